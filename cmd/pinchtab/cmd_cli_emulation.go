@@ -8,7 +8,7 @@ import (
 var setCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Set browser emulation properties",
-	Long:  "Commands for setting browser emulation properties such as viewport, geolocation, and network conditions.",
+	Long:  "Commands for setting browser emulation properties such as viewport, geolocation, permissions, and network conditions.",
 }
 
 var setViewportCmd = &cobra.Command{
@@ -83,10 +83,23 @@ var setMediaCmd = &cobra.Command{
 	},
 }
 
+var setPermissionCmd = &cobra.Command{
+	Use:   "permission <name> <granted|denied|prompt|reset>",
+	Short: "Grant or deny a browser permission",
+	Long:  "Set a browser permission (geolocation, notifications, camera, microphone, clipboard-read, midi, ...) for the current tab's origin via Browser.setPermission. Use --origin for another origin; reset drops the override so the instance default applies again.",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCLI(func(rt cliRuntime) {
+			browseractions.SetPermission(rt.client, rt.base, rt.token, cmd, args)
+		})
+	},
+}
+
 func init() {
 	setViewportCmd.Flags().Float64("dpr", 0, "Device pixel ratio (default 1.0)")
 	setViewportCmd.Flags().Bool("mobile", false, "Emulate mobile device")
 	setGeoCmd.Flags().Float64("accuracy", 0, "Geolocation accuracy in meters (default 1.0)")
+	setPermissionCmd.Flags().String("origin", "", "Origin to apply to (default: the tab's current origin)")
 
 	setCmd.AddCommand(setViewportCmd)
 	setCmd.AddCommand(setGeoCmd)
@@ -94,4 +107,5 @@ func init() {
 	setCmd.AddCommand(setHeadersCmd)
 	setCmd.AddCommand(setCredentialsCmd)
 	setCmd.AddCommand(setMediaCmd)
+	setCmd.AddCommand(setPermissionCmd)
 }
@@ -15,6 +15,18 @@ against request URLs as a substring (no wildcards) or as a glob ('*', '?').
   pinchtab network route 'api/users' --body '{}'   # fulfill with JSON body
   pinchtab network route 'tracker.io'              # pass-through (no-op rule)
 
+WebSocket and EventSource connections are routed with --resource-type
+websocket or eventsource:
+
+  pinchtab network route 'wss://feed.*' --resource-type websocket --abort
+  pinchtab network route 'wss://feed.*' --resource-type websocket --mock \
+      --frame '{"px":1}' --frame '{"px":2}' --frame-delay 500
+  pinchtab network route '/events' --resource-type eventsource --drop '*heartbeat*'
+
+--mock serves the frames from a fake connection; without it, frames are
+injected into the real connection after it opens. --drop discards matching
+frames in both directions.
+
 Use 'pinchtab network unroute' to remove a rule.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -38,3 +50,32 @@ var networkUnrouteCmd = &cobra.Command{
 		})
 	},
 }
+
+var networkReplayCmd = &cobra.Command{
+	Use:   "replay [har-file]",
+	Short: "Serve the active tab from a recorded HAR archive",
+	Long: `Answer the active tab's requests from a HAR archive instead of the network.
+The path is relative to the state dir, so archives saved with
+GET /network/export?output=file replay as exports/<name>.har.
+
+  pinchtab network replay exports/checkout.har                 # start
+  pinchtab network replay exports/checkout.har --match url     # ignore request bodies
+  pinchtab network replay exports/checkout.har --not-found passthrough
+  pinchtab network replay                                      # status
+  pinchtab network replay --stop                               # stop
+
+--match selects how requests are matched: strict (method, URL and body hash),
+url (method and URL) or path (method and URL without query). --not-found
+decides what unmatched requests get: abort, passthrough or 404. Route rules
+installed with 'pinchtab network route' take precedence over the archive.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		archive := ""
+		if len(args) > 0 {
+			archive = args[0]
+		}
+		runCLI(func(rt cliRuntime) {
+			browseractions.NetworkReplay(rt.client, rt.base, rt.token, cmd, archive)
+		})
+	},
+}
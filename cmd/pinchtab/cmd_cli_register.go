@@ -42,7 +42,7 @@ func registerBrowserCommands() {
 	keyboardCmd.AddCommand(keyboardTypeCmd, keyboardInsertTextCmd)
 	dialogCmd.AddCommand(dialogAcceptCmd, dialogDismissCmd)
 	mouseCmd.AddCommand(mouseMoveCmd, mouseDownCmd, mouseUpCmd, mouseWheelCmd)
-	networkCmd.AddCommand(networkRouteCmd, networkUnrouteCmd)
+	networkCmd.AddCommand(networkRouteCmd, networkUnrouteCmd, networkReplayCmd)
 	recordCmd.AddCommand(recordStartCmd, recordStopCmd, recordStatusCmd)
 
 	configureBrowserFlags()
@@ -191,6 +191,7 @@ func configureBrowserFlags() {
 	navCmd.Flags().Bool("new-tab", false, "Open in new tab")
 	navCmd.Flags().Bool("block-images", false, "Block image loading")
 	navCmd.Flags().Bool("block-ads", false, "Block ads")
+	navCmd.Flags().String("proxy-pool", "", "Open in a new tab behind a proxy from this browser.proxyPools pool")
 	addPostActionFlags(navCmd, "navigation", false)
 	navCmd.Flags().Bool("dismiss-banners", false, "After landing, click any visible cookie/consent dismissal button or remove obvious overlay containers")
 
@@ -261,6 +262,7 @@ func configureBrowserFlags() {
 		setHeadersCmd,
 		setCredentialsCmd,
 		setMediaCmd,
+		setPermissionCmd,
 	)
 
 	evalCmd.Flags().Bool("await-promise", false, "Resolve a returned Promise before responding")
@@ -321,6 +323,7 @@ func configureBrowserFlags() {
 		setHeadersCmd,
 		setCredentialsCmd,
 		setMediaCmd,
+		setPermissionCmd,
 	)
 
 	scrollintoviewCmd.Flags().String("css", "", "CSS selector instead of ref")
@@ -331,8 +334,15 @@ func configureBrowserFlags() {
 	networkRouteCmd.Flags().String("content-type", "", "(With --body) Response Content-Type (default application/json)")
 	networkRouteCmd.Flags().Int("status", 0, "(With --body) Response status code (default 200)")
 	networkRouteCmd.Flags().String("method", "", "Limit to an HTTP method (GET, POST, ...). Fulfill rules without --method skip OPTIONS preflights to avoid breaking CORS.")
-	addTabFlag(networkRouteCmd, networkUnrouteCmd)
-	addJSONFlag(networkRouteCmd, networkUnrouteCmd)
+	networkRouteCmd.Flags().StringArray("frame", nil, "(WebSocket/EventSource) Scripted server frame data (repeatable)")
+	networkRouteCmd.Flags().Int("frame-delay", 0, "(With --frame) Milliseconds before each frame")
+	networkRouteCmd.Flags().Bool("mock", false, "(WebSocket/EventSource) Serve --frame data from a fake connection instead of the server")
+	networkRouteCmd.Flags().String("drop", "", "(WebSocket/EventSource) Discard frames matching this pattern")
+	networkReplayCmd.Flags().String("match", "", "Request matching: strict (method, URL, body hash; default), url or path")
+	networkReplayCmd.Flags().String("not-found", "", "Unmatched requests: abort (default), passthrough or 404")
+	networkReplayCmd.Flags().Bool("stop", false, "Stop replaying on the tab")
+	addTabFlag(networkRouteCmd, networkUnrouteCmd, networkReplayCmd)
+	addJSONFlag(networkRouteCmd, networkUnrouteCmd, networkReplayCmd)
 
 	networkCmd.Flags().String("filter", "", "URL pattern filter")
 	networkCmd.Flags().String("method", "", "HTTP method filter (GET, POST, etc)")
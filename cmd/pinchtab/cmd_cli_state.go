@@ -85,9 +85,13 @@ var stateCleanCmd = &cobra.Command{
 func init() {
 	stateCmd.AddCommand(stateListCmd, stateSaveCmd, stateLoadCmd, stateShowCmd, stateDeleteCmd, stateCleanCmd)
 	addTabFlag(stateCmd)
+	stateCmd.Flags().StringArray("origin", nil, "Also capture localStorage of this origin (repeatable)")
+	stateCmd.Flags().Bool("visited", false, "Also capture localStorage of every origin visited in this browser session")
 
 	stateSaveCmd.Flags().String("name", "", "Name for the saved state (auto-generated if omitted)")
 	stateSaveCmd.Flags().Bool("encrypt", false, "Encrypt the state file (requires security.stateEncryptionKey in config)")
+	stateSaveCmd.Flags().StringArray("origin", nil, "Also capture localStorage of this origin (repeatable)")
+	stateSaveCmd.Flags().Bool("visited", false, "Also capture localStorage of every origin visited in this browser session")
 	addTabFlag(stateSaveCmd)
 
 	stateLoadCmd.Flags().String("name", "", "Exact name or prefix of the state file to load")
@@ -49,6 +49,7 @@ func init() {
 		Run: func(cmd *cobra.Command, args []string) {
 			agentID, _ := cmd.Flags().GetString("agent-id")
 			label, _ := cmd.Flags().GetString("label")
+			isolated, _ := cmd.Flags().GetBool("isolated")
 			jsonOutput, _ := cmd.Flags().GetBool("json")
 			if agentID == "" {
 				fmt.Fprintln(os.Stderr, "Error: --agent-id is required")
@@ -58,6 +59,9 @@ func init() {
 			if label != "" {
 				body["label"] = label
 			}
+			if isolated {
+				body["isolated"] = true
+			}
 			// Auto-start the control plane first: the documented "create a session
 			// before any browser command" order otherwise fails cold on a fresh
 			// machine (only browser commands start the server).
@@ -94,6 +98,7 @@ func init() {
 	}
 	createCmd.Flags().String("agent-id", "", "Agent ID to associate with the session (required)")
 	createCmd.Flags().String("label", "", "Optional human-readable label")
+	createCmd.Flags().Bool("isolated", false, "Give the session its own browser context (cookies, storage, permissions)")
 	addJSONFlag(createCmd)
 
 	revokeCmd := &cobra.Command{
@@ -9,7 +9,13 @@ import {
 } from "react-router-dom";
 import { ActivityPage, AgentsPage } from "./activities";
 import { HandoffNotifications, NavBar } from "./components/molecules";
-import { LoginPage, MonitoringPage, ProfilesPage, SettingsPage } from "./pages";
+import {
+  LoginPage,
+  MonitoringPage,
+  ProfilesPage,
+  SettingsPage,
+  TakeoverPage,
+} from "./pages";
 import * as api from "./services/api";
 import {
   AUTH_REQUIRED_EVENT,
@@ -285,7 +291,12 @@ function AppContent() {
 export default function App() {
   return (
     <BrowserRouter>
-      <AppContent />
+      <Routes>
+        {/* Signed handoff links authenticate themselves, so the takeover view
+            sits outside the login gate and the dashboard shell. */}
+        <Route path="/dashboard/takeover" element={<TakeoverPage />} />
+        <Route path="*" element={<AppContent />} />
+      </Routes>
     </BrowserRouter>
   );
 }
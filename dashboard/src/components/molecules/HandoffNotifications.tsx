@@ -53,6 +53,16 @@ export default function HandoffNotifications() {
             <div className="mb-2 text-xs text-text-muted">{n.hint}</div>
           )}
           <div className="flex justify-end gap-2">
+            {n.takeoverUrl && (
+              <a
+                href={n.takeoverUrl}
+                target="_blank"
+                rel="noopener noreferrer"
+                className="rounded-sm border border-primary/40 px-3 py-1 text-xs text-primary transition-all duration-150 hover:bg-primary/10"
+              >
+                Take over
+              </a>
+            )}
             <button
               type="button"
               onClick={() => handleResume(n.tabId)}
@@ -12,7 +12,7 @@ interface UseScreencastInputArgs {
 // CDP modifier bitmask: Alt=1, Ctrl=2, Meta=4, Shift=8. Shared by the keyboard
 // and pointer paths so held-modifier gestures (Shift+click, Cmd/Ctrl+click,
 // Ctrl+C) reach the page with the same encoding.
-function modifierBitmask(e: {
+export function modifierBitmask(e: {
   altKey: boolean;
   ctrlKey: boolean;
   metaKey: boolean;
@@ -102,6 +102,7 @@ export interface ActivityLogEvent {
   requestId?: string;
   sessionId?: string;
   agentId?: string;
+  tokenId?: string;
   method: string;
   path: string;
   status: number /* int */;
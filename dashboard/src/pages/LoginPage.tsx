@@ -1,4 +1,4 @@
-import { useState } from "react";
+import { useEffect, useState } from "react";
 import type { ComponentProps } from "react";
 import { useLocation, useNavigate } from "react-router-dom";
 import { Button, Card } from "../components/atoms";
@@ -9,13 +9,31 @@ import {
   isInsecureDashboardTransport,
 } from "../services/auth";
 
+const ssoErrors: Record<string, string> = {
+  not_allowed: "Your account is not in a group allowed to use this dashboard.",
+  identity_mismatch:
+    "You signed in as a different user. Sign in as the same user to elevate.",
+  login_expired: "The sign-in took too long or was already used. Try again.",
+  state_mismatch: "The sign-in could not be verified. Try again.",
+  reauth_required: "The identity provider did not ask you to sign in again.",
+};
+
 export default function LoginPage() {
   const navigate = useNavigate();
   const location = useLocation();
   const [token, setToken] = useState("");
   const [error, setError] = useState("");
   const [submitting, setSubmitting] = useState(false);
+  const [ssoEnabled, setSsoEnabled] = useState(false);
   const insecureDashboardTransport = isInsecureDashboardTransport();
+  const ssoError = new URLSearchParams(location.search).get("sso_error");
+
+  useEffect(() => {
+    api
+      .fetchSsoStatus()
+      .then((status) => setSsoEnabled(status.enabled))
+      .catch(() => setSsoEnabled(false));
+  }, []);
 
   const from =
     (location.state as { from?: string } | null)?.from ||
@@ -64,6 +82,27 @@ export default function LoginPage() {
           )}
         </div>
 
+        {ssoError && (
+          <div className="mb-4 rounded-sm border border-destructive/35 bg-destructive/10 px-3 py-2 text-xs leading-5 text-destructive">
+            {ssoErrors[ssoError] ?? "Single sign-on failed. Try again."}
+          </div>
+        )}
+
+        {ssoEnabled && (
+          <div className="mb-4 space-y-3">
+            <Button
+              variant="primary"
+              className="w-full"
+              onClick={() => window.location.assign(api.ssoLoginUrl(from))}
+            >
+              Sign in with SSO
+            </Button>
+            <div className="text-center text-xs text-text-muted">
+              or use the API token
+            </div>
+          </div>
+        )}
+
         <form
           id="login-form"
           className="space-y-4"
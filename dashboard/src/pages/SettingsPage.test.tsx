@@ -11,6 +11,8 @@ vi.mock("../services/api", () => ({
   fetchHealth: vi.fn(),
   saveBackendConfig: vi.fn(),
   elevate: vi.fn(),
+  fetchSsoIdentity: vi.fn(),
+  ssoLoginUrl: vi.fn(),
   isApiError: vi.fn(() => false),
 }));
 
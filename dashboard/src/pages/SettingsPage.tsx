@@ -2,11 +2,13 @@ import type { Dispatch, SetStateAction } from "react";
 import { useState } from "react";
 import { Button, Card, Input, Modal } from "../components/atoms";
 import { SidebarPanel, SidebarPanelHeader } from "../components/molecules";
+import * as api from "../services/api";
 import type {
   BackendConfig,
   BackendConfigState,
   LocalDashboardSettings,
 } from "../types";
+import { ApiTokensSettingsSection } from "./settings/ApiTokensSettingsSection";
 import { BrowserSettingsSection } from "./settings/BrowserSettingsSection";
 import { DashboardSettingsSection } from "./settings/DashboardSettingsSection";
 import { DefaultsSettingsSection } from "./settings/DefaultsSettingsSection";
@@ -82,6 +84,8 @@ function renderActiveSection(
           updateBackendSection={options.updateBackendSection}
         />
       );
+    case "api-tokens":
+      return <ApiTokensSettingsSection />;
     case "profiles":
       return (
         <ProfilesSettingsSection
@@ -149,6 +153,7 @@ export default function SettingsPage() {
     setElevationToken,
     elevationError,
     elevating,
+    canElevateWithSso,
     hasChanges,
     restartRequired,
     restartReasons,
@@ -220,6 +225,25 @@ export default function SettingsPage() {
               {elevationError}
             </div>
           )}
+          {canElevateWithSso && (
+            <div className="space-y-2 border-t border-border-subtle pt-4">
+              <p className="text-xs leading-5 text-text-muted">
+                Or sign in again with SSO. Unsaved changes are discarded, so
+                save again afterwards.
+              </p>
+              <Button
+                type="button"
+                variant="secondary"
+                onClick={() =>
+                  window.location.assign(
+                    api.ssoLoginUrl("/dashboard/settings", true),
+                  )
+                }
+              >
+                Sign in again with SSO
+              </Button>
+            </div>
+          )}
         </form>
       </Modal>
 
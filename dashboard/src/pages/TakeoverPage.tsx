@@ -0,0 +1,363 @@
+import { useCallback, useEffect, useRef, useState } from "react";
+import type { ComponentProps } from "react";
+import { useSearchParams } from "react-router-dom";
+import { Button, Card, Input } from "../components/atoms";
+import { drawFrameToCanvas } from "../components/screencast/frameDecode";
+import { modifierBitmask } from "../components/screencast/useScreencastInput";
+import * as api from "../services/api";
+import { sameOriginUrl } from "../services/auth";
+
+type StreamStatus = "connecting" | "streaming" | "error";
+
+// TakeoverPage is the target of a signed handoff link. It runs outside the
+// dashboard login gate: the link token in the URL authenticates every call,
+// and it only grants access to this one paused tab.
+export default function TakeoverPage() {
+  const [params] = useSearchParams();
+  const tabId = params.get("tab") ?? "";
+  const token = params.get("t") ?? "";
+
+  const [info, setInfo] = useState<api.TakeoverInfo | null>(null);
+  const [loadError, setLoadError] = useState("");
+  const [resumed, setResumed] = useState(false);
+  const handleResumed = useCallback(() => setResumed(true), []);
+
+  useEffect(() => {
+    if (!tabId || !token) {
+      setLoadError("This takeover link is incomplete.");
+      return;
+    }
+    let cancelled = false;
+    api
+      .fetchTakeover(tabId, token)
+      .then((next) => {
+        if (!cancelled) setInfo(next);
+      })
+      .catch((e) => {
+        if (!cancelled) setLoadError(takeoverErrorMessage(e));
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [tabId, token]);
+
+  if (resumed) {
+    return (
+      <TakeoverMessage title="Control returned to the agent">
+        You can close this window.
+      </TakeoverMessage>
+    );
+  }
+  if (loadError) {
+    return (
+      <TakeoverMessage title="Takeover unavailable">{loadError}</TakeoverMessage>
+    );
+  }
+  if (!info) {
+    return <TakeoverMessage title="Loading takeover...">{null}</TakeoverMessage>;
+  }
+
+  return (
+    <div className="flex h-screen flex-col bg-bg-app">
+      <header className="flex shrink-0 items-center justify-between gap-4 border-b border-border-subtle px-4 py-3">
+        <div className="min-w-0">
+          <div className="dashboard-section-label">Human handoff</div>
+          <div className="truncate text-sm font-semibold text-text-primary">
+            {info.title || info.url || info.tabId}
+          </div>
+          {info.url && (
+            <div className="truncate text-xs text-text-muted">{info.url}</div>
+          )}
+        </div>
+        <div className="shrink-0 text-right text-xs text-text-muted">
+          <div>
+            Reason: <code>{info.reason}</code>
+          </div>
+          <div>
+            Link expires {new Date(info.linkExpiresAt).toLocaleTimeString()}
+          </div>
+        </div>
+      </header>
+      <div className="flex min-h-0 flex-1">
+        <div className="flex min-w-0 flex-1 items-center justify-center bg-black">
+          {info.screencast ? (
+            <TakeoverCanvas
+              tabId={tabId}
+              token={token}
+              onEnded={handleResumed}
+            />
+          ) : (
+            <div className="px-6 text-center text-sm text-text-muted">
+              Live view is disabled on this server
+              (security.allowScreencast). Finish the task in the browser
+              window, then resume below.
+            </div>
+          )}
+        </div>
+        <ResumeForm
+          tabId={tabId}
+          token={token}
+          onResumed={handleResumed}
+        />
+      </div>
+    </div>
+  );
+}
+
+function TakeoverCanvas({
+  tabId,
+  token,
+  onEnded,
+}: {
+  tabId: string;
+  token: string;
+  onEnded: () => void;
+}) {
+  const canvasRef = useRef<HTMLCanvasElement>(null);
+  const socketRef = useRef<WebSocket | null>(null);
+  const [status, setStatus] = useState<StreamStatus>("connecting");
+  const [inputError, setInputError] = useState("");
+
+  useEffect(() => {
+    const canvas = canvasRef.current;
+    const ctx = canvas?.getContext("2d");
+    if (!canvas || !ctx) return;
+
+    const wsUrl = new URL(
+      sameOriginUrl(api.takeoverStreamPath(tabId, token)),
+      window.location.origin,
+    );
+    wsUrl.protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
+    const socket = new WebSocket(wsUrl.toString());
+    socket.binaryType = "arraybuffer";
+    socketRef.current = socket;
+
+    let disposed = false;
+    let pending: ArrayBuffer | null = null;
+    let rendering = false;
+    const render = async () => {
+      if (disposed || rendering || !pending) return;
+      rendering = true;
+      const frame = pending;
+      pending = null;
+      try {
+        await drawFrameToCanvas(canvas, ctx, frame);
+        if (!disposed) setStatus("streaming");
+      } catch (err) {
+        console.error("takeover frame render failed", err);
+      } finally {
+        rendering = false;
+        if (!disposed && pending) void render();
+      }
+    };
+
+    socket.onmessage = (evt) => {
+      if (disposed) return;
+      if (typeof evt.data === "string") {
+        let msg: { type?: string; error?: string };
+        try {
+          msg = JSON.parse(evt.data);
+        } catch {
+          return;
+        }
+        if (msg.type === "resumed") {
+          onEnded();
+        } else if (msg.type === "error" && msg.error) {
+          setInputError(msg.error);
+        }
+        return;
+      }
+      pending = evt.data as ArrayBuffer;
+      void render();
+    };
+    socket.onclose = () => {
+      if (!disposed) setStatus("error");
+    };
+
+    return () => {
+      disposed = true;
+      socket.close();
+      socketRef.current = null;
+    };
+  }, [tabId, token, onEnded]);
+
+  const send = useCallback(
+    (input: Record<string, unknown>) => {
+      const socket = socketRef.current;
+      if (status !== "streaming" || socket?.readyState !== WebSocket.OPEN) {
+        return;
+      }
+      setInputError("");
+      socket.send(JSON.stringify(input));
+    },
+    [status],
+  );
+
+  const frameCoords = (e: React.MouseEvent<HTMLCanvasElement>) => {
+    const canvas = e.currentTarget;
+    const rect = canvas.getBoundingClientRect();
+    return {
+      x: Math.round(((e.clientX - rect.left) / rect.width) * canvas.width),
+      y: Math.round(((e.clientY - rect.top) / rect.height) * canvas.height),
+      hasXY: true,
+      frameW: canvas.width,
+      frameH: canvas.height,
+    };
+  };
+
+  return (
+    <div className="relative flex h-full w-full items-center justify-center">
+      <canvas
+        ref={canvasRef}
+        className="max-h-full max-w-full cursor-pointer object-contain"
+        tabIndex={0}
+        width={1280}
+        height={960}
+        onClick={(e) => {
+          e.currentTarget.focus();
+          send({
+            kind: "click",
+            ...frameCoords(e),
+            modifiers: modifierBitmask(e),
+          });
+        }}
+        onWheel={(e) =>
+          send({
+            kind: "scroll",
+            ...frameCoords(e),
+            scrollY: Math.round(e.deltaY),
+          })
+        }
+        onKeyDown={(e) => {
+          e.preventDefault();
+          if (e.key.length === 1 && !e.ctrlKey && !e.metaKey && !e.altKey) {
+            send({ kind: "keyboard-inserttext", text: e.key });
+          } else {
+            send({ kind: "press", key: e.key, modifiers: modifierBitmask(e) });
+          }
+        }}
+      />
+      {status !== "streaming" && (
+        <div className="absolute inset-0 flex items-center justify-center bg-black/70 text-sm text-white">
+          {status === "connecting" ? "Connecting..." : "Live view disconnected"}
+        </div>
+      )}
+      {inputError && (
+        <div className="absolute bottom-3 left-3 rounded-sm border border-destructive/35 bg-destructive/10 px-3 py-1.5 text-xs text-destructive">
+          {inputError}
+        </div>
+      )}
+    </div>
+  );
+}
+
+function ResumeForm({
+  tabId,
+  token,
+  onResumed,
+}: {
+  tabId: string;
+  token: string;
+  onResumed: () => void;
+}) {
+  const [status, setStatus] = useState("human_completed");
+  const [note, setNote] = useState("");
+  const [error, setError] = useState("");
+  const [submitting, setSubmitting] = useState(false);
+
+  const handleSubmit: NonNullable<ComponentProps<"form">["onSubmit"]> = async (
+    event,
+  ) => {
+    event.preventDefault();
+    setSubmitting(true);
+    setError("");
+    try {
+      await api.resumeTakeover(
+        tabId,
+        token,
+        status.trim(),
+        note.trim() ? { note: note.trim() } : undefined,
+      );
+      onResumed();
+    } catch (e) {
+      setError(takeoverErrorMessage(e));
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <form
+      className="flex w-80 shrink-0 flex-col gap-4 border-l border-border-subtle p-4"
+      onSubmit={handleSubmit}
+    >
+      <p className="text-sm leading-6 text-text-muted">
+        Complete the step the agent is stuck on, then hand the tab back.
+      </p>
+      <Input
+        label="Outcome"
+        value={status}
+        onChange={(e) => setStatus(e.target.value)}
+        hint="Passed to the agent as the resume status."
+      />
+      <div className="flex flex-col gap-1.5">
+        <label
+          htmlFor="takeover-note"
+          className="dashboard-section-title text-[0.68rem]"
+        >
+          Note for the agent
+        </label>
+        <textarea
+          id="takeover-note"
+          rows={4}
+          value={note}
+          onChange={(e) => setNote(e.target.value)}
+          className="rounded-sm border border-border-subtle bg-[rgb(var(--brand-surface-code-rgb)/0.72)] px-3 py-2 text-sm text-text-primary placeholder:text-text-muted focus:border-primary focus:outline-none focus:ring-2 focus:ring-primary/20"
+          placeholder="Optional"
+        />
+      </div>
+      {error && (
+        <div className="rounded-sm border border-destructive/35 bg-destructive/10 px-3 py-2 text-xs leading-5 text-destructive">
+          {error}
+        </div>
+      )}
+      <Button type="submit" variant="primary" disabled={submitting}>
+        {submitting ? "Resuming..." : "Resume agent"}
+      </Button>
+    </form>
+  );
+}
+
+function TakeoverMessage({
+  title,
+  children,
+}: {
+  title: string;
+  children: React.ReactNode;
+}) {
+  return (
+    <div className="flex min-h-screen items-center justify-center bg-bg-app px-4">
+      <Card className="w-full max-w-md p-6">
+        <h1 className="text-lg font-semibold text-text-primary">{title}</h1>
+        {children && (
+          <p className="mt-2 text-sm leading-6 text-text-muted">{children}</p>
+        )}
+      </Card>
+    </div>
+  );
+}
+
+function takeoverErrorMessage(e: unknown): string {
+  if (api.isApiError(e)) {
+    switch (e.code) {
+      case "takeover_link_expired":
+        return "This takeover link has expired.";
+      case "handoff_not_active":
+        return "This handoff has already been resumed.";
+      case "bad_takeover_link":
+      case "missing_token":
+        return "This takeover link is not valid.";
+    }
+  }
+  return e instanceof Error ? e.message : "Request failed";
+}
@@ -2,3 +2,4 @@ export { default as MonitoringPage } from "./MonitoringPage";
 export { default as ProfilesPage } from "./ProfilesPage";
 export { default as SettingsPage } from "./SettingsPage";
 export { default as LoginPage } from "./LoginPage";
+export { default as TakeoverPage } from "./TakeoverPage";
@@ -3,7 +3,11 @@ import { useNavigate } from "react-router-dom";
 import { useAppStore } from "../../stores/useAppStore";
 import * as api from "../../services/api";
 
-const AUTO_INSTANCE_STRATEGIES = new Set(["always-on", "simple-autorestart"]);
+const AUTO_INSTANCE_STRATEGIES = new Set([
+  "always-on",
+  "simple-autorestart",
+  "autoscale",
+]);
 const STARTUP_REFRESH_DELAYS_MS = [500, 1000, 1500, 2500, 4000] as const;
 
 export function useMonitoringController() {
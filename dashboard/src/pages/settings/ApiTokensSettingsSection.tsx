@@ -0,0 +1,382 @@
+import { useCallback, useEffect, useState } from "react";
+import type { FormEvent } from "react";
+import { Button, Input } from "../../components/atoms";
+import * as api from "../../services/api";
+import type { ApiToken, CreatedApiToken } from "../../services/api";
+import { fieldClass } from "./settingsShared";
+import { SectionCard, SettingRow } from "./SettingsSharedComponents";
+
+const families = [
+  "browse",
+  "network",
+  "media",
+  "cookies",
+  "clipboard",
+  "evaluate",
+  "storage",
+  "console",
+  "solve",
+  "tasks",
+  "activity",
+  "instances",
+];
+
+const capabilities = [
+  "evaluate",
+  "macro",
+  "screencast",
+  "download",
+  "cookies",
+  "upload",
+  "stateExport",
+  "networkIntercept",
+  "webauthn",
+];
+
+const checkboxClass =
+  "h-4 w-4 rounded border-border-subtle bg-bg-elevated text-primary focus:ring-primary/50";
+
+type PendingAction = { kind: "create" } | { kind: "revoke"; id: string };
+
+function splitList(value: string): string[] {
+  return value
+    .split(/[\s,]+/)
+    .map((item) => item.trim())
+    .filter(Boolean);
+}
+
+function toggle(list: string[], value: string, on: boolean): string[] {
+  return on ? [...list, value] : list.filter((item) => item !== value);
+}
+
+function formatTime(value?: string): string {
+  if (!value || value.startsWith("0001-")) {
+    return "—";
+  }
+  return new Date(value).toLocaleString();
+}
+
+function scopeSummary(token: ApiToken): string {
+  const parts = [token.scope.families.join(", ")];
+  if (token.scope.capabilities?.length) {
+    parts.push(`caps: ${token.scope.capabilities.join(", ")}`);
+  }
+  const targets = [
+    ...(token.scope.instances ?? []),
+    ...(token.scope.profiles ?? []),
+  ];
+  if (targets.length) {
+    parts.push(`only: ${targets.join(", ")}`);
+  }
+  if (token.scope.allowedDomains?.length) {
+    parts.push(`domains: ${token.scope.allowedDomains.join(", ")}`);
+  }
+  return parts.join(" · ");
+}
+
+function Checkboxes({
+  options,
+  selected,
+  onChange,
+}: {
+  options: string[];
+  selected: string[];
+  onChange: (next: string[]) => void;
+}) {
+  return (
+    <div className="grid grid-cols-2 gap-2">
+      {options.map((option) => (
+        <label
+          key={option}
+          className="flex cursor-pointer items-center gap-2 text-sm text-text-secondary"
+        >
+          <input
+            type="checkbox"
+            checked={selected.includes(option)}
+            onChange={(e) => onChange(toggle(selected, option, e.target.checked))}
+            className={checkboxClass}
+          />
+          {option}
+        </label>
+      ))}
+    </div>
+  );
+}
+
+export function ApiTokensSettingsSection() {
+  const [tokens, setTokens] = useState<ApiToken[]>([]);
+  const [error, setError] = useState("");
+  const [busy, setBusy] = useState(false);
+  const [created, setCreated] = useState<CreatedApiToken | null>(null);
+
+  const [name, setName] = useState("");
+  const [selectedFamilies, setSelectedFamilies] = useState<string[]>([
+    "browse",
+  ]);
+  const [selectedCapabilities, setSelectedCapabilities] = useState<string[]>(
+    [],
+  );
+  const [instances, setInstances] = useState("");
+  const [profiles, setProfiles] = useState("");
+  const [domains, setDomains] = useState("");
+  const [expiresAt, setExpiresAt] = useState("");
+
+  const [pending, setPending] = useState<PendingAction | null>(null);
+  const [elevationToken, setElevationToken] = useState("");
+  const [canElevateWithSso, setCanElevateWithSso] = useState(false);
+
+  const load = useCallback(async () => {
+    try {
+      setTokens(await api.fetchApiTokens());
+    } catch (e) {
+      setError(e instanceof Error ? e.message : "Failed to load API tokens");
+    }
+  }, []);
+
+  useEffect(() => {
+    void load();
+  }, [load]);
+
+  const run = async (action: PendingAction) => {
+    setBusy(true);
+    setError("");
+    try {
+      if (action.kind === "create") {
+        const token = await api.createApiToken({
+          name,
+          scope: {
+            families: selectedFamilies,
+            capabilities: selectedCapabilities,
+            instances: splitList(instances),
+            profiles: splitList(profiles),
+            allowedDomains: splitList(domains),
+          },
+          expiresAt: expiresAt ? new Date(expiresAt).toISOString() : undefined,
+        });
+        setCreated(token);
+        setName("");
+      } else {
+        await api.revokeApiToken(action.id);
+      }
+      setPending(null);
+      await load();
+    } catch (e) {
+      if (api.isApiError(e) && e.code === "elevation_required") {
+        setElevationToken("");
+        setPending(action);
+        api
+          .fetchSsoIdentity()
+          .then((identity) => setCanElevateWithSso(identity?.role === "admin"))
+          .catch(() => setCanElevateWithSso(false));
+        return;
+      }
+      setError(e instanceof Error ? e.message : "API token request failed");
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  const handleElevate = async (event: FormEvent<HTMLFormElement>) => {
+    event.preventDefault();
+    if (!pending) {
+      return;
+    }
+    try {
+      await api.elevate(elevationToken);
+      setElevationToken("");
+      await run(pending);
+    } catch (e) {
+      setError(e instanceof Error ? e.message : "Failed to verify API token");
+    }
+  };
+
+  return (
+    <SectionCard
+      title="API Tokens"
+      description="Named tokens for agents and scripts. Each token is limited to endpoint families and capabilities, and optionally to instances, profiles, and URL domains. Only a hash is stored, so a new token is shown once."
+    >
+      {error && (
+        <div className="rounded-sm border border-destructive/35 bg-destructive/10 px-3 py-2 text-sm text-destructive">
+          {error}
+        </div>
+      )}
+
+      {pending && (
+        <form
+          onSubmit={handleElevate}
+          className="flex flex-col gap-3 rounded-sm border border-warning/25 bg-warning/10 p-4"
+        >
+          <p className="text-sm text-warning">
+            Re-enter the server API token to manage API tokens.
+          </p>
+          <Input
+            type="password"
+            autoComplete="off"
+            label="Server API token"
+            value={elevationToken}
+            onChange={(e) => setElevationToken(e.target.value)}
+            spellCheck={false}
+            autoCapitalize="none"
+          />
+          <div className="flex gap-2">
+            <Button type="submit" variant="primary" disabled={busy}>
+              Verify
+            </Button>
+            {canElevateWithSso && (
+              <Button
+                type="button"
+                variant="secondary"
+                onClick={() =>
+                  window.location.assign(
+                    api.ssoLoginUrl("/dashboard/settings", true),
+                  )
+                }
+              >
+                Sign in again with SSO
+              </Button>
+            )}
+            <Button
+              type="button"
+              variant="secondary"
+              onClick={() => setPending(null)}
+            >
+              Cancel
+            </Button>
+          </div>
+        </form>
+      )}
+
+      {created && (
+        <div className="flex flex-col gap-2 rounded-sm border border-primary/30 bg-primary/10 p-4">
+          <div className="text-sm text-primary">
+            Copy the token for “{created.name}” now. It will not be shown
+            again.
+          </div>
+          <code className="dashboard-mono break-all text-sm text-text-primary">
+            {created.token}
+          </code>
+          <div>
+            <Button size="sm" variant="secondary" onClick={() => setCreated(null)}>
+              Done
+            </Button>
+          </div>
+        </div>
+      )}
+
+      <SettingRow label="Name" description="Shown in the token list and activity log.">
+        <input
+          value={name}
+          onChange={(e) => setName(e.target.value)}
+          placeholder="ci-agent"
+          className={fieldClass}
+        />
+      </SettingRow>
+      <SettingRow
+        label="Endpoint families"
+        description="Groups of endpoints the token may call. Tasks cannot be combined with instance, profile, or domain limits."
+      >
+        <Checkboxes
+          options={families}
+          selected={selectedFamilies}
+          onChange={setSelectedFamilies}
+        />
+      </SettingRow>
+      <SettingRow
+        label="Capabilities"
+        description="Gated endpoints also need their capability here, on top of the server's security settings."
+      >
+        <Checkboxes
+          options={capabilities}
+          selected={selectedCapabilities}
+          onChange={setSelectedCapabilities}
+        />
+      </SettingRow>
+      <SettingRow
+        label="Instances and profiles"
+        description="Comma-separated instance IDs and profile names or IDs. Leave both empty to allow every instance."
+      >
+        <div className="flex flex-col gap-2">
+          <input
+            value={instances}
+            onChange={(e) => setInstances(e.target.value)}
+            placeholder="inst_ab12cd34"
+            className={fieldClass}
+          />
+          <input
+            value={profiles}
+            onChange={(e) => setProfiles(e.target.value)}
+            placeholder="work, research"
+            className={fieldClass}
+          />
+        </div>
+      </SettingRow>
+      <SettingRow
+        label="Allowed domains"
+        description="Comma-separated domain patterns such as example.com or *.example.com. Leave empty to allow every URL."
+      >
+        <input
+          value={domains}
+          onChange={(e) => setDomains(e.target.value)}
+          placeholder="example.com, *.example.com"
+          className={fieldClass}
+        />
+      </SettingRow>
+      <SettingRow label="Expires" description="Leave empty for a token that never expires.">
+        <input
+          type="datetime-local"
+          value={expiresAt}
+          onChange={(e) => setExpiresAt(e.target.value)}
+          className={fieldClass}
+        />
+      </SettingRow>
+      <div className="flex justify-end">
+        <Button
+          variant="primary"
+          disabled={busy || !name.trim() || selectedFamilies.length === 0}
+          onClick={() => void run({ kind: "create" })}
+        >
+          Create token
+        </Button>
+      </div>
+
+      <div className="flex flex-col gap-2">
+        {tokens.length === 0 ? (
+          <div className="text-sm text-text-muted">No API tokens yet.</div>
+        ) : (
+          tokens.map((token) => (
+            <div
+              key={token.id}
+              className="flex flex-col gap-2 rounded-sm border border-border-subtle bg-black/10 p-4 lg:flex-row lg:items-center lg:justify-between"
+            >
+              <div className="min-w-0">
+                <div className="text-sm font-medium text-text-primary">
+                  {token.name}{" "}
+                  <span className="dashboard-mono text-xs text-text-muted">
+                    {token.id} · {token.status}
+                  </span>
+                </div>
+                <p className="mt-1 text-xs leading-5 text-text-muted">
+                  {scopeSummary(token)}
+                </p>
+                <p className="text-xs leading-5 text-text-muted">
+                  Last used {formatTime(token.lastUsedAt)} · expires{" "}
+                  {formatTime(token.expiresAt)}
+                </p>
+              </div>
+              {token.status === "active" && (
+                <Button
+                  size="sm"
+                  variant="danger"
+                  disabled={busy}
+                  onClick={() => void run({ kind: "revoke", id: token.id })}
+                >
+                  Revoke
+                </Button>
+              )}
+            </div>
+          ))
+        )}
+      </div>
+    </SectionCard>
+  );
+}
@@ -35,6 +35,7 @@ export function OrchestrationSettingsSection({
           <option value="simple">Simple</option>
           <option value="explicit">Explicit</option>
           <option value="simple-autorestart">Simple autorestart</option>
+          <option value="autoscale">Autoscale</option>
           <option value="no-instance">No instance (hub)</option>
         </Select>
         <div className="mt-2 text-[11px] leading-relaxed text-text-muted">
@@ -46,6 +47,8 @@ export function OrchestrationSettingsSection({
             "All instances managed via API. No automatic launches."}
           {backendConfig.multiInstance.strategy === "simple-autorestart" &&
             "Launches on first request and relaunches on crash."}
+          {backendConfig.multiInstance.strategy === "autoscale" &&
+            "Adds instances under load and stops idle ones, within the configured min and max."}
           {backendConfig.multiInstance.strategy === "no-instance" &&
             "No local Chrome processes. Acts as a hub for remote bridges only."}
         </div>
@@ -66,6 +69,8 @@ export function OrchestrationSettingsSection({
           <option value="fcfs">First available</option>
           <option value="round_robin">Round robin</option>
           <option value="random">Random</option>
+          <option value="least_loaded">Least loaded</option>
+          <option value="weighted">Weighted load</option>
         </Select>
       </SettingRow>
       <SettingRow
@@ -11,6 +11,7 @@ export type SectionId =
   | "orchestration"
   | "security"
   | "security-idpi"
+  | "api-tokens"
   | "profiles"
   | "network"
   | "browser"
@@ -48,6 +49,11 @@ export const sections: Array<{
     label: "Security IDPI",
     description: "Indirect prompt injection website and content defenses.",
   },
+  {
+    id: "api-tokens",
+    label: "API Tokens",
+    description: "Scoped, revocable tokens for agents and scripts.",
+  },
   {
     id: "profiles",
     label: "Profiles",
@@ -109,6 +115,11 @@ export const securityEndpointRows = [
     "Allow network interception",
     "Lets agents install rules to abort or fulfill (mock) HTTP requests on a tab. When on, response forgery is FORBIDDEN on hosts in 'Allowed websites' below and PERMITTED elsewhere. Forging responses on hosts you've authorized the agent to use (e.g. your bank) is the highest-risk outcome — that's why allowlisted hosts are protected, not the reverse. OPTIONS preflights are skipped by default to avoid breaking CORS.",
   ],
+  [
+    "allowWebAuthn",
+    "Allow WebAuthn",
+    "Lets agents attach virtual passkey authenticators to tabs and sign in with them. Credentials, private keys included, are listed by the API and stored unencrypted in the profile directory.",
+  ],
   [
     "allowFileScheme",
     "Allow file:// navigation",
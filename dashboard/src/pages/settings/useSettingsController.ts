@@ -28,6 +28,7 @@ export interface UseSettingsControllerResult {
   setElevationToken: Dispatch<SetStateAction<string>>;
   elevationError: string;
   elevating: boolean;
+  canElevateWithSso: boolean;
   hasChanges: boolean;
   restartRequired: boolean;
   restartReasons: string[];
@@ -64,6 +65,7 @@ export function useSettingsController(): UseSettingsControllerResult {
   const [elevationToken, setElevationToken] = useState("");
   const [elevationError, setElevationError] = useState("");
   const [elevating, setElevating] = useState(false);
+  const [canElevateWithSso, setCanElevateWithSso] = useState(false);
 
   useEffect(() => {
     setLocalSettings(settings);
@@ -204,6 +206,10 @@ export function useSettingsController(): UseSettingsControllerResult {
         setElevationToken("");
         setElevationError("");
         setPendingElevatedAction("save");
+        api
+          .fetchSsoIdentity()
+          .then((identity) => setCanElevateWithSso(identity?.role === "admin"))
+          .catch(() => setCanElevateWithSso(false));
         return;
       }
       setError(e instanceof Error ? e.message : "Failed to save settings");
@@ -263,6 +269,7 @@ export function useSettingsController(): UseSettingsControllerResult {
     setElevationToken,
     elevationError,
     elevating,
+    canElevateWithSso,
     hasChanges,
     restartRequired,
     restartReasons,
@@ -17,4 +17,5 @@ export * from "./api/agents";
 export * from "./api/activity";
 export * from "./api/auth";
 export * from "./api/config";
+export * from "./api/tokens";
 export * from "./api/realtime";
@@ -39,3 +39,39 @@ export async function elevate(token: string): Promise<void> {
     },
   );
 }
+
+export interface SsoIdentity {
+  issuer: string;
+  subject: string;
+  email?: string;
+  name?: string;
+  groups?: string[];
+  role: "viewer" | "operator" | "admin";
+}
+
+export async function fetchSsoStatus(): Promise<{ enabled: boolean }> {
+  return request<{ enabled: boolean }>("/api/auth/oidc", undefined, {
+    suppressAuthRedirect: true,
+  });
+}
+
+// fetchSsoIdentity returns the single sign-on identity of the current
+// dashboard session, or null for sessions opened with the API token.
+export async function fetchSsoIdentity(): Promise<SsoIdentity | null> {
+  const res = await request<{ identity: SsoIdentity | null }>(
+    "/api/auth/oidc/session",
+    undefined,
+    { suppressAuthRedirect: true },
+  );
+  return res.identity;
+}
+
+// ssoLoginUrl starts a single sign-on login. With elevate, an admin signs in
+// again to elevate the current session instead.
+export function ssoLoginUrl(next: string, elevate = false): string {
+  const params = new URLSearchParams({ next });
+  if (elevate) {
+    params.set("elevate", "1");
+  }
+  return `/api/auth/oidc/login?${params.toString()}`;
+}
@@ -29,6 +29,54 @@ export async function resumeTab(
   });
 }
 
+export interface TakeoverInfo {
+  tabId: string;
+  status: string;
+  reason: string;
+  url?: string;
+  title?: string;
+  pausedAt: string;
+  expiresAt?: string;
+  linkExpiresAt: string;
+  screencast: boolean;
+}
+
+function takeoverPath(tabId: string, token: string, suffix = ""): string {
+  return `/tabs/${encodeURIComponent(tabId)}/takeover${suffix}?t=${encodeURIComponent(token)}`;
+}
+
+// Takeover calls authenticate with the signed link token instead of a
+// session, so a 401 means the link is bad — never a reason to show login.
+export async function fetchTakeover(
+  tabId: string,
+  token: string,
+): Promise<TakeoverInfo> {
+  return request<TakeoverInfo>(takeoverPath(tabId, token), undefined, {
+    suppressAuthRedirect: true,
+  });
+}
+
+export async function resumeTakeover(
+  tabId: string,
+  token: string,
+  status: string,
+  resolvedData?: Record<string, unknown>,
+): Promise<void> {
+  await request(
+    takeoverPath(tabId, token, "/resume"),
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ status, resolvedData }),
+    },
+    { suppressAuthRedirect: true },
+  );
+}
+
+export function takeoverStreamPath(tabId: string, token: string): string {
+  return takeoverPath(tabId, token, "/stream");
+}
+
 export interface ConsoleLogEntry {
   timestamp: string;
   level: string;
@@ -0,0 +1,48 @@
+import { request } from "./client";
+
+export interface ApiTokenScope {
+  families: string[];
+  capabilities?: string[];
+  instances?: string[];
+  profiles?: string[];
+  allowedDomains?: string[];
+}
+
+export interface ApiToken {
+  id: string;
+  name: string;
+  scope: ApiTokenScope;
+  createdAt: string;
+  expiresAt?: string;
+  lastUsedAt?: string;
+  revokedAt?: string;
+  status: "active" | "revoked" | "expired";
+}
+
+export interface CreatedApiToken extends ApiToken {
+  // The secret is returned once, at creation.
+  token: string;
+}
+
+export async function fetchApiTokens(): Promise<ApiToken[]> {
+  return request<ApiToken[]>("/api/tokens");
+}
+
+export async function createApiToken(input: {
+  name: string;
+  scope: ApiTokenScope;
+  expiresAt?: string;
+}): Promise<CreatedApiToken> {
+  return request<CreatedApiToken>("/api/tokens", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function revokeApiToken(id: string): Promise<void> {
+  await request<{ status: string }>(
+    `/api/tokens/${encodeURIComponent(id)}/revoke`,
+    { method: "POST" },
+  );
+}
@@ -77,6 +77,19 @@ describe("handoffFromSystemEvent", () => {
     expect(typeof notification?.receivedAt).toBe("number");
   });
 
+  it("carries the signed takeover link", () => {
+    const notification = handoffFromSystemEvent({
+      type: "tab.handoff",
+      instance: {
+        tabId: "tab1",
+        takeoverUrl: "/dashboard/takeover?tab=tab1&t=abc",
+      } as any,
+    });
+    expect(notification?.takeoverUrl).toBe(
+      "/dashboard/takeover?tab=tab1&t=abc",
+    );
+  });
+
   it("returns null when the tab id is missing or not a string", () => {
     expect(
       handoffFromSystemEvent({ type: "tab.handoff", instance: {} as any }),
@@ -65,6 +65,8 @@ export function handoffFromSystemEvent(
     source: typeof payload.source === "string" ? payload.source : undefined,
     url: typeof payload.url === "string" ? payload.url : undefined,
     title: typeof payload.title === "string" ? payload.title : undefined,
+    takeoverUrl:
+      typeof payload.takeoverUrl === "string" ? payload.takeoverUrl : undefined,
     receivedAt: Date.now(),
   };
 }
@@ -24,6 +24,7 @@ export interface HandoffNotification {
   source?: string;
   url?: string;
   title?: string;
+  takeoverUrl?: string;
   receivedAt: number;
 }
 
@@ -151,6 +151,7 @@ export interface BackendSecurityConfig {
   allowCookies: boolean;
   allowUpload: boolean;
   allowNetworkIntercept: boolean;
+  allowWebAuthn: boolean;
   allowFileScheme: boolean;
   allowedDomains: string[];
   trustedProxyCIDRs: string[];
@@ -170,11 +171,39 @@ export interface BackendMultiInstanceConfig {
     | "explicit"
     | "simple-autorestart"
     | "always-on"
+    | "autoscale"
     | "no-instance";
-  allocationPolicy: "fcfs" | "round_robin" | "random";
+  allocationPolicy:
+    | "fcfs"
+    | "round_robin"
+    | "random"
+    | "least_loaded"
+    | "weighted";
+  allocationWeights?: BackendMultiInstanceAllocationWeights;
   instancePortStart: number;
   instancePortEnd: number;
   restart: BackendMultiInstanceRestartConfig;
+  autoscale?: BackendMultiInstanceAutoscaleConfig;
+}
+
+export interface BackendMultiInstanceAllocationWeights {
+  tabs?: number;
+  queue?: number;
+  inflight?: number;
+  latency?: number;
+  memory?: number;
+}
+
+export interface BackendMultiInstanceAutoscaleConfig {
+  profiles?: string[];
+  minInstances?: number;
+  maxInstances?: number;
+  queueDepthPerInstance?: number;
+  tabsPerInstance?: number;
+  cpuPercent?: number;
+  memoryMB?: number;
+  intervalSec?: number;
+  cooldownSec?: number;
 }
 
 export interface BackendMultiInstanceRestartConfig {
@@ -303,6 +332,7 @@ export const defaultBackendConfig: BackendConfig = {
     allowCookies: false,
     allowUpload: false,
     allowNetworkIntercept: false,
+    allowWebAuthn: false,
     allowFileScheme: false,
     allowedDomains: ["127.0.0.1", "localhost", "::1"],
     trustedProxyCIDRs: [],
@@ -41,8 +41,10 @@ type Event struct {
 	Timestamp   time.Time                 `json:"timestamp"`
 	Source      string                    `json:"source"`
 	RequestID   string                    `json:"requestId,omitempty"`
+	TraceID     string                    `json:"traceId,omitempty"`
 	SessionID   string                    `json:"sessionId,omitempty"`
 	AgentID     string                    `json:"agentId,omitempty"`
+	TokenID     string                    `json:"tokenId,omitempty"`
 	Method      string                    `json:"method"`
 	Path        string                    `json:"path"`
 	Status      int                       `json:"status"`
@@ -64,6 +66,7 @@ type Filter struct {
 	SessionID   string
 	AgentID     string
 	AgentIDLike string
+	TokenID     string
 	InstanceID  string
 	ProfileID   string
 	ProfileName string
@@ -471,6 +474,9 @@ func (f Filter) matches(evt Event) bool {
 	if f.AgentID != "" && evt.AgentID != f.AgentID {
 		return false
 	}
+	if f.TokenID != "" && evt.TokenID != f.TokenID {
+		return false
+	}
 	if f.InstanceID != "" && evt.InstanceID != f.InstanceID {
 		return false
 	}
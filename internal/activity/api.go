@@ -38,8 +38,10 @@ func RegisterHandlers(mux *http.ServeMux, rec Recorder) {
 				Timestamp:   event.Timestamp,
 				Source:      event.Source,
 				RequestID:   event.RequestID,
+				TraceID:     event.TraceID,
 				SessionID:   event.SessionID,
 				AgentID:     event.AgentID,
+				TokenID:     event.TokenID,
 				Method:      event.Method,
 				Path:        event.Path,
 				Status:      event.Status,
@@ -88,6 +90,7 @@ func filterFromRequest(r *http.Request) (Filter, error) {
 		RequestID:   strings.TrimSpace(q.Get("requestId")),
 		SessionID:   strings.TrimSpace(q.Get("sessionId")),
 		AgentID:     strings.TrimSpace(q.Get("agentId")),
+		TokenID:     strings.TrimSpace(q.Get("tokenId")),
 		InstanceID:  strings.TrimSpace(q.Get("instanceId")),
 		ProfileID:   strings.TrimSpace(q.Get("profileId")),
 		ProfileName: strings.TrimSpace(q.Get("profileName")),
@@ -13,6 +13,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/authn"
 	"github.com/pinchtab/pinchtab/internal/browserops"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 const (
@@ -23,6 +24,7 @@ const (
 	HeaderPTProfileID = "X-PinchTab-Profile-Id"
 	HeaderPTProfile   = "X-PinchTab-Profile-Name"
 	HeaderPTTabID     = "X-PinchTab-Tab-Id"
+	HeaderPTTokenID   = "X-PinchTab-Token-Id"
 )
 
 type requestStateKey struct{}
@@ -36,6 +38,7 @@ type Update struct {
 	RequestID   string
 	SessionID   string
 	AgentID     string
+	TokenID     string
 	InstanceID  string
 	ProfileID   string
 	ProfileName string
@@ -59,8 +62,10 @@ func Middleware(rec Recorder, source string, next http.Handler) http.Handler {
 				Timestamp:  start.UTC(),
 				Source:     sourceFor(r, source),
 				RequestID:  requestIDFor(r, w),
+				TraceID:    tracing.TraceIDFromContext(r.Context()),
 				AgentID:    agentIDFor(r),
 				SessionID:  strings.TrimSpace(r.Header.Get(HeaderPTSessionID)),
+				TokenID:    strings.TrimSpace(r.Header.Get(HeaderPTTokenID)),
 				Method:     r.Method,
 				Path:       r.URL.Path,
 				RemoteAddr: remoteAddrFor(r),
@@ -131,6 +136,9 @@ func EnrichRequest(r *http.Request, update Update) {
 	if update.AgentID != "" {
 		state.event.AgentID = update.AgentID
 	}
+	if update.TokenID != "" {
+		state.event.TokenID = update.TokenID
+	}
 	if update.InstanceID != "" {
 		state.event.InstanceID = update.InstanceID
 	}
@@ -176,6 +184,9 @@ func PropagateHeaders(ctx context.Context, req *http.Request) {
 	if evt.SessionID != "" {
 		req.Header.Set(HeaderPTSessionID, evt.SessionID)
 	}
+	if evt.TokenID != "" {
+		req.Header.Set(HeaderPTTokenID, evt.TokenID)
+	}
 	if evt.InstanceID != "" {
 		req.Header.Set(HeaderPTInstance, evt.InstanceID)
 	}
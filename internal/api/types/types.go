@@ -103,8 +103,10 @@ type ActivityLogEvent struct {
 	Timestamp   time.Time      `json:"timestamp"`
 	Source      string         `json:"source"`
 	RequestID   string         `json:"requestId,omitempty"`
+	TraceID     string         `json:"traceId,omitempty"`
 	SessionID   string         `json:"sessionId,omitempty"`
 	AgentID     string         `json:"agentId,omitempty"`
+	TokenID     string         `json:"tokenId,omitempty"`
 	Method      string         `json:"method"`
 	Path        string         `json:"path"`
 	Status      int            `json:"status"`
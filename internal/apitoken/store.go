@@ -0,0 +1,344 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usePersistInterval bounds how often a LastUsedAt-only update is flushed to
+// disk; the in-memory value is always current.
+const usePersistInterval = 30 * time.Second
+
+// Store keeps API tokens keyed by id, with a hash index for authentication.
+// Revoked and expired tokens stay listed so their activity can still be
+// attributed.
+type Store struct {
+	mu          sync.Mutex
+	tokens      map[string]*Token
+	byTokenHash map[[32]byte]*Token
+	persistPath string
+	now         func() time.Time
+	lastUseSave time.Time
+
+	// Snapshots are built under mu and written under saveMu, as in the
+	// session store, so authentication never waits on disk I/O.
+	saveMu     sync.Mutex
+	saveSeq    uint64 // guarded by mu
+	writtenSeq uint64 // guarded by saveMu
+}
+
+// NewStore creates a store persisted at persistPath, or in memory only when
+// persistPath is empty.
+func NewStore(persistPath string) *Store {
+	s := &Store{
+		tokens:      make(map[string]*Token),
+		byTokenHash: make(map[[32]byte]*Token),
+		persistPath: persistPath,
+		now:         time.Now,
+	}
+	s.loadPersisted()
+	return s
+}
+
+// Create adds a token and returns it with the plaintext secret, which is
+// never stored. A zero expiresAt never expires.
+func (s *Store) Create(name string, scope Scope, expiresAt time.Time) (*Token, string, error) {
+	if s == nil {
+		return nil, "", fmt.Errorf("store is nil")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("name is required")
+	}
+	scope, err := NormalizeScope(scope)
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.now()
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return nil, "", fmt.Errorf("expiresAt must be in the future")
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := TokenPrefix + secret
+	tok := &Token{
+		ID:        "tok_" + id,
+		Name:      name,
+		TokenHash: hashToken(plaintext),
+		Scope:     scope,
+		CreatedAt: now,
+		ExpiresAt: expiresAt.UTC(),
+		Status:    StatusActive,
+	}
+
+	s.mu.Lock()
+	for _, existing := range s.tokens {
+		if existing.Status == StatusActive && strings.EqualFold(existing.Name, name) {
+			s.mu.Unlock()
+			return nil, "", fmt.Errorf("an active token named %q already exists", name)
+		}
+	}
+	s.tokens[tok.ID] = tok
+	s.byTokenHash[tok.TokenHash] = tok
+	job, persist := s.snapshotLocked()
+	cp := tok.clone()
+	s.mu.Unlock()
+	if persist {
+		s.writeSnapshot(job)
+	}
+	return cp, plaintext, nil
+}
+
+// Authenticate returns the active token matching plaintext and records its
+// use. Expired tokens are marked so and rejected.
+func (s *Store) Authenticate(plaintext string) (*Token, bool) {
+	if s == nil {
+		return nil, false
+	}
+	plaintext = strings.TrimSpace(plaintext)
+	if !strings.HasPrefix(plaintext, TokenPrefix) {
+		return nil, false
+	}
+	hash := hashToken(plaintext)
+	now := s.now()
+
+	s.mu.Lock()
+	tok, ok := s.byTokenHash[hash]
+	if !ok || tok.Status != StatusActive || subtle.ConstantTimeCompare(hash[:], tok.TokenHash[:]) != 1 {
+		s.mu.Unlock()
+		return nil, false
+	}
+	var (
+		job     snapshotJob
+		persist bool
+	)
+	if tok.Expired(now) {
+		tok.Status = StatusExpired
+		job, persist = s.snapshotLocked()
+		s.mu.Unlock()
+		if persist {
+			s.writeSnapshot(job)
+		}
+		return nil, false
+	}
+	tok.LastUsedAt = now
+	if now.Sub(s.lastUseSave) >= usePersistInterval {
+		s.lastUseSave = now
+		job, persist = s.snapshotLocked()
+	}
+	cp := tok.clone()
+	s.mu.Unlock()
+	if persist {
+		s.writeSnapshot(job)
+	}
+	return cp, true
+}
+
+// Get returns a copy of the token with id.
+func (s *Store) Get(id string) (*Token, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.tokens[strings.TrimSpace(id)]
+	if !ok {
+		return nil, false
+	}
+	s.refreshStatusLocked(tok)
+	return tok.clone(), true
+}
+
+// List returns copies of every token, newest first.
+func (s *Store) List() []Token {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	out := make([]Token, 0, len(s.tokens))
+	for _, tok := range s.tokens {
+		s.refreshStatusLocked(tok)
+		out = append(out, *tok.clone())
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out
+}
+
+// Revoke stops the token from authenticating. Revoking twice is a no-op;
+// it returns false only for an unknown id.
+func (s *Store) Revoke(id string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	tok, ok := s.tokens[strings.TrimSpace(id)]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	if tok.Status == StatusRevoked {
+		s.mu.Unlock()
+		return true
+	}
+	tok.Status = StatusRevoked
+	tok.RevokedAt = s.now().UTC()
+	job, persist := s.snapshotLocked()
+	s.mu.Unlock()
+	if persist {
+		s.writeSnapshot(job)
+	}
+	return true
+}
+
+// refreshStatusLocked reports an active token past its expiry as expired.
+// Caller must hold s.mu.
+func (s *Store) refreshStatusLocked(tok *Token) {
+	if tok.Status == StatusActive && tok.Expired(s.now()) {
+		tok.Status = StatusExpired
+	}
+}
+
+func (t *Token) clone() *Token {
+	cp := *t
+	cp.Scope = Scope{
+		Families:       append([]string(nil), t.Scope.Families...),
+		Capabilities:   append([]string(nil), t.Scope.Capabilities...),
+		Instances:      append([]string(nil), t.Scope.Instances...),
+		Profiles:       append([]string(nil), t.Scope.Profiles...),
+		AllowedDomains: append([]string(nil), t.Scope.AllowedDomains...),
+	}
+	return &cp
+}
+
+type persistedStore struct {
+	SavedAt time.Time        `json:"savedAt"`
+	Tokens  []persistedToken `json:"tokens"`
+}
+
+type persistedToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	TokenHash  string    `json:"tokenHash"`
+	Scope      Scope     `json:"scope"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  time.Time `json:"revokedAt,omitempty"`
+	Status     string    `json:"status"`
+}
+
+func (s *Store) loadPersisted() {
+	if s.persistPath == "" {
+		return
+	}
+	data, err := os.ReadFile(s.persistPath)
+	if err != nil {
+		return
+	}
+	var persisted persistedStore
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return
+	}
+	for _, rec := range persisted.Tokens {
+		hash, err := hex.DecodeString(strings.TrimSpace(rec.TokenHash))
+		if err != nil || len(hash) != sha256.Size || rec.ID == "" {
+			continue
+		}
+		tok := &Token{
+			ID:         rec.ID,
+			Name:       rec.Name,
+			Scope:      rec.Scope,
+			CreatedAt:  rec.CreatedAt,
+			ExpiresAt:  rec.ExpiresAt,
+			LastUsedAt: rec.LastUsedAt,
+			RevokedAt:  rec.RevokedAt,
+			Status:     rec.Status,
+		}
+		copy(tok.TokenHash[:], hash)
+		s.tokens[tok.ID] = tok
+		s.byTokenHash[tok.TokenHash] = tok
+	}
+}
+
+type snapshotJob struct {
+	snapshot persistedStore
+	seq      uint64
+}
+
+// snapshotLocked copies every token for writing outside s.mu. Caller must
+// hold s.mu; ok=false when the store is not persisted.
+func (s *Store) snapshotLocked() (snapshotJob, bool) {
+	if s.persistPath == "" {
+		return snapshotJob{}, false
+	}
+	s.saveSeq++
+	snapshot := persistedStore{SavedAt: s.now().UTC(), Tokens: make([]persistedToken, 0, len(s.tokens))}
+	for _, tok := range s.tokens {
+		cp := tok.clone()
+		snapshot.Tokens = append(snapshot.Tokens, persistedToken{
+			ID:         cp.ID,
+			Name:       cp.Name,
+			TokenHash:  hex.EncodeToString(cp.TokenHash[:]),
+			Scope:      cp.Scope,
+			CreatedAt:  cp.CreatedAt,
+			ExpiresAt:  cp.ExpiresAt,
+			LastUsedAt: cp.LastUsedAt,
+			RevokedAt:  cp.RevokedAt,
+			Status:     cp.Status,
+		})
+	}
+	return snapshotJob{snapshot: snapshot, seq: s.saveSeq}, true
+}
+
+// writeSnapshot writes job atomically, skipping it when a newer snapshot is
+// already on disk.
+func (s *Store) writeSnapshot(job snapshotJob) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if job.seq <= s.writtenSeq {
+		return
+	}
+	s.writtenSeq = job.seq
+
+	data, err := json.MarshalIndent(job.snapshot, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.persistPath), 0755); err != nil {
+		return
+	}
+	tmpPath := s.persistPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return
+	}
+	_ = os.Rename(tmpPath, s.persistPath)
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) [32]byte {
+	return sha256.Sum256([]byte(strings.TrimSpace(token)))
+}
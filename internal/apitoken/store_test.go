@@ -0,0 +1,96 @@
+package apitoken
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreCreateAuthenticateRevoke(t *testing.T) {
+	s := NewStore("")
+	tok, plaintext, err := s.Create("ci", Scope{Families: []string{"Browse", "browse"}}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(plaintext, TokenPrefix) || !strings.HasPrefix(tok.ID, "tok_") {
+		t.Fatalf("token = %q, id = %q", plaintext, tok.ID)
+	}
+	if len(tok.Scope.Families) != 1 || tok.Scope.Families[0] != FamilyBrowse {
+		t.Fatalf("families = %v, want [browse]", tok.Scope.Families)
+	}
+
+	got, ok := s.Authenticate(plaintext)
+	if !ok || got.ID != tok.ID || got.LastUsedAt.IsZero() {
+		t.Fatalf("Authenticate = %+v, %v", got, ok)
+	}
+	if _, ok := s.Authenticate(plaintext + "x"); ok {
+		t.Fatal("a wrong secret authenticated")
+	}
+	if _, _, err := s.Create("CI", Scope{Families: []string{"*"}}, time.Time{}); err == nil {
+		t.Fatal("a second active token with the same name was created")
+	}
+
+	if !s.Revoke(tok.ID) || !s.Revoke(tok.ID) {
+		t.Fatal("Revoke should succeed and be idempotent")
+	}
+	if _, ok := s.Authenticate(plaintext); ok {
+		t.Fatal("a revoked token authenticated")
+	}
+	if got, _ := s.Get(tok.ID); got.Status != StatusRevoked || got.RevokedAt.IsZero() {
+		t.Fatalf("revoked token = %+v", got)
+	}
+	if s.Revoke("tok_missing") {
+		t.Fatal("Revoke of an unknown id should fail")
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	s := NewStore("")
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	if _, _, err := s.Create("old", Scope{Families: []string{"browse"}}, now.Add(-time.Minute)); err == nil {
+		t.Fatal("a token expiring in the past was created")
+	}
+	tok, plaintext, err := s.Create("short", Scope{Families: []string{"browse"}}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, ok := s.Authenticate(plaintext); ok {
+		t.Fatal("an expired token authenticated")
+	}
+	if got, _ := s.Get(tok.ID); got.Status != StatusExpired {
+		t.Fatalf("status = %q, want expired", got.Status)
+	}
+}
+
+func TestStorePersistsHashesOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-tokens.json")
+	s := NewStore(path)
+	tok, plaintext, err := s.Create("ci", Scope{Families: []string{"browse"}, AllowedDomains: []string{"Example.com"}}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), plaintext) {
+		t.Fatal("the plaintext token was written to disk")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("file mode = %v, %v", info.Mode().Perm(), err)
+	}
+
+	reloaded := NewStore(path)
+	got, ok := reloaded.Authenticate(plaintext)
+	if !ok || got.ID != tok.ID || got.Scope.AllowedDomains[0] != "example.com" {
+		t.Fatalf("reloaded Authenticate = %+v, %v", got, ok)
+	}
+	if list := reloaded.List(); len(list) != 1 || list[0].Name != "ci" {
+		t.Fatalf("List = %+v", list)
+	}
+}
@@ -0,0 +1,228 @@
+// Package apitoken provides named, scoped API tokens. Where server.token
+// grants everything, an API token is limited to endpoint families,
+// capability gates, instances or profiles, and URL domains. Tokens expire,
+// can be revoked, and are stored only as hashes.
+package apitoken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/routes"
+	"github.com/pinchtab/pinchtab/internal/security"
+)
+
+// TokenPrefix starts every API token, so the auth layer can tell one from
+// the server token without a lookup.
+const TokenPrefix = "ptk_"
+
+const (
+	StatusActive  = "active"
+	StatusRevoked = "revoked"
+	StatusExpired = "expired"
+)
+
+// Endpoint families a token may be scoped to. All but "instances" match the
+// agent session grants of the same name; "instances" covers instance and
+// profile management under /instances and /profiles.
+const (
+	FamilyBrowse    = "browse"
+	FamilyNetwork   = "network"
+	FamilyMedia     = "media"
+	FamilyCookies   = "cookies"
+	FamilyClipboard = "clipboard"
+	FamilyEvaluate  = "evaluate"
+	FamilyStorage   = "storage"
+	FamilyConsole   = "console"
+	FamilySolve     = "solve"
+	FamilyTasks     = "tasks"
+	FamilyActivity  = "activity"
+	FamilyInstances = "instances"
+	// FamilyAll grants every family above.
+	FamilyAll = "*"
+)
+
+// Families lists the endpoint families in display order.
+var Families = []string{
+	FamilyBrowse, FamilyNetwork, FamilyMedia, FamilyCookies, FamilyClipboard, FamilyEvaluate,
+	FamilyStorage, FamilyConsole, FamilySolve, FamilyTasks, FamilyActivity, FamilyInstances,
+}
+
+// Scope is what a token may do. Empty Capabilities allows no capability
+// gated endpoint; empty Instances and Profiles allow every instance; empty
+// AllowedDomains allows every URL.
+type Scope struct {
+	Families     []string `json:"families"`
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Instances and Profiles restrict the instances the token reaches. An
+	// instance is allowed when its id is in Instances or its profile (id or
+	// name) is in Profiles.
+	Instances      []string `json:"instances,omitempty"`
+	Profiles       []string `json:"profiles,omitempty"`
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+}
+
+// Token is a named API token. The plaintext is shown once at creation.
+type Token struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	TokenHash  [32]byte  `json:"-"`
+	Scope      Scope     `json:"scope"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  time.Time `json:"revokedAt,omitempty"`
+	Status     string    `json:"status"`
+}
+
+// NormalizeScope trims, lowercases and de-duplicates scope entries and
+// rejects unknown families and capabilities. Tasks run later with the
+// scheduler's own credentials, so the tasks family cannot be combined with
+// instance or domain restrictions it would bypass; "*" leaves it out of such
+// a scope.
+func NormalizeScope(s Scope) (Scope, error) {
+	var out Scope
+	for _, f := range s.Families {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if f != FamilyAll && !slices.Contains(Families, f) {
+			return Scope{}, fmt.Errorf("unknown family %q", f)
+		}
+		out.Families = appendUnique(out.Families, f)
+	}
+	if len(out.Families) == 0 {
+		return Scope{}, fmt.Errorf("at least one family is required")
+	}
+	for _, c := range s.Capabilities {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		capability, ok := routes.ParseCapability(c)
+		if !ok || capability == routes.CapNone {
+			return Scope{}, fmt.Errorf("unknown capability %q", c)
+		}
+		out.Capabilities = appendUnique(out.Capabilities, string(capability))
+	}
+	for _, id := range s.Instances {
+		if id = strings.TrimSpace(id); id != "" {
+			out.Instances = appendUnique(out.Instances, id)
+		}
+	}
+	for _, p := range s.Profiles {
+		if p = strings.TrimSpace(p); p != "" {
+			out.Profiles = appendUnique(out.Profiles, p)
+		}
+	}
+	for _, d := range s.AllowedDomains {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			out.AllowedDomains = appendUnique(out.AllowedDomains, d)
+		}
+	}
+	if slices.Contains(out.Families, FamilyTasks) && out.restricted() {
+		return Scope{}, fmt.Errorf("the tasks family cannot be combined with instance, profile or domain restrictions")
+	}
+	return out, nil
+}
+
+func appendUnique(list []string, v string) []string {
+	if slices.Contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+func (s Scope) allowsFamily(family string) bool {
+	if slices.Contains(s.Families, family) {
+		return true
+	}
+	if !slices.Contains(s.Families, FamilyAll) {
+		return false
+	}
+	return family != FamilyTasks || !s.restricted()
+}
+
+func (s Scope) restricted() bool {
+	return s.InstanceRestricted() || len(s.AllowedDomains) > 0
+}
+
+// AllowsFamily reports whether the token may call endpoints of family.
+func (t *Token) AllowsFamily(family string) bool {
+	return t != nil && t.Scope.allowsFamily(family)
+}
+
+// AllowsCapability reports whether the token may call endpoints behind the
+// capability gate. Ungated endpoints need no capability.
+func (t *Token) AllowsCapability(c routes.Capability) bool {
+	if c == routes.CapNone {
+		return true
+	}
+	return t != nil && slices.Contains(t.Scope.Capabilities, string(c))
+}
+
+// InstanceRestricted reports whether the scope limits instances.
+func (s Scope) InstanceRestricted() bool {
+	return len(s.Instances) > 0 || len(s.Profiles) > 0
+}
+
+// AllowsInstance reports whether the token may reach the instance id whose
+// profile goes by any of profiles (id, name, ...).
+func (t *Token) AllowsInstance(id string, profiles ...string) bool {
+	if t == nil || !t.Scope.InstanceRestricted() {
+		return true
+	}
+	if id != "" && slices.Contains(t.Scope.Instances, id) {
+		return true
+	}
+	return slices.ContainsFunc(profiles, t.AllowsProfile)
+}
+
+// AllowsProfile reports whether the token's profile list names profile.
+// A token restricted to instances only allows no profile.
+func (t *Token) AllowsProfile(profile string) bool {
+	if t == nil || !t.Scope.InstanceRestricted() {
+		return true
+	}
+	return profile != "" && slices.Contains(t.Scope.Profiles, profile)
+}
+
+// AllowsURL reports whether rawURL is within the token's allowed domains.
+func (t *Token) AllowsURL(rawURL string) bool {
+	return t == nil || URLAllowed(rawURL, t.Scope.AllowedDomains)
+}
+
+// URLAllowed reports whether rawURL's host matches domains, with the same
+// patterns as security.allowedDomains. An empty list allows every URL.
+func URLAllowed(rawURL string, domains []string) bool {
+	return security.HostAllowed(rawURL, domains)
+}
+
+// Expired reports whether the token is past its expiry at now.
+func (t *Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
+
+type contextKey struct{}
+
+// WithToken stores the authenticated token on the request context.
+func WithToken(r *http.Request, tok *Token) *http.Request {
+	if r == nil || tok == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, tok))
+}
+
+// FromRequest returns the API token that authenticated the request.
+func FromRequest(r *http.Request) (*Token, bool) {
+	if r == nil {
+		return nil, false
+	}
+	tok, ok := r.Context().Value(contextKey{}).(*Token)
+	return tok, ok && tok != nil
+}
@@ -0,0 +1,62 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/routes"
+)
+
+func TestNormalizeScope(t *testing.T) {
+	if _, err := NormalizeScope(Scope{}); err == nil {
+		t.Error("a scope without families was accepted")
+	}
+	if _, err := NormalizeScope(Scope{Families: []string{"shell"}}); err == nil {
+		t.Error("an unknown family was accepted")
+	}
+	if _, err := NormalizeScope(Scope{Families: []string{"browse"}, Capabilities: []string{"root"}}); err == nil {
+		t.Error("an unknown capability was accepted")
+	}
+	if _, err := NormalizeScope(Scope{Families: []string{"tasks"}, AllowedDomains: []string{"example.com"}}); err == nil {
+		t.Error("tasks with a domain restriction was accepted")
+	}
+
+	s, err := NormalizeScope(Scope{Families: []string{"*"}, Capabilities: []string{"Evaluate", "evaluate"}, Profiles: []string{" work "}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Capabilities) != 1 || s.Capabilities[0] != "evaluate" || s.Profiles[0] != "work" {
+		t.Fatalf("scope = %+v", s)
+	}
+	tok := &Token{Scope: s}
+	if tok.AllowsFamily(FamilyTasks) {
+		t.Error(`"*" should leave tasks out of a profile-restricted scope`)
+	}
+	if !tok.AllowsFamily(FamilyInstances) {
+		t.Error(`"*" should grant the instances family`)
+	}
+}
+
+func TestTokenAllows(t *testing.T) {
+	tok := &Token{Scope: Scope{
+		Families:       []string{FamilyBrowse},
+		Capabilities:   []string{string(routes.CapEvaluate)},
+		Instances:      []string{"inst_1"},
+		Profiles:       []string{"work"},
+		AllowedDomains: []string{"*.example.com"},
+	}}
+
+	if !tok.AllowsCapability(routes.CapNone) || !tok.AllowsCapability(routes.CapEvaluate) || tok.AllowsCapability(routes.CapCookies) {
+		t.Error("capability checks are wrong")
+	}
+	if !tok.AllowsInstance("inst_1") || !tok.AllowsInstance("inst_2", "prof_9", "work") || tok.AllowsInstance("inst_2", "other") {
+		t.Error("instance checks are wrong")
+	}
+	if !tok.AllowsURL("https://app.example.com/x") || tok.AllowsURL("https://example.org/") || !tok.AllowsURL("about:blank") {
+		t.Error("domain checks are wrong")
+	}
+
+	open := &Token{Scope: Scope{Families: []string{FamilyBrowse}}}
+	if !open.AllowsInstance("anything") || !open.AllowsURL("https://anywhere.test/") {
+		t.Error("an unrestricted token should allow every instance and URL")
+	}
+}
@@ -18,3 +18,12 @@ var ScreencastRepaintStartJS string
 
 //go:embed screencast_repaint_stop.js
 var ScreencastRepaintStopJS string
+
+//go:embed stream_route.js
+var StreamRouteJS string
+
+//go:embed permission_watch.js
+var PermissionWatchJS string
+
+//go:embed site_data.js
+var SiteDataJS string
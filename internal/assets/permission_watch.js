@@ -0,0 +1,120 @@
+// Permission request watcher. Records every call a page makes to an API that
+// can raise a browser permission prompt, so the tab state can show an agent
+// that the page is waiting on one. Installed as an init script; the bridge
+// reads the log back with
+//
+//   window.__pinchtabPermissionRequests.requests
+//
+// Each entry is {name, state, at}: name is the Permissions API name, state is
+// "pending" until the call settles and then "granted", "denied" or
+// "dismissed", and at is the ISO time of the request. Wrappers are Proxies so
+// the patched functions keep their native toString.
+(function () {
+  const KEY = '__pinchtabPermissionRequests';
+  if (window[KEY]) return;
+  const MAX = 50;
+  const state = { requests: [] };
+  Object.defineProperty(window, KEY, { value: state, configurable: false, enumerable: false });
+
+  function record(name) {
+    const entry = { name: name, state: 'pending', at: new Date().toISOString() };
+    state.requests.push(entry);
+    if (state.requests.length > MAX) state.requests.shift();
+    return entry;
+  }
+
+  function settle(entry, next) {
+    if (entry.state === 'pending') entry.state = next;
+  }
+
+  function deniedState(err) {
+    return err && (err.name === 'NotAllowedError' || err.name === 'SecurityError') ? 'denied' : 'dismissed';
+  }
+
+  function wrap(owner, prop, handler) {
+    if (!owner) return;
+    const original = owner[prop];
+    if (typeof original !== 'function') return;
+    try {
+      owner[prop] = new Proxy(original, {
+        apply(target, thisArg, args) {
+          return handler(target, thisArg, args || []);
+        },
+      });
+    } catch (e) {}
+  }
+
+  // Promise-returning APIs: the settled promise tells us the outcome.
+  function wrapPromise(owner, prop, nameOf, grantedOf) {
+    wrap(owner, prop, function (target, thisArg, args) {
+      const names = nameOf(args);
+      const entries = names.map(record);
+      let result;
+      try {
+        result = Reflect.apply(target, thisArg, args);
+      } catch (err) {
+        entries.forEach((e) => settle(e, deniedState(err)));
+        throw err;
+      }
+      if (result && typeof result.then === 'function') {
+        result.then(
+          (value) => entries.forEach((e) => settle(e, grantedOf ? grantedOf(value) : 'granted')),
+          (err) => entries.forEach((e) => settle(e, deniedState(err))),
+        );
+      }
+      return result;
+    });
+  }
+
+  if (typeof Notification === 'function') {
+    wrapPromise(Notification, 'requestPermission', () => ['notifications'], (value) => {
+      if (value === 'granted') return 'granted';
+      return value === 'denied' ? 'denied' : 'dismissed';
+    });
+  }
+
+  if (typeof MediaDevices === 'function') {
+    wrapPromise(MediaDevices.prototype, 'getUserMedia', (args) => {
+      const c = args[0] || {};
+      const names = [];
+      if (c.video) names.push('camera');
+      if (c.audio) names.push('microphone');
+      return names;
+    });
+  }
+
+  if (typeof Clipboard === 'function') {
+    wrapPromise(Clipboard.prototype, 'read', () => ['clipboard-read']);
+    wrapPromise(Clipboard.prototype, 'readText', () => ['clipboard-read']);
+  }
+
+  if (typeof Navigator === 'function') {
+    wrapPromise(Navigator.prototype, 'requestMIDIAccess', (args) => [
+      args[0] && args[0].sysex ? 'midi-sysex' : 'midi',
+    ]);
+  }
+
+  // Geolocation reports through callbacks: the first success or error
+  // settles the request.
+  if (typeof Geolocation === 'function') {
+    const geoHandler = function (target, thisArg, args) {
+      const success = args[0];
+      const failure = args[1];
+      // Let the native call reject bad arguments exactly as it would.
+      if (typeof success !== 'function') return Reflect.apply(target, thisArg, args);
+      const entry = record('geolocation');
+      const next = args.slice();
+      next[0] = function () {
+        settle(entry, 'granted');
+        return Reflect.apply(success, this, arguments);
+      };
+      next[1] = function (err) {
+        settle(entry, err && err.code === 1 ? 'denied' : 'dismissed');
+        if (typeof failure === 'function') return Reflect.apply(failure, this, arguments);
+      };
+      return Reflect.apply(target, thisArg, next);
+    };
+    wrap(Geolocation.prototype, 'getCurrentPosition', geoHandler);
+    wrap(Geolocation.prototype, 'watchPosition', geoHandler);
+  }
+})();
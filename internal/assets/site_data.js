@@ -0,0 +1,338 @@
+// Site data operations that have no CDP equivalent usable from a page target:
+// IndexedDB listing, export and import, and restoring Cache Storage entries
+// and service worker registrations from saved state. The handlers evaluate
+//
+//   (<this function>)(op, arg)
+//
+// with awaitPromise and get back a JSON string. Everything runs in the page,
+// so it only ever touches the current origin.
+//
+// IndexedDB values are structured-clone values, richer than JSON. They are
+// encoded with tagged objects: {$date}, {$bytes, $type} for ArrayBuffers and
+// typed arrays, {$blob, $type, $name}, {$map}, {$set}, {$bigint}, {$number}
+// for NaN and infinities, {$regexp, $flags} and {$undefined}. A plain object
+// that has its own "$"-prefixed key is wrapped as {$object} so it can never be
+// mistaken for a tag.
+(async function (op, arg) {
+  arg = arg || {};
+
+  function toBase64(bytes) {
+    let s = '';
+    for (let i = 0; i < bytes.length; i += 0x8000) {
+      s += String.fromCharCode.apply(null, bytes.subarray(i, i + 0x8000));
+    }
+    return btoa(s);
+  }
+
+  function fromBase64(b64) {
+    const s = atob(b64 || '');
+    const bytes = new Uint8Array(s.length);
+    for (let i = 0; i < s.length; i++) bytes[i] = s.charCodeAt(i);
+    return bytes;
+  }
+
+  async function encode(v) {
+    if (v === null || typeof v === 'string' || typeof v === 'boolean') return v;
+    if (v === undefined) return { $undefined: true };
+    if (typeof v === 'number') return isFinite(v) ? v : { $number: String(v) };
+    if (typeof v === 'bigint') return { $bigint: v.toString() };
+    if (v instanceof Date) return { $date: isNaN(v.getTime()) ? null : v.toISOString() };
+    if (v instanceof ArrayBuffer) return { $bytes: toBase64(new Uint8Array(v)), $type: 'ArrayBuffer' };
+    if (ArrayBuffer.isView(v)) {
+      return { $bytes: toBase64(new Uint8Array(v.buffer, v.byteOffset, v.byteLength)), $type: v.constructor.name };
+    }
+    if (typeof Blob !== 'undefined' && v instanceof Blob) {
+      const out = { $blob: toBase64(new Uint8Array(await v.arrayBuffer())), $type: v.type };
+      if (typeof File !== 'undefined' && v instanceof File) out.$name = v.name;
+      return out;
+    }
+    if (v instanceof Map) {
+      const entries = [];
+      for (const [k, val] of v) entries.push([await encode(k), await encode(val)]);
+      return { $map: entries };
+    }
+    if (v instanceof Set) {
+      const items = [];
+      for (const item of v) items.push(await encode(item));
+      return { $set: items };
+    }
+    if (v instanceof RegExp) return { $regexp: v.source, $flags: v.flags };
+    if (Array.isArray(v)) {
+      const out = [];
+      for (const item of v) out.push(await encode(item));
+      return out;
+    }
+    const out = {};
+    let tagged = false;
+    for (const k of Object.keys(v)) {
+      if (k.charAt(0) === '$') tagged = true;
+      out[k] = await encode(v[k]);
+    }
+    return tagged ? { $object: out } : out;
+  }
+
+  function decode(v) {
+    if (v === null || typeof v !== 'object') return v;
+    if (Array.isArray(v)) return v.map(decode);
+    if ('$undefined' in v) return undefined;
+    if ('$number' in v) return Number(v.$number);
+    if ('$bigint' in v) return BigInt(v.$bigint);
+    if ('$date' in v) return new Date(v.$date === null ? NaN : v.$date);
+    if ('$bytes' in v) {
+      const bytes = fromBase64(v.$bytes);
+      if (v.$type === 'ArrayBuffer') return bytes.buffer;
+      if (v.$type === 'DataView') return new DataView(bytes.buffer);
+      const Ctor = typeof globalThis[v.$type] === 'function' ? globalThis[v.$type] : Uint8Array;
+      return new Ctor(bytes.buffer, 0, bytes.byteLength / (Ctor.BYTES_PER_ELEMENT || 1));
+    }
+    if ('$blob' in v) {
+      const bytes = fromBase64(v.$blob);
+      if (v.$name !== undefined) return new File([bytes], v.$name, { type: v.$type || '' });
+      return new Blob([bytes], { type: v.$type || '' });
+    }
+    if ('$map' in v) return new Map(v.$map.map(function (e) { return [decode(e[0]), decode(e[1])]; }));
+    if ('$set' in v) return new Set(v.$set.map(decode));
+    if ('$regexp' in v) return new RegExp(v.$regexp, v.$flags || '');
+    const src = '$object' in v ? v.$object : v;
+    const out = {};
+    for (const k of Object.keys(src)) out[k] = decode(src[k]);
+    return out;
+  }
+
+  function promisify(req) {
+    return new Promise(function (resolve, reject) {
+      req.onsuccess = function () { resolve(req.result); };
+      req.onerror = function () { reject(req.error); };
+    });
+  }
+
+  function done(tx) {
+    return new Promise(function (resolve, reject) {
+      tx.oncomplete = function () { resolve(); };
+      tx.onabort = tx.onerror = function () { reject(tx.error || new Error('transaction aborted')); };
+    });
+  }
+
+  function openDB(name, version, upgrade) {
+    return new Promise(function (resolve, reject) {
+      const req = version ? indexedDB.open(name, version) : indexedDB.open(name);
+      req.onupgradeneeded = function () { if (upgrade) upgrade(req.result, req.transaction); };
+      req.onsuccess = function () { resolve(req.result); };
+      req.onerror = function () { reject(req.error); };
+      req.onblocked = function () { reject(new Error('database ' + name + ' is open elsewhere with an older version')); };
+    });
+  }
+
+  function describeStore(store) {
+    const indexes = [];
+    for (const name of Array.from(store.indexNames)) {
+      const idx = store.index(name);
+      indexes.push({ name: name, keyPath: idx.keyPath, unique: idx.unique, multiEntry: idx.multiEntry });
+    }
+    return { name: store.name, keyPath: store.keyPath, autoIncrement: store.autoIncrement, indexes: indexes };
+  }
+
+  async function databaseNames() {
+    const dbs = await indexedDB.databases();
+    return dbs.filter(function (d) { return d.name; });
+  }
+
+  async function listIndexedDB() {
+    const out = [];
+    for (const info of await databaseNames()) {
+      if (arg.database && info.name !== arg.database) continue;
+      const db = await openDB(info.name);
+      try {
+        const stores = [];
+        for (const name of Array.from(db.objectStoreNames)) {
+          const store = db.transaction(name, 'readonly').objectStore(name);
+          const desc = describeStore(store);
+          desc.count = await promisify(store.count());
+          stores.push(desc);
+        }
+        out.push({ name: db.name, version: db.version, objectStores: stores });
+      } finally {
+        db.close();
+      }
+    }
+    return out;
+  }
+
+  async function exportIndexedDB() {
+    const limit = arg.limit > 0 ? arg.limit : 0;
+    let budget = arg.maxBytes > 0 ? arg.maxBytes : Infinity;
+    let truncated = false;
+    const out = [];
+    for (const info of await databaseNames()) {
+      if (arg.database && info.name !== arg.database) continue;
+      const db = await openDB(info.name);
+      try {
+        const stores = [];
+        for (const name of Array.from(db.objectStoreNames)) {
+          if (arg.store && name !== arg.store) continue;
+          const store = db.transaction(name, 'readonly').objectStore(name);
+          const desc = describeStore(store);
+          desc.count = await promisify(store.count());
+          // Keys and values are read in the same transaction and come back in
+          // the same (key) order; encoding happens afterwards because reading
+          // blobs would let the transaction commit.
+          const keys = await promisify(limit ? store.getAllKeys(null, limit) : store.getAllKeys());
+          const values = await promisify(limit ? store.getAll(null, limit) : store.getAll());
+          desc.records = [];
+          for (let i = 0; i < keys.length; i++) {
+            const rec = { key: await encode(keys[i]), value: await encode(values[i]) };
+            const size = JSON.stringify(rec).length;
+            if (size > budget) {
+              truncated = true;
+              break;
+            }
+            budget -= size;
+            desc.records.push(rec);
+          }
+          desc.truncated = desc.records.length < desc.count;
+          if (desc.truncated) truncated = true;
+          stores.push(desc);
+        }
+        out.push({ name: db.name, version: db.version, objectStores: stores });
+      } finally {
+        db.close();
+      }
+    }
+    return { databases: out, truncated: truncated };
+  }
+
+  function keyPathOption(keyPath) {
+    return keyPath === null || keyPath === undefined ? undefined : keyPath;
+  }
+
+  function missingSchema(db, saved) {
+    for (const s of saved.objectStores || []) {
+      if (!db.objectStoreNames.contains(s.name)) return true;
+      const store = db.transaction(s.name, 'readonly').objectStore(s.name);
+      for (const idx of s.indexes || []) {
+        if (!store.indexNames.contains(idx.name)) return true;
+      }
+    }
+    return false;
+  }
+
+  function createSchema(db, tx, saved) {
+    for (const s of saved.objectStores || []) {
+      const store = db.objectStoreNames.contains(s.name)
+        ? tx.objectStore(s.name)
+        : db.createObjectStore(s.name, { keyPath: keyPathOption(s.keyPath), autoIncrement: !!s.autoIncrement });
+      for (const idx of s.indexes || []) {
+        if (!store.indexNames.contains(idx.name)) {
+          store.createIndex(idx.name, idx.keyPath, { unique: !!idx.unique, multiEntry: !!idx.multiEntry });
+        }
+      }
+    }
+  }
+
+  async function importIndexedDB() {
+    const existing = {};
+    for (const info of await databaseNames()) existing[info.name] = info.version;
+    let databases = 0;
+    let records = 0;
+    const errors = [];
+    for (const saved of arg.databases || []) {
+      try {
+        let db;
+        if (!(saved.name in existing)) {
+          db = await openDB(saved.name, saved.version || 1, function (db, tx) { createSchema(db, tx, saved); });
+        } else {
+          db = await openDB(saved.name);
+          const current = db.version;
+          if (missingSchema(db, saved) || (saved.version || 0) > current) {
+            db.close();
+            // Adding stores or indexes needs a version change; keep the saved
+            // version when it is ahead so the page's own open() still works.
+            const next = Math.max(saved.version || 0, current + 1);
+            db = await openDB(saved.name, next, function (db, tx) { createSchema(db, tx, saved); });
+          }
+        }
+        try {
+          for (const s of saved.objectStores || []) {
+            if (!s.records || !s.records.length) continue;
+            const decoded = s.records.map(function (r) { return { key: decode(r.key), value: decode(r.value) }; });
+            const tx = db.transaction(s.name, 'readwrite');
+            const store = tx.objectStore(s.name);
+            const inline = store.keyPath !== null;
+            let n = 0;
+            for (const r of decoded) {
+              const req = inline ? store.put(r.value) : store.put(r.value, r.key);
+              req.onsuccess = function () { n++; };
+              req.onerror = function (e) { e.preventDefault(); };
+            }
+            await done(tx);
+            records += n;
+          }
+        } finally {
+          db.close();
+        }
+        databases++;
+      } catch (e) {
+        errors.push(saved.name + ': ' + (e && e.message ? e.message : String(e)));
+      }
+    }
+    return { databases: databases, records: records, errors: errors };
+  }
+
+  async function restoreCaches() {
+    if (typeof caches === 'undefined') throw new Error('Cache Storage is unavailable in this context');
+    let entries = 0;
+    const errors = [];
+    for (const saved of arg.caches || []) {
+      const cache = await caches.open(saved.name);
+      for (const e of saved.entries || []) {
+        try {
+          if (e.requestMethod && e.requestMethod !== 'GET') continue;
+          const headers = new Headers();
+          for (const h of e.headers || []) {
+            try { headers.append(h.name, h.value); } catch (_) {}
+          }
+          const nullBody = [101, 103, 204, 205, 304].indexOf(e.status) >= 0;
+          await cache.put(new Request(e.requestURL), new Response(nullBody ? null : fromBase64(e.body), {
+            status: e.status,
+            statusText: e.statusText || '',
+            headers: headers,
+          }));
+          entries++;
+        } catch (err) {
+          errors.push(e.requestURL + ': ' + (err && err.message ? err.message : String(err)));
+        }
+      }
+    }
+    return { entries: entries, errors: errors };
+  }
+
+  async function registerServiceWorkers() {
+    if (!navigator.serviceWorker) throw new Error('service workers are unavailable in this context');
+    let registered = 0;
+    const errors = [];
+    for (const w of arg.workers || []) {
+      try {
+        if (new URL(w.scopeURL).origin !== location.origin) continue;
+        await navigator.serviceWorker.register(w.scriptURL, { scope: w.scopeURL });
+        registered++;
+      } catch (err) {
+        errors.push(w.scopeURL + ': ' + (err && err.message ? err.message : String(err)));
+      }
+    }
+    return { registered: registered, errors: errors };
+  }
+
+  const ops = {
+    'idb.list': listIndexedDB,
+    'idb.export': exportIndexedDB,
+    'idb.import': importIndexedDB,
+    'cache.restore': restoreCaches,
+    'sw.register': registerServiceWorkers,
+  };
+  try {
+    if (!ops[op]) throw new Error('unknown site data op: ' + op);
+    return JSON.stringify({ origin: location.origin, result: await ops[op]() });
+  } catch (e) {
+    return JSON.stringify({ origin: location.origin, error: e && e.message ? e.message : String(e) });
+  }
+})
@@ -0,0 +1,266 @@
+// Stream routing shim for WebSocket and EventSource rules installed through
+// /network/route. Chrome's Fetch domain never sees WebSocket traffic and
+// cannot rewrite a live event stream, so matching connections are handled
+// in the page instead. Evaluated as a function expression and called with
+//
+//   {rules: [{re, type, action, frames, drop}], noForge: ["host", "*.host"]}
+//
+// re and drop are regular expression sources; noForge lists hosts on which
+// scripted frames must never be delivered (security.allowedDomains).
+// Re-running the shim only swaps the rule table, which applies to
+// connections opened afterwards.
+(function (config) {
+  const KEY = '__pinchtabStreamRoutes';
+  const existing = window[KEY];
+  if (existing) {
+    existing.config = config;
+    return;
+  }
+  const state = { config: config };
+  Object.defineProperty(window, KEY, { value: state, configurable: false, enumerable: false });
+
+  const NativeWebSocket = window.WebSocket;
+  const NativeEventSource = window.EventSource;
+  const addListener = EventTarget.prototype.addEventListener;
+  const removeListener = EventTarget.prototype.removeEventListener;
+
+  function hostOf(url) {
+    try {
+      return new URL(url, location.href).hostname.toLowerCase();
+    } catch (e) {
+      return '';
+    }
+  }
+
+  function forgeryPermitted(url) {
+    const host = hostOf(url);
+    const deny = state.config.noForge || [];
+    for (let i = 0; i < deny.length; i++) {
+      const p = String(deny[i]).toLowerCase();
+      if (p === '*') return false;
+      if (p.indexOf('*.') === 0 ? host.endsWith(p.slice(1)) : host === p) return false;
+    }
+    return true;
+  }
+
+  function findRule(url, type) {
+    let abs = String(url);
+    try {
+      abs = new URL(url, location.href).href;
+    } catch (e) {}
+    const rules = state.config.rules || [];
+    for (let i = 0; i < rules.length; i++) {
+      const r = rules[i];
+      if (r.type === type && new RegExp(r.re).test(abs)) return { rule: r, url: abs };
+    }
+    return null;
+  }
+
+  function dropper(rule) {
+    if (!rule.drop) return function () { return false; };
+    const re = new RegExp(rule.drop);
+    return function (data) { return typeof data === 'string' && re.test(data); };
+  }
+
+  // fire dispatches ev on target and then calls the matching on<type>
+  // handler, which fake connections keep as a plain property.
+  function fire(target, ev) {
+    target.dispatchEvent(ev);
+    const handler = target['on' + ev.type];
+    if (typeof handler === 'function') {
+      try {
+        handler.call(target, ev);
+      } catch (e) {
+        setTimeout(function () { throw e; });
+      }
+    }
+  }
+
+  // play delivers frames in order, each delayMs after the previous one,
+  // while alive() holds.
+  function play(frames, alive, deliver) {
+    let i = 0;
+    function next() {
+      if (i >= frames.length || !alive()) return;
+      const f = frames[i++];
+      setTimeout(function () {
+        if (!alive()) return;
+        deliver(f);
+        next();
+      }, f.delayMs || 0);
+    }
+    next();
+  }
+
+  // fakeConnection builds a real EventTarget whose prototype is the native
+  // one, so instanceof and addEventListener behave, with the native
+  // accessors shadowed by own properties.
+  function fakeConnection(proto, props) {
+    const target = Reflect.construct(EventTarget, [], function () {});
+    Object.setPrototypeOf(target, proto);
+    for (const k in props) {
+      Object.defineProperty(target, k, { value: props[k], writable: true, configurable: true, enumerable: true });
+    }
+    return target;
+  }
+
+  if (typeof NativeWebSocket === 'function') {
+    const RoutedWebSocket = function WebSocket(url, protocols) {
+      if (!new.target) throw new TypeError("Failed to construct 'WebSocket': Please use the 'new' operator");
+      const m = findRule(url, 'websocket');
+      const args = protocols === undefined ? [url] : [url, protocols];
+      if (!m) return Reflect.construct(NativeWebSocket, args, new.target);
+      const rule = m.rule;
+      const forge = forgeryPermitted(m.url);
+
+      if (rule.action === 'abort' || (rule.action === 'fulfill' && forge)) {
+        const ws = fakeConnection(NativeWebSocket.prototype, {
+          url: m.url, protocol: '', extensions: '', bufferedAmount: 0, binaryType: 'blob',
+          readyState: NativeWebSocket.CONNECTING,
+          onopen: null, onmessage: null, onerror: null, onclose: null,
+        });
+        const closeWith = function (code, reason, clean) {
+          if (ws.readyState === NativeWebSocket.CLOSED) return;
+          ws.readyState = NativeWebSocket.CLOSED;
+          fire(ws, new CloseEvent('close', { code: code, reason: reason || '', wasClean: clean }));
+        };
+        ws.send = function () {
+          if (ws.readyState === NativeWebSocket.CONNECTING) {
+            throw new DOMException("Failed to execute 'send' on 'WebSocket': Still in CONNECTING state.", 'InvalidStateError');
+          }
+        };
+        ws.close = function (code, reason) {
+          if (ws.readyState >= NativeWebSocket.CLOSING) return;
+          ws.readyState = NativeWebSocket.CLOSING;
+          setTimeout(function () { closeWith(code || 1000, reason, true); });
+        };
+        setTimeout(function () {
+          if (rule.action === 'abort') {
+            ws.readyState = NativeWebSocket.CLOSED;
+            fire(ws, new Event('error'));
+            fire(ws, new CloseEvent('close', { code: 1006, reason: '', wasClean: false }));
+            return;
+          }
+          ws.readyState = NativeWebSocket.OPEN;
+          fire(ws, new Event('open'));
+          play(rule.frames || [], function () { return ws.readyState === NativeWebSocket.OPEN; }, function (f) {
+            fire(ws, new MessageEvent('message', { data: f.data, origin: new URL(m.url).origin }));
+          });
+        });
+        return ws;
+      }
+
+      // continue: a real connection with optional drops and injected frames.
+      const ws = Reflect.construct(NativeWebSocket, args, new.target);
+      const drop = dropper(rule);
+      const injected = new WeakSet();
+      const nativeSend = ws.send;
+      ws.send = function (data) {
+        if (drop(data)) return;
+        return nativeSend.call(ws, data);
+      };
+      addListener.call(ws, 'message', function (ev) {
+        if (!injected.has(ev) && drop(ev.data)) ev.stopImmediatePropagation();
+      });
+      if (forge && rule.frames && rule.frames.length) {
+        addListener.call(ws, 'open', function () {
+          play(rule.frames, function () { return ws.readyState === NativeWebSocket.OPEN; }, function (f) {
+            const ev = new MessageEvent('message', { data: f.data, origin: new URL(m.url).origin });
+            injected.add(ev);
+            ws.dispatchEvent(ev);
+          });
+        }, { once: true });
+      }
+      return ws;
+    };
+    RoutedWebSocket.prototype = NativeWebSocket.prototype;
+    ['CONNECTING', 'OPEN', 'CLOSING', 'CLOSED'].forEach(function (k) {
+      Object.defineProperty(RoutedWebSocket, k, { value: NativeWebSocket[k], enumerable: true });
+    });
+    Object.setPrototypeOf(RoutedWebSocket, NativeWebSocket);
+    window.WebSocket = RoutedWebSocket;
+  }
+
+  if (typeof NativeEventSource === 'function') {
+    const RoutedEventSource = function EventSource(url, init) {
+      if (!new.target) throw new TypeError("Failed to construct 'EventSource': Please use the 'new' operator");
+      const m = findRule(url, 'eventsource');
+      const args = init === undefined ? [url] : [url, init];
+      if (!m) return Reflect.construct(NativeEventSource, args, new.target);
+      const rule = m.rule;
+      const forge = forgeryPermitted(m.url);
+      const origin = new URL(m.url).origin;
+      const deliver = function (target, f, mark) {
+        const ev = new MessageEvent(f.event || 'message', { data: f.data, lastEventId: f.id || '', origin: origin });
+        if (mark) mark.add(ev);
+        fire(target, ev);
+      };
+
+      if (rule.action === 'abort' || (rule.action === 'fulfill' && forge)) {
+        const es = fakeConnection(NativeEventSource.prototype, {
+          url: m.url, withCredentials: !!(init && init.withCredentials),
+          readyState: NativeEventSource.CONNECTING,
+          onopen: null, onmessage: null, onerror: null,
+        });
+        es.close = function () { es.readyState = NativeEventSource.CLOSED; };
+        setTimeout(function () {
+          if (es.readyState === NativeEventSource.CLOSED) return;
+          if (rule.action === 'abort') {
+            es.readyState = NativeEventSource.CLOSED;
+            fire(es, new Event('error'));
+            return;
+          }
+          es.readyState = NativeEventSource.OPEN;
+          fire(es, new Event('open'));
+          play(rule.frames || [], function () { return es.readyState === NativeEventSource.OPEN; }, function (f) {
+            deliver(es, f);
+          });
+        });
+        return es;
+      }
+
+      const es = Reflect.construct(NativeEventSource, args, new.target);
+      const drop = dropper(rule);
+      const injected = new WeakSet();
+      const wrapped = new Map();
+      // Named events have no on<type> handler, so listeners are wrapped as
+      // they are added; onmessage is covered by the first-registered guard.
+      es.addEventListener = function (type, listener, options) {
+        if (typeof listener !== 'function' || type === 'open' || type === 'error') {
+          return addListener.call(es, type, listener, options);
+        }
+        let w = wrapped.get(listener);
+        if (!w) {
+          w = function (ev) {
+            if (!injected.has(ev) && drop(ev.data)) return;
+            return listener.call(this, ev);
+          };
+          wrapped.set(listener, w);
+        }
+        return addListener.call(es, type, w, options);
+      };
+      es.removeEventListener = function (type, listener, options) {
+        return removeListener.call(es, type, wrapped.get(listener) || listener, options);
+      };
+      addListener.call(es, 'message', function (ev) {
+        if (!injected.has(ev) && drop(ev.data)) ev.stopImmediatePropagation();
+      });
+      if (forge && rule.frames && rule.frames.length) {
+        addListener.call(es, 'open', function () {
+          play(rule.frames, function () { return es.readyState === NativeEventSource.OPEN; }, function (f) {
+            const ev = new MessageEvent(f.event || 'message', { data: f.data, lastEventId: f.id || '', origin: origin });
+            injected.add(ev);
+            es.dispatchEvent(ev);
+          });
+        }, { once: true });
+      }
+      return es;
+    };
+    RoutedEventSource.prototype = NativeEventSource.prototype;
+    ['CONNECTING', 'OPEN', 'CLOSED'].forEach(function (k) {
+      Object.defineProperty(RoutedEventSource, k, { value: NativeEventSource[k], enumerable: true });
+    });
+    Object.setPrototypeOf(RoutedEventSource, NativeEventSource);
+    window.EventSource = RoutedEventSource;
+  }
+})
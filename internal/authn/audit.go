@@ -31,6 +31,9 @@ func auditAttrs(r *http.Request, event string) []any {
 	if creds := CredentialsFromRequest(r); creds.Method != MethodNone {
 		attrs = append(attrs, "authMethod", string(creds.Method))
 	}
+	if id, ok := IdentityFromRequest(r); ok {
+		attrs = append(attrs, "user", id.User(), "subject", id.Subject, "role", id.Role)
+	}
 	if origin := strings.TrimSpace(r.Header.Get("Origin")); origin != "" {
 		attrs = append(attrs, "origin", origin)
 	}
@@ -42,6 +42,40 @@ func ClearSessionCookie(w http.ResponseWriter, r *http.Request, trustProxy bool,
 	})
 }
 
+// OIDCStateCookieName binds a pending single sign-on login to the browser
+// that started it.
+const OIDCStateCookieName = "pinchtab_oidc_state"
+
+const oidcStateCookiePath = "/api/auth/oidc/callback"
+
+// SetOIDCStateCookie stores the OIDC state for the callback. It is Lax rather
+// than Strict because the callback is a cross-site redirect from the
+// provider.
+func SetOIDCStateCookie(w http.ResponseWriter, r *http.Request, state string, maxAge time.Duration, trustProxy bool, cookieSecure *bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    state,
+		Path:     oidcStateCookiePath,
+		HttpOnly: true,
+		Secure:   sessionCookieSecure(r, trustProxy, cookieSecure),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(maxAge.Seconds()),
+	})
+}
+
+// ClearOIDCStateCookie expires the OIDC state cookie.
+func ClearOIDCStateCookie(w http.ResponseWriter, r *http.Request, trustProxy bool, cookieSecure *bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCStateCookieName,
+		Value:    "",
+		Path:     oidcStateCookiePath,
+		HttpOnly: true,
+		Secure:   sessionCookieSecure(r, trustProxy, cookieSecure),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+	})
+}
+
 func sessionCookieSecure(r *http.Request, trustProxy bool, cookieSecure *bool) bool {
 	if cookieSecure != nil {
 		return *cookieSecure
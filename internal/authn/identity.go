@@ -0,0 +1,51 @@
+package authn
+
+import (
+	"context"
+	"net/http"
+)
+
+// Dashboard roles granted to single sign-on identities, lowest first.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Identity is a dashboard user who signed in through an OpenID Connect
+// provider rather than with the server token.
+type Identity struct {
+	Issuer  string   `json:"issuer"`
+	Subject string   `json:"subject"`
+	Email   string   `json:"email,omitempty"`
+	Name    string   `json:"name,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Role    string   `json:"role"`
+}
+
+// User returns the most readable name for the identity.
+func (id Identity) User() string {
+	if id.Email != "" {
+		return id.Email
+	}
+	if id.Name != "" {
+		return id.Name
+	}
+	return id.Subject
+}
+
+type identityKey struct{}
+
+// WithIdentity returns r carrying id, so audit records name the user.
+func WithIdentity(r *http.Request, id Identity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, id))
+}
+
+// IdentityFromRequest returns the signed-in identity attached to r, if any.
+func IdentityFromRequest(r *http.Request) (Identity, bool) {
+	if r == nil {
+		return Identity{}, false
+	}
+	id, ok := r.Context().Value(identityKey{}).(Identity)
+	return id, ok
+}
@@ -74,17 +74,23 @@ func (p *PinchtabPage) Screenshot() ([]byte, error) {
 	return buf, nil
 }
 
+// NavigateGuard vets a solver-chosen URL before the executor navigates to
+// it. A non-nil error refuses the navigation.
+type NavigateGuard func(url string) error
+
 // PinchtabExecutor implements autosolver.ActionExecutor by delegating
 // to the Pinchtab bridge's human-like input system.
 type PinchtabExecutor struct {
 	ctx   context.Context
 	tabID string
 	b     bridge.BridgeAPI
+	guard NavigateGuard
 }
 
 // NewPinchtabExecutor creates an ActionExecutor backed by a Pinchtab bridge.
-func NewPinchtabExecutor(ctx context.Context, tabID string, b bridge.BridgeAPI) *PinchtabExecutor {
-	return &PinchtabExecutor{ctx: ctx, tabID: tabID, b: b}
+// guard, when set, is consulted before every Navigate.
+func NewPinchtabExecutor(ctx context.Context, tabID string, b bridge.BridgeAPI, guard NavigateGuard) *PinchtabExecutor {
+	return &PinchtabExecutor{ctx: ctx, tabID: tabID, b: b, guard: guard}
 }
 
 func (e *PinchtabExecutor) Click(ctx context.Context, x, y float64) error {
@@ -106,19 +112,27 @@ func (e *PinchtabExecutor) Evaluate(ctx context.Context, expr string, result int
 	return chromedp.Run(ctx, chromedp.Evaluate(expr, result))
 }
 
+// Navigate drives the tab to url. Solver URLs come from page content or an
+// LLM, so they pass the same domain guard as a /navigate request first.
 func (e *PinchtabExecutor) Navigate(ctx context.Context, url string) error {
+	if e.guard != nil {
+		if err := e.guard(url); err != nil {
+			return fmt.Errorf("navigate %s: %w", url, err)
+		}
+	}
 	return chromedp.Run(ctx, chromedp.Navigate(url))
 }
 
 // NewFromBridge creates both a Page and ActionExecutor from a Bridge
 // and tab ID. This is the primary factory for integration with Pinchtab.
-func NewFromBridge(b bridge.BridgeAPI, tabID string) (autosolver.Page, autosolver.ActionExecutor, error) {
+// guard vets navigate actions; see NavigateGuard.
+func NewFromBridge(b bridge.BridgeAPI, tabID string, guard NavigateGuard) (autosolver.Page, autosolver.ActionExecutor, error) {
 	tabCtx, resolvedID, err := b.TabContext(tabID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("resolve tab %q: %w", tabID, err)
 	}
 
 	page := NewPinchtabPage(tabCtx, resolvedID, b)
-	executor := NewPinchtabExecutor(tabCtx, resolvedID, b)
+	executor := NewPinchtabExecutor(tabCtx, resolvedID, b, guard)
 	return page, executor, nil
 }
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"net"
 	"runtime"
 	"testing"
@@ -112,3 +113,23 @@ func TestHTMLWithin_StalledFetchDoesNotLeakGoroutine(t *testing.T) {
 			"a stalled HTML fetch appears to leak a worker", grew, iterations, before, after)
 	}
 }
+
+// TestNavigate_GuardRefusesBeforeCDP pins that a refused URL never reaches
+// chromedp: the executor has no browser behind it, so only the guard's own
+// error can come back.
+func TestNavigate_GuardRefusesBeforeCDP(t *testing.T) {
+	refused := errors.New("outside allowed domains")
+	var seen string
+	exec := NewPinchtabExecutor(context.Background(), "tab1", nil, func(url string) error {
+		seen = url
+		return refused
+	})
+
+	err := exec.Navigate(context.Background(), "https://evil.test/")
+	if !errors.Is(err, refused) {
+		t.Fatalf("Navigate error = %v, want guard error", err)
+	}
+	if seen != "https://evil.test/" {
+		t.Fatalf("guard saw %q, want the navigate url", seen)
+	}
+}
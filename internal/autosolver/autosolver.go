@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"sort"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 // AutoSolver orchestrates the challenge-detection and solving pipeline.
@@ -44,6 +46,23 @@ func (as *AutoSolver) Registry() *Registry {
 //  5. If all fail and LLM is enabled, try LLM fallback
 //  6. Return result with full attempt history
 func (as *AutoSolver) Solve(ctx context.Context, page Page, executor ActionExecutor) (*Result, error) {
+	ctx, span := tracing.Start(ctx, "autosolver.run")
+	defer span.End()
+
+	result, err := as.solve(ctx, page, executor)
+	span.RecordError(err)
+	if result != nil {
+		span.SetAttributes(
+			tracing.Bool("pinchtab.autosolver.solved", result.Solved),
+			tracing.Int("pinchtab.autosolver.attempts", result.Attempts),
+			tracing.String("pinchtab.autosolver.solver", result.SolverUsed),
+			tracing.String("pinchtab.autosolver.intent", string(result.Intent)),
+		)
+	}
+	return result, err
+}
+
+func (as *AutoSolver) solve(ctx context.Context, page Page, executor ActionExecutor) (*Result, error) {
 	start := time.Now()
 	result := &Result{
 		FinalTitle: page.Title(),
@@ -104,6 +123,7 @@ func (as *AutoSolver) Solve(ctx context.Context, page Page, executor ActionExecu
 		}
 
 		solved, entry := as.trySemantic(ctx, page, executor, intent)
+		recordAttempt(entry)
 		appendAttempt(result, entry)
 		if solved {
 			return as.finalizeSuccess(result, page, entry.Solver, start), nil
@@ -116,7 +136,8 @@ func (as *AutoSolver) Solve(ctx context.Context, page Page, executor ActionExecu
 		}
 
 		if as.config.LLMFallback && as.llm != nil {
-			solved, entry = as.tryLLM(ctx, page, executor, result.History)
+			solved, entry = as.tryLLM(ctx, page, executor, result)
+			recordAttempt(entry)
 			appendAttempt(result, entry)
 			if solved {
 				return as.finalizeSuccess(result, page, "llm", start), nil
@@ -175,10 +196,12 @@ func (as *AutoSolver) detectIntent(ctx context.Context, page Page) (*Intent, err
 func (as *AutoSolver) trySolvers(ctx context.Context, page Page, executor ActionExecutor) (bool, *AttemptEntry) {
 	solvers := as.registry.MatchingSolvers(ctx, page)
 	if len(solvers) == 0 {
-		return false, &AttemptEntry{
+		entry := &AttemptEntry{
 			Solver: "none",
 			Status: StatusSkipped,
 		}
+		recordAttempt(entry)
+		return false, entry
 	}
 
 	orderedSolvers := solvers
@@ -237,6 +260,7 @@ func (as *AutoSolver) trySolvers(ctx context.Context, page Page, executor Action
 		if err != nil {
 			entry.Status = StatusFailed
 			entry.Error = err.Error()
+			recordAttempt(entry)
 			slog.Warn("autosolver_failure",
 				"solver", s.Name(),
 				"error", err,
@@ -246,6 +270,7 @@ func (as *AutoSolver) trySolvers(ctx context.Context, page Page, executor Action
 
 		if solveResult != nil && solveResult.Solved {
 			entry.Status = StatusSolved
+			recordAttempt(entry)
 			return true, entry
 		}
 
@@ -253,6 +278,7 @@ func (as *AutoSolver) trySolvers(ctx context.Context, page Page, executor Action
 		if solveResult != nil && solveResult.Error != "" {
 			entry.Error = solveResult.Error
 		}
+		recordAttempt(entry)
 		slog.Debug("autosolver: solver returned not-solved",
 			"solver", s.Name(),
 			"duration_ms", entry.Duration.Milliseconds())
@@ -686,7 +712,9 @@ func resolveSelectorCenter(ctx context.Context, executor ActionExecutor, selecto
 	return coords.X, coords.Y, nil
 }
 
-func (as *AutoSolver) tryLLM(ctx context.Context, page Page, executor ActionExecutor, history []AttemptEntry) (bool, *AttemptEntry) {
+// tryLLM asks the LLM provider for one action and executes it. Token usage
+// is added to result.LLMUsage whether or not the action worked.
+func (as *AutoSolver) tryLLM(ctx context.Context, page Page, executor ActionExecutor, result *Result) (bool, *AttemptEntry) {
 	llmStart := time.Now()
 	entry := &AttemptEntry{Solver: "llm"}
 
@@ -708,8 +736,14 @@ func (as *AutoSolver) tryLLM(ctx context.Context, page Page, executor ActionExec
 		PageURL:      page.URL(),
 		TrimmedHTML:  html,
 		DetectedType: IntentUnknown,
-		PrevAttempts: history,
+		PrevAttempts: result.History,
 	})
+	if resp != nil && resp.Usage != nil {
+		if result.LLMUsage == nil {
+			result.LLMUsage = &LLMUsage{}
+		}
+		result.LLMUsage.Add(resp.Usage)
+	}
 	if err != nil {
 		entry.Status = StatusFailed
 		entry.Error = fmt.Sprintf("llm: %v", err)
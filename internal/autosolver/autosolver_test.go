@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
 )
 
 type mockPage struct {
@@ -541,6 +543,43 @@ func TestSolve_LLMFallback(t *testing.T) {
 	}
 }
 
+func TestSolve_LLMUsageAccumulates(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 2
+	cfg.LLMFallback = true
+	cfg.RetryBaseDelay = time.Millisecond
+	cfg.RetryMaxDelay = time.Millisecond
+
+	// The model spent tokens on an unusable answer both times.
+	llm := &mockLLM{
+		resp: &LLMResponse{
+			Action: ActionNone,
+			Usage:  &LLMUsage{Provider: "openai", Model: "m", Calls: 1, InputTokens: 100, OutputTokens: 10, CostUSD: 0.5},
+		},
+		err: fmt.Errorf("no JSON object in response"),
+	}
+	as := New(cfg, nil, llm)
+
+	page := &mockPage{title: "Just a moment...", url: "https://example.com", html: "<html></html>"}
+	result, err := as.Solve(context.Background(), page, &mockExecutor{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Solved {
+		t.Fatal("expected Solved=false when the LLM fails")
+	}
+	u := result.LLMUsage
+	if u == nil {
+		t.Fatal("expected LLMUsage on the result")
+	}
+	if u.Calls != 2 || u.InputTokens != 200 || u.OutputTokens != 20 || u.CostUSD != 1 {
+		t.Errorf("usage = %+v, want 2 calls, 200/20 tokens, $1", *u)
+	}
+	if u.Provider != "openai" || u.Model != "m" {
+		t.Errorf("usage provider/model = %q/%q", u.Provider, u.Model)
+	}
+}
+
 func TestSolve_ContextCancellation(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.MaxAttempts = 10
@@ -775,3 +814,40 @@ func TestExecuteAction_LLMNavigate(t *testing.T) {
 		t.Errorf("expected navigate, got %d", ex.navigateCalled)
 	}
 }
+
+func TestSolve_RecordsAttemptMetrics(t *testing.T) {
+	metricAttempts.Reset()
+	t.Cleanup(metricAttempts.Reset)
+
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 1
+	cfg.RetryBaseDelay = time.Millisecond
+
+	as := New(cfg, nil, nil)
+	as.Registry().MustRegister(&mockSolver{name: "failing", priority: 10, canHandle: true, err: fmt.Errorf("solver error")})
+	as.Registry().MustRegister(&mockSolver{name: "succeeding", priority: 20, canHandle: true, solved: true})
+
+	page := &mockPage{title: "Just a moment...", url: "https://example.com"}
+	if _, err := as.Solve(context.Background(), page, &mockExecutor{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]float64{}
+	for _, f := range metrics.Default.Gather() {
+		if f.Name != "pinchtab_autosolver_attempts" {
+			continue
+		}
+		for _, s := range f.Samples {
+			got[s.Labels[0].Value+"/"+s.Labels[1].Value] = s.Value
+		}
+	}
+	want := map[string]float64{"semantic/skipped": 1, "failing/failed": 1, "succeeding/solved": 1}
+	for key, v := range want {
+		if got[key] != v {
+			t.Errorf("attempts[%s] = %v, want %v (all: %v)", key, got[key], v, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("unexpected attempt series: %v", got)
+	}
+}
@@ -97,5 +97,7 @@ type SemanticEngine interface {
 // Implementations should minimize token usage.
 type LLMProvider interface {
 	// SuggestNextAction asks the LLM for the next action given page context.
+	// An implementation may return a response carrying only Usage together
+	// with an error when tokens were spent on an unusable answer.
 	SuggestNextAction(ctx context.Context, req LLMRequest) (*LLMResponse, error)
 }
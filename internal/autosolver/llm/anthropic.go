@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// anthropicVersion is the messages API version header the request shape
+// below was written against.
+const anthropicVersion = "2023-06-01"
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// completeAnthropic calls POST {baseURL}/messages. The API has no JSON mode,
+// so the answer is the concatenated text blocks and parseLLMResponse digs
+// the object out of them.
+func (p *Provider) completeAnthropic(ctx context.Context, baseURL, prompt string) (string, tokenUsage, error) {
+	headers := map[string]string{"anthropic-version": anthropicVersion}
+	if p.config.APIKey != "" {
+		headers["x-api-key"] = p.config.APIKey
+	}
+	body := anthropicRequest{
+		Model:       p.config.Model,
+		System:      systemPrompt,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+		MaxTokens:   p.config.MaxTokens,
+		Temperature: p.config.Temperature,
+	}
+
+	var out anthropicResponse
+	if err := p.postJSON(ctx, baseURL+"/messages", headers, body, &out); err != nil {
+		return "", tokenUsage{}, err
+	}
+	usage := tokenUsage{input: out.Usage.InputTokens, output: out.Usage.OutputTokens}
+	var sb strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", usage, fmt.Errorf("llm: anthropic: response has no text content")
+	}
+	return sb.String(), usage, nil
+}
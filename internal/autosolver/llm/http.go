@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxResponseBytes caps a provider response body. Completions are
+	// capped at a few hundred tokens, so anything near this is an error page.
+	maxResponseBytes = 1 << 20
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// statusError is a non-2xx provider answer.
+type statusError struct {
+	status  int
+	message string
+	retry   time.Duration // from Retry-After, 0 when absent
+}
+
+func (e *statusError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("HTTP %d", e.status)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.status, e.message)
+}
+
+// retryable reports whether a failed request is worth repeating: transport
+// errors, per-request timeouts, rate limits and server-side failures.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status == http.StatusRequestTimeout ||
+			se.status == http.StatusTooManyRequests ||
+			se.status >= 500
+	}
+	return true
+}
+
+// postJSON sends body to url and decodes a 2xx answer into out. Each attempt
+// gets its own Timeout; retryable failures are repeated up to MaxRetries
+// times with exponential backoff (or the server's Retry-After).
+func (p *Provider) postJSON(ctx context.Context, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("llm: encode request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := backoff(attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("llm: %w (last error: %v)", ctx.Err(), lastErr)
+			case <-time.After(delay):
+			}
+		}
+		lastErr = p.postOnce(ctx, url, headers, payload, out)
+		if lastErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !retryable(lastErr) {
+			break
+		}
+	}
+	return fmt.Errorf("llm: %s: %w", p.config.Provider, lastErr)
+}
+
+func (p *Provider) postOnce(ctx context.Context, url string, headers map[string]string, payload []byte, out any) error {
+	reqCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{
+			status:  resp.StatusCode,
+			message: errorMessage(raw),
+			retry:   retryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return &statusError{status: resp.StatusCode, message: fmt.Sprintf("decode response: %v", err)}
+	}
+	return nil
+}
+
+func backoff(attempt int, lastErr error) time.Duration {
+	var se *statusError
+	if errors.As(lastErr, &se) && se.retry > 0 {
+		return min(se.retry, retryMaxDelay)
+	}
+	return min(retryBaseDelay*time.Duration(1<<uint(attempt-1)), retryMaxDelay)
+}
+
+// retryAfter parses the delay-seconds form of Retry-After.
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// errorMessage extracts the message from either API's error envelope,
+// {"error": {"message": ...}}, falling back to a prefix of the raw body.
+func errorMessage(raw []byte) string {
+	var env struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(raw, &env) == nil && env.Error.Message != "" {
+		return env.Error.Message
+	}
+	const maxLen = 200
+	if len(raw) > maxLen {
+		raw = raw[:maxLen]
+	}
+	return string(bytes.TrimSpace(raw))
+}
@@ -8,35 +8,96 @@ package llm
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/autosolver"
 )
 
+// Supported provider wire formats. Any server speaking one of them (vLLM,
+// llama.cpp, Ollama, LiteLLM, ...) works by pointing BaseURL at it.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+const (
+	defaultMaxTokens   = 256
+	defaultTemperature = 0.1
+	defaultTimeout     = 30 * time.Second
+	defaultMaxRetries  = 2
+)
+
 // ProviderConfig holds LLM provider configuration.
 type ProviderConfig struct {
-	Provider    string  `json:"provider"`    // "openai", "anthropic", etc.
-	Model       string  `json:"model"`       // Model name (e.g., "gpt-4o-mini")
-	APIKey      string  `json:"apiKey"`      // Provider API key
-	MaxTokens   int     `json:"maxTokens"`   // Max output tokens (default: 256)
-	Temperature float64 `json:"temperature"` // Sampling temperature (default: 0.1)
+	Provider    string        `json:"provider"`             // "openai" or "anthropic"
+	Model       string        `json:"model"`                // Model name (e.g., "gpt-4o-mini")
+	APIKey      string        `json:"apiKey"`               // Provider API key
+	BaseURL     string        `json:"baseUrl,omitempty"`    // API root, e.g. http://localhost:8000/v1
+	MaxTokens   int           `json:"maxTokens"`            // Max output tokens (default: 256)
+	Temperature float64       `json:"temperature"`          // Sampling temperature (default: 0.1)
+	Timeout     time.Duration `json:"timeout,omitempty"`    // Per-request timeout (default: 30s)
+	MaxRetries  int           `json:"maxRetries,omitempty"` // Retries after the first request (default: 2, -1 disables)
+	InputCost   float64       `json:"inputCost,omitempty"`  // USD per million input tokens
+	OutputCost  float64       `json:"outputCost,omitempty"` // USD per million output tokens
+	HTTPClient  *http.Client  `json:"-"`                    // Optional; tests inject a fake transport
 }
 
-// Provider implements autosolver.LLMProvider as a skeleton.
-// The actual HTTP client for OpenAI/Anthropic must be implemented
-// based on the chosen provider.
+// Provider implements autosolver.LLMProvider over the OpenAI chat
+// completions and Anthropic messages APIs.
 type Provider struct {
 	config ProviderConfig
+	client *http.Client
 }
 
 // NewProvider creates an LLM provider with the given configuration.
 func NewProvider(cfg ProviderConfig) *Provider {
+	cfg.Provider = strings.ToLower(strings.TrimSpace(cfg.Provider))
 	if cfg.MaxTokens <= 0 {
-		cfg.MaxTokens = 256
+		cfg.MaxTokens = defaultMaxTokens
 	}
 	if cfg.Temperature <= 0 {
-		cfg.Temperature = 0.1
+		cfg.Temperature = defaultTemperature
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultModel(cfg.Provider)
+	}
+	cfg.BaseURL = strings.TrimRight(strings.TrimSpace(cfg.BaseURL), "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Provider{config: cfg, client: client}
+}
+
+func defaultModel(provider string) string {
+	switch provider {
+	case ProviderOpenAI:
+		return "gpt-4o-mini"
+	case ProviderAnthropic:
+		return "claude-3-5-haiku-latest"
+	}
+	return ""
+}
+
+func defaultBaseURL(provider string) string {
+	switch provider {
+	case ProviderOpenAI:
+		return "https://api.openai.com/v1"
+	case ProviderAnthropic:
+		return "https://api.anthropic.com/v1"
 	}
-	return &Provider{config: cfg}
+	return ""
 }
 
 // SuggestNextAction builds a structured prompt from the page context and
@@ -47,20 +108,78 @@ func NewProvider(cfg ProviderConfig) *Provider {
 //   - Trimmed HTML (scripts/styles removed, max ~4000 chars)
 //   - Previous attempt summary (what failed and why)
 //   - Structured output format (action type + parameters)
+//
+// When the model answered but the answer is unusable, the returned response
+// still carries Usage alongside the error so the tokens are accounted for.
 func (p *Provider) SuggestNextAction(ctx context.Context, req autosolver.LLMRequest) (*autosolver.LLMResponse, error) {
-	if p.config.APIKey == "" {
-		return nil, fmt.Errorf("llm: API key not configured for provider %q", p.config.Provider)
+	if p.config.Provider != ProviderOpenAI && p.config.Provider != ProviderAnthropic {
+		return nil, fmt.Errorf("llm: unsupported provider %q (want openai or anthropic)", p.config.Provider)
+	}
+	baseURL := p.config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL(p.config.Provider)
+		// A custom base URL usually means a local model server, which
+		// rarely wants a key; the hosted APIs always do.
+		if p.config.APIKey == "" {
+			return nil, fmt.Errorf("llm: API key not configured for provider %q", p.config.Provider)
+		}
+	}
+	if p.config.Model == "" {
+		return nil, fmt.Errorf("llm: model not configured for provider %q", p.config.Provider)
 	}
 
+	req.TrimmedHTML = TrimHTML(req.TrimmedHTML)
 	prompt := buildPrompt(req)
 
-	// TODO: Implement actual LLM API call based on p.config.Provider.
-	// For now, return an error indicating skeleton-only status.
-	_ = prompt
+	var (
+		text  string
+		usage tokenUsage
+		err   error
+	)
+	switch p.config.Provider {
+	case ProviderOpenAI:
+		text, usage, err = p.completeOpenAI(ctx, baseURL, prompt)
+	case ProviderAnthropic:
+		text, usage, err = p.completeAnthropic(ctx, baseURL, prompt)
+	}
+	if err != nil {
+		if usage.input+usage.output > 0 {
+			return &autosolver.LLMResponse{Action: autosolver.ActionNone, Usage: p.usage(usage)}, err
+		}
+		return nil, err
+	}
+
+	accounted := p.usage(usage)
+	resp, err := parseLLMResponse(text)
+	if err != nil {
+		return &autosolver.LLMResponse{Action: autosolver.ActionNone, Usage: accounted}, err
+	}
+	resp.Usage = accounted
+	return resp, nil
+}
 
-	return nil, fmt.Errorf("llm: provider %q not yet implemented — skeleton only", p.config.Provider)
+// tokenUsage is the provider-neutral token count of one completion.
+type tokenUsage struct {
+	input  int
+	output int
 }
 
+func (p *Provider) usage(u tokenUsage) *autosolver.LLMUsage {
+	return &autosolver.LLMUsage{
+		Provider:     p.config.Provider,
+		Model:        p.config.Model,
+		Calls:        1,
+		InputTokens:  u.input,
+		OutputTokens: u.output,
+		CostUSD:      (float64(u.input)*p.config.InputCost + float64(u.output)*p.config.OutputCost) / 1e6,
+	}
+}
+
+// systemPrompt pins the output contract; buildPrompt repeats the schema so
+// models without system-prompt support still see it.
+const systemPrompt = "You are a browser automation assistant that clears interstitials, " +
+	"challenges and forms. Reply with a single JSON object and nothing else."
+
 func buildPrompt(req autosolver.LLMRequest) string {
 	prompt := fmt.Sprintf(`You are a browser automation assistant. Analyze the following page and suggest the next action.
 
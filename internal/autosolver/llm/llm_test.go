@@ -0,0 +1,271 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/autosolver"
+)
+
+// fakeServer stands in for an OpenAI- or Anthropic-compatible endpoint.
+// handler sees the decoded request body and the attempt number (from 1).
+func fakeServer(t *testing.T, path string, handler func(w http.ResponseWriter, r *http.Request, body map[string]any, attempt int)) (*httptest.Server, *int32) {
+	t.Helper()
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("path = %s, want %s", r.URL.Path, path)
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		handler(w, r, body, int(atomic.AddInt32(&attempts, 1)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func testRequest() autosolver.LLMRequest {
+	return autosolver.LLMRequest{
+		PageTitle:    "Verify you are human",
+		PageURL:      "https://example.com/gate",
+		TrimmedHTML:  `<script>track()</script><button id="go">Continue</button>`,
+		DetectedType: autosolver.IntentBlocked,
+	}
+}
+
+func TestSuggestNextAction_OpenAI(t *testing.T) {
+	srv, _ := fakeServer(t, "/v1/chat/completions", func(w http.ResponseWriter, r *http.Request, body map[string]any, _ int) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if body["model"] != "local-model" {
+			t.Errorf("model = %v", body["model"])
+		}
+		if rf, _ := body["response_format"].(map[string]any); rf["type"] != "json_object" {
+			t.Errorf("response_format = %v, want json_object", body["response_format"])
+		}
+		msgs, _ := body["messages"].([]any)
+		if len(msgs) != 2 {
+			t.Fatalf("messages = %d, want system + user", len(msgs))
+		}
+		user, _ := msgs[1].(map[string]any)["content"].(string)
+		if strings.Contains(user, "track()") || !strings.Contains(user, `id="go"`) {
+			t.Errorf("prompt HTML not trimmed: %q", user)
+		}
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"content": "{\"action\":\"click\",\"selector\":\"#go\",\"reasoning\":\"continue\",\"confidence\":0.9}"}}],
+			"usage": {"prompt_tokens": 1200, "completion_tokens": 40}
+		}`))
+	})
+
+	p := NewProvider(ProviderConfig{
+		Provider:   "openai",
+		Model:      "local-model",
+		APIKey:     "sk-test",
+		BaseURL:    srv.URL + "/v1/",
+		InputCost:  0.15,
+		OutputCost: 0.6,
+	})
+	resp, err := p.SuggestNextAction(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("SuggestNextAction: %v", err)
+	}
+	if resp.Action != autosolver.ActionClick || resp.Selector != "#go" || resp.Confidence != 0.9 {
+		t.Errorf("resp = %+v", resp)
+	}
+	u := resp.Usage
+	if u == nil || u.Calls != 1 || u.InputTokens != 1200 || u.OutputTokens != 40 {
+		t.Fatalf("usage = %+v", u)
+	}
+	if want := (1200*0.15 + 40*0.6) / 1e6; u.CostUSD != want {
+		t.Errorf("cost = %v, want %v", u.CostUSD, want)
+	}
+	if u.Provider != "openai" || u.Model != "local-model" {
+		t.Errorf("usage provider/model = %q/%q", u.Provider, u.Model)
+	}
+}
+
+func TestSuggestNextAction_Anthropic(t *testing.T) {
+	srv, _ := fakeServer(t, "/v1/messages", func(w http.ResponseWriter, r *http.Request, body map[string]any, _ int) {
+		if got := r.Header.Get("x-api-key"); got != "ak-test" {
+			t.Errorf("x-api-key = %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got == "" {
+			t.Error("anthropic-version header missing")
+		}
+		if body["system"] == nil {
+			t.Error("system prompt missing")
+		}
+		_, _ = w.Write([]byte(`{
+			"content": [{"type": "text", "text": "Here you go:\n` + "```json" + `\n{\"action\": \"type\", \"selector\": \"#q\", \"text\": \"hello {world}\", \"confidence\": \"0.7\"}\n` + "```" + `"}],
+			"usage": {"input_tokens": 900, "output_tokens": 30}
+		}`))
+	})
+
+	p := NewProvider(ProviderConfig{Provider: "Anthropic", APIKey: "ak-test", BaseURL: srv.URL + "/v1"})
+	resp, err := p.SuggestNextAction(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("SuggestNextAction: %v", err)
+	}
+	if resp.Action != autosolver.ActionType_ || resp.Text != "hello {world}" || resp.Confidence != 0.7 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.InputTokens != 900 || resp.Usage.OutputTokens != 30 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+	if resp.Usage.CostUSD != 0 {
+		t.Errorf("cost = %v, want 0 without configured prices", resp.Usage.CostUSD)
+	}
+}
+
+func TestSuggestNextAction_RetriesTransientFailures(t *testing.T) {
+	srv, attempts := fakeServer(t, "/chat/completions", func(w http.ResponseWriter, _ *http.Request, _ map[string]any, attempt int) {
+		switch attempt {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"message": "overloaded"}}`))
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "{\"action\":\"none\"}"}}]}`))
+		}
+	})
+
+	p := NewProvider(ProviderConfig{Provider: "openai", BaseURL: srv.URL})
+	resp, err := p.SuggestNextAction(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("SuggestNextAction: %v", err)
+	}
+	if resp.Action != autosolver.ActionNone {
+		t.Errorf("action = %q, want none", resp.Action)
+	}
+	if got := atomic.LoadInt32(attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestSuggestNextAction_DoesNotRetryClientErrors(t *testing.T) {
+	srv, attempts := fakeServer(t, "/chat/completions", func(w http.ResponseWriter, _ *http.Request, _ map[string]any, _ int) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": {"message": "invalid api key"}}`))
+	})
+
+	p := NewProvider(ProviderConfig{Provider: "openai", BaseURL: srv.URL})
+	_, err := p.SuggestNextAction(context.Background(), testRequest())
+	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("err = %v, want the provider's message", err)
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestSuggestNextAction_Timeout(t *testing.T) {
+	srv, attempts := fakeServer(t, "/chat/completions", func(w http.ResponseWriter, r *http.Request, _ map[string]any, _ int) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	p := NewProvider(ProviderConfig{Provider: "openai", BaseURL: srv.URL, Timeout: 50 * time.Millisecond, MaxRetries: -1})
+	start := time.Now()
+	if _, err := p.SuggestNextAction(context.Background(), testRequest()); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, want the per-request timeout to cut it short", elapsed)
+	}
+	if got := atomic.LoadInt32(attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1 with retries disabled", got)
+	}
+}
+
+func TestSuggestNextAction_UnusableAnswerKeepsUsage(t *testing.T) {
+	srv, _ := fakeServer(t, "/chat/completions", func(w http.ResponseWriter, _ *http.Request, _ map[string]any, _ int) {
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"content": "{\"action\":\"evaluate\",\"expr\":\"document.cookie\"}"}}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5}
+		}`))
+	})
+
+	p := NewProvider(ProviderConfig{Provider: "openai", BaseURL: srv.URL})
+	resp, err := p.SuggestNextAction(context.Background(), testRequest())
+	if err == nil {
+		t.Fatal("expected evaluate to be refused")
+	}
+	if resp == nil || resp.Usage == nil || resp.Usage.InputTokens != 10 {
+		t.Errorf("resp = %+v, want usage alongside the error", resp)
+	}
+}
+
+func TestSuggestNextAction_Configuration(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewProvider(ProviderConfig{Provider: "openai"}).SuggestNextAction(ctx, testRequest()); err == nil ||
+		!strings.Contains(err.Error(), "API key") {
+		t.Errorf("hosted API without key: err = %v", err)
+	}
+	if _, err := NewProvider(ProviderConfig{Provider: "gemini", BaseURL: "http://127.0.0.1:1"}).SuggestNextAction(ctx, testRequest()); err == nil ||
+		!strings.Contains(err.Error(), "unsupported provider") {
+		t.Errorf("unknown provider: err = %v", err)
+	}
+}
+
+func TestParseLLMResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    autosolver.LLMResponse
+		wantErr bool
+	}{
+		{
+			name: "bare object",
+			text: `{"action":"click","selector":"#ok","confidence":1.5}`,
+			want: autosolver.LLMResponse{Action: autosolver.ActionClick, Selector: "#ok", Confidence: 1},
+		},
+		{
+			name: "prose and braces in strings",
+			text: `Sure! {"action":"WAIT","selector":"div[data-x=\"}\"]","reasoning":"page {loading}"} done`,
+			want: autosolver.LLMResponse{Action: autosolver.ActionWait, Selector: `div[data-x="}"]`, Reasoning: "page {loading}"},
+		},
+		{
+			name: "navigate",
+			text: `{"action":"navigate","url":"https://example.com/next"}`,
+			want: autosolver.LLMResponse{Action: autosolver.ActionNavigate, URL: "https://example.com/next"},
+		},
+		{name: "navigate to javascript url", text: `{"action":"navigate","url":"javascript:alert(1)"}`, wantErr: true},
+		{name: "click without selector", text: `{"action":"click"}`, wantErr: true},
+		{name: "type without text", text: `{"action":"type","selector":"#q"}`, wantErr: true},
+		{name: "evaluate refused", text: `{"action":"evaluate"}`, wantErr: true},
+		{name: "no object", text: `I cannot help with that.`, wantErr: true},
+		{name: "unterminated", text: `{"action":"none"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLLMResponse(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLLMResponse: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string            `json:"model"`
+	Messages       []openAIMessage   `json:"messages"`
+	MaxTokens      int               `json:"max_tokens"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// completeOpenAI calls POST {baseURL}/chat/completions in JSON mode.
+func (p *Provider) completeOpenAI(ctx context.Context, baseURL, prompt string) (string, tokenUsage, error) {
+	headers := map[string]string{}
+	if p.config.APIKey != "" {
+		headers["Authorization"] = "Bearer " + p.config.APIKey
+	}
+	body := openAIRequest{
+		Model: p.config.Model,
+		Messages: []openAIMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:      p.config.MaxTokens,
+		Temperature:    p.config.Temperature,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	}
+
+	var out openAIResponse
+	if err := p.postJSON(ctx, baseURL+"/chat/completions", headers, body, &out); err != nil {
+		return "", tokenUsage{}, err
+	}
+	usage := tokenUsage{input: out.Usage.PromptTokens, output: out.Usage.CompletionTokens}
+	if len(out.Choices) == 0 {
+		return "", usage, fmt.Errorf("llm: openai: response has no choices")
+	}
+	return out.Choices[0].Message.Content, usage, nil
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/autosolver"
+)
+
+// parseLLMResponse extracts the action object from a completion. Models
+// wrap JSON in prose or code fences often enough that the first balanced
+// object in the text is taken rather than requiring a bare document.
+//
+// Only the actions the prompt offers are accepted; in particular evaluate
+// is refused so a model cannot run arbitrary script in the page.
+func parseLLMResponse(text string) (*autosolver.LLMResponse, error) {
+	obj := extractJSONObject(text)
+	if obj == "" {
+		return nil, fmt.Errorf("llm: no JSON object in response: %q", truncate(text, 120))
+	}
+
+	var raw struct {
+		Action     string          `json:"action"`
+		Selector   string          `json:"selector"`
+		Text       string          `json:"text"`
+		URL        string          `json:"url"`
+		Reasoning  string          `json:"reasoning"`
+		Confidence json.RawMessage `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(obj), &raw); err != nil {
+		return nil, fmt.Errorf("llm: decode action: %w", err)
+	}
+
+	resp := &autosolver.LLMResponse{
+		Action:     autosolver.ActionType(strings.ToLower(strings.TrimSpace(raw.Action))),
+		Selector:   strings.TrimSpace(raw.Selector),
+		Text:       raw.Text,
+		URL:        strings.TrimSpace(raw.URL),
+		Reasoning:  raw.Reasoning,
+		Confidence: parseConfidence(raw.Confidence),
+	}
+
+	switch resp.Action {
+	case autosolver.ActionClick:
+		if resp.Selector == "" {
+			return nil, fmt.Errorf("llm: click action without selector")
+		}
+	case autosolver.ActionType_:
+		if resp.Text == "" {
+			return nil, fmt.Errorf("llm: type action without text")
+		}
+	case autosolver.ActionNavigate:
+		u, err := url.Parse(resp.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("llm: navigate action needs an absolute http(s) url, got %q", resp.URL)
+		}
+	case autosolver.ActionWait, autosolver.ActionNone:
+	default:
+		return nil, fmt.Errorf("llm: unsupported action %q", raw.Action)
+	}
+	return resp, nil
+}
+
+// extractJSONObject returns the first balanced {...} in text, honouring
+// string literals so braces inside selectors or reasoning do not confuse
+// the scan. It returns "" when there is none.
+func extractJSONObject(text string) string {
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return text[start : i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// parseConfidence accepts a number or a numeric string and clamps it to
+// [0, 1]; anything else reads as 0.
+func parseConfidence(raw json.RawMessage) float64 {
+	if len(raw) == 0 {
+		return 0
+	}
+	var f float64
+	if err := json.Unmarshal(raw, &f); err != nil {
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return 0
+		}
+		if _, err := fmt.Sscanf(strings.TrimSpace(s), "%g", &f); err != nil {
+			return 0
+		}
+	}
+	return min(max(f, 0), 1)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,15 @@
+package autosolver
+
+import "github.com/pinchtab/pinchtab/internal/metrics"
+
+var metricAttempts = metrics.Default.NewCounterVec("pinchtab_autosolver_attempts",
+	"Autosolver attempts by solver and outcome.", "solver", "outcome")
+
+// recordAttempt counts one solver attempt. The rule-based stage records each
+// solver it runs rather than the summary entry it adds to the history.
+func recordAttempt(entry *AttemptEntry) {
+	if entry == nil || entry.Status == "" {
+		return
+	}
+	metricAttempts.Inc(entry.Solver, string(entry.Status))
+}
@@ -50,6 +50,7 @@ type Result struct {
 	FinalTitle    string         `json:"finalTitle,omitempty"`
 	FinalURL      string         `json:"finalURL,omitempty"`
 	Error         string         `json:"error,omitempty"`
+	LLMUsage      *LLMUsage      `json:"llmUsage,omitempty"` // Summed over all LLM calls in the run
 }
 
 // AttemptEntry records a single solver attempt within the fallback chain.
@@ -113,6 +114,36 @@ type LLMResponse struct {
 	URL        string     `json:"url,omitempty"`
 	Reasoning  string     `json:"reasoning,omitempty"`
 	Confidence float64    `json:"confidence"`
+	Usage      *LLMUsage  `json:"usage,omitempty"` // Tokens spent producing this response
+}
+
+// LLMUsage accounts for the tokens and estimated cost of LLM calls. CostUSD
+// is derived from the configured per-million-token prices and stays 0 when
+// none are set.
+type LLMUsage struct {
+	Provider     string  `json:"provider,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"inputTokens"`
+	OutputTokens int     `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
+}
+
+// Add folds u into the receiver.
+func (l *LLMUsage) Add(u *LLMUsage) {
+	if l == nil || u == nil {
+		return
+	}
+	if l.Provider == "" {
+		l.Provider = u.Provider
+	}
+	if l.Model == "" {
+		l.Model = u.Model
+	}
+	l.Calls += u.Calls
+	l.InputTokens += u.InputTokens
+	l.OutputTokens += u.OutputTokens
+	l.CostUSD += u.CostUSD
 }
 
 // Credentials supplies values the semantic solver injects into recognised
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 type ActionFunc func(ctx context.Context, req ActionRequest) (map[string]any, error)
@@ -78,6 +80,15 @@ type ActionRequest struct {
 	WaitNav bool   `json:"waitNav"`
 	Fast    bool   `json:"fast"`
 	Owner   string `json:"owner"`
+	// LockToken is the fencing token from POST /lock. When set, the action
+	// is refused unless it is still the tab's current lease.
+	LockToken uint64 `json:"lockToken,omitempty"`
+	// ExpectedEpoch is the epoch.domEpoch from /capture the caller's refs
+	// came from. When set, the action is refused with 409 stale_epoch once
+	// the tab's ref cache or DOM has moved on, unless RemapOnStale lets
+	// semantic recovery re-resolve Ref first.
+	ExpectedEpoch string `json:"expectedEpoch,omitempty"`
+	RemapOnStale  bool   `json:"remapOnStale,omitempty"`
 
 	// DismissBanners, when true and combined with WaitNav, runs a best-effort
 	// cookie/consent-banner dismissal pass after a click that triggered a
@@ -187,10 +198,15 @@ func (b *Bridge) ExecuteAction(ctx context.Context, kind string, req ActionReque
 		}
 	}
 
+	ctx, span := tracing.Start(ctx, "cdp.action", tracing.String("pinchtab.action", kind))
 	res, err := fn(ctx, req)
 	if err != nil {
-		return nil, classifyActionError(err)
+		err = classifyActionError(err)
+		span.RecordError(err)
+		span.End()
+		return nil, err
 	}
+	span.End()
 
 	if checkNav && beforeURL != "" {
 		afterURL, uErr := urlReader(ctx)
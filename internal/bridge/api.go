@@ -10,6 +10,7 @@ import (
 	bridgetabs "github.com/pinchtab/pinchtab/internal/bridge/tabs"
 	"github.com/pinchtab/pinchtab/internal/cdptk"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 	"github.com/pinchtab/pinchtab/internal/runtimetypes"
 	"github.com/pinchtab/pinchtab/internal/stealth"
 )
@@ -28,6 +29,20 @@ type BridgeAPI interface {
 	TabContext(tabID string) (ctx *TabHandle, resolvedID string, err error)
 	ListTargets() ([]TabTarget, error)
 	CreateTab(url string) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
+	// CreateTabWithProxy opens a tab in its own browser context behind a
+	// proxy from a configured pool; an empty pool is CreateTab.
+	CreateTabWithProxy(url string, opts TabProxyOptions) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
+	ProxyPools() []proxypool.PoolStatus
+	TabProxies() []TabProxy
+	// CreateTabInSession opens a tab in the browser context of an isolated
+	// agent session, creating the context on first use.
+	CreateTabInSession(url, sessionID string) (tabID string, ctx context.Context, cancel context.CancelFunc, err error)
+	SessionContext(sessionID string) (SessionContext, bool)
+	SessionContexts() []SessionContext
+	// TabSession returns the isolated session that owns a tab, or "" for a
+	// tab outside every session context.
+	TabSession(tabID string) string
+	DisposeSessionContext(sessionID string) (tabsClosed int, err error)
 	CloseTab(tabID string) error
 	FocusTab(tabID string) error
 
@@ -49,6 +64,8 @@ type BridgeAPI interface {
 
 	TabLockInfo(tabID string) *LockInfo
 	Lock(tabID, owner string, ttl time.Duration) error
+	WaitLock(ctx context.Context, tabID, owner string, ttl time.Duration) error
+	RenewLock(tabID, owner string, ttl time.Duration) error
 	Unlock(tabID, owner string) error
 
 	EnsureBrowser(cfg *config.RuntimeConfig) error
@@ -71,6 +88,35 @@ type BridgeAPI interface {
 	AddRouteRule(tabID string, rule RouteRule) error
 	RemoveRouteRule(tabID, pattern string) (int, error)
 	ListRouteRules(tabID string) ([]RouteRule, error)
+	StartNetworkReplay(tabID string, replay *HARReplay) error
+	StopNetworkReplay(tabID string) (*ReplayStatus, error)
+	NetworkReplayStatus(tabID string) (*ReplayStatus, error)
+
+	SetPermissions(ctx context.Context, origin string, perms map[string]string) error
+	ResetPermissions(ctx context.Context, origin string, names []string) error
+	PermissionOverrides(ctx context.Context, origin string) map[string]string
+	QueryPermissions(ctx context.Context, names []string) (map[string]string, error)
+	PermissionRequests(tabID string) ([]PermissionRequest, error)
+
+	AddVirtualAuthenticator(ctx context.Context, tabID string, opts WebAuthnAuthenticatorOptions) (*VirtualAuthenticator, int, error)
+	RemoveVirtualAuthenticator(ctx context.Context, tabID, authenticatorID string) error
+	VirtualAuthenticators(tabID string) []VirtualAuthenticator
+	SetAuthenticatorPresence(ctx context.Context, tabID, authenticatorID string, userPresence, userVerified *bool) (*VirtualAuthenticator, error)
+	WebAuthnCredentials(ctx context.Context, tabID, authenticatorID string) ([]*WebAuthnCredential, error)
+	AddWebAuthnCredential(ctx context.Context, tabID, authenticatorID string, cred *WebAuthnCredential) error
+	RemoveWebAuthnCredential(ctx context.Context, tabID, authenticatorID, credentialID string) error
+
+	ServiceWorkers(ctx context.Context, tabID string) ([]ServiceWorkerInfo, error)
+	UnregisterServiceWorker(ctx context.Context, tabID, scopeURL string) error
+	StopServiceWorkers(ctx context.Context, tabID, versionID string) error
+	SetServiceWorkerBypass(ctx context.Context, tabID string, bypass bool) error
+	ServiceWorkerBypass(tabID string) bool
+	CacheStorageCaches(ctx context.Context, origin string) ([]*CacheStorageCache, error)
+	CacheStorageEntries(ctx context.Context, cacheID string, skip, limit int, pathFilter string) ([]*CacheStorageEntry, int, error)
+	CacheStorageResponse(ctx context.Context, cacheID, requestURL string) ([]byte, error)
+	DeleteCacheStorage(ctx context.Context, cacheID, requestURL string) error
+	WithOriginTarget(ctx context.Context, origin string, fn func(ctx context.Context) error) error
+	VisitedOrigins(ctx context.Context) []string
 
 	GetDialogManager() *DialogManager
 
@@ -326,6 +372,12 @@ type Instance struct {
 	// Instance JSON byte-identical to pre-P2.4a output.
 	FallbackFrom   string `json:"fallbackFrom,omitempty"`
 	FallbackReason string `json:"fallbackReason,omitempty"`
+
+	// ProxyPool/Proxy: the pool the instance's proxy was drawn from and
+	// that proxy's server (no credentials). ProxyRegion is the pool's region.
+	ProxyPool   string `json:"proxyPool,omitempty"`
+	Proxy       string `json:"proxy,omitempty"`
+	ProxyRegion string `json:"proxyRegion,omitempty"`
 }
 
 func (i Instance) MarshalJSON() ([]byte, error) {
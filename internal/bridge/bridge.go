@@ -7,11 +7,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/chromedp"
-	bridgeruntime "github.com/pinchtab/pinchtab/internal/bridge/runtime"
 	"github.com/pinchtab/pinchtab/internal/browsers"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/ids"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 	"github.com/pinchtab/pinchtab/internal/stealth"
 )
 
@@ -54,9 +55,48 @@ type Bridge struct {
 	workerStealthTargets sync.Map
 	handoffMu            sync.RWMutex
 	handoffs             map[string]TabHandoffState
+	handoffResumes       map[string]TabHandoffResume
 	pointerMu            sync.RWMutex
 	pointerByTab         map[string]pointerState
 
+	// permMu guards the permission overrides set through SetPermissions
+	// (origin → name → setting) and permBrowser, the browser they and the
+	// instance defaults were last applied to.
+	permMu           sync.Mutex
+	permOverrides    map[string]map[string]string
+	ctxPermOverrides map[cdp.BrowserContextID]map[string]map[string]string
+	permBrowser      *chromedp.Browser
+
+	// webAuthnMu guards the per-tab virtual authenticators and the credential
+	// stores they are seeded from: the profile store for the default browser
+	// context, one in-memory store per other context (see webauthn.go).
+	webAuthnMu       sync.Mutex
+	webAuthnTabs     map[string]*webAuthnTab
+	webAuthnCreds    *webAuthnCredentialStore
+	ctxWebAuthnCreds map[cdp.BrowserContextID]*webAuthnCredentialStore
+
+	// swMu guards the per-tab service worker tracking and bypass flags
+	// (see serviceworker.go).
+	swMu   sync.Mutex
+	swTabs map[string]*serviceWorkerTab
+
+	// visited holds the origins seen this browser session (see
+	// origin_target.go).
+	visited visitedOrigins
+
+	// tabProxyMu guards the pool proxies of tabs opened through
+	// CreateTabWithProxy, the browser contexts they share and the lazily
+	// built pool manager (see proxy_pool.go).
+	tabProxyMu    sync.Mutex
+	tabProxies    map[string]*tabProxy
+	proxyContexts map[string]*proxyContext
+	proxyPools    *proxypool.Manager
+
+	// sessionCtxMu guards the browser contexts of isolated agent sessions
+	// (see session_context.go).
+	sessionCtxMu    sync.Mutex
+	sessionContexts map[string]*sessionContext
+
 	// Initialized during EnsureBrowser. Nil before launch.
 	Runtime browsers.RuntimeInstance
 
@@ -89,6 +129,7 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 		netMonitor:          NewNetworkMonitor(netBufSize),
 		fingerprintOverlays: make(map[string]bool),
 		handoffs:            make(map[string]TabHandoffState),
+		handoffResumes:      make(map[string]TabHandoffResume),
 		pointerByTab:        make(map[string]pointerState),
 		LogStore:            logStore,
 		stealthLaunchMode:   stealth.LaunchModeUninitialized,
@@ -103,7 +144,7 @@ func New(allocCtx, browserCtx context.Context, cfg *config.RuntimeConfig) *Bridg
 		return b.Config.AllowedDomains
 	})
 	b.routeMgr.SetFetchAuthCoordination(
-		func() bool { return b.Config != nil && bridgeruntime.ProxyAuthEnabled(b.Config.Proxy) },
+		b.proxyAuthConfigured,
 		b.SetFetchPauseSuppressed,
 	)
 	b.ensureStealthBundle()
@@ -216,6 +257,10 @@ func (b *Bridge) wireTabManager(browserCtx context.Context) {
 	// wire, so no cross-reinit duplication). External hooks recorded on the
 	// bridge are re-applied so they survive the TabManager swap.
 	b.TabManager.AddTabRemovedHook(b.dropFetchPauseSuppression)
+	b.TabManager.AddTabRemovedHook(b.dropWebAuthnTab)
+	b.TabManager.AddTabRemovedHook(b.dropServiceWorkerTab)
+	b.TabManager.AddTabRemovedHook(b.dropTabProxy)
+	b.TabManager.AddTabRemovedHook(b.dropSessionTab)
 	b.tabRemovedHooksMu.Lock()
 	hooks := make([]func(string), len(b.externalTabRemovedHooks))
 	copy(hooks, b.externalTabRemovedHooks)
@@ -321,6 +366,17 @@ func (b *Bridge) Lock(tabID, owner string, ttl time.Duration) error {
 	return b.Locks.TryLock(tabID, owner, ttl)
 }
 
+// WaitLock acquires the tab lock, queueing behind other waiters until the
+// lock frees up or ctx is done.
+func (b *Bridge) WaitLock(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	return b.Locks.Lock(ctx, tabID, owner, ttl)
+}
+
+// RenewLock extends a lease owner still holds without re-acquiring it.
+func (b *Bridge) RenewLock(tabID, owner string, ttl time.Duration) error {
+	return b.Locks.Renew(tabID, owner, ttl)
+}
+
 func (b *Bridge) Unlock(tabID, owner string) error {
 	return b.Locks.Unlock(tabID, owner)
 }
@@ -511,6 +567,42 @@ func (b *Bridge) ListRouteRules(tabID string) ([]RouteRule, error) {
 	return b.routeMgr.List(resolvedID), nil
 }
 
+// Answers the tab's unmatched requests from a recorded HAR archive, replacing
+// any replay already active. Route rules keep precedence.
+func (b *Bridge) StartNetworkReplay(tabID string, replay *HARReplay) error {
+	if b.routeMgr == nil {
+		return fmt.Errorf("route manager not initialized")
+	}
+	tabHandle, resolvedID, err := b.TabContext(tabID)
+	if err != nil {
+		return err
+	}
+	return b.routeMgr.SetReplay(tabHandle, resolvedID, replay)
+}
+
+// Returns the final status of the stopped replay, or nil if none was active.
+func (b *Bridge) StopNetworkReplay(tabID string) (*ReplayStatus, error) {
+	if b.routeMgr == nil {
+		return nil, fmt.Errorf("route manager not initialized")
+	}
+	tabHandle, resolvedID, err := b.TabContext(tabID)
+	if err != nil {
+		return nil, err
+	}
+	return b.routeMgr.ClearReplay(tabHandle, resolvedID), nil
+}
+
+func (b *Bridge) NetworkReplayStatus(tabID string) (*ReplayStatus, error) {
+	if b.routeMgr == nil {
+		return nil, fmt.Errorf("route manager not initialized")
+	}
+	_, resolvedID, err := b.TabContext(tabID)
+	if err != nil {
+		return nil, err
+	}
+	return b.routeMgr.ReplayStatus(resolvedID), nil
+}
+
 func (b *Bridge) GetDialogManager() *DialogManager {
 	return b.Dialogs
 }
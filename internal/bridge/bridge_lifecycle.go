@@ -88,11 +88,15 @@ func (b *Bridge) tabSetup(ctx context.Context, tabID string) {
 	// the launch/attach init paths. The suppression flag quiets this
 	// listener's request-pause continue while RouteManager rules or the
 	// credentials handler own dispatch on the tab.
-	if b.Config != nil {
-		if err := bridgeruntime.EnableProxyAuth(ctx, b.Config.Proxy, b.fetchPauseSuppression(tabID)); err != nil {
+	// Tabs behind a pool proxy answer with that proxy's credentials.
+	if proxy, pooled := b.tabProxyConfig(tabID); b.Config != nil {
+		if err := bridgeruntime.EnableProxyAuth(ctx, proxy, b.fetchPauseSuppression(tabID)); err != nil {
 			slog.Warn("per-tab proxy auth setup failed", "err", err)
-		} else if bridgeruntime.ProxyAuthEnabled(b.Config.Proxy) {
-			slog.Debug("per-tab proxy auth enabled", "tab", tabID)
+		} else if bridgeruntime.ProxyAuthEnabled(proxy) {
+			slog.Debug("per-tab proxy auth enabled", "tab", tabID, "pooled", pooled)
+		}
+		if pooled {
+			b.setupTabProxy(ctx, tabID)
 		}
 	}
 	if !config.PinchTabStealthDefaultsDisabled(b.Config) {
@@ -100,6 +104,11 @@ func (b *Bridge) tabSetup(ctx context.Context, tabID string) {
 		b.installWorkerStealthParity(ctx)
 	}
 	b.injectStealth(ctx)
+	b.installPermissionWatch(ctx)
+	b.trackVisitedOrigins(ctx)
+	if err := b.ensurePermissionDefaults(ctx); err != nil {
+		slog.Warn("default permissions setup failed", "tab", tabID, "err", err)
+	}
 	if b.Config != nil && b.Config.NoAnimations {
 		if err := b.InjectNoAnimations(ctx); err != nil {
 			slog.Warn("no-animations injection failed", "err", err)
@@ -364,6 +373,11 @@ func (b *Bridge) RestartBrowser(cfg *config.RuntimeConfig) error {
 // Cleanup releases browser resources and removes temporary profile directories.
 // Must be called on shutdown to prevent Chrome process and disk leaks.
 func (b *Bridge) Cleanup() {
+	if b != nil {
+		b.tabProxyMu.Lock()
+		b.proxyPools.Close()
+		b.tabProxyMu.Unlock()
+	}
 	// Remote-CDP: external browser is not owned by PinchTab.
 	if b != nil && b.Config != nil && strings.TrimSpace(b.Config.RemoteCDPURL) != "" {
 		if b.BrowserCancel != nil {
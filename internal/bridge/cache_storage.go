@@ -0,0 +1,95 @@
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cachestorage"
+	"github.com/chromedp/chromedp"
+)
+
+// CacheStorageCache is a Cache Storage cache of an origin.
+type CacheStorageCache = cachestorage.Cache
+
+// CacheStorageEntry is a request/response pair stored in a cache.
+type CacheStorageEntry = cachestorage.DataEntry
+
+// ErrCacheEntryNotFound is returned when a cache holds no entry for a URL.
+var ErrCacheEntryNotFound = errors.New("cache entry not found")
+
+// CacheStorageCaches lists the Cache Storage caches of origin.
+func (b *Bridge) CacheStorageCaches(ctx context.Context, origin string) ([]*CacheStorageCache, error) {
+	var caches []*CacheStorageCache
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		caches, err = cachestorage.RequestCacheNames().WithSecurityOrigin(origin).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, fmt.Errorf("list caches: %w", err)
+	}
+	if caches == nil {
+		caches = []*CacheStorageCache{}
+	}
+	return caches, nil
+}
+
+// CacheStorageEntries returns up to limit entries of a cache after skipping
+// skip, optionally keeping only URLs whose path contains pathFilter. The
+// total is the number of entries matching the filter.
+func (b *Bridge) CacheStorageEntries(ctx context.Context, cacheID string, skip, limit int, pathFilter string) ([]*CacheStorageEntry, int, error) {
+	var entries []*CacheStorageEntry
+	var total float64
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		p := cachestorage.RequestEntries(cachestorage.CacheID(cacheID)).WithSkipCount(int64(skip))
+		if limit > 0 {
+			p = p.WithPageSize(int64(limit))
+		}
+		if pathFilter != "" {
+			p = p.WithPathFilter(pathFilter)
+		}
+		var err error
+		entries, total, err = p.Do(ctx)
+		return err
+	})); err != nil {
+		return nil, 0, fmt.Errorf("list cache entries: %w", err)
+	}
+	if entries == nil {
+		entries = []*CacheStorageEntry{}
+	}
+	return entries, int(total), nil
+}
+
+// CacheStorageResponse returns the cached response body for requestURL.
+func (b *Bridge) CacheStorageResponse(ctx context.Context, cacheID, requestURL string) ([]byte, error) {
+	var resp *cachestorage.CachedResponse
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		resp, err = cachestorage.RequestCachedResponse(cachestorage.CacheID(cacheID), requestURL, []*cachestorage.Header{}).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, fmt.Errorf("read cache entry: %w", err)
+	}
+	if resp == nil {
+		return nil, ErrCacheEntryNotFound
+	}
+	body, err := base64.StdEncoding.DecodeString(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decode cache entry: %w", err)
+	}
+	return body, nil
+}
+
+// DeleteCacheStorage deletes the entry for requestURL from a cache, or the
+// whole cache when requestURL is empty.
+func (b *Bridge) DeleteCacheStorage(ctx context.Context, cacheID, requestURL string) error {
+	var action chromedp.Action = cachestorage.DeleteCache(cachestorage.CacheID(cacheID))
+	if requestURL != "" {
+		action = cachestorage.DeleteEntry(cachestorage.CacheID(cacheID), requestURL)
+	}
+	if err := chromedp.Run(ctx, action); err != nil {
+		return fmt.Errorf("delete cache storage: %w", err)
+	}
+	return nil
+}
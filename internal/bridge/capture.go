@@ -2,8 +2,6 @@ package bridge
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/base32"
 	"time"
 
 	"github.com/chromedp/cdproto/page"
@@ -46,6 +44,10 @@ type PairedResult struct {
 	LoaderID  string
 	DomEpoch  string
 	Navigated bool
+	// DomMutated is true when the main document's structure changed while
+	// the capture window was open (re-renders, lazy content). Best-effort:
+	// it stays false when the page observer could not be armed.
+	DomMutated bool
 
 	ImageBytes  []byte
 	ImageFormat string // "jpeg" or "png"
@@ -69,9 +71,10 @@ type PairedResult struct {
 // frame's loaderId before and after the capture window. opts.Wait == "stable"
 // adds a Page.lifecycleEvent quiet-window wait before the window opens.
 // opts.WithBounds populates a viewport-, document-, or clip-relative
-// BoundingBox per snapshot node via DOM.getBoxModel. Residual risk:
-// in-document churn (React re-renders, IntersectionObserver mutations) is not
-// detected — wait:stable reduces but does not eliminate it.
+// BoundingBox per snapshot node via DOM.getBoxModel. In-document churn
+// (React re-renders, IntersectionObserver mutations) is not prevented, but a
+// MutationObserver armed for the result's DomEpoch reports it as DomMutated
+// and keeps watching after the capture so later actions can detect it too.
 func PairedCapture(ctx context.Context, opts CaptureOpts) (*PairedResult, error) {
 	start := time.Now()
 	res := &PairedResult{
@@ -101,6 +104,9 @@ func PairedCapture(ctx context.Context, opts CaptureOpts) (*PairedResult, error)
 	res.FrameID = pre.Frame.ID
 	res.LoaderID = pre.Frame.LoaderID
 
+	res.DomEpoch = MintDomEpoch()
+	armed := ArmDomEpoch(ctx, res.DomEpoch) == nil
+
 	// Layout metrics: captured BEFORE the screenshot so opts.Image.Scale can
 	// synthesize a viewport-covering clip when no other clip is set. Also
 	// populates the response viewport / devicePixelRatio for clients.
@@ -158,15 +164,21 @@ func PairedCapture(ctx context.Context, opts CaptureOpts) (*PairedResult, error)
 	}
 
 	// Post-capture frame info. Compare root frame id + loader id to detect
-	// navigation that happened during the capture window. We do not assert on
-	// in-document churn (React re-renders, observer mutations) — that's the
-	// residual risk wait:stable mitigates in P2.
+	// navigation that happened during the capture window.
 	post, err := FetchFrameTree(ctx)
 	if err == nil {
 		res.Navigated = pre.Frame.ID != post.Frame.ID || pre.Frame.LoaderID != post.Frame.LoaderID
 	}
 
-	res.DomEpoch = mintDomEpoch()
+	// In-document churn during the window is reported, then the observer is
+	// re-armed so the epoch handed out covers the DOM as of now.
+	if armed && !res.Navigated {
+		if changed, err := DomEpochChanged(ctx, res.DomEpoch); err == nil && changed {
+			res.DomMutated = true
+			_ = ArmDomEpoch(ctx, res.DomEpoch)
+		}
+	}
+
 	res.DurationMs = time.Since(start).Milliseconds()
 	return res, nil
 }
@@ -200,12 +212,3 @@ func FilterAXNodesByFrame(nodes []RawAXNode, frameID string) []RawAXNode {
 	}
 	return filtered
 }
-
-// mintDomEpoch returns an opaque token unique per paired capture. The token
-// has no semantic content — consumers should treat it as a black box and use
-// it only for handshake comparisons against the cached value on RefCache.
-func mintDomEpoch() string {
-	var b [12]byte
-	_, _ = rand.Read(b[:])
-	return "ep_" + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b[:])
-}
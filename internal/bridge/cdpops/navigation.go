@@ -12,6 +12,8 @@ import (
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 const TargetTypePage = "page"
@@ -32,12 +34,16 @@ func NavigatePage(ctx context.Context, url string) error {
 // reaches "interactive" or "complete", returning nil; it returns ctx.Err() if the
 // context ends first. readyState eval errors are ignored and retried.
 func waitForReadyStateAfterNavigation(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "navigation.ready_state")
+	defer span.End()
+
 	ticker := time.NewTicker(200 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			span.RecordError(ctx.Err())
 			return ctx.Err()
 		case <-ticker.C:
 			var state string
@@ -130,7 +136,13 @@ func shouldReplaceInitialBlankNavigation(ctx context.Context) (bool, error) {
 	return ShouldReplaceBlankHistoryEntry(curURL, cur, len(entries)), nil
 }
 
-func startNavigation(ctx context.Context, url string, replaceInitialBlank bool) error {
+func startNavigation(ctx context.Context, url string, replaceInitialBlank bool) (err error) {
+	ctx, span := tracing.Start(ctx, "cdp.navigate", tracing.Bool("pinchtab.replace_initial_blank", replaceInitialBlank))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if !replaceInitialBlank {
 		_, _, _, _, err := page.Navigate(url).Do(ctx)
 		return err
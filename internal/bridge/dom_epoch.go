@@ -0,0 +1,86 @@
+package bridge
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+
+	"github.com/chromedp/chromedp"
+)
+
+// domEpochArmJS installs a MutationObserver on the main document that tags
+// it with an epoch and remembers elements removed from then on that refs can
+// point at. Arming a new epoch replaces the previous observer. Live pages
+// churn constantly (clocks, spinners, ads), so text edits, additions and
+// removals of non-interactive content are ignored: they do not invalidate
+// the refs actions target. Removed elements that are later reattached (a
+// framework moving a node) keep their backend node id and are not counted
+// either. Nodes PinchTab injects itself (data-pinchtab) are ignored so our
+// own overlays do not bump the epoch. Past maxTracked removals the epoch is
+// treated as changed without holding on to more nodes.
+const domEpochArmJS = `
+(function(epoch) {
+  const prev = window.__pinchtabDomEpoch;
+  if (prev && prev.observer) prev.observer.disconnect();
+  const maxTracked = 256;
+  const refSelector = 'a[href],area[href],button,input,select,textarea,summary,[contenteditable],[tabindex],' +
+    ['button','link','checkbox','radio','textbox','searchbox','combobox','listbox','option','menuitem',
+     'menuitemcheckbox','menuitemradio','tab','switch','slider','spinbutton','treeitem']
+      .map((r) => '[role="' + r + '"]').join(',');
+  const holdsRefs = (n) => n.nodeType === 1 && !n.hasAttribute('data-pinchtab') &&
+    (n.matches(refSelector) || n.querySelector(refSelector) !== null);
+  const state = { epoch: epoch, removed: [], overflow: false, observer: null };
+  state.observer = new MutationObserver((records) => {
+    for (const r of records) {
+      for (const n of r.removedNodes) {
+        if (!holdsRefs(n)) continue;
+        if (state.removed.length >= maxTracked) { state.overflow = true; continue; }
+        state.removed.push(n);
+      }
+    }
+  });
+  state.observer.observe(document, { childList: true, subtree: true });
+  Object.defineProperty(window, '__pinchtabDomEpoch', { value: state, configurable: true, writable: true });
+  return true;
+})(%q)
+`
+
+// domEpochCheckJS returns -1 when the document no longer carries epoch (a
+// new document or a newer epoch), else the number of tracked elements that
+// are still detached.
+const domEpochCheckJS = `
+(function(epoch) {
+  const s = window.__pinchtabDomEpoch;
+  if (!s || s.epoch !== epoch) return -1;
+  if (s.overflow) return s.removed.length + 1;
+  return s.removed.filter((n) => !n.isConnected).length;
+})(%q)
+`
+
+// MintDomEpoch returns an opaque token unique per ref-cache generation. The
+// token has no semantic content — consumers should treat it as a black box
+// and use it only for handshake comparisons against the cached value on
+// RefCache.
+func MintDomEpoch() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "ep_" + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b[:])
+}
+
+// ArmDomEpoch starts tracking in-document mutations for epoch on the tab's
+// main frame. Mutations inside child frames are not observed.
+func ArmDomEpoch(ctx context.Context, epoch string) error {
+	return chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(domEpochArmJS, epoch), nil))
+}
+
+// DomEpochChanged reports whether the main document has moved on from
+// epoch: it was replaced by a navigation, a newer epoch was armed, or an
+// interactive element was removed since ArmDomEpoch and not put back.
+func DomEpochChanged(ctx context.Context, epoch string) (bool, error) {
+	var mutations int
+	if err := chromedp.Run(ctx, chromedp.Evaluate(fmt.Sprintf(domEpochCheckJS, epoch), &mutations)); err != nil {
+		return false, err
+	}
+	return mutations != 0, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/chromedp/cdproto/fetch"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/storage"
 	"github.com/chromedp/chromedp"
 )
 
@@ -143,13 +144,24 @@ func (b *Bridge) SetRawCookie(ctx context.Context, params RawSetCookieParams) er
 	}))
 }
 
+// GetRawCookies returns the cookies of the tab's pages. A tab in a browser
+// context of its own (isolated session, pool proxy) gets the whole jar of
+// that context instead, so saved state snapshots the context.
 func (b *Bridge) GetRawCookies(ctx context.Context) ([]RawCookie, error) {
 	var cookies []*network.Cookie
-	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-		var err error
-		cookies, err = network.GetCookies().Do(ctx)
-		return err
-	}))
+	var err error
+	if contextID := b.browserContextOf(ctx); contextID != "" {
+		var execCtx context.Context
+		if execCtx, err = browserExecutorContext(ctx); err == nil {
+			cookies, err = storage.GetCookies().WithBrowserContextID(contextID).Do(execCtx)
+		}
+	} else {
+		err = chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			var err error
+			cookies, err = network.GetCookies().Do(ctx)
+			return err
+		}))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,16 @@ type TabHandoffState struct {
 	ExpiresAt     time.Time `json:"expiresAt,omitempty"`
 }
 
+// TabHandoffResume is the structured outcome a human (or agent) hands back
+// when ending a handoff. It outlives the pause so the agent can read it after
+// the tab goes active again.
+type TabHandoffResume struct {
+	Status    string         `json:"status,omitempty"`
+	Data      map[string]any `json:"resolvedData,omitempty"`
+	ResumedBy string         `json:"resumedBy"` // "api" or "takeover"
+	ResumedAt time.Time      `json:"resumedAt"`
+}
+
 func (b *Bridge) SetTabHandoff(tabID, reason string, timeout time.Duration) error {
 	if b == nil {
 		return fmt.Errorf("bridge not initialized")
@@ -39,6 +49,8 @@ func (b *Bridge) SetTabHandoff(tabID, reason string, timeout time.Duration) erro
 	b.handoffMu.Lock()
 	defer b.handoffMu.Unlock()
 	b.handoffs[tabID] = state
+	// A new pause invalidates the outcome of the previous one.
+	delete(b.handoffResumes, tabID)
 	return nil
 }
 
@@ -75,3 +87,27 @@ func (b *Bridge) TabHandoffState(tabID string) (TabHandoffState, bool) {
 	}
 	return state, ok
 }
+
+// RecordTabResume stores the outcome of the tab's most recent handoff.
+func (b *Bridge) RecordTabResume(tabID string, resume TabHandoffResume) {
+	if b == nil || strings.TrimSpace(tabID) == "" {
+		return
+	}
+	b.handoffMu.Lock()
+	defer b.handoffMu.Unlock()
+	if b.handoffResumes == nil {
+		b.handoffResumes = make(map[string]TabHandoffResume)
+	}
+	b.handoffResumes[tabID] = resume
+}
+
+// LastTabResume returns the outcome recorded by RecordTabResume, if any.
+func (b *Bridge) LastTabResume(tabID string) (TabHandoffResume, bool) {
+	if b == nil {
+		return TabHandoffResume{}, false
+	}
+	b.handoffMu.RLock()
+	defer b.handoffMu.RUnlock()
+	resume, ok := b.handoffResumes[tabID]
+	return resume, ok
+}
@@ -21,3 +21,18 @@ func TestTabHandoffState_Expires(t *testing.T) {
 		t.Fatal("expected handoff state to expire")
 	}
 }
+
+func TestTabResume_ClearedByNextPause(t *testing.T) {
+	b := New(context.TODO(), nil, &config.RuntimeConfig{})
+	b.RecordTabResume("tab1", TabHandoffResume{Status: "solved", ResumedBy: "takeover", ResumedAt: time.Now()})
+	got, ok := b.LastTabResume("tab1")
+	if !ok || got.Status != "solved" || got.ResumedBy != "takeover" {
+		t.Fatalf("LastTabResume = %+v, %v", got, ok)
+	}
+	if err := b.SetTabHandoff("tab1", "2fa", 0); err != nil {
+		t.Fatalf("set handoff: %v", err)
+	}
+	if _, ok := b.LastTabResume("tab1"); ok {
+		t.Fatal("expected a new pause to clear the previous resume")
+	}
+}
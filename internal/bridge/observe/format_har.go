@@ -75,3 +75,23 @@ func (e *harEncoder) Finish() error {
 	_, err := io.WriteString(e.w, "]}}\n")
 	return err
 }
+
+// harDocument is the subset of a HAR 1.2 file ReadHAR needs.
+type harDocument struct {
+	Log *struct {
+		Entries []ExportEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// ReadHAR decodes the entries of a HAR 1.2 document, such as one written by
+// the "har" export format or by browser devtools.
+func ReadHAR(r io.Reader) ([]ExportEntry, error) {
+	var doc harDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode har: %w", err)
+	}
+	if doc.Log == nil {
+		return nil, fmt.Errorf("decode har: missing log object")
+	}
+	return doc.Log.Entries, nil
+}
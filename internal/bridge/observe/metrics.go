@@ -11,6 +11,8 @@ import (
 // MemoryMetrics holds Chrome memory statistics.
 type MemoryMetrics struct {
 	MemoryMB float64 `json:"memoryMB"`
+	// CPUSeconds is the CPU time (user + system) used by the process tree.
+	CPUSeconds float64 `json:"cpuSeconds"`
 
 	JSHeapUsedMB  float64 `json:"jsHeapUsedMB"`
 	JSHeapTotalMB float64 `json:"jsHeapTotalMB"`
@@ -50,6 +52,7 @@ func GetAggregatedMemoryMetrics(browserCtx context.Context) (*MemoryMetrics, err
 	if err != nil {
 		mem, _ := getProcessMemory(mainPID)
 		result.MemoryMB = float64(mem) / (1024 * 1024)
+		result.CPUSeconds = getProcessCPUSeconds(p)
 		return result, nil
 	}
 
@@ -58,6 +61,7 @@ func GetAggregatedMemoryMetrics(browserCtx context.Context) (*MemoryMetrics, err
 
 	mem, _ := getProcessMemory(mainPID)
 	totalMem += mem
+	cpu := getProcessCPUSeconds(p)
 
 	for _, child := range children {
 		cmdline, _ := child.Cmdline()
@@ -66,9 +70,11 @@ func GetAggregatedMemoryMetrics(browserCtx context.Context) (*MemoryMetrics, err
 		}
 		childMem, _ := getProcessMemory(child.Pid)
 		totalMem += childMem
+		cpu += getProcessCPUSeconds(child)
 	}
 
 	result.MemoryMB = float64(totalMem) / (1024 * 1024)
+	result.CPUSeconds = cpu
 	result.Renderers = rendererCount
 	result.JSHeapUsedMB = result.MemoryMB * 0.4
 	result.JSHeapTotalMB = result.MemoryMB * 0.5
@@ -90,6 +96,14 @@ func getProcessMemory(pid int32) (uint64, error) {
 	return mem.RSS, nil
 }
 
+func getProcessCPUSeconds(p *process.Process) float64 {
+	times, err := p.Times()
+	if err != nil || times == nil {
+		return 0
+	}
+	return times.User + times.System
+}
+
 func containsRenderer(cmdline string) bool {
 	return strings.Contains(cmdline, "--type=renderer") || strings.Contains(cmdline, "--type=tab")
 }
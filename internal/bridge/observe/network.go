@@ -129,6 +129,9 @@ func (nm *NetworkMonitor) StartCaptureWithSize(tabCtx context.Context, tabID str
 	}
 
 	chromedp.ListenTarget(listenerCtx, func(ev interface{}) {
+		if handleWebSocketEvent(buf, ev) {
+			return
+		}
 		switch e := ev.(type) {
 		case *network.EventRequestWillBeSent:
 			headers := make(map[string]string)
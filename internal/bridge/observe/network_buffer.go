@@ -37,6 +37,9 @@ type NetworkEntry struct {
 	BodySkipReason  string            `json:"bodySkipReason,omitempty"`
 	BodyTruncated   bool              `json:"bodyTruncated,omitempty"`
 	BodyError       string            `json:"bodyError,omitempty"`
+	FramesSent      int               `json:"framesSent,omitempty"`
+	FramesReceived  int               `json:"framesReceived,omitempty"`
+	FramesDropped   int               `json:"framesDropped,omitempty"`
 }
 
 type NetworkBuffer struct {
@@ -72,6 +75,15 @@ type NetworkBuffer struct {
 	// closed and replaced on each signal; waiters capture it before reading state.
 	bodyChangeMu sync.Mutex
 	bodyChangeCh chan struct{}
+
+	// WebSocket frames are kept beside the entry ring, keyed by RequestID, so
+	// entry copies handed to subscribers stay small. wsFrames follows entry
+	// eviction and is guarded by mu; frame subscribers have their own lock.
+	wsFrames       map[string]*wsFrameRing
+	wsFrameLimit   int
+	frameSubMu     sync.Mutex
+	frameSubs      map[string]map[int]chan WebSocketFrame
+	nextFrameSubID int
 }
 
 func NewNetworkBuffer(size int) *NetworkBuffer {
@@ -88,6 +100,9 @@ func NewNetworkBuffer(size int) *NetworkBuffer {
 		subscribers:    make(map[int]chan NetworkEntry),
 		completionSubs: make(map[int]chan string),
 		bodyChangeCh:   make(chan struct{}),
+		wsFrames:       make(map[string]*wsFrameRing),
+		wsFrameLimit:   DefaultWebSocketFrameLimit,
+		frameSubs:      make(map[string]map[int]chan WebSocketFrame),
 	}
 }
 
@@ -186,6 +201,7 @@ func (nb *NetworkBuffer) Add(entry NetworkEntry) {
 	nb.mu.Lock()
 
 	isNew := false
+	evicted := ""
 	if slot, ok := nb.index[entry.RequestID]; ok {
 		nb.entries[slot] = entry
 	} else {
@@ -201,6 +217,10 @@ func (nb *NetworkBuffer) Add(entry NetworkEntry) {
 				}
 			}
 			delete(nb.index, oldest.RequestID)
+			if IsWebSocket(oldest) {
+				delete(nb.wsFrames, oldest.RequestID)
+				evicted = oldest.RequestID
+			}
 			nb.head = (nb.head + 1) % nb.maxSize
 		} else {
 			slot = (nb.head + nb.size) % nb.maxSize
@@ -211,6 +231,9 @@ func (nb *NetworkBuffer) Add(entry NetworkEntry) {
 	}
 	nb.mu.Unlock()
 
+	if evicted != "" {
+		nb.closeFrameSubs(evicted)
+	}
 	if isNew {
 		nb.subMu.Lock()
 		for _, ch := range nb.subscribers {
@@ -294,15 +317,18 @@ func (nb *NetworkBuffer) List(filter NetworkFilter) []NetworkEntry {
 	return result
 }
 
-// Clear removes all entries. Inflight tracking is preserved because
-// active requests are not affected by a buffer clear.
+// Clear removes all entries and WebSocket frames, ending live frame streams.
+// Inflight tracking is preserved because active requests are not affected by
+// a buffer clear.
 func (nb *NetworkBuffer) Clear() {
 	nb.mu.Lock()
-	defer nb.mu.Unlock()
 	nb.entries = make([]NetworkEntry, nb.maxSize)
 	nb.index = make(map[string]int)
+	nb.wsFrames = make(map[string]*wsFrameRing)
 	nb.head = 0
 	nb.size = 0
+	nb.mu.Unlock()
+	nb.closeFrameSubs()
 }
 
 func (nb *NetworkBuffer) Len() int {
@@ -72,6 +72,19 @@ type ExportEntry struct {
 	Request         ExportRequest  `json:"request"`
 	Response        ExportResponse `json:"response"`
 	Timings         ExportTimings  `json:"timings"`
+
+	// WebSocketMessages carries the frames of a WebSocket entry, following
+	// the _webSocketMessages extension Chrome DevTools writes into HAR files.
+	WebSocketMessages []ExportWebSocketMessage `json:"_webSocketMessages,omitempty"`
+}
+
+// ExportWebSocketMessage is one WebSocket frame. Time is seconds since the
+// Unix epoch; Type is "send" or "receive".
+type ExportWebSocketMessage struct {
+	Type   string  `json:"type"`
+	Time   float64 `json:"time"`
+	Opcode int     `json:"opcode"`
+	Data   string  `json:"data"`
 }
 
 // ExportRequest holds the request portion of an entry.
@@ -172,6 +185,24 @@ func NetworkEntryToExport(entry NetworkEntry, body string, base64Encoded bool) E
 	return e
 }
 
+// WebSocketFramesToExport converts captured frames to export messages. It
+// returns nil for an empty slice so non-WebSocket entries omit the field.
+func WebSocketFramesToExport(frames []WebSocketFrame) []ExportWebSocketMessage {
+	if len(frames) == 0 {
+		return nil
+	}
+	msgs := make([]ExportWebSocketMessage, len(frames))
+	for i, f := range frames {
+		msgs[i] = ExportWebSocketMessage{
+			Type:   f.Type,
+			Time:   float64(f.Time.UnixNano()) / 1e9,
+			Opcode: f.Opcode,
+			Data:   f.Data,
+		}
+	}
+	return msgs
+}
+
 func mapToNameValuePairs(headers map[string]string) []NameValuePair {
 	if len(headers) == 0 {
 		return []NameValuePair{}
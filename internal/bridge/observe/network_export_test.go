@@ -117,6 +117,30 @@ func TestHAREncoder_Empty(t *testing.T) {
 	}
 }
 
+func TestReadHAR_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := GetFormat("har")("TestApp", "1.0")
+	_ = enc.Start(&buf)
+	_ = enc.Encode(makeTestExportEntry())
+	_ = enc.Finish()
+
+	entries, err := ReadHAR(&buf)
+	if err != nil {
+		t.Fatalf("ReadHAR: %v", err)
+	}
+	want := makeTestExportEntry()
+	if len(entries) != 1 || entries[0].Request.URL != want.Request.URL || entries[0].Response.Status != want.Response.Status {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	if _, err := ReadHAR(strings.NewReader(`{"entries":[]}`)); err == nil {
+		t.Error("expected error for a document without a log object")
+	}
+	if _, err := ReadHAR(strings.NewReader(`not json`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
 func TestNDJSONEncoder(t *testing.T) {
 	factory := GetFormat("ndjson")
 	if factory == nil {
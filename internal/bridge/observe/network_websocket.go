@@ -0,0 +1,275 @@
+package observe
+
+import (
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/pinchtab/pinchtab/internal/sanitize"
+)
+
+const (
+	// DefaultWebSocketFrameLimit is how many frames each WebSocket connection
+	// keeps. Older frames are dropped and counted in NetworkEntry.FramesDropped.
+	DefaultWebSocketFrameLimit = 200
+
+	// maxWebSocketFrameDataBytes caps the payload kept per frame. Binary
+	// payloads arrive base64-encoded from CDP and are capped the same way.
+	maxWebSocketFrameDataBytes = 16 * 1024
+)
+
+// WebSocket resource type and frame directions. The direction strings follow
+// the HAR _webSocketMessages convention so frames export without remapping.
+const (
+	ResourceTypeWebSocket = "WebSocket"
+	FrameSend             = "send"
+	FrameReceive          = "receive"
+)
+
+// WebSocketFrame is one message sent or received on a WebSocket connection.
+// Seq increases by one per frame on a connection and is never reused, so
+// readers can resume or de-duplicate after frames have been dropped.
+type WebSocketFrame struct {
+	Seq       int64     `json:"seq"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Opcode    int       `json:"opcode"`
+	Data      string    `json:"data"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// IsWebSocket reports whether entry is a WebSocket connection rather than an
+// HTTP request.
+func IsWebSocket(entry NetworkEntry) bool {
+	return entry.ResourceType == ResourceTypeWebSocket
+}
+
+// wsFrameRing is a fixed-capacity ring of the most recent frames of one
+// connection.
+type wsFrameRing struct {
+	frames  []WebSocketFrame
+	head    int
+	size    int
+	nextSeq int64
+}
+
+func newWSFrameRing(limit int) *wsFrameRing {
+	return &wsFrameRing{frames: make([]WebSocketFrame, limit), nextSeq: 1}
+}
+
+// push appends frame, assigning its sequence number, and reports whether the
+// oldest frame was overwritten.
+func (r *wsFrameRing) push(frame WebSocketFrame) (WebSocketFrame, bool) {
+	frame.Seq = r.nextSeq
+	r.nextSeq++
+	if r.size == len(r.frames) {
+		r.frames[r.head] = frame
+		r.head = (r.head + 1) % len(r.frames)
+		return frame, true
+	}
+	r.frames[(r.head+r.size)%len(r.frames)] = frame
+	r.size++
+	return frame, false
+}
+
+func (r *wsFrameRing) list() []WebSocketFrame {
+	out := make([]WebSocketFrame, r.size)
+	for i := 0; i < r.size; i++ {
+		out[i] = r.frames[(r.head+i)%len(r.frames)]
+	}
+	return out
+}
+
+// AddWebSocketFrame records a frame for the connection requestID and hands it
+// to frame subscribers. Frames for connections no longer in the buffer are
+// dropped and false is returned. A zero frame.Time is stamped with now.
+func (nb *NetworkBuffer) AddWebSocketFrame(requestID string, frame WebSocketFrame) bool {
+	if frame.Time.IsZero() {
+		frame.Time = time.Now()
+	}
+	if len(frame.Data) > maxWebSocketFrameDataBytes {
+		frame.Data = sanitize.TruncateUTF8Bytes(frame.Data, maxWebSocketFrameDataBytes)
+		frame.Truncated = true
+	}
+
+	nb.mu.Lock()
+	slot, ok := nb.index[requestID]
+	if !ok {
+		nb.mu.Unlock()
+		return false
+	}
+	ring := nb.wsFrames[requestID]
+	if ring == nil {
+		ring = newWSFrameRing(nb.wsFrameLimit)
+		nb.wsFrames[requestID] = ring
+	}
+	frame, dropped := ring.push(frame)
+	entry := &nb.entries[slot]
+	if frame.Type == FrameSend {
+		entry.FramesSent++
+	} else {
+		entry.FramesReceived++
+	}
+	if dropped {
+		entry.FramesDropped++
+	}
+	nb.mu.Unlock()
+
+	nb.frameSubMu.Lock()
+	for _, ch := range nb.frameSubs[requestID] {
+		select {
+		case ch <- frame:
+		default:
+		}
+	}
+	nb.frameSubMu.Unlock()
+	return true
+}
+
+// WebSocketFrames returns the retained frames of a connection, oldest first.
+func (nb *NetworkBuffer) WebSocketFrames(requestID string) []WebSocketFrame {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+	ring := nb.wsFrames[requestID]
+	if ring == nil {
+		return []WebSocketFrame{}
+	}
+	return ring.list()
+}
+
+// MarkWebSocketClosed finishes the connection's entry and closes its frame
+// subscriber channels so live streams can end.
+func (nb *NetworkBuffer) MarkWebSocketClosed(requestID string) {
+	nb.Update(requestID, func(entry *NetworkEntry) {
+		entry.Finished = true
+		entry.EndTime = time.Now()
+		if !entry.StartTime.IsZero() {
+			entry.Duration = float64(entry.EndTime.Sub(entry.StartTime).Milliseconds())
+		}
+	})
+	nb.closeFrameSubs(requestID)
+}
+
+// SubscribeFrames returns a channel that receives each new frame of the
+// connection requestID. The channel is closed when the connection closes or
+// its entry leaves the buffer.
+func (nb *NetworkBuffer) SubscribeFrames(requestID string) (int, <-chan WebSocketFrame) {
+	nb.frameSubMu.Lock()
+	defer nb.frameSubMu.Unlock()
+	id := nb.nextFrameSubID
+	nb.nextFrameSubID++
+	ch := make(chan WebSocketFrame, 64)
+	if nb.frameSubs[requestID] == nil {
+		nb.frameSubs[requestID] = make(map[int]chan WebSocketFrame)
+	}
+	nb.frameSubs[requestID][id] = ch
+	return id, ch
+}
+
+func (nb *NetworkBuffer) UnsubscribeFrames(requestID string, id int) {
+	nb.frameSubMu.Lock()
+	defer nb.frameSubMu.Unlock()
+	subs := nb.frameSubs[requestID]
+	if ch, ok := subs[id]; ok {
+		close(ch)
+		delete(subs, id)
+		if len(subs) == 0 {
+			delete(nb.frameSubs, requestID)
+		}
+	}
+}
+
+// closeFrameSubs closes the frame subscribers of the given connections, or of
+// every connection when none are named.
+func (nb *NetworkBuffer) closeFrameSubs(requestIDs ...string) {
+	nb.frameSubMu.Lock()
+	defer nb.frameSubMu.Unlock()
+	if len(requestIDs) == 0 {
+		for id := range nb.frameSubs {
+			requestIDs = append(requestIDs, id)
+		}
+	}
+	for _, id := range requestIDs {
+		for _, ch := range nb.frameSubs[id] {
+			close(ch)
+		}
+		delete(nb.frameSubs, id)
+	}
+}
+
+// handleWebSocketEvent records the CDP WebSocket lifecycle on buf. It reports
+// whether ev was a WebSocket event.
+func handleWebSocketEvent(buf *NetworkBuffer, ev interface{}) bool {
+	switch e := ev.(type) {
+	case *network.EventWebSocketCreated:
+		// WebSockets are long-lived, so they are not marked in flight: an open
+		// socket must not hold network-idle waits forever.
+		buf.Add(NetworkEntry{
+			RequestID:    string(e.RequestID),
+			URL:          e.URL,
+			Method:       "GET",
+			ResourceType: ResourceTypeWebSocket,
+			StartTime:    time.Now(),
+		})
+
+	case *network.EventWebSocketWillSendHandshakeRequest:
+		if e.Request == nil {
+			return true
+		}
+		buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+			entry.RequestHeaders = stringHeaders(e.Request.Headers)
+		})
+
+	case *network.EventWebSocketHandshakeResponseReceived:
+		if e.Response == nil {
+			return true
+		}
+		buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+			entry.Status = int(e.Response.Status)
+			entry.StatusText = e.Response.StatusText
+			entry.ResponseHeaders = stringHeaders(e.Response.Headers)
+		})
+
+	case *network.EventWebSocketFrameSent:
+		if e.Response != nil {
+			buf.AddWebSocketFrame(string(e.RequestID), cdpFrame(FrameSend, e.Response))
+		}
+
+	case *network.EventWebSocketFrameReceived:
+		if e.Response != nil {
+			buf.AddWebSocketFrame(string(e.RequestID), cdpFrame(FrameReceive, e.Response))
+		}
+
+	case *network.EventWebSocketFrameError:
+		buf.Update(string(e.RequestID), func(entry *NetworkEntry) {
+			entry.Error = e.ErrorMessage
+		})
+
+	case *network.EventWebSocketClosed:
+		buf.MarkWebSocketClosed(string(e.RequestID))
+
+	default:
+		return false
+	}
+	return true
+}
+
+func cdpFrame(direction string, f *network.WebSocketFrame) WebSocketFrame {
+	return WebSocketFrame{
+		Type:   direction,
+		Opcode: int(f.Opcode),
+		Data:   f.PayloadData,
+	}
+}
+
+func stringHeaders(h network.Headers) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
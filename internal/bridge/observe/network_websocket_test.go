@@ -0,0 +1,159 @@
+package observe
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestHandleWebSocketEventLifecycle(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	events := []interface{}{
+		&network.EventWebSocketCreated{RequestID: "ws1", URL: "wss://example.com/feed"},
+		&network.EventWebSocketHandshakeResponseReceived{RequestID: "ws1", Response: &network.WebSocketResponse{
+			Status: 101, StatusText: "Switching Protocols", Headers: network.Headers{"Upgrade": "websocket"},
+		}},
+		&network.EventWebSocketFrameSent{RequestID: "ws1", Response: &network.WebSocketFrame{Opcode: 1, PayloadData: `{"sub":"BTC"}`}},
+		&network.EventWebSocketFrameReceived{RequestID: "ws1", Response: &network.WebSocketFrame{Opcode: 1, PayloadData: `{"px":1}`}},
+		&network.EventWebSocketFrameReceived{RequestID: "ws1", Response: &network.WebSocketFrame{Opcode: 2, PayloadData: "AAE="}},
+	}
+	for _, ev := range events {
+		if !handleWebSocketEvent(buf, ev) {
+			t.Fatalf("%T not handled", ev)
+		}
+	}
+	if handleWebSocketEvent(buf, &network.EventLoadingFinished{RequestID: "ws1"}) {
+		t.Fatal("HTTP events must fall through to the request handler")
+	}
+
+	entry, ok := buf.Get("ws1")
+	if !ok || !IsWebSocket(entry) || entry.Status != 101 || entry.ResponseHeaders["Upgrade"] != "websocket" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.FramesSent != 1 || entry.FramesReceived != 2 || entry.Finished {
+		t.Fatalf("frame counts = %d/%d, finished=%v", entry.FramesSent, entry.FramesReceived, entry.Finished)
+	}
+	if count, _ := buf.InflightStatus(); count != 0 {
+		t.Fatalf("open WebSocket counted as in flight: %d", count)
+	}
+
+	frames := buf.WebSocketFrames("ws1")
+	if len(frames) != 3 || frames[0].Type != FrameSend || frames[2].Opcode != 2 || frames[2].Seq != 3 {
+		t.Fatalf("frames = %+v", frames)
+	}
+
+	handleWebSocketEvent(buf, &network.EventWebSocketClosed{RequestID: "ws1"})
+	if entry, _ := buf.Get("ws1"); !entry.Finished || entry.EndTime.IsZero() {
+		t.Fatalf("closed entry = %+v", entry)
+	}
+}
+
+func TestWebSocketFramesAreBounded(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "ws1", ResourceType: ResourceTypeWebSocket})
+
+	for i := 0; i < DefaultWebSocketFrameLimit+5; i++ {
+		buf.AddWebSocketFrame("ws1", WebSocketFrame{Type: FrameReceive, Opcode: 1, Data: "x"})
+	}
+	frames := buf.WebSocketFrames("ws1")
+	if len(frames) != DefaultWebSocketFrameLimit {
+		t.Fatalf("kept %d frames, want %d", len(frames), DefaultWebSocketFrameLimit)
+	}
+	if frames[0].Seq != 6 || frames[len(frames)-1].Seq != int64(DefaultWebSocketFrameLimit+5) {
+		t.Fatalf("seq range = %d..%d", frames[0].Seq, frames[len(frames)-1].Seq)
+	}
+	if entry, _ := buf.Get("ws1"); entry.FramesDropped != 5 || entry.FramesReceived != DefaultWebSocketFrameLimit+5 {
+		t.Fatalf("entry = %+v", entry)
+	}
+
+	buf.AddWebSocketFrame("ws1", WebSocketFrame{Type: FrameSend, Data: strings.Repeat("a", maxWebSocketFrameDataBytes+10)})
+	frames = buf.WebSocketFrames("ws1")
+	if last := frames[len(frames)-1]; !last.Truncated || len(last.Data) != maxWebSocketFrameDataBytes {
+		t.Fatalf("oversized frame kept %d bytes, truncated=%v", len(last.Data), last.Truncated)
+	}
+
+	if buf.AddWebSocketFrame("unknown", WebSocketFrame{Type: FrameSend}) {
+		t.Fatal("frame for an unknown connection should be dropped")
+	}
+}
+
+func TestWebSocketFrameSubscribersCloseWithConnection(t *testing.T) {
+	buf := NewNetworkBuffer(1)
+	buf.Add(NetworkEntry{RequestID: "ws1", ResourceType: ResourceTypeWebSocket})
+	_, ch := buf.SubscribeFrames("ws1")
+
+	buf.AddWebSocketFrame("ws1", WebSocketFrame{Type: FrameSend, Data: "hi"})
+	if frame := <-ch; frame.Data != "hi" || frame.Seq != 1 {
+		t.Fatalf("frame = %+v", frame)
+	}
+
+	// Evicting the connection's entry drops its frames and ends the stream.
+	buf.Add(NetworkEntry{RequestID: "r2"})
+	if _, ok := <-ch; ok {
+		t.Fatal("subscriber channel should be closed on eviction")
+	}
+	if frames := buf.WebSocketFrames("ws1"); len(frames) != 0 {
+		t.Fatalf("evicted connection kept %d frames", len(frames))
+	}
+
+	buf.Add(NetworkEntry{RequestID: "ws2", ResourceType: ResourceTypeWebSocket})
+	id, ch2 := buf.SubscribeFrames("ws2")
+	buf.MarkWebSocketClosed("ws2")
+	if _, ok := <-ch2; ok {
+		t.Fatal("subscriber channel should be closed when the socket closes")
+	}
+	buf.UnsubscribeFrames("ws2", id) // already closed: must not panic
+}
+
+func TestWebSocketMessagesInExports(t *testing.T) {
+	buf := NewNetworkBuffer(10)
+	buf.Add(NetworkEntry{RequestID: "ws1", URL: "wss://example.com/feed", Method: "GET", Status: 101, ResourceType: ResourceTypeWebSocket})
+	buf.AddWebSocketFrame("ws1", WebSocketFrame{Type: FrameSend, Opcode: 1, Data: "ping"})
+	buf.AddWebSocketFrame("ws1", WebSocketFrame{Type: FrameReceive, Opcode: 1, Data: "pong"})
+	entry, _ := buf.Get("ws1")
+
+	export := NetworkEntryToExport(entry, "", false)
+	export.WebSocketMessages = WebSocketFramesToExport(buf.WebSocketFrames("ws1"))
+
+	var har bytes.Buffer
+	enc := GetFormat("har")("test", "1.0")
+	if err := enc.Start(&har); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(export); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Messages []ExportWebSocketMessage `json:"_webSocketMessages"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(har.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid HAR: %v", err)
+	}
+	msgs := doc.Log.Entries[0].Messages
+	if len(msgs) != 2 || msgs[0].Type != "send" || msgs[1].Data != "pong" || msgs[0].Time <= 0 {
+		t.Fatalf("_webSocketMessages = %+v", msgs)
+	}
+
+	var nd bytes.Buffer
+	enc = GetFormat("ndjson")("", "")
+	_ = enc.Start(&nd)
+	_ = enc.Encode(export)
+	_ = enc.Encode(NetworkEntryToExport(NetworkEntry{RequestID: "r1", URL: "https://example.com/"}, "", false))
+	lines := strings.Split(strings.TrimSpace(nd.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"_webSocketMessages":[{"type":"send"`) {
+		t.Fatalf("ndjson = %s", nd.String())
+	}
+	if strings.Contains(lines[1], "_webSocketMessages") {
+		t.Fatalf("HTTP entry should omit _webSocketMessages: %s", lines[1])
+	}
+}
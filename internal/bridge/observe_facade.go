@@ -29,6 +29,7 @@ type NetworkEntry = bridgeobserve.NetworkEntry
 type NetworkBuffer = bridgeobserve.NetworkBuffer
 type NetworkFilter = bridgeobserve.NetworkFilter
 type NetworkMonitor = bridgeobserve.NetworkMonitor
+type WebSocketFrame = bridgeobserve.WebSocketFrame
 type MemoryMetrics = bridgeobserve.MemoryMetrics
 
 func frameIDs(tree RawFrameTree) []string {
@@ -131,3 +132,12 @@ func (b *Bridge) GetBrowserMemoryMetrics() (*MemoryMetrics, error) {
 func (b *Bridge) GetAggregatedMemoryMetrics() (*MemoryMetrics, error) {
 	return bridgeobserve.GetAggregatedMemoryMetrics(b.BrowserCtx)
 }
+
+// ExecutorStats reports the tab executor's running and queued actions, or
+// false while no browser is running.
+func (b *Bridge) ExecutorStats() (ExecutorStats, bool) {
+	if b.TabManager == nil || b.Executor() == nil {
+		return ExecutorStats{}, false
+	}
+	return b.Executor().Stats(), true
+}
@@ -0,0 +1,184 @@
+package bridge
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// originSeedDocument is served for every request a helper target makes, so
+// the origin's own pages, scripts and redirects never run in it.
+const originSeedDocument = "<!doctype html><title></title>"
+
+// maxVisitedOrigins bounds the visited-origin set of one browser context;
+// the least recently seen origin is dropped first.
+const maxVisitedOrigins = 200
+
+// visitedOrigins records the http(s) origins documents were loaded from in
+// this browser session, per browser context, the default one under ""
+// (see VisitedOrigins).
+type visitedOrigins struct {
+	mu   sync.Mutex
+	seen map[cdp.BrowserContextID]map[string]time.Time
+}
+
+// WithOriginTarget opens a hidden helper target on origin and runs fn
+// against it. The target is served a blank document by PinchTab instead of
+// the network and bypasses service workers, so only the origin's storage is
+// reachable — nothing of the site itself loads. Local storage, IndexedDB and
+// Cache Storage are shared with every tab of the browser; session storage is
+// not. The target is opened in the browser context of the tab of ctx, so an
+// isolated session's or pooled tab's helper sees that context's storage.
+// The target is closed when fn returns.
+func (b *Bridge) WithOriginTarget(ctx context.Context, origin string, fn func(ctx context.Context) error) error {
+	if b.BrowserCtx == nil {
+		return fmt.Errorf("no browser context available")
+	}
+	execCtx, err := browserExecutorContext(b.BrowserCtx)
+	if err != nil {
+		return err
+	}
+	contextID := b.browserContextOf(ctx)
+	create := func(hidden bool) (target.ID, error) {
+		p := target.CreateTarget("about:blank").WithBackground(true)
+		if hidden {
+			p = p.WithHidden(true)
+		}
+		if contextID != "" {
+			p = p.WithBrowserContextID(contextID)
+		}
+		return p.Do(execCtx)
+	}
+	targetID, err := create(true)
+	if err != nil {
+		// Hidden targets are recent; a background tab still leaves the
+		// user's tab in front.
+		targetID, err = create(false)
+	}
+	if err != nil {
+		return fmt.Errorf("create helper target: %w", err)
+	}
+
+	tCtx, cancel := chromedp.NewContext(b.BrowserCtx, chromedp.WithTargetID(targetID))
+	defer func() {
+		cancel()
+		closeCtx, closeCancel := context.WithTimeout(b.BrowserCtx, 5*time.Second)
+		defer closeCancel()
+		if ex, err := browserExecutorContext(closeCtx); err == nil {
+			if err := target.CloseTarget(targetID).Do(ex); err != nil {
+				slog.Debug("close helper target failed", "targetId", targetID, "err", err)
+			}
+		}
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		var deadlineCancel context.CancelFunc
+		tCtx, deadlineCancel = context.WithDeadline(tCtx, deadline)
+		defer deadlineCancel()
+	}
+
+	body := base64.StdEncoding.EncodeToString([]byte(originSeedDocument))
+	chromedp.ListenTarget(tCtx, func(ev any) {
+		e, ok := ev.(*fetch.EventRequestPaused)
+		if !ok {
+			return
+		}
+		go func() {
+			c := chromedp.FromContext(tCtx)
+			if c == nil || c.Target == nil {
+				return
+			}
+			if err := fetch.FulfillRequest(e.RequestID, 200).
+				WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/html"}}).
+				WithBody(body).
+				Do(cdp.WithExecutor(tCtx, c.Target)); err != nil {
+				slog.Debug("helper target fulfill failed", "url", e.Request.URL, "err", err)
+			}
+		}()
+	})
+
+	if err := chromedp.Run(tCtx,
+		network.Enable(),
+		network.SetBypassServiceWorker(true),
+		fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}),
+		chromedp.Navigate(origin+"/"),
+	); err != nil {
+		return fmt.Errorf("open %s in helper target: %w", origin, err)
+	}
+	return fn(tCtx)
+}
+
+// VisitedOrigins returns the http(s) origins documents were loaded from
+// during this browser session in the browser context of the tab of ctx, by
+// any of its tabs (or frames), sorted.
+func (b *Bridge) VisitedOrigins(ctx context.Context) []string {
+	contextID := b.browserContextOf(ctx)
+	b.visited.mu.Lock()
+	defer b.visited.mu.Unlock()
+	seen := b.visited.seen[contextID]
+	out := make([]string, 0, len(seen))
+	for o := range seen {
+		out = append(out, o)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// trackVisitedOrigins records the origin of every frame navigation of the
+// tab of ctx under the tab's browser context.
+func (b *Bridge) trackVisitedOrigins(ctx context.Context) {
+	chromedp.ListenTarget(ctx, func(ev any) {
+		if e, ok := ev.(*page.EventFrameNavigated); ok && e.Frame != nil {
+			b.recordVisitedOrigin(b.browserContextOf(ctx), e.Frame.URL)
+		}
+	})
+}
+
+func (b *Bridge) recordVisitedOrigin(contextID cdp.BrowserContextID, rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	b.visited.mu.Lock()
+	defer b.visited.mu.Unlock()
+	if b.visited.seen == nil {
+		b.visited.seen = make(map[cdp.BrowserContextID]map[string]time.Time)
+	}
+	seen := b.visited.seen[contextID]
+	if seen == nil {
+		seen = make(map[string]time.Time)
+		b.visited.seen[contextID] = seen
+	}
+	seen[origin] = time.Now()
+	if len(seen) <= maxVisitedOrigins {
+		return
+	}
+	oldest, oldestAt := "", time.Time{}
+	for o, at := range seen {
+		if oldest == "" || at.Before(oldestAt) {
+			oldest, oldestAt = o, at
+		}
+	}
+	delete(seen, oldest)
+}
+
+// forgetVisitedOrigins drops the visited origins of a disposed browser
+// context.
+func (b *Bridge) forgetVisitedOrigins(contextID cdp.BrowserContextID) {
+	b.visited.mu.Lock()
+	defer b.visited.mu.Unlock()
+	delete(b.visited.seen, contextID)
+}
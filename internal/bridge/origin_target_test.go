@@ -0,0 +1,33 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVisitedOriginsPerBrowserContext(t *testing.T) {
+	b := &Bridge{}
+	b.recordVisitedOrigin("", "https://shared.example/page")
+	b.recordVisitedOrigin("ctx-a", "https://a.example/login")
+	b.recordVisitedOrigin("ctx-a", "about:blank")
+
+	// A context without a tab target resolves to the default context.
+	if got := strings.Join(b.VisitedOrigins(context.Background()), ","); got != "https://shared.example" {
+		t.Fatalf("default context origins = %s", got)
+	}
+	b.visited.mu.Lock()
+	got := len(b.visited.seen["ctx-a"])
+	b.visited.mu.Unlock()
+	if got != 1 {
+		t.Fatalf("ctx-a origins = %d, want 1", got)
+	}
+
+	b.forgetVisitedOrigins("ctx-a")
+	b.visited.mu.Lock()
+	_, ok := b.visited.seen["ctx-a"]
+	b.visited.mu.Unlock()
+	if ok {
+		t.Fatal("disposed context kept its visited origins")
+	}
+}
@@ -0,0 +1,318 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// Permission state is owned by the browser context, not the tab: an override
+// for an origin applies to every tab showing that origin. The bridge keeps
+// the overrides it set so a reset of one origin (Browser.resetPermissions
+// only clears the whole context) can restore the rest, and so a relaunched
+// browser gets them back along with instanceDefaults.permissions. Tabs in a
+// session or pool proxy context keep their overrides to that context.
+
+// PermissionRequest is a permission prompt a page asked for, as recorded by
+// the in-page watcher (assets.PermissionWatchJS). State is "pending" until
+// the page's call settles.
+type PermissionRequest struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	At    string `json:"at"`
+}
+
+// permissionDescriptor maps a config.PermissionNames entry to its CDP form.
+func permissionDescriptor(name string) *browser.PermissionDescriptor {
+	switch name {
+	case "midi-sysex":
+		return &browser.PermissionDescriptor{Name: "midi", Sysex: true}
+	case "push":
+		return &browser.PermissionDescriptor{Name: "push", UserVisibleOnly: true}
+	}
+	return &browser.PermissionDescriptor{Name: name}
+}
+
+// NormalizePermissionOrigin reduces a URL to the scheme://host[:port] origin
+// permissions are keyed by.
+func NormalizePermissionOrigin(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid origin %q (want scheme://host[:port])", raw)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("invalid origin %q: permissions apply to http and https origins only", raw)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// SetPermissions applies perms (name → granted|denied|prompt) to origin in
+// the browser context of ctx and remembers them for later resets.
+func (b *Bridge) SetPermissions(ctx context.Context, origin string, perms map[string]string) error {
+	if err := config.ValidatePermissions(perms); err != nil {
+		return err
+	}
+	contextID := b.browserContextOf(ctx)
+	if contextID == "" {
+		if err := b.ensurePermissionDefaults(ctx); err != nil {
+			return err
+		}
+	}
+	if err := setPermissions(ctx, contextID, origin, perms); err != nil {
+		return err
+	}
+	b.permMu.Lock()
+	overrides := b.permOverridesFor(contextID, true)
+	if overrides[origin] == nil {
+		overrides[origin] = make(map[string]string)
+	}
+	maps.Copy(overrides[origin], perms)
+	b.permMu.Unlock()
+	return nil
+}
+
+// permOverridesFor returns the override map of a browser context ("" is the
+// default context), creating it when create is set. Caller holds permMu.
+func (b *Bridge) permOverridesFor(contextID cdp.BrowserContextID, create bool) map[string]map[string]string {
+	if contextID == "" {
+		if b.permOverrides == nil && create {
+			b.permOverrides = make(map[string]map[string]string)
+		}
+		return b.permOverrides
+	}
+	if b.ctxPermOverrides[contextID] == nil && create {
+		if b.ctxPermOverrides == nil {
+			b.ctxPermOverrides = make(map[cdp.BrowserContextID]map[string]map[string]string)
+		}
+		b.ctxPermOverrides[contextID] = make(map[string]map[string]string)
+	}
+	return b.ctxPermOverrides[contextID]
+}
+
+// dropContextPermissions forgets the overrides of a disposed context.
+func (b *Bridge) dropContextPermissions(contextID cdp.BrowserContextID) {
+	b.permMu.Lock()
+	delete(b.ctxPermOverrides, contextID)
+	b.permMu.Unlock()
+}
+
+// ResetPermissions drops the overrides for origin (only the named ones when
+// names is non-empty) and restores the browser's permission state from
+// instanceDefaults.permissions plus the overrides that remain.
+func (b *Bridge) ResetPermissions(ctx context.Context, origin string, names []string) error {
+	contextID := b.browserContextOf(ctx)
+	b.permMu.Lock()
+	overrides := b.permOverridesFor(contextID, false)
+	if len(names) == 0 {
+		delete(overrides, origin)
+	} else {
+		for _, name := range names {
+			delete(overrides[origin], name)
+		}
+		if len(overrides[origin]) == 0 {
+			delete(overrides, origin)
+		}
+	}
+	b.permMu.Unlock()
+	if contextID != "" {
+		return b.applyContextPermissions(ctx, contextID)
+	}
+	return b.applyPermissions(ctx, true)
+}
+
+// PermissionOverrides returns the overrides set for origin in the browser
+// context of the tab ctx is attached to.
+func (b *Bridge) PermissionOverrides(ctx context.Context, origin string) map[string]string {
+	contextID := b.browserContextOf(ctx)
+	b.permMu.Lock()
+	defer b.permMu.Unlock()
+	return maps.Clone(b.permOverridesFor(contextID, false)[origin])
+}
+
+// QueryPermissions reports the page's view of each named permission via
+// navigator.permissions.query. Names the browser cannot query are omitted.
+func (b *Bridge) QueryPermissions(ctx context.Context, names []string) (map[string]string, error) {
+	descs := make(map[string]*browser.PermissionDescriptor, len(names))
+	for _, name := range names {
+		descs[name] = permissionDescriptor(name)
+	}
+	arg, err := json.Marshal(descs)
+	if err != nil {
+		return nil, err
+	}
+	js := fmt.Sprintf(`(async (descs) => {
+  const out = {};
+  if (!navigator.permissions || !navigator.permissions.query) return out;
+  for (const [name, d] of Object.entries(descs)) {
+    const q = { name: d.name };
+    if (d.sysex) q.sysex = true;
+    if (d.userVisibleOnly) q.userVisibleOnly = true;
+    try { out[name] = (await navigator.permissions.query(q)).state; } catch (e) {}
+  }
+  return out;
+})(%s)`, arg)
+	out := map[string]string{}
+	err = chromedp.Run(ctx, chromedp.Evaluate(js, &out, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true)
+	}))
+	return out, err
+}
+
+// PermissionRequests returns the permission prompts the tab's current
+// document has raised, oldest first.
+func (b *Bridge) PermissionRequests(tabID string) ([]PermissionRequest, error) {
+	tm, err := b.tabManager()
+	if err != nil {
+		return nil, err
+	}
+	ctx, _, err := tm.TabContext(tabID)
+	if err != nil {
+		return nil, err
+	}
+	var out []PermissionRequest
+	js := `(() => { const s = window.__pinchtabPermissionRequests; return s ? s.requests : []; })()`
+	if err := chromedp.Run(ctx, chromedp.Evaluate(js, &out)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// installPermissionWatch adds the permission request watcher to a new tab.
+// Quiet in full stealth, like the other page observers.
+func (b *Bridge) installPermissionWatch(ctx context.Context) {
+	if b.quietStealthObservers() {
+		return
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(assets.PermissionWatchJS).Do(ctx)
+		return err
+	})); err != nil {
+		slog.Warn("permission watch injection failed", "err", err)
+	}
+}
+
+// ensurePermissionDefaults applies instanceDefaults.permissions and the
+// remembered overrides the first time it sees a browser, so they survive a
+// relaunch.
+func (b *Bridge) ensurePermissionDefaults(ctx context.Context) error {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Browser == nil {
+		return fmt.Errorf("no browser executor available")
+	}
+	b.permMu.Lock()
+	applied := b.permBrowser == c.Browser
+	b.permMu.Unlock()
+	if applied {
+		return nil
+	}
+	return b.applyPermissions(ctx, false)
+}
+
+// applyPermissions pushes the instance defaults (every origin) and the
+// remembered per-origin overrides to the browser, optionally clearing the
+// context's permission state first. Per-origin overrides win over the
+// all-origin defaults regardless of order.
+func (b *Bridge) applyPermissions(ctx context.Context, reset bool) error {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Browser == nil {
+		return fmt.Errorf("no browser executor available")
+	}
+	var defaults map[string]string
+	if b.Config != nil {
+		defaults = b.Config.Permissions
+	}
+
+	b.permMu.Lock()
+	defer b.permMu.Unlock()
+	if reset {
+		execCtx, err := browserExecutorContext(ctx)
+		if err != nil {
+			return err
+		}
+		if err := browser.ResetPermissions().Do(execCtx); err != nil {
+			return fmt.Errorf("reset permissions: %w", err)
+		}
+	}
+	if err := setPermissions(ctx, "", "", defaults); err != nil {
+		return fmt.Errorf("instance default permissions: %w", err)
+	}
+	for origin, perms := range b.permOverrides {
+		if err := setPermissions(ctx, "", origin, perms); err != nil {
+			return err
+		}
+	}
+	b.permBrowser = c.Browser
+	return nil
+}
+
+// applyContextPermissions clears a session or pool proxy context's
+// permission state and reapplies the instance defaults and its overrides.
+func (b *Bridge) applyContextPermissions(ctx context.Context, contextID cdp.BrowserContextID) error {
+	var defaults map[string]string
+	if b.Config != nil {
+		defaults = b.Config.Permissions
+	}
+	b.permMu.Lock()
+	defer b.permMu.Unlock()
+	execCtx, err := browserExecutorContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := browser.ResetPermissions().WithBrowserContextID(contextID).Do(execCtx); err != nil {
+		return fmt.Errorf("reset permissions: %w", err)
+	}
+	if err := setPermissions(ctx, contextID, "", defaults); err != nil {
+		return fmt.Errorf("instance default permissions: %w", err)
+	}
+	for origin, perms := range b.ctxPermOverrides[contextID] {
+		if err := setPermissions(ctx, contextID, origin, perms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPermissions sends one Browser.setPermission per entry; an empty origin
+// applies to all origins, an empty contextID to the default context.
+func setPermissions(ctx context.Context, contextID cdp.BrowserContextID, origin string, perms map[string]string) error {
+	if len(perms) == 0 {
+		return nil
+	}
+	execCtx, err := browserExecutorContext(ctx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(perms))
+	for name := range perms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := browser.SetPermission(permissionDescriptor(name), browser.PermissionSetting(perms[name]))
+		if origin != "" {
+			p = p.WithOrigin(origin)
+		}
+		if contextID != "" {
+			p = p.WithBrowserContextID(contextID)
+		}
+		if err := p.Do(execCtx); err != nil {
+			return fmt.Errorf("set %s permission: %w", name, err)
+		}
+	}
+	return nil
+}
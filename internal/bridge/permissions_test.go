@@ -0,0 +1,33 @@
+package bridge
+
+import "testing"
+
+func TestNormalizePermissionOrigin(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://Shop.Example.com/cart?x=1": "https://shop.example.com",
+		"http://localhost:8080/":            "http://localhost:8080",
+		" https://a.test ":                  "https://a.test",
+	} {
+		got, err := NormalizePermissionOrigin(in)
+		if err != nil || got != want {
+			t.Errorf("NormalizePermissionOrigin(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "about:blank", "file:///tmp/x.html", "shop.example.com"} {
+		if _, err := NormalizePermissionOrigin(in); err == nil {
+			t.Errorf("NormalizePermissionOrigin(%q) should fail", in)
+		}
+	}
+}
+
+func TestPermissionDescriptor(t *testing.T) {
+	if d := permissionDescriptor("midi-sysex"); d.Name != "midi" || !d.Sysex {
+		t.Fatalf("midi-sysex = %+v", d)
+	}
+	if d := permissionDescriptor("push"); !d.UserVisibleOnly {
+		t.Fatalf("push = %+v", d)
+	}
+	if d := permissionDescriptor("camera"); d.Name != "camera" || d.Sysex {
+		t.Fatalf("camera = %+v", d)
+	}
+}
@@ -0,0 +1,673 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+	bridgeruntime "github.com/pinchtab/pinchtab/internal/bridge/runtime"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
+)
+
+// Tabs can run behind a proxy drawn from a configured pool
+// (browser.proxyPools) instead of the browser-wide proxy. Chrome takes a
+// proxy per browser context, so such tabs run in a context created with
+// proxyServer. Tabs with the same pool, sticky key and proxy share one
+// context, and so their cookies and storage; it is disposed with its last
+// tab. Proxy auth, geo alignment and failure tracking follow the tab's proxy.
+// When the proxy fails, the context's tabs are replaced by tabs on a healthy
+// proxy of the pool, with the context's cookies carried over and the old
+// tab IDs resolving to the new ones.
+
+// TabProxyOptions selects the pool proxy for CreateTabWithProxy.
+type TabProxyOptions struct {
+	Pool string
+	// Key pins the choice under the sticky strategy, e.g. a session ID.
+	Key string
+}
+
+// rotatedTabAliasTTL is how long the ID of a tab replaced by proxy
+// rotation keeps resolving to its replacement.
+const rotatedTabAliasTTL = 30 * time.Minute
+
+// TabProxy is the pool proxy a tab runs behind.
+type TabProxy struct {
+	TabID            string `json:"tabId"`
+	Pool             string `json:"pool"`
+	Server           string `json:"server"`
+	Key              string `json:"key,omitempty"`
+	BrowserContextID string `json:"browserContextId"`
+	// RotatedFrom is the tab this one replaced when its proxy failed.
+	RotatedFrom string `json:"rotatedFrom,omitempty"`
+	// Failed is set once the tab saw the proxy fail. The proxy is then out
+	// of rotation, and the tab is replaced unless no healthy proxy is left.
+	Failed    bool   `json:"failed,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+type tabProxy struct {
+	info  TabProxy
+	proxy config.BrowserProxyConfig
+	pc    *proxyContext
+}
+
+// proxyContext is a browser context behind one pool proxy, shared by the
+// tabs of one sticky key. pending counts tabs being created in it, so it is
+// not disposed under them. failed is set once its proxy failure is reported.
+type proxyContext struct {
+	key     string
+	id      cdp.BrowserContextID
+	tabs    map[string]struct{}
+	pending int
+	failed  bool
+}
+
+// proxyContextKey identifies the context tabs of a pool, sticky key and
+// proxy share; tabs without a key never share.
+func proxyContextKey(pool, key string, px config.BrowserProxyConfig) string {
+	if key == "" {
+		return ""
+	}
+	return pool + "\x00" + key + "\x00" + px.Server + "\x00" + px.Username
+}
+
+// reserveProxyContext returns the open context for pool, key and px and
+// counts a pending tab in it, or nil when a new context is needed.
+func (b *Bridge) reserveProxyContext(pool, key string, px config.BrowserProxyConfig) *proxyContext {
+	ck := proxyContextKey(pool, key, px)
+	if ck == "" {
+		return nil
+	}
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	pc := b.proxyContexts[ck]
+	if pc != nil {
+		pc.pending++
+	}
+	return pc
+}
+
+// addProxyContext registers a new context with one pending tab. When another
+// request registered one for the same key meanwhile, that one is returned
+// and the caller disposes its own.
+func (b *Bridge) addProxyContext(pool, key string, px config.BrowserProxyConfig, id cdp.BrowserContextID) (*proxyContext, bool) {
+	pc := &proxyContext{key: proxyContextKey(pool, key, px), id: id, tabs: map[string]struct{}{}, pending: 1}
+	if pc.key == "" {
+		return pc, true
+	}
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	if prev := b.proxyContexts[pc.key]; prev != nil {
+		prev.pending++
+		return prev, false
+	}
+	if b.proxyContexts == nil {
+		b.proxyContexts = map[string]*proxyContext{}
+	}
+	b.proxyContexts[pc.key] = pc
+	return pc, true
+}
+
+// settleProxyTab ends a pending tab of pc: tabID joins the context, or, when
+// empty, creation failed. It returns the context to dispose when it is left
+// without tabs.
+func (b *Bridge) settleProxyTab(pc *proxyContext, tabID string) (cdp.BrowserContextID, bool) {
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	pc.pending--
+	if tabID != "" {
+		pc.tabs[tabID] = struct{}{}
+	}
+	return b.releaseProxyContextLocked(pc)
+}
+
+// releaseProxyContextLocked forgets pc once it has neither tabs nor pending
+// ones and reports that it should be disposed.
+func (b *Bridge) releaseProxyContextLocked(pc *proxyContext) (cdp.BrowserContextID, bool) {
+	if len(pc.tabs) > 0 || pc.pending > 0 {
+		return "", false
+	}
+	if b.proxyContexts[pc.key] == pc {
+		delete(b.proxyContexts, pc.key)
+	}
+	return pc.id, true
+}
+
+// proxyGeoLookupTimeout bounds the geo lookup for a new proxied tab.
+const proxyGeoLookupTimeout = 5 * time.Second
+
+// proxyFailureErrors are the Chrome net errors that blame the proxy rather
+// than the site.
+var proxyFailureErrors = []string{
+	"net::ERR_PROXY_CONNECTION_FAILED",
+	"net::ERR_TUNNEL_CONNECTION_FAILED",
+	"net::ERR_PROXY_AUTH_UNSUPPORTED",
+	"net::ERR_PROXY_AUTH_REQUESTED",
+	"net::ERR_PROXY_CERTIFICATE_INVALID",
+	"net::ERR_MANDATORY_PROXY_CONFIGURATION_FAILED",
+	"net::ERR_SOCKS_CONNECTION_FAILED",
+	"net::ERR_SOCKS_CONNECTION_HOST_UNREACHABLE",
+}
+
+// proxyAuthWatchLimit bounds the challenged requests a tab remembers.
+const proxyAuthWatchLimit = 1024
+
+// proxyAuthWatch spots rejected proxy credentials. Proxy auth answers every
+// proxy challenge with the configured credentials; when Chrome challenges the
+// same request again, the proxy refused them.
+type proxyAuthWatch struct {
+	mu   sync.Mutex
+	seen map[fetch.RequestID]struct{}
+}
+
+// rejected records a Fetch authRequired event and reports whether it is a
+// repeated proxy challenge. Server (WWW-Authenticate) challenges never count.
+func (w *proxyAuthWatch) rejected(e *fetch.EventAuthRequired) bool {
+	if e == nil || e.AuthChallenge == nil || e.AuthChallenge.Source != fetch.AuthChallengeSourceProxy {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.seen[e.RequestID]; ok {
+		return true
+	}
+	if w.seen == nil || len(w.seen) >= proxyAuthWatchLimit {
+		w.seen = map[fetch.RequestID]struct{}{}
+	}
+	w.seen[e.RequestID] = struct{}{}
+	return false
+}
+
+// isProxyFailure reports whether a Chrome network error text blames the proxy.
+func isProxyFailure(errText string) bool {
+	for _, e := range proxyFailureErrors {
+		if strings.Contains(errText, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyPoolManager returns the pool manager, built from the config on first
+// use.
+func (b *Bridge) proxyPoolManager() *proxypool.Manager {
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	if b.proxyPools == nil {
+		var pools config.ProxyPoolsConfig
+		if b.Config != nil {
+			pools = b.Config.ProxyPools
+		}
+		b.proxyPools = proxypool.NewManager(pools)
+	}
+	return b.proxyPools
+}
+
+// ProxyPools reports the configured pools and the health of their proxies.
+func (b *Bridge) ProxyPools() []proxypool.PoolStatus {
+	return b.proxyPoolManager().Status()
+}
+
+// TabProxy returns the pool proxy of a tab created by CreateTabWithProxy.
+func (b *Bridge) TabProxy(tabID string) (TabProxy, bool) {
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	if tp, ok := b.tabProxies[tabID]; ok {
+		return tp.info, true
+	}
+	return TabProxy{}, false
+}
+
+// TabProxies lists the pool proxies of open tabs, sorted by tab ID.
+func (b *Bridge) TabProxies() []TabProxy {
+	b.tabProxyMu.Lock()
+	out := make([]TabProxy, 0, len(b.tabProxies))
+	for _, tp := range b.tabProxies {
+		out = append(out, tp.info)
+	}
+	b.tabProxyMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].TabID < out[j].TabID })
+	return out
+}
+
+// CreateTabWithProxy opens a tab behind a proxy from opts.Pool, in the
+// browser context the key's earlier tabs on that proxy use, else a new one.
+// With no pool it is CreateTab. It never falls back to a direct connection:
+// no usable proxy is an error.
+func (b *Bridge) CreateTabWithProxy(url string, opts TabProxyOptions) (string, context.Context, context.CancelFunc, error) {
+	if opts.Pool == "" {
+		return b.CreateTab(url)
+	}
+	tm, err := b.tabManager()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	px, err := b.proxyPoolManager().Acquire(opts.Pool, opts.Key)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	pc, err := b.proxyContextFor(tm, opts.Pool, opts.Key, px)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	tabID, ctx, tabCancel, err := b.createProxyTab(tm, url, opts, px, pc, "")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	slog.Info("proxied tab created", "tabId", tabID, "pool", opts.Pool, "proxy", px.Redacted().Server)
+	return tabID, ctx, tabCancel, nil
+}
+
+// proxyContextFor returns the context for a new tab of pool and key on px,
+// shared or new, with the tab counted as pending in it.
+func (b *Bridge) proxyContextFor(tm *TabManager, pool, key string, px config.BrowserProxyConfig) (*proxyContext, error) {
+	if pc := b.reserveProxyContext(pool, key, px); pc != nil {
+		return pc, nil
+	}
+	contextID, err := b.createProxyContext(tm, pool, px)
+	if err != nil {
+		return nil, err
+	}
+	pc, created := b.addProxyContext(pool, key, px, contextID)
+	if !created {
+		b.disposeBrowserContext(contextID)
+	}
+	return pc, nil
+}
+
+// createProxyTab opens a tab in pc, a context reserved by proxyContextFor,
+// and registers its proxy. A failure releases the reservation and, when it
+// blames the proxy, marks px bad.
+func (b *Bridge) createProxyTab(tm *TabManager, url string, opts TabProxyOptions, px config.BrowserProxyConfig, pc *proxyContext, rotatedFrom string) (string, context.Context, context.CancelFunc, error) {
+	entry := &tabProxy{
+		info:  TabProxy{Pool: opts.Pool, Server: px.Server, Key: opts.Key, BrowserContextID: string(pc.id), RotatedFrom: rotatedFrom},
+		proxy: px,
+		pc:    pc,
+	}
+	createdID := ""
+	tabID, ctx, tabCancel, err := tm.createTab(url, tabCreateOptions{
+		browserContextID: pc.id,
+		onTabID: func(tabID string) {
+			createdID = tabID
+			entry.info.TabID = tabID
+			b.tabProxyMu.Lock()
+			if b.tabProxies == nil {
+				b.tabProxies = map[string]*tabProxy{}
+			}
+			b.tabProxies[tabID] = entry
+			b.tabProxyMu.Unlock()
+		},
+	})
+	if err != nil {
+		if createdID != "" {
+			b.tabProxyMu.Lock()
+			delete(b.tabProxies, createdID)
+			b.tabProxyMu.Unlock()
+		}
+		if id, dispose := b.settleProxyTab(pc, ""); dispose {
+			b.dropContextPermissions(id)
+			b.disposeBrowserContext(id)
+		}
+		if isProxyFailure(err.Error()) {
+			b.proxyPoolManager().MarkBad(opts.Pool, px, err.Error())
+		}
+		return "", nil, nil, err
+	}
+	b.settleProxyTab(pc, tabID)
+	return tabID, ctx, tabCancel, nil
+}
+
+// createProxyContext creates a browser context behind px with the default
+// permissions.
+func (b *Bridge) createProxyContext(tm *TabManager, pool string, px config.BrowserProxyConfig) (cdp.BrowserContextID, error) {
+	server, bypass, err := config.BrowserProxyServer(px)
+	if err != nil {
+		return "", fmt.Errorf("proxy pool %q: %w", pool, err)
+	}
+	execCtx, err := browserExecutorContext(tm.browserCtx)
+	if err != nil {
+		return "", err
+	}
+	createCtx, cancel := context.WithTimeout(execCtx, tabCreateTimeout)
+	defer cancel()
+	create := target.CreateBrowserContext().WithProxyServer(server)
+	if bypass != "" {
+		create = create.WithProxyBypassList(bypass)
+	}
+	contextID, err := create.Do(createCtx)
+	if err != nil {
+		return "", fmt.Errorf("create browser context: %w", err)
+	}
+	if b.Config != nil {
+		if perr := setPermissions(createCtx, contextID, "", b.Config.Permissions); perr != nil {
+			slog.Warn("proxy context default permissions failed", "pool", pool, "err", perr)
+		}
+	}
+	return contextID, nil
+}
+
+// tabProxyConfig returns the proxy tabSetup should answer auth challenges
+// for: the tab's pool proxy, or the browser-wide one.
+func (b *Bridge) tabProxyConfig(tabID string) (config.BrowserProxyConfig, bool) {
+	b.tabProxyMu.Lock()
+	tp, ok := b.tabProxies[tabID]
+	b.tabProxyMu.Unlock()
+	if ok {
+		return tp.proxy, true
+	}
+	if b.Config == nil {
+		return config.BrowserProxyConfig{}, false
+	}
+	return b.Config.Proxy, false
+}
+
+// proxyAuthConfigured reports whether any proxy, browser-wide or pooled,
+// carries credentials, i.e. whether proxy auth may own a tab's Fetch domain.
+func (b *Bridge) proxyAuthConfigured() bool {
+	if b.Config == nil {
+		return false
+	}
+	if bridgeruntime.ProxyAuthEnabled(b.Config.Proxy) {
+		return true
+	}
+	for _, pool := range b.Config.ProxyPools {
+		for _, px := range pool.Proxies {
+			if bridgeruntime.ProxyAuthEnabled(px) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setupTabProxy aligns a pool-proxied tab with its proxy's geo data and
+// watches for proxy failures. Called from tabSetup.
+func (b *Bridge) setupTabProxy(ctx context.Context, tabID string) {
+	b.tabProxyMu.Lock()
+	tp, ok := b.tabProxies[tabID]
+	b.tabProxyMu.Unlock()
+	if !ok {
+		return
+	}
+	b.alignTabGeo(ctx, tabID, tp.proxy)
+
+	// A 407 response alone does not count: it is the normal first leg of
+	// proxy auth. Fetch authRequired events only arrive when proxy auth
+	// enabled the Fetch domain for this tab in tabSetup.
+	var auth proxyAuthWatch
+	chromedp.ListenTarget(ctx, func(ev any) {
+		switch e := ev.(type) {
+		case *network.EventLoadingFailed:
+			if isProxyFailure(e.ErrorText) {
+				go b.reportTabProxyFailure(tabID, e.ErrorText)
+			}
+		case *fetch.EventAuthRequired:
+			if auth.rejected(e) {
+				go b.reportTabProxyFailure(tabID, "proxy rejected the credentials")
+			}
+		}
+	})
+	if err := chromedp.Run(ctx, network.Enable()); err != nil {
+		slog.Warn("proxy failure watch: network enable failed", "tab", tabID, "err", err)
+	}
+}
+
+// alignTabGeo applies the timezone and locale the geo provider reports for
+// the tab's proxy.
+func (b *Bridge) alignTabGeo(ctx context.Context, tabID string, px config.BrowserProxyConfig) {
+	lookupCtx, cancel := context.WithTimeout(ctx, proxyGeoLookupTimeout)
+	defer cancel()
+	ip := ""
+	if _, host, _, err := config.ParseProxyServer(px.Server); err == nil && net.ParseIP(host) != nil {
+		ip = host
+	}
+	info, err := bridgeruntime.GeoProviderForProxy(px).Lookup(lookupCtx, ip)
+	if err != nil {
+		slog.Warn("proxy geo lookup failed", "tab", tabID, "err", err)
+		return
+	}
+	if info.IsZero() {
+		return
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if info.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(info.Timezone).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if info.Locale != "" {
+			return emulation.SetLocaleOverride().WithLocale(info.Locale).Do(ctx)
+		}
+		return nil
+	})); err != nil {
+		slog.Warn("proxy geo alignment failed", "tab", tabID, "err", err)
+	}
+}
+
+// reportTabProxyFailure marks the tab's proxy bad in its pool, once per
+// browser context, and moves the context's tabs to a healthy proxy.
+func (b *Bridge) reportTabProxyFailure(tabID, reason string) {
+	tp, tabs, ok := b.failTabProxy(tabID, reason)
+	if !ok {
+		return
+	}
+	b.proxyPoolManager().MarkBad(tp.info.Pool, tp.proxy, reason)
+	slog.Warn("proxy marked bad", "tab", tabID, "pool", tp.info.Pool, "proxy", tp.proxy.Redacted().Server, "reason", reason)
+	if tp.pc != nil {
+		b.rotateProxyTabs(tp, tabs)
+	}
+}
+
+// failTabProxy marks tabID and the other tabs of its proxy context failed
+// and stops new tabs from joining the context. It returns the tab's proxy
+// and the failed tabs, or false when the failure was already reported.
+func (b *Bridge) failTabProxy(tabID, reason string) (*tabProxy, []string, bool) {
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	tp, ok := b.tabProxies[tabID]
+	if !ok || tp.info.Failed || (tp.pc != nil && tp.pc.failed) {
+		return nil, nil, false
+	}
+	tabs := []string{tabID}
+	if pc := tp.pc; pc != nil {
+		pc.failed = true
+		if b.proxyContexts[pc.key] == pc {
+			delete(b.proxyContexts, pc.key)
+		}
+		tabs = tabs[:0]
+		for id := range pc.tabs {
+			tabs = append(tabs, id)
+		}
+		sort.Strings(tabs)
+	}
+	for _, id := range tabs {
+		if t, ok := b.tabProxies[id]; ok {
+			t.info.Failed = true
+			t.info.LastError = reason
+		}
+	}
+	return tp, tabs, true
+}
+
+// rotateProxyTabs replaces the tabs of a failed proxy context with tabs at
+// the same URLs in a context on a healthy proxy of the pool, copying the
+// failed context's cookies first. With no healthy proxy left the tabs stay
+// on the failed one.
+func (b *Bridge) rotateProxyTabs(failed *tabProxy, tabs []string) {
+	tm, err := b.tabManager()
+	if err != nil {
+		return
+	}
+	pool, key := failed.info.Pool, failed.info.Key
+	px, err := b.proxyPoolManager().Acquire(pool, key)
+	if err != nil {
+		slog.Warn("proxy rotation skipped", "pool", pool, "tabs", len(tabs), "err", err)
+		return
+	}
+	cookies, err := b.contextCookies(tm, failed.pc.id)
+	if err != nil {
+		slog.Warn("proxy rotation: cookies not carried over", "pool", pool, "err", err)
+	}
+	seeded := map[cdp.BrowserContextID]bool{}
+	for _, tabID := range tabs {
+		newID, err := b.rotateProxyTab(tm, tabID, TabProxyOptions{Pool: pool, Key: key}, px, cookies, seeded)
+		if err != nil {
+			slog.Warn("proxy rotation failed", "tab", tabID, "pool", pool, "err", err)
+			continue
+		}
+		slog.Info("proxied tab rotated", "tab", tabID, "newTab", newID, "pool", pool, "proxy", px.Redacted().Server)
+	}
+}
+
+// rotateProxyTab replaces one tab, keeping the current tab unless it was
+// the replaced one, and aliases the old tab ID to the new one.
+func (b *Bridge) rotateProxyTab(tm *TabManager, tabID string, opts TabProxyOptions, px config.BrowserProxyConfig, cookies []*network.CookieParam, seeded map[cdp.BrowserContextID]bool) (string, error) {
+	tabCtx, _, err := tm.TabContext(tabID)
+	if err != nil {
+		return "", err
+	}
+	urlCtx, cancel := context.WithTimeout(tabCtx, 5*time.Second)
+	url, err := b.CurrentURL(urlCtx)
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("read tab URL: %w", err)
+	}
+
+	pc, err := b.proxyContextFor(tm, opts.Pool, opts.Key, px)
+	if err != nil {
+		return "", err
+	}
+	if len(cookies) > 0 && !seeded[pc.id] {
+		seeded[pc.id] = true
+		if err := b.setContextCookies(tm, pc.id, cookies); err != nil {
+			slog.Warn("proxy rotation: cookies not carried over", "pool", opts.Pool, "err", err)
+		}
+	}
+
+	tm.mu.RLock()
+	current := tm.currentTab
+	tm.mu.RUnlock()
+	newID, _, _, err := b.createProxyTab(tm, url, opts, px, pc, tabID)
+	if err != nil {
+		return "", err
+	}
+	if current != tabID {
+		tm.mu.Lock()
+		if tm.currentTab == newID {
+			tm.currentTab = current
+		}
+		tm.mu.Unlock()
+	}
+	if err := tm.CloseTab(tabID); err != nil {
+		slog.Warn("proxy rotation: close replaced tab", "tab", tabID, "err", err)
+	}
+	tm.AliasTab(tabID, newID, rotatedTabAliasTTL)
+	return newID, nil
+}
+
+// contextCookies reads the cookie jar of a browser context.
+func (b *Bridge) contextCookies(tm *TabManager, id cdp.BrowserContextID) ([]*network.CookieParam, error) {
+	execCtx, err := browserExecutorContext(tm.browserCtx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(execCtx, tabCreateTimeout)
+	defer cancel()
+	cookies, err := storage.GetCookies().WithBrowserContextID(id).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cookieParams(cookies), nil
+}
+
+// setContextCookies writes cookies into the jar of a browser context.
+func (b *Bridge) setContextCookies(tm *TabManager, id cdp.BrowserContextID, cookies []*network.CookieParam) error {
+	execCtx, err := browserExecutorContext(tm.browserCtx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(execCtx, tabCreateTimeout)
+	defer cancel()
+	return storage.SetCookies(cookies).WithBrowserContextID(id).Do(ctx)
+}
+
+// cookieParams turns read cookies into cookies to set, keeping session
+// cookies without an expiry.
+func cookieParams(cookies []*network.Cookie) []*network.CookieParam {
+	out := make([]*network.CookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		p := &network.CookieParam{
+			Name:         c.Name,
+			Value:        c.Value,
+			Domain:       c.Domain,
+			Path:         c.Path,
+			Secure:       c.Secure,
+			HTTPOnly:     c.HTTPOnly,
+			SameSite:     c.SameSite,
+			Priority:     c.Priority,
+			SourceScheme: c.SourceScheme,
+			SourcePort:   c.SourcePort,
+			PartitionKey: c.PartitionKey,
+		}
+		if !c.Session && c.Expires > 0 {
+			sec, frac := math.Modf(c.Expires)
+			expires := cdp.TimeSinceEpoch(time.Unix(int64(sec), int64(frac*1e9)))
+			p.Expires = &expires
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// dropTabProxy forgets a closed proxied tab and disposes its browser
+// context when it was the context's last tab.
+func (b *Bridge) dropTabProxy(tabID string) {
+	b.tabProxyMu.Lock()
+	tp, ok := b.tabProxies[tabID]
+	delete(b.tabProxies, tabID)
+	var id cdp.BrowserContextID
+	dispose := false
+	if ok && tp.pc != nil {
+		delete(tp.pc.tabs, tabID)
+		id, dispose = b.releaseProxyContextLocked(tp.pc)
+	}
+	b.tabProxyMu.Unlock()
+	if dispose {
+		b.dropContextPermissions(id)
+		b.disposeBrowserContext(id)
+	}
+}
+
+func (b *Bridge) disposeBrowserContext(id cdp.BrowserContextID) {
+	if id == "" {
+		return
+	}
+	b.forgetVisitedOrigins(id)
+	b.dropContextWebAuthn(id)
+	if b.BrowserCtx == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.BrowserCtx, 5*time.Second)
+	defer cancel()
+	execCtx, err := browserExecutorContext(ctx)
+	if err != nil {
+		return
+	}
+	if err := target.DisposeBrowserContext(id).Do(execCtx); err != nil {
+		slog.Debug("dispose browser context failed", "browserContextId", id, "err", err)
+	}
+}
@@ -0,0 +1,181 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestIsProxyFailure(t *testing.T) {
+	for text, want := range map[string]bool{
+		"net::ERR_TUNNEL_CONNECTION_FAILED":          true,
+		"net::ERR_PROXY_CONNECTION_FAILED":           true,
+		"navigate: net::ERR_SOCKS_CONNECTION_FAILED": true,
+		"net::ERR_NAME_NOT_RESOLVED":                 false,
+		"net::ERR_ABORTED":                           false,
+	} {
+		if got := isProxyFailure(text); got != want {
+			t.Errorf("isProxyFailure(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestProxyAuthWatchCountsRepeatedProxyChallenges(t *testing.T) {
+	var w proxyAuthWatch
+	proxy := &fetch.EventAuthRequired{RequestID: "r1", AuthChallenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceProxy}}
+	server := &fetch.EventAuthRequired{RequestID: "r2", AuthChallenge: &fetch.AuthChallenge{Source: fetch.AuthChallengeSourceServer}}
+
+	if w.rejected(proxy) {
+		t.Fatal("first proxy challenge counted as a failure")
+	}
+	if w.rejected(server) || w.rejected(server) {
+		t.Fatal("server challenge counted as a proxy failure")
+	}
+	if !w.rejected(proxy) {
+		t.Fatal("repeated proxy challenge not counted as a failure")
+	}
+}
+
+func TestReportTabProxyFailureRotatesStickyKey(t *testing.T) {
+	proxies := []config.BrowserProxyConfig{
+		{Server: "http://a.example:8080"},
+		{Server: "http://b.example:8080"},
+	}
+	b := &Bridge{Config: &config.RuntimeConfig{ProxyPools: config.ProxyPoolsConfig{
+		"res": {Strategy: config.ProxyPoolSticky, Proxies: proxies},
+	}}}
+	first, err := b.proxyPoolManager().Acquire("res", "ses_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.tabProxies = map[string]*tabProxy{
+		"tab1": {info: TabProxy{Pool: "res", Server: first.Server, Key: "ses_1"}, proxy: first},
+	}
+
+	b.reportTabProxyFailure("tab1", "net::ERR_TUNNEL_CONNECTION_FAILED")
+	b.reportTabProxyFailure("tab1", "net::ERR_TUNNEL_CONNECTION_FAILED")
+
+	info, ok := b.TabProxy("tab1")
+	if !ok || !info.Failed || info.LastError == "" {
+		t.Fatalf("tab proxy = %+v", info)
+	}
+	next, err := b.proxyPoolManager().Acquire("res", "ses_1")
+	if err != nil || next.Server == first.Server {
+		t.Fatalf("sticky key not rotated: %v, %v", next.Server, err)
+	}
+	failures := 0
+	for _, p := range b.ProxyPools()[0].Proxies {
+		failures += p.Failures
+	}
+	if failures != 1 {
+		t.Fatalf("failures = %d, want 1 (reported once per tab)", failures)
+	}
+}
+
+func TestProxyAuthConfiguredIncludesPools(t *testing.T) {
+	b := &Bridge{Config: &config.RuntimeConfig{}}
+	if b.proxyAuthConfigured() {
+		t.Fatal("no proxies configured")
+	}
+	b.Config.ProxyPools = config.ProxyPoolsConfig{"res": {Proxies: []config.BrowserProxyConfig{
+		{Server: "http://a.example:8080", Username: "u", Password: "p"},
+	}}}
+	if !b.proxyAuthConfigured() {
+		t.Fatal("pool credentials should enable proxy auth coordination")
+	}
+}
+
+func TestProxyContextSharedPerKeyUntilLastTab(t *testing.T) {
+	b := &Bridge{}
+	px := config.BrowserProxyConfig{Server: "http://a.example:8080"}
+
+	if b.reserveProxyContext("res", "ses_1", px) != nil {
+		t.Fatal("reserved a context before one was created")
+	}
+	pc, created := b.addProxyContext("res", "ses_1", px, "ctx1")
+	if !created {
+		t.Fatal("first context not registered")
+	}
+	b.settleProxyTab(pc, "tab1")
+
+	again := b.reserveProxyContext("res", "ses_1", px)
+	if again != pc {
+		t.Fatal("same pool, key and proxy did not share the context")
+	}
+	b.settleProxyTab(again, "tab2")
+	if b.reserveProxyContext("res", "ses_2", px) != nil {
+		t.Fatal("another key shared the context")
+	}
+	if b.reserveProxyContext("res", "", px) != nil {
+		t.Fatal("tabs without a key shared a context")
+	}
+
+	b.tabProxies = map[string]*tabProxy{
+		"tab1": {info: TabProxy{TabID: "tab1"}, pc: pc},
+		"tab2": {info: TabProxy{TabID: "tab2"}, pc: pc},
+	}
+	b.dropTabProxy("tab1")
+	if b.proxyContexts[pc.key] != pc {
+		t.Fatal("context dropped while a tab still uses it")
+	}
+	if id, dispose := b.settleProxyTab(b.reserveProxyContext("res", "ses_1", px), ""); dispose {
+		t.Fatalf("failed tab creation disposed a context in use: %s", id)
+	}
+	b.tabProxyMu.Lock()
+	delete(pc.tabs, "tab2")
+	delete(b.tabProxies, "tab2")
+	id, dispose := b.releaseProxyContextLocked(pc)
+	b.tabProxyMu.Unlock()
+	if !dispose || id != "ctx1" {
+		t.Fatalf("last tab did not release the context: %q, %v", id, dispose)
+	}
+	if _, ok := b.proxyContexts[pc.key]; ok {
+		t.Fatal("released context still shared")
+	}
+}
+
+func TestFailTabProxyOncePerContext(t *testing.T) {
+	b := &Bridge{}
+	px := config.BrowserProxyConfig{Server: "http://a.example:8080"}
+	pc, _ := b.addProxyContext("res", "ses_1", px, "ctx1")
+	b.settleProxyTab(pc, "tab1")
+	pc.tabs["tab2"] = struct{}{}
+	b.tabProxies = map[string]*tabProxy{
+		"tab1": {info: TabProxy{TabID: "tab1", Pool: "res", Key: "ses_1"}, proxy: px, pc: pc},
+		"tab2": {info: TabProxy{TabID: "tab2", Pool: "res", Key: "ses_1"}, proxy: px, pc: pc},
+	}
+
+	tp, tabs, ok := b.failTabProxy("tab2", "net::ERR_PROXY_CONNECTION_FAILED")
+	if !ok || tp.info.TabID != "tab2" || len(tabs) != 2 || tabs[0] != "tab1" || tabs[1] != "tab2" {
+		t.Fatalf("failTabProxy = %+v, %v, %v", tp, tabs, ok)
+	}
+	if _, _, again := b.failTabProxy("tab1", "net::ERR_PROXY_CONNECTION_FAILED"); again {
+		t.Fatal("failure reported twice for one context")
+	}
+	for _, id := range tabs {
+		if info, _ := b.TabProxy(id); !info.Failed || info.LastError == "" {
+			t.Fatalf("%s not marked failed: %+v", id, info)
+		}
+	}
+	if b.reserveProxyContext("res", "ses_1", px) != nil {
+		t.Fatal("new tab joined the failed context")
+	}
+}
+
+func TestCookieParamsKeepsExpiry(t *testing.T) {
+	params := cookieParams([]*network.Cookie{
+		{Name: "sid", Value: "1", Domain: ".example.com", Path: "/", Session: true, Expires: -1, HTTPOnly: true},
+		{Name: "pref", Value: "2", Domain: "example.com", Path: "/", Expires: 1893456000.5, SameSite: network.CookieSameSiteLax},
+	})
+	if len(params) != 2 {
+		t.Fatalf("params = %d, want 2", len(params))
+	}
+	if params[0].Expires != nil || !params[0].HTTPOnly {
+		t.Fatalf("session cookie = %+v", params[0])
+	}
+	if params[1].Expires == nil || params[1].Expires.Time().Unix() != 1893456000 || params[1].SameSite != network.CookieSameSiteLax {
+		t.Fatalf("persistent cookie = %+v", params[1])
+	}
+}
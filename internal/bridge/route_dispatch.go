@@ -111,6 +111,12 @@ func (rm *RouteManager) dispatch(listenCtx context.Context, tabID string, e *fet
 	}
 	executor := cdp.WithExecutor(listenCtx, chromedp.FromContext(listenCtx).Target)
 
+	if !matched {
+		if rp := rm.replayFor(tabID); rp != nil {
+			rm.dispatchReplay(executor, tabID, e, rp)
+			return
+		}
+	}
 	if !matched || rule.Action == RouteActionContinue {
 		if err := fetch.ContinueRequest(e.RequestID).Do(executor); err != nil {
 			slog.Debug("fetch.continueRequest failed", "tabId", tabID, "url", e.Request.URL, "err", err)
@@ -135,10 +141,16 @@ func (rm *RouteManager) dispatch(listenCtx context.Context, tabID string, e *fet
 			}
 			return
 		}
-		headers := []*fetch.HeaderEntry{{Name: "Content-Type", Value: rule.ContentType}}
-		if err := fetch.FulfillRequest(e.RequestID, int64(rule.Status)).
+		status, contentType, body := rule.Status, rule.ContentType, []byte(rule.Body)
+		if IsStreamResourceType(rule.ResourceType) {
+			// Only EventSource requests reach Fetch; the shim normally answers
+			// them first, this covers connections it could not wrap.
+			status, contentType, body = 200, "text/event-stream", eventStreamBody(rule.Frames)
+		}
+		headers := []*fetch.HeaderEntry{{Name: "Content-Type", Value: contentType}}
+		if err := fetch.FulfillRequest(e.RequestID, int64(status)).
 			WithResponseHeaders(headers).
-			WithBody(base64.StdEncoding.EncodeToString([]byte(rule.Body))).
+			WithBody(base64.StdEncoding.EncodeToString(body)).
 			Do(executor); err != nil {
 			slog.Debug("fetch.fulfillRequest failed", "tabId", tabID, "url", e.Request.URL, "err", err)
 		}
@@ -150,8 +162,8 @@ func (rm *RouteManager) dispatch(listenCtx context.Context, tabID string, e *fet
 }
 
 // match looks for the first rule matching url + resourceType + method. The
-// third return value (hasRules) is true iff the tab has any rules at all —
-// callers use it to skip dispatch entirely during teardown windows.
+// third return value (hasRules) is true iff the tab has any rules or a HAR
+// replay — callers use it to skip dispatch entirely during teardown windows.
 //
 // Method semantics:
 //
@@ -168,7 +180,7 @@ func (rm *RouteManager) match(tabID, url, resourceType, method string) (rule Rou
 	rm.mu.Lock()
 	defer rm.mu.Unlock()
 	state := rm.perTab[tabID]
-	if state == nil || len(state.rules) == 0 {
+	if state == nil || (len(state.rules) == 0 && state.replay == nil) {
 		return RouteRule{}, false, false
 	}
 	const optionsMethod = "OPTIONS"
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 )
 
@@ -25,6 +26,11 @@ type RouteManager struct {
 	// handleAuthRequests so challenges stay answerable.
 	proxyAuthActive    func() bool                // nil ⇒ no proxy auth configured
 	setPauseSuppressed func(tabID string, v bool) // nil ⇒ no coordination
+
+	// streamMu serializes stream shim installs per manager; streamScripts
+	// holds each tab's current init script (see syncStreamShim).
+	streamMu      sync.Mutex
+	streamScripts map[string]page.ScriptIdentifier
 }
 
 // tabRouteState holds per-tab interception state. listenCtx, when non-nil, is
@@ -33,6 +39,7 @@ type RouteManager struct {
 // and is called when the last rule is removed or the tab closes.
 type tabRouteState struct {
 	rules        []RouteRule
+	replay       *HARReplay // nil ⇒ no HAR replay (see route_replay.go)
 	listenCtx    context.Context
 	listenCancel context.CancelFunc
 	fetchEnabled bool
@@ -43,7 +50,11 @@ type tabRouteState struct {
 // receive a fabricated response body (security.allowedDomains boundary).
 // Pass nil to disable that check.
 func NewRouteManager(allowedDomainsFn func() []string) *RouteManager {
-	return &RouteManager{perTab: make(map[string]*tabRouteState), allowedDomainsFn: allowedDomainsFn}
+	return &RouteManager{
+		perTab:           make(map[string]*tabRouteState),
+		allowedDomainsFn: allowedDomainsFn,
+		streamScripts:    make(map[string]page.ScriptIdentifier),
+	}
 }
 
 // SetFetchAuthCoordination wires the proxy-auth coordination callbacks: the
@@ -129,7 +140,11 @@ func (rm *RouteManager) AddRule(ctx context.Context, tabID string, rule RouteRul
 	if rule.ContentType != "" && !IsFulfillContentTypeAllowed(rule.ContentType) {
 		return fmt.Errorf("contentType %q is not on the fulfill safe-list (or contains control chars)", rule.ContentType)
 	}
-	if rule.Action == RouteActionFulfill {
+	if err := validateStreamFields(rule); err != nil {
+		return err
+	}
+	stream := IsStreamResourceType(rule.ResourceType)
+	if rule.Action == RouteActionFulfill && !stream {
 		if rule.Status == 0 {
 			rule.Status = 200
 		}
@@ -161,6 +176,7 @@ func (rm *RouteManager) AddRule(ctx context.Context, tabID string, rule RouteRul
 	replaced := false
 	for i, r := range state.rules {
 		if r.Pattern == rule.Pattern {
+			stream = stream || IsStreamResourceType(r.ResourceType)
 			state.rules[i] = rule
 			replaced = true
 			break
@@ -197,26 +213,44 @@ func (rm *RouteManager) AddRule(ctx context.Context, tabID string, rule RouteRul
 	if needRegister {
 		rm.registerListener(listenCtx, tabID)
 	}
+	if stream {
+		if err := rm.syncStreamShim(ctx, tabID); err != nil {
+			rm.rollbackAddRule(tabID, isNewState, needRegister, priorRules, priorListenCtx, priorListenCancel, priorFetchEnabled)
+			rm.resyncStreamShim(ctx, tabID)
+			return fmt.Errorf("stream routes: %w", err)
+		}
+	}
 	if needEnable {
-		// Suppress the proxy-auth listener's blanket continue BEFORE rules
-		// take over dispatch, and keep handleAuthRequests on so proxy
-		// challenges stay answerable while routes own the Fetch domain.
-		rm.suppressPause(tabID, true)
-		handleAuth := authFn != nil && authFn()
-		if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
-			return fetch.Enable().
-				WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}).
-				WithHandleAuthRequests(handleAuth).
-				Do(c)
-		})); err != nil {
-			rm.suppressPause(tabID, false)
+		if err := rm.enableFetch(ctx, tabID, authFn); err != nil {
 			rm.rollbackAddRule(tabID, isNewState, needRegister, priorRules, priorListenCtx, priorListenCancel, priorFetchEnabled)
-			return fmt.Errorf("fetch.enable: %w", err)
+			if stream {
+				rm.resyncStreamShim(ctx, tabID)
+			}
+			return err
 		}
 	}
 	return nil
 }
 
+// enableFetch turns on request interception for a tab. It suppresses the
+// proxy-auth listener's blanket continue BEFORE rules take over dispatch, and
+// keeps handleAuthRequests on so proxy challenges stay answerable while
+// routes own the Fetch domain. On failure the suppression is released.
+func (rm *RouteManager) enableFetch(ctx context.Context, tabID string, authFn func() bool) error {
+	rm.suppressPause(tabID, true)
+	handleAuth := authFn != nil && authFn()
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		return fetch.Enable().
+			WithPatterns([]*fetch.RequestPattern{{URLPattern: "*"}}).
+			WithHandleAuthRequests(handleAuth).
+			Do(c)
+	})); err != nil {
+		rm.suppressPause(tabID, false)
+		return fmt.Errorf("fetch.enable: %w", err)
+	}
+	return nil
+}
+
 // rollbackAddRule restores the per-tab state captured before a failed AddRule.
 // If we registered a fresh listener for this call, its context is cancelled so
 // the no-op listener handle is released.
@@ -235,7 +269,7 @@ func (rm *RouteManager) rollbackAddRule(tabID string, isNewState, registeredList
 	}
 	s.rules = priorRules
 	s.fetchEnabled = priorFetchEnabled
-	if isNewState && len(s.rules) == 0 {
+	if isNewState && len(s.rules) == 0 && s.replay == nil {
 		delete(rm.perTab, tabID)
 	}
 	rm.mu.Unlock()
@@ -245,6 +279,15 @@ func (rm *RouteManager) rollbackAddRule(tabID string, isNewState, registeredList
 	}
 }
 
+// resyncStreamShim re-applies the tab's stream rules after a rollback or
+// removal. Failures are logged only: the rules themselves are already
+// consistent, and the next AddRule retries the install.
+func (rm *RouteManager) resyncStreamShim(ctx context.Context, tabID string) {
+	if err := rm.syncStreamShim(ctx, tabID); err != nil {
+		slog.Debug("stream route resync failed", "tabId", tabID, "err", err)
+	}
+}
+
 // Remove deletes rules matching pattern. Empty pattern removes all rules for
 // the tab. Returns the number of rules removed. When the last rule is removed,
 // the listener context is cancelled and CDP fetch interception is disabled.
@@ -261,14 +304,19 @@ func (rm *RouteManager) Remove(ctx context.Context, tabID string, pattern string
 	}
 
 	removed := 0
+	removedStream := false
 	if pattern == "" {
 		removed = len(state.rules)
+		for _, r := range state.rules {
+			removedStream = removedStream || IsStreamResourceType(r.ResourceType)
+		}
 		state.rules = nil
 	} else {
 		kept := state.rules[:0]
 		for _, r := range state.rules {
 			if r.Pattern == pattern {
 				removed++
+				removedStream = removedStream || IsStreamResourceType(r.ResourceType)
 				continue
 			}
 			kept = append(kept, r)
@@ -276,7 +324,7 @@ func (rm *RouteManager) Remove(ctx context.Context, tabID string, pattern string
 		state.rules = kept
 	}
 
-	teardown := len(state.rules) == 0
+	teardown := len(state.rules) == 0 && state.replay == nil
 	wasEnabled := state.fetchEnabled
 	cancel := state.listenCancel
 	if teardown {
@@ -287,34 +335,43 @@ func (rm *RouteManager) Remove(ctx context.Context, tabID string, pattern string
 	}
 	rm.mu.Unlock()
 
+	if removedStream {
+		rm.resyncStreamShim(ctx, tabID)
+	}
 	if teardown {
-		if wasEnabled {
-			if rm.proxyAuthOn() {
-				// Hand the Fetch domain back to proxy auth instead of
-				// disabling it (which would kill auth handling too).
-				// Unsuppress first so no paused request goes unanswered.
-				rm.suppressPause(tabID, false)
-				if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
-					return fetch.Enable().WithHandleAuthRequests(true).Do(c)
-				})); err != nil {
-					slog.Debug("fetch re-enable for proxy auth failed during route teardown", "tabId", tabID, "err", err)
-				}
-			} else {
-				if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
-					return fetch.Disable().Do(c)
-				})); err != nil {
-					slog.Debug("fetch.disable failed during route teardown", "tabId", tabID, "err", err)
-				}
-				rm.suppressPause(tabID, false)
+		rm.releaseFetch(ctx, tabID, wasEnabled, cancel)
+	}
+	return removed, nil
+}
+
+// releaseFetch undoes enableFetch once a tab has neither rules nor a replay,
+// then cancels the listener.
+func (rm *RouteManager) releaseFetch(ctx context.Context, tabID string, wasEnabled bool, cancel context.CancelFunc) {
+	if wasEnabled {
+		if rm.proxyAuthOn() {
+			// Hand the Fetch domain back to proxy auth instead of
+			// disabling it (which would kill auth handling too).
+			// Unsuppress first so no paused request goes unanswered.
+			rm.suppressPause(tabID, false)
+			if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+				return fetch.Enable().WithHandleAuthRequests(true).Do(c)
+			})); err != nil {
+				slog.Debug("fetch re-enable for proxy auth failed during route teardown", "tabId", tabID, "err", err)
 			}
 		} else {
+			if err := chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+				return fetch.Disable().Do(c)
+			})); err != nil {
+				slog.Debug("fetch.disable failed during route teardown", "tabId", tabID, "err", err)
+			}
 			rm.suppressPause(tabID, false)
 		}
-		if cancel != nil {
-			cancel()
-		}
+	} else {
+		rm.suppressPause(tabID, false)
+	}
+	if cancel != nil {
+		cancel()
 	}
-	return removed, nil
 }
 
 // RemoveTab drops all rule state for a tab without issuing CDP calls. It is
@@ -337,6 +394,9 @@ func (rm *RouteManager) RemoveTab(tabID string) {
 	cancel := state.listenCancel
 	delete(rm.perTab, tabID)
 	rm.mu.Unlock()
+	rm.streamMu.Lock()
+	delete(rm.streamScripts, tabID)
+	rm.streamMu.Unlock()
 	// Hand pause dispatch back even though the Bridge drops the whole flag in
 	// its own onTabRemoved hook right after — RemoveTab must stay correct on
 	// its own, not by courtesy of the caller's cleanup ordering.
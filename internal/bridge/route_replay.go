@@ -0,0 +1,446 @@
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
+)
+
+// ReplayMatch selects how strictly a paused request must agree with an
+// archived one before its recorded response is served.
+type ReplayMatch string
+
+const (
+	// ReplayMatchStrict compares method, full URL and a SHA-256 of the
+	// request body.
+	ReplayMatchStrict ReplayMatch = "strict"
+	// ReplayMatchURL compares method and full URL; bodies are ignored.
+	ReplayMatchURL ReplayMatch = "url"
+	// ReplayMatchPath compares method and URL without its query string;
+	// bodies are ignored.
+	ReplayMatchPath ReplayMatch = "path"
+)
+
+// ReplayNotFound is the policy for requests the archive cannot answer.
+type ReplayNotFound string
+
+const (
+	ReplayNotFoundAbort       ReplayNotFound = "abort"
+	ReplayNotFoundPassthrough ReplayNotFound = "passthrough"
+	ReplayNotFound404         ReplayNotFound = "404"
+)
+
+// MaxReplayEntries caps how many archive entries a replay indexes.
+const MaxReplayEntries = 20_000
+
+// replaySkippedHeaders are recorded response headers that must not be
+// replayed: the archived body is already decoded and re-framed by Fetch, and
+// HTTP/2 pseudo-headers (":status") are dropped separately.
+var replaySkippedHeaders = map[string]struct{}{
+	"content-encoding":  {},
+	"content-length":    {},
+	"transfer-encoding": {},
+	"connection":        {},
+	"keep-alive":        {},
+}
+
+// ReplayOptions configures a HAR replay. Zero values select strict matching
+// and the abort policy.
+type ReplayOptions struct {
+	Match    ReplayMatch    `json:"match"`
+	NotFound ReplayNotFound `json:"notFound"`
+	// Source names the archive for status output (e.g. its state-dir path).
+	Source string `json:"source,omitempty"`
+}
+
+// ReplayStatus reports an active (or just stopped) replay.
+type ReplayStatus struct {
+	Source   string         `json:"source,omitempty"`
+	Match    ReplayMatch    `json:"match"`
+	NotFound ReplayNotFound `json:"notFound"`
+	Entries  int            `json:"entries"`
+	Skipped  int            `json:"skipped,omitempty"`
+	Served   int64          `json:"served"`
+	Missed   int64          `json:"missed"`
+}
+
+type replayResponse struct {
+	status     int
+	statusText string
+	headers    []*fetch.HeaderEntry
+	body       []byte
+	// failed marks entries recorded without a response (status 0): the
+	// request failed at record time and fails again on replay.
+	failed bool
+}
+
+// HARReplay answers paused requests from a recorded HAR archive. Entries
+// sharing a key are served in recorded order; once exhausted, the last one
+// keeps being served so polling pages stay deterministic.
+type HARReplay struct {
+	opts    ReplayOptions
+	entries int
+	skipped int
+
+	mu     sync.Mutex
+	byKey  map[string][]*replayResponse
+	next   map[string]int
+	served int64
+	missed int64
+}
+
+// NewHARReplay indexes entries for replay. WebSocket entries and responses
+// Fetch cannot fulfill (1xx, out-of-range status) are skipped; an archive
+// with nothing left to serve is an error.
+func NewHARReplay(entries []bridgeobserve.ExportEntry, opts ReplayOptions) (*HARReplay, error) {
+	if opts.Match == "" {
+		opts.Match = ReplayMatchStrict
+	}
+	switch opts.Match {
+	case ReplayMatchStrict, ReplayMatchURL, ReplayMatchPath:
+	default:
+		return nil, fmt.Errorf("invalid match %q (want strict, url or path)", opts.Match)
+	}
+	if opts.NotFound == "" {
+		opts.NotFound = ReplayNotFoundAbort
+	}
+	switch opts.NotFound {
+	case ReplayNotFoundAbort, ReplayNotFoundPassthrough, ReplayNotFound404:
+	default:
+		return nil, fmt.Errorf("invalid notFound %q (want abort, passthrough or 404)", opts.NotFound)
+	}
+	if len(entries) > MaxReplayEntries {
+		return nil, fmt.Errorf("archive has %d entries (cap %d)", len(entries), MaxReplayEntries)
+	}
+
+	rp := &HARReplay{opts: opts, byKey: make(map[string][]*replayResponse), next: make(map[string]int)}
+	for i, e := range entries {
+		status := e.Response.Status
+		if len(e.WebSocketMessages) > 0 || (status != 0 && (status < 200 || status > 599)) {
+			rp.skipped++
+			continue
+		}
+		resp := &replayResponse{status: status, statusText: e.Response.StatusText, failed: status == 0}
+		if !resp.failed {
+			body, err := archivedBody(e.Response.Content)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d (%s): %w", i, e.Request.URL, err)
+			}
+			resp.body = body
+			resp.headers = replayHeaders(e.Response)
+		}
+		var reqBody string
+		if e.Request.PostData != nil {
+			reqBody = e.Request.PostData.Text
+		}
+		key := rp.key(e.Request.Method, e.Request.URL, []byte(reqBody))
+		rp.byKey[key] = append(rp.byKey[key], resp)
+		rp.entries++
+	}
+	if rp.entries == 0 {
+		return nil, fmt.Errorf("archive has no replayable entries")
+	}
+	return rp, nil
+}
+
+func archivedBody(c bridgeobserve.ExportContent) ([]byte, error) {
+	if c.Encoding == "base64" {
+		body, err := base64.StdEncoding.DecodeString(c.Text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body: %w", err)
+		}
+		return body, nil
+	}
+	return []byte(c.Text), nil
+}
+
+func replayHeaders(r bridgeobserve.ExportResponse) []*fetch.HeaderEntry {
+	headers := make([]*fetch.HeaderEntry, 0, len(r.Headers)+1)
+	hasContentType := false
+	for _, h := range r.Headers {
+		name := strings.TrimSpace(h.Name)
+		lower := strings.ToLower(name)
+		if name == "" || strings.HasPrefix(name, ":") || containsHeaderControlChar(name) || containsHeaderControlChar(h.Value) {
+			continue
+		}
+		if _, skip := replaySkippedHeaders[lower]; skip {
+			continue
+		}
+		hasContentType = hasContentType || lower == "content-type"
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: h.Value})
+	}
+	if !hasContentType && r.Content.MimeType != "" && !containsHeaderControlChar(r.Content.MimeType) {
+		headers = append(headers, &fetch.HeaderEntry{Name: "Content-Type", Value: r.Content.MimeType})
+	}
+	return headers
+}
+
+// key builds the lookup key for a request under the replay's match mode.
+func (rp *HARReplay) key(method, rawURL string, body []byte) string {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	u := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		parsed.Fragment = ""
+		parsed.RawFragment = ""
+		if rp.opts.Match == ReplayMatchPath {
+			parsed.RawQuery = ""
+			parsed.ForceQuery = false
+		}
+		u = parsed.String()
+	}
+	if rp.opts.Match != ReplayMatchStrict {
+		return method + " " + u
+	}
+	hash := ""
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		hash = hex.EncodeToString(sum[:])
+	}
+	return method + " " + u + " " + hash
+}
+
+// lookup returns the next recorded response for the request and updates the
+// served/missed counters.
+func (rp *HARReplay) lookup(method, rawURL string, body []byte) (*replayResponse, bool) {
+	key := rp.key(method, rawURL, body)
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	list := rp.byKey[key]
+	if len(list) == 0 {
+		rp.missed++
+		return nil, false
+	}
+	i := rp.next[key]
+	if i < len(list)-1 {
+		rp.next[key] = i + 1
+	}
+	rp.served++
+	return list[i], true
+}
+
+// Status returns the replay's configuration and counters.
+func (rp *HARReplay) Status() *ReplayStatus {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return &ReplayStatus{
+		Source:   rp.opts.Source,
+		Match:    rp.opts.Match,
+		NotFound: rp.opts.NotFound,
+		Entries:  rp.entries,
+		Skipped:  rp.skipped,
+		Served:   rp.served,
+		Missed:   rp.missed,
+	}
+}
+
+// needsBody reports whether lookups need the request body.
+func (rp *HARReplay) needsBody() bool {
+	return rp.opts.Match == ReplayMatchStrict
+}
+
+// SetReplay starts (or replaces) HAR replay on a tab. Requests that no route
+// rule matches are answered from rp; rules keep precedence, so a continue
+// rule can punch a hole through to the live network.
+func (rm *RouteManager) SetReplay(ctx context.Context, tabID string, rp *HARReplay) error {
+	if rm == nil {
+		return fmt.Errorf("route manager not initialized")
+	}
+	if rp == nil {
+		return fmt.Errorf("replay required")
+	}
+
+	rm.mu.Lock()
+	state := rm.perTab[tabID]
+	isNewState := state == nil
+	if state == nil {
+		state = &tabRouteState{}
+		rm.perTab[tabID] = state
+	}
+	priorReplay := state.replay
+	state.replay = rp
+	needRegister := state.listenCancel == nil
+	needEnable := !state.fetchEnabled
+	authFn := rm.proxyAuthActive
+	if needRegister {
+		state.listenCtx, state.listenCancel = context.WithCancel(ctx)
+	}
+	if needEnable {
+		state.fetchEnabled = true
+	}
+	listenCtx := state.listenCtx
+	rm.mu.Unlock()
+
+	if needRegister {
+		rm.registerListener(listenCtx, tabID)
+	}
+	if needEnable {
+		if err := rm.enableFetch(ctx, tabID, authFn); err != nil {
+			rm.mu.Lock()
+			var cancel context.CancelFunc
+			if s := rm.perTab[tabID]; s != nil {
+				s.replay = priorReplay
+				s.fetchEnabled = false
+				if needRegister {
+					cancel = s.listenCancel
+					s.listenCtx, s.listenCancel = nil, nil
+				}
+				if isNewState && len(s.rules) == 0 && s.replay == nil {
+					delete(rm.perTab, tabID)
+				}
+			}
+			rm.mu.Unlock()
+			if cancel != nil {
+				cancel()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearReplay stops HAR replay on a tab and returns its final status, or nil
+// when the tab was not replaying. Interception is torn down when no route
+// rules remain.
+func (rm *RouteManager) ClearReplay(ctx context.Context, tabID string) *ReplayStatus {
+	if rm == nil {
+		return nil
+	}
+	rm.mu.Lock()
+	state := rm.perTab[tabID]
+	if state == nil || state.replay == nil {
+		rm.mu.Unlock()
+		return nil
+	}
+	final := state.replay.Status()
+	state.replay = nil
+	teardown := len(state.rules) == 0
+	wasEnabled := state.fetchEnabled
+	cancel := state.listenCancel
+	if teardown {
+		state.fetchEnabled = false
+		state.listenCancel = nil
+		state.listenCtx = nil
+		delete(rm.perTab, tabID)
+	}
+	rm.mu.Unlock()
+
+	if teardown {
+		rm.releaseFetch(ctx, tabID, wasEnabled, cancel)
+	}
+	return final
+}
+
+// ReplayStatus returns the tab's active replay status, or nil.
+func (rm *RouteManager) ReplayStatus(tabID string) *ReplayStatus {
+	if rp := rm.replayFor(tabID); rp != nil {
+		return rp.Status()
+	}
+	return nil
+}
+
+func (rm *RouteManager) replayFor(tabID string) *HARReplay {
+	if rm == nil {
+		return nil
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if state := rm.perTab[tabID]; state != nil {
+		return state.replay
+	}
+	return nil
+}
+
+// dispatchReplay answers a paused request that no rule matched from the
+// tab's archive. Archived responses are forged responses, so the same
+// allowedDomains policy as fulfill applies: blocked hosts go to the network.
+func (rm *RouteManager) dispatchReplay(executor context.Context, tabID string, e *fetch.EventRequestPaused, rp *HARReplay) {
+	var body []byte
+	if rp.needsBody() {
+		body = pausedRequestBody(executor, e)
+	}
+	resp, found := rp.lookup(e.Request.Method, e.Request.URL, body)
+	if !found {
+		switch rp.opts.NotFound {
+		case ReplayNotFoundPassthrough:
+			rm.continuePaused(executor, tabID, e, "replay passthrough")
+			return
+		case ReplayNotFound404:
+			resp = &replayResponse{
+				status:  404,
+				headers: []*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/plain"}},
+				body:    []byte("not in replay archive\n"),
+			}
+		default:
+			if err := fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(executor); err != nil {
+				slog.Debug("fetch.failRequest (replay miss) failed", "tabId", tabID, "url", e.Request.URL, "err", err)
+			}
+			return
+		}
+	}
+	if resp.failed {
+		if err := fetch.FailRequest(e.RequestID, network.ErrorReasonFailed).Do(executor); err != nil {
+			slog.Debug("fetch.failRequest (replayed failure) failed", "tabId", tabID, "url", e.Request.URL, "err", err)
+		}
+		return
+	}
+	if !rm.fulfillForgeryPermittedFor(e.Request.URL) {
+		slog.Warn("route replay blocked: response forgery not permitted (allowlisted host or forbidden scheme)",
+			"tabId", tabID,
+			"url", e.Request.URL,
+		)
+		rm.continuePaused(executor, tabID, e, "replay fallthrough")
+		return
+	}
+	fulfill := fetch.FulfillRequest(e.RequestID, int64(resp.status)).
+		WithResponseHeaders(resp.headers).
+		WithBody(base64.StdEncoding.EncodeToString(resp.body))
+	if resp.statusText != "" && !containsHeaderControlChar(resp.statusText) {
+		fulfill = fulfill.WithResponsePhrase(resp.statusText)
+	}
+	if err := fulfill.Do(executor); err != nil {
+		slog.Debug("fetch.fulfillRequest (replay) failed", "tabId", tabID, "url", e.Request.URL, "err", err)
+	}
+}
+
+func (rm *RouteManager) continuePaused(executor context.Context, tabID string, e *fetch.EventRequestPaused, why string) {
+	if err := fetch.ContinueRequest(e.RequestID).Do(executor); err != nil {
+		slog.Debug("fetch.continueRequest ("+why+") failed", "tabId", tabID, "url", e.Request.URL, "err", err)
+	}
+}
+
+// pausedRequestBody returns the request body of a paused request. Large
+// bodies are omitted from the event and fetched separately.
+func pausedRequestBody(executor context.Context, e *fetch.EventRequestPaused) []byte {
+	if e.Request == nil || !e.Request.HasPostData {
+		return nil
+	}
+	if len(e.Request.PostDataEntries) > 0 {
+		var body []byte
+		for _, entry := range e.Request.PostDataEntries {
+			b, err := base64.StdEncoding.DecodeString(entry.Bytes)
+			if err != nil {
+				return nil
+			}
+			body = append(body, b...)
+		}
+		return body
+	}
+	if e.NetworkID == "" {
+		return nil
+	}
+	body, err := network.GetRequestPostData(network.RequestID(e.NetworkID)).Do(executor)
+	if err != nil {
+		return nil
+	}
+	return body
+}
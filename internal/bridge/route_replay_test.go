@@ -0,0 +1,202 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+	bridgeobserve "github.com/pinchtab/pinchtab/internal/bridge/observe"
+)
+
+func harEntry(method, url, reqBody string, status int, body string) bridgeobserve.ExportEntry {
+	e := bridgeobserve.ExportEntry{
+		Request: bridgeobserve.ExportRequest{Method: method, URL: url},
+		Response: bridgeobserve.ExportResponse{
+			Status:     status,
+			StatusText: "OK",
+			Headers:    []bridgeobserve.NameValuePair{{Name: "Content-Type", Value: "application/json"}},
+			Content:    bridgeobserve.ExportContent{Text: body, MimeType: "application/json"},
+		},
+	}
+	if reqBody != "" {
+		e.Request.PostData = &bridgeobserve.ExportPostData{Text: reqBody}
+	}
+	return e
+}
+
+func TestHARReplay_MatchModes(t *testing.T) {
+	entries := []bridgeobserve.ExportEntry{
+		harEntry("POST", "https://api.test/search?q=a", `{"q":"a"}`, 200, `{"hits":1}`),
+	}
+	cases := []struct {
+		match  ReplayMatch
+		method string
+		url    string
+		body   string
+		want   bool
+	}{
+		{ReplayMatchStrict, "POST", "https://api.test/search?q=a", `{"q":"a"}`, true},
+		{ReplayMatchStrict, "POST", "https://api.test/search?q=a#frag", `{"q":"a"}`, true},
+		{ReplayMatchStrict, "POST", "https://api.test/search?q=a", `{"q":"b"}`, false},
+		{ReplayMatchStrict, "GET", "https://api.test/search?q=a", `{"q":"a"}`, false},
+		{ReplayMatchURL, "post", "https://api.test/search?q=a", `{"q":"b"}`, true},
+		{ReplayMatchURL, "POST", "https://api.test/search?q=b", `{"q":"a"}`, false},
+		{ReplayMatchPath, "POST", "https://api.test/search?q=b", "", true},
+		{ReplayMatchPath, "POST", "https://api.test/other", "", false},
+	}
+	for _, tc := range cases {
+		rp, err := NewHARReplay(entries, ReplayOptions{Match: tc.match})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, got := rp.lookup(tc.method, tc.url, []byte(tc.body)); got != tc.want {
+			t.Errorf("%s %s %s body=%q: matched=%v, want %v", tc.match, tc.method, tc.url, tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestHARReplay_RepeatedRequestsServeInOrder(t *testing.T) {
+	rp, err := NewHARReplay([]bridgeobserve.ExportEntry{
+		harEntry("GET", "https://api.test/poll", "", 200, `1`),
+		harEntry("GET", "https://api.test/poll", "", 200, `2`),
+	}, ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for range 3 {
+		resp, ok := rp.lookup("GET", "https://api.test/poll", nil)
+		if !ok {
+			t.Fatal("expected a match")
+		}
+		got = append(got, string(resp.body))
+	}
+	if strings.Join(got, ",") != "1,2,2" {
+		t.Errorf("served %v, want 1,2,2 (last entry repeats)", got)
+	}
+	rp.lookup("GET", "https://api.test/missing", nil)
+	if st := rp.Status(); st.Served != 3 || st.Missed != 1 || st.Entries != 2 {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestHARReplay_ResponseShape(t *testing.T) {
+	png := harEntry("GET", "https://cdn.test/a.png", "", 200, "iVBORw0KGgo=")
+	png.Response.Content.Encoding = "base64"
+	png.Response.Content.MimeType = "image/png"
+	png.Response.Headers = []bridgeobserve.NameValuePair{
+		{Name: ":status", Value: "200"},
+		{Name: "Content-Encoding", Value: "gzip"},
+		{Name: "Content-Length", Value: "9"},
+		{Name: "X-Bad", Value: "a\r\nb"},
+		{Name: "Cache-Control", Value: "max-age=60"},
+	}
+	failed := harEntry("GET", "https://api.test/down", "", 0, "")
+	ws := harEntry("GET", "wss://feed.test/", "", 101, "")
+	ws.WebSocketMessages = []bridgeobserve.ExportWebSocketMessage{{Type: "send", Data: "x"}}
+
+	rp, err := NewHARReplay([]bridgeobserve.ExportEntry{png, failed, ws}, ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := rp.Status(); st.Entries != 2 || st.Skipped != 1 {
+		t.Errorf("status = %+v", st)
+	}
+	resp, _ := rp.lookup("GET", "https://cdn.test/a.png", nil)
+	if string(resp.body) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("body not base64-decoded: %q", resp.body)
+	}
+	var names []string
+	for _, h := range resp.headers {
+		names = append(names, h.Name+"="+h.Value)
+	}
+	if strings.Join(names, ";") != "Cache-Control=max-age=60;Content-Type=image/png" {
+		t.Errorf("headers = %v", names)
+	}
+	if resp, _ := rp.lookup("GET", "https://api.test/down", nil); !resp.failed {
+		t.Error("status 0 entries should replay as failures")
+	}
+}
+
+func TestNewHARReplay_Validation(t *testing.T) {
+	ok := []bridgeobserve.ExportEntry{harEntry("GET", "https://a.test/", "", 200, "")}
+	if _, err := NewHARReplay(ok, ReplayOptions{Match: "fuzzy"}); err == nil {
+		t.Error("expected error for unknown match mode")
+	}
+	if _, err := NewHARReplay(ok, ReplayOptions{NotFound: "retry"}); err == nil {
+		t.Error("expected error for unknown notFound policy")
+	}
+	if _, err := NewHARReplay(nil, ReplayOptions{}); err == nil {
+		t.Error("expected error for an empty archive")
+	}
+	bad := harEntry("GET", "https://a.test/", "", 200, "%%%")
+	bad.Response.Content.Encoding = "base64"
+	if _, err := NewHARReplay([]bridgeobserve.ExportEntry{bad}, ReplayOptions{}); err == nil {
+		t.Error("expected error for invalid base64 body")
+	}
+	rp, err := NewHARReplay(ok, ReplayOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := rp.Status(); st.Match != ReplayMatchStrict || st.NotFound != ReplayNotFoundAbort {
+		t.Errorf("defaults = %+v", st)
+	}
+}
+
+// A replaying tab stays routed after its last rule is removed, and the
+// listener keeps dispatching (hasRules) so unmatched requests reach replay.
+func TestRouteManager_ReplayKeepsTabRouted(t *testing.T) {
+	rm := NewRouteManager(nil)
+	rp, _ := NewHARReplay([]bridgeobserve.ExportEntry{harEntry("GET", "https://a.test/", "", 200, "")}, ReplayOptions{})
+	rm.mu.Lock()
+	rm.perTab["tab1"] = &tabRouteState{
+		rules:  []RouteRule{{Pattern: "*.png", Action: RouteActionAbort}},
+		replay: rp,
+	}
+	rm.mu.Unlock()
+
+	if _, err := rm.Remove(t.Context(), "tab1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, matched, hasRules := rm.match("tab1", "https://a.test/", "document", "GET"); matched || !hasRules {
+		t.Errorf("matched=%v hasRules=%v, want unmatched with replay active", matched, hasRules)
+	}
+	if st := rm.ReplayStatus("tab1"); st == nil || st.Entries != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+
+	final := rm.ClearReplay(t.Context(), "tab1")
+	if final == nil || final.Entries != 1 {
+		t.Errorf("final = %+v", final)
+	}
+	rm.mu.Lock()
+	_, ok := rm.perTab["tab1"]
+	rm.mu.Unlock()
+	if ok {
+		t.Error("clearing the last replay should drop tab state")
+	}
+	if rm.ClearReplay(t.Context(), "tab1") != nil {
+		t.Error("second clear should report no active replay")
+	}
+}
+
+func TestRouteManager_SetReplay_FailedEnableRollsBack(t *testing.T) {
+	rm := NewRouteManager(nil)
+	parent, cancel := chromedp.NewContext(context.Background())
+	cancel()
+
+	rp, _ := NewHARReplay([]bridgeobserve.ExportEntry{harEntry("GET", "https://a.test/", "", 200, "")}, ReplayOptions{})
+	if err := rm.SetReplay(parent, "tab1", rp); err == nil {
+		t.Fatal("expected enable failure on dead chromedp context")
+	}
+	if rm.ReplayStatus("tab1") != nil {
+		t.Error("replay should not stay installed after a failed enable")
+	}
+	rm.mu.Lock()
+	_, ok := rm.perTab["tab1"]
+	rm.mu.Unlock()
+	if ok {
+		t.Error("per-tab state should be dropped after rollback")
+	}
+}
@@ -165,6 +165,13 @@ type RouteRule struct {
 	// Method="OPTIONS" to opt in.
 	Method string `json:"method,omitempty"`
 
+	// Frames and DropPattern apply to stream rules only (ResourceType
+	// "websocket" or "eventsource", see route_stream.go). Frames are scripted
+	// server messages; DropPattern discards matching frames, with the same
+	// substring/glob semantics as Pattern.
+	Frames      []RouteFrame `json:"frames,omitempty"`
+	DropPattern string       `json:"dropPattern,omitempty"`
+
 	// compiled is the precompiled regex for wildcard patterns. It is nil for
 	// substring patterns (Pattern has no '*' or '?'). Set once at AddRule time
 	// and read-only thereafter so the listener doesn't recompile per event.
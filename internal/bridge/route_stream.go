@@ -0,0 +1,263 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/pinchtab/pinchtab/internal/assets"
+)
+
+// Stream rules are RouteRules whose ResourceType is "websocket" or
+// "eventsource". Chrome's Fetch domain never pauses WebSocket traffic and can
+// only answer an event stream with one fixed body, so these rules are
+// enforced by an in-page shim (assets.StreamRouteJS) that wraps the
+// WebSocket and EventSource constructors:
+//
+//   - abort    → the connection fails (error, then close 1006 for sockets).
+//   - fulfill  → a scripted connection opens and delivers Frames without
+//     contacting the server.
+//   - continue → the real connection, with Frames injected after it opens
+//     and frames matching DropPattern discarded in both directions.
+//
+// Forgery rules match fulfill: hosts in security.allowedDomains never get
+// scripted frames — fulfill falls back to the real connection and injected
+// frames are skipped. The shim only sees connections opened after it was
+// (re)installed; existing connections keep the rules they started with.
+const (
+	// MaxStreamFramesPerRule caps the scripted frames one rule may carry.
+	MaxStreamFramesPerRule = 100
+
+	// MaxStreamFrameDelayMs caps RouteFrame.DelayMs so a typo cannot park a
+	// scripted connection for hours.
+	MaxStreamFrameDelayMs = 60_000
+)
+
+// RouteFrame is one scripted server message of a stream rule. Event and ID
+// only apply to EventSource rules and become the SSE event name and
+// lastEventId. DelayMs is measured from the previous frame (or from open).
+type RouteFrame struct {
+	Data    string `json:"data"`
+	Event   string `json:"event,omitempty"`
+	ID      string `json:"id,omitempty"`
+	DelayMs int    `json:"delayMs,omitempty"`
+}
+
+// IsStreamResourceType reports whether rt selects the stream routing path
+// (WebSocket or EventSource) rather than plain Fetch interception.
+func IsStreamResourceType(rt string) bool {
+	switch strings.ToLower(rt) {
+	case "websocket", "eventsource":
+		return true
+	}
+	return false
+}
+
+// validateStreamFields checks the stream-only fields of rule. It is shared by
+// AddRule and the HTTP handler's early checks.
+func validateStreamFields(rule RouteRule) error {
+	stream := IsStreamResourceType(rule.ResourceType)
+	if !stream {
+		if len(rule.Frames) > 0 {
+			return fmt.Errorf("frames require resourceType websocket or eventsource")
+		}
+		if rule.DropPattern != "" {
+			return fmt.Errorf("dropPattern requires resourceType websocket or eventsource")
+		}
+		return nil
+	}
+	if rule.Body != "" || rule.ContentType != "" || rule.Status != 0 {
+		return fmt.Errorf("body, contentType and status do not apply to %s rules; use frames", strings.ToLower(rule.ResourceType))
+	}
+	if rule.Method != "" {
+		return fmt.Errorf("method does not apply to %s rules", strings.ToLower(rule.ResourceType))
+	}
+	if rule.Action == RouteActionAbort && (len(rule.Frames) > 0 || rule.DropPattern != "") {
+		return fmt.Errorf("frames and dropPattern do not apply to abort rules")
+	}
+	if rule.Action == RouteActionFulfill && rule.DropPattern != "" {
+		return fmt.Errorf("dropPattern needs a real connection (action continue)")
+	}
+	if len(rule.Frames) > MaxStreamFramesPerRule {
+		return fmt.Errorf("too many frames: %d (cap %d)", len(rule.Frames), MaxStreamFramesPerRule)
+	}
+	total := 0
+	sse := strings.EqualFold(rule.ResourceType, "eventsource")
+	for i, f := range rule.Frames {
+		total += len(f.Data)
+		if f.DelayMs < 0 || f.DelayMs > MaxStreamFrameDelayMs {
+			return fmt.Errorf("frames[%d].delayMs must be 0-%d", i, MaxStreamFrameDelayMs)
+		}
+		if !sse && (f.Event != "" || f.ID != "") {
+			return fmt.Errorf("frames[%d]: event and id only apply to eventsource rules", i)
+		}
+		if containsHeaderControlChar(f.Event) || containsHeaderControlChar(f.ID) {
+			return fmt.Errorf("frames[%d]: event and id must not contain CR, LF or NUL", i)
+		}
+	}
+	if total > MaxFulfillBodyBytes {
+		return fmt.Errorf("frames exceed %d bytes (cap)", MaxFulfillBodyBytes)
+	}
+	if rule.DropPattern != "" {
+		if _, err := compileDropPattern(rule.DropPattern); err != nil {
+			return fmt.Errorf("invalid dropPattern %q: %w", rule.DropPattern, err)
+		}
+	}
+	return nil
+}
+
+// ValidateStreamFields is the exported form of the stream-rule checks for
+// callers that want an early 400 before reaching the bridge.
+func ValidateStreamFields(rule RouteRule) error {
+	return validateStreamFields(rule)
+}
+
+// compileDropPattern compiles a DropPattern with the same semantics as
+// Pattern: wildcards anchor a glob over the whole frame, anything else is a
+// substring match.
+func compileDropPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.ContainsAny(pattern, "*?") {
+		return globToRegex(pattern)
+	}
+	return regexp.Compile(regexp.QuoteMeta(pattern))
+}
+
+// patternRegexSource returns a regular expression source equivalent to
+// ruleMatchesURL for pattern. Go's glob and QuoteMeta output is also valid
+// JavaScript RegExp syntax, which is what the shim compiles it with.
+func patternRegexSource(pattern string) string {
+	if strings.ContainsAny(pattern, "*?") {
+		if re, err := globToRegex(pattern); err == nil {
+			return re.String()
+		}
+	}
+	return regexp.QuoteMeta(pattern)
+}
+
+type streamShimRule struct {
+	Re     string       `json:"re"`
+	Type   string       `json:"type"`
+	Action RouteAction  `json:"action"`
+	Frames []RouteFrame `json:"frames,omitempty"`
+	Drop   string       `json:"drop,omitempty"`
+}
+
+type streamShimConfig struct {
+	Rules   []streamShimRule `json:"rules"`
+	NoForge []string         `json:"noForge"`
+}
+
+// streamShimSource renders the shim invocation for the given stream rules.
+// noForge is the allowedDomains list; it is normalized here so the shim can
+// compare hosts verbatim.
+func streamShimSource(rules []RouteRule, noForge []string) (string, error) {
+	cfg := streamShimConfig{Rules: []streamShimRule{}, NoForge: []string{}}
+	for _, r := range rules {
+		sr := streamShimRule{
+			Re:     patternRegexSource(r.Pattern),
+			Type:   strings.ToLower(r.ResourceType),
+			Action: r.Action,
+			Frames: r.Frames,
+		}
+		if r.DropPattern != "" {
+			sr.Drop = patternRegexSource(r.DropPattern)
+		}
+		cfg.Rules = append(cfg.Rules, sr)
+	}
+	for _, d := range noForge {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			cfg.NoForge = append(cfg.NoForge, d)
+		}
+	}
+	// json.Marshal escapes <, > and U+2028/U+2029, so the config is safe to
+	// splice into a script.
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return "(" + strings.TrimSpace(assets.StreamRouteJS) + ")(" + string(raw) + ");", nil
+}
+
+// eventStreamBody renders frames as a text/event-stream body. It backs
+// fulfill at the Fetch level for EventSource requests the shim did not see
+// (e.g. opened from a worker); delays cannot be honoured there.
+func eventStreamBody(frames []RouteFrame) []byte {
+	var sb strings.Builder
+	for _, f := range frames {
+		if f.Event != "" {
+			sb.WriteString("event: " + f.Event + "\n")
+		}
+		if f.ID != "" {
+			sb.WriteString("id: " + f.ID + "\n")
+		}
+		data := strings.ReplaceAll(f.Data, "\r\n", "\n")
+		for _, line := range strings.Split(data, "\n") {
+			sb.WriteString("data: " + line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+// syncStreamShim brings the tab's shim in line with its current stream
+// rules: the previous init script is replaced, and the running document gets
+// the new rule table. With no stream rules left the shim is disarmed in
+// place (an empty table) and no init script remains.
+func (rm *RouteManager) syncStreamShim(ctx context.Context, tabID string) error {
+	var rules []RouteRule
+	rm.mu.Lock()
+	if state := rm.perTab[tabID]; state != nil {
+		for _, r := range state.rules {
+			if IsStreamResourceType(r.ResourceType) {
+				rules = append(rules, r)
+			}
+		}
+	}
+	rm.mu.Unlock()
+
+	var noForge []string
+	if rm.allowedDomainsFn != nil {
+		noForge = rm.allowedDomainsFn()
+	}
+	src, err := streamShimSource(rules, noForge)
+	if err != nil {
+		return err
+	}
+
+	rm.streamMu.Lock()
+	defer rm.streamMu.Unlock()
+	if rm.streamScripts == nil {
+		rm.streamScripts = make(map[string]page.ScriptIdentifier)
+	}
+	prev, installed := rm.streamScripts[tabID]
+	if !installed && len(rules) == 0 {
+		return nil
+	}
+	return chromedp.Run(ctx, chromedp.ActionFunc(func(c context.Context) error {
+		if installed {
+			if err := page.RemoveScriptToEvaluateOnNewDocument(prev).Do(c); err != nil {
+				slog.Debug("stream route script removal failed", "tabId", tabID, "err", err)
+			}
+			delete(rm.streamScripts, tabID)
+		}
+		if len(rules) > 0 {
+			id, err := page.AddScriptToEvaluateOnNewDocument(src).Do(c)
+			if err != nil {
+				return fmt.Errorf("install stream route script: %w", err)
+			}
+			rm.streamScripts[tabID] = id
+		}
+		// The current document is best effort: it may be mid-navigation, in
+		// which case the init script covers the next one.
+		if _, exc, err := runtime.Evaluate(src).Do(c); err != nil || exc != nil {
+			slog.Debug("stream route script evaluate failed", "tabId", tabID, "err", err, "exception", exc)
+		}
+		return nil
+	}))
+}
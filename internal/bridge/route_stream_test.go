@@ -0,0 +1,144 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+)
+
+func TestValidateStreamFields(t *testing.T) {
+	frames := []RouteFrame{{Data: "hello"}}
+	cases := []struct {
+		name string
+		rule RouteRule
+		ok   bool
+	}{
+		{"plain http rule", RouteRule{Pattern: "api", Action: RouteActionFulfill, Body: "{}"}, true},
+		{"websocket mock", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "WebSocket", Frames: frames}, true},
+		{"websocket drop", RouteRule{Pattern: "feed", Action: RouteActionContinue, ResourceType: "websocket", DropPattern: "*heartbeat*"}, true},
+		{"eventsource event and id", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "eventsource", Frames: []RouteFrame{{Data: "x", Event: "tick", ID: "1"}}}, true},
+		{"frames on http rule", RouteRule{Pattern: "api", Action: RouteActionFulfill, Frames: frames}, false},
+		{"drop on http rule", RouteRule{Pattern: "api", DropPattern: "x"}, false},
+		{"body on stream rule", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Body: "x"}, false},
+		{"method on stream rule", RouteRule{Pattern: "feed", ResourceType: "websocket", Method: "GET"}, false},
+		{"frames on abort", RouteRule{Pattern: "feed", Action: RouteActionAbort, ResourceType: "websocket", Frames: frames}, false},
+		{"drop on fulfill", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", DropPattern: "x"}, false},
+		{"event on websocket", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Frames: []RouteFrame{{Data: "x", Event: "tick"}}}, false},
+		{"newline in event", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "eventsource", Frames: []RouteFrame{{Data: "x", Event: "a\nb"}}}, false},
+		{"negative delay", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Frames: []RouteFrame{{Data: "x", DelayMs: -1}}}, false},
+		{"delay over cap", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Frames: []RouteFrame{{Data: "x", DelayMs: MaxStreamFrameDelayMs + 1}}}, false},
+		{"too many frames", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Frames: make([]RouteFrame, MaxStreamFramesPerRule+1)}, false},
+		{"frames over byte cap", RouteRule{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "websocket", Frames: []RouteFrame{{Data: strings.Repeat("a", MaxFulfillBodyBytes)}, {Data: "b"}}}, false},
+	}
+	for _, tc := range cases {
+		err := ValidateStreamFields(tc.rule)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+// The shim compiles rule patterns as JavaScript regular expressions; the
+// sources must match exactly what ruleMatchesURL matches in Go.
+func TestPatternRegexSourceMatchesRuleSemantics(t *testing.T) {
+	urls := []string{
+		"wss://feed.example.com/live?x=1",
+		"wss://other.test/feed.example.com",
+		"https://example.com/events",
+		"https://example.com/eventsXjson",
+	}
+	for _, pattern := range []string{"feed.example.com", "wss://*.example.com/*", "*events?json", "(a)[b]{c}|$^+"} {
+		re := regexp.MustCompile(patternRegexSource(pattern))
+		for _, u := range urls {
+			if got, want := re.MatchString(u), ruleMatchesURL(RouteRule{Pattern: pattern}, u); got != want {
+				t.Errorf("pattern %q url %q: regex=%v rule=%v", pattern, u, got, want)
+			}
+		}
+	}
+}
+
+func TestStreamShimSource(t *testing.T) {
+	rules := []RouteRule{
+		{Pattern: "wss://feed.test/*", Action: RouteActionFulfill, ResourceType: "WebSocket", Frames: []RouteFrame{{Data: "</script>", DelayMs: 10}}},
+		{Pattern: "events", Action: RouteActionContinue, ResourceType: "eventsource", DropPattern: "*ping*"},
+	}
+	src, err := streamShimSource(rules, []string{" Bank.Example ", "", "*.corp.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(src, "</script>") {
+		t.Error("frame data must be escaped when spliced into the script")
+	}
+	start := strings.LastIndex(src, ")({")
+	if start < 0 || !strings.HasSuffix(src, ");") {
+		t.Fatalf("unexpected invocation shape: ...%s", src[max(0, len(src)-200):])
+	}
+	var cfg streamShimConfig
+	if err := json.Unmarshal([]byte(src[start+2:len(src)-2]), &cfg); err != nil {
+		t.Fatalf("config is not JSON: %v", err)
+	}
+	if len(cfg.Rules) != 2 || cfg.Rules[0].Type != "websocket" || cfg.Rules[0].Re != `^wss://feed\.test/.*$` {
+		t.Errorf("rules = %+v", cfg.Rules)
+	}
+	if cfg.Rules[0].Frames[0].Data != "</script>" || cfg.Rules[1].Drop != "^.*ping.*$" {
+		t.Errorf("frames/drop = %+v", cfg.Rules)
+	}
+	if strings.Join(cfg.NoForge, ",") != "bank.example,*.corp.test" {
+		t.Errorf("noForge = %v", cfg.NoForge)
+	}
+}
+
+func TestEventStreamBody(t *testing.T) {
+	got := string(eventStreamBody([]RouteFrame{
+		{Data: `{"px":1}`, Event: "tick", ID: "7"},
+		{Data: "line one\r\nline two"},
+	}))
+	want := "event: tick\nid: 7\ndata: {\"px\":1}\n\ndata: line one\ndata: line two\n\n"
+	if got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRouteManager_Match_StreamRuleByResourceType(t *testing.T) {
+	rm := NewRouteManager(nil)
+	rm.mu.Lock()
+	rm.perTab["tab1"] = &tabRouteState{rules: []RouteRule{
+		{Pattern: "feed", Action: RouteActionFulfill, ResourceType: "eventsource", Frames: []RouteFrame{{Data: "x"}}},
+	}}
+	rm.mu.Unlock()
+
+	if _, matched, _ := rm.match("tab1", "https://x/feed", "xhr", "GET"); matched {
+		t.Error("eventsource rule must not match an xhr to the same URL")
+	}
+	if r, matched, _ := rm.match("tab1", "https://x/feed", "eventsource", "GET"); !matched || len(r.Frames) != 1 {
+		t.Errorf("eventsource request should match, got %+v %v", r, matched)
+	}
+}
+
+// A stream rule whose shim cannot be installed must not linger in the rule
+// table: the page would not enforce it.
+func TestRouteManager_AddRule_StreamShimFailureRollsBack(t *testing.T) {
+	rm := NewRouteManager(nil)
+	parent, cancel := chromedp.NewContext(context.Background())
+	cancel()
+
+	err := rm.AddRule(parent, "tab1", RouteRule{
+		Pattern: "wss://feed.test/*", Action: RouteActionAbort, ResourceType: "websocket",
+	})
+	if err == nil || !strings.Contains(err.Error(), "stream routes") {
+		t.Fatalf("expected stream route install failure, got %v", err)
+	}
+	if rules := rm.List("tab1"); len(rules) != 0 {
+		t.Errorf("rule kept after failed install: %+v", rules)
+	}
+	rm.mu.Lock()
+	_, ok := rm.perTab["tab1"]
+	rm.mu.Unlock()
+	if ok {
+		t.Error("per-tab state should be dropped after rollback")
+	}
+}
@@ -297,10 +297,19 @@ func injectedScript(ctx context.Context, script string) error {
 }
 
 func geoProviderForConfig(cfg *config.RuntimeConfig) geo.Provider {
-	if cfg == nil || cfg.Proxy.Geo == nil || cfg.Proxy.Geo.IsZero() {
+	if cfg == nil {
 		return geo.Noop{}
 	}
-	return geo.Static{Info: cfg.Proxy.GeoInfo()}
+	return GeoProviderForProxy(cfg.Proxy)
+}
+
+// GeoProviderForProxy returns the geo provider aligning fingerprints with p:
+// its configured geo block, or no opinion.
+func GeoProviderForProxy(p config.BrowserProxyConfig) geo.Provider {
+	if p.Geo == nil || p.Geo.IsZero() {
+		return geo.Noop{}
+	}
+	return geo.Static{Info: p.GeoInfo()}
 }
 
 func resolveLaunchGeoAlignment(parent context.Context, cfg *config.RuntimeConfig) (launchGeoAlignment, error) {
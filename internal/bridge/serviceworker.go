@@ -0,0 +1,204 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/serviceworker"
+	"github.com/chromedp/chromedp"
+)
+
+// serviceWorkerSettle bounds how long the first listing waits for Chrome to
+// report the stored registrations after the ServiceWorker domain is enabled.
+const serviceWorkerSettle = 300 * time.Millisecond
+
+// ServiceWorkerInfo is a service worker registration and its versions.
+type ServiceWorkerInfo struct {
+	RegistrationID string                 `json:"registrationId"`
+	ScopeURL       string                 `json:"scopeURL"`
+	Versions       []ServiceWorkerVersion `json:"versions"`
+}
+
+// ServiceWorkerVersion is one version of a registered service worker.
+type ServiceWorkerVersion struct {
+	VersionID         string   `json:"versionId"`
+	ScriptURL         string   `json:"scriptURL"`
+	RunningStatus     string   `json:"runningStatus"`
+	Status            string   `json:"status"`
+	ControlledClients []string `json:"controlledClients,omitempty"`
+}
+
+// serviceWorkerTab tracks the registrations and versions reported by the
+// ServiceWorker domain of one tab. Chrome only pushes updates, so the
+// current set is rebuilt from events.
+type serviceWorkerTab struct {
+	registrations map[string]*serviceworker.Registration
+	versions      map[string]*serviceworker.Version
+	seen          chan struct{}
+	bypass        bool
+}
+
+// ServiceWorkers lists the service workers known to the browser as seen from
+// the tab of ctx.
+func (b *Bridge) ServiceWorkers(ctx context.Context, tabID string) ([]ServiceWorkerInfo, error) {
+	t, err := b.enableServiceWorkers(ctx, tabID)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-t.seen:
+	case <-time.After(serviceWorkerSettle):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	b.swMu.Lock()
+	defer b.swMu.Unlock()
+	byReg := make(map[string]*ServiceWorkerInfo, len(t.registrations))
+	for id, r := range t.registrations {
+		if r.IsDeleted {
+			continue
+		}
+		byReg[id] = &ServiceWorkerInfo{RegistrationID: id, ScopeURL: r.ScopeURL, Versions: []ServiceWorkerVersion{}}
+	}
+	for _, v := range t.versions {
+		info := byReg[string(v.RegistrationID)]
+		if info == nil || v.Status == serviceworker.VersionStatusRedundant {
+			continue
+		}
+		sv := ServiceWorkerVersion{
+			VersionID:     v.VersionID,
+			ScriptURL:     v.ScriptURL,
+			RunningStatus: string(v.RunningStatus),
+			Status:        string(v.Status),
+		}
+		for _, c := range v.ControlledClients {
+			sv.ControlledClients = append(sv.ControlledClients, string(c))
+		}
+		info.Versions = append(info.Versions, sv)
+	}
+
+	out := make([]ServiceWorkerInfo, 0, len(byReg))
+	for _, info := range byReg {
+		sort.Slice(info.Versions, func(i, j int) bool { return info.Versions[i].VersionID < info.Versions[j].VersionID })
+		out = append(out, *info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ScopeURL < out[j].ScopeURL })
+	return out, nil
+}
+
+// UnregisterServiceWorker unregisters the service worker for scopeURL.
+func (b *Bridge) UnregisterServiceWorker(ctx context.Context, tabID, scopeURL string) error {
+	if _, err := b.enableServiceWorkers(ctx, tabID); err != nil {
+		return err
+	}
+	if err := chromedp.Run(ctx, serviceworker.Unregister(scopeURL)); err != nil {
+		return fmt.Errorf("unregister service worker: %w", err)
+	}
+	return nil
+}
+
+// StopServiceWorkers stops the worker running versionID, or every running
+// worker when versionID is empty. Workers restart on their next event.
+func (b *Bridge) StopServiceWorkers(ctx context.Context, tabID, versionID string) error {
+	if _, err := b.enableServiceWorkers(ctx, tabID); err != nil {
+		return err
+	}
+	var action chromedp.Action = serviceworker.StopAllWorkers()
+	if versionID != "" {
+		action = serviceworker.StopWorker(versionID)
+	}
+	if err := chromedp.Run(ctx, action); err != nil {
+		return fmt.Errorf("stop service worker: %w", err)
+	}
+	return nil
+}
+
+// SetServiceWorkerBypass makes the tab's network requests skip service
+// workers (and go to the network) while enabled.
+func (b *Bridge) SetServiceWorkerBypass(ctx context.Context, tabID string, bypass bool) error {
+	if err := chromedp.Run(ctx, network.Enable(), network.SetBypassServiceWorker(bypass)); err != nil {
+		return fmt.Errorf("set service worker bypass: %w", err)
+	}
+	b.swMu.Lock()
+	defer b.swMu.Unlock()
+	b.serviceWorkerTabLocked(tabID).bypass = bypass
+	return nil
+}
+
+// ServiceWorkerBypass reports whether the tab bypasses service workers.
+func (b *Bridge) ServiceWorkerBypass(tabID string) bool {
+	b.swMu.Lock()
+	defer b.swMu.Unlock()
+	t := b.swTabs[tabID]
+	return t != nil && t.bypass
+}
+
+// enableServiceWorkers enables the ServiceWorker domain on the tab and, once
+// per tab, starts tracking its registration and version events.
+func (b *Bridge) enableServiceWorkers(ctx context.Context, tabID string) (*serviceWorkerTab, error) {
+	b.swMu.Lock()
+	t := b.serviceWorkerTabLocked(tabID)
+	first := t.seen == nil
+	if first {
+		t.seen = make(chan struct{})
+		seen := t.seen
+		chromedp.ListenTarget(ctx, func(ev any) {
+			switch e := ev.(type) {
+			case *serviceworker.EventWorkerRegistrationUpdated:
+				b.swMu.Lock()
+				for _, r := range e.Registrations {
+					t.registrations[string(r.RegistrationID)] = r
+				}
+				b.swMu.Unlock()
+				closeOnce(seen)
+			case *serviceworker.EventWorkerVersionUpdated:
+				b.swMu.Lock()
+				for _, v := range e.Versions {
+					t.versions[v.VersionID] = v
+				}
+				b.swMu.Unlock()
+				closeOnce(seen)
+			}
+		})
+	}
+	b.swMu.Unlock()
+
+	if err := chromedp.Run(ctx, serviceworker.Enable()); err != nil {
+		return nil, fmt.Errorf("enable service workers: %w", err)
+	}
+	return t, nil
+}
+
+func (b *Bridge) serviceWorkerTabLocked(tabID string) *serviceWorkerTab {
+	if b.swTabs == nil {
+		b.swTabs = make(map[string]*serviceWorkerTab)
+	}
+	t := b.swTabs[tabID]
+	if t == nil {
+		t = &serviceWorkerTab{
+			registrations: make(map[string]*serviceworker.Registration),
+			versions:      make(map[string]*serviceworker.Version),
+		}
+		b.swTabs[tabID] = t
+	}
+	return t
+}
+
+// dropServiceWorkerTab forgets a closed tab's service worker tracking.
+func (b *Bridge) dropServiceWorkerTab(tabID string) {
+	b.swMu.Lock()
+	defer b.swMu.Unlock()
+	delete(b.swTabs, tabID)
+}
+
+func closeOnce(ch chan struct{}) {
+	select {
+	case <-ch:
+	default:
+		close(ch)
+	}
+}
@@ -0,0 +1,228 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// An isolated agent session gets a browser context of its own, created with
+// its first tab. Every tab the session opens lives there, so its cookies,
+// storage and permissions never mix with other sessions or with the
+// instance's default context. The context outlives its tabs and goes away
+// with DisposeSessionContext (session revoked or expired) or the browser.
+
+// SessionContext describes the browser context of an isolated session.
+type SessionContext struct {
+	SessionID        string    `json:"sessionId"`
+	BrowserContextID string    `json:"browserContextId"`
+	Tabs             []string  `json:"tabs"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type sessionContext struct {
+	id      cdp.BrowserContextID
+	tabs    map[string]struct{}
+	created time.Time
+}
+
+func (sc *sessionContext) info(sessionID string) SessionContext {
+	tabs := make([]string, 0, len(sc.tabs))
+	for tabID := range sc.tabs {
+		tabs = append(tabs, tabID)
+	}
+	sort.Strings(tabs)
+	return SessionContext{SessionID: sessionID, BrowserContextID: string(sc.id), Tabs: tabs, CreatedAt: sc.created}
+}
+
+// CreateTabInSession opens a tab in the browser context of sessionID,
+// creating the context on first use.
+func (b *Bridge) CreateTabInSession(url, sessionID string) (string, context.Context, context.CancelFunc, error) {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return b.CreateTab(url)
+	}
+	tm, err := b.tabManager()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	// The context ID goes stale when the browser restarts; retry once with a
+	// fresh context.
+	for attempt := 0; ; attempt++ {
+		contextID, fresh, err := b.sessionBrowserContext(tm, sessionID)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		tabID, ctx, cancel, err := tm.createTab(url, tabCreateOptions{
+			browserContextID: contextID,
+			onTabID: func(tabID string) {
+				b.sessionCtxMu.Lock()
+				if sc, ok := b.sessionContexts[sessionID]; ok && sc.id == contextID {
+					sc.tabs[tabID] = struct{}{}
+				}
+				b.sessionCtxMu.Unlock()
+			},
+		})
+		if err == nil {
+			return tabID, ctx, cancel, nil
+		}
+		if fresh || attempt > 0 {
+			return "", nil, nil, err
+		}
+		slog.Debug("session context unusable, recreating", "session", sessionID, "err", err)
+		b.sessionCtxMu.Lock()
+		if sc, ok := b.sessionContexts[sessionID]; ok && sc.id == contextID {
+			delete(b.sessionContexts, sessionID)
+		}
+		b.sessionCtxMu.Unlock()
+	}
+}
+
+// sessionBrowserContext returns the session's context, creating it (with the
+// instance default permissions) when there is none. fresh reports creation.
+func (b *Bridge) sessionBrowserContext(tm *TabManager, sessionID string) (cdp.BrowserContextID, bool, error) {
+	b.sessionCtxMu.Lock()
+	defer b.sessionCtxMu.Unlock()
+	if sc, ok := b.sessionContexts[sessionID]; ok {
+		return sc.id, false, nil
+	}
+
+	execCtx, err := browserExecutorContext(tm.browserCtx)
+	if err != nil {
+		return "", false, err
+	}
+	createCtx, cancel := context.WithTimeout(execCtx, tabCreateTimeout)
+	defer cancel()
+	contextID, err := target.CreateBrowserContext().Do(createCtx)
+	if err != nil {
+		return "", false, fmt.Errorf("create session browser context: %w", err)
+	}
+	if b.Config != nil {
+		if err := setPermissions(createCtx, contextID, "", b.Config.Permissions); err != nil {
+			slog.Warn("session context default permissions failed", "session", sessionID, "err", err)
+		}
+	}
+	if b.sessionContexts == nil {
+		b.sessionContexts = map[string]*sessionContext{}
+	}
+	b.sessionContexts[sessionID] = &sessionContext{id: contextID, tabs: map[string]struct{}{}, created: time.Now()}
+	slog.Info("session browser context created", "session", sessionID, "browserContextId", contextID)
+	return contextID, true, nil
+}
+
+// SessionContext returns the browser context of an isolated session.
+func (b *Bridge) SessionContext(sessionID string) (SessionContext, bool) {
+	b.sessionCtxMu.Lock()
+	defer b.sessionCtxMu.Unlock()
+	sc, ok := b.sessionContexts[strings.TrimSpace(sessionID)]
+	if !ok {
+		return SessionContext{}, false
+	}
+	return sc.info(sessionID), true
+}
+
+// TabSession returns the isolated session whose context holds tabID, or "".
+func (b *Bridge) TabSession(tabID string) string {
+	b.sessionCtxMu.Lock()
+	defer b.sessionCtxMu.Unlock()
+	for sessionID, sc := range b.sessionContexts {
+		if _, ok := sc.tabs[tabID]; ok {
+			return sessionID
+		}
+	}
+	return ""
+}
+
+// SessionContexts lists the session browser contexts, sorted by session ID.
+func (b *Bridge) SessionContexts() []SessionContext {
+	b.sessionCtxMu.Lock()
+	out := make([]SessionContext, 0, len(b.sessionContexts))
+	for id, sc := range b.sessionContexts {
+		out = append(out, sc.info(id))
+	}
+	b.sessionCtxMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].SessionID < out[j].SessionID })
+	return out
+}
+
+// DisposeSessionContext closes the session's tabs and disposes its browser
+// context, dropping its cookies, storage and permissions. It reports the
+// number of tabs closed; a session without a context is not an error.
+func (b *Bridge) DisposeSessionContext(sessionID string) (int, error) {
+	sessionID = strings.TrimSpace(sessionID)
+	b.sessionCtxMu.Lock()
+	sc, ok := b.sessionContexts[sessionID]
+	delete(b.sessionContexts, sessionID)
+	var tabs []string
+	if ok {
+		tabs = sc.info(sessionID).Tabs
+	}
+	b.sessionCtxMu.Unlock()
+	if !ok {
+		return 0, nil
+	}
+
+	closed := 0
+	for _, tabID := range tabs {
+		if err := b.CloseTab(tabID); err != nil {
+			slog.Debug("session context: close tab failed", "session", sessionID, "tab", tabID, "err", err)
+			continue
+		}
+		closed++
+	}
+	b.dropContextPermissions(sc.id)
+	b.disposeBrowserContext(sc.id)
+	slog.Info("session browser context disposed", "session", sessionID, "browserContextId", sc.id, "tabsClosed", closed)
+	return closed, nil
+}
+
+// dropSessionTab forgets a closed tab; the context stays for the session's
+// next tab.
+func (b *Bridge) dropSessionTab(tabID string) {
+	b.sessionCtxMu.Lock()
+	defer b.sessionCtxMu.Unlock()
+	for _, sc := range b.sessionContexts {
+		delete(sc.tabs, tabID)
+	}
+}
+
+// tabBrowserContext returns the non-default browser context a tab lives in
+// (session or pool proxy context), or "" for the default context.
+func (b *Bridge) tabBrowserContext(tabID string) cdp.BrowserContextID {
+	if tabID == "" {
+		return ""
+	}
+	b.sessionCtxMu.Lock()
+	for _, sc := range b.sessionContexts {
+		if _, ok := sc.tabs[tabID]; ok {
+			b.sessionCtxMu.Unlock()
+			return sc.id
+		}
+	}
+	b.sessionCtxMu.Unlock()
+
+	b.tabProxyMu.Lock()
+	defer b.tabProxyMu.Unlock()
+	if tp, ok := b.tabProxies[tabID]; ok {
+		return cdp.BrowserContextID(tp.info.BrowserContextID)
+	}
+	return ""
+}
+
+// browserContextOf returns the non-default browser context of the tab ctx
+// is attached to.
+func (b *Bridge) browserContextOf(ctx context.Context) cdp.BrowserContextID {
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return ""
+	}
+	return b.tabBrowserContext(string(c.Target.TargetID))
+}
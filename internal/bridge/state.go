@@ -146,6 +146,11 @@ func (b *Bridge) SaveState() {
 		if seen[t.URL] || !accessed[string(t.TargetID)] {
 			continue
 		}
+		// A restored tab would open without its pool proxy or outside its
+		// session's browser context.
+		if b.tabBrowserContext(string(t.TargetID)) != "" {
+			continue
+		}
 		seen[t.URL] = true
 
 		status := "active"
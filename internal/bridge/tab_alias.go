@@ -0,0 +1,51 @@
+package bridge
+
+import "time"
+
+// tabAliasMaxHops bounds how many replacements of a replaced tab an alias
+// follows.
+const tabAliasMaxHops = 8
+
+// tabAlias points the ID of a tab that was replaced, such as a proxied tab
+// moved to a healthy proxy, at its replacement for a while.
+type tabAlias struct {
+	to      string
+	expires time.Time
+}
+
+// AliasTab makes from resolve to to for ttl, so callers holding a replaced
+// tab's ID keep reaching its replacement. Expired aliases are pruned here.
+func (tm *TabManager) AliasTab(from, to string, ttl time.Duration) {
+	if tm == nil || from == "" || to == "" || from == to {
+		return
+	}
+	now := time.Now()
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for id, a := range tm.aliases {
+		if now.After(a.expires) {
+			delete(tm.aliases, id)
+		}
+	}
+	if tm.aliases == nil {
+		tm.aliases = map[string]tabAlias{}
+	}
+	tm.aliases[from] = tabAlias{to: to, expires: now.Add(ttl)}
+}
+
+// resolveTabAlias returns the tab that replaced tabID, following chains of
+// replacements, or false when tabID is not an alias.
+func (tm *TabManager) resolveTabAlias(tabID string) (string, bool) {
+	now := time.Now()
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	resolved := tabID
+	for i := 0; i < tabAliasMaxHops; i++ {
+		a, ok := tm.aliases[resolved]
+		if !ok || now.After(a.expires) {
+			break
+		}
+		resolved = a.to
+	}
+	return resolved, resolved != tabID
+}
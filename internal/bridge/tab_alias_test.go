@@ -0,0 +1,32 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTabContextFollowsTabAlias(t *testing.T) {
+	tm := &TabManager{tabs: map[string]*TabEntry{
+		"tab_new": {Ctx: context.Background()},
+	}, accessed: map[string]bool{}}
+	tm.AliasTab("tab_old", "tab_mid", time.Minute)
+	tm.AliasTab("tab_mid", "tab_new", time.Minute)
+
+	_, resolved, err := tm.TabContext("tab_old")
+	if err != nil || resolved != "tab_new" {
+		t.Fatalf("TabContext(tab_old) = %q, %v; want tab_new", resolved, err)
+	}
+	if _, ok := tm.resolveTabAlias("tab_new"); ok {
+		t.Fatal("live tab resolved as an alias")
+	}
+
+	tm.AliasTab("tab_gone", "tab_new", -time.Second)
+	if _, ok := tm.resolveTabAlias("tab_gone"); ok {
+		t.Fatal("expired alias still resolves")
+	}
+	tm.AliasTab("tab_other", "tab_new", time.Minute)
+	if _, ok := tm.aliases["tab_gone"]; ok {
+		t.Fatal("expired alias not pruned")
+	}
+}
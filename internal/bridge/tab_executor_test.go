@@ -247,6 +247,44 @@ func TestTabExecutor_Stats(t *testing.T) {
 	}
 }
 
+func TestTabExecutor_StatsCountsQueuedActions(t *testing.T) {
+	te := NewTabExecutor(1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{}, 2)
+	go func() {
+		_ = te.Execute(context.Background(), "tab1", func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+		done <- struct{}{}
+	}()
+	<-started
+	go func() {
+		_ = te.Execute(context.Background(), "tab2", func(ctx context.Context) error { return nil })
+		done <- struct{}{}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for te.Stats().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 1 queued action, got %+v", te.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if used := te.Stats().SemaphoreUsed; used != 1 {
+		t.Errorf("expected 1 running action, got %d", used)
+	}
+
+	close(release)
+	<-done
+	<-done
+	if q := te.Stats().Queued; q != 0 {
+		t.Errorf("expected 0 queued after completion, got %d", q)
+	}
+}
+
 func TestTabExecutor_ExecuteWithTimeout(t *testing.T) {
 	te := NewTabExecutor(2)
 	err := te.ExecuteWithTimeout(context.Background(), "tab1", 100*time.Millisecond, func(ctx context.Context) error {
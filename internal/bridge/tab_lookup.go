@@ -84,6 +84,17 @@ func (tm *TabManager) TabContext(tabID string) (context.Context, string, error)
 	entry, ok := tm.tabs[tabID]
 	tm.mu.RUnlock()
 
+	if !ok {
+		if to, aliased := tm.resolveTabAlias(tabID); aliased {
+			tm.mu.RLock()
+			entry, ok = tm.tabs[to]
+			tm.mu.RUnlock()
+			if ok {
+				tabID = to
+			}
+		}
+	}
+
 	if !ok {
 		targets, err := tm.ListTargets()
 		if err == nil {
@@ -45,7 +45,8 @@ type TabManager struct {
 	routeMgr          *RouteManager
 	onTabRemovedHooks []func(tabID string)
 	netMonitor        *NetworkMonitor
-	currentTab        string // ID of the most recently used tab
+	currentTab        string              // ID of the most recently used tab
+	aliases           map[string]tabAlias // replaced tab IDs, see tab_alias.go
 	executor          *TabExecutor
 	guardOnce         sync.Once
 	guardActive       bool
@@ -130,6 +131,20 @@ func browserExecutorContext(ctx context.Context) (context.Context, error) {
 }
 
 func (tm *TabManager) CreateTab(url string) (string, context.Context, context.CancelFunc, error) {
+	return tm.createTab(url, tabCreateOptions{})
+}
+
+// tabCreateOptions customizes createTab for tabs that need more than a
+// default target.
+type tabCreateOptions struct {
+	// browserContextID opens the target in that browser context instead of
+	// the default one.
+	browserContextID cdp.BrowserContextID
+	// onTabID runs once the tab ID is known, before onTabSetup.
+	onTabID func(tabID string)
+}
+
+func (tm *TabManager) createTab(url string, opts tabCreateOptions) (string, context.Context, context.CancelFunc, error) {
 	if tm == nil {
 		return "", nil, nil, fmt.Errorf("tab manager not initialized")
 	}
@@ -167,7 +182,11 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	if err := chromedp.Run(createCtx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
-			targetID, err = target.CreateTarget("about:blank").Do(ctx)
+			create := target.CreateTarget("about:blank")
+			if opts.browserContextID != "" {
+				create = create.WithBrowserContextID(opts.browserContextID)
+			}
+			targetID, err = create.Do(ctx)
 			return err
 		}),
 	); err != nil {
@@ -186,6 +205,9 @@ func (tm *TabManager) CreateTab(url string) (string, context.Context, context.Ca
 	rawCDPID := string(targetID)
 	tabID := tm.idMgr.TabIDFromCDPTarget(rawCDPID)
 
+	if opts.onTabID != nil {
+		opts.onTabID(tabID)
+	}
 	if tm.onTabSetup != nil {
 		tm.onTabSetup(ctx, tabID)
 	}
@@ -267,6 +289,16 @@ func (tm *TabManager) CloseTab(tabID string) error {
 	tm.mu.Lock()
 	entry, tracked := tm.tabs[tabID]
 	tm.mu.Unlock()
+	if !tracked {
+		if to, ok := tm.resolveTabAlias(tabID); ok {
+			tm.mu.Lock()
+			entry, tracked = tm.tabs[to]
+			tm.mu.Unlock()
+			if tracked {
+				tabID = to
+			}
+		}
+	}
 
 	if tracked && entry.Cancel != nil {
 		entry.Cancel()
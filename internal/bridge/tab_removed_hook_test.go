@@ -8,7 +8,8 @@ import (
 // TestExternalTabRemovedHookSurvivesRewire verifies that a hook registered via
 // Bridge.AddTabRemovedHook is applied to the current TabManager, re-applied when
 // wireTabManager swaps the TabManager (launch/reinit/remote-CDP), and not
-// duplicated across rewires — alongside the built-in dropFetchPauseSuppression.
+// duplicated across rewires — alongside the built-in dropFetchPauseSuppression,
+// dropWebAuthnTab, dropServiceWorkerTab, dropTabProxy and dropSessionTab.
 func TestExternalTabRemovedHookSurvivesRewire(t *testing.T) {
 	b := &Bridge{}
 
@@ -18,9 +19,10 @@ func TestExternalTabRemovedHookSurvivesRewire(t *testing.T) {
 	ctx := context.Background()
 	b.wireTabManager(ctx)
 
-	// External hook + built-in dropFetchPauseSuppression.
-	if got := len(b.onTabRemovedHooks); got != 2 {
-		t.Fatalf("hooks after first wire = %d, want 2", got)
+	// External hook + built-in dropFetchPauseSuppression, dropWebAuthnTab,
+	// dropServiceWorkerTab, dropTabProxy and dropSessionTab.
+	if got := len(b.onTabRemovedHooks); got != 6 {
+		t.Fatalf("hooks after first wire = %d, want 6", got)
 	}
 	for _, h := range b.onTabRemovedHooks {
 		h("tab1")
@@ -32,7 +34,7 @@ func TestExternalTabRemovedHookSurvivesRewire(t *testing.T) {
 	// A reinit swaps the TabManager; the external hook must persist without
 	// duplicating (built-in is freshly re-added, not accumulated).
 	b.wireTabManager(ctx)
-	if got := len(b.onTabRemovedHooks); got != 2 {
-		t.Fatalf("hooks after rewire = %d, want 2 (no duplication)", got)
+	if got := len(b.onTabRemovedHooks); got != 6 {
+		t.Fatalf("hooks after rewire = %d, want 6 (no duplication)", got)
 	}
 }
@@ -1,6 +1,8 @@
 package tabs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -8,58 +10,140 @@ import (
 
 const DefaultLockTimeout = 10 * time.Minute
 
+// ErrLockNotHeld is returned when renewing a lease the caller no longer owns.
+var ErrLockNotHeld = errors.New("lock not held")
+
+// LockInfo describes a live lease. Token is the fencing token minted when
+// the current owner acquired the lock; it only grows, so a request carrying
+// an older token is from a lease that has since expired or been released.
 type LockInfo struct {
 	Owner     string
 	ExpiresAt time.Time
+	Token     uint64
 }
 
 type lockEntry struct {
 	owner   string
 	expires time.Time
+	token   uint64
+}
+
+type lockWaiter struct {
+	ready chan struct{}
 }
 
 type LockManager struct {
-	locks map[string]lockEntry
-	mu    sync.Mutex
+	locks     map[string]lockEntry
+	waiters   map[string][]*lockWaiter
+	lastToken uint64
+	mu        sync.Mutex
 }
 
 func NewLockManager() *LockManager {
 	return &LockManager{
-		locks: make(map[string]lockEntry),
+		locks:   make(map[string]lockEntry),
+		waiters: make(map[string][]*lockWaiter),
 	}
 }
 
+// TryLock acquires or renews the lock without waiting. A free tab is not
+// granted while other callers are queued in Lock, so waiters keep their turn.
 func (m *LockManager) TryLock(tabID, owner string, ttl time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if ok && time.Now().Before(l.expires) && l.owner != owner {
+	l, ok := m.active(tabID)
+	if ok && l.owner != owner {
 		return fmt.Errorf("tab %s is locked by %s for another %v", tabID, l.owner, time.Until(l.expires).Round(time.Second))
 	}
-
-	m.locks[tabID] = lockEntry{
-		owner:   owner,
-		expires: time.Now().Add(ttl),
+	if !ok && len(m.waiters[tabID]) > 0 {
+		return fmt.Errorf("tab %s has %d lock waiter(s) queued", tabID, len(m.waiters[tabID]))
 	}
+
+	m.acquire(tabID, owner, ttl)
 	return nil
 }
 
-func (m *LockManager) Unlock(tabID, owner string) error {
+// Lock acquires the lock, queueing behind earlier waiters until it is free
+// or ctx is done. Waiters are served in FIFO order.
+func (m *LockManager) Lock(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	m.mu.Lock()
+	if l, ok := m.active(tabID); (ok && l.owner == owner) || (!ok && len(m.waiters[tabID]) == 0) {
+		m.acquire(tabID, owner, ttl)
+		m.mu.Unlock()
+		return nil
+	}
+
+	w := &lockWaiter{ready: make(chan struct{}, 1)}
+	m.waiters[tabID] = append(m.waiters[tabID], w)
+	for {
+		l, held := m.active(tabID)
+		head := m.waiters[tabID][0] == w
+		if (held && l.owner == owner) || (!held && head) {
+			m.acquire(tabID, owner, ttl)
+			m.dequeue(tabID, w)
+			m.mu.Unlock()
+			return nil
+		}
+
+		// Only the head of the queue watches the holder's expiry; everyone
+		// else is woken when they move up.
+		var timer *time.Timer
+		var expired <-chan time.Time
+		if held && head {
+			timer = time.NewTimer(time.Until(l.expires))
+			expired = timer.C
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			m.mu.Lock()
+			m.dequeue(tabID, w)
+			m.mu.Unlock()
+			if held {
+				return fmt.Errorf("timed out waiting for tab %s lock held by %s: %w", tabID, l.owner, ctx.Err())
+			}
+			return fmt.Errorf("timed out waiting for tab %s lock: %w", tabID, ctx.Err())
+		case <-w.ready:
+		case <-expired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		m.mu.Lock()
+	}
+}
+
+// Renew extends a lease the owner still holds. Unlike TryLock it never
+// acquires a lock that has already lapsed.
+func (m *LockManager) Renew(tabID, owner string, ttl time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if !ok || time.Now().After(l.expires) {
-		delete(m.locks, tabID)
-		return nil
+	l, ok := m.active(tabID)
+	if !ok || l.owner != owner {
+		return fmt.Errorf("%w: tab %s is not locked by %s", ErrLockNotHeld, tabID, owner)
 	}
+	l.expires = time.Now().Add(ttl)
+	m.locks[tabID] = l
+	return nil
+}
+
+func (m *LockManager) Unlock(tabID, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	if l.owner != owner {
+	l, ok := m.active(tabID)
+	if ok && l.owner != owner {
 		return fmt.Errorf("cannot unlock: tab %s is locked by %s", tabID, l.owner)
 	}
 
 	delete(m.locks, tabID)
+	m.wakeHead(tabID)
 	return nil
 }
 
@@ -67,13 +151,59 @@ func (m *LockManager) Get(tabID string) *LockInfo {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	l, ok := m.locks[tabID]
-	if !ok || time.Now().After(l.expires) {
+	l, ok := m.active(tabID)
+	if !ok {
 		return nil
 	}
 
 	return &LockInfo{
 		Owner:     l.owner,
 		ExpiresAt: l.expires,
+		Token:     l.token,
+	}
+}
+
+func (m *LockManager) active(tabID string) (lockEntry, bool) {
+	l, ok := m.locks[tabID]
+	if !ok || time.Now().After(l.expires) {
+		return lockEntry{}, false
+	}
+	return l, true
+}
+
+// acquire grants or renews the lock. Renewal keeps the fencing token; a new
+// acquisition always mints a higher one.
+func (m *LockManager) acquire(tabID, owner string, ttl time.Duration) {
+	l, ok := m.active(tabID)
+	if !ok || l.owner != owner {
+		m.lastToken++
+		l = lockEntry{owner: owner, token: m.lastToken}
+	}
+	l.expires = time.Now().Add(ttl)
+	m.locks[tabID] = l
+}
+
+func (m *LockManager) dequeue(tabID string, w *lockWaiter) {
+	q := m.waiters[tabID]
+	for i, other := range q {
+		if other == w {
+			q = append(q[:i:i], q[i+1:]...)
+			break
+		}
+	}
+	if len(q) == 0 {
+		delete(m.waiters, tabID)
+		return
+	}
+	m.waiters[tabID] = q
+	m.wakeHead(tabID)
+}
+
+func (m *LockManager) wakeHead(tabID string) {
+	if q := m.waiters[tabID]; len(q) > 0 {
+		select {
+		case q[0].ready <- struct{}{}:
+		default:
+		}
 	}
 }
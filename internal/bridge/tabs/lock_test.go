@@ -0,0 +1,157 @@
+package tabs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockManager_FencingToken(t *testing.T) {
+	m := NewLockManager()
+
+	_ = m.TryLock("tab1", "agent1", time.Hour)
+	first := m.Get("tab1").Token
+	if first == 0 {
+		t.Fatal("expected a non-zero fencing token")
+	}
+
+	_ = m.TryLock("tab1", "agent1", time.Hour)
+	if got := m.Get("tab1").Token; got != first {
+		t.Fatalf("renewal changed token: %d -> %d", first, got)
+	}
+
+	_ = m.Unlock("tab1", "agent1")
+	_ = m.TryLock("tab1", "agent2", time.Hour)
+	if got := m.Get("tab1").Token; got <= first {
+		t.Fatalf("new lease token %d not greater than %d", got, first)
+	}
+}
+
+func TestLockManager_Renew(t *testing.T) {
+	m := NewLockManager()
+
+	if err := m.Renew("tab1", "agent1", time.Hour); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("renew of free tab: err = %v", err)
+	}
+
+	_ = m.TryLock("tab1", "agent1", 50*time.Millisecond)
+	token := m.Get("tab1").Token
+	if err := m.Renew("tab1", "agent1", time.Hour); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if info := m.Get("tab1"); time.Until(info.ExpiresAt) < time.Minute || info.Token != token {
+		t.Fatalf("renew = %+v, want extended lease with token %d", info, token)
+	}
+	if err := m.Renew("tab1", "agent2", time.Hour); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("renew by other owner: err = %v", err)
+	}
+}
+
+func TestLockManager_WaitFIFO(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "holder", time.Hour)
+
+	order := make(chan string, 2)
+	var wg sync.WaitGroup
+	for i, owner := range []string{"first", "second"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Lock(context.Background(), "tab1", owner, time.Hour); err != nil {
+				t.Errorf("%s: %v", owner, err)
+				return
+			}
+			order <- owner
+			_ = m.Unlock("tab1", owner)
+		}()
+		waitForWaiters(t, m, "tab1", i+1)
+	}
+
+	_ = m.Unlock("tab1", "holder")
+	wg.Wait()
+	close(order)
+	var got []string
+	for owner := range order {
+		got = append(got, owner)
+	}
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Fatalf("acquisition order = %v", got)
+	}
+}
+
+func TestLockManager_TryLockDoesNotJumpQueue(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "holder", time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Lock(ctx, "tab1", "waiter", time.Hour) }()
+	waitForWaiters(t, m, "tab1", 1)
+
+	// Expire the holder without waking the waiter: the tab is free but
+	// someone is queued for it.
+	m.mu.Lock()
+	l := m.locks["tab1"]
+	l.expires = time.Now().Add(-time.Second)
+	m.locks["tab1"] = l
+	m.mu.Unlock()
+
+	if err := m.TryLock("tab1", "intruder", time.Hour); err == nil {
+		t.Fatal("TryLock jumped the wait queue")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("waiter err = %v", err)
+	}
+	if err := m.TryLock("tab1", "intruder", time.Hour); err != nil {
+		t.Fatalf("lock after waiter left: %v", err)
+	}
+}
+
+func TestLockManager_WaitForExpiry(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "holder", 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := m.Lock(ctx, "tab1", "waiter", time.Hour); err != nil {
+		t.Fatalf("wait for expiry: %v", err)
+	}
+	if got := m.Get("tab1").Owner; got != "waiter" {
+		t.Fatalf("owner = %q", got)
+	}
+}
+
+func TestLockManager_WaitTimeout(t *testing.T) {
+	m := NewLockManager()
+	_ = m.TryLock("tab1", "holder", time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Lock(ctx, "tab1", "waiter", time.Hour); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+
+	// The abandoned waiter must not block later callers.
+	_ = m.Unlock("tab1", "holder")
+	if err := m.TryLock("tab1", "next", time.Hour); err != nil {
+		t.Fatalf("lock after abandoned wait: %v", err)
+	}
+}
+
+func waitForWaiters(t *testing.T, m *LockManager, tabID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		queued := len(m.waiters[tabID])
+		m.mu.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d lock waiters", n)
+}
@@ -6,7 +6,10 @@ import (
 	"log/slog"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 // TabExecutor provides safe parallel execution across tabs.
@@ -15,6 +18,8 @@ type TabExecutor struct {
 	tabLocks    map[string]*sync.Mutex
 	mu          sync.Mutex
 	maxParallel int
+	// queued counts tasks waiting for a slot or their tab's lock.
+	queued atomic.Int64
 }
 
 func NewTabExecutor(maxParallel int) *TabExecutor {
@@ -62,11 +67,26 @@ func (te *TabExecutor) Execute(ctx context.Context, tabID string, task func(ctx
 		return ctx.Err()
 	}
 
+	// tab.queue covers the wait for a global slot and the per-tab lock, so
+	// traces separate executor contention from the CDP work itself.
+	_, queued := tracing.Start(ctx, "tab.queue", tracing.String("pinchtab.tab_id", tabID))
+	te.queued.Add(1)
+	waiting := true
+	stopWaiting := func() {
+		if waiting {
+			waiting = false
+			te.queued.Add(-1)
+		}
+	}
+	defer stopWaiting()
 	select {
 	case te.semaphore <- struct{}{}:
 		defer func() { <-te.semaphore }()
 	case <-ctx.Done():
-		return fmt.Errorf("tab %s: waiting for execution slot: %w", tabID, ctx.Err())
+		err := fmt.Errorf("tab %s: waiting for execution slot: %w", tabID, ctx.Err())
+		queued.RecordError(err)
+		queued.End()
+		return err
 	}
 
 	tabMu := te.tabMutex(tabID)
@@ -84,10 +104,19 @@ func (te *TabExecutor) Execute(ctx context.Context, tabID string, task func(ctx
 			<-locked
 			tabMu.Unlock()
 		}()
-		return fmt.Errorf("tab %s: waiting for tab lock: %w", tabID, ctx.Err())
+		err := fmt.Errorf("tab %s: waiting for tab lock: %w", tabID, ctx.Err())
+		queued.RecordError(err)
+		queued.End()
+		return err
 	}
-
-	return te.safeRun(ctx, tabID, task)
+	stopWaiting()
+	queued.End()
+
+	ctx, span := tracing.Start(ctx, "tab.execute", tracing.String("pinchtab.tab_id", tabID))
+	defer span.End()
+	err := te.safeRun(ctx, tabID, task)
+	span.RecordError(err)
+	return err
 }
 
 func (te *TabExecutor) safeRun(ctx context.Context, tabID string, task func(ctx context.Context) error) (err error) {
@@ -126,8 +155,9 @@ func (te *TabExecutor) ActiveTabs() int {
 type ExecutorStats struct {
 	MaxParallel   int `json:"maxParallel"`
 	ActiveTabs    int `json:"activeTabs"`
-	SemaphoreUsed int `json:"semaphoreUsed"`
+	SemaphoreUsed int `json:"semaphoreUsed"` // actions running now
 	SemaphoreFree int `json:"semaphoreFree"`
+	Queued        int `json:"queued"` // actions waiting for a slot or their tab
 }
 
 func (te *TabExecutor) Stats() ExecutorStats {
@@ -137,6 +167,7 @@ func (te *TabExecutor) Stats() ExecutorStats {
 		ActiveTabs:    te.ActiveTabs(),
 		SemaphoreUsed: used,
 		SemaphoreFree: te.maxParallel - used,
+		Queued:        int(te.queued.Load()),
 	}
 }
 
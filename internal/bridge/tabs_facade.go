@@ -8,6 +8,8 @@ import (
 
 const DefaultLockTimeout = bridgetabs.DefaultLockTimeout
 
+var ErrLockNotHeld = bridgetabs.ErrLockNotHeld
+
 type DialogState = bridgetabs.DialogState
 type DialogManager = bridgetabs.DialogManager
 type DialogResult = bridgetabs.DialogResult
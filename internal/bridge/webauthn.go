@@ -0,0 +1,525 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/webauthn"
+	"github.com/chromedp/chromedp"
+)
+
+// Virtual authenticators live on a tab's CDP target and vanish with it, but
+// the credentials they hold are mirrored into a store in the profile
+// directory (webAuthnStoreFile). A new authenticator is seeded from that
+// store, so a passkey an agent registered survives instance restarts.
+// Tabs in their own browser context (isolated sessions, pool proxies) use a
+// store of that context instead, kept in memory and dropped with the
+// context, so one session's passkeys never reach another.
+
+// webAuthnStoreFile holds the persisted credentials, private keys included,
+// inside the profile directory.
+const webAuthnStoreFile = "pinchtab-webauthn.json"
+
+// WebAuthnCredential is a virtual authenticator credential. PrivateKey is the
+// base64 PKCS#8 ECDSA P-256 key; CredentialID and UserHandle are base64.
+type WebAuthnCredential = webauthn.Credential
+
+// WebAuthnAuthenticatorOptions configures a virtual authenticator. A passkey
+// (platform authenticator) is ctap2 over the internal transport with resident
+// keys and user verification.
+type WebAuthnAuthenticatorOptions struct {
+	Protocol            string `json:"protocol,omitempty"`  // ctap2 (default) or u2f
+	Transport           string `json:"transport,omitempty"` // internal (default), usb, nfc, ble
+	HasResidentKey      bool   `json:"hasResidentKey"`
+	HasUserVerification bool   `json:"hasUserVerification"`
+	// IsUserVerified defaults to HasUserVerification; UserPresence (the
+	// authenticator answers presence checks on its own) defaults to true.
+	IsUserVerified *bool `json:"isUserVerified,omitempty"`
+	UserPresence   *bool `json:"userPresence,omitempty"`
+}
+
+// VirtualAuthenticator describes a virtual authenticator attached to a tab.
+type VirtualAuthenticator struct {
+	ID                  string `json:"id"`
+	TabID               string `json:"tabId"`
+	Protocol            string `json:"protocol"`
+	Transport           string `json:"transport"`
+	HasResidentKey      bool   `json:"hasResidentKey"`
+	HasUserVerification bool   `json:"hasUserVerification"`
+	IsUserVerified      bool   `json:"isUserVerified"`
+	UserPresence        bool   `json:"userPresence"`
+}
+
+// ErrAuthenticatorNotFound is returned for an authenticator id the tab does
+// not have.
+var ErrAuthenticatorNotFound = errors.New("virtual authenticator not found")
+
+// Normalize fills defaults and validates the options.
+func (o WebAuthnAuthenticatorOptions) Normalize() (WebAuthnAuthenticatorOptions, error) {
+	if o.Protocol == "" {
+		o.Protocol = string(webauthn.AuthenticatorProtocolCtap2)
+	}
+	if o.Transport == "" {
+		o.Transport = string(webauthn.AuthenticatorTransportInternal)
+	}
+	switch webauthn.AuthenticatorProtocol(o.Protocol) {
+	case webauthn.AuthenticatorProtocolCtap2:
+	case webauthn.AuthenticatorProtocolU2f:
+		if o.HasResidentKey || o.HasUserVerification {
+			return o, fmt.Errorf("u2f authenticators support neither resident keys nor user verification")
+		}
+	default:
+		return o, fmt.Errorf("invalid protocol %q (must be ctap2 or u2f)", o.Protocol)
+	}
+	switch webauthn.AuthenticatorTransport(o.Transport) {
+	case webauthn.AuthenticatorTransportInternal, webauthn.AuthenticatorTransportUsb,
+		webauthn.AuthenticatorTransportNfc, webauthn.AuthenticatorTransportBle:
+	default:
+		return o, fmt.Errorf("invalid transport %q (must be internal, usb, nfc, or ble)", o.Transport)
+	}
+	if o.IsUserVerified == nil {
+		v := o.HasUserVerification
+		o.IsUserVerified = &v
+	}
+	if o.UserPresence == nil {
+		v := true
+		o.UserPresence = &v
+	}
+	return o, nil
+}
+
+// webAuthnTab is the per-tab authenticator state. listening records that the
+// credential event listener is attached to the tab's target.
+type webAuthnTab struct {
+	listening      bool
+	authenticators map[string]*VirtualAuthenticator
+}
+
+// AddVirtualAuthenticator attaches a virtual authenticator to the tab of ctx
+// and seeds it with the credentials it can hold from the store of the tab's
+// browser context. It returns the
+// authenticator and the number of credentials restored.
+func (b *Bridge) AddVirtualAuthenticator(ctx context.Context, tabID string, opts WebAuthnAuthenticatorOptions) (*VirtualAuthenticator, int, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := b.enableWebAuthn(ctx, tabID); err != nil {
+		return nil, 0, err
+	}
+
+	var id webauthn.AuthenticatorID
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		id, err = webauthn.AddVirtualAuthenticator(&webauthn.VirtualAuthenticatorOptions{
+			Protocol:                    webauthn.AuthenticatorProtocol(opts.Protocol),
+			Transport:                   webauthn.AuthenticatorTransport(opts.Transport),
+			HasResidentKey:              opts.HasResidentKey,
+			HasUserVerification:         opts.HasUserVerification,
+			IsUserVerified:              *opts.IsUserVerified,
+			AutomaticPresenceSimulation: *opts.UserPresence,
+		}).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, 0, fmt.Errorf("add virtual authenticator: %w", err)
+	}
+
+	auth := &VirtualAuthenticator{
+		ID:                  string(id),
+		TabID:               tabID,
+		Protocol:            opts.Protocol,
+		Transport:           opts.Transport,
+		HasResidentKey:      opts.HasResidentKey,
+		HasUserVerification: opts.HasUserVerification,
+		IsUserVerified:      *opts.IsUserVerified,
+		UserPresence:        *opts.UserPresence,
+	}
+	b.webAuthnMu.Lock()
+	if t := b.webAuthnTabs[tabID]; t != nil {
+		t.authenticators[auth.ID] = auth
+	}
+	b.webAuthnMu.Unlock()
+
+	restored := 0
+	for _, cred := range b.webAuthnStoreFor(tabID).credentials(auth.Protocol) {
+		if cred.IsResidentCredential && !auth.HasResidentKey {
+			continue
+		}
+		if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return webauthn.AddCredential(id, cred).Do(ctx)
+		})); err != nil {
+			slog.Warn("webauthn: restore credential failed", "rpId", cred.RpID, "err", err)
+			continue
+		}
+		restored++
+	}
+	return auth, restored, nil
+}
+
+// RemoveVirtualAuthenticator detaches an authenticator. Its credentials stay
+// in the profile store.
+func (b *Bridge) RemoveVirtualAuthenticator(ctx context.Context, tabID, authenticatorID string) error {
+	if _, err := b.virtualAuthenticator(tabID, authenticatorID); err != nil {
+		return err
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return webauthn.RemoveVirtualAuthenticator(webauthn.AuthenticatorID(authenticatorID)).Do(ctx)
+	})); err != nil {
+		return fmt.Errorf("remove virtual authenticator: %w", err)
+	}
+	b.webAuthnMu.Lock()
+	if t := b.webAuthnTabs[tabID]; t != nil {
+		delete(t.authenticators, authenticatorID)
+	}
+	b.webAuthnMu.Unlock()
+	return nil
+}
+
+// VirtualAuthenticators lists the tab's authenticators by id.
+func (b *Bridge) VirtualAuthenticators(tabID string) []VirtualAuthenticator {
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	t := b.webAuthnTabs[tabID]
+	if t == nil {
+		return []VirtualAuthenticator{}
+	}
+	out := make([]VirtualAuthenticator, 0, len(t.authenticators))
+	for _, a := range t.authenticators {
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// SetAuthenticatorPresence toggles whether the authenticator answers user
+// presence checks on its own and whether user verification succeeds. Nil
+// leaves a setting unchanged.
+func (b *Bridge) SetAuthenticatorPresence(ctx context.Context, tabID, authenticatorID string, userPresence, userVerified *bool) (*VirtualAuthenticator, error) {
+	if _, err := b.virtualAuthenticator(tabID, authenticatorID); err != nil {
+		return nil, err
+	}
+	id := webauthn.AuthenticatorID(authenticatorID)
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if userPresence != nil {
+			if err := webauthn.SetAutomaticPresenceSimulation(id, *userPresence).Do(ctx); err != nil {
+				return err
+			}
+		}
+		if userVerified != nil {
+			return webauthn.SetUserVerified(id, *userVerified).Do(ctx)
+		}
+		return nil
+	})); err != nil {
+		return nil, fmt.Errorf("set authenticator presence: %w", err)
+	}
+
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	t := b.webAuthnTabs[tabID]
+	if t == nil || t.authenticators[authenticatorID] == nil {
+		return nil, ErrAuthenticatorNotFound
+	}
+	auth := t.authenticators[authenticatorID]
+	if userPresence != nil {
+		auth.UserPresence = *userPresence
+	}
+	if userVerified != nil {
+		auth.IsUserVerified = *userVerified
+	}
+	out := *auth
+	return &out, nil
+}
+
+// WebAuthnCredentials lists the credentials an authenticator holds.
+func (b *Bridge) WebAuthnCredentials(ctx context.Context, tabID, authenticatorID string) ([]*WebAuthnCredential, error) {
+	if _, err := b.virtualAuthenticator(tabID, authenticatorID); err != nil {
+		return nil, err
+	}
+	var creds []*webauthn.Credential
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		creds, err = webauthn.GetCredentials(webauthn.AuthenticatorID(authenticatorID)).Do(ctx)
+		return err
+	})); err != nil {
+		return nil, fmt.Errorf("get credentials: %w", err)
+	}
+	if creds == nil {
+		creds = []*webauthn.Credential{}
+	}
+	return creds, nil
+}
+
+// AddWebAuthnCredential imports a credential into an authenticator and the
+// profile store.
+func (b *Bridge) AddWebAuthnCredential(ctx context.Context, tabID, authenticatorID string, cred *WebAuthnCredential) error {
+	auth, err := b.virtualAuthenticator(tabID, authenticatorID)
+	if err != nil {
+		return err
+	}
+	if cred == nil || cred.CredentialID == "" || cred.PrivateKey == "" || cred.RpID == "" {
+		return fmt.Errorf("credential requires credentialId, rpId and privateKey")
+	}
+	if cred.IsResidentCredential && !auth.HasResidentKey {
+		return fmt.Errorf("authenticator %s does not support resident credentials", authenticatorID)
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return webauthn.AddCredential(webauthn.AuthenticatorID(authenticatorID), cred).Do(ctx)
+	})); err != nil {
+		return fmt.Errorf("add credential: %w", err)
+	}
+	b.webAuthnStoreFor(tabID).put(auth.Protocol, cred)
+	return nil
+}
+
+// RemoveWebAuthnCredential deletes a credential from an authenticator and the
+// profile store.
+func (b *Bridge) RemoveWebAuthnCredential(ctx context.Context, tabID, authenticatorID, credentialID string) error {
+	if _, err := b.virtualAuthenticator(tabID, authenticatorID); err != nil {
+		return err
+	}
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return webauthn.RemoveCredential(webauthn.AuthenticatorID(authenticatorID), credentialID).Do(ctx)
+	})); err != nil {
+		return fmt.Errorf("remove credential: %w", err)
+	}
+	b.webAuthnStoreFor(tabID).remove(credentialID)
+	return nil
+}
+
+func (b *Bridge) virtualAuthenticator(tabID, authenticatorID string) (VirtualAuthenticator, error) {
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	if t := b.webAuthnTabs[tabID]; t != nil {
+		if a := t.authenticators[authenticatorID]; a != nil {
+			return *a, nil
+		}
+	}
+	return VirtualAuthenticator{}, ErrAuthenticatorNotFound
+}
+
+// enableWebAuthn enables the WebAuthn domain on the tab (without Chrome's
+// account selector UI) and, once per tab, starts mirroring credential events
+// into the profile store.
+func (b *Bridge) enableWebAuthn(ctx context.Context, tabID string) error {
+	if err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return webauthn.Enable().WithEnableUI(false).Do(ctx)
+	})); err != nil {
+		return fmt.Errorf("enable webauthn: %w", err)
+	}
+
+	store := b.webAuthnStoreFor(tabID)
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	if b.webAuthnTabs == nil {
+		b.webAuthnTabs = make(map[string]*webAuthnTab)
+	}
+	t := b.webAuthnTabs[tabID]
+	if t == nil {
+		t = &webAuthnTab{authenticators: make(map[string]*VirtualAuthenticator)}
+		b.webAuthnTabs[tabID] = t
+	}
+	if t.listening {
+		return nil
+	}
+	t.listening = true
+	chromedp.ListenTarget(ctx, func(ev any) {
+		switch e := ev.(type) {
+		case *webauthn.EventCredentialAdded:
+			store.put(b.authenticatorProtocol(tabID, e.AuthenticatorID), e.Credential)
+		case *webauthn.EventCredentialUpdated:
+			store.put(b.authenticatorProtocol(tabID, e.AuthenticatorID), e.Credential)
+		case *webauthn.EventCredentialAsserted:
+			// Keeps the signature counter current; relying parties reject
+			// a counter that goes backwards.
+			store.put(b.authenticatorProtocol(tabID, e.AuthenticatorID), e.Credential)
+		case *webauthn.EventCredentialDeleted:
+			store.remove(e.CredentialID)
+		}
+	})
+	return nil
+}
+
+func (b *Bridge) authenticatorProtocol(tabID string, id webauthn.AuthenticatorID) string {
+	if a, err := b.virtualAuthenticator(tabID, string(id)); err == nil {
+		return a.Protocol
+	}
+	return string(webauthn.AuthenticatorProtocolCtap2)
+}
+
+// dropWebAuthnTab forgets a closed tab's authenticators.
+func (b *Bridge) dropWebAuthnTab(tabID string) {
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	delete(b.webAuthnTabs, tabID)
+}
+
+// webAuthnStoreFor returns the credential store of the tab's browser
+// context: the profile store for the default context, else the context's
+// own in-memory store.
+func (b *Bridge) webAuthnStoreFor(tabID string) *webAuthnCredentialStore {
+	contextID := b.tabBrowserContext(tabID)
+	if contextID == "" {
+		return b.webAuthnStore()
+	}
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	if b.ctxWebAuthnCreds == nil {
+		b.ctxWebAuthnCreds = make(map[cdp.BrowserContextID]*webAuthnCredentialStore)
+	}
+	s := b.ctxWebAuthnCreds[contextID]
+	if s == nil {
+		s = newWebAuthnCredentialStore("")
+		b.ctxWebAuthnCreds[contextID] = s
+	}
+	return s
+}
+
+// dropContextWebAuthn forgets the credentials of a disposed browser context.
+func (b *Bridge) dropContextWebAuthn(contextID cdp.BrowserContextID) {
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	delete(b.ctxWebAuthnCreds, contextID)
+}
+
+// webAuthnStore returns the credential store for the current profile
+// directory; without one, credentials are kept in memory only.
+func (b *Bridge) webAuthnStore() *webAuthnCredentialStore {
+	path := ""
+	if b.Config != nil && b.Config.ProfileDir != "" {
+		path = filepath.Join(b.Config.ProfileDir, webAuthnStoreFile)
+	}
+	b.webAuthnMu.Lock()
+	defer b.webAuthnMu.Unlock()
+	if b.webAuthnCreds == nil || b.webAuthnCreds.path != path {
+		b.webAuthnCreds = newWebAuthnCredentialStore(path)
+	}
+	return b.webAuthnCreds
+}
+
+// storedWebAuthnCredential is one entry of the profile store. Protocol is the
+// protocol of the authenticator that held the credential; u2f and ctap2
+// credentials are not interchangeable.
+type storedWebAuthnCredential struct {
+	Protocol   string               `json:"protocol"`
+	Credential *webauthn.Credential `json:"credential"`
+}
+
+type webAuthnCredentialStore struct {
+	mu    sync.Mutex
+	path  string
+	creds map[string]storedWebAuthnCredential
+}
+
+func newWebAuthnCredentialStore(path string) *webAuthnCredentialStore {
+	s := &webAuthnCredentialStore{path: path, creds: make(map[string]storedWebAuthnCredential)}
+	if path == "" {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("webauthn: read credential store", "path", path, "err", err)
+		}
+		return s
+	}
+	var file struct {
+		Credentials []storedWebAuthnCredential `json:"credentials"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		slog.Warn("webauthn: parse credential store", "path", path, "err", err)
+		return s
+	}
+	for _, c := range file.Credentials {
+		if c.Credential != nil && c.Credential.CredentialID != "" {
+			s.creds[c.Credential.CredentialID] = c
+		}
+	}
+	return s
+}
+
+// credentials returns copies of the stored credentials for protocol, ordered
+// by credential id.
+func (s *webAuthnCredentialStore) credentials(protocol string) []*webauthn.Credential {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*webauthn.Credential, 0, len(s.creds))
+	for _, c := range s.creds {
+		if c.Protocol == protocol {
+			cred := *c.Credential
+			out = append(out, &cred)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CredentialID < out[j].CredentialID })
+	return out
+}
+
+func (s *webAuthnCredentialStore) put(protocol string, cred *webauthn.Credential) {
+	if cred == nil || cred.CredentialID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *cred
+	// Events other than credentialAdded may omit the private key; keep the
+	// one already stored.
+	if prev, ok := s.creds[c.CredentialID]; ok && c.PrivateKey == "" {
+		c.PrivateKey = prev.Credential.PrivateKey
+	}
+	if c.PrivateKey == "" {
+		return
+	}
+	s.creds[c.CredentialID] = storedWebAuthnCredential{Protocol: protocol, Credential: &c}
+	s.saveLocked()
+}
+
+func (s *webAuthnCredentialStore) remove(credentialID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.creds[credentialID]; !ok {
+		return
+	}
+	delete(s.creds, credentialID)
+	s.saveLocked()
+}
+
+func (s *webAuthnCredentialStore) saveLocked() {
+	if s.path == "" {
+		return
+	}
+	ids := make([]string, 0, len(s.creds))
+	for id := range s.creds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var file struct {
+		Credentials []storedWebAuthnCredential `json:"credentials"`
+	}
+	for _, id := range ids {
+		file.Credentials = append(file.Credentials, s.creds[id])
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		slog.Error("webauthn: marshal credential store", "err", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		slog.Error("webauthn: mkdir", "err", err)
+		return
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		slog.Error("webauthn: write credential store", "path", tmp, "err", err)
+		return
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		slog.Error("webauthn: replace credential store", "path", s.path, "err", err)
+	}
+}
@@ -0,0 +1,101 @@
+package bridge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chromedp/cdproto/webauthn"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestWebAuthnAuthenticatorOptionsNormalize(t *testing.T) {
+	opts, err := WebAuthnAuthenticatorOptions{HasResidentKey: true, HasUserVerification: true}.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Protocol != "ctap2" || opts.Transport != "internal" || !*opts.IsUserVerified || !*opts.UserPresence {
+		t.Fatalf("defaults = %+v", opts)
+	}
+
+	for name, o := range map[string]WebAuthnAuthenticatorOptions{
+		"bad protocol":   {Protocol: "fido3"},
+		"bad transport":  {Transport: "carrier-pigeon"},
+		"u2f resident":   {Protocol: "u2f", HasResidentKey: true},
+		"u2f verifiable": {Protocol: "u2f", HasUserVerification: true},
+	} {
+		if _, err := o.Normalize(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestWebAuthnCredentialStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile", webAuthnStoreFile)
+
+	s := newWebAuthnCredentialStore(path)
+	s.put("ctap2", &webauthn.Credential{CredentialID: "c1", RpID: "example.com", PrivateKey: "key1", IsResidentCredential: true})
+	s.put("u2f", &webauthn.Credential{CredentialID: "c2", RpID: "example.com", PrivateKey: "key2"})
+	// Assertion events may carry no private key; the stored one is kept.
+	s.put("ctap2", &webauthn.Credential{CredentialID: "c1", RpID: "example.com", SignCount: 7})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("store mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	reloaded := newWebAuthnCredentialStore(path)
+	ctap := reloaded.credentials("ctap2")
+	if len(ctap) != 1 || ctap[0].PrivateKey != "key1" || ctap[0].SignCount != 7 {
+		t.Fatalf("ctap2 credentials = %+v", ctap)
+	}
+	if u2f := reloaded.credentials("u2f"); len(u2f) != 1 || u2f[0].CredentialID != "c2" {
+		t.Fatalf("u2f credentials = %+v", u2f)
+	}
+
+	reloaded.remove("c1")
+	if got := newWebAuthnCredentialStore(path).credentials("ctap2"); len(got) != 0 {
+		t.Fatalf("removed credential still stored: %+v", got)
+	}
+}
+
+func TestWebAuthnCredentialStoreIgnoresKeylessCredential(t *testing.T) {
+	s := newWebAuthnCredentialStore("")
+	s.put("ctap2", &webauthn.Credential{CredentialID: "c1", RpID: "example.com"})
+	if got := s.credentials("ctap2"); len(got) != 0 {
+		t.Fatalf("credential without private key stored: %+v", got)
+	}
+}
+
+func TestWebAuthnStorePerBrowserContext(t *testing.T) {
+	dir := t.TempDir()
+	b := &Bridge{
+		Config: &config.RuntimeConfig{ProfileDir: dir},
+		sessionContexts: map[string]*sessionContext{
+			"ses_a": {id: "ctx-a", tabs: map[string]struct{}{"tabA": {}}},
+			"ses_b": {id: "ctx-b", tabs: map[string]struct{}{"tabB": {}}},
+		},
+	}
+	b.webAuthnStoreFor("tabA").put("ctap2", &webauthn.Credential{CredentialID: "a1", RpID: "example.com", PrivateKey: "keyA"})
+
+	if got := b.webAuthnStoreFor("tabB").credentials("ctap2"); len(got) != 0 {
+		t.Fatalf("session B sees session A's credentials: %+v", got)
+	}
+	if got := b.webAuthnStoreFor("tab0").credentials("ctap2"); len(got) != 0 {
+		t.Fatalf("default context sees session A's credentials: %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, webAuthnStoreFile)); !os.IsNotExist(err) {
+		t.Fatalf("session credential persisted to the profile store: %v", err)
+	}
+	if got := b.webAuthnStoreFor("tabA").credentials("ctap2"); len(got) != 1 {
+		t.Fatalf("session A credentials = %+v", got)
+	}
+
+	b.dropContextWebAuthn("ctx-a")
+	if got := b.webAuthnStoreFor("tabA").credentials("ctap2"); len(got) != 0 {
+		t.Fatalf("disposed context kept its credentials: %+v", got)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/authn"
 )
 
 const (
@@ -48,6 +50,8 @@ type sessionState struct {
 	LastSeen      time.Time
 	ElevatedUntil time.Time
 	TokenHash     [32]byte
+	// Identity is set for sessions created by single sign-on.
+	Identity *authn.Identity
 }
 
 type persistedSessions struct {
@@ -56,11 +60,12 @@ type persistedSessions struct {
 }
 
 type persistedSessionRecord struct {
-	ID            string    `json:"id"`
-	CreatedAt     time.Time `json:"createdAt"`
-	LastSeen      time.Time `json:"lastSeen"`
-	ElevatedUntil time.Time `json:"elevatedUntil,omitempty"`
-	TokenHash     string    `json:"tokenHash"`
+	ID            string          `json:"id"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	LastSeen      time.Time       `json:"lastSeen"`
+	ElevatedUntil time.Time       `json:"elevatedUntil,omitempty"`
+	TokenHash     string          `json:"tokenHash"`
+	Identity      *authn.Identity `json:"identity,omitempty"`
 }
 
 func NewManager(cfg Config) *Manager {
@@ -76,6 +81,17 @@ func NewManager(cfg Config) *Manager {
 }
 
 func (m *Manager) Create(token string) (string, error) {
+	return m.create(token, nil)
+}
+
+// CreateWithIdentity creates a session for a single sign-on user. Like any
+// dashboard session it is bound to token, so rotating the server token
+// signs SSO users out too.
+func (m *Manager) CreateWithIdentity(token string, identity authn.Identity) (string, error) {
+	return m.create(token, &identity)
+}
+
+func (m *Manager) create(token string, identity *authn.Identity) (string, error) {
 	if m == nil {
 		return "", nil
 	}
@@ -89,6 +105,7 @@ func (m *Manager) Create(token string) (string, error) {
 		CreatedAt: now,
 		LastSeen:  now,
 		TokenHash: hashToken(token),
+		Identity:  identity,
 	}
 	m.saveLocked()
 	m.mu.Unlock()
@@ -150,6 +167,20 @@ func (m *Manager) IsElevated(sessionID, token string) bool {
 	})
 }
 
+// Identity returns the single sign-on identity of a valid session. It is
+// false for sessions created with the server token.
+func (m *Manager) Identity(sessionID, token string) (authn.Identity, bool) {
+	var identity authn.Identity
+	ok := m.withValidSession(sessionID, token, func(_ string, _ time.Time, state sessionState) bool {
+		if state.Identity == nil {
+			return false
+		}
+		identity = *state.Identity
+		return true
+	})
+	return identity, ok
+}
+
 func (m *Manager) Revoke(sessionID string) {
 	if m == nil {
 		return
@@ -283,6 +314,7 @@ func (m *Manager) loadPersisted() {
 			LastSeen:      record.LastSeen,
 			ElevatedUntil: record.ElevatedUntil,
 			TokenHash:     hash,
+			Identity:      record.Identity,
 		}
 		if !m.persistElevationAcrossRestart {
 			state.ElevatedUntil = time.Time{}
@@ -327,6 +359,7 @@ func (m *Manager) saveLocked() {
 			LastSeen:      state.LastSeen,
 			ElevatedUntil: state.ElevatedUntil,
 			TokenHash:     hex.EncodeToString(state.TokenHash[:]),
+			Identity:      state.Identity,
 		}
 		if !m.persistElevationAcrossRestart {
 			record.ElevatedUntil = time.Time{}
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/pinchtab/pinchtab/internal/authn"
 )
 
 func TestIsElevatedPersistsExpiryDeletion(t *testing.T) {
@@ -250,3 +252,32 @@ func TestSessionManagerClearsElevationAcrossRestartByDefault(t *testing.T) {
 		t.Fatal("IsElevated() after restart = true, want false when persistence across restart is disabled")
 	}
 }
+
+func TestSessionManagerIdentityPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dashboard-auth-sessions.json")
+	cfg := Config{Persist: true, PersistPath: path}
+	mgr := NewManager(cfg)
+
+	tokenSession, err := mgr.Create("secret")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, ok := mgr.Identity(tokenSession, "secret"); ok {
+		t.Fatal("Identity() for a token session = true, want false")
+	}
+
+	want := authn.Identity{Issuer: "https://idp.example.com", Subject: "u-1", Email: "ada@example.com", Role: authn.RoleAdmin}
+	ssoSession, err := mgr.CreateWithIdentity("secret", want)
+	if err != nil {
+		t.Fatalf("CreateWithIdentity() error = %v", err)
+	}
+
+	restarted := NewManager(cfg)
+	got, ok := restarted.Identity(ssoSession, "secret")
+	if !ok || got.Subject != want.Subject || got.Role != authn.RoleAdmin {
+		t.Fatalf("Identity() after restart = %+v, %v", got, ok)
+	}
+	if _, ok := restarted.Identity(ssoSession, "rotated"); ok {
+		t.Fatal("Identity() after token rotation = true, want false")
+	}
+}
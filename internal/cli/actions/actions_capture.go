@@ -117,8 +117,8 @@ func Capture(client *http.Client, base, token string, cmd *cobra.Command) {
 	if resp.Title != "" {
 		output.Value(fmt.Sprintf("title: %s", resp.Title))
 	}
-	output.Value(fmt.Sprintf("epoch: %s navigated=%v duration=%dms",
-		resp.Epoch.DomEpoch, resp.Pairing.Navigated, resp.Pairing.CaptureDurationMs))
+	output.Value(fmt.Sprintf("epoch: %s navigated=%v domMutated=%v duration=%dms",
+		resp.Epoch.DomEpoch, resp.Pairing.Navigated, resp.Pairing.DomMutated, resp.Pairing.CaptureDurationMs))
 	output.Value(fmt.Sprintf("viewport: %.0fx%.0f dpr=%g space=%s",
 		resp.Image.Viewport.W, resp.Image.Viewport.H, resp.Image.DPR, resp.Image.CoordinateSpace))
 
@@ -152,6 +152,7 @@ type captureResponse struct {
 	} `json:"epoch"`
 	Pairing struct {
 		Navigated         bool  `json:"navigated"`
+		DomMutated        bool  `json:"domMutated"`
 		CaptureDurationMs int64 `json:"captureDurationMs"`
 	} `json:"pairing"`
 	Image struct {
@@ -156,6 +156,11 @@ func buildNavigateRequest(url string, cmd *cobra.Command) navigateRequest {
 	if v, _ := cmd.Flags().GetBool("dismiss-banners"); v {
 		body["dismissBanners"] = true
 	}
+	// A pool proxy always means a new tab.
+	if pool, _ := cmd.Flags().GetString("proxy-pool"); pool != "" {
+		body["proxyPool"] = pool
+		newTab = true
+	}
 	tabID, _ := cmd.Flags().GetString("tab")
 	path := "/navigate"
 	explicitTab := cmd.Flags().Changed("tab")
@@ -15,6 +15,7 @@ func newNavigateCmd() *cobra.Command {
 	cmd.Flags().Bool("block-images", false, "")
 	cmd.Flags().Bool("block-ads", false, "")
 	cmd.Flags().Bool("dismiss-banners", false, "")
+	cmd.Flags().String("proxy-pool", "", "")
 	cmd.Flags().String("tab", "", "")
 	cmd.Flags().Bool("print-tab-id", false, "")
 	return cmd
@@ -111,6 +112,21 @@ func TestBuildNavigateRequestDoesNotFallbackForExplicitTab(t *testing.T) {
 	}
 }
 
+func TestBuildNavigateRequestProxyPoolIgnoresCurrentTab(t *testing.T) {
+	cmd := newNavigateCmd()
+	_ = cmd.Flags().Set("tab", "TAB1")
+	_ = cmd.Flags().Set("proxy-pool", "residential")
+
+	req := buildNavigateRequest("https://pinchtab.com", cmd)
+
+	if req.path != "/navigate" {
+		t.Fatalf("path = %q, want /navigate", req.path)
+	}
+	if req.body["proxyPool"] != "residential" {
+		t.Fatalf("body = %v, want proxyPool", req.body)
+	}
+}
+
 func TestNavigateWithAllFlags(t *testing.T) {
 	m := newMockServer()
 	defer m.close()
@@ -23,6 +23,15 @@ import (
 //	--status <code>         : (with --body) override the response status (default 200)
 //	--content-type <ct>     : (with --body) override Content-Type (default application/json)
 //
+// WebSocket and EventSource rules (--resource-type websocket|eventsource):
+//
+//	--frame <data>     : scripted server frame (repeatable)
+//	--frame-delay <ms> : delay before each --frame
+//	--mock             : serve the frames from a fake connection (fulfill)
+//	--drop <pattern>   : discard frames matching pattern on the real connection
+//
+// Without --mock, frames are injected into the real connection after it opens.
+//
 // The --tab flag (state file) selects the target tab; when
 // empty the bare /network/route endpoint resolves to the active tab.
 func NetworkRoute(client *http.Client, base, token string, cmd *cobra.Command, pattern string) {
@@ -32,15 +41,24 @@ func NetworkRoute(client *http.Client, base, token string, cmd *cobra.Command, p
 	contentType, _ := cmd.Flags().GetString("content-type")
 	status, _ := cmd.Flags().GetInt("status")
 	method, _ := cmd.Flags().GetString("method")
+	frameData, _ := cmd.Flags().GetStringArray("frame")
+	frameDelay, _ := cmd.Flags().GetInt("frame-delay")
+	mock, _ := cmd.Flags().GetBool("mock")
+	drop, _ := cmd.Flags().GetString("drop")
 
 	if abort && body != "" {
 		exitErr(1, "Error: --abort and --body are mutually exclusive")
 	}
+	if mock && (abort || body != "") {
+		exitErr(1, "Error: --mock cannot be combined with --abort or --body")
+	}
 
 	req := map[string]any{"pattern": pattern}
 	switch {
 	case abort:
 		req["action"] = "abort"
+	case mock:
+		req["action"] = "fulfill"
 	case body != "":
 		req["action"] = "fulfill"
 		req["body"] = body
@@ -59,6 +77,20 @@ func NetworkRoute(client *http.Client, base, token string, cmd *cobra.Command, p
 	if method != "" {
 		req["method"] = method
 	}
+	if len(frameData) > 0 {
+		frames := make([]map[string]any, 0, len(frameData))
+		for _, data := range frameData {
+			frame := map[string]any{"data": data}
+			if frameDelay > 0 {
+				frame["delayMs"] = frameDelay
+			}
+			frames = append(frames, frame)
+		}
+		req["frames"] = frames
+	}
+	if drop != "" {
+		req["dropPattern"] = drop
+	}
 
 	path := "/network/route"
 	if tab, _ := cmd.Flags().GetString("tab"); tab != "" {
@@ -98,3 +130,45 @@ func NetworkUnroute(client *http.Client, base, token string, cmd *cobra.Command,
 		fmt.Println("routes cleared")
 	}
 }
+
+// NetworkReplay serves the active tab from a HAR archive in the state dir.
+// With --stop it ends the replay; with no archive it prints the status.
+//
+//	--match <mode>      : strict (default), url or path
+//	--not-found <mode>  : abort (default), passthrough or 404
+func NetworkReplay(client *http.Client, base, token string, cmd *cobra.Command, archive string) {
+	path := "/network/replay"
+	if tab, _ := cmd.Flags().GetString("tab"); tab != "" {
+		path = fmt.Sprintf("/tabs/%s/network/replay", url.PathEscape(tab))
+	}
+	if stop, _ := cmd.Flags().GetBool("stop"); stop {
+		apiclient.DoDelete(client, base, token, path, nil)
+		return
+	}
+	if archive == "" {
+		apiclient.DoGet(client, base, token, path, nil)
+		return
+	}
+
+	req := map[string]any{"path": archive}
+	if match, _ := cmd.Flags().GetString("match"); match != "" {
+		req["match"] = match
+	}
+	if notFound, _ := cmd.Flags().GetString("not-found"); notFound != "" {
+		req["notFound"] = notFound
+	}
+	result := requireMap(apiclient.DoPostQuiet(client, base, token, path, req), 1, "Failed to start replay")
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		printIndented(result)
+		return
+	}
+	entries := 0
+	if replay, ok := result["replay"].(map[string]any); ok {
+		if n, ok := replay["entries"].(float64); ok {
+			entries = int(n)
+		}
+	}
+	fmt.Printf("replaying %s (%d entries)\n", archive, entries)
+}
@@ -0,0 +1,56 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pinchtab/pinchtab/internal/cli/apiclient"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// SetPermission grants, denies or resets one browser permission for the
+// active tab's origin (or --origin). Setting "reset" drops the override so
+// the instance default applies again.
+func SetPermission(client *http.Client, base, token string, cmd *cobra.Command, args []string) {
+	name, setting := args[0], args[1]
+	if !config.IsKnownPermission(name) {
+		fmt.Fprintf(os.Stderr, "ERROR: unknown permission %q\n", name)
+		os.Exit(2)
+	}
+	if setting != "reset" && !config.IsValidPermissionSetting(setting) {
+		fmt.Fprintf(os.Stderr, "ERROR: invalid setting %q: must be granted, denied, prompt, or reset\n", setting)
+		os.Exit(2)
+	}
+
+	origin, _ := cmd.Flags().GetString("origin")
+	path := "/permissions"
+	if tab, _ := cmd.Flags().GetString("tab"); tab != "" {
+		path = "/tabs/" + url.PathEscape(tab) + "/permissions"
+	}
+
+	var result map[string]any
+	if setting == "reset" {
+		params := url.Values{"name": {name}}
+		if origin != "" {
+			params.Set("origin", origin)
+		}
+		result = apiclient.DoDelete(client, base, token, path, params)
+	} else {
+		body := map[string]any{"permissions": map[string]string{name: setting}}
+		if origin != "" {
+			body["origin"] = origin
+		}
+		result = apiclient.DoPostQuiet(client, base, token, path, body)
+	}
+	result = requireMap(result, 2, "ERROR: set permission failed")
+
+	jsonOut, _ := cmd.Flags().GetBool("json")
+	if jsonOut {
+		printIndented(result)
+		return
+	}
+	fmt.Printf("%s %s for %v\n", name, setting, result["origin"])
+}
@@ -14,6 +14,13 @@ func StateCurrent(client *http.Client, base, token string, cmd *cobra.Command) {
 	if tabID != "" {
 		params.Set("tabId", tabID)
 	}
+	origins, _ := cmd.Flags().GetStringArray("origin")
+	for _, origin := range origins {
+		params.Add("origins", origin)
+	}
+	if visited, _ := cmd.Flags().GetBool("visited"); visited {
+		params.Set("visitedOrigins", "true")
+	}
 
 	result := requireBytes(apiclient.DoGetRaw(client, base, token, "/state", params), 1, "Failed to read current browser state")
 	buf := decodeMap(result, 1, "Failed to parse response")
@@ -38,6 +45,12 @@ func StateSave(client *http.Client, base, token string, cmd *cobra.Command) {
 	if tabID != "" {
 		body["tabId"] = tabID
 	}
+	if origins, _ := cmd.Flags().GetStringArray("origin"); len(origins) > 0 {
+		body["origins"] = origins
+	}
+	if visited, _ := cmd.Flags().GetBool("visited"); visited {
+		body["visitedOrigins"] = true
+	}
 
 	requireMap(apiclient.DoPost(client, base, token, "/state/save", body), 1, "Failed to save state")
 }
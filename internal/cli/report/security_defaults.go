@@ -31,6 +31,7 @@ func RecommendedSecurityDefaultLines(cfg *config.RuntimeConfig) []string {
 		"security.allowDownload = false",
 		"security.allowUpload = false",
 		"security.allowNetworkIntercept = false",
+		"security.allowWebAuthn = false",
 		"security.attach.enabled = false",
 		"security.attach.allowHosts = 127.0.0.1,localhost,::1",
 		"security.attach.allowSchemes = ws,wss",
@@ -57,6 +58,7 @@ func RecommendedSecurityDefaultLines(cfg *config.RuntimeConfig) []string {
 				"security.allowDownload = false",
 				"security.allowUpload = false",
 				"security.allowNetworkIntercept = false",
+				"security.allowWebAuthn = false",
 			} {
 				needed[line] = true
 			}
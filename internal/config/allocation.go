@@ -0,0 +1,38 @@
+package config
+
+import "fmt"
+
+// AllocationWeights scale the load signals of the weighted allocation policy
+// (multiInstance.allocationWeights). Each signal is compared against the
+// busiest candidate, so only the ratio between weights matters. All zero
+// takes the least_loaded defaults.
+type AllocationWeights struct {
+	Tabs     float64 `json:"tabs,omitempty"`
+	Queue    float64 `json:"queue,omitempty"`    // tab-executor actions waiting
+	InFlight float64 `json:"inflight,omitempty"` // tab-executor actions running
+	Latency  float64 `json:"latency,omitempty"`  // recent mean request latency
+	Memory   float64 `json:"memory,omitempty"`   // browser process tree RSS
+}
+
+// ValidateAllocationWeights checks multiInstance.allocationWeights.
+func ValidateAllocationWeights(field string, w AllocationWeights) []error {
+	var errs []error
+	for _, f := range []struct {
+		name  string
+		value float64
+	}{
+		{"tabs", w.Tabs},
+		{"queue", w.Queue},
+		{"inflight", w.InFlight},
+		{"latency", w.Latency},
+		{"memory", w.Memory},
+	} {
+		if f.value < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + "." + f.name,
+				Message: fmt.Sprintf("must be >= 0 (got %g)", f.value),
+			})
+		}
+	}
+	return errs
+}
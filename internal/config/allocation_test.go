@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestValidateAllocationWeights(t *testing.T) {
+	if errs := ValidateAllocationWeights("multiInstance.allocationWeights", AllocationWeights{Tabs: 1, Memory: 0.5}); len(errs) != 0 {
+		t.Fatalf("valid weights: %v", errs)
+	}
+	errs := ValidateAllocationWeights("multiInstance.allocationWeights", AllocationWeights{Queue: -1})
+	if len(errs) != 1 {
+		t.Fatalf("errs = %v, want one", errs)
+	}
+	if ve, ok := errs[0].(ValidationError); !ok || ve.Field != "multiInstance.allocationWeights.queue" {
+		t.Fatalf("err = %#v", errs[0])
+	}
+}
+
+func TestAllocationWeightsRoundTrip(t *testing.T) {
+	fc := DefaultFileConfig()
+	fc.MultiInstance.AllocationPolicy = "weighted"
+	fc.MultiInstance.AllocationWeights = AllocationWeights{Queue: 4, Latency: 2}
+
+	cfg := &RuntimeConfig{}
+	ApplyFileConfigToRuntime(cfg, &fc)
+	if cfg.AllocationPolicy != "weighted" || cfg.AllocationWeights != fc.MultiInstance.AllocationWeights {
+		t.Fatalf("runtime = %q %+v", cfg.AllocationPolicy, cfg.AllocationWeights)
+	}
+	back := FileConfigFromRuntime(cfg)
+	if back.MultiInstance.AllocationWeights != fc.MultiInstance.AllocationWeights {
+		t.Fatalf("file config weights = %+v", back.MultiInstance.AllocationWeights)
+	}
+}
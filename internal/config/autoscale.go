@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AutoscaleConfig drives the autoscale strategy (multiInstance.autoscale).
+// Every profile in Profiles runs between MinInstances and MaxInstances
+// instances. The strategy adds one when a load threshold is crossed and
+// stops an idle one after CooldownSec. Zero values take the strategy
+// defaults; a threshold of zero leaves that signal unused, and with no
+// threshold set at all queueDepthPerInstance 10 and tabsPerInstance 20 apply.
+type AutoscaleConfig struct {
+	// Profiles are the profiles to scale; default ["default"]. Instances
+	// beyond a profile's first run on temporary profiles.
+	Profiles     []string `json:"profiles,omitempty"`
+	MinInstances int      `json:"minInstances,omitempty"` // default 1
+	MaxInstances int      `json:"maxInstances,omitempty"` // default 4
+
+	// Scale-up thresholds, per running instance.
+	QueueDepthPerInstance int `json:"queueDepthPerInstance,omitempty"` // queued scheduler tasks
+	TabsPerInstance       int `json:"tabsPerInstance,omitempty"`
+	CPUPercent            int `json:"cpuPercent,omitempty"` // browser process tree, 100 = one core
+	MemoryMB              int `json:"memoryMB,omitempty"`   // browser process tree RSS
+
+	IntervalSec int `json:"intervalSec,omitempty"` // evaluation interval, default 15
+	CooldownSec int `json:"cooldownSec,omitempty"` // idle time before scale-down, default 300
+}
+
+// IsZero reports whether no autoscale setting is configured.
+func (c AutoscaleConfig) IsZero() bool {
+	return len(c.Profiles) == 0 && c.MinInstances == 0 && c.MaxInstances == 0 &&
+		c.QueueDepthPerInstance == 0 && c.TabsPerInstance == 0 && c.CPUPercent == 0 &&
+		c.MemoryMB == 0 && c.IntervalSec == 0 && c.CooldownSec == 0
+}
+
+func cloneAutoscaleConfig(in AutoscaleConfig) AutoscaleConfig {
+	out := in
+	out.Profiles = append([]string(nil), in.Profiles...)
+	if len(out.Profiles) == 0 {
+		out.Profiles = nil
+	}
+	return out
+}
+
+// ValidateAutoscale checks multiInstance.autoscale.
+func ValidateAutoscale(field string, c AutoscaleConfig) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for i, name := range c.Profiles {
+		// The profile manager applies the full name rules at launch.
+		if strings.TrimSpace(name) != name || name == "" || strings.Contains(name, "..") || strings.ContainsAny(name, "/\\") {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("%s.profiles[%d]", field, i),
+				Message: fmt.Sprintf("invalid profile name %q", name),
+			})
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("%s.profiles[%d]", field, i),
+				Message: fmt.Sprintf("duplicate profile %q", name),
+			})
+		}
+		seen[name] = true
+	}
+	for _, f := range []struct {
+		name  string
+		value int
+	}{
+		{"minInstances", c.MinInstances},
+		{"maxInstances", c.MaxInstances},
+		{"queueDepthPerInstance", c.QueueDepthPerInstance},
+		{"tabsPerInstance", c.TabsPerInstance},
+		{"cpuPercent", c.CPUPercent},
+		{"memoryMB", c.MemoryMB},
+		{"intervalSec", c.IntervalSec},
+		{"cooldownSec", c.CooldownSec},
+	} {
+		if f.value < 0 {
+			errs = append(errs, ValidationError{
+				Field:   field + "." + f.name,
+				Message: fmt.Sprintf("must be >= 0 (got %d)", f.value),
+			})
+		}
+	}
+	if c.MinInstances > 0 && c.MaxInstances > 0 && c.MinInstances > c.MaxInstances {
+		errs = append(errs, ValidationError{
+			Field:   field + ".minInstances",
+			Message: fmt.Sprintf("must be <= maxInstances (%d > %d)", c.MinInstances, c.MaxInstances),
+		})
+	}
+	return errs
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAutoscale(t *testing.T) {
+	good := AutoscaleConfig{Profiles: []string{"default", "work"}, MinInstances: 1, MaxInstances: 3, CPUPercent: 150}
+	if errs := ValidateAutoscale("multiInstance.autoscale", good); len(errs) != 0 {
+		t.Fatalf("valid config rejected: %v", errs)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     AutoscaleConfig
+		wantSub string
+	}{
+		{"bad profile", AutoscaleConfig{Profiles: []string{"../etc"}}, "invalid profile name"},
+		{"blank profile", AutoscaleConfig{Profiles: []string{" "}}, "invalid profile name"},
+		{"duplicate profile", AutoscaleConfig{Profiles: []string{"work", "work"}}, "duplicate profile"},
+		{"negative threshold", AutoscaleConfig{TabsPerInstance: -1}, "tabsPerInstance"},
+		{"min over max", AutoscaleConfig{MinInstances: 5, MaxInstances: 2}, "must be <= maxInstances"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateAutoscale("multiInstance.autoscale", tt.cfg)
+			if len(errs) == 0 {
+				t.Fatal("expected an error")
+			}
+			var all []string
+			for _, err := range errs {
+				all = append(all, err.Error())
+			}
+			if joined := strings.Join(all, "; "); !strings.Contains(joined, tt.wantSub) {
+				t.Fatalf("errors %q do not mention %q", joined, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestAutoscaleConfigRoundTrip(t *testing.T) {
+	fc := DefaultFileConfig()
+	fc.MultiInstance.Strategy = "autoscale"
+	fc.MultiInstance.Autoscale = AutoscaleConfig{Profiles: []string{"work"}, MaxInstances: 6, CooldownSec: 60}
+
+	cfg := &RuntimeConfig{}
+	ApplyFileConfigToRuntime(cfg, &fc)
+	if cfg.Autoscale.MaxInstances != 6 || len(cfg.Autoscale.Profiles) != 1 {
+		t.Fatalf("runtime autoscale = %+v", cfg.Autoscale)
+	}
+
+	if err := SetConfigValue(&fc, "multiInstance.autoscale.profiles", "work,research"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigValue(&fc, "multiInstance.autoscale.tabsPerInstance", "15"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetConfigValue(&fc, "multiInstance.autoscale.profiles"); err != nil || got != "work,research" {
+		t.Fatalf("profiles = %q, %v", got, err)
+	}
+	if got, err := GetConfigValue(&fc, "multiInstance.autoscale.tabsPerInstance"); err != nil || got != "15" {
+		t.Fatalf("tabsPerInstance = %q, %v", got, err)
+	}
+	if err := SetConfigValue(&fc, "multiInstance.autoscale.minInstances", "x"); err == nil {
+		t.Fatal("expected an error for a non-integer value")
+	}
+}
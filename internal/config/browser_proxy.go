@@ -188,20 +188,28 @@ func BrowserProxyFlags(p BrowserProxyConfig) ([]string, error) {
 	if p.IsZero() {
 		return nil, nil
 	}
-	scheme, host, port, err := ParseProxyServer(p.Server)
+	server, bypass, err := BrowserProxyServer(p)
 	if err != nil {
 		return nil, fmt.Errorf("browser.proxy.server is invalid; refusing to launch without the configured proxy (traffic would egress directly): %w", err)
 	}
-	authority := net.JoinHostPort(host, strconv.Itoa(port))
-	flags := []string{
-		fmt.Sprintf("--proxy-server=%s://%s", scheme, authority),
-	}
-	if bypass := joinBypassList(p.BypassList); bypass != "" {
+	flags := []string{"--proxy-server=" + server}
+	if bypass != "" {
 		flags = append(flags, "--proxy-bypass-list="+bypass)
 	}
 	return flags, nil
 }
 
+// BrowserProxyServer returns the credential-free "scheme://host:port" and the
+// ';'-joined bypass list, in the form both --proxy-server and
+// Target.createBrowserContext take.
+func BrowserProxyServer(p BrowserProxyConfig) (server, bypass string, err error) {
+	scheme, host, port, err := ParseProxyServer(p.Server)
+	if err != nil {
+		return "", "", err
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)), joinBypassList(p.BypassList), nil
+}
+
 func joinBypassList(items []string) string {
 	cleaned := make([]string, 0, len(items))
 	for _, it := range items {
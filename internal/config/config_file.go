@@ -123,6 +123,7 @@ func DefaultFileConfig() FileConfig {
 	allowDownload := false
 	allowCookies := false
 	allowNetworkIntercept := false
+	allowWebAuthn := false
 	downloadMaxBytes := DefaultDownloadMaxBytes
 	allowUpload := false
 	allowClipboard := false
@@ -146,6 +147,8 @@ func DefaultFileConfig() FileConfig {
 	activitySchedulerEvents := false
 	activityMCPEvents := false
 	activityOtherEvents := false
+	tracingEnabled := false
+	tracingSampleRatio := 1.0
 	dashboardSessionPersist := true
 	dashboardSessionIdleSec := 7 * 24 * 60 * 60
 	dashboardSessionMaxLifetimeSec := 7 * 24 * 60 * 60
@@ -189,6 +192,7 @@ func DefaultFileConfig() FileConfig {
 			AllowDownload:          &allowDownload,
 			AllowCookies:           &allowCookies,
 			AllowNetworkIntercept:  &allowNetworkIntercept,
+			AllowWebAuthn:          &allowWebAuthn,
 			AllowedDomains:         append([]string(nil), defaultLocalAllowedDomains...),
 			DownloadAllowedDomains: []string{},
 			DownloadMaxBytes:       &downloadMaxBytes,
@@ -254,6 +258,11 @@ func DefaultFileConfig() FileConfig {
 					Other:        &activityOtherEvents,
 				},
 			},
+			Tracing: TracingFileConfig{
+				Enabled:     &tracingEnabled,
+				Exporter:    "file",
+				SampleRatio: &tracingSampleRatio,
+			},
 		},
 		Sessions: SessionsFileConfig{
 			Dashboard: DashboardSessionFileConfig{
@@ -15,6 +15,7 @@ type fileConfigJSON struct {
 	Observability    observabilityFileConfigJSON `json:"observability"`
 	Sessions         sessionsFileConfigJSON      `json:"sessions"`
 	AutoSolver       autoSolverFileConfigJSON    `json:"autoSolver,omitempty"`
+	Handoff          handoffFileConfigJSON       `json:"handoff,omitempty"`
 }
 
 type serverConfigJSON struct {
@@ -39,6 +40,7 @@ type browserConfigJSON struct {
 	ExtensionPaths    []string                `json:"extensionPaths"`
 	// Pointer so omitempty drops the field for legacy configs (byte-identical round-trip).
 	Proxy         *BrowserProxyConfig  `json:"proxy,omitempty"`
+	ProxyPools    ProxyPoolsConfig     `json:"proxyPools,omitempty"`
 	DefaultTarget string               `json:"defaultTarget,omitempty"`
 	FallbackOrder []string             `json:"fallbackOrder,omitempty"`
 	Targets       BrowserTargetsConfig `json:"targets,omitempty"`
@@ -70,6 +72,7 @@ type instanceDefaultsConfigJSON struct {
 	StealthLevel      string             `json:"stealthLevel"`
 	TabEvictionPolicy string             `json:"tabEvictionPolicy"`
 	TabPolicy         *TabPolicyDefaults `json:"tabPolicy,omitempty"`
+	Permissions       map[string]string  `json:"permissions,omitempty"`
 }
 
 type profilesConfigJSON struct {
@@ -84,6 +87,7 @@ type securityConfigJSON struct {
 	AllowDownload          *bool          `json:"allowDownload"`
 	AllowCookies           *bool          `json:"allowCookies"`
 	AllowNetworkIntercept  *bool          `json:"allowNetworkIntercept"`
+	AllowWebAuthn          *bool          `json:"allowWebAuthn"`
 	AllowFileScheme        *bool          `json:"allowFileScheme"`
 	AllowedDomains         []string       `json:"allowedDomains"`
 	DownloadAllowedDomains []string       `json:"downloadAllowedDomains"`
@@ -128,6 +132,8 @@ type multiInstanceConfigJSON struct {
 	InstancePortStart *int                     `json:"instancePortStart"`
 	InstancePortEnd   *int                     `json:"instancePortEnd"`
 	Restart           multiInstanceRestartJSON `json:"restart"`
+	AllocationWeights AllocationWeights        `json:"allocationWeights,omitempty"`
+	Autoscale         AutoscaleConfig          `json:"autoscale,omitempty"`
 }
 
 type multiInstanceRestartJSON struct {
@@ -153,10 +159,21 @@ type schedulerFileConfigJSON struct {
 	MaxPerAgentFlight *int   `json:"maxPerAgentInflight"`
 	ResultTTLSec      *int   `json:"resultTTLSec"`
 	WorkerCount       *int   `json:"workerCount"`
+	Persist           *bool  `json:"persist,omitempty"`
+	OnRestart         string `json:"onRestart,omitempty"`
 }
 
 type observabilityFileConfigJSON struct {
 	Activity activityConfigJSON `json:"activity"`
+	Tracing  tracingConfigJSON  `json:"tracing"`
+}
+
+type tracingConfigJSON struct {
+	Enabled     *bool    `json:"enabled"`
+	Exporter    string   `json:"exporter,omitempty"`
+	Endpoint    string   `json:"endpoint,omitempty"`
+	File        string   `json:"file,omitempty"`
+	SampleRatio *float64 `json:"sampleRatio,omitempty"`
 }
 
 type activityConfigJSON struct {
@@ -182,12 +199,13 @@ type sessionsFileConfigJSON struct {
 }
 
 type dashboardSessionConfigJSON struct {
-	Persist                       *bool `json:"persist,omitempty"`
-	IdleTimeoutSec                *int  `json:"idleTimeoutSec,omitempty"`
-	MaxLifetimeSec                *int  `json:"maxLifetimeSec,omitempty"`
-	ElevationWindowSec            *int  `json:"elevationWindowSec,omitempty"`
-	PersistElevationAcrossRestart *bool `json:"persistElevationAcrossRestart,omitempty"`
-	RequireElevation              *bool `json:"requireElevation,omitempty"`
+	Persist                       *bool      `json:"persist,omitempty"`
+	IdleTimeoutSec                *int       `json:"idleTimeoutSec,omitempty"`
+	MaxLifetimeSec                *int       `json:"maxLifetimeSec,omitempty"`
+	ElevationWindowSec            *int       `json:"elevationWindowSec,omitempty"`
+	PersistElevationAcrossRestart *bool      `json:"persistElevationAcrossRestart,omitempty"`
+	RequireElevation              *bool      `json:"requireElevation,omitempty"`
+	OIDC                          OIDCConfig `json:"oidc,omitempty"`
 }
 
 type autoSolverFileConfigJSON struct {
@@ -202,10 +220,29 @@ type autoSolverFileConfigJSON struct {
 	Solvers           []string                        `json:"solvers,omitempty"`
 	LLMProvider       string                          `json:"llmProvider,omitempty"`
 	LLMFallback       *bool                           `json:"llmFallback,omitempty"`
+	LLM               autoSolverLLMConfigJSON         `json:"llm,omitempty"`
 	External          autoSolverExtConfigJSON         `json:"external,omitempty"`
 	Credentials       autoSolverCredentialsConfigJSON `json:"credentials,omitempty"`
 }
 
+type autoSolverLLMConfigJSON struct {
+	Model             string  `json:"model,omitempty"`
+	APIKey            string  `json:"apiKey,omitempty"`
+	BaseURL           string  `json:"baseUrl,omitempty"`
+	MaxTokens         int     `json:"maxTokens,omitempty"`
+	TimeoutSec        int     `json:"timeoutSec,omitempty"`
+	MaxRetries        int     `json:"maxRetries,omitempty"`
+	InputCostPerMTok  float64 `json:"inputCostPerMTok,omitempty"`
+	OutputCostPerMTok float64 `json:"outputCostPerMTok,omitempty"`
+}
+
+type handoffFileConfigJSON struct {
+	PublicURL     string   `json:"publicUrl,omitempty"`
+	LinkTTLSec    int      `json:"linkTtlSec,omitempty"`
+	Webhooks      []string `json:"webhooks,omitempty"`
+	WebhookSecret string   `json:"webhookSecret,omitempty"`
+}
+
 type autoSolverExtConfigJSON struct {
 	CapsolverKey  string `json:"capsolverKey,omitempty"`
 	TwoCaptchaKey string `json:"twoCaptchaKey,omitempty"`
@@ -184,6 +184,7 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			Cloak:             cloakBrowserConfigJSONFromFile(fc.Browser.Cloak),
 			ExtensionPaths:    copyStringSlice(fc.Browser.ExtensionPaths),
 			Proxy:             browserProxyJSONFromFile(fc.Browser.Proxy),
+			ProxyPools:        fc.Browser.ProxyPools,
 			DefaultTarget:     fc.Browser.DefaultTarget,
 			FallbackOrder:     fc.Browser.FallbackOrder,
 			Targets:           fc.Browser.Targets,
@@ -203,6 +204,7 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			StealthLevel:      fc.InstanceDefaults.StealthLevel,
 			TabEvictionPolicy: fc.InstanceDefaults.TabEvictionPolicy,
 			TabPolicy:         fc.InstanceDefaults.TabPolicy,
+			Permissions:       fc.InstanceDefaults.Permissions,
 		},
 		Security: securityConfigJSON{
 			AllowEvaluate:          fc.Security.AllowEvaluate,
@@ -211,6 +213,7 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			AllowDownload:          fc.Security.AllowDownload,
 			AllowCookies:           fc.Security.AllowCookies,
 			AllowNetworkIntercept:  fc.Security.AllowNetworkIntercept,
+			AllowWebAuthn:          fc.Security.AllowWebAuthn,
 			AllowFileScheme:        fc.Security.AllowFileScheme,
 			AllowedDomains:         effectiveSecurityAllowedDomains(fc.Security),
 			DownloadAllowedDomains: copyStringSlice(fc.Security.DownloadAllowedDomains),
@@ -259,6 +262,8 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 				MaxBackoffSec:  fc.MultiInstance.Restart.MaxBackoffSec,
 				StableAfterSec: fc.MultiInstance.Restart.StableAfterSec,
 			},
+			AllocationWeights: fc.MultiInstance.AllocationWeights,
+			Autoscale:         fc.MultiInstance.Autoscale,
 		},
 		Timeouts: timeoutsConfigJSON{
 			ActionSec:   fc.Timeouts.ActionSec,
@@ -275,6 +280,8 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			MaxPerAgentFlight: fc.Scheduler.MaxPerAgentFlight,
 			ResultTTLSec:      fc.Scheduler.ResultTTLSec,
 			WorkerCount:       fc.Scheduler.WorkerCount,
+			Persist:           fc.Scheduler.Persist,
+			OnRestart:         fc.Scheduler.OnRestart,
 		},
 		Observability: observabilityFileConfigJSON{
 			Activity: activityConfigJSON{
@@ -292,6 +299,13 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 					Other:        fc.Observability.Activity.Events.Other,
 				},
 			},
+			Tracing: tracingConfigJSON{
+				Enabled:     fc.Observability.Tracing.Enabled,
+				Exporter:    fc.Observability.Tracing.Exporter,
+				Endpoint:    fc.Observability.Tracing.Endpoint,
+				File:        fc.Observability.Tracing.File,
+				SampleRatio: fc.Observability.Tracing.SampleRatio,
+			},
 		},
 		Sessions: sessionsFileConfigJSON{
 			Dashboard: dashboardSessionConfigJSON{
@@ -301,6 +315,7 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 				ElevationWindowSec:            fc.Sessions.Dashboard.ElevationWindowSec,
 				PersistElevationAcrossRestart: fc.Sessions.Dashboard.PersistElevationAcrossRestart,
 				RequireElevation:              fc.Sessions.Dashboard.RequireElevation,
+				OIDC:                          fc.Sessions.Dashboard.OIDC,
 			},
 		},
 		AutoSolver: autoSolverFileConfigJSON{
@@ -315,6 +330,7 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 			Solvers:           copyStringSlice(fc.AutoSolver.Solvers),
 			LLMProvider:       fc.AutoSolver.LLMProvider,
 			LLMFallback:       fc.AutoSolver.LLMFallback,
+			LLM:               autoSolverLLMConfigJSON(fc.AutoSolver.LLM),
 			External: autoSolverExtConfigJSON{
 				CapsolverKey:  fc.AutoSolver.External.CapsolverKey,
 				TwoCaptchaKey: fc.AutoSolver.External.TwoCaptchaKey,
@@ -336,6 +352,12 @@ func (fc FileConfig) MarshalJSON() ([]byte, error) {
 				},
 			},
 		},
+		Handoff: handoffFileConfigJSON{
+			PublicURL:     fc.Handoff.PublicURL,
+			LinkTTLSec:    fc.Handoff.LinkTTLSec,
+			Webhooks:      copyStringSlice(fc.Handoff.Webhooks),
+			WebhookSecret: fc.Handoff.WebhookSecret,
+		},
 	})
 }
 
@@ -369,6 +391,7 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 	allowDownload := cfg.AllowDownload
 	allowCookies := cfg.AllowCookies
 	allowNetworkIntercept := cfg.AllowNetworkIntercept
+	allowWebAuthn := cfg.AllowWebAuthn
 	allowFileScheme := cfg.AllowFileScheme
 	downloadAllowedDomains := copyStringSlice(cfg.DownloadAllowedDomains)
 	downloadMaxBytes := cfg.EffectiveDownloadMaxBytes()
@@ -400,6 +423,8 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 	activitySchedulerEvents := cfg.Observability.Activity.Events.Scheduler
 	activityMCPEvents := cfg.Observability.Activity.Events.MCP
 	activityOtherEvents := cfg.Observability.Activity.Events.Other
+	tracingEnabled := cfg.Observability.Tracing.Enabled
+	tracingSampleRatio := cfg.Observability.Tracing.SampleRatio
 	dashboardSessionPersist := cfg.Sessions.Dashboard.Persist
 	dashboardSessionIdleSec := int(cfg.Sessions.Dashboard.IdleTimeout / time.Second)
 	dashboardSessionMaxLifetimeSec := int(cfg.Sessions.Dashboard.MaxLifetime / time.Second)
@@ -464,6 +489,7 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 			Cloak:             cloakBrowserConfigFromRuntime(cfg),
 			ExtensionPaths:    append([]string(nil), cfg.ExtensionPaths...),
 			Proxy:             cloneBrowserProxyConfig(cfg.Proxy),
+			ProxyPools:        cloneProxyPoolsConfig(cfg.ProxyPools),
 			DefaultTarget:     cfg.DefaultTarget,
 			FallbackOrder:     append([]string(nil), cfg.FallbackOrder...),
 			Targets:           cloneBrowserTargetsConfig(cfg.Targets),
@@ -491,6 +517,7 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 			AllowDownload:          &allowDownload,
 			AllowCookies:           &allowCookies,
 			AllowNetworkIntercept:  &allowNetworkIntercept,
+			AllowWebAuthn:          &allowWebAuthn,
 			AllowFileScheme:        &allowFileScheme,
 			AllowedDomains:         append([]string(nil), cfg.AllowedDomains...),
 			DownloadAllowedDomains: downloadAllowedDomains,
@@ -530,6 +557,8 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 				MaxBackoffSec:  &restartMaxBackoffSec,
 				StableAfterSec: &restartStableAfterSec,
 			},
+			AllocationWeights: cfg.AllocationWeights,
+			Autoscale:         cloneAutoscaleConfig(cfg.Autoscale),
 		},
 		Timeouts: TimeoutsConfig{
 			ActionSec:   int(cfg.ActionTimeout / time.Second),
@@ -552,6 +581,13 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 					Other:        &activityOtherEvents,
 				},
 			},
+			Tracing: TracingFileConfig{
+				Enabled:     &tracingEnabled,
+				Exporter:    cfg.Observability.Tracing.Exporter,
+				Endpoint:    cfg.Observability.Tracing.Endpoint,
+				File:        cfg.Observability.Tracing.File,
+				SampleRatio: &tracingSampleRatio,
+			},
 		},
 		Sessions: SessionsFileConfig{
 			Dashboard: DashboardSessionFileConfig{
@@ -561,6 +597,7 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 				ElevationWindowSec:            &dashboardSessionElevationWindowSec,
 				PersistElevationAcrossRestart: &dashboardSessionPersistElevationAcrossRestart,
 				RequireElevation:              &dashboardSessionRequireElevation,
+				OIDC:                          cloneOIDCConfig(cfg.Sessions.Dashboard.OIDC),
 			},
 		},
 		AutoSolver: AutoSolverFileConfig{
@@ -575,6 +612,7 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 			Solvers:           copyStringSlice(cfg.AutoSolver.Solvers),
 			LLMProvider:       cfg.AutoSolver.LLMProvider,
 			LLMFallback:       &autoSolverLLMFallback,
+			LLM:               AutoSolverLLMConf(cfg.AutoSolver.LLM),
 			External: AutoSolverExtConf{
 				CapsolverKey:  cfg.AutoSolver.CapsolverKey,
 				TwoCaptchaKey: cfg.AutoSolver.TwoCaptchaKey,
@@ -596,6 +634,12 @@ func FileConfigFromRuntime(cfg *RuntimeConfig) FileConfig {
 				},
 			},
 		},
+		Handoff: HandoffFileConfig{
+			PublicURL:     cfg.Handoff.PublicURL,
+			LinkTTLSec:    cfg.Handoff.LinkTTLSec,
+			Webhooks:      copyStringSlice(cfg.Handoff.Webhooks),
+			WebhookSecret: cfg.Handoff.WebhookSecret,
+		},
 		Browsers: browsersBlock,
 	}
 
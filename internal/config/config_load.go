@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
@@ -155,6 +156,7 @@ func LoadConfig() (*RuntimeConfig, []LoadDiagnostic, error) {
 		AllowDownload:             false,
 		AllowCookies:              false,
 		AllowNetworkIntercept:     false,
+		AllowWebAuthn:             false,
 		AllowFileScheme:           false,
 		RetainNetworkBodies:       false,
 		RetainNetworkBodyMaxBytes: 256 * 1024,
@@ -229,6 +231,10 @@ func LoadConfig() (*RuntimeConfig, []LoadDiagnostic, error) {
 				RetentionDays:  30,
 				StateDir:       "",
 			},
+			Tracing: TracingConfig{
+				Exporter:    "file",
+				SampleRatio: 1,
+			},
 		},
 
 		Sessions: SessionsRuntimeConfig{
@@ -386,6 +392,9 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Security.AllowNetworkIntercept != nil {
 		cfg.AllowNetworkIntercept = *fc.Security.AllowNetworkIntercept
 	}
+	if fc.Security.AllowWebAuthn != nil {
+		cfg.AllowWebAuthn = *fc.Security.AllowWebAuthn
+	}
 	if fc.Security.AllowFileScheme != nil {
 		cfg.AllowFileScheme = *fc.Security.AllowFileScheme
 	}
@@ -464,6 +473,17 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Observability.Activity.Events.Other != nil {
 		cfg.Observability.Activity.Events.Other = *fc.Observability.Activity.Events.Other
 	}
+	if fc.Observability.Tracing.Enabled != nil {
+		cfg.Observability.Tracing.Enabled = *fc.Observability.Tracing.Enabled
+	}
+	if fc.Observability.Tracing.Exporter != "" {
+		cfg.Observability.Tracing.Exporter = fc.Observability.Tracing.Exporter
+	}
+	cfg.Observability.Tracing.Endpoint = fc.Observability.Tracing.Endpoint
+	cfg.Observability.Tracing.File = fc.Observability.Tracing.File
+	if fc.Observability.Tracing.SampleRatio != nil {
+		cfg.Observability.Tracing.SampleRatio = *fc.Observability.Tracing.SampleRatio
+	}
 	if fc.Sessions.Dashboard.Persist != nil {
 		cfg.Sessions.Dashboard.Persist = *fc.Sessions.Dashboard.Persist
 	}
@@ -482,6 +502,7 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Sessions.Dashboard.RequireElevation != nil {
 		cfg.Sessions.Dashboard.RequireElevation = *fc.Sessions.Dashboard.RequireElevation
 	}
+	cfg.Sessions.Dashboard.OIDC = cloneOIDCConfig(fc.Sessions.Dashboard.OIDC)
 
 	if fc.Sessions.Agent.Enabled != nil {
 		cfg.Sessions.Agent.Enabled = *fc.Sessions.Agent.Enabled
@@ -570,6 +591,8 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 		geoCopy := *fc.Browser.Proxy.Geo
 		cfg.Proxy.Geo = &geoCopy
 	}
+	// Unconditional for the same reason as Proxy: pools carry credentials.
+	cfg.ProxyPools = cloneProxyPoolsConfig(fc.Browser.ProxyPools)
 	if fc.Browser.ExtensionPaths != nil {
 		cfg.ExtensionPaths = append([]string(nil), fc.Browser.ExtensionPaths...)
 	}
@@ -651,6 +674,9 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.InstanceDefaults.DialogAutoAccept != nil {
 		cfg.DialogAutoAccept = *fc.InstanceDefaults.DialogAutoAccept
 	}
+	if len(fc.InstanceDefaults.Permissions) > 0 {
+		cfg.Permissions = maps.Clone(fc.InstanceDefaults.Permissions)
+	}
 
 	if fc.Profiles.BaseDir != "" {
 		cfg.ProfilesBaseDir = fc.Profiles.BaseDir
@@ -684,6 +710,8 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.MultiInstance.Restart.StableAfterSec != nil {
 		cfg.RestartStableAfter = time.Duration(*fc.MultiInstance.Restart.StableAfterSec) * time.Second
 	}
+	cfg.AllocationWeights = fc.MultiInstance.AllocationWeights
+	cfg.Autoscale = cloneAutoscaleConfig(fc.MultiInstance.Autoscale)
 
 	if fc.Security.Attach.Enabled != nil {
 		cfg.AttachEnabled = *fc.Security.Attach.Enabled
@@ -735,6 +763,12 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.Scheduler.WorkerCount != nil {
 		cfg.Scheduler.WorkerCount = *fc.Scheduler.WorkerCount
 	}
+	if fc.Scheduler.Persist != nil {
+		cfg.Scheduler.Persist = *fc.Scheduler.Persist
+	}
+	if fc.Scheduler.OnRestart != "" {
+		cfg.Scheduler.OnRestart = fc.Scheduler.OnRestart
+	}
 
 	if fc.AutoSolver.Enabled != nil {
 		cfg.AutoSolver.Enabled = *fc.AutoSolver.Enabled
@@ -769,6 +803,7 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 	if fc.AutoSolver.LLMFallback != nil {
 		cfg.AutoSolver.LLMFallback = *fc.AutoSolver.LLMFallback
 	}
+	cfg.AutoSolver.LLM = AutoSolverLLM(fc.AutoSolver.LLM)
 	cfg.AutoSolver.CapsolverKey = fc.AutoSolver.External.CapsolverKey
 	cfg.AutoSolver.TwoCaptchaKey = fc.AutoSolver.External.TwoCaptchaKey
 	cfg.AutoSolver.Credentials = AutoSolverCredentials{
@@ -787,6 +822,12 @@ func applyFileConfig(cfg *RuntimeConfig, fc *FileConfig) {
 			Email:  fc.AutoSolver.Credentials.Form.Email,
 		},
 	}
+	cfg.Handoff = HandoffConfig{
+		PublicURL:     strings.TrimRight(strings.TrimSpace(fc.Handoff.PublicURL), "/"),
+		LinkTTLSec:    fc.Handoff.LinkTTLSec,
+		Webhooks:      append([]string(nil), fc.Handoff.Webhooks...),
+		WebhookSecret: fc.Handoff.WebhookSecret,
+	}
 }
 
 // ApplyFileConfigToRuntime merges file configuration into an existing runtime
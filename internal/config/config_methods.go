@@ -1,5 +1,7 @@
 package config
 
+import "path/filepath"
+
 // EnabledSensitiveEndpoints returns the names of sensitive endpoint families
 // that are currently enabled in the runtime configuration.
 func (cfg *RuntimeConfig) EnabledSensitiveEndpoints() []string {
@@ -7,7 +9,7 @@ func (cfg *RuntimeConfig) EnabledSensitiveEndpoints() []string {
 		return nil
 	}
 
-	enabled := make([]string, 0, 7)
+	enabled := make([]string, 0, 8)
 	if cfg.AllowEvaluate {
 		enabled = append(enabled, "evaluate")
 	}
@@ -29,6 +31,9 @@ func (cfg *RuntimeConfig) EnabledSensitiveEndpoints() []string {
 	if cfg.AllowNetworkIntercept {
 		enabled = append(enabled, "networkIntercept")
 	}
+	if cfg.AllowWebAuthn {
+		enabled = append(enabled, "webauthn")
+	}
 	return enabled
 }
 
@@ -40,3 +45,15 @@ func (cfg *RuntimeConfig) ActivityStateDir() string {
 	}
 	return cfg.StateDir
 }
+
+// TracingFile returns the file the "file" trace exporter appends to. When
+// unset, spans go to traces.jsonl under the main server state directory.
+func (cfg *RuntimeConfig) TracingFile() string {
+	if cfg == nil {
+		return ""
+	}
+	if cfg.Observability.Tracing.File != "" {
+		return cfg.Observability.Tracing.File
+	}
+	return filepath.Join(cfg.StateDir, "traces.jsonl")
+}
@@ -24,6 +24,7 @@ type RuntimeConfig struct {
 	AllowDownload         bool
 	AllowCookies          bool
 	AllowNetworkIntercept bool
+	AllowWebAuthn         bool
 	AllowFileScheme       bool
 	// AllowedDomains is the unified per-instance allowlist sourced from
 	// security.allowedDomains in the file config.
@@ -71,6 +72,7 @@ type RuntimeConfig struct {
 	CDPAttachURL      string
 	Cloak             CloakBrowserRuntimeConfig
 	Proxy             BrowserProxyConfig
+	ProxyPools        ProxyPoolsConfig
 	DefaultBrowser    string
 	BrowsersAvailable []string
 	Targets           BrowserTargetsConfig
@@ -97,12 +99,14 @@ type RuntimeConfig struct {
 	WaitNavDelay    time.Duration
 
 	// Orchestrator settings (dashboard mode only)
-	Strategy           string        // "always-on" (default), "simple", "explicit", or "simple-autorestart"
-	AllocationPolicy   string        // "fcfs" (default), "round_robin", "random"
+	Strategy           string        // "always-on" (default), "simple", "explicit", "simple-autorestart", or "autoscale"
+	AllocationPolicy   string        // "fcfs" (default), "round_robin", "random", "least_loaded", "weighted"
 	RestartMaxRestarts int           // Max restart attempts for restart-managed strategies (-1 = unlimited, 0 = strategy default)
 	RestartInitBackoff time.Duration // Initial restart backoff (0 = strategy default)
 	RestartMaxBackoff  time.Duration // Maximum restart backoff cap (0 = strategy default)
 	RestartStableAfter time.Duration // Stable runtime window that resets the restart counter (0 = strategy default)
+	Autoscale          AutoscaleConfig
+	AllocationWeights  AllocationWeights
 
 	AttachEnabled          bool
 	AttachAllowHosts       []string
@@ -117,6 +121,10 @@ type RuntimeConfig struct {
 
 	DialogAutoAccept bool
 
+	// Permissions is the instance-wide permission default applied to every
+	// origin when the browser starts (instanceDefaults.permissions).
+	Permissions map[string]string
+
 	NetworkBufferSize         int  // Per-tab network buffer size (default 100)
 	RetainNetworkBodies       bool // When true, opportunistically retain response bodies in the per-tab network buffer
 	RetainNetworkBodyMaxBytes int  // Max retained response-body bytes per entry when RetainNetworkBodies is enabled
@@ -129,6 +137,8 @@ type RuntimeConfig struct {
 	Sessions SessionsRuntimeConfig
 
 	AutoSolver AutoSolverConfig
+
+	Handoff HandoffConfig
 }
 
 type SessionsRuntimeConfig struct {
@@ -150,6 +160,7 @@ type DashboardSessionRuntimeConfig struct {
 	ElevationWindow               time.Duration `json:"elevationWindow,omitempty"`
 	PersistElevationAcrossRestart bool          `json:"persistElevationAcrossRestart,omitempty"`
 	RequireElevation              bool          `json:"requireElevation,omitempty"`
+	OIDC                          OIDCConfig    `json:"oidc,omitempty"`
 }
 
 // IDPIConfig holds the configuration for the Indirect Prompt Injection (IDPI)
@@ -177,6 +188,8 @@ type SchedulerConfig struct {
 	MaxPerAgentFlight int    `json:"maxPerAgentInflight,omitempty"`
 	ResultTTLSec      int    `json:"resultTTLSec,omitempty"`
 	WorkerCount       int    `json:"workerCount,omitempty"`
+	Persist           bool   `json:"persist,omitempty"`   // journal tasks under the state dir so they survive restarts
+	OnRestart         string `json:"onRestart,omitempty"` // "fail" (default) or "requeue" for tasks interrupted mid-flight
 }
 
 // AutoSolverConfig holds autosolver runtime settings.
@@ -190,13 +203,39 @@ type AutoSolverConfig struct {
 	RetryBaseDelayMs  int      `json:"retryBaseDelayMs,omitempty"`
 	RetryMaxDelayMs   int      `json:"retryMaxDelayMs,omitempty"`
 	Solvers           []string `json:"solvers,omitempty"`     // Ordered solver names
-	LLMProvider       string   `json:"llmProvider,omitempty"` // "openai", "anthropic", etc.
+	LLMProvider       string   `json:"llmProvider,omitempty"` // "openai" or "anthropic"
 	LLMFallback       bool     `json:"llmFallback,omitempty"` // Enable LLM as last resort
 	CapsolverKey      string   `json:"capsolverKey,omitempty"`
 	TwoCaptchaKey     string   `json:"twoCaptchaKey,omitempty"`
+	LLM               AutoSolverLLM
 	Credentials       AutoSolverCredentials
 }
 
+// HandoffConfig controls how humans are told about a tab paused for handoff.
+type HandoffConfig struct {
+	PublicURL     string   // Base URL takeover links are built on; empty yields a relative /dashboard link
+	LinkTTLSec    int      // Lifetime of signed takeover links (0 = DefaultHandoffLinkTTLSec)
+	Webhooks      []string // Endpoints POSTed on handoff.requested and handoff.resumed
+	WebhookSecret string   // Optional HMAC-SHA256 key for the X-PinchTab-Signature header
+}
+
+// DefaultHandoffLinkTTLSec is the takeover link lifetime when none is configured.
+const DefaultHandoffLinkTTLSec = 900
+
+// AutoSolverLLM configures the client behind LLMProvider. BaseURL points it
+// at any OpenAI- or Anthropic-compatible server; zero values take the
+// provider defaults.
+type AutoSolverLLM struct {
+	Model             string
+	APIKey            string
+	BaseURL           string
+	MaxTokens         int
+	TimeoutSec        int
+	MaxRetries        int // -1 disables retries
+	InputCostPerMTok  float64
+	OutputCostPerMTok float64
+}
+
 // AutoSolverCredentials carries values the semantic solver injects into
 // matched login/signup/form fields. Persisted to the config file but
 // redacted when read back through the dashboard config API.
@@ -225,6 +264,18 @@ type AutoSolverFormCreds struct {
 
 type ObservabilityConfig struct {
 	Activity ActivityConfig `json:"activity,omitempty"`
+	Tracing  TracingConfig  `json:"tracing,omitempty"`
+}
+
+// TracingConfig controls OTLP span export. Exporter is "file" (File, by
+// default traces.jsonl under the state dir) or "otlp" (an OTLP/HTTP
+// collector at Endpoint).
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled,omitempty"`
+	Exporter    string  `json:"exporter,omitempty"`
+	Endpoint    string  `json:"endpoint,omitempty"`
+	File        string  `json:"file,omitempty"`
+	SampleRatio float64 `json:"sampleRatio,omitempty"`
 }
 
 type ActivityConfig struct {
@@ -260,6 +311,7 @@ type FileConfig struct {
 	Observability    ObservabilityFileConfig `json:"observability,omitempty"`
 	Sessions         SessionsFileConfig      `json:"sessions,omitempty"`
 	AutoSolver       AutoSolverFileConfig    `json:"autoSolver,omitempty"`
+	Handoff          HandoffFileConfig       `json:"handoff,omitempty"`
 	Browsers         BrowsersConfig          `json:"browsers,omitempty"`
 }
 
@@ -291,12 +343,13 @@ type AgentSessionFileConfig struct {
 }
 
 type DashboardSessionFileConfig struct {
-	Persist                       *bool `json:"persist,omitempty"`
-	IdleTimeoutSec                *int  `json:"idleTimeoutSec,omitempty"`
-	MaxLifetimeSec                *int  `json:"maxLifetimeSec,omitempty"`
-	ElevationWindowSec            *int  `json:"elevationWindowSec,omitempty"`
-	PersistElevationAcrossRestart *bool `json:"persistElevationAcrossRestart,omitempty"`
-	RequireElevation              *bool `json:"requireElevation,omitempty"`
+	Persist                       *bool      `json:"persist,omitempty"`
+	IdleTimeoutSec                *int       `json:"idleTimeoutSec,omitempty"`
+	MaxLifetimeSec                *int       `json:"maxLifetimeSec,omitempty"`
+	ElevationWindowSec            *int       `json:"elevationWindowSec,omitempty"`
+	PersistElevationAcrossRestart *bool      `json:"persistElevationAcrossRestart,omitempty"`
+	RequireElevation              *bool      `json:"requireElevation,omitempty"`
+	OIDC                          OIDCConfig `json:"oidc,omitempty"`
 }
 
 type BrowserConfig struct {
@@ -310,6 +363,9 @@ type BrowserConfig struct {
 	ExtensionPaths    []string           `json:"extensionPaths,omitempty"`
 
 	Proxy BrowserProxyConfig `json:"proxy,omitempty"`
+	// ProxyPools are named proxy sets that instances and tabs can draw from
+	// instead of the single Proxy.
+	ProxyPools ProxyPoolsConfig `json:"proxyPools,omitempty"`
 
 	DefaultTarget string               `json:"defaultTarget,omitempty"`
 	FallbackOrder []string             `json:"fallbackOrder,omitempty"`
@@ -387,6 +443,10 @@ type InstanceDefaultsConfig struct {
 	TabEvictionPolicy string             `json:"tabEvictionPolicy,omitempty"` // Deprecated: use TabPolicy.Eviction
 	TabPolicy         *TabPolicyDefaults `json:"tabPolicy,omitempty"`
 	DialogAutoAccept  *bool              `json:"dialogAutoAccept,omitempty"`
+	// Permissions maps a permission name (see PermissionNames) to granted,
+	// denied, or prompt for every origin. Per-origin overrides set through
+	// the permissions API take precedence.
+	Permissions map[string]string `json:"permissions,omitempty"`
 }
 
 // TabPolicyDefaults groups eviction (cap pressure) and lifecycle (idle) policies
@@ -410,6 +470,7 @@ type SecurityConfig struct {
 	AllowDownload          *bool        `json:"allowDownload,omitempty"`
 	AllowCookies           *bool        `json:"allowCookies,omitempty"`
 	AllowNetworkIntercept  *bool        `json:"allowNetworkIntercept,omitempty"`
+	AllowWebAuthn          *bool        `json:"allowWebAuthn,omitempty"`
 	AllowFileScheme        *bool        `json:"allowFileScheme,omitempty"`
 	AllowedDomains         []string     `json:"allowedDomains,omitempty"`
 	DownloadAllowedDomains []string     `json:"downloadAllowedDomains,omitempty"`
@@ -437,6 +498,8 @@ type MultiInstanceConfig struct {
 	InstancePortStart *int                       `json:"instancePortStart,omitempty"`
 	InstancePortEnd   *int                       `json:"instancePortEnd,omitempty"`
 	Restart           MultiInstanceRestartConfig `json:"restart,omitempty"`
+	AllocationWeights AllocationWeights          `json:"allocationWeights,omitempty"`
+	Autoscale         AutoscaleConfig            `json:"autoscale,omitempty"`
 }
 
 // MultiInstanceRestartConfig controls restart-managed strategy recovery behavior.
@@ -470,10 +533,21 @@ type SchedulerFileConfig struct {
 	MaxPerAgentFlight *int   `json:"maxPerAgentInflight,omitempty"`
 	ResultTTLSec      *int   `json:"resultTTLSec,omitempty"`
 	WorkerCount       *int   `json:"workerCount,omitempty"`
+	Persist           *bool  `json:"persist,omitempty"`
+	OnRestart         string `json:"onRestart,omitempty"`
 }
 
 type ObservabilityFileConfig struct {
 	Activity ActivityFileConfig `json:"activity,omitempty"`
+	Tracing  TracingFileConfig  `json:"tracing,omitempty"`
+}
+
+type TracingFileConfig struct {
+	Enabled     *bool    `json:"enabled,omitempty"`
+	Exporter    string   `json:"exporter,omitempty"`
+	Endpoint    string   `json:"endpoint,omitempty"`
+	File        string   `json:"file,omitempty"`
+	SampleRatio *float64 `json:"sampleRatio,omitempty"`
 }
 
 type ActivityFileConfig struct {
@@ -494,6 +568,15 @@ type ActivityEventsFileConfig struct {
 	Other        *bool `json:"other,omitempty"`
 }
 
+// HandoffFileConfig is the persisted form of HandoffConfig. The webhook
+// secret is write-only from the dashboard.
+type HandoffFileConfig struct {
+	PublicURL     string   `json:"publicUrl,omitempty"`
+	LinkTTLSec    int      `json:"linkTtlSec,omitempty"`
+	Webhooks      []string `json:"webhooks,omitempty"`
+	WebhookSecret string   `json:"webhookSecret,omitempty"`
+}
+
 // AutoSolverFileConfig is the persistent configuration for the autosolver system.
 type AutoSolverFileConfig struct {
 	Enabled           *bool                     `json:"enabled,omitempty"`
@@ -507,10 +590,24 @@ type AutoSolverFileConfig struct {
 	Solvers           []string                  `json:"solvers,omitempty"`
 	LLMProvider       string                    `json:"llmProvider,omitempty"`
 	LLMFallback       *bool                     `json:"llmFallback,omitempty"`
+	LLM               AutoSolverLLMConf         `json:"llm,omitempty"`
 	External          AutoSolverExtConf         `json:"external,omitempty"`
 	Credentials       AutoSolverCredentialsConf `json:"credentials,omitempty"`
 }
 
+// AutoSolverLLMConf is the persisted form of the LLM client settings. The
+// API key is write-only from the dashboard, like the external solver keys.
+type AutoSolverLLMConf struct {
+	Model             string  `json:"model,omitempty"`
+	APIKey            string  `json:"apiKey,omitempty"`
+	BaseURL           string  `json:"baseUrl,omitempty"`
+	MaxTokens         int     `json:"maxTokens,omitempty"`
+	TimeoutSec        int     `json:"timeoutSec,omitempty"`
+	MaxRetries        int     `json:"maxRetries,omitempty"`
+	InputCostPerMTok  float64 `json:"inputCostPerMTok,omitempty"`
+	OutputCostPerMTok float64 `json:"outputCostPerMTok,omitempty"`
+}
+
 // AutoSolverExtConf holds external solver API keys.
 type AutoSolverExtConf struct {
 	CapsolverKey  string `json:"capsolverKey,omitempty"`
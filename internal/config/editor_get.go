@@ -186,9 +186,32 @@ func getObservabilityField(o *ObservabilityFileConfig, field string) (string, er
 	if strings.HasPrefix(field, "activity.") {
 		return getActivityField(&o.Activity, strings.TrimPrefix(field, "activity."))
 	}
+	if strings.HasPrefix(field, "tracing.") {
+		return getTracingField(&o.Tracing, strings.TrimPrefix(field, "tracing."))
+	}
 	return "", fmt.Errorf("unknown field observability.%s", field)
 }
 
+func getTracingField(t *TracingFileConfig, field string) (string, error) {
+	switch field {
+	case "enabled":
+		return formatBoolPtr(t.Enabled), nil
+	case "exporter":
+		return t.Exporter, nil
+	case "endpoint":
+		return t.Endpoint, nil
+	case "file":
+		return t.File, nil
+	case "sampleRatio":
+		if t.SampleRatio == nil {
+			return "", nil
+		}
+		return strconv.FormatFloat(*t.SampleRatio, 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unknown field observability.tracing.%s", field)
+	}
+}
+
 func getActivityField(a *ActivityFileConfig, field string) (string, error) {
 	if strings.HasPrefix(field, "events.") {
 		return getActivityEventField(&a.Events, strings.TrimPrefix(field, "events."))
@@ -237,6 +260,9 @@ func getSessionsField(s *SessionsFileConfig, field string) (string, error) {
 }
 
 func getDashboardSessionField(s *DashboardSessionFileConfig, field string) (string, error) {
+	if strings.HasPrefix(field, "oidc.") {
+		return getOIDCField(&s.OIDC, strings.TrimPrefix(field, "oidc."))
+	}
 	switch field {
 	case "persist":
 		return formatBoolPtr(s.Persist), nil
@@ -255,6 +281,33 @@ func getDashboardSessionField(s *DashboardSessionFileConfig, field string) (stri
 	}
 }
 
+func getOIDCField(o *OIDCConfig, field string) (string, error) {
+	switch field {
+	case "enabled":
+		return strconv.FormatBool(o.Enabled), nil
+	case "issuer":
+		return o.Issuer, nil
+	case "clientId":
+		return o.ClientID, nil
+	case "clientSecret":
+		return o.ClientSecret, nil
+	case "redirectUrl":
+		return o.RedirectURL, nil
+	case "scopes":
+		return strings.Join(o.Scopes, ","), nil
+	case "groupsClaim":
+		return o.GroupsClaim, nil
+	case "allowedGroups":
+		return strings.Join(o.AllowedGroups, ","), nil
+	case "operatorGroups":
+		return strings.Join(o.OperatorGroups, ","), nil
+	case "adminGroups":
+		return strings.Join(o.AdminGroups, ","), nil
+	default:
+		return "", fmt.Errorf("unknown field sessions.dashboard.oidc.%s", field)
+	}
+}
+
 func getInstanceDefaultsField(c *InstanceDefaultsConfig, field string) (string, error) {
 	if after, ok := strings.CutPrefix(field, "tabPolicy."); ok {
 		return getTabPolicyField(c.TabPolicy, after)
@@ -330,6 +383,8 @@ func getSecurityField(s *SecurityConfig, field string) (string, error) {
 		return formatBoolPtr(s.AllowCookies), nil
 	case "allowNetworkIntercept":
 		return formatBoolPtr(s.AllowNetworkIntercept), nil
+	case "allowWebAuthn":
+		return formatBoolPtr(s.AllowWebAuthn), nil
 	case "allowFileScheme":
 		return formatBoolPtr(s.AllowFileScheme), nil
 	case "allowedDomains":
@@ -378,6 +433,9 @@ func getMultiInstanceField(o *MultiInstanceConfig, field string) (string, error)
 	if strings.HasPrefix(field, "restart.") {
 		return getMultiInstanceRestartField(&o.Restart, strings.TrimPrefix(field, "restart."))
 	}
+	if strings.HasPrefix(field, "autoscale.") {
+		return getAutoscaleField(&o.Autoscale, strings.TrimPrefix(field, "autoscale."))
+	}
 
 	switch field {
 	case "strategy":
@@ -393,6 +451,31 @@ func getMultiInstanceField(o *MultiInstanceConfig, field string) (string, error)
 	}
 }
 
+func getAutoscaleField(a *AutoscaleConfig, field string) (string, error) {
+	switch field {
+	case "profiles":
+		return strings.Join(a.Profiles, ","), nil
+	case "minInstances":
+		return strconv.Itoa(a.MinInstances), nil
+	case "maxInstances":
+		return strconv.Itoa(a.MaxInstances), nil
+	case "queueDepthPerInstance":
+		return strconv.Itoa(a.QueueDepthPerInstance), nil
+	case "tabsPerInstance":
+		return strconv.Itoa(a.TabsPerInstance), nil
+	case "cpuPercent":
+		return strconv.Itoa(a.CPUPercent), nil
+	case "memoryMB":
+		return strconv.Itoa(a.MemoryMB), nil
+	case "intervalSec":
+		return strconv.Itoa(a.IntervalSec), nil
+	case "cooldownSec":
+		return strconv.Itoa(a.CooldownSec), nil
+	default:
+		return "", fmt.Errorf("unknown field multiInstance.autoscale.%s", field)
+	}
+}
+
 func getMultiInstanceRestartField(r *MultiInstanceRestartConfig, field string) (string, error) {
 	switch field {
 	case "maxRestarts":
@@ -230,9 +230,38 @@ func setObservabilityField(o *ObservabilityFileConfig, field, value string) erro
 	if strings.HasPrefix(field, "activity.") {
 		return setActivityField(&o.Activity, strings.TrimPrefix(field, "activity."), value)
 	}
+	if strings.HasPrefix(field, "tracing.") {
+		return setTracingField(&o.Tracing, strings.TrimPrefix(field, "tracing."), value)
+	}
 	return fmt.Errorf("unknown field observability.%s", field)
 }
 
+func setTracingField(t *TracingFileConfig, field, value string) error {
+	switch field {
+	case "enabled":
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("observability.tracing.enabled: %w", err)
+		}
+		t.Enabled = &b
+	case "exporter":
+		t.Exporter = value
+	case "endpoint":
+		t.Endpoint = value
+	case "file":
+		t.File = value
+	case "sampleRatio":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("observability.tracing.sampleRatio must be a number: %w", err)
+		}
+		t.SampleRatio = &f
+	default:
+		return fmt.Errorf("unknown field observability.tracing.%s", field)
+	}
+	return nil
+}
+
 func setActivityField(a *ActivityFileConfig, field, value string) error {
 	if strings.HasPrefix(field, "events.") {
 		return setActivityEventField(&a.Events, strings.TrimPrefix(field, "events."), value)
@@ -300,6 +329,9 @@ func setSessionsField(s *SessionsFileConfig, field, value string) error {
 }
 
 func setDashboardSessionField(s *DashboardSessionFileConfig, field, value string) error {
+	if strings.HasPrefix(field, "oidc.") {
+		return setOIDCField(&s.OIDC, strings.TrimPrefix(field, "oidc."), value)
+	}
 	switch field {
 	case "persist":
 		b, err := parseBool(value)
@@ -343,6 +375,38 @@ func setDashboardSessionField(s *DashboardSessionFileConfig, field, value string
 	return nil
 }
 
+func setOIDCField(o *OIDCConfig, field, value string) error {
+	switch field {
+	case "enabled":
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("sessions.dashboard.oidc.enabled: %w", err)
+		}
+		o.Enabled = b
+	case "issuer":
+		o.Issuer = value
+	case "clientId":
+		o.ClientID = value
+	case "clientSecret":
+		o.ClientSecret = value
+	case "redirectUrl":
+		o.RedirectURL = value
+	case "scopes":
+		o.Scopes = parseCSVList(value)
+	case "groupsClaim":
+		o.GroupsClaim = value
+	case "allowedGroups":
+		o.AllowedGroups = parseCSVList(value)
+	case "operatorGroups":
+		o.OperatorGroups = parseCSVList(value)
+	case "adminGroups":
+		o.AdminGroups = parseCSVList(value)
+	default:
+		return fmt.Errorf("unknown field sessions.dashboard.oidc.%s", field)
+	}
+	return nil
+}
+
 func setInstanceDefaultsField(c *InstanceDefaultsConfig, field, value string) error {
 	if strings.HasPrefix(field, "tabPolicy.") {
 		if c.TabPolicy == nil {
@@ -527,6 +591,8 @@ func setSecurityField(s *SecurityConfig, field, value string) error {
 		s.AllowUpload = &b
 	case "allowNetworkIntercept":
 		s.AllowNetworkIntercept = &b
+	case "allowWebAuthn":
+		s.AllowWebAuthn = &b
 	case "allowFileScheme":
 		s.AllowFileScheme = &b
 	case "enableActionGuards":
@@ -555,6 +621,9 @@ func setMultiInstanceField(o *MultiInstanceConfig, field, value string) error {
 	if strings.HasPrefix(field, "restart.") {
 		return setMultiInstanceRestartField(&o.Restart, strings.TrimPrefix(field, "restart."), value)
 	}
+	if strings.HasPrefix(field, "autoscale.") {
+		return setAutoscaleField(&o.Autoscale, strings.TrimPrefix(field, "autoscale."), value)
+	}
 
 	switch field {
 	case "strategy":
@@ -579,6 +648,39 @@ func setMultiInstanceField(o *MultiInstanceConfig, field, value string) error {
 	return nil
 }
 
+func setAutoscaleField(a *AutoscaleConfig, field, value string) error {
+	if field == "profiles" {
+		a.Profiles = parseCSVList(value)
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("multiInstance.autoscale.%s must be a number: %w", field, err)
+	}
+
+	switch field {
+	case "minInstances":
+		a.MinInstances = n
+	case "maxInstances":
+		a.MaxInstances = n
+	case "queueDepthPerInstance":
+		a.QueueDepthPerInstance = n
+	case "tabsPerInstance":
+		a.TabsPerInstance = n
+	case "cpuPercent":
+		a.CPUPercent = n
+	case "memoryMB":
+		a.MemoryMB = n
+	case "intervalSec":
+		a.IntervalSec = n
+	case "cooldownSec":
+		a.CooldownSec = n
+	default:
+		return fmt.Errorf("unknown field multiInstance.autoscale.%s", field)
+	}
+	return nil
+}
+
 func setMultiInstanceRestartField(r *MultiInstanceRestartConfig, field, value string) error {
 	n, err := strconv.Atoi(value)
 	if err != nil {
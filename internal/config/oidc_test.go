@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOIDC(t *testing.T) {
+	good := OIDCConfig{Enabled: true, Issuer: "https://idp.example.com", ClientID: "pinchtab", AdminGroups: []string{"ops"}}
+	if errs := ValidateOIDC("sessions.dashboard.oidc", good); len(errs) != 0 {
+		t.Fatalf("valid config rejected: %v", errs)
+	}
+	if errs := ValidateOIDC("sessions.dashboard.oidc", OIDCConfig{Issuer: "https://idp.example.com"}); len(errs) != 0 {
+		t.Fatalf("disabled config rejected: %v", errs)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     OIDCConfig
+		wantSub string
+	}{
+		{"missing issuer", OIDCConfig{Enabled: true, ClientID: "x", AllowedGroups: []string{"g"}}, "issuer"},
+		{"missing client", OIDCConfig{Enabled: true, Issuer: "https://idp.example.com", AllowedGroups: []string{"g"}}, "clientId"},
+		{"no groups", OIDCConfig{Enabled: true, Issuer: "https://idp.example.com", ClientID: "x"}, "at least one of"},
+		{"plain http issuer", OIDCConfig{Issuer: "http://idp.example.com"}, "must be an https URL"},
+		{"relative redirect", OIDCConfig{RedirectURL: "/callback"}, "redirectUrl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateOIDC("sessions.dashboard.oidc", tt.cfg)
+			if len(errs) == 0 {
+				t.Fatal("expected an error")
+			}
+			var all []string
+			for _, err := range errs {
+				all = append(all, err.Error())
+			}
+			if joined := strings.Join(all, "; "); !strings.Contains(joined, tt.wantSub) {
+				t.Fatalf("errors %q do not mention %q", joined, tt.wantSub)
+			}
+		})
+	}
+
+	if errs := ValidateOIDC("sessions.dashboard.oidc", OIDCConfig{Issuer: "http://127.0.0.1:9000"}); len(errs) != 0 {
+		t.Fatalf("loopback http issuer rejected: %v", errs)
+	}
+}
+
+func TestOIDCConfigRoundTrip(t *testing.T) {
+	fc := DefaultFileConfig()
+	fc.Sessions.Dashboard.OIDC = OIDCConfig{Enabled: true, Issuer: "https://idp.example.com", ClientID: "pinchtab", AdminGroups: []string{"ops"}}
+
+	cfg := &RuntimeConfig{}
+	ApplyFileConfigToRuntime(cfg, &fc)
+	if !cfg.Sessions.Dashboard.OIDC.Enabled || cfg.Sessions.Dashboard.OIDC.AdminGroups[0] != "ops" {
+		t.Fatalf("runtime oidc = %+v", cfg.Sessions.Dashboard.OIDC)
+	}
+	if back := FileConfigFromRuntime(cfg); back.Sessions.Dashboard.OIDC.ClientID != "pinchtab" {
+		t.Fatalf("file oidc = %+v", back.Sessions.Dashboard.OIDC)
+	}
+
+	if err := SetConfigValue(&fc, "sessions.dashboard.oidc.allowedGroups", "eng,qa"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetConfigValue(&fc, "sessions.dashboard.oidc.allowedGroups"); err != nil || got != "eng,qa" {
+		t.Fatalf("allowedGroups = %q, %v", got, err)
+	}
+	if err := SetConfigValue(&fc, "sessions.dashboard.oidc.enabled", "maybe"); err == nil {
+		t.Fatal("expected an error for a non-boolean value")
+	}
+}
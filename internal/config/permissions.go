@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PermissionNames are the browser permissions PinchTab can grant or deny,
+// spelled as in the Permissions API. midi-sysex is MIDI with system-exclusive
+// access ({name: "midi", sysex: true}).
+var PermissionNames = []string{
+	"background-sync",
+	"camera",
+	"clipboard-read",
+	"clipboard-write",
+	"geolocation",
+	"idle-detection",
+	"local-fonts",
+	"microphone",
+	"midi",
+	"midi-sysex",
+	"notifications",
+	"persistent-storage",
+	"push",
+	"screen-wake-lock",
+	"storage-access",
+	"window-management",
+}
+
+// PermissionSettings are the states a permission can be set to.
+var PermissionSettings = []string{"granted", "denied", "prompt"}
+
+func IsKnownPermission(name string) bool {
+	return slices.Contains(PermissionNames, name)
+}
+
+func IsValidPermissionSetting(setting string) bool {
+	return slices.Contains(PermissionSettings, setting)
+}
+
+// ValidatePermissions checks a permission name → setting map, reporting the
+// first offending entry in name order so errors are stable.
+func ValidatePermissions(perms map[string]string) error {
+	names := make([]string, 0, len(perms))
+	for name := range perms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !IsKnownPermission(name) {
+			return fmt.Errorf("unknown permission %q (must be one of %s)", name, strings.Join(PermissionNames, ", "))
+		}
+		if setting := perms[name]; !IsValidPermissionSetting(setting) {
+			return fmt.Errorf("invalid setting %q for %s (must be granted, denied, or prompt)", setting, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Proxy pool selection strategies.
+const (
+	ProxyPoolRoundRobin = "round_robin"
+	ProxyPoolRandom     = "random"
+	ProxyPoolSticky     = "sticky"
+)
+
+// ProxyPoolsConfig maps pool name -> pool. Names follow the browser target
+// rules (`^[a-z][a-z0-9-]{0,31}$`).
+type ProxyPoolsConfig map[string]ProxyPoolConfig
+
+// ProxyPoolConfig is a named set of interchangeable proxies. Instances and
+// tabs draw one proxy from the pool; a proxy that fails (407, connection or
+// tunnel errors, failed health check) sits out CooldownSec before it is
+// handed out again.
+type ProxyPoolConfig struct {
+	Proxies []BrowserProxyConfig `json:"proxies"`
+	// Strategy is round_robin (default), random, or sticky. Sticky keeps a
+	// key (session, agent or explicit proxyKey) on the same proxy until that
+	// proxy goes bad.
+	Strategy    string                     `json:"strategy,omitempty"`
+	HealthCheck ProxyPoolHealthCheckConfig `json:"healthCheck,omitempty"`
+	// CooldownSec defaults to 300.
+	CooldownSec int `json:"cooldownSec,omitempty"`
+	// Region labels where the pool's proxies exit (e.g. "eu-west"). Requests
+	// can ask for an instance behind a region with ?proxyRegion=.
+	Region string `json:"region,omitempty"`
+}
+
+// ProxyPoolHealthCheckConfig enables active checks: every IntervalSec each
+// proxy fetches URL and must answer with a non-5xx status. Empty URL leaves
+// only passive checks (failures seen by tabs).
+type ProxyPoolHealthCheckConfig struct {
+	URL         string `json:"url,omitempty"`
+	IntervalSec int    `json:"intervalSec,omitempty"` // default 60
+	TimeoutSec  int    `json:"timeoutSec,omitempty"`  // default 10
+}
+
+// NormalizeProxyPoolStrategy maps "" to round_robin.
+func NormalizeProxyPoolStrategy(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ProxyPoolRoundRobin
+	}
+	return s
+}
+
+func cloneProxyPoolsConfig(in ProxyPoolsConfig) ProxyPoolsConfig {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(ProxyPoolsConfig, len(in))
+	for name, p := range in {
+		proxies := make([]BrowserProxyConfig, len(p.Proxies))
+		for i, px := range p.Proxies {
+			proxies[i] = cloneBrowserProxyConfig(px)
+		}
+		p.Proxies = proxies
+		out[name] = p
+	}
+	return out
+}
+
+// ProxyPoolForRegion returns the first pool, by name, whose region matches
+// region (case-insensitive).
+func ProxyPoolForRegion(pools ProxyPoolsConfig, region string) (string, bool) {
+	names := make([]string, 0, len(pools))
+	for name, p := range pools {
+		if region != "" && strings.EqualFold(p.Region, region) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+// ValidateProxyPools returns nil when no pools are configured.
+func ValidateProxyPools(field string, pools ProxyPoolsConfig) []error {
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		p := pools[name]
+		prefix := fmt.Sprintf("%s.%s", field, name)
+		if !IsValidBrowserTargetName(name) {
+			errs = append(errs, ValidationError{
+				Field:   prefix,
+				Message: fmt.Sprintf("invalid pool name %q (must match ^[a-z][a-z0-9-]{0,31}$)", name),
+			})
+		}
+		if len(p.Proxies) == 0 {
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".proxies",
+				Message: "at least one proxy is required",
+			})
+		}
+		seen := map[string]bool{}
+		for i, px := range p.Proxies {
+			pxField := fmt.Sprintf("%s.proxies[%d]", prefix, i)
+			if px.IsZero() {
+				errs = append(errs, ValidationError{Field: pxField + ".server", Message: "server is required"})
+				continue
+			}
+			errs = append(errs, ValidateBrowserProxy(pxField, px)...)
+			key := strings.ToLower(strings.TrimSpace(px.Server)) + "|" + px.Username
+			if seen[key] {
+				errs = append(errs, ValidationError{
+					Field:   pxField + ".server",
+					Message: fmt.Sprintf("duplicate proxy %q", px.Server),
+				})
+			}
+			seen[key] = true
+		}
+		switch NormalizeProxyPoolStrategy(p.Strategy) {
+		case ProxyPoolRoundRobin, ProxyPoolRandom, ProxyPoolSticky:
+		default:
+			errs = append(errs, ValidationError{
+				Field:   prefix + ".strategy",
+				Message: fmt.Sprintf("unknown strategy %q (use round_robin, random, or sticky)", p.Strategy),
+			})
+		}
+		if p.CooldownSec < 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".cooldownSec", Message: "must be >= 0"})
+		}
+		hc := p.HealthCheck
+		if raw := strings.TrimSpace(hc.URL); raw != "" {
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, ValidationError{
+					Field:   prefix + ".healthCheck.url",
+					Message: fmt.Sprintf("%q must be an http(s) URL", raw),
+				})
+			}
+		}
+		if hc.IntervalSec < 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".healthCheck.intervalSec", Message: "must be >= 0"})
+		}
+		if hc.TimeoutSec < 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".healthCheck.timeoutSec", Message: "must be >= 0"})
+		}
+	}
+	return errs
+}
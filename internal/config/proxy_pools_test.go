@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateProxyPools(t *testing.T) {
+	good := ProxyPoolConfig{
+		Strategy: "sticky",
+		Proxies: []BrowserProxyConfig{
+			{Server: "http://a.example:8080", Username: "u", Password: "p"},
+			{Server: "socks5://b.example:1080"},
+		},
+		HealthCheck: ProxyPoolHealthCheckConfig{URL: "https://example.com/generate_204", IntervalSec: 30},
+	}
+	if errs := ValidateProxyPools("browser.proxyPools", ProxyPoolsConfig{"residential": good}); len(errs) != 0 {
+		t.Fatalf("valid pool rejected: %v", errs)
+	}
+
+	tests := []struct {
+		name    string
+		pools   ProxyPoolsConfig
+		wantSub string
+	}{
+		{"bad name", ProxyPoolsConfig{"Bad_Name": good}, "invalid pool name"},
+		{"no proxies", ProxyPoolsConfig{"dc": {}}, "at least one proxy"},
+		{"bad strategy", ProxyPoolsConfig{"dc": {Strategy: "least_used", Proxies: good.Proxies}}, "unknown strategy"},
+		{"duplicate proxy", ProxyPoolsConfig{"dc": {Proxies: []BrowserProxyConfig{
+			{Server: "http://a.example:8080"}, {Server: "HTTP://a.example:8080"},
+		}}}, "duplicate proxy"},
+		{"bad proxy", ProxyPoolsConfig{"dc": {Proxies: []BrowserProxyConfig{{Server: "ftp://a.example:21"}}}}, "scheme"},
+		{"bad health url", ProxyPoolsConfig{"dc": {Proxies: good.Proxies, HealthCheck: ProxyPoolHealthCheckConfig{URL: "example.com"}}}, "http(s) URL"},
+		{"negative cooldown", ProxyPoolsConfig{"dc": {Proxies: good.Proxies, CooldownSec: -1}}, "cooldownSec"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateProxyPools("browser.proxyPools", tt.pools)
+			if len(errs) == 0 {
+				t.Fatal("expected an error")
+			}
+			var all []string
+			for _, err := range errs {
+				all = append(all, err.Error())
+			}
+			if joined := strings.Join(all, "; "); !strings.Contains(joined, tt.wantSub) {
+				t.Fatalf("errors %q do not mention %q", joined, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestProxyPoolForRegion(t *testing.T) {
+	pools := ProxyPoolsConfig{
+		"eu-b": {Region: "eu-west"},
+		"eu-a": {Region: "EU-West"},
+		"us":   {Region: "us-east"},
+		"any":  {},
+	}
+	if got, ok := ProxyPoolForRegion(pools, "eu-west"); !ok || got != "eu-a" {
+		t.Fatalf("eu-west = %q, %v; want eu-a", got, ok)
+	}
+	if _, ok := ProxyPoolForRegion(pools, "ap-south"); ok {
+		t.Fatal("ap-south should have no pool")
+	}
+	if _, ok := ProxyPoolForRegion(pools, ""); ok {
+		t.Fatal("empty region should match nothing")
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -62,6 +63,10 @@ func ValidateFileConfig(fc *FileConfig) []error {
 	}
 	errs = append(errs, validateCloakBrowserConfig(fc.Browser.Cloak)...)
 	errs = append(errs, ValidateBrowserProxy("browser.proxy", fc.Browser.Proxy)...)
+	errs = append(errs, ValidateProxyPools("browser.proxyPools", fc.Browser.ProxyPools)...)
+	errs = append(errs, ValidateAutoscale("multiInstance.autoscale", fc.MultiInstance.Autoscale)...)
+	errs = append(errs, ValidateOIDC("sessions.dashboard.oidc", fc.Sessions.Dashboard.OIDC)...)
+	errs = append(errs, ValidateAllocationWeights("multiInstance.allocationWeights", fc.MultiInstance.AllocationWeights)...)
 	errs = append(errs, ValidateBrowserTargets(fc.Browser)...)
 	errs = append(errs, validateBrowsersBlock(*fc)...)
 
@@ -161,6 +166,12 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			Message: fmt.Sprintf("must be >= 1 (got %d)", *fc.InstanceDefaults.MaxTabs),
 		})
 	}
+	if err := ValidatePermissions(fc.InstanceDefaults.Permissions); err != nil {
+		errs = append(errs, ValidationError{
+			Field:   "instanceDefaults.permissions",
+			Message: err.Error(),
+		})
+	}
 	if fc.InstanceDefaults.MaxParallelTabs != nil && *fc.InstanceDefaults.MaxParallelTabs < 0 {
 		errs = append(errs, ValidationError{
 			Field:   "instanceDefaults.maxParallelTabs",
@@ -172,7 +183,7 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		if !isValidStrategy(fc.MultiInstance.Strategy) {
 			errs = append(errs, ValidationError{
 				Field:   "multiInstance.strategy",
-				Message: fmt.Sprintf("invalid value %q (must be simple, explicit, simple-autorestart, or always-on)", fc.MultiInstance.Strategy),
+				Message: fmt.Sprintf("invalid value %q (must be simple, explicit, simple-autorestart, always-on, no-instance, or autoscale)", fc.MultiInstance.Strategy),
 			})
 		}
 	}
@@ -180,11 +191,18 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		if !isValidAllocationPolicy(fc.MultiInstance.AllocationPolicy) {
 			errs = append(errs, ValidationError{
 				Field:   "multiInstance.allocationPolicy",
-				Message: fmt.Sprintf("invalid value %q (must be fcfs, round_robin, or random)", fc.MultiInstance.AllocationPolicy),
+				Message: fmt.Sprintf("invalid value %q (must be fcfs, round_robin, random, least_loaded, or weighted)", fc.MultiInstance.AllocationPolicy),
 			})
 		}
 	}
 
+	if fc.Scheduler.OnRestart != "" && !isValidSchedulerRestartPolicy(fc.Scheduler.OnRestart) {
+		errs = append(errs, ValidationError{
+			Field:   "scheduler.onRestart",
+			Message: fmt.Sprintf("invalid value %q (must be fail or requeue)", fc.Scheduler.OnRestart),
+		})
+	}
+
 	for _, scheme := range fc.Security.Attach.AllowSchemes {
 		if !isValidAttachScheme(scheme) {
 			errs = append(errs, ValidationError{
@@ -271,6 +289,32 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			Message: fmt.Sprintf("retry base delay (%d) must be <= retry max delay (%d)", *fc.AutoSolver.RetryBaseDelayMs, *fc.AutoSolver.RetryMaxDelayMs),
 		})
 	}
+	if p := strings.ToLower(strings.TrimSpace(fc.AutoSolver.LLMProvider)); p != "" && p != "openai" && p != "anthropic" {
+		errs = append(errs, ValidationError{
+			Field:   "autoSolver.llmProvider",
+			Message: fmt.Sprintf("invalid value %q (must be openai or anthropic)", fc.AutoSolver.LLMProvider),
+		})
+	}
+	if llm := fc.AutoSolver.LLM; llm.MaxTokens < 0 || llm.TimeoutSec < 0 || llm.MaxRetries < -1 {
+		errs = append(errs, ValidationError{
+			Field:   "autoSolver.llm",
+			Message: "maxTokens and timeoutSec must be >= 0, maxRetries >= -1",
+		})
+	}
+	if llm := fc.AutoSolver.LLM; llm.InputCostPerMTok < 0 || llm.OutputCostPerMTok < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "autoSolver.llm",
+			Message: "inputCostPerMTok and outputCostPerMTok must be >= 0",
+		})
+	}
+	if b := strings.TrimSpace(fc.AutoSolver.LLM.BaseURL); b != "" {
+		if !isAbsoluteHTTPURL(b) {
+			errs = append(errs, ValidationError{
+				Field:   "autoSolver.llm.baseUrl",
+				Message: fmt.Sprintf("must be an absolute http(s) URL (got %q)", b),
+			})
+		}
+	}
 	for _, solverName := range fc.AutoSolver.Solvers {
 		if strings.TrimSpace(solverName) == "" {
 			errs = append(errs, ValidationError{
@@ -281,6 +325,28 @@ func ValidateFileConfig(fc *FileConfig) []error {
 		}
 	}
 
+	if b := strings.TrimSpace(fc.Handoff.PublicURL); b != "" && !isAbsoluteHTTPURL(b) {
+		errs = append(errs, ValidationError{
+			Field:   "handoff.publicUrl",
+			Message: fmt.Sprintf("must be an absolute http(s) URL (got %q)", b),
+		})
+	}
+	if fc.Handoff.LinkTTLSec < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "handoff.linkTtlSec",
+			Message: fmt.Sprintf("must be >= 0 (got %d)", fc.Handoff.LinkTTLSec),
+		})
+	}
+	for _, hook := range fc.Handoff.Webhooks {
+		if !isAbsoluteHTTPURL(strings.TrimSpace(hook)) {
+			errs = append(errs, ValidationError{
+				Field:   "handoff.webhooks",
+				Message: fmt.Sprintf("must be absolute http(s) URLs (got %q)", hook),
+			})
+			break
+		}
+	}
+
 	if fc.Observability.Activity.SessionIdleSec != nil && *fc.Observability.Activity.SessionIdleSec < 0 {
 		errs = append(errs, ValidationError{
 			Field:   "observability.activity.sessionIdleSec",
@@ -293,6 +359,18 @@ func ValidateFileConfig(fc *FileConfig) []error {
 			Message: fmt.Sprintf("must be > 0 (got %d)", *fc.Observability.Activity.RetentionDays),
 		})
 	}
+	if fc.Observability.Tracing.Exporter != "" && !isValidTracingExporter(fc.Observability.Tracing.Exporter) {
+		errs = append(errs, ValidationError{
+			Field:   "observability.tracing.exporter",
+			Message: fmt.Sprintf("invalid value %q (must be file or otlp)", fc.Observability.Tracing.Exporter),
+		})
+	}
+	if r := fc.Observability.Tracing.SampleRatio; r != nil && (*r < 0 || *r > 1) {
+		errs = append(errs, ValidationError{
+			Field:   "observability.tracing.sampleRatio",
+			Message: fmt.Sprintf("must be between 0 and 1 (got %g)", *r),
+		})
+	}
 	if fc.Sessions.Dashboard.IdleTimeoutSec != nil && *fc.Sessions.Dashboard.IdleTimeoutSec <= 0 {
 		errs = append(errs, ValidationError{
 			Field:   "sessions.dashboard.idleTimeoutSec",
@@ -422,9 +500,11 @@ var (
 	stealthLevels      = []string{"light", "medium", "full"}
 	evictionPolicies   = []string{"reject", "close_oldest", "close_lru"}
 	lifecyclePolicies  = []string{"keep", "close_idle"}
-	strategies         = []string{"simple", "explicit", "simple-autorestart", "always-on", "no-instance"}
-	allocationPolicies = []string{"fcfs", "round_robin", "random"}
+	strategies         = []string{"simple", "explicit", "simple-autorestart", "always-on", "no-instance", "autoscale"}
+	allocationPolicies = []string{"fcfs", "round_robin", "random", "least_loaded", "weighted"}
+	schedulerRestarts  = []string{"fail", "requeue"}
 	attachSchemes      = []string{"ws", "wss", "http", "https"}
+	tracingExporters   = []string{"file", "otlp"}
 )
 
 func isValidCloakPlatform(platform string) bool {
@@ -455,10 +535,18 @@ func isValidAllocationPolicy(policy string) bool {
 	return slices.Contains(allocationPolicies, policy)
 }
 
+func isValidSchedulerRestartPolicy(policy string) bool {
+	return slices.Contains(schedulerRestarts, policy)
+}
+
 func isValidAttachScheme(scheme string) bool {
 	return slices.Contains(attachSchemes, scheme)
 }
 
+func isValidTracingExporter(exporter string) bool {
+	return slices.Contains(tracingExporters, exporter)
+}
+
 func ValidStealthLevels() []string {
 	return slices.Clone(stealthLevels)
 }
@@ -623,3 +711,8 @@ func validateBrowsersBlock(fc FileConfig) []error {
 
 	return errs
 }
+
+func isAbsoluteHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -198,6 +198,29 @@ func TestValidateFileConfig_InvalidStealthLevel(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_Permissions(t *testing.T) {
+	tests := []struct {
+		perms   map[string]string
+		wantErr bool
+	}{
+		{nil, false},
+		{map[string]string{"notifications": "denied", "geolocation": "granted", "midi-sysex": "prompt"}, false},
+		{map[string]string{"telepathy": "granted"}, true},
+		{map[string]string{"camera": "allow"}, true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{
+			InstanceDefaults: InstanceDefaultsConfig{Permissions: tt.perms},
+		}
+		errs := ValidateFileConfig(fc)
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("permissions=%v: got error=%v, want error=%v", tt.perms, hasErr, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidEvictionPolicy(t *testing.T) {
 	tests := []struct {
 		policy  string
@@ -232,6 +255,7 @@ func TestValidateFileConfig_InvalidStrategy(t *testing.T) {
 		{"explicit", false},
 		{"simple-autorestart", false},
 		{"always-on", false},
+		{"autoscale", false},
 		{"", false},
 		{"auto", true},
 		{"default", true},
@@ -257,6 +281,8 @@ func TestValidateFileConfig_InvalidAllocationPolicy(t *testing.T) {
 		{"fcfs", false},
 		{"round_robin", false},
 		{"random", false},
+		{"least_loaded", false},
+		{"weighted", false},
 		{"", false},
 		{"fifo", true},
 		{"roundrobin", true}, // underscore required
@@ -274,6 +300,29 @@ func TestValidateFileConfig_InvalidAllocationPolicy(t *testing.T) {
 	}
 }
 
+func TestValidateFileConfig_InvalidSchedulerOnRestart(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{"fail", false},
+		{"requeue", false},
+		{"", false},
+		{"retry", true},
+	}
+
+	for _, tt := range tests {
+		fc := &FileConfig{
+			Scheduler: SchedulerFileConfig{OnRestart: tt.policy},
+		}
+		errs := ValidateFileConfig(fc)
+		hasErr := len(errs) > 0
+		if hasErr != tt.wantErr {
+			t.Errorf("onRestart=%q: got error=%v, want error=%v", tt.policy, hasErr, tt.wantErr)
+		}
+	}
+}
+
 func TestValidateFileConfig_InvalidAttachScheme(t *testing.T) {
 	tests := []struct {
 		schemes []string
@@ -51,6 +51,7 @@ func UpdateSensitiveEndpoints(value string) (*config.RuntimeConfig, bool, error)
 			"cookies":          "security.allowCookies",
 			"upload":           "security.allowUpload",
 			"networkIntercept": "security.allowNetworkIntercept",
+			"webauthn":         "security.allowWebAuthn",
 		} {
 			if err := config.SetConfigValue(fc, path, fmt.Sprintf("%t", selected[endpoint])); err != nil {
 				return fmt.Errorf("set %s: %w", endpoint, err)
@@ -136,6 +137,7 @@ func BuildGuardsDownConfig(fc *config.FileConfig) (bool, error) {
 		{path: "security.allowCookies", value: "true"},
 		{path: "security.allowUpload", value: "true"},
 		{path: "security.allowNetworkIntercept", value: "true"},
+		{path: "security.allowWebAuthn", value: "true"},
 		{path: "security.attach.enabled", value: "true"},
 		{path: "security.attach.allowHosts", value: "127.0.0.1,localhost,::1"},
 		{path: "security.attach.allowSchemes", value: "ws,wss"},
@@ -212,6 +214,7 @@ type securityConfigValues struct {
 	AllowDownload         bool
 	AllowCookies          bool
 	AllowNetworkIntercept bool
+	AllowWebAuthn         bool
 	DownloadMaxBytes      int
 	AllowUpload           bool
 	UploadMaxRequestBytes int
@@ -252,6 +255,9 @@ func securityDefaultsSnapshot(fc *config.FileConfig) securityDefaultsState {
 	if fc.Security.AllowNetworkIntercept != nil {
 		s.Security.AllowNetworkIntercept = *fc.Security.AllowNetworkIntercept
 	}
+	if fc.Security.AllowWebAuthn != nil {
+		s.Security.AllowWebAuthn = *fc.Security.AllowWebAuthn
+	}
 	if fc.Security.DownloadMaxBytes != nil {
 		s.Security.DownloadMaxBytes = *fc.Security.DownloadMaxBytes
 	}
@@ -29,6 +29,9 @@ func activityEventToLiveEvent(evt activity.Event) apiTypes.ActivityEvent {
 	if evt.SessionID != "" {
 		details["sessionId"] = evt.SessionID
 	}
+	if evt.TraceID != "" {
+		details["traceId"] = evt.TraceID
+	}
 	if evt.InstanceID != "" {
 		details["instanceId"] = evt.InstanceID
 	}
@@ -12,6 +12,7 @@ type AdminDeps struct {
 	ConfigAPI     *ConfigAPI
 	AuthAPI       *AuthAPI
 	SessionAPI    *SessionAPI
+	TokenAPI      *TokenAPI
 	Activity      activity.Recorder
 	ServerMetrics func() map[string]any
 }
@@ -24,6 +25,7 @@ func (d *Dashboard) RegisterAdminRoutes(mux *http.ServeMux, deps AdminDeps) {
 	if deps.SessionAPI != nil {
 		deps.SessionAPI.RegisterHandlers(mux)
 	}
+	deps.TokenAPI.RegisterHandlers(mux)
 	activity.RegisterHandlers(mux, deps.Activity)
 	mux.HandleFunc("GET /api/metrics", func(w http.ResponseWriter, r *http.Request) {
 		httpx.JSON(w, 200, map[string]any{"metrics": deps.ServerMetrics()})
@@ -40,6 +40,9 @@ func (a *SessionAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
 		AgentID string `json:"agentId"`
 		Label   string `json:"label,omitempty"`
 		Browser string `json:"browser,omitempty"`
+		// Isolated gives the session a browser context of its own on every
+		// instance it uses.
+		Isolated bool `json:"isolated,omitempty"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10)).Decode(&req); err != nil {
 		httpx.ErrorCode(w, http.StatusBadRequest, "bad_request", "invalid request body", false, nil)
@@ -61,6 +64,9 @@ func (a *SessionAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
 		httpx.ErrorCode(w, http.StatusInternalServerError, "create_failed", "failed to create session", false, nil)
 		return
 	}
+	if req.Isolated {
+		a.store.SetIsolated(sessionID, true)
+	}
 
 	sess, _ := a.store.Get(sessionID)
 
@@ -82,6 +88,9 @@ func (a *SessionAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
 	if sess.Browser != "" {
 		resp["browser"] = sess.Browser
 	}
+	if sess.Isolated {
+		resp["isolated"] = true
+	}
 	httpx.JSON(w, http.StatusCreated, resp)
 }
 
@@ -64,6 +64,28 @@ func TestAgentSessionAPI_Create(t *testing.T) {
 	}
 }
 
+func TestAgentSessionAPI_Create_Isolated(t *testing.T) {
+	store := newTestSessionStore()
+	mux := newTestSessionMux(store)
+
+	req := httptest.NewRequest("POST", "/sessions", strings.NewReader(`{"agentId":"agent-1","isolated":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	resp := decodeSessionResponse(t, w)
+	if resp["isolated"] != true {
+		t.Fatalf("isolated = %v, want true", resp["isolated"])
+	}
+	sess, ok := store.Get(resp["id"].(string))
+	if !ok || !sess.Isolated {
+		t.Fatalf("stored session = %+v, want isolated", sess)
+	}
+}
+
 func TestAgentSessionAPI_Create_MissingAgentID(t *testing.T) {
 	store := newTestSessionStore()
 	mux := newTestSessionMux(store)
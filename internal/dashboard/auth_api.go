@@ -18,6 +18,7 @@ type AuthAPI struct {
 	runtime      *config.RuntimeConfig
 	sessions     *browsersession.Manager
 	loginLimiter *authn.AttemptLimiter
+	oidc         oidcLogins
 }
 
 func NewAuthAPI(runtime *config.RuntimeConfig, sessions *browsersession.Manager) *AuthAPI {
@@ -35,6 +36,7 @@ func (a *AuthAPI) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/auth/login", a.HandleLogin)
 	mux.HandleFunc("POST /api/auth/elevate", a.HandleElevate)
 	mux.HandleFunc("POST /api/auth/logout", a.HandleLogout)
+	a.registerOIDCHandlers(mux)
 }
 
 func (a *AuthAPI) HandleLogin(w http.ResponseWriter, r *http.Request) {
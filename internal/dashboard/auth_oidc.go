@@ -0,0 +1,366 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/oidc"
+)
+
+const (
+	oidcCallbackPath = "/api/auth/oidc/callback"
+	oidcLoginTTL     = 10 * time.Minute
+	oidcMaxPending   = 1000
+)
+
+// oidcLogins holds the discovered provider and the logins waiting for
+// their callback.
+type oidcLogins struct {
+	mu          sync.Mutex
+	provider    *oidc.Provider
+	providerKey string
+	pending     map[string]oidcPending
+}
+
+type oidcPending struct {
+	verifier    string
+	nonce       string
+	redirectURL string
+	next        string
+	created     time.Time
+	// elevateSession is set when an admin signs in again to elevate an
+	// existing dashboard session.
+	elevateSession string
+}
+
+func (a *AuthAPI) registerOIDCHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/auth/oidc", a.HandleOIDCStatus)
+	mux.HandleFunc("GET /api/auth/oidc/login", a.HandleOIDCLogin)
+	mux.HandleFunc("GET "+oidcCallbackPath, a.HandleOIDCCallback)
+	mux.HandleFunc("GET /api/auth/oidc/session", a.HandleOIDCSession)
+}
+
+func (a *AuthAPI) oidcConfig() config.OIDCConfig {
+	if a == nil || a.runtime == nil {
+		return config.OIDCConfig{}
+	}
+	return a.runtime.Sessions.Dashboard.OIDC
+}
+
+// HandleOIDCStatus tells the login page whether single sign-on is offered.
+func (a *AuthAPI) HandleOIDCStatus(w http.ResponseWriter, _ *http.Request) {
+	httpx.JSON(w, http.StatusOK, map[string]any{"enabled": a.oidcConfig().Enabled})
+}
+
+// HandleOIDCSession returns the single sign-on identity of the caller's
+// dashboard session, or a null identity for token sessions.
+func (a *AuthAPI) HandleOIDCSession(w http.ResponseWriter, r *http.Request) {
+	var identity *authn.Identity
+	if id, ok := authn.IdentityFromRequest(r); ok {
+		identity = &id
+	}
+	httpx.JSON(w, http.StatusOK, map[string]any{"identity": identity})
+}
+
+// HandleOIDCLogin starts an authorization-code login with PKCE. With
+// ?elevate=1 an admin's existing session is elevated once they sign in
+// again.
+func (a *AuthAPI) HandleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	cfg := a.oidcConfig()
+	if !cfg.Enabled {
+		httpx.ErrorCode(w, http.StatusNotFound, "oidc_disabled", "single sign-on is not enabled", false, nil)
+		return
+	}
+	token := strings.TrimSpace(a.runtime.Token)
+	if token == "" {
+		httpx.ErrorCode(w, http.StatusServiceUnavailable, "token_required", "server token is not configured", false, nil)
+		return
+	}
+	if a.sessions == nil {
+		httpx.ErrorCode(w, http.StatusInternalServerError, "session_unavailable", "dashboard sessions are not configured", false, nil)
+		return
+	}
+	if a.requiresHTTPSForDashboardSession(r) {
+		httpx.ErrorCode(w, http.StatusBadRequest, "secure_cookie_requires_https", "server.cookieSecure=true requires HTTPS for dashboard login", false, nil)
+		return
+	}
+
+	pending := oidcPending{
+		redirectURL: a.oidcRedirectURL(r, cfg),
+		next:        safeNextPath(r.URL.Query().Get("next"), "/dashboard/monitoring"),
+		created:     time.Now(),
+	}
+	extra := url.Values{}
+	if r.URL.Query().Get("elevate") == "1" {
+		creds := authn.CredentialsFromRequest(r)
+		identity, ok := a.sessions.Identity(creds.Value, token)
+		if creds.Method != authn.MethodCookie || !ok {
+			httpx.ErrorCode(w, http.StatusForbidden, "session_auth_required", "single sign-on session required", false, nil)
+			return
+		}
+		if identity.Role != authn.RoleAdmin {
+			authn.AuditWarn(authn.WithIdentity(r, identity), "auth.elevation_failed", "reason", "role")
+			httpx.ErrorCode(w, http.StatusForbidden, "role_forbidden", "only admins can elevate", false, nil)
+			return
+		}
+		pending.elevateSession = creds.Value
+		pending.next = safeNextPath(r.URL.Query().Get("next"), "/dashboard/settings")
+		// Elevation needs a fresh sign-in, not a silent one from the
+		// provider's own session.
+		extra.Set("prompt", "login")
+		extra.Set("max_age", "0")
+	}
+
+	provider, err := a.oidcProvider(r, cfg)
+	if err != nil {
+		authn.AuditWarn(r, "auth.oidc_failed", "reason", "discovery", "err", err.Error())
+		httpx.ErrorCode(w, http.StatusBadGateway, "oidc_unavailable", "identity provider is unavailable", true, nil)
+		return
+	}
+
+	state, err1 := oidc.NewVerifier()
+	nonce, err2 := oidc.NewVerifier()
+	verifier, err3 := oidc.NewVerifier()
+	if err1 != nil || err2 != nil || err3 != nil {
+		httpx.ErrorCode(w, http.StatusInternalServerError, "oidc_failed", "failed to start single sign-on", false, nil)
+		return
+	}
+	pending.nonce = nonce
+	pending.verifier = verifier
+	a.oidc.put(state, pending)
+
+	authn.SetOIDCStateCookie(w, r, state, oidcLoginTTL, a.runtime.TrustProxyHeaders, cookieSecureSetting(a.runtime))
+	http.Redirect(w, r, provider.AuthCodeURL(pending.redirectURL, state, nonce, verifier, extra), http.StatusFound)
+}
+
+// HandleOIDCCallback completes a login: it checks state, redeems the code,
+// maps groups to a role, and creates or elevates the dashboard session.
+// Failures redirect to the login page with an sso_error code.
+func (a *AuthAPI) HandleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	cfg := a.oidcConfig()
+	trustProxy := a.runtime != nil && a.runtime.TrustProxyHeaders
+	authn.ClearOIDCStateCookie(w, r, trustProxy, cookieSecureSetting(a.runtime))
+	if !cfg.Enabled {
+		a.oidcFail(w, r, "oidc_disabled")
+		return
+	}
+	token := strings.TrimSpace(a.runtime.Token)
+	if token == "" || a.sessions == nil {
+		a.oidcFail(w, r, "session_failed")
+		return
+	}
+
+	q := r.URL.Query()
+	state := q.Get("state")
+	cookie, err := r.Cookie(authn.OIDCStateCookieName)
+	if state == "" || err != nil || cookie.Value != state {
+		a.oidcFail(w, r, "state_mismatch")
+		return
+	}
+	pending, ok := a.oidc.take(state)
+	if !ok {
+		a.oidcFail(w, r, "login_expired")
+		return
+	}
+	if idpErr := q.Get("error"); idpErr != "" {
+		authn.AuditWarn(r, "auth.oidc_failed", "reason", "provider_error", "error", idpErr)
+		a.oidcFail(w, r, "provider_error")
+		return
+	}
+
+	provider, err := a.oidcProvider(r, cfg)
+	if err != nil {
+		authn.AuditWarn(r, "auth.oidc_failed", "reason", "discovery", "err", err.Error())
+		a.oidcFail(w, r, "provider_unavailable")
+		return
+	}
+	claims, err := provider.Exchange(r.Context(), q.Get("code"), pending.redirectURL, pending.verifier, pending.nonce)
+	if err != nil {
+		authn.AuditWarn(r, "auth.oidc_failed", "reason", "exchange", "err", err.Error())
+		a.oidcFail(w, r, "exchange_failed")
+		return
+	}
+
+	identity := authn.Identity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+		Name:    firstNonEmpty(claims.Name, claims.PreferredUsername),
+		Groups:  claims.StringList(oidcGroupsClaim(cfg)),
+	}
+	identity.Role = oidcRole(cfg, identity.Groups)
+	r = authn.WithIdentity(r, identity)
+	if identity.Role == "" {
+		authn.AuditWarn(r, "auth.oidc_denied", "reason", "groups", "groups", identity.Groups)
+		a.oidcFail(w, r, "not_allowed")
+		return
+	}
+
+	if pending.elevateSession != "" {
+		a.finishOIDCElevation(w, r, pending, identity, claims.AuthTime, token)
+		return
+	}
+
+	sessionID, err := a.sessions.CreateWithIdentity(token, identity)
+	if err != nil {
+		a.oidcFail(w, r, "session_failed")
+		return
+	}
+	authn.SetSessionCookie(w, r, sessionID, a.sessions.MaxLifetime(), trustProxy, cookieSecureSetting(a.runtime))
+	authn.AuditLog(r, "auth.session_created",
+		"loginMethod", "oidc",
+		"sessionIdleSec", int(a.sessions.IdleTimeout().Seconds()),
+		"sessionMaxLifetimeSec", int(a.sessions.MaxLifetime().Seconds()),
+	)
+	http.Redirect(w, r, pending.next, http.StatusFound)
+}
+
+func (a *AuthAPI) finishOIDCElevation(w http.ResponseWriter, r *http.Request, pending oidcPending, identity authn.Identity, authTime time.Time, token string) {
+	current, ok := a.sessions.Identity(pending.elevateSession, token)
+	switch {
+	case !ok:
+		a.oidcFail(w, r, "login_expired")
+		return
+	case current.Issuer != identity.Issuer || current.Subject != identity.Subject:
+		// Someone else signed in at the provider; never elevate a session
+		// for a different user.
+		authn.AuditWarn(r, "auth.elevation_failed", "reason", "identity_mismatch", "sessionSubject", current.Subject)
+		a.oidcFail(w, r, "identity_mismatch")
+		return
+	case identity.Role != authn.RoleAdmin:
+		authn.AuditWarn(r, "auth.elevation_failed", "reason", "role")
+		a.oidcFail(w, r, "not_allowed")
+		return
+	case authTime.IsZero() || authTime.Before(pending.created.Add(-time.Minute)):
+		// Without auth_time there is no proof the provider re-authenticated
+		// the user for this request.
+		authn.AuditWarn(r, "auth.elevation_failed", "reason", "stale_authentication")
+		a.oidcFail(w, r, "reauth_required")
+		return
+	}
+	if !a.sessions.Elevate(pending.elevateSession, token) {
+		a.oidcFail(w, r, "login_expired")
+		return
+	}
+	authn.AuditLog(r, "auth.session_elevated", "loginMethod", "oidc", "elevationWindowSec", int(a.sessions.ElevationWindow().Seconds()))
+	http.Redirect(w, r, pending.next, http.StatusFound)
+}
+
+func (a *AuthAPI) oidcFail(w http.ResponseWriter, r *http.Request, code string) {
+	http.Redirect(w, r, "/login?sso_error="+url.QueryEscape(code), http.StatusFound)
+}
+
+// oidcProvider returns the discovered provider, rediscovering when the
+// issuer or client settings change.
+func (a *AuthAPI) oidcProvider(r *http.Request, cfg config.OIDCConfig) (*oidc.Provider, error) {
+	key := strings.Join([]string{cfg.Issuer, cfg.ClientID, cfg.ClientSecret, strings.Join(cfg.Scopes, " ")}, "\x00")
+	a.oidc.mu.Lock()
+	if a.oidc.provider != nil && a.oidc.providerKey == key {
+		p := a.oidc.provider
+		a.oidc.mu.Unlock()
+		return p, nil
+	}
+	a.oidc.mu.Unlock()
+
+	p, err := oidc.Discover(r.Context(), oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Scopes:       cfg.Scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.oidc.mu.Lock()
+	a.oidc.provider, a.oidc.providerKey = p, key
+	a.oidc.mu.Unlock()
+	return p, nil
+}
+
+func (a *AuthAPI) oidcRedirectURL(r *http.Request, cfg config.OIDCConfig) string {
+	if cfg.RedirectURL != "" {
+		return cfg.RedirectURL
+	}
+	trust := a.runtime != nil && a.runtime.TrustProxyHeaders
+	return authn.RequestScheme(r, trust) + "://" + authn.RequestHost(r, trust) + oidcCallbackPath
+}
+
+func (l *oidcLogins) put(state string, p oidcPending) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.pending == nil {
+		l.pending = map[string]oidcPending{}
+	}
+	for s, old := range l.pending {
+		if time.Since(old.created) > oidcLoginTTL {
+			delete(l.pending, s)
+		}
+	}
+	if len(l.pending) >= oidcMaxPending {
+		// Abandoned logins cannot grow the map without bound.
+		for s := range l.pending {
+			delete(l.pending, s)
+			break
+		}
+	}
+	l.pending[state] = p
+}
+
+func (l *oidcLogins) take(state string) (oidcPending, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p, ok := l.pending[state]
+	delete(l.pending, state)
+	if !ok || time.Since(p.created) > oidcLoginTTL {
+		return oidcPending{}, false
+	}
+	return p, true
+}
+
+func oidcGroupsClaim(cfg config.OIDCConfig) string {
+	if cfg.GroupsClaim != "" {
+		return cfg.GroupsClaim
+	}
+	return "groups"
+}
+
+// oidcRole maps groups to the highest dashboard role they grant, or ""
+// when the user may not sign in.
+func oidcRole(cfg config.OIDCConfig, groups []string) string {
+	member := func(allowed []string) bool {
+		return slices.ContainsFunc(groups, func(g string) bool { return slices.Contains(allowed, g) })
+	}
+	switch {
+	case member(cfg.AdminGroups):
+		return authn.RoleAdmin
+	case member(cfg.OperatorGroups):
+		return authn.RoleOperator
+	case member(cfg.AllowedGroups):
+		return authn.RoleViewer
+	}
+	return ""
+}
+
+// safeNextPath keeps post-login redirects on this origin.
+func safeNextPath(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+	return next
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
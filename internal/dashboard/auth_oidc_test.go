@@ -0,0 +1,222 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/browsersession"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/oidc/oidctest"
+)
+
+func newOIDCTestAPI(t *testing.T) (*AuthAPI, *browsersession.Manager, *oidctest.Provider, *http.ServeMux) {
+	t.Helper()
+	idp := oidctest.NewProvider("pinchtab")
+	t.Cleanup(idp.Close)
+
+	runtime := &config.RuntimeConfig{Token: "secret-token"}
+	runtime.Sessions.Dashboard.OIDC = config.OIDCConfig{
+		Enabled:        true,
+		Issuer:         idp.Issuer,
+		ClientID:       "pinchtab",
+		AllowedGroups:  []string{"eng"},
+		OperatorGroups: []string{"ops"},
+		AdminGroups:    []string{"admins"},
+	}
+	sessions := browsersession.NewManager(browsersession.Config{})
+	api := NewAuthAPI(runtime, sessions)
+	mux := http.NewServeMux()
+	api.RegisterHandlers(mux)
+	return api, sessions, idp, mux
+}
+
+// oidcRoundTrip drives a login through the mock provider and returns the
+// callback response.
+func oidcRoundTrip(t *testing.T, mux *http.ServeMux, idp *oidctest.Provider, loginPath string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "http://localhost:9867"+loginPath, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var state *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == authn.OIDCStateCookieName {
+			state = c
+		}
+	}
+	if state == nil || state.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("state cookie = %+v", state)
+	}
+	authURL := w.Header().Get("Location")
+	if q := mustParse(t, authURL).Query(); q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL lacks PKCE: %s", authURL)
+	}
+
+	back, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb := httptest.NewRequest("GET", "http://localhost:9867"+back.RequestURI(), nil)
+	cb.AddCookie(state)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, cb)
+	return w
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == authn.CookieName && c.Value != "" {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestOIDCLoginCreatesSessionWithRole(t *testing.T) {
+	_, sessions, idp, mux := newOIDCTestAPI(t)
+	idp.SetUser(oidctest.User{Subject: "u-1", Email: "ada@example.com", Groups: []string{"eng", "ops"}})
+
+	w := oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login?next=/dashboard/agents")
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/dashboard/agents" {
+		t.Fatalf("callback = %d %s", w.Code, w.Header().Get("Location"))
+	}
+	cookie := sessionCookie(w)
+	if cookie == nil {
+		t.Fatal("no session cookie was set")
+	}
+	identity, ok := sessions.Identity(cookie.Value, "secret-token")
+	if !ok || identity.Email != "ada@example.com" || identity.Role != authn.RoleOperator {
+		t.Fatalf("identity = %+v, %v", identity, ok)
+	}
+}
+
+func TestOIDCLoginDeniesUsersOutsideGroups(t *testing.T) {
+	_, _, idp, mux := newOIDCTestAPI(t)
+	idp.SetUser(oidctest.User{Subject: "u-2", Groups: []string{"marketing"}})
+
+	w := oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login")
+	if loc := w.Header().Get("Location"); loc != "/login?sso_error=not_allowed" {
+		t.Fatalf("callback location = %q", loc)
+	}
+	if sessionCookie(w) != nil {
+		t.Fatal("a session was created for a user outside the allowed groups")
+	}
+}
+
+func TestOIDCCallbackRequiresMatchingState(t *testing.T) {
+	_, _, _, mux := newOIDCTestAPI(t)
+
+	req := httptest.NewRequest("GET", "http://localhost:9867/api/auth/oidc/callback?code=x&state=forged", nil)
+	req.AddCookie(&http.Cookie{Name: authn.OIDCStateCookieName, Value: "other"})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if loc := w.Header().Get("Location"); loc != "/login?sso_error=state_mismatch" {
+		t.Fatalf("callback location = %q", loc)
+	}
+}
+
+func TestOIDCElevationRequiresSameAdmin(t *testing.T) {
+	_, sessions, idp, mux := newOIDCTestAPI(t)
+	admin := oidctest.User{Subject: "u-admin", Email: "root@example.com", Groups: []string{"admins"}}
+	idp.SetUser(admin)
+	cookie := sessionCookie(oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login"))
+	if cookie == nil {
+		t.Fatal("no session cookie was set")
+	}
+	if sessions.IsElevated(cookie.Value, "secret-token") {
+		t.Fatal("a fresh SSO session should not start elevated")
+	}
+
+	// Another user signing in at the provider must not elevate the session.
+	idp.SetUser(oidctest.User{Subject: "u-other", Groups: []string{"admins"}})
+	w := oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login?elevate=1", cookie)
+	if loc := w.Header().Get("Location"); loc != "/login?sso_error=identity_mismatch" {
+		t.Fatalf("mismatched elevation location = %q", loc)
+	}
+	if sessions.IsElevated(cookie.Value, "secret-token") {
+		t.Fatal("session was elevated by a different user")
+	}
+
+	idp.SetUser(admin)
+	w = oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login?elevate=1", cookie)
+	if loc := w.Header().Get("Location"); loc != "/dashboard/settings" {
+		t.Fatalf("elevation location = %q", loc)
+	}
+	if !sessions.IsElevated(cookie.Value, "secret-token") {
+		t.Fatal("admin re-authentication did not elevate the session")
+	}
+}
+
+func TestOIDCElevationRequiresAuthTime(t *testing.T) {
+	_, sessions, idp, mux := newOIDCTestAPI(t)
+	idp.SetUser(oidctest.User{Subject: "u-admin", Groups: []string{"admins"}})
+	cookie := sessionCookie(oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login"))
+	if cookie == nil {
+		t.Fatal("no session cookie was set")
+	}
+
+	// A provider that leaves out auth_time gives no proof of a fresh login.
+	idp.SetClaims(map[string]any{"auth_time": nil})
+	w := oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login?elevate=1", cookie)
+	if loc := w.Header().Get("Location"); loc != "/login?sso_error=reauth_required" {
+		t.Fatalf("elevation location = %q", loc)
+	}
+	if sessions.IsElevated(cookie.Value, "secret-token") {
+		t.Fatal("session was elevated without auth_time")
+	}
+}
+
+func TestOIDCElevationDeniedForOperators(t *testing.T) {
+	_, sessions, idp, mux := newOIDCTestAPI(t)
+	idp.SetUser(oidctest.User{Subject: "u-op", Groups: []string{"ops"}})
+	cookie := sessionCookie(oidcRoundTrip(t, mux, idp, "/api/auth/oidc/login"))
+
+	req := httptest.NewRequest("GET", "http://localhost:9867/api/auth/oidc/login?elevate=1", nil)
+	req.AddCookie(cookie)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", w.Code)
+	}
+	if sessions.IsElevated(cookie.Value, "secret-token") {
+		t.Fatal("operator session was elevated")
+	}
+}
+
+func TestOIDCRole(t *testing.T) {
+	cfg := config.OIDCConfig{AllowedGroups: []string{"eng"}, OperatorGroups: []string{"ops"}, AdminGroups: []string{"admins"}}
+	for _, tt := range []struct {
+		groups []string
+		want   string
+	}{
+		{[]string{"eng"}, authn.RoleViewer},
+		{[]string{"eng", "ops"}, authn.RoleOperator},
+		{[]string{"ops", "admins"}, authn.RoleAdmin},
+		{[]string{"sales"}, ""},
+		{nil, ""},
+	} {
+		if got := oidcRole(cfg, tt.groups); got != tt.want {
+			t.Errorf("oidcRole(%v) = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+	if got := safeNextPath("//evil.example.com", "/dashboard"); got != "/dashboard" {
+		t.Errorf("safeNextPath kept an off-origin redirect: %q", got)
+	}
+}
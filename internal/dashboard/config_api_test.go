@@ -173,6 +173,8 @@ func TestHandleGetConfigRedactsToken(t *testing.T) {
 	fc.Security.StateEncryptionKey = &stateKey
 	fc.AutoSolver.External.CapsolverKey = "capsolver-secret"
 	fc.AutoSolver.External.TwoCaptchaKey = "twocaptcha-secret"
+	fc.AutoSolver.LLM.APIKey = "llm-secret"
+	fc.Handoff.WebhookSecret = "hook-secret"
 
 	api := newConfigAPITestAPI(t, fc)
 
@@ -197,6 +199,12 @@ func TestHandleGetConfigRedactsToken(t *testing.T) {
 	if env.Config.AutoSolver.External.TwoCaptchaKey != "" {
 		t.Fatalf("config twoCaptchaKey = %q, want redacted empty string", env.Config.AutoSolver.External.TwoCaptchaKey)
 	}
+	if env.Config.AutoSolver.LLM.APIKey != "" {
+		t.Fatalf("config llm.apiKey = %q, want redacted empty string", env.Config.AutoSolver.LLM.APIKey)
+	}
+	if env.Config.Handoff.WebhookSecret != "" {
+		t.Fatalf("config handoff.webhookSecret = %q, want redacted empty string", env.Config.Handoff.WebhookSecret)
+	}
 	if !env.TokenConfigured {
 		t.Fatal("tokenConfigured = false, want true")
 	}
@@ -264,6 +272,8 @@ func TestHandlePutConfigPreservesWriteOnlySecretsFromRedactedGetPayload(t *testi
 	fc.Security.StateEncryptionKey = &stateKey
 	fc.AutoSolver.External.CapsolverKey = "capsolver-secret"
 	fc.AutoSolver.External.TwoCaptchaKey = "twocaptcha-secret"
+	fc.AutoSolver.LLM.APIKey = "llm-secret"
+	fc.Handoff.WebhookSecret = "hook-secret"
 
 	api := newConfigAPITestAPI(t, fc)
 	sessions := browsersession.NewManager(browsersession.Config{ElevationWindow: time.Minute})
@@ -312,6 +322,12 @@ func TestHandlePutConfigPreservesWriteOnlySecretsFromRedactedGetPayload(t *testi
 	if saved.AutoSolver.External.TwoCaptchaKey != "twocaptcha-secret" {
 		t.Fatalf("saved twoCaptchaKey = %q, want existing key preserved", saved.AutoSolver.External.TwoCaptchaKey)
 	}
+	if saved.AutoSolver.LLM.APIKey != "llm-secret" {
+		t.Fatalf("saved llm.apiKey = %q, want existing key preserved", saved.AutoSolver.LLM.APIKey)
+	}
+	if saved.Handoff.WebhookSecret != "hook-secret" {
+		t.Fatalf("saved handoff.webhookSecret = %q, want existing secret preserved", saved.Handoff.WebhookSecret)
+	}
 	if saved.Server.Port != "9898" {
 		t.Fatalf("saved port = %q, want %q", saved.Server.Port, "9898")
 	}
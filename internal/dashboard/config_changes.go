@@ -28,6 +28,10 @@ func sensitiveConfigChanges(current, next *config.FileConfig) sensitiveConfigCha
 		out.requiresElevation = true
 		out.names = append(out.names, "security")
 	}
+	if !reflect.DeepEqual(current.Sessions.Dashboard.OIDC, next.Sessions.Dashboard.OIDC) {
+		out.requiresElevation = true
+		out.names = append(out.names, "sessions.dashboard.oidc")
+	}
 	if !reflect.DeepEqual(current.Browser.Proxy, next.Browser.Proxy) {
 		out.requiresElevation = true
 		out.proxyChanged = true
@@ -105,6 +109,9 @@ func (c *ConfigAPI) restartReasonsFor(next config.FileConfig) []string {
 		!sameIntPtr(c.boot.MultiInstance.Restart.StableAfterSec, next.MultiInstance.Restart.StableAfterSec) {
 		reasons = append(reasons, "Restart policy")
 	}
+	if !reflect.DeepEqual(c.boot.MultiInstance.Autoscale, next.MultiInstance.Autoscale) {
+		reasons = append(reasons, "Autoscale policy")
+	}
 
 	return reasons
 }
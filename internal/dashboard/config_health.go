@@ -128,6 +128,7 @@ func isGuardsDownPosture(cfg *config.RuntimeConfig) bool {
 		cfg.AllowCookies &&
 		cfg.AllowUpload &&
 		cfg.AllowNetworkIntercept &&
+		cfg.AllowWebAuthn &&
 		cfg.AttachEnabled &&
 		!cfg.IDPI.Enabled
 }
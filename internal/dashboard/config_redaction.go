@@ -18,6 +18,9 @@ func redactToken(cfg config.FileConfig) config.FileConfig {
 	cfg.Security.StateEncryptionKey = nil
 	cfg.AutoSolver.External.CapsolverKey = ""
 	cfg.AutoSolver.External.TwoCaptchaKey = ""
+	cfg.AutoSolver.LLM.APIKey = ""
+	cfg.Handoff.WebhookSecret = ""
+	cfg.Sessions.Dashboard.OIDC.ClientSecret = ""
 	cfg.AutoSolver.Credentials = config.AutoSolverCredentialsConf{}
 	cfg.Browser.Proxy = cfg.Browser.Proxy.Redacted()
 	if len(cfg.Browser.Targets) > 0 {
@@ -29,6 +32,18 @@ func redactToken(cfg config.FileConfig) config.FileConfig {
 		}
 		cfg.Browser.Targets = copied
 	}
+	if len(cfg.Browser.ProxyPools) > 0 {
+		copied := make(config.ProxyPoolsConfig, len(cfg.Browser.ProxyPools))
+		for name, pool := range cfg.Browser.ProxyPools {
+			proxies := make([]config.BrowserProxyConfig, len(pool.Proxies))
+			for i, p := range pool.Proxies {
+				proxies[i] = p.Redacted()
+			}
+			pool.Proxies = proxies
+			copied[name] = pool
+		}
+		cfg.Browser.ProxyPools = copied
+	}
 	return cfg
 }
 
@@ -40,6 +55,9 @@ func preserveWriteOnlyConfigFields(dst, src *config.FileConfig) {
 	dst.Security.StateEncryptionKey = src.Security.StateEncryptionKey
 	dst.AutoSolver.External.CapsolverKey = src.AutoSolver.External.CapsolverKey
 	dst.AutoSolver.External.TwoCaptchaKey = src.AutoSolver.External.TwoCaptchaKey
+	dst.AutoSolver.LLM.APIKey = src.AutoSolver.LLM.APIKey
+	dst.Handoff.WebhookSecret = src.Handoff.WebhookSecret
+	preserveCredString(&dst.Sessions.Dashboard.OIDC.ClientSecret, src.Sessions.Dashboard.OIDC.ClientSecret)
 	// Credentials are write-only: a blank or omitted credential field — which is
 	// what GET echoes back, having redacted them — keeps the value already on disk.
 	// A blank field does NOT clear a credential via the dashboard: missing and
@@ -63,6 +81,22 @@ func preserveWriteOnlyConfigFields(dst, src *config.FileConfig) {
 			dst.Browser.Targets[name] = t
 		}
 	}
+	// Pool entries are matched by server and username rather than index,
+	// since the dashboard may reorder them.
+	for name, pool := range dst.Browser.ProxyPools {
+		srcPool, ok := src.Browser.ProxyPools[name]
+		if !ok {
+			continue
+		}
+		for i := range pool.Proxies {
+			for _, srcP := range srcPool.Proxies {
+				if srcP.Server == pool.Proxies[i].Server && srcP.Username == pool.Proxies[i].Username {
+					preserveProxyPassword(&pool.Proxies[i], srcP)
+					break
+				}
+			}
+		}
+	}
 }
 
 // preserveProxyPassword keeps the on-disk password when the inbound PUT is blank or the "***" mask.
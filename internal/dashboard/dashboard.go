@@ -33,6 +33,7 @@ var dashboardFS embed.FS
 type SystemEvent struct {
 	Type     string      `json:"type"` // "instance.started", "instance.stopped", "instance.error"
 	Instance interface{} `json:"instance,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
 }
 
 // InstanceLister returns running instances (provided by Orchestrator).
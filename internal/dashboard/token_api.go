@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/authn"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// TokenAPI manages scoped API tokens.
+type TokenAPI struct {
+	store *apitoken.Store
+}
+
+// NewTokenAPI creates a new API token handler.
+func NewTokenAPI(store *apitoken.Store) *TokenAPI {
+	return &TokenAPI{store: store}
+}
+
+// RegisterHandlers registers API token routes.
+func (a *TokenAPI) RegisterHandlers(mux *http.ServeMux) {
+	if a == nil || a.store == nil {
+		return
+	}
+	mux.HandleFunc("GET /api/tokens", a.handleList)
+	mux.HandleFunc("POST /api/tokens", a.handleCreate)
+	mux.HandleFunc("GET /api/tokens/{id}", a.handleGet)
+	mux.HandleFunc("POST /api/tokens/{id}/revoke", a.handleRevoke)
+}
+
+func (a *TokenAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name      string         `json:"name"`
+		Scope     apitoken.Scope `json:"scope"`
+		ExpiresAt time.Time      `json:"expiresAt,omitempty"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16<<10)).Decode(&req); err != nil {
+		httpx.ErrorCode(w, http.StatusBadRequest, "bad_request", "invalid request body", false, nil)
+		return
+	}
+
+	tok, plaintext, err := a.store.Create(req.Name, req.Scope, req.ExpiresAt)
+	if err != nil {
+		httpx.ErrorCode(w, http.StatusBadRequest, "invalid_token_request", err.Error(), false, nil)
+		return
+	}
+	activity.EnrichRequest(r, activity.Update{Action: "tokens"})
+	authn.AuditLog(r, "token.created", "tokenId", tok.ID, "name", tok.Name)
+
+	// The plaintext is returned here only; the store keeps its hash.
+	httpx.JSON(w, http.StatusCreated, struct {
+		*apitoken.Token
+		Secret string `json:"token"`
+	}{tok, plaintext})
+}
+
+func (a *TokenAPI) handleList(w http.ResponseWriter, _ *http.Request) {
+	tokens := a.store.List()
+	if tokens == nil {
+		tokens = []apitoken.Token{}
+	}
+	httpx.JSON(w, http.StatusOK, tokens)
+}
+
+func (a *TokenAPI) handleGet(w http.ResponseWriter, r *http.Request) {
+	tok, ok := a.store.Get(r.PathValue("id"))
+	if !ok {
+		httpx.ErrorCode(w, http.StatusNotFound, "token_not_found", "token not found", false, nil)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, tok)
+}
+
+func (a *TokenAPI) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !a.store.Revoke(id) {
+		httpx.ErrorCode(w, http.StatusNotFound, "token_not_found", "token not found", false, nil)
+		return
+	}
+	activity.EnrichRequest(r, activity.Update{Action: "tokens"})
+	authn.AuditLog(r, "token.revoked", "tokenId", id)
+	httpx.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
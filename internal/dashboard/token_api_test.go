@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+)
+
+func TestTokenAPI_CreateListRevoke(t *testing.T) {
+	store := apitoken.NewStore("")
+	mux := http.NewServeMux()
+	NewTokenAPI(store).RegisterHandlers(mux)
+
+	body := `{"name":"ci","scope":{"families":["browse"],"capabilities":["screencast"],"allowedDomains":["example.com"]}}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/tokens", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID    string         `json:"id"`
+		Token string         `json:"token"`
+		Scope apitoken.Scope `json:"scope"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || !strings.HasPrefix(created.Token, apitoken.TokenPrefix) || created.Scope.AllowedDomains[0] != "example.com" {
+		t.Fatalf("created = %+v", created)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/tokens", nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), created.Token) {
+		t.Fatalf("list status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/tokens/"+created.ID+"/revoke", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d", w.Code)
+	}
+	if _, ok := store.Authenticate(created.Token); ok {
+		t.Fatal("revoked token still authenticates")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/tokens/tok_missing/revoke", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("revoke unknown status = %d, want 404", w.Code)
+	}
+}
+
+func TestTokenAPI_CreateRejectsBadScope(t *testing.T) {
+	mux := http.NewServeMux()
+	NewTokenAPI(apitoken.NewStore("")).RegisterHandlers(mux)
+
+	for _, body := range []string{
+		`{"name":"","scope":{"families":["browse"]}}`,
+		`{"name":"x","scope":{"families":["root"]}}`,
+		`{"name":"x","scope":{"families":["browse"]},"expiresAt":"2001-01-01T00:00:00Z"}`,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/tokens", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
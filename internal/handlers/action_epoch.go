@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// Reasons an expectedEpoch no longer matches the tab, reported as
+// details.reason on 409 stale_epoch.
+const (
+	staleEpochCacheCleared = "ref_cache_cleared"
+	staleEpochReplaced     = "epoch_replaced"
+	staleEpochDomMutated   = "dom_mutated"
+)
+
+// checkExpectedEpoch compares the caller's expectedEpoch with the tab's ref
+// cache and returns why it is stale, or "" when it still holds (or none was
+// given). When the page reports DOM mutations since the epoch was armed, the
+// cached epoch is replaced by a freshly armed one so the stale verdict sticks
+// for every later caller. Mutation checks are best-effort: a page that cannot
+// be evaluated is given the benefit of the doubt.
+func (h *Handlers) checkExpectedEpoch(ctx context.Context, tabID, expected string) string {
+	if expected == "" {
+		return ""
+	}
+	cache := h.Bridge.GetRefCache(tabID)
+	switch {
+	case cache == nil:
+		return staleEpochCacheCleared
+	case cache.DomEpoch != expected:
+		return staleEpochReplaced
+	}
+	changed, err := h.domEpochChanged(ctx, expected)
+	if err != nil || !changed {
+		return ""
+	}
+	// An epoch the page does not carry would read as changed forever, so
+	// only publish the bump once it is armed.
+	if epoch := h.armRefCacheEpoch(ctx); epoch != "" {
+		bumped := *cache
+		bumped.DomEpoch = epoch
+		h.Bridge.SetRefCache(tabID, &bumped)
+	}
+	return staleEpochDomMutated
+}
+
+// armRefCacheEpoch mints an epoch for a new ref cache generation and arms it
+// in the page. It returns "" when the page cannot be armed; a cache without
+// an epoch refuses every expectedEpoch rather than trusting an unarmed one.
+func (h *Handlers) armRefCacheEpoch(ctx context.Context) string {
+	epoch := bridge.MintDomEpoch()
+	if err := h.armDomEpoch(ctx, epoch); err != nil {
+		return ""
+	}
+	return epoch
+}
+
+// canRemapStaleRef reports whether a stale-epoch action can be retried
+// through semantic recovery instead of being refused.
+func (h *Handlers) canRemapStaleRef(req bridge.ActionRequest) bool {
+	return req.RemapOnStale && req.Ref != "" && h.Recovery != nil
+}
+
+func writeStaleEpoch(w http.ResponseWriter, expected, reason string) {
+	httpx.ErrorCode(w, http.StatusConflict, "stale_epoch",
+		fmt.Sprintf("expectedEpoch %s is stale (%s); capture the tab again for fresh refs", expected, reason),
+		false, map[string]any{"expectedEpoch": expected, "reason": reason})
+}
+
+// staleEpochError is the per-step error for batch and macro results.
+func staleEpochError(expected, reason string) error {
+	return fmt.Errorf("stale_epoch: expectedEpoch %s is stale (%s)", expected, reason)
+}
+
+// remappedEpoch describes a stale-epoch action that recovery re-targeted,
+// including the epoch of the refreshed ref cache for follow-up calls.
+func (h *Handlers) remappedEpoch(tabID, expected, reason string) map[string]any {
+	out := map[string]any{"expected": expected, "reason": reason, "remapped": true}
+	if cache := h.Bridge.GetRefCache(tabID); cache != nil && cache.DomEpoch != "" {
+		out["current"] = cache.DomEpoch
+	}
+	return out
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+// epochBridge keeps a settable ref cache and counts executed actions.
+type epochBridge struct {
+	mockBridge
+	cache    *bridge.RefCache
+	executed []bridge.ActionRequest
+}
+
+// TabContext hands out a live context so semantic recovery can run.
+func (m *epochBridge) TabContext(string) (*bridge.TabHandle, string, error) {
+	return bridge.NewTabHandle(context.Background()), "tab1", nil
+}
+
+func (m *epochBridge) GetRefCache(string) *bridge.RefCache { return m.cache }
+
+func (m *epochBridge) SetRefCache(_ string, cache *bridge.RefCache) { m.cache = cache }
+
+func (m *epochBridge) ExecuteAction(_ context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
+	m.executed = append(m.executed, req)
+	return map[string]any{"clicked": true}, nil
+}
+
+func (m *epochBridge) Snapshot(context.Context, string, string, bridge.ContentParams) (*bridge.SnapshotResult, error) {
+	return &bridge.SnapshotResult{
+		Nodes: []bridge.A11yNode{{Ref: "e1", Role: "button", Name: "Submit order", NodeID: 42}},
+		Refs:  map[string]int64{"e1": 42},
+	}, nil
+}
+
+func newEpochBridge(epoch string) *epochBridge {
+	return &epochBridge{cache: &bridge.RefCache{
+		Refs:     map[string]int64{"e1": 42},
+		Nodes:    []bridge.A11yNode{{Ref: "e1", Role: "button", Name: "Submit order"}},
+		DomEpoch: epoch,
+	}}
+}
+
+func TestCheckExpectedEpoch(t *testing.T) {
+	b := newEpochBridge("ep_1")
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	mutated := false
+	h.domEpochChanged = func(context.Context, string) (bool, error) { return mutated, nil }
+	var armed []string
+	h.armDomEpoch = func(_ context.Context, epoch string) error {
+		armed = append(armed, epoch)
+		return nil
+	}
+
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", ""); got != "" {
+		t.Fatalf("no expectedEpoch: got %q", got)
+	}
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_1"); got != "" {
+		t.Fatalf("current epoch: got %q", got)
+	}
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_0"); got != staleEpochReplaced {
+		t.Fatalf("older epoch: got %q", got)
+	}
+
+	mutated = true
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_1"); got != staleEpochDomMutated {
+		t.Fatalf("mutated DOM: got %q", got)
+	}
+	if b.cache.DomEpoch == "ep_1" {
+		t.Fatal("expected the cached epoch to be bumped after a mutation")
+	}
+	if len(armed) != 1 || armed[0] != b.cache.DomEpoch {
+		t.Fatalf("bumped epoch %q was not armed in the page (armed %v)", b.cache.DomEpoch, armed)
+	}
+	// The bump sticks even once the page check stops reporting changes.
+	mutated = false
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_1"); got != staleEpochReplaced {
+		t.Fatalf("after bump: got %q", got)
+	}
+
+	// A bump that cannot be armed keeps the old epoch: the page still
+	// reports its mutations, and an unarmed epoch would never validate.
+	b.cache.DomEpoch = "ep_2"
+	mutated = true
+	h.armDomEpoch = func(context.Context, string) error { return context.DeadlineExceeded }
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_2"); got != staleEpochDomMutated {
+		t.Fatalf("unarmed bump: got %q", got)
+	}
+	if b.cache.DomEpoch != "ep_2" {
+		t.Fatalf("unarmed bump replaced the epoch with %q", b.cache.DomEpoch)
+	}
+
+	b.cache = nil
+	if got := h.checkExpectedEpoch(context.Background(), "tab1", "ep_1"); got != staleEpochCacheCleared {
+		t.Fatalf("cleared cache: got %q", got)
+	}
+}
+
+func TestHandleSnapshot_ArmsRefCacheEpoch(t *testing.T) {
+	b := newEpochBridge("ep_capture")
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	var armed string
+	h.armDomEpoch = func(_ context.Context, epoch string) error {
+		armed = epoch
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	h.HandleSnapshot(w, httptest.NewRequest("GET", "/snapshot?tabId=tab1", nil))
+	if w.Code != 200 {
+		t.Fatalf("snapshot: %d %s", w.Code, w.Body.String())
+	}
+	if armed == "" || b.cache.DomEpoch != armed {
+		t.Fatalf("ref cache epoch = %q, armed %q", b.cache.DomEpoch, armed)
+	}
+}
+
+func postAction(t *testing.T, h *Handlers, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/action", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.HandleAction(w, req)
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestHandleAction_StaleEpochRejected(t *testing.T) {
+	b := newEpochBridge("ep_new")
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.domEpochChanged = func(context.Context, string) (bool, error) { return false, nil }
+
+	code, resp := postAction(t, h, `{"kind":"click","ref":"e1","expectedEpoch":"ep_old"}`)
+	if code != 409 || resp["code"] != "stale_epoch" {
+		t.Fatalf("stale epoch: %d %v", code, resp)
+	}
+	details, _ := resp["details"].(map[string]any)
+	if details["reason"] != staleEpochReplaced {
+		t.Fatalf("details = %v", resp)
+	}
+	if len(b.executed) != 0 {
+		t.Fatal("stale action must not run")
+	}
+
+	code, resp = postAction(t, h, `{"kind":"click","ref":"e1","expectedEpoch":"ep_new"}`)
+	if code != 200 || len(b.executed) != 1 {
+		t.Fatalf("current epoch: %d %v", code, resp)
+	}
+}
+
+func TestHandleAction_StaleEpochRemapped(t *testing.T) {
+	b := newEpochBridge("ep_new")
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.domEpochChanged = func(context.Context, string) (bool, error) { return false, nil }
+
+	code, resp := postAction(t, h, `{"kind":"click","ref":"e1","expectedEpoch":"ep_old","remapOnStale":true}`)
+	if code != 200 {
+		t.Fatalf("remap: %d %v", code, resp)
+	}
+	epoch, _ := resp["epoch"].(map[string]any)
+	if epoch["remapped"] != true || epoch["reason"] != staleEpochReplaced || epoch["current"] != "ep_new" {
+		t.Fatalf("epoch = %v", resp)
+	}
+	rec, _ := resp["recovery"].(map[string]any)
+	if rec["new_ref"] != "e1" {
+		t.Fatalf("recovery = %v", resp)
+	}
+	if len(b.executed) != 1 || b.executed[0].NodeID != 42 {
+		t.Fatalf("executed = %+v", b.executed)
+	}
+}
+
+func TestHandleActions_StaleEpoch(t *testing.T) {
+	b := newEpochBridge("ep_new")
+	h := New(b, &config.RuntimeConfig{ActionTimeout: time.Second}, nil, nil, nil)
+	h.domEpochChanged = func(context.Context, string) (bool, error) { return false, nil }
+
+	req := httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(`{"expectedEpoch":"ep_old","actions":[{"kind":"click","ref":"e1"}]}`)))
+	w := httptest.NewRecorder()
+	h.HandleActions(w, req)
+	if w.Code != 409 {
+		t.Fatalf("batch precondition: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/actions", bytes.NewReader([]byte(`{"actions":[{"kind":"click","ref":"e1","expectedEpoch":"ep_old"},{"kind":"click","ref":"e1"}]}`)))
+	w = httptest.NewRecorder()
+	h.HandleActions(w, req)
+	var resp struct {
+		Results []actionResult `json:"results"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != 200 || len(resp.Results) != 2 || resp.Results[0].Success || !resp.Results[1].Success {
+		t.Fatalf("per-step epoch: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 	"github.com/pinchtab/semantic"
 	"github.com/pinchtab/semantic/recovery"
 )
@@ -71,18 +72,26 @@ func (h *Handlers) executeActionResilient(ctx context.Context, req *bridge.Actio
 
 	result, backend, err := h.executeAction(ctx, *req, cfg)
 	if err != nil && shouldRetryPointerAction(*req, err) {
-		if req.Ref != "" && shouldRetryStaleRef(err) {
+		spanName := "action.retry"
+		staleRef := req.Ref != "" && shouldRetryStaleRef(err)
+		if staleRef {
+			spanName = "stale_ref.retry"
+		}
+		retryCtx, span := tracing.Start(ctx, spanName, tracing.String("pinchtab.retry_reason", err.Error()))
+		if staleRef {
 			recordStaleRefRetry()
-			h.refreshRefCache(ctx, resolvedTabID)
+			h.refreshRefCache(retryCtx, resolvedTabID)
 			if cache := h.Bridge.GetRefCache(resolvedTabID); cache != nil {
 				if target, ok := cache.Lookup(req.Ref); ok {
 					req.NodeID = target.BackendNodeID
 				}
 			}
 		}
-		h.refreshActionNodeIDFromSelector(ctx, req)
+		h.refreshActionNodeIDFromSelector(retryCtx, req)
 		time.Sleep(pointerRetryDelay)
-		result, backend, err = h.executeAction(ctx, *req, cfg)
+		result, backend, err = h.executeAction(retryCtx, *req, cfg)
+		span.RecordError(err)
+		span.End()
 	}
 
 	var rr *recovery.RecoveryResult
@@ -166,10 +175,14 @@ func (h *Handlers) refreshRefCache(ctx context.Context, tabID string) {
 	}
 	flat, refs := bridge.BuildSnapshot(nodes, bridge.FilterInteractive, -1)
 	_ = bridge.EnrichA11yNodesWithDOMMetadata(ctx, flat)
+	// A refreshed cache is a new generation of refs: give it its own epoch
+	// so callers holding the previous one see it as stale.
+	epoch := h.armRefCacheEpoch(ctx)
 	h.Bridge.SetRefCache(tabID, &bridge.RefCache{
-		Refs:    refs,
-		Targets: bridge.RefTargetsFromNodes(flat),
-		Nodes:   flat,
+		Refs:     refs,
+		Targets:  bridge.RefTargetsFromNodes(flat),
+		Nodes:    flat,
+		DomEpoch: epoch,
 	})
 }
 
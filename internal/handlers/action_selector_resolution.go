@@ -7,6 +7,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/selector"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 type actionSelectorResolution struct {
@@ -25,7 +26,7 @@ func frameScopedSelectorError(kind string, err error) error {
 	return fmt.Errorf("%s in current frame: %w", kind, err)
 }
 
-func (h *Handlers) resolveActionRequestSelector(ctx context.Context, tabID string, req *bridge.ActionRequest) (actionSelectorResolution, error) {
+func (h *Handlers) resolveActionRequestSelector(ctx context.Context, tabID string, req *bridge.ActionRequest) (res actionSelectorResolution, err error) {
 	req.NormalizeSelector()
 	if req.NodeID != 0 {
 		return actionSelectorResolution{}, nil
@@ -38,6 +39,12 @@ func (h *Handlers) resolveActionRequestSelector(ctx context.Context, tabID strin
 	}
 
 	sel := selector.Parse(req.Selector)
+	ctx, span := tracing.Start(ctx, "selector.resolve", tracing.String("pinchtab.selector.kind", string(sel.Kind)))
+	defer func() {
+		span.SetAttributes(tracing.Bool("pinchtab.selector.ref_missing", res.refMissing))
+		span.RecordError(err)
+		span.End()
+	}()
 	if handled, err := h.applySemanticActionSelector(ctx, tabID, sel, req); handled {
 		if err != nil {
 			return actionSelectorResolution{status: semanticSelectorHTTPStatus(err)}, err
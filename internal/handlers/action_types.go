@@ -1,19 +1,26 @@
 package handlers
 
-import "github.com/pinchtab/pinchtab/internal/bridge"
+import (
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/semantic/recovery"
+)
 
 type actionsRequest struct {
-	TabID       string                 `json:"tabId"`
-	Owner       string                 `json:"owner"`
-	Actions     []bridge.ActionRequest `json:"actions"`
-	StopOnError bool                   `json:"stopOnError"`
+	TabID         string                 `json:"tabId"`
+	Owner         string                 `json:"owner"`
+	LockToken     uint64                 `json:"lockToken"`
+	ExpectedEpoch string                 `json:"expectedEpoch"`
+	Actions       []bridge.ActionRequest `json:"actions"`
+	StopOnError   bool                   `json:"stopOnError"`
 }
 
 type actionResult struct {
-	Index   int            `json:"index"`
-	Success bool           `json:"success"`
-	Result  map[string]any `json:"result,omitempty"`
-	Error   string         `json:"error,omitempty"`
+	Index    int                      `json:"index"`
+	Success  bool                     `json:"success"`
+	Result   map[string]any           `json:"result,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+	Recovery *recovery.RecoveryResult `json:"recovery,omitempty"`
+	Epoch    map[string]any           `json:"epoch,omitempty"`
 }
 
 func countSuccessful(results []actionResult) int {
@@ -30,11 +30,33 @@ func resolveOwner(r *http.Request, fallback string) string {
 	return strings.TrimSpace(fallback)
 }
 
-func (h *Handlers) enforceTabLease(tabID, owner string) error {
+// resolveLockToken reads the caller's fencing token from the X-Lock-Token
+// header, the lockToken query parameter, or the body value in fallback.
+func resolveLockToken(r *http.Request, fallback uint64) uint64 {
+	for _, raw := range []string{r.Header.Get("X-Lock-Token"), r.URL.Query().Get("lockToken")} {
+		if tok, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64); err == nil {
+			return tok
+		}
+	}
+	return fallback
+}
+
+// errStaleLockToken marks a request whose lock token no longer names the
+// tab's current lease.
+var errStaleLockToken = errors.New("stale lock token")
+
+// enforceTabLease checks the caller against the tab's lock. A lock token,
+// when given, must match the current lease exactly, so a caller whose lease
+// lapsed cannot act on a tab someone else has since locked. The token only
+// fences the lease; the caller must still name its owner.
+func (h *Handlers) enforceTabLease(tabID, owner string, token uint64) error {
 	if tabID == "" {
 		return nil
 	}
 	lock := h.Bridge.TabLockInfo(tabID)
+	if token != 0 && (lock == nil || lock.Token != token) {
+		return fmt.Errorf("%w: lock token %d is not the current lease on tab %s", errStaleLockToken, token, tabID)
+	}
 	if lock == nil {
 		return nil
 	}
@@ -47,6 +69,15 @@ func (h *Handlers) enforceTabLease(tabID, owner string) error {
 	return nil
 }
 
+// writeTabLeaseError reports an enforceTabLease failure.
+func writeTabLeaseError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errStaleLockToken) {
+		httpx.ErrorCode(w, http.StatusConflict, "stale_lock_token", err.Error(), false, nil)
+		return
+	}
+	httpx.ErrorCode(w, http.StatusLocked, "tab_locked", err.Error(), false, nil)
+}
+
 func (h *Handlers) enforceTabNotPausedForHandoff(tabID string) error {
 	if tabID == "" {
 		return nil
@@ -133,7 +164,7 @@ func (h *Handlers) runResolvedActionStep(
 	refMissing bool,
 	errFallback func(error) string,
 ) (actionResult, context.Context, string) {
-	res, _, _, err := h.executeActionResilient(tCtx, step, cfg, tabID, refMissing)
+	res, _, rr, err := h.executeActionResilient(tCtx, step, cfg, tabID, refMissing)
 	nextCtx := ctx
 	nextTabID := tabID
 	if err == nil {
@@ -156,7 +187,7 @@ func (h *Handlers) runResolvedActionStep(
 			Error:   h.dialogAwareActionError(err, step.Kind, nextTabID, errFallback(err)),
 		}, nextCtx, nextTabID
 	}
-	return actionResult{Index: index, Success: true, Result: res}, nextCtx, nextTabID
+	return actionResult{Index: index, Success: true, Result: res, Recovery: rr}, nextCtx, nextTabID
 }
 
 func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
@@ -195,6 +226,8 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 		}
 		d.Int("deltaX", &req.DeltaX)
 		d.Int("deltaY", &req.DeltaY)
+		req.ExpectedEpoch = q.Get("expectedEpoch")
+		d.Bool("remapOnStale", &req.RemapOnStale)
 		req.Browser = q.Get("browser")
 		if err := d.Err(); err != nil {
 			httpx.Error(w, 400, err)
@@ -260,8 +293,9 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 			req.TabID = resolvedTabID
 		}
 		owner := resolveOwner(r, req.Owner)
-		if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-			httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+		lockToken := resolveLockToken(r, req.LockToken)
+		if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+			writeTabLeaseError(w, err)
 			return
 		}
 		if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
@@ -305,6 +339,18 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 		h.cacheActionIntent(resolvedTabID, req)
 	}
 
+	// A stale expectedEpoch means the ref may now point at a different
+	// element. Refuse, or with remapOnStale re-resolve it through recovery
+	// instead of trusting the cached node.
+	staleReason := h.checkExpectedEpoch(tCtx, resolvedTabID, req.ExpectedEpoch)
+	if staleReason != "" {
+		if !h.canRemapStaleRef(req) {
+			writeStaleEpoch(w, req.ExpectedEpoch, staleReason)
+			return
+		}
+		refMissing = true
+	}
+
 	// If ref was not in snapshot cache, attempt semantic recovery before
 	// returning 404. This handles the common case where a page reload
 	// cleared the snapshot (DeleteRefCache) but the intent is still cached.
@@ -358,7 +404,7 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 		actionBackend = "chrome"
 	}
 	if actionBackend != "static" {
-		h.maybeAutoSolve(tCtx, resolvedTabID, autoSolverTriggerAction)
+		h.maybeAutoSolve(r, resolvedTabID, autoSolverTriggerAction)
 		// Banner dismissal only makes sense when the click triggered a
 		// navigation (waitNav settles us on a fresh page). Without waitNav we
 		// skip — the caller is interacting within the current document and
@@ -380,6 +426,9 @@ func (h *Handlers) HandleAction(w http.ResponseWriter, r *http.Request) {
 	if recoveryResult != nil {
 		resp["recovery"] = recoveryResult
 	}
+	if staleReason != "" {
+		resp["epoch"] = h.remappedEpoch(resolvedTabID, req.ExpectedEpoch, staleReason)
+	}
 	httpx.JSON(w, 200, resp)
 }
 
@@ -429,6 +478,7 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 	var ctx context.Context
 	var resolvedTabID string
 	owner := resolveOwner(r, req.Owner)
+	lockToken := resolveLockToken(r, req.LockToken)
 	{
 		var err error
 		ctx, resolvedTabID, err = h.tabContext(r, req.TabID)
@@ -436,8 +486,14 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 			WriteTabContextError(w, err, 404)
 			return
 		}
-		if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-			httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+		if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+			writeTabLeaseError(w, err)
+			return
+		}
+		// The batch-level epoch is a precondition checked once: the batch's
+		// own steps are expected to mutate the DOM.
+		if reason := h.checkExpectedEpoch(ctx, resolvedTabID, req.ExpectedEpoch); reason != "" {
+			writeStaleEpoch(w, req.ExpectedEpoch, reason)
 			return
 		}
 	}
@@ -459,7 +515,7 @@ func (h *Handlers) handleActionsBatch(w http.ResponseWriter, r *http.Request, re
 				}
 				continue
 			}
-			if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
+			if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
 				results = append(results, actionResult{Index: i, Success: false, Error: err.Error()})
 				if req.StopOnError {
 					break
@@ -541,6 +597,19 @@ func (h *Handlers) runMultiStepActionTail(
 		h.cacheActionIntent(resolvedTabID, *step)
 	}
 
+	staleReason := h.checkExpectedEpoch(tCtx, resolvedTabID, step.ExpectedEpoch)
+	if staleReason != "" {
+		if !h.canRemapStaleRef(*step) {
+			cancel()
+			*results = append(*results, actionResult{
+				Index: index, Success: false,
+				Error: staleEpochError(step.ExpectedEpoch, staleReason).Error(),
+			})
+			return ctx, resolvedTabID, stopOnError
+		}
+		refMissing = true
+	}
+
 	if refMissing && h.Recovery == nil {
 		cancel()
 		*results = append(*results, actionResult{
@@ -553,6 +622,9 @@ func (h *Handlers) runMultiStepActionTail(
 	var result actionResult
 	result, ctx, resolvedTabID = h.runResolvedActionStep(ctx, tCtx, r, w, step, cfg, resolvedTabID, index, refMissing, errFmt)
 	cancel()
+	if staleReason != "" && result.Success {
+		result.Epoch = h.remappedEpoch(resolvedTabID, step.ExpectedEpoch, staleReason)
+	}
 	*results = append(*results, result)
 	return ctx, resolvedTabID, !result.Success && stopOnError
 }
@@ -571,7 +643,7 @@ func (h *Handlers) writeMultiStepActionResult(
 ) {
 	successful := countSuccessful(results)
 	if successful > 0 {
-		h.maybeAutoSolve(ctx, resolvedTabID, autoSolverTriggerAction)
+		h.maybeAutoSolve(r, resolvedTabID, autoSolverTriggerAction)
 	}
 	h.recordActivity(r, activity.Update{Route: route})
 	resp := map[string]any{
@@ -593,11 +665,13 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var req struct {
-		TabID       string                 `json:"tabId"`
-		Owner       string                 `json:"owner"`
-		Steps       []bridge.ActionRequest `json:"steps"`
-		StopOnError bool                   `json:"stopOnError"`
-		StepTimeout float64                `json:"stepTimeout"`
+		TabID         string                 `json:"tabId"`
+		Owner         string                 `json:"owner"`
+		LockToken     uint64                 `json:"lockToken"`
+		ExpectedEpoch string                 `json:"expectedEpoch"`
+		Steps         []bridge.ActionRequest `json:"steps"`
+		StopOnError   bool                   `json:"stopOnError"`
+		StepTimeout   float64                `json:"stepTimeout"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		httpx.ErrorCode(w, 400, "bad_request", fmt.Sprintf("decode: %v", err), false, nil)
@@ -608,6 +682,7 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	owner := resolveOwner(r, req.Owner)
+	lockToken := resolveLockToken(r, req.LockToken)
 
 	// Browser resolution: use the first step's browser field as the request
 	// browser, then fall through session > instance > global default > chrome.
@@ -672,8 +747,12 @@ func (h *Handlers) HandleMacro(w http.ResponseWriter, r *http.Request) {
 			WriteTabContextError(w, err, 404)
 			return
 		}
-		if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-			httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+		if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+			writeTabLeaseError(w, err)
+			return
+		}
+		if reason := h.checkExpectedEpoch(ctx, resolvedTabID, req.ExpectedEpoch); reason != "" {
+			writeStaleEpoch(w, req.ExpectedEpoch, reason)
 			return
 		}
 	}
@@ -20,6 +20,8 @@ type failMockBridge struct {
 	bridge.BridgeAPI
 }
 
+func (m *failMockBridge) TabSession(string) string { return "" }
+
 type recordingActionBridge struct {
 	mockBridge
 	lastKind string
@@ -7,6 +7,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 func (h *Handlers) tabContext(r *http.Request, tabID string) (context.Context, string, error) {
@@ -27,9 +28,19 @@ func (h *Handlers) tabContext(r *http.Request, tabID string) (context.Context, s
 		h.CurrentTabs.Clear(scope)
 		return nil, "", noCurrentTabError(scope.Description())
 	}
+	if err == nil {
+		if err := h.checkTabSession(r, resolvedID); err != nil {
+			return nil, "", err
+		}
+	}
 	if err == nil {
 		h.setCurrentTabForRequest(r, resolvedID)
 		h.recordActivity(r, activity.Update{TabID: resolvedID})
+		// The tab context derives from the browser, not the request; carry
+		// the request span over so executor and CDP spans join its trace.
+		if span := tracing.SpanFromContext(r.Context()); span != nil {
+			ctx = bridge.NewTabHandle(tracing.ContextWithSpan(ctx, span))
+		}
 	}
 	return ctx, resolvedID, err
 }
@@ -59,11 +59,11 @@ func (h *Handlers) HandleAudit(w http.ResponseWriter, r *http.Request) {
 	httpx.ExtendWriteDeadline(w, auditRunTimeout)
 
 	auditor := func(url string, opts audit.PageOptions) audit.PageAudit {
-		targets, err := h.validateAuditTarget(url, effectiveCfg)
+		targets, err := h.validateAuditTargetFor(r, url, effectiveCfg)
 		if err != nil {
 			return audit.NewPageAuditError(url, err)
 		}
-		return h.auditPage(r.Context(), url, opts, effectiveCfg, targets)
+		return h.auditPage(r, url, opts, effectiveCfg, targets)
 	}
 
 	report, err := audit.RunAudit(
@@ -75,7 +75,7 @@ func (h *Handlers) HandleAudit(w http.ResponseWriter, r *http.Request) {
 			EnrichAll:   req.EnrichAll,
 			Page:        req.Options.pageOptions(),
 		},
-		h.fetchSitemap(effectiveCfg),
+		h.fetchSitemap(r, effectiveCfg),
 		auditor,
 	)
 	if err != nil {
@@ -101,11 +101,11 @@ func auditNeedsBrowser(req auditRequest, seaportalPages []audit.SeaportalPage) b
 // validation as page navigation, then discovers pages through
 // seaportal.FlattenSitemap (recursive sitemap-index support). The crawl
 // guard also gates every child-sitemap fetch inside the flattening.
-func (h *Handlers) fetchSitemap(cfg *config.RuntimeConfig) audit.SitemapFetcher {
+func (h *Handlers) fetchSitemap(r *http.Request, cfg *config.RuntimeConfig) audit.SitemapFetcher {
 	return func(sitemapURL string) ([]string, error) {
-		if _, err := h.validateAuditTarget(sitemapURL, cfg); err != nil {
+		if _, err := h.validateAuditTargetFor(r, sitemapURL, cfg); err != nil {
 			return nil, err
 		}
-		return audit.FlattenSitemapURLs(context.Background(), sitemapURL, h.crawlGuard(cfg).Policy())
+		return audit.FlattenSitemapURLs(context.Background(), sitemapURL, h.crawlGuard(r, cfg).Policy())
 	}
 }
@@ -91,7 +91,16 @@ func (h *Handlers) HandleAuditPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	httpx.ExtendWriteDeadline(w, auditPageDeadline)
-	httpx.JSON(w, 200, h.auditPage(r.Context(), req.URL, req.Options.pageOptions(), routing.EffectiveCfg, targets))
+	httpx.JSON(w, 200, h.auditPage(r, req.URL, req.Options.pageOptions(), routing.EffectiveCfg, targets))
+}
+
+// validateAuditTargetFor is validateAuditTarget plus the API token domain
+// allowlist of r.
+func (h *Handlers) validateAuditTargetFor(r *http.Request, url string, cfg *config.RuntimeConfig) (navTargets, error) {
+	if err := tokenDomainError(r, url); err != nil {
+		return navTargets{}, err
+	}
+	return h.validateAuditTarget(url, cfg)
 }
 
 // validateAuditTarget is the non-writing sibling of validateNavigateTargets
@@ -120,8 +129,9 @@ func (h *Handlers) validateAuditTarget(url string, cfg *config.RuntimeConfig) (n
 // audit. Per-page failures are data, not crashes: navigation errors come
 // back as a structured entry with the error field set. The tab is always
 // closed before returning.
-func (h *Handlers) auditPage(clientCtx context.Context, url string, opts audit.PageOptions, cfg *config.RuntimeConfig, targets navTargets) audit.PageAudit {
-	tabID, tabCtx, _, err := h.Bridge.CreateTab("")
+func (h *Handlers) auditPage(r *http.Request, url string, opts audit.PageOptions, cfg *config.RuntimeConfig, targets navTargets) audit.PageAudit {
+	clientCtx := r.Context()
+	tabID, tabCtx, _, err := h.createTab(r, bridge.TabProxyOptions{})
 	if err != nil {
 		return audit.NewPageAuditError(url, fmt.Errorf("new tab: %w", err))
 	}
@@ -3,16 +3,20 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/pinchtab/pinchtab/internal/apitoken"
 	coreautosolver "github.com/pinchtab/pinchtab/internal/autosolver"
 	"github.com/pinchtab/pinchtab/internal/autosolver/adapters"
 	"github.com/pinchtab/pinchtab/internal/autosolver/external"
 	autosolverllm "github.com/pinchtab/pinchtab/internal/autosolver/llm"
 	autosolversemantic "github.com/pinchtab/pinchtab/internal/autosolver/semantic"
 	autosolvers "github.com/pinchtab/pinchtab/internal/autosolver/solvers"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 )
 
 const (
@@ -37,17 +41,19 @@ const (
 // It never blocks the caller: the HTTP request that triggered this returns
 // immediately while the solver runs with its own bounded context. If the
 // solver detects a challenge and fails, the tab is flipped to paused_handoff
-// so subsequent action requests see the 409 handoff error.
-func (h *Handlers) maybeAutoSolve(_ context.Context, tabID, trigger string) {
+// so subsequent action requests see the 409 handoff error. The run keeps
+// the triggering request's token domains for any navigation it makes.
+func (h *Handlers) maybeAutoSolve(r *http.Request, tabID, trigger string) {
 	if tabID == "" || h.autoSolverRunner == nil || !h.shouldAutoSolve(trigger) {
 		return
 	}
+	tokenDomains := requestTokenDomains(r)
 
 	go func() {
 		runCtx, cancel := context.WithTimeout(context.Background(), autoTriggerRunBudget)
 		defer cancel()
 
-		if err := h.autoSolverRunner(runCtx, tabID); err != nil &&
+		if err := h.autoSolverRunner(runCtx, tabID, tokenDomains); err != nil &&
 			!errors.Is(err, context.Canceled) &&
 			!errors.Is(err, context.DeadlineExceeded) {
 			slog.Warn("autosolver auto-trigger failed",
@@ -58,6 +64,23 @@ func (h *Handlers) maybeAutoSolve(_ context.Context, tabID, trigger string) {
 	}()
 }
 
+// autoSolverNavigateGuard refuses solver navigations that a /navigate
+// request with the same token could not make: URLs outside the token's
+// allowed domains or blocked by the IDPI allowlist.
+func (h *Handlers) autoSolverNavigateGuard(tokenDomains []string) adapters.NavigateGuard {
+	return func(url string) error {
+		if len(tokenDomains) > 0 && !apitoken.URLAllowed(url, tokenDomains) {
+			return fmt.Errorf("%s is outside the API token's allowed domains", url)
+		}
+		if h.currentTabDomainPolicyEnabled() {
+			if state := bridge.EvaluateTabPolicy(url, h.Config.IDPI, h.Config.AllowedDomains); state.Blocked {
+				return fmt.Errorf("url blocked by IDPI: %s", state.Reason)
+			}
+		}
+		return nil
+	}
+}
+
 func (h *Handlers) shouldAutoSolve(trigger string) bool {
 	if h == nil || h.Config == nil {
 		return false
@@ -78,12 +101,12 @@ func (h *Handlers) shouldAutoSolve(trigger string) bool {
 	}
 }
 
-func (h *Handlers) runAutoSolver(ctx context.Context, tabID string) error {
+func (h *Handlers) runAutoSolver(ctx context.Context, tabID string, tokenDomains []string) error {
 	if h == nil || h.Config == nil || h.Bridge == nil {
 		return nil
 	}
 
-	page, executor, err := adapters.NewFromBridge(h.Bridge, tabID)
+	page, executor, err := adapters.NewFromBridge(h.Bridge, tabID, h.autoSolverNavigateGuard(tokenDomains))
 	if err != nil {
 		return err
 	}
@@ -212,9 +235,7 @@ func (h *Handlers) normalizedAutoSolverConfig() coreautosolver.Config {
 }
 
 // llmProviderForAutoSolver returns the configured LLM provider, or nil when no
-// provider is configured. The provider is a skeleton today (returns
-// "not yet implemented"); wiring it gated on LLMProvider makes the llmFallback
-// switch live so it lights up automatically once a real client is implemented.
+// provider is configured. It is only consulted when llmFallback is on.
 func (h *Handlers) llmProviderForAutoSolver() coreautosolver.LLMProvider {
 	if h == nil || h.Config == nil {
 		return nil
@@ -223,7 +244,18 @@ func (h *Handlers) llmProviderForAutoSolver() coreautosolver.LLMProvider {
 	if provider == "" {
 		return nil
 	}
-	return autosolverllm.NewProvider(autosolverllm.ProviderConfig{Provider: provider})
+	llm := h.Config.AutoSolver.LLM
+	return autosolverllm.NewProvider(autosolverllm.ProviderConfig{
+		Provider:   provider,
+		Model:      strings.TrimSpace(llm.Model),
+		APIKey:     strings.TrimSpace(llm.APIKey),
+		BaseURL:    llm.BaseURL,
+		MaxTokens:  llm.MaxTokens,
+		Timeout:    time.Duration(llm.TimeoutSec) * time.Second,
+		MaxRetries: llm.MaxRetries,
+		InputCost:  llm.InputCostPerMTok,
+		OutputCost: llm.OutputCostPerMTok,
+	})
 }
 
 func (h *Handlers) buildAutoSolver(cfg coreautosolver.Config, includeSemantic bool) *coreautosolver.AutoSolver {
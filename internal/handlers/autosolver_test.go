@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"context"
+	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pinchtab/pinchtab/internal/apitoken"
 	"github.com/pinchtab/pinchtab/internal/config"
 )
 
@@ -111,7 +113,7 @@ func TestMaybeAutoSolve_InvokesRunnerWhenEnabled(t *testing.T) {
 
 	var calls atomic.Int64
 	done := make(chan struct{}, 8)
-	h.autoSolverRunner = func(_ context.Context, tabID string) error {
+	h.autoSolverRunner = func(_ context.Context, tabID string, _ []string) error {
 		calls.Add(1)
 		if tabID != "tab1" {
 			t.Errorf("runner tabID = %q, want tab1", tabID)
@@ -131,22 +133,73 @@ func TestMaybeAutoSolve_InvokesRunnerWhenEnabled(t *testing.T) {
 		return false
 	}
 
-	h.maybeAutoSolve(context.Background(), "tab1", autoSolverTriggerNavigate)
+	h.maybeAutoSolve(httptest.NewRequest("POST", "/navigate", nil), "tab1", autoSolverTriggerNavigate)
 	if !waitFor(1) {
 		t.Fatalf("autoSolverRunner calls = %d, want 1", calls.Load())
 	}
 	<-done
 
-	h.maybeAutoSolve(context.Background(), "", autoSolverTriggerNavigate)
+	h.maybeAutoSolve(httptest.NewRequest("POST", "/navigate", nil), "", autoSolverTriggerNavigate)
 	time.Sleep(20 * time.Millisecond) // ensure no goroutine was spawned
 	if got := calls.Load(); got != 1 {
 		t.Fatalf("autoSolverRunner calls with empty tab id = %d, want unchanged", got)
 	}
 
 	h.Config.AutoSolver.TriggerOnNavigate = false
-	h.maybeAutoSolve(context.Background(), "tab1", autoSolverTriggerNavigate)
+	h.maybeAutoSolve(httptest.NewRequest("POST", "/navigate", nil), "tab1", autoSolverTriggerNavigate)
 	time.Sleep(20 * time.Millisecond)
 	if got := calls.Load(); got != 1 {
 		t.Fatalf("autoSolverRunner calls with navigate trigger disabled = %d, want unchanged", got)
 	}
 }
+
+func TestAutoSolverNavigateGuard(t *testing.T) {
+	h := &Handlers{Config: &config.RuntimeConfig{
+		AllowedDomains: []string{"pinchtab.com", "example.com"},
+		IDPI:           config.IDPIConfig{Enabled: true, StrictMode: true},
+	}}
+
+	guard := h.autoSolverNavigateGuard(nil)
+	if err := guard("https://pinchtab.com/next"); err != nil {
+		t.Fatalf("allowed url refused: %v", err)
+	}
+	if err := guard("https://evil.test/"); err == nil {
+		t.Fatal("expected IDPI allowlist to refuse evil.test")
+	}
+
+	guard = h.autoSolverNavigateGuard([]string{"pinchtab.com"})
+	if err := guard("https://example.com/"); err == nil {
+		t.Fatal("expected token domains to refuse example.com")
+	}
+	if err := guard("https://pinchtab.com/"); err != nil {
+		t.Fatalf("token domain refused: %v", err)
+	}
+}
+
+func TestMaybeAutoSolve_PassesTokenDomains(t *testing.T) {
+	h := &Handlers{
+		Config: &config.RuntimeConfig{AutoSolver: config.AutoSolverConfig{
+			Enabled:           true,
+			AutoTrigger:       true,
+			TriggerOnNavigate: true,
+		}},
+	}
+	got := make(chan []string, 1)
+	h.autoSolverRunner = func(_ context.Context, _ string, tokenDomains []string) error {
+		got <- tokenDomains
+		return nil
+	}
+
+	req := httptest.NewRequest("POST", "/navigate", nil)
+	req = apitoken.WithToken(req, &apitoken.Token{Scope: apitoken.Scope{AllowedDomains: []string{"pinchtab.com"}}})
+	h.maybeAutoSolve(req, "tab1", autoSolverTriggerNavigate)
+
+	select {
+	case domains := <-got:
+		if len(domains) != 1 || domains[0] != "pinchtab.com" {
+			t.Fatalf("runner token domains = %v, want [pinchtab.com]", domains)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("autoSolverRunner was not called")
+	}
+}
@@ -18,8 +18,10 @@ import (
 // @Endpoint GET /capture
 // @Description Paired screenshot + accessibility snapshot. Returns both
 //
-//	artefacts plus a frame/loader parity check (pairing.navigated) and an
-//	opaque domEpoch handshake token cached on the tab's RefCache.
+//	artefacts plus a frame/loader parity check (pairing.navigated), an
+//	in-document churn flag (pairing.domMutated), and an opaque domEpoch
+//	handshake token cached on the tab's RefCache. Action endpoints accept it
+//	as expectedEpoch.
 //
 // @Param tabId string query Tab ID (optional, defaults to current)
 // @Param selector string query Optional scope (clips screenshot and filters snapshot subtree)
@@ -172,7 +174,7 @@ func (h *Handlers) HandleCapture(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Persist the snapshot half to the ref cache with the minted epoch so that
-	// follow-up `/click eN` etc. can later opt into an epoch handshake.
+	// follow-up actions can opt into the epoch handshake via expectedEpoch.
 	h.Bridge.SetRefCache(resolvedTabID, &bridge.RefCache{
 		Refs:     result.Refs,
 		Targets:  bridge.RefTargetsFromNodes(result.Nodes),
@@ -235,6 +237,7 @@ func (h *Handlers) HandleCapture(w http.ResponseWriter, r *http.Request) {
 		},
 		"pairing": map[string]any{
 			"navigated":         result.Navigated,
+			"domMutated":        result.DomMutated,
 			"captureDurationMs": result.DurationMs,
 		},
 		"image": imageInfo,
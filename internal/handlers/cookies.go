@@ -39,7 +39,7 @@ func (h *Handlers) HandleGetCookies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if url != "" && !h.enforceURLDomainPolicy(w, url) {
+	if url != "" && !h.enforceURLDomainPolicy(w, r, url) {
 		return
 	}
 
@@ -195,7 +195,7 @@ func (h *Handlers) HandleSetCookies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.enforceURLDomainPolicy(w, req.URL) {
+	if !h.enforceURLDomainPolicy(w, r, req.URL) {
 		return
 	}
 
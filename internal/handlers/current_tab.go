@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -161,6 +162,24 @@ func (s *CurrentTabStore) ClearTab(tabID string) {
 	s.mu.Unlock()
 }
 
+// ScopesForTab returns the keys ("session:<id>", "agent:<id>") of the
+// scopes whose current tab is tabID, sorted.
+func (s *CurrentTabStore) ScopesForTab(tabID string) []string {
+	if s == nil || tabID == "" {
+		return nil
+	}
+	s.mu.RLock()
+	var keys []string
+	for key, entry := range s.entries {
+		if entry.tabID == tabID {
+			keys = append(keys, key)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Strings(keys)
+	return keys
+}
+
 func currentTabScopeFromRequest(r *http.Request) currentTabScope {
 	if sess, ok := session.FromRequest(r); ok && sess != nil {
 		if id := strings.TrimSpace(sess.ID); id != "" {
@@ -184,6 +203,19 @@ func currentTabScopeFromRequest(r *http.Request) currentTabScope {
 	return currentTabScope{kind: currentTabScopeGlobal, key: currentTabScopeGlobal, label: "global"}
 }
 
+// currentTabScopeFromKey parses a key returned by ScopesForTab.
+func currentTabScopeFromKey(key string) (currentTabScope, bool) {
+	kind, id, ok := strings.Cut(key, ":")
+	if !ok || strings.TrimSpace(id) == "" {
+		return currentTabScope{}, false
+	}
+	switch kind {
+	case currentTabScopeSession, currentTabScopeAgent:
+		return scopedCurrentTab(kind, id), true
+	}
+	return currentTabScope{}, false
+}
+
 func scopedCurrentTab(kind, id string) currentTabScope {
 	return currentTabScope{
 		kind:  kind,
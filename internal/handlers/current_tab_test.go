@@ -24,6 +24,8 @@ type scopedCurrentTabBridge struct {
 	ensureCall int
 }
 
+func (m *scopedCurrentTabBridge) TabSession(string) string { return "" }
+
 func newScopedCurrentTabBridge() *scopedCurrentTabBridge {
 	return &scopedCurrentTabBridge{
 		tabs: map[string]context.Context{
@@ -113,8 +113,9 @@ func (h *Handlers) enforceDownloadTabPolicy(w http.ResponseWriter, r *http.Reque
 		return false
 	}
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, http.StatusLocked, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return false
 	}
 	currentURL, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID)
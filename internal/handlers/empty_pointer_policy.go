@@ -55,6 +55,10 @@ func WriteTabContextError(w http.ResponseWriter, err error, notFoundStatus int)
 		httpx.ErrorCode(w, http.StatusConflict, "no_current_tab", err.Error(), false, nil)
 		return
 	}
+	if errors.Is(err, ErrTabSessionForbidden) {
+		httpx.ErrorCode(w, http.StatusForbidden, "tab_session_forbidden", err.Error(), false, nil)
+		return
+	}
 	if notFoundStatus == 0 {
 		notFoundStatus = http.StatusNotFound
 	}
@@ -24,6 +24,8 @@ type findMockBridge struct {
 	refCache *bridge.RefCache
 }
 
+func (m *findMockBridge) TabSession(string) string { return "" }
+
 func (m *findMockBridge) EnsureBrowser(cfg *config.RuntimeConfig) error  { return nil }
 func (m *findMockBridge) RestartBrowser(cfg *config.RuntimeConfig) error { return nil }
 
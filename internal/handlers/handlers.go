@@ -14,10 +14,12 @@ import (
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/contentguard"
 	"github.com/pinchtab/pinchtab/internal/dashboard"
+	"github.com/pinchtab/pinchtab/internal/handoff"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 	"github.com/pinchtab/pinchtab/internal/idpi"
 	"github.com/pinchtab/pinchtab/internal/ids"
 	"github.com/pinchtab/pinchtab/internal/routes"
+	"github.com/pinchtab/pinchtab/internal/workflow"
 	"github.com/pinchtab/semantic"
 	"github.com/pinchtab/semantic/recovery"
 )
@@ -38,6 +40,7 @@ type Handlers struct {
 	Version         string
 	clipboard       clipboardStore
 	credentialStore *credentialStore
+	workflows       *workflow.Store
 
 	// emptyPointerPolicy controls behavior when an identified caller omits
 	// tabId and has no stored scoped current tab. See EmptyPointerPolicy.
@@ -45,10 +48,15 @@ type Handlers struct {
 
 	recorder *recorder
 
+	// handoffNotifier posts handoff webhooks; nil when none are configured.
+	handoffNotifier *handoff.Notifier
+
 	// Optional dependency injection (for unit testing)
 	evalJS           func(ctx context.Context, expression string, out *string) error
-	autoSolverRunner func(ctx context.Context, tabID string) error
+	autoSolverRunner func(ctx context.Context, tabID string, tokenDomains []string) error
 	evalRuntime      func(ctx context.Context, expression string, out any, opts bridge.EvalOpts) error
+	domEpochChanged  func(ctx context.Context, epoch string) (bool, error)
+	armDomEpoch      func(ctx context.Context, epoch string) error
 }
 
 func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService, d *dashboard.Dashboard, o bridge.OrchestratorService) *Handlers {
@@ -72,7 +80,9 @@ func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService,
 		},
 		CurrentTabs:     NewCurrentTabStore(),
 		credentialStore: newCredentialStore(),
+		workflows:       workflow.NewStore(workflow.DefaultRunTTL),
 		recorder:        &recorder{},
+		handoffNotifier: handoff.NewNotifier(cfg.Handoff.Webhooks, cfg.Handoff.WebhookSecret),
 	}
 
 	h.recorder.captureFrame = func(ctx context.Context, quality int) ([]byte, error) {
@@ -108,6 +118,8 @@ func New(b bridge.BridgeAPI, cfg *config.RuntimeConfig, p bridge.ProfileService,
 		return h.Bridge.Evaluate(ctx, expression, out, bridge.EvalOpts{})
 	}
 	h.autoSolverRunner = h.runAutoSolver
+	h.domEpochChanged = bridge.DomEpochChanged
+	h.armDomEpoch = bridge.ArmDomEpoch
 	h.evalRuntime = func(ctx context.Context, expression string, out any, opts bridge.EvalOpts) error {
 		return h.Bridge.Evaluate(ctx, expression, out, opts)
 	}
@@ -316,6 +328,9 @@ func (h *Handlers) bridgeBindings() []routeBinding {
 		{pattern: "POST /handoff", tab: h.HandleTabHandoff, tabOnly: true},
 		{pattern: "POST /resume", tab: h.HandleTabResume, tabOnly: true},
 		{pattern: "GET /handoff", tab: h.HandleTabHandoffStatus, tabOnly: true},
+		{pattern: "GET /takeover", tab: h.HandleTabTakeover, tabOnly: true},
+		{pattern: "GET /takeover/stream", tab: h.HandleTabTakeoverStream, tabOnly: true},
+		{pattern: "POST /takeover/resume", tab: h.HandleTabTakeoverResume, tabOnly: true},
 		{pattern: "GET /cookies", root: h.HandleGetCookies, tab: h.HandleTabGetCookies},
 		{pattern: "POST /cookies", root: h.HandleSetCookies, tab: h.HandleTabSetCookies},
 		{pattern: "DELETE /cookies", root: h.HandleClearCookies, tab: h.HandleTabClearCookies},
@@ -330,10 +345,14 @@ func (h *Handlers) bridgeBindings() []routeBinding {
 		{pattern: "GET /network/export", root: h.HandleNetworkExport, tab: h.HandleTabNetworkExport},
 		{pattern: "GET /network/export/stream", root: h.HandleNetworkExportStream, tab: h.HandleTabNetworkExportStream},
 		{pattern: "GET /network/{requestId}", root: h.HandleNetworkByID, tab: h.HandleTabNetworkByID},
+		{pattern: "GET /network/{requestId}/frames", root: h.HandleNetworkFrames, tab: h.HandleTabNetworkFrames},
 		{pattern: "POST /network/clear", root: h.HandleNetworkClear},
 		{pattern: "GET /network/route", root: h.HandleNetworkRouteList, tab: h.HandleTabNetworkRouteList},
 		{pattern: "POST /network/route", root: h.HandleNetworkRoute, tab: h.HandleTabNetworkRoute},
 		{pattern: "DELETE /network/route", root: h.HandleNetworkUnroute, tab: h.HandleTabNetworkUnroute},
+		{pattern: "GET /network/replay", root: h.HandleNetworkReplayStatus, tab: h.HandleTabNetworkReplayStatus},
+		{pattern: "POST /network/replay", root: h.HandleNetworkReplay, tab: h.HandleTabNetworkReplay},
+		{pattern: "DELETE /network/replay", root: h.HandleNetworkReplayStop, tab: h.HandleTabNetworkReplayStop},
 		{pattern: "GET /console", root: h.HandleGetConsoleLogs},
 		{pattern: "POST /console/clear", root: h.HandleClearConsoleLogs},
 		{pattern: "GET /errors", root: h.HandleGetErrorLogs},
@@ -343,6 +362,9 @@ func (h *Handlers) bridgeBindings() []routeBinding {
 		{pattern: "POST /clipboard/copy", root: h.HandleClipboardCopy},
 		{pattern: "GET /clipboard/paste", root: h.HandleClipboardPaste},
 		{pattern: "GET /stealth/status", root: h.HandleStealthStatus},
+		{pattern: "GET /proxypools", root: h.HandleProxyPools},
+		{pattern: "GET /session/context", root: h.HandleSessionContext},
+		{pattern: "DELETE /session/context", root: h.HandleDisposeSessionContext},
 		{pattern: "POST /fingerprint/rotate", root: h.HandleFingerprintRotate},
 		{pattern: "GET /solvers", root: h.HandleListSolvers},
 		{pattern: "GET /config/autosolver", root: h.HandleAutoSolverConfig},
@@ -354,11 +376,32 @@ func (h *Handlers) bridgeBindings() []routeBinding {
 		{pattern: "POST /emulation/headers", root: h.HandleSetHeaders, tab: h.HandleTabSetHeaders},
 		{pattern: "POST /emulation/credentials", root: h.HandleSetCredentials, tab: h.HandleTabSetCredentials},
 		{pattern: "POST /emulation/media", root: h.HandleSetMedia, tab: h.HandleTabSetMedia},
+		{pattern: "GET /permissions", root: h.HandlePermissions, tab: h.HandleTabPermissions},
+		{pattern: "POST /permissions", root: h.HandleSetPermissions, tab: h.HandleTabSetPermissions},
+		{pattern: "DELETE /permissions", root: h.HandleResetPermissions, tab: h.HandleTabResetPermissions},
+		{pattern: "GET /webauthn", root: h.HandleWebAuthn, tab: h.HandleTabWebAuthn},
+		{pattern: "POST /webauthn/authenticators", root: h.HandleAddAuthenticator, tab: h.HandleTabAddAuthenticator},
+		{pattern: "DELETE /webauthn/authenticators/{authenticatorId}", root: h.HandleRemoveAuthenticator, tab: h.HandleTabRemoveAuthenticator},
+		{pattern: "POST /webauthn/authenticators/{authenticatorId}/presence", root: h.HandleAuthenticatorPresence, tab: h.HandleTabAuthenticatorPresence},
+		{pattern: "GET /webauthn/authenticators/{authenticatorId}/credentials", root: h.HandleWebAuthnCredentials, tab: h.HandleTabWebAuthnCredentials},
+		{pattern: "POST /webauthn/authenticators/{authenticatorId}/credentials", root: h.HandleImportWebAuthnCredential, tab: h.HandleTabImportWebAuthnCredential},
+		{pattern: "DELETE /webauthn/authenticators/{authenticatorId}/credentials", root: h.HandleRemoveWebAuthnCredential, tab: h.HandleTabRemoveWebAuthnCredential},
+		{pattern: "POST /webauthn/authenticators/{authenticatorId}/credentials/export", root: h.HandleExportWebAuthnCredentials, tab: h.HandleTabExportWebAuthnCredentials},
 		{pattern: "POST /cache/clear", root: h.HandleCacheClear},
 		{pattern: "GET /cache/status", root: h.HandleCacheStatus},
 		{pattern: "POST /storage", root: h.HandleStorage, tab: h.HandleTabStorageSet},
 		{pattern: "DELETE /storage", root: h.HandleStorage, tab: h.HandleTabStorageDelete},
 		{pattern: "GET /storage", root: h.HandleStorage, tab: h.HandleTabStorageGet},
+		{pattern: "GET /serviceworkers", root: h.HandleServiceWorkers, tab: h.HandleTabServiceWorkers},
+		{pattern: "POST /serviceworkers/unregister", root: h.HandleUnregisterServiceWorker, tab: h.HandleTabUnregisterServiceWorker},
+		{pattern: "POST /serviceworkers/stop", root: h.HandleStopServiceWorkers, tab: h.HandleTabStopServiceWorkers},
+		{pattern: "POST /serviceworkers/bypass", root: h.HandleServiceWorkerBypass, tab: h.HandleTabServiceWorkerBypass},
+		{pattern: "GET /cachestorage", root: h.HandleCacheStorage, tab: h.HandleTabCacheStorage},
+		{pattern: "GET /cachestorage/entries", root: h.HandleCacheStorageEntries, tab: h.HandleTabCacheStorageEntries},
+		{pattern: "GET /cachestorage/entry", root: h.HandleCacheStorageEntry, tab: h.HandleTabCacheStorageEntry},
+		{pattern: "DELETE /cachestorage", root: h.HandleCacheStorageDelete, tab: h.HandleTabCacheStorageDelete},
+		{pattern: "GET /indexeddb", root: h.HandleIndexedDB, tab: h.HandleTabIndexedDB},
+		{pattern: "GET /indexeddb/export", root: h.HandleIndexedDBExport, tab: h.HandleTabIndexedDBExport},
 		{pattern: "GET /state", root: h.HandleStateCurrent},
 		{pattern: "GET /state/list", root: h.HandleStateList},
 		{pattern: "GET /state/show", root: h.HandleStateShow},
@@ -368,6 +411,9 @@ func (h *Handlers) bridgeBindings() []routeBinding {
 		{pattern: "POST /state/clean", root: h.HandleStateClean},
 		{pattern: "POST /evaluate", root: h.HandleEvaluate, tab: h.HandleTabEvaluate},
 		{pattern: "POST /macro", root: h.HandleMacro},
+		{pattern: "POST /workflows/run", root: h.HandleWorkflowRun},
+		{pattern: "GET /workflows/runs/{id}", root: h.HandleWorkflowRunGet},
+		{pattern: "POST /workflows/runs/{id}/cancel", root: h.HandleWorkflowRunCancel},
 		{pattern: "GET /download", root: h.HandleDownload, tab: h.HandleTabDownload},
 		{pattern: "POST /upload", root: h.HandleUpload, tab: h.HandleTabUpload},
 		{pattern: "GET /screencast", root: h.HandleScreencast},
@@ -408,6 +454,9 @@ var specialCaseRoutes = []string{
 	"GET /navigate",
 	"GET /action",
 	"GET /tabs/{id}/state",
+	"GET /metrics/prometheus",
+	"GET /migration/tabs",
+	"POST /migration/tabs",
 	"POST /shutdown",
 }
 
@@ -458,6 +507,11 @@ func (h *Handlers) registerSpecialRoutes(mux muxRegistrar, doShutdown func()) {
 	// is the ungated lightweight tab-runtime readiness view, so it is not a
 	// TabScoped catalog entry and is registered explicitly here.
 	mux.HandleFunc("GET /tabs/{id}/state", h.HandleTabState)
+	mux.HandleFunc("GET /metrics/prometheus", h.HandlePrometheusMetrics)
+	// Drain-time tab migration is orchestrator-only, so it stays out of the
+	// catalog and never becomes a shorthand route.
+	mux.HandleFunc("GET /migration/tabs", h.HandleMigrationExport)
+	mux.HandleFunc("POST /migration/tabs", h.HandleMigrationImport)
 	if doShutdown != nil {
 		mux.HandleFunc("POST /shutdown", h.HandleShutdown(doShutdown))
 	}
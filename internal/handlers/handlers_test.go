@@ -15,6 +15,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 )
 
 type mockBridge struct {
@@ -45,6 +46,17 @@ type mockBridge struct {
 	evaluateCalls int
 	evaluateExprs []string
 	evaluateFn    func(expression string, result any) error
+
+	visitedOrigins []string
+	originTargets  []string
+
+	tabProxyOpts []bridge.TabProxyOptions
+	// sessionTabs records the session of every CreateTabInSession call.
+	sessionTabs      []string
+	disposedSessions []string
+	// tabSessions maps tab IDs to the isolated session that owns them.
+	tabSessions map[string]string
+	proxyPools  []proxypool.PoolStatus
 }
 
 func (m *mockBridge) TabContext(tabID string) (*bridge.TabHandle, string, error) {
@@ -81,6 +93,47 @@ func (m *mockBridge) CreateTab(url string) (string, context.Context, context.Can
 	return "tab_abc12345", ctx, cancel, nil
 }
 
+func (m *mockBridge) CreateTabWithProxy(url string, opts bridge.TabProxyOptions) (string, context.Context, context.CancelFunc, error) {
+	if opts.Pool == "missing" {
+		return "", nil, nil, fmt.Errorf("%w %q", proxypool.ErrUnknownPool, opts.Pool)
+	}
+	m.tabProxyOpts = append(m.tabProxyOpts, opts)
+	return m.CreateTab(url)
+}
+
+func (m *mockBridge) ProxyPools() []proxypool.PoolStatus { return m.proxyPools }
+
+func (m *mockBridge) TabProxies() []bridge.TabProxy { return nil }
+
+func (m *mockBridge) CreateTabInSession(url, sessionID string) (string, context.Context, context.CancelFunc, error) {
+	m.sessionTabs = append(m.sessionTabs, sessionID)
+	return m.CreateTab(url)
+}
+
+func (m *mockBridge) SessionContext(sessionID string) (bridge.SessionContext, bool) {
+	for _, id := range m.sessionTabs {
+		if id == sessionID {
+			return bridge.SessionContext{SessionID: sessionID, BrowserContextID: "ctx-" + sessionID}, true
+		}
+	}
+	return bridge.SessionContext{}, false
+}
+
+func (m *mockBridge) SessionContexts() []bridge.SessionContext {
+	out := make([]bridge.SessionContext, 0, len(m.sessionTabs))
+	for _, id := range m.sessionTabs {
+		out = append(out, bridge.SessionContext{SessionID: id, BrowserContextID: "ctx-" + id})
+	}
+	return out
+}
+
+func (m *mockBridge) TabSession(tabID string) string { return m.tabSessions[tabID] }
+
+func (m *mockBridge) DisposeSessionContext(sessionID string) (int, error) {
+	m.disposedSessions = append(m.disposedSessions, sessionID)
+	return 0, nil
+}
+
 func (m *mockBridge) CloseTab(tabID string) error {
 	if tabID == "fail" {
 		return fmt.Errorf("close failed")
@@ -167,6 +220,10 @@ func (m *mockBridge) NetworkMonitor() *bridge.NetworkMonitor {
 	return nil
 }
 
+func (m *mockBridge) PermissionRequests(tabID string) ([]bridge.PermissionRequest, error) {
+	return nil, nil
+}
+
 func (m *mockBridge) GetDialogManager() *bridge.DialogManager {
 	if m.dialogManager == nil {
 		m.dialogManager = bridge.NewDialogManager()
@@ -197,6 +254,13 @@ func (m *mockBridge) Evaluate(ctx context.Context, expression string, result any
 	return nil
 }
 
+func (m *mockBridge) WithOriginTarget(ctx context.Context, origin string, fn func(ctx context.Context) error) error {
+	m.originTargets = append(m.originTargets, origin)
+	return fn(ctx)
+}
+
+func (m *mockBridge) VisitedOrigins(context.Context) []string { return m.visitedOrigins }
+
 func (m *mockBridge) CallFunctionOnNode(ctx context.Context, backendNodeID int64, functionDecl string, args []map[string]any, result any) error {
 	return fmt.Errorf("not implemented")
 }
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/dashboard"
+	"github.com/pinchtab/pinchtab/internal/handoff"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -48,21 +51,49 @@ func (h *Handlers) handoffErrorDetails(tabID string) map[string]any {
 	return details
 }
 
-// pauseTabForHandoff marks a tab as paused for human handoff and broadcasts
-// a dashboard event. source identifies what triggered the handoff
-// (e.g. "autosolver", "manual"). Returns the applied reason.
-func (h *Handlers) pauseTabForHandoff(tabID, reason, source string, timeout time.Duration) (string, error) {
+// tabResumeRecorder is implemented by bridges that keep the outcome of a
+// tab's last handoff so the agent can read it after resuming.
+type tabResumeRecorder interface {
+	RecordTabResume(tabID string, resume bridge.TabHandoffResume)
+	LastTabResume(tabID string) (bridge.TabHandoffResume, bool)
+}
+
+// handoffPause describes an applied pause.
+type handoffPause struct {
+	Reason string
+	// TakeoverURL is the signed link to the takeover view; empty when no
+	// link could be minted. LinkExpiresAt is its expiry.
+	TakeoverURL   string
+	LinkExpiresAt time.Time
+}
+
+// pauseTabForHandoff marks a tab as paused for human handoff, mints a signed
+// takeover link, and tells the dashboard and any configured webhooks. source
+// identifies what triggered the handoff (e.g. "autosolver", "manual").
+func (h *Handlers) pauseTabForHandoff(tabID, reason, source string, timeout time.Duration) (handoffPause, error) {
 	ctrl, ok := h.handoffController()
 	if !ok {
-		return "", fmt.Errorf("bridge does not support handoff state")
+		return handoffPause{}, fmt.Errorf("bridge does not support handoff state")
 	}
 	reason = strings.TrimSpace(reason)
 	if reason == "" {
 		reason = "manual_handoff"
 	}
+	pause := handoffPause{Reason: reason}
 	if err := ctrl.SetTabHandoff(tabID, reason, timeout); err != nil {
-		return reason, err
+		return pause, err
 	}
+	if state, ok := ctrl.TabHandoffState(tabID); ok {
+		link, expiresAt, err := h.mintTakeoverLink(tabID, state)
+		if err != nil {
+			slog.Warn("handoff: takeover link unavailable", "tab", tabID, "err", err)
+		} else {
+			pause.TakeoverURL, pause.LinkExpiresAt = link, expiresAt
+		}
+	}
+
+	now := time.Now().UTC()
+	pageURL, title := h.tabPageInfo(tabID)
 	if h.Dashboard != nil {
 		payload := map[string]any{
 			"tabId":       tabID,
@@ -70,17 +101,124 @@ func (h *Handlers) pauseTabForHandoff(tabID, reason, source string, timeout time
 			"reason":      reason,
 			"source":      source,
 			"hint":        handoffHintMessage,
-			"requestedAt": time.Now().UTC().Format(time.RFC3339),
+			"requestedAt": now.Format(time.RFC3339),
 		}
 		if timeout > 0 {
 			payload["timeoutMs"] = int(timeout / time.Millisecond)
 		}
+		if pageURL != "" {
+			payload["url"] = pageURL
+		}
+		if title != "" {
+			payload["title"] = title
+		}
+		if pause.TakeoverURL != "" {
+			payload["takeoverUrl"] = pause.TakeoverURL
+			payload["linkExpiresAt"] = pause.LinkExpiresAt.Format(time.RFC3339)
+		}
 		h.Dashboard.BroadcastSystemEvent(dashboard.SystemEvent{
 			Type:     "tab.handoff",
 			Instance: payload,
 		})
 	}
-	return reason, nil
+	ev := handoff.Event{
+		Event:       handoff.EventRequested,
+		TabID:       tabID,
+		Reason:      reason,
+		Source:      source,
+		Hint:        handoffHintMessage,
+		URL:         pageURL,
+		Title:       title,
+		TakeoverURL: pause.TakeoverURL,
+		Time:        now.Format(time.RFC3339),
+	}
+	if !pause.LinkExpiresAt.IsZero() {
+		ev.ExpiresAt = pause.LinkExpiresAt.Format(time.RFC3339)
+	}
+	h.handoffNotifier.Notify(ev)
+	return pause, nil
+}
+
+// mintTakeoverLink signs a takeover link for the pause described by state.
+// The link never outlives the pause itself.
+func (h *Handlers) mintTakeoverLink(tabID string, state bridge.TabHandoffState) (string, time.Time, error) {
+	if h.Config == nil {
+		return "", time.Time{}, fmt.Errorf("no runtime config")
+	}
+	ttl := time.Duration(h.Config.Handoff.LinkTTLSec) * time.Second
+	if ttl <= 0 {
+		ttl = config.DefaultHandoffLinkTTLSec * time.Second
+	}
+	expiresAt := time.Now().UTC().Add(ttl).Truncate(time.Second)
+	if !state.ExpiresAt.IsZero() && state.ExpiresAt.Before(expiresAt) {
+		expiresAt = state.ExpiresAt.Truncate(time.Second)
+	}
+	token, err := handoff.SignLink(h.Config.Token, handoff.LinkClaims{
+		TabID:      tabID,
+		Generation: state.PausedAt.UnixNano(),
+		ExpiresAt:  expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return handoff.TakeoverURL(h.Config.Handoff.PublicURL, tabID, token), expiresAt, nil
+}
+
+// tabPageInfo returns the tab's URL and title for handoff notifications.
+func (h *Handlers) tabPageInfo(tabID string) (string, string) {
+	targets, err := h.Bridge.ListTargets()
+	if err != nil {
+		return "", ""
+	}
+	for _, t := range targets {
+		if t.TargetID == tabID {
+			return t.URL, t.Title
+		}
+	}
+	return "", ""
+}
+
+// resumeTabFromHandoff hands a paused tab back to the agent, records the
+// structured outcome, and tells the dashboard and any configured webhooks.
+func (h *Handlers) resumeTabFromHandoff(r *http.Request, tabID string, resume bridge.TabHandoffResume) error {
+	ctrl, ok := h.handoffController()
+	if !ok {
+		return fmt.Errorf("bridge does not support handoff state")
+	}
+	if err := ctrl.ResumeTabHandoff(tabID); err != nil {
+		return err
+	}
+	resume.Status = strings.TrimSpace(resume.Status)
+	if resume.ResumedAt.IsZero() {
+		resume.ResumedAt = time.Now().UTC()
+	}
+	if rec, ok := h.Bridge.(tabResumeRecorder); ok {
+		rec.RecordTabResume(tabID, resume)
+	}
+
+	h.recordActivity(r, activity.Update{Action: "resume", TabID: tabID})
+	resumedAt := resume.ResumedAt.Format(time.RFC3339)
+	if h.Dashboard != nil {
+		h.Dashboard.BroadcastSystemEvent(dashboard.SystemEvent{
+			Type: "tab.resume",
+			Instance: map[string]any{
+				"tabId":        tabID,
+				"status":       resume.Status,
+				"resolvedData": resume.Data,
+				"resumedBy":    resume.ResumedBy,
+				"resumedAt":    resumedAt,
+			},
+		})
+	}
+	h.handoffNotifier.Notify(handoff.Event{
+		Event:     handoff.EventResumed,
+		TabID:     tabID,
+		Status:    resume.Status,
+		Data:      resume.Data,
+		ResumedBy: resume.ResumedBy,
+		Time:      resumedAt,
+	})
+	return nil
 }
 
 func (h *Handlers) HandleTabHandoff(w http.ResponseWriter, r *http.Request) {
@@ -105,8 +243,9 @@ func (h *Handlers) HandleTabHandoff(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return
 	}
 	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
@@ -122,7 +261,7 @@ func (h *Handlers) HandleTabHandoff(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	timeout := time.Duration(req.TimeoutMs) * time.Millisecond
-	reason, err := h.pauseTabForHandoff(resolvedTabID, req.Reason, "manual", timeout)
+	pause, err := h.pauseTabForHandoff(resolvedTabID, req.Reason, "manual", timeout)
 	if err != nil {
 		httpx.ErrorCode(w, 500, "handoff_failed", err.Error(), false, nil)
 		return
@@ -133,13 +272,17 @@ func (h *Handlers) HandleTabHandoff(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]any{
 		"tabId":     resolvedTabID,
 		"status":    "paused_handoff",
-		"reason":    reason,
+		"reason":    pause.Reason,
 		"timeoutMs": req.TimeoutMs,
 		"hint":      handoffHintMessage,
 	}
 	if timeout > 0 {
 		resp["expiresAt"] = time.Now().UTC().Add(timeout).Format(time.RFC3339)
 	}
+	if pause.TakeoverURL != "" {
+		resp["takeoverUrl"] = pause.TakeoverURL
+		resp["linkExpiresAt"] = pause.LinkExpiresAt.Format(time.RFC3339)
+	}
 	httpx.JSON(w, 200, resp)
 }
 
@@ -165,37 +308,29 @@ func (h *Handlers) HandleTabResume(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return
 	}
 	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
 		return
 	}
 
-	ctrl, ok := h.handoffController()
-	if !ok {
+	if _, ok := h.handoffController(); !ok {
 		httpx.ErrorCode(w, 501, "handoff_not_supported", "bridge does not support handoff state", false, nil)
 		return
 	}
-	if err := ctrl.ResumeTabHandoff(resolvedTabID); err != nil {
+	err = h.resumeTabFromHandoff(r, resolvedTabID, bridge.TabHandoffResume{
+		Status:    req.Status,
+		Data:      req.Data,
+		ResumedBy: "api",
+	})
+	if err != nil {
 		httpx.ErrorCode(w, 500, "resume_failed", err.Error(), false, nil)
 		return
 	}
 
-	h.recordActivity(r, activity.Update{Action: "resume", TabID: resolvedTabID})
-	if h.Dashboard != nil {
-		h.Dashboard.BroadcastSystemEvent(dashboard.SystemEvent{
-			Type: "tab.resume",
-			Instance: map[string]any{
-				"tabId":        resolvedTabID,
-				"status":       strings.TrimSpace(req.Status),
-				"resolvedData": req.Data,
-				"resumedAt":    time.Now().UTC().Format(time.RFC3339),
-			},
-		})
-	}
-
 	httpx.JSON(w, 200, map[string]any{
 		"tabId":        resolvedTabID,
 		"status":       "active",
@@ -238,8 +373,14 @@ func (h *Handlers) HandleTabHandoffStatus(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	httpx.JSON(w, 200, map[string]any{
+	resp := map[string]any{
 		"tabId":  resolvedTabID,
 		"status": "active",
-	})
+	}
+	if rec, ok := h.Bridge.(tabResumeRecorder); ok {
+		if last, ok := rec.LastTabResume(resolvedTabID); ok {
+			resp["lastResume"] = last
+		}
+	}
+	httpx.JSON(w, 200, resp)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/cdptk"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 	"github.com/pinchtab/pinchtab/internal/stealth"
 )
 
@@ -326,6 +327,26 @@ func (m *MockBridge) CreateTab(url string) (string, context.Context, context.Can
 	return "", context.Background(), func() {}, nil
 }
 
+func (m *MockBridge) CreateTabWithProxy(url string, _ bridge.TabProxyOptions) (string, context.Context, context.CancelFunc, error) {
+	return m.CreateTab(url)
+}
+
+func (m *MockBridge) ProxyPools() []proxypool.PoolStatus { return nil }
+
+func (m *MockBridge) TabProxies() []bridge.TabProxy { return nil }
+
+func (m *MockBridge) CreateTabInSession(url, _ string) (string, context.Context, context.CancelFunc, error) {
+	return m.CreateTab(url)
+}
+
+func (m *MockBridge) SessionContext(string) (bridge.SessionContext, bool) {
+	return bridge.SessionContext{}, false
+}
+
+func (m *MockBridge) SessionContexts() []bridge.SessionContext  { return nil }
+func (m *MockBridge) TabSession(string) string                  { return "" }
+func (m *MockBridge) DisposeSessionContext(string) (int, error) { return 0, nil }
+
 func (m *MockBridge) CloseTab(tabID string) error {
 	return nil
 }
@@ -364,6 +385,14 @@ func (m *MockBridge) Lock(tabID, owner string, ttl time.Duration) error {
 	return nil
 }
 
+func (m *MockBridge) WaitLock(ctx context.Context, tabID, owner string, ttl time.Duration) error {
+	return nil
+}
+
+func (m *MockBridge) RenewLock(tabID, owner string, ttl time.Duration) error {
+	return nil
+}
+
 func (m *MockBridge) Unlock(tabID, owner string) error {
 	return nil
 }
@@ -426,6 +455,100 @@ func (m *MockBridge) RemoveRouteRule(tabID, pattern string) (int, error) { retur
 
 func (m *MockBridge) ListRouteRules(tabID string) ([]bridge.RouteRule, error) { return nil, nil }
 
+func (m *MockBridge) StartNetworkReplay(tabID string, replay *bridge.HARReplay) error { return nil }
+
+func (m *MockBridge) StopNetworkReplay(tabID string) (*bridge.ReplayStatus, error) { return nil, nil }
+
+func (m *MockBridge) NetworkReplayStatus(tabID string) (*bridge.ReplayStatus, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) SetPermissions(ctx context.Context, origin string, perms map[string]string) error {
+	return nil
+}
+
+func (m *MockBridge) ResetPermissions(ctx context.Context, origin string, names []string) error {
+	return nil
+}
+
+func (m *MockBridge) PermissionOverrides(_ context.Context, origin string) map[string]string {
+	return nil
+}
+
+func (m *MockBridge) QueryPermissions(ctx context.Context, names []string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) PermissionRequests(tabID string) ([]bridge.PermissionRequest, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) AddVirtualAuthenticator(ctx context.Context, tabID string, opts bridge.WebAuthnAuthenticatorOptions) (*bridge.VirtualAuthenticator, int, error) {
+	return nil, 0, nil
+}
+
+func (m *MockBridge) RemoveVirtualAuthenticator(ctx context.Context, tabID, authenticatorID string) error {
+	return nil
+}
+
+func (m *MockBridge) VirtualAuthenticators(tabID string) []bridge.VirtualAuthenticator { return nil }
+
+func (m *MockBridge) SetAuthenticatorPresence(ctx context.Context, tabID, authenticatorID string, userPresence, userVerified *bool) (*bridge.VirtualAuthenticator, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) WebAuthnCredentials(ctx context.Context, tabID, authenticatorID string) ([]*bridge.WebAuthnCredential, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) AddWebAuthnCredential(ctx context.Context, tabID, authenticatorID string, cred *bridge.WebAuthnCredential) error {
+	return nil
+}
+
+func (m *MockBridge) RemoveWebAuthnCredential(ctx context.Context, tabID, authenticatorID, credentialID string) error {
+	return nil
+}
+
+func (m *MockBridge) ServiceWorkers(ctx context.Context, tabID string) ([]bridge.ServiceWorkerInfo, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) UnregisterServiceWorker(ctx context.Context, tabID, scopeURL string) error {
+	return nil
+}
+
+func (m *MockBridge) StopServiceWorkers(ctx context.Context, tabID, versionID string) error {
+	return nil
+}
+
+func (m *MockBridge) SetServiceWorkerBypass(ctx context.Context, tabID string, bypass bool) error {
+	return nil
+}
+
+func (m *MockBridge) ServiceWorkerBypass(tabID string) bool { return false }
+
+func (m *MockBridge) CacheStorageCaches(ctx context.Context, origin string) ([]*bridge.CacheStorageCache, error) {
+	return nil, nil
+}
+
+func (m *MockBridge) CacheStorageEntries(ctx context.Context, cacheID string, skip, limit int, pathFilter string) ([]*bridge.CacheStorageEntry, int, error) {
+	return nil, 0, nil
+}
+
+func (m *MockBridge) CacheStorageResponse(ctx context.Context, cacheID, requestURL string) ([]byte, error) {
+	return nil, bridge.ErrCacheEntryNotFound
+}
+
+func (m *MockBridge) DeleteCacheStorage(ctx context.Context, cacheID, requestURL string) error {
+	return nil
+}
+
+func (m *MockBridge) WithOriginTarget(ctx context.Context, origin string, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *MockBridge) VisitedOrigins(context.Context) []string { return nil }
+
 func (m *MockBridge) GetDialogManager() *bridge.DialogManager {
 	return bridge.NewDialogManager()
 }
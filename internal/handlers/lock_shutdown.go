@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,11 +15,20 @@ import (
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
+// maxLockWait caps how long a single /lock request may queue for the lock.
+// It stays under the 60s server write timeout and orchestrator proxy
+// timeout; callers that need to wait longer retry.
+const maxLockWait = 50 * time.Second
+
+// HandleTabLock acquires, waits for, or renews a tab lease. The response
+// carries the lease's fencing token as lockToken.
 func (h *Handlers) HandleTabLock(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		TabID      string `json:"tabId"`
 		Owner      string `json:"owner"`
 		TimeoutSec int    `json:"timeoutSec"`
+		WaitSec    int    `json:"waitSec"`
+		Renew      bool   `json:"renew"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
@@ -27,31 +38,64 @@ func (h *Handlers) HandleTabLock(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, 400, fmt.Errorf("tabId and owner required"))
 		return
 	}
+	if req.WaitSec < 0 {
+		httpx.Error(w, 400, fmt.Errorf("waitSec must be >= 0"))
+		return
+	}
+	if !h.enforceTabSession(w, r, req.TabID) {
+		return
+	}
 
 	timeout := bridge.DefaultLockTimeout
 	if req.TimeoutSec > 0 {
 		timeout = time.Duration(req.TimeoutSec) * time.Second
 	}
 
-	if err := h.Bridge.Lock(req.TabID, req.Owner, timeout); err != nil {
-		httpx.Error(w, 409, err)
-		return
+	switch {
+	case req.Renew:
+		if err := h.Bridge.RenewLock(req.TabID, req.Owner, timeout); err != nil {
+			if errors.Is(err, bridge.ErrLockNotHeld) {
+				httpx.ErrorCode(w, 409, "lock_not_held", err.Error(), false, nil)
+				return
+			}
+			httpx.Error(w, 409, err)
+			return
+		}
+	case req.WaitSec > 0:
+		wait := min(time.Duration(req.WaitSec)*time.Second, maxLockWait)
+		ctx, cancel := context.WithTimeout(r.Context(), wait)
+		defer cancel()
+		if err := h.Bridge.WaitLock(ctx, req.TabID, req.Owner, timeout); err != nil {
+			httpx.ErrorCode(w, 409, "lock_wait_timeout", err.Error(), true, nil)
+			return
+		}
+	default:
+		if err := h.Bridge.Lock(req.TabID, req.Owner, timeout); err != nil {
+			httpx.Error(w, 409, err)
+			return
+		}
 	}
 
 	h.recordActivity(r, activity.Update{Action: "tab.lock", TabID: req.TabID})
 
 	lock := h.Bridge.TabLockInfo(req.TabID)
+	if lock == nil {
+		httpx.ErrorCode(w, 409, "lock_not_held", fmt.Sprintf("lock on tab %s lapsed before it could be reported", req.TabID), true, nil)
+		return
+	}
 	httpx.JSON(w, 200, map[string]any{
 		"locked":    true,
 		"owner":     lock.Owner,
 		"expiresAt": lock.ExpiresAt.Format(time.RFC3339),
+		"lockToken": lock.Token,
 	})
 }
 
 func (h *Handlers) HandleTabUnlock(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		TabID string `json:"tabId"`
-		Owner string `json:"owner"`
+		TabID     string `json:"tabId"`
+		Owner     string `json:"owner"`
+		LockToken uint64 `json:"lockToken"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
@@ -61,6 +105,18 @@ func (h *Handlers) HandleTabUnlock(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, 400, fmt.Errorf("tabId and owner required"))
 		return
 	}
+	if !h.enforceTabSession(w, r, req.TabID) {
+		return
+	}
+
+	// A stale token means this caller's lease already ended; releasing now
+	// would drop whoever holds the lock under the same owner name.
+	if token := resolveLockToken(r, req.LockToken); token != 0 {
+		if lock := h.Bridge.TabLockInfo(req.TabID); lock == nil || lock.Token != token {
+			httpx.ErrorCode(w, 409, "stale_lock_token", fmt.Sprintf("lock token %d is not the current lease on tab %s", token, req.TabID), false, nil)
+			return
+		}
+	}
 
 	if err := h.Bridge.Unlock(req.TabID, req.Owner); err != nil {
 		httpx.Error(w, 409, err)
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -137,6 +139,107 @@ func TestHandleTabUnlockByID_Mismatch(t *testing.T) {
 	}
 }
 
+func postLock(t *testing.T, h *Handlers, body map[string]any) (int, map[string]any) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/lock", bytes.NewReader(data))
+	h.HandleTabLock(w, r)
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestHandleTabLock_LockTokenAndRenew(t *testing.T) {
+	b := bridge.New(context.Background(), context.Background(), nil)
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	code, resp := postLock(t, h, map[string]any{"tabId": "t1", "owner": "agent-a"})
+	if code != 200 {
+		t.Fatalf("lock: %d %v", code, resp)
+	}
+	token, _ := resp["lockToken"].(float64)
+	if token == 0 {
+		t.Fatalf("expected lockToken: %v", resp)
+	}
+
+	code, resp = postLock(t, h, map[string]any{"tabId": "t1", "owner": "agent-a", "renew": true})
+	if code != 200 || resp["lockToken"] != token {
+		t.Fatalf("renew: %d %v", code, resp)
+	}
+
+	code, resp = postLock(t, h, map[string]any{"tabId": "t1", "owner": "agent-b", "renew": true})
+	if code != 409 || resp["code"] != "lock_not_held" {
+		t.Fatalf("renew by non-owner: %d %v", code, resp)
+	}
+}
+
+func TestHandleTabLock_Wait(t *testing.T) {
+	b := bridge.New(context.Background(), context.Background(), nil)
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	_ = b.Lock("t1", "agent-a", 10*time.Minute)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = b.Unlock("t1", "agent-a")
+	}()
+	code, resp := postLock(t, h, map[string]any{"tabId": "t1", "owner": "agent-b", "waitSec": 5})
+	if code != 200 || resp["owner"] != "agent-b" {
+		t.Fatalf("wait: %d %v", code, resp)
+	}
+}
+
+func TestEnforceTabLease_LockToken(t *testing.T) {
+	b := bridge.New(context.Background(), context.Background(), nil)
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	_ = b.Lock("t1", "agent-a", 10*time.Minute)
+	stale := b.TabLockInfo("t1").Token
+	_ = b.Unlock("t1", "agent-a")
+	_ = b.Lock("t1", "agent-b", 10*time.Minute)
+	current := b.TabLockInfo("t1").Token
+
+	if err := h.enforceTabLease("t1", "agent-b", current); err != nil {
+		t.Fatalf("current token rejected: %v", err)
+	}
+	// The token fences the lease but does not stand in for its owner.
+	for _, owner := range []string{"", "agent-c"} {
+		err := h.enforceTabLease("t1", owner, current)
+		if err == nil || errors.Is(err, errStaleLockToken) {
+			t.Fatalf("current token with owner %q: err = %v, want tab_locked", owner, err)
+		}
+	}
+	err := h.enforceTabLease("t1", "agent-a", stale)
+	if !errors.Is(err, errStaleLockToken) {
+		t.Fatalf("stale token: err = %v", err)
+	}
+	w := httptest.NewRecorder()
+	writeTabLeaseError(w, err)
+	if w.Code != 409 || !strings.Contains(w.Body.String(), "stale_lock_token") {
+		t.Fatalf("stale token response: %d %s", w.Code, w.Body.String())
+	}
+
+	// A token for a lease that lapsed with nobody else holding is still stale.
+	_ = b.Unlock("t1", "agent-b")
+	if err := h.enforceTabLease("t1", "", current); !errors.Is(err, errStaleLockToken) {
+		t.Fatalf("token without lease: err = %v", err)
+	}
+}
+
+func TestResolveLockToken(t *testing.T) {
+	r := httptest.NewRequest("POST", "/action?lockToken=7", nil)
+	if got := resolveLockToken(r, 3); got != 7 {
+		t.Fatalf("query token = %d", got)
+	}
+	r.Header.Set("X-Lock-Token", "9")
+	if got := resolveLockToken(r, 3); got != 9 {
+		t.Fatalf("header token = %d", got)
+	}
+	if got := resolveLockToken(httptest.NewRequest("POST", "/action", nil), 3); got != 3 {
+		t.Fatalf("body token = %d", got)
+	}
+}
+
 func TestHandleShutdown_CallsFunc(t *testing.T) {
 	called := make(chan bool, 1)
 	doShutdown := func() { called <- true }
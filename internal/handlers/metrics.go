@@ -1,14 +1,99 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+var (
+	metricHTTPRequests = metrics.Default.NewCounterVec("pinchtab_http_requests",
+		"HTTP requests by route pattern, method and status code.", "route", "method", "code")
+	metricHTTPDuration = metrics.Default.NewHistogramVec("pinchtab_http_request_duration_seconds",
+		"HTTP request latency by route pattern and method.", metrics.DefaultLatencyBuckets, "route", "method")
 )
 
+func init() {
+	metrics.Default.Register(collectProcessMetrics)
+}
+
 func recordStaleRefRetry() {
 	atomic.AddUint64(&metricStaleRefRetries, 1)
 }
 
+// recordRouteMetrics counts a finished request against the mux pattern that
+// served it. The pattern is only known once the mux has routed the request,
+// so this runs after next.ServeHTTP; unrouted requests share one series to
+// keep scanners and typos from inflating cardinality.
+func recordRouteMetrics(r *http.Request, route string, code int, elapsed time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	metricHTTPRequests.Inc(route, r.Method, strconv.Itoa(code))
+	metricHTTPDuration.Observe(elapsed.Seconds(), route, r.Method)
+}
+
+type routeHolderKey struct{}
+
+// routeHolder carries the mux pattern back out to LoggingMiddleware. The mux
+// sets Pattern on the request it is handed, which is a copy whenever a
+// middleware in between swaps in a request with a new context (agent
+// sessions, scoped tokens, SSO identities).
+type routeHolder struct {
+	pattern string
+}
+
+func withRouteHolder(r *http.Request) (*http.Request, *routeHolder) {
+	holder := &routeHolder{}
+	return r.WithContext(context.WithValue(r.Context(), routeHolderKey{}, holder)), holder
+}
+
+// RoutePatternMiddleware records the pattern the mux matched for the
+// request. It must wrap the mux directly so it sees the request the mux
+// routed.
+func RoutePatternMiddleware(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if holder, ok := r.Context().Value(routeHolderKey{}).(*routeHolder); ok {
+			holder.pattern = r.Pattern
+		}
+	})
+}
+
+// routePattern returns the mux pattern that served r without its method
+// prefix, or "" when the request was not routed.
+func routePattern(r *http.Request, holder *routeHolder) string {
+	route := r.Pattern
+	if holder != nil && holder.pattern != "" {
+		route = holder.pattern
+	}
+	if _, path, ok := strings.Cut(route, " "); ok {
+		route = path
+	}
+	return route
+}
+
+func collectProcessMetrics() []metrics.Family {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	return []metrics.Family{
+		{Name: "pinchtab_rate_limited_requests", Help: "Requests rejected by the rate limiter.", Type: metrics.Counter,
+			Samples: []metrics.Sample{{Suffix: "_total", Value: float64(atomic.LoadUint64(&metricRateLimited))}}},
+		{Name: "pinchtab_stale_ref_retries", Help: "Actions retried after an element ref went stale.", Type: metrics.Counter,
+			Samples: []metrics.Sample{{Suffix: "_total", Value: float64(atomic.LoadUint64(&metricStaleRefRetries))}}},
+		gauge("pinchtab_rate_limit_tracked_hosts", "Client hosts with an active rate-limit bucket.", float64(RateBucketHostCount())),
+		gauge("pinchtab_go_goroutines", "Goroutines in this process.", float64(runtime.NumGoroutine())),
+		gauge("pinchtab_go_heap_alloc_bytes", "Go heap bytes allocated and in use.", float64(memStats.HeapAlloc)),
+	}
+}
+
 // RateBucketHostCount returns the number of unique hosts in rate limit tracking
 func RateBucketHostCount() int {
 	rateMu.Lock()
@@ -46,3 +131,57 @@ func SnapshotMetrics() map[string]any {
 		"goNumGC":         memStats.NumGC,
 	}
 }
+
+// HandlePrometheusMetrics serves the process metrics plus this instance's
+// browser gauges in the OpenMetrics or Prometheus text format.
+func (h *Handlers) HandlePrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	families := append(metrics.Default.Gather(), h.browserMetricFamilies()...)
+	metrics.ServeHTTP(w, r, metrics.Merge(families))
+}
+
+// browserMetricFamilies reports tab counts and Chrome memory. Both are
+// omitted while no browser is running rather than reported as zero. The
+// legacy DOM counters are left out because they are never populated.
+func (h *Handlers) browserMetricFamilies() []metrics.Family {
+	if h.Bridge == nil {
+		return nil
+	}
+	var out []metrics.Family
+	if targets, err := h.Bridge.ListTargets(); err == nil {
+		tabs := 0
+		for _, t := range targets {
+			if !bridge.IsTransientURL(t.URL, h.Config.Port) {
+				tabs++
+			}
+		}
+		out = append(out, gauge("pinchtab_browser_tabs", "Open tabs in this browser.", float64(tabs)))
+	}
+	if mem, err := h.Bridge.GetAggregatedMemoryMetrics(); err == nil && mem != nil {
+		const mb = 1024 * 1024
+		out = append(out,
+			gauge("pinchtab_browser_memory_bytes", "Resident memory of the browser process tree.", mem.MemoryMB*mb),
+			gauge("pinchtab_browser_js_heap_used_bytes", "Estimated JavaScript heap in use, derived from process memory.", mem.JSHeapUsedMB*mb),
+			gauge("pinchtab_browser_js_heap_total_bytes", "Estimated JavaScript heap allocated, derived from process memory.", mem.JSHeapTotalMB*mb),
+			gauge("pinchtab_browser_renderer_processes", "Browser renderer processes.", float64(mem.Renderers)),
+			metrics.Family{Name: "pinchtab_browser_cpu_seconds", Help: "CPU time used by the browser process tree.", Type: metrics.Counter,
+				Samples: []metrics.Sample{{Suffix: "_total", Value: mem.CPUSeconds}}},
+		)
+	}
+	if es, ok := h.Bridge.(executorStatsProvider); ok {
+		if stats, ok := es.ExecutorStats(); ok {
+			out = append(out,
+				gauge("pinchtab_tab_actions_inflight", "Tab actions running now.", float64(stats.SemaphoreUsed)),
+				gauge("pinchtab_tab_actions_queued", "Tab actions waiting for an execution slot or their tab.", float64(stats.Queued)),
+			)
+		}
+	}
+	return out
+}
+
+type executorStatsProvider interface {
+	ExecutorStats() (bridge.ExecutorStats, bool)
+}
+
+func gauge(name, help string, v float64) metrics.Family {
+	return metrics.Family{Name: name, Help: help, Type: metrics.Gauge, Samples: []metrics.Sample{{Value: v}}}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/session"
+)
+
+func TestLoggingMiddleware_RecordsRouteMetrics(t *testing.T) {
+	resetObservabilityForTests()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tabs/{id}/text", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := LoggingMiddleware(mux)
+
+	for _, path := range []string{"/tabs/a/text", "/tabs/b/text", "/nope"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/metrics/prometheus", nil)
+	w := httptest.NewRecorder()
+	h.HandlePrometheusMetrics(w, req)
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	out := w.Body.String()
+	for _, want := range []string{
+		`pinchtab_http_requests_total{route="/tabs/{id}/text",method="GET",code="200"} 2`,
+		`pinchtab_http_requests_total{route="unmatched",method="GET",code="404"} 1`,
+		`pinchtab_http_request_duration_seconds_count{route="/tabs/{id}/text",method="GET"} 2`,
+		`pinchtab_stale_ref_retries_total 0`,
+		`pinchtab_rate_limited_requests_total 0`,
+		`pinchtab_browser_tabs 1`,
+		`pinchtab_browser_js_heap_used_bytes 5.24288e+07`,
+		`pinchtab_browser_memory_bytes 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+}
+
+func TestLoggingMiddleware_RecordsRouteForSessionRequests(t *testing.T) {
+	resetObservabilityForTests()
+	store := session.NewStore(session.Config{Enabled: true, IdleTimeout: 30 * time.Minute, MaxLifetime: 24 * time.Hour})
+	_, token, _ := store.Create("test-agent", "test", "")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tabs/{id}/text", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	// Session auth swaps in a request with a new context before the mux
+	// routes it, so the pattern has to come back through the route holder.
+	cfg := &config.RuntimeConfig{Token: "server-token"}
+	handler := LoggingMiddleware(AuthMiddlewareWithTokens(cfg, nil, store, nil, RoutePatternMiddleware(mux)))
+
+	req := httptest.NewRequest("GET", "/tabs/a/text", nil)
+	req.Header.Set("Authorization", "Session "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	out := httptest.NewRecorder()
+	h.HandlePrometheusMetrics(out, httptest.NewRequest("GET", "/metrics/prometheus", nil))
+	if want := `pinchtab_http_requests_total{route="/tabs/{id}/text",method="GET",code="200"} 1`; !strings.Contains(out.Body.String(), want) {
+		t.Fatalf("missing %s in:\n%s", want, out.Body.String())
+	}
+}
+
+func TestHandlePrometheusMetrics_OpenMetrics(t *testing.T) {
+	resetObservabilityForTests()
+	recordStaleRefRetry()
+
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+	req := httptest.NewRequest("GET", "/metrics/prometheus", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	w := httptest.NewRecorder()
+	h.HandlePrometheusMetrics(w, req)
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	out := w.Body.String()
+	if !strings.Contains(out, "# TYPE pinchtab_stale_ref_retries counter\npinchtab_stale_ref_retries_total 1\n") {
+		t.Errorf("stale ref counter missing:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Errorf("OpenMetrics output must end with # EOF:\n%s", out)
+	}
+}
+
+type executorStatsBridge struct {
+	mockBridge
+	stats bridge.ExecutorStats
+}
+
+func (b *executorStatsBridge) ExecutorStats() (bridge.ExecutorStats, bool) {
+	return b.stats, true
+}
+
+func TestHandlePrometheusMetrics_TabExecutorGauges(t *testing.T) {
+	resetObservabilityForTests()
+	b := &executorStatsBridge{stats: bridge.ExecutorStats{SemaphoreUsed: 3, Queued: 7}}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandlePrometheusMetrics(w, httptest.NewRequest("GET", "/metrics/prometheus", nil))
+
+	out := w.Body.String()
+	for _, want := range []string{`pinchtab_tab_actions_inflight 3`, `pinchtab_tab_actions_queued 7`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+}
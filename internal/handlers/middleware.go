@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
 	"github.com/pinchtab/pinchtab/internal/authn"
 	"github.com/pinchtab/pinchtab/internal/browsersession"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 	"github.com/pinchtab/pinchtab/internal/session"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 var (
@@ -37,8 +39,17 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		sw := &httpx.StatusWriter{ResponseWriter: w, Code: 200}
+		r, holder := withRouteHolder(r)
 		next.ServeHTTP(sw, r)
-		ms := uint64(time.Since(start).Milliseconds())
+		elapsed := time.Since(start)
+		ms := uint64(elapsed.Milliseconds())
+		route := routePattern(r, holder)
+		recordRouteMetrics(r, route, sw.Code, elapsed)
+		if route != "" {
+			span := tracing.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(tracing.String("http.route", route))
+		}
 		atomic.AddUint64(&metricRequestsTotal, 1)
 		atomic.AddUint64(&metricRequestLatencyN, ms)
 		if sw.Code >= 400 {
@@ -80,6 +91,12 @@ func AuthMiddleware(cfg *config.RuntimeConfig, next http.Handler) http.Handler {
 }
 
 func AuthMiddlewareWithSessions(cfg *config.RuntimeConfig, sessions *browsersession.Manager, agentSessions *session.Store, next http.Handler) http.Handler {
+	return AuthMiddlewareWithTokens(cfg, sessions, agentSessions, nil, next)
+}
+
+// AuthMiddlewareWithTokens also accepts the scoped API tokens of apiTokens
+// as bearer credentials.
+func AuthMiddlewareWithTokens(cfg *config.RuntimeConfig, sessions *browsersession.Manager, agentSessions *session.Store, apiTokens *apitoken.Store, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if isPublicDashboardPath(r.URL.Path) || isPublicAuthPath(r.URL.Path) {
 			next.ServeHTTP(w, r)
@@ -89,6 +106,12 @@ func AuthMiddlewareWithSessions(cfg *config.RuntimeConfig, sessions *browsersess
 			next.ServeHTTP(w, r)
 			return
 		}
+		// Signed handoff links stand in for the token on takeover routes so a
+		// human can pick up a paused tab without holding API credentials.
+		if takeoverLinkAllowed(cfg.Token, r) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		token := strings.TrimSpace(cfg.Token)
 		if token == "" {
 			httpx.ErrorCode(w, http.StatusServiceUnavailable, "token_required", "server token is not configured", false, nil)
@@ -128,18 +151,38 @@ func AuthMiddlewareWithSessions(cfg *config.RuntimeConfig, sessions *browsersess
 			}
 			r.Header.Set(activity.HeaderAgentID, sess.AgentID)
 			r.Header.Set(activity.HeaderPTSessionID, sess.ID)
+			if sess.Isolated {
+				r.Header.Set(SessionIsolatedHeader, "1")
+			}
 			activity.EnrichRequest(r, activity.Update{
 				AgentID:   sess.AgentID,
 				SessionID: sess.ID,
 			})
 			r = session.WithSession(r, sess)
 		case authn.MethodHeader:
-			if subtle.ConstantTimeCompare([]byte(creds.Value), []byte(token)) != 1 {
+			if subtle.ConstantTimeCompare([]byte(creds.Value), []byte(token)) == 1 {
+				break
+			}
+			tok, ok := apiTokens.Authenticate(creds.Value)
+			if !ok {
 				authn.ClearSessionCookie(w, r, cfg != nil && cfg.TrustProxyHeaders, cookieSecureSetting(cfg))
 				w.Header().Set("WWW-Authenticate", `Bearer realm="pinchtab", error="bad_token"`)
 				httpx.ErrorCode(w, 401, "bad_token", "unauthorized", false, nil)
 				return
 			}
+			if denial, ok := tokenRequestAllowed(r, tok); !ok {
+				authn.AuditWarn(r, "auth.token_scope_denied", "tokenId", tok.ID, "code", denial.code)
+				httpx.ErrorCode(w, http.StatusForbidden, denial.code, denial.message, false, map[string]any{
+					"tokenId": tok.ID,
+				})
+				return
+			}
+			r.Header.Set(activity.HeaderPTTokenID, tok.ID)
+			if len(tok.Scope.AllowedDomains) > 0 {
+				r.Header.Set(TokenDomainsHeader, strings.Join(tok.Scope.AllowedDomains, ","))
+			}
+			activity.EnrichRequest(r, activity.Update{TokenID: tok.ID})
+			r = apitoken.WithToken(r, tok)
 		case authn.MethodCookie:
 			if !cookieOriginAllowed(r, cfg.TrustProxyHeaders) {
 				httpx.ErrorCode(w, http.StatusForbidden, "origin_forbidden", "same-origin browser request required for session authentication", false, map[string]any{
@@ -157,6 +200,16 @@ func AuthMiddlewareWithSessions(cfg *config.RuntimeConfig, sessions *browsersess
 				httpx.ErrorCode(w, 403, "header_auth_required", "authorization header required for this endpoint", false, nil)
 				return
 			}
+			if identity, ok := sessions.Identity(creds.Value, token); ok {
+				r = authn.WithIdentity(r, identity)
+				if !identityRoleAllows(r, identity.Role) {
+					authn.AuditWarn(r, "auth.role_forbidden")
+					httpx.ErrorCode(w, http.StatusForbidden, "role_forbidden", "your dashboard role does not allow this action", false, map[string]any{
+						"role": identity.Role,
+					})
+					return
+				}
+			}
 			if cookieElevationRequired(r, cfg) && !sessions.IsElevated(creds.Value, token) {
 				authn.AuditWarn(r, "auth.elevation_required", "elevationWindowSec", int(sessions.ElevationWindow().Seconds()))
 				httpx.ErrorCode(w, 403, "elevation_required", "re-enter API token to continue", false, map[string]any{
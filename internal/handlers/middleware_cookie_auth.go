@@ -19,7 +19,8 @@ func isPublicDashboardPath(path string) bool {
 
 func isPublicAuthPath(path string) bool {
 	switch path {
-	case "/api/auth/login", "/api/auth/logout":
+	case "/api/auth/login", "/api/auth/logout",
+		"/api/auth/oidc", "/api/auth/oidc/login", "/api/auth/oidc/callback":
 		return true
 	default:
 		return false
@@ -38,6 +39,9 @@ func cookieAuthAllowed(r *http.Request) bool {
 			path == "/api/agents",
 			path == "/api/events",
 			path == "/api/config",
+			path == "/api/auth/oidc/session",
+			path == "/api/tokens",
+			strings.HasPrefix(path, "/api/tokens/"),
 			path == "/sessions",
 			strings.HasPrefix(path, "/sessions/"),
 			path == "/profiles",
@@ -59,6 +63,8 @@ func cookieAuthAllowed(r *http.Request) bool {
 		switch {
 		case path == "/api/auth/elevate":
 			return true
+		case path == "/api/tokens" || apiTokenRevokePath(path):
+			return true
 		case strings.HasPrefix(path, "/api/agents/") && strings.HasSuffix(path, "/events"):
 			return true
 		case path == "/action":
@@ -88,16 +94,47 @@ func cookieElevationRequired(r *http.Request, cfg *config.RuntimeConfig) bool {
 	if cfg == nil || !cfg.Sessions.Dashboard.RequireElevation {
 		return false
 	}
+	return cookieAdminRoute(r)
+}
+
+// identityRoleAllows applies the role of a single sign-on session on top of
+// cookieAuthAllowed: viewers may only read, and only admins reach the
+// routes that otherwise ask for elevation.
+func identityRoleAllows(r *http.Request, role string) bool {
+	switch role {
+	case authn.RoleAdmin:
+		return true
+	case authn.RoleOperator:
+		return !cookieAdminRoute(r)
+	case authn.RoleViewer:
+		return r.Method == http.MethodGet || r.Method == http.MethodHead
+	}
+	return false
+}
+
+func cookieAdminRoute(r *http.Request) bool {
 	path := strings.TrimSpace(r.URL.Path)
 	switch r.Method {
 	case http.MethodPut:
 		return path == "/api/config"
 	case http.MethodPost:
-		return path == "/shutdown"
+		return path == "/shutdown" || path == "/api/tokens" || apiTokenRevokePath(path) || webAuthnExportPath(path)
 	}
 	return false
 }
 
+// webAuthnExportPath matches the root and /tabs/{id} forms of the WebAuthn
+// private key export.
+func webAuthnExportPath(path string) bool {
+	return strings.HasSuffix(path, "/credentials/export") &&
+		(strings.HasPrefix(path, "/webauthn/authenticators/") || (strings.HasPrefix(path, "/tabs/") && strings.Contains(path, "/webauthn/authenticators/")))
+}
+
+func apiTokenRevokePath(path string) bool {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(path, "/api/tokens/"), "/revoke")
+	return ok && strings.HasPrefix(path, "/api/tokens/") && id != "" && !strings.Contains(id, "/")
+}
+
 func cookieOriginAllowed(r *http.Request, trustProxy bool) bool {
 	if isWebSocketUpgrade(r) {
 		origin := strings.TrimSpace(r.Header.Get("Origin"))
@@ -65,6 +65,8 @@ func sessionAdminRoute(method, path string) bool {
 		return true
 	case method == http.MethodGet && strings.HasPrefix(path, "/api/agents/") && !strings.HasSuffix(path, "/events"):
 		return true
+	case path == "/api/tokens" || strings.HasPrefix(path, "/api/tokens/"):
+		return true
 	case path == "/sessions" || strings.HasPrefix(path, "/sessions/"):
 		return path != "/sessions/me"
 	case path == "/instances" || strings.HasPrefix(path, "/instances/"):
@@ -130,7 +132,8 @@ func sessionBrowseGrantAllows(method, path string) bool {
 			path == "/openapi.json",
 			path == "/help",
 			path == "/health",
-			path == "/sessions/me":
+			path == "/sessions/me",
+			path == "/session/context":
 			return true
 		case tabRouteHasSuffix(path, "/snapshot"),
 			tabRouteHasSuffix(path, "/screenshot"),
@@ -167,6 +170,8 @@ func sessionBrowseGrantAllows(method, path string) bool {
 			tabRouteHasSuffix(path, "/unlock"):
 			return true
 		}
+	case http.MethodDelete:
+		return path == "/session/context"
 	}
 	return false
 }
@@ -255,11 +260,22 @@ func sessionEvaluateGrantAllows(method, path string) bool {
 func sessionStorageGrantAllows(method, path string) bool {
 	switch method {
 	case http.MethodGet:
-		return path == "/storage" || path == "/state" || path == "/state/list" || path == "/state/show"
+		switch path {
+		case "/storage", "/state", "/state/list", "/state/show",
+			"/serviceworkers", "/cachestorage", "/cachestorage/entries", "/cachestorage/entry",
+			"/indexeddb", "/indexeddb/export":
+			return true
+		}
+		return false
 	case http.MethodPost:
-		return path == "/storage" || path == "/state/save" || path == "/state/load" || path == "/state/clean"
+		switch path {
+		case "/storage", "/state/save", "/state/load", "/state/clean",
+			"/serviceworkers/unregister", "/serviceworkers/stop", "/serviceworkers/bypass":
+			return true
+		}
+		return false
 	case http.MethodDelete:
-		return path == "/storage" || path == "/state"
+		return path == "/storage" || path == "/state" || path == "/cachestorage"
 	default:
 		return false
 	}
@@ -294,9 +310,13 @@ func sessionSolveGrantAllows(method, path string) bool {
 func sessionTasksGrantAllows(method, path string) bool {
 	switch method {
 	case http.MethodGet:
-		return path == "/tasks" || path == "/scheduler/stats" || strings.HasPrefix(path, "/tasks/")
+		return path == "/tasks" || path == "/scheduler/stats" || strings.HasPrefix(path, "/tasks/") ||
+			path == "/schedules" || strings.HasPrefix(path, "/schedules/")
 	case http.MethodPost:
-		return path == "/tasks" || path == "/tasks/batch" || (strings.HasPrefix(path, "/tasks/") && strings.HasSuffix(path, "/cancel"))
+		return path == "/tasks" || path == "/tasks/batch" || (strings.HasPrefix(path, "/tasks/") && strings.HasSuffix(path, "/cancel")) ||
+			path == "/schedules"
+	case http.MethodDelete:
+		return strings.HasPrefix(path, "/schedules/")
 	default:
 		return false
 	}
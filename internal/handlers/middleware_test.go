@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 	"github.com/pinchtab/pinchtab/internal/session"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 type activityCaptureRecorder struct {
@@ -846,6 +849,58 @@ func TestLoggingMiddleware(t *testing.T) {
 	}
 }
 
+type recordedSpans struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *recordedSpans) Export(_ context.Context, _ string, spans []tracing.SpanData) error {
+	r.mu.Lock()
+	r.spans = append(r.spans, spans...)
+	r.mu.Unlock()
+	return nil
+}
+
+func TestLoggingMiddleware_NamesSpanForSessionRequests(t *testing.T) {
+	spans := &recordedSpans{}
+	tracer := tracing.New(tracing.Config{Exporter: spans, SampleRatio: 1})
+	tracing.Install(tracer)
+	t.Cleanup(func() { tracing.Install(nil) })
+
+	store := session.NewStore(session.Config{Enabled: true, IdleTimeout: 30 * time.Minute, MaxLifetime: 24 * time.Hour})
+	_, token, _ := store.Create("test-agent", "test", "")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tabs/{id}/snapshot", func(w http.ResponseWriter, r *http.Request) {})
+	cfg := &config.RuntimeConfig{Token: "server-token"}
+	handler := tracing.Middleware(LoggingMiddleware(AuthMiddlewareWithTokens(cfg, nil, store, nil, RoutePatternMiddleware(mux))))
+
+	req := httptest.NewRequest("GET", "/tabs/t1/snapshot", nil)
+	req.Header.Set("Authorization", "Session "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans.mu.Lock()
+	defer spans.mu.Unlock()
+	if len(spans.spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans.spans))
+	}
+	span := spans.spans[0]
+	if span.Name != "GET /tabs/{id}/snapshot" {
+		t.Fatalf("span name = %q", span.Name)
+	}
+	found := false
+	for _, attr := range span.Attrs {
+		if attr.Key == "http.route" && attr.Value == "/tabs/{id}/snapshot" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("span has no http.route: %+v", span.Attrs)
+	}
+}
+
 func TestLoggingMiddleware_RecordsFailure(t *testing.T) {
 	resetObservabilityForTests()
 	handler := RequestIDMiddleware(LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1507,3 +1562,49 @@ func TestAuthMiddleware_SessionAuthDisabled(t *testing.T) {
 		t.Fatalf("expected 401, got %d", rr.Code)
 	}
 }
+
+func TestAuthMiddleware_CookieIdentityRoles(t *testing.T) {
+	cfg := &config.RuntimeConfig{Token: "secret123"}
+	sessions := browsersession.NewManager(browsersession.Config{})
+
+	var seen authn.Identity
+	handler := AuthMiddlewareWithSessions(cfg, sessions, nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = authn.IdentityFromRequest(r)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		role   string
+		method string
+		path   string
+		want   int
+	}{
+		{authn.RoleViewer, http.MethodGet, "/api/config", http.StatusOK},
+		{authn.RoleViewer, http.MethodPost, "/instances/start", http.StatusForbidden},
+		{authn.RoleOperator, http.MethodPost, "/instances/start", http.StatusOK},
+		{authn.RoleOperator, http.MethodPut, "/api/config", http.StatusForbidden},
+		{authn.RoleOperator, http.MethodPost, "/api/tokens", http.StatusForbidden},
+		{authn.RoleAdmin, http.MethodPut, "/api/config", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			sessionID, err := sessions.CreateWithIdentity(cfg.Token, authn.Identity{Subject: "u-1", Email: "ada@example.com", Role: tt.role})
+			if err != nil {
+				t.Fatalf("CreateWithIdentity() error = %v", err)
+			}
+			seen = authn.Identity{}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.AddCookie(&http.Cookie{Name: authn.CookieName, Value: sessionID})
+			req.Header.Set("Referer", "http://example.com/dashboard")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want == http.StatusOK && seen.Email != "ada@example.com" {
+				t.Fatalf("identity not attached to request: %+v", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/routes"
+)
+
+// tokenScopeDenial explains why an API token may not call an endpoint.
+type tokenScopeDenial struct {
+	code    string
+	message string
+}
+
+// tokenRequestAllowed checks the endpoint against the token's families and
+// capability gates. Instance, profile and domain limits are enforced where
+// the instance and URL are known: the orchestrator proxy and the instance
+// handlers.
+func tokenRequestAllowed(r *http.Request, tok *apitoken.Token) (tokenScopeDenial, bool) {
+	method := strings.ToUpper(strings.TrimSpace(r.Method))
+	path := strings.TrimSpace(r.URL.Path)
+
+	if !tokenFamiliesAllow(tok, method, path) {
+		return tokenScopeDenial{"token_scope_forbidden", "API token is not allowed to access this endpoint"}, false
+	}
+	if capability := routes.CapabilityFor(method, path); !tok.AllowsCapability(capability) {
+		return tokenScopeDenial{"token_capability_forbidden", "API token is not granted the " + string(capability) + " capability"}, false
+	}
+	return tokenScopeDenial{}, true
+}
+
+func tokenFamiliesAllow(tok *apitoken.Token, method, path string) bool {
+	if instanceManagementRoute(path) {
+		if !tok.AllowsFamily(apitoken.FamilyInstances) {
+			return false
+		}
+		// Per-instance routes that reach into the browser also need the
+		// family of the endpoint they proxy to.
+		if child, ok := instanceChildRoute(method, path); ok {
+			return tokenBrowserFamiliesAllow(tok, method, child)
+		}
+		return true
+	}
+	if sessionAdminRoute(method, path) {
+		return false
+	}
+	return tokenBrowserFamiliesAllow(tok, method, path)
+}
+
+func tokenBrowserFamiliesAllow(tok *apitoken.Token, method, path string) bool {
+	if tok.AllowsFamily(apitoken.FamilyAll) {
+		// "*" leaves out tasks when the token is instance or domain limited.
+		return tok.AllowsFamily(apitoken.FamilyTasks) || !sessionGrantAllows(apitoken.FamilyTasks, method, path)
+	}
+	for _, family := range tok.Scope.Families {
+		if sessionGrantAllows(family, method, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func instanceManagementRoute(path string) bool {
+	return path == "/instances" || strings.HasPrefix(path, "/instances/") ||
+		path == "/profiles" || strings.HasPrefix(path, "/profiles/")
+}
+
+// instanceChildRoute maps an /instances/{id}/... route proxied into the
+// instance's browser to the instance-local route it reaches.
+func instanceChildRoute(method, path string) (string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/instances/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", false
+	}
+	switch rest := "/" + parts[1]; {
+	case method == http.MethodPost && rest == "/tabs/open":
+		return "/tab", true
+	case rest == "/proxy/screencast":
+		return "/screencast", true
+	case rest == "/tab", rest == "/cookies", rest == "/audit", rest == "/scrape", rest == "/screencast":
+		return rest, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func TestAuthMiddleware_APITokenScope(t *testing.T) {
+	store := apitoken.NewStore("")
+	_, browse, err := store.Create("browse", apitoken.Scope{
+		Families:       []string{"browse", "cookies"},
+		AllowedDomains: []string{"example.com"},
+	}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ops, err := store.Create("ops", apitoken.Scope{
+		Families:     []string{"instances", "evaluate"},
+		Capabilities: []string{"evaluate"},
+	}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		want   int
+		code   string
+	}{
+		{"family route", browse, http.MethodPost, "/navigate", http.StatusOK, ""},
+		{"tab family route", browse, http.MethodGet, "/tabs/T1/text", http.StatusOK, ""},
+		{"route outside families", browse, http.MethodGet, "/network", http.StatusForbidden, "token_scope_forbidden"},
+		{"capability not granted", browse, http.MethodGet, "/cookies", http.StatusForbidden, "token_capability_forbidden"},
+		{"admin route", ops, http.MethodGet, "/api/config", http.StatusForbidden, "token_scope_forbidden"},
+		{"token management", ops, http.MethodGet, "/api/tokens", http.StatusForbidden, "token_scope_forbidden"},
+		{"instances family", ops, http.MethodGet, "/instances", http.StatusOK, ""},
+		{"instances family without it", browse, http.MethodGet, "/instances", http.StatusForbidden, "token_scope_forbidden"},
+		{"proxied route needs its family", ops, http.MethodPost, "/instances/inst_1/tabs/open", http.StatusForbidden, "token_scope_forbidden"},
+		{"granted capability", ops, http.MethodPost, "/tabs/T1/evaluate", http.StatusOK, ""},
+		{"unknown token", "ptk_nope", http.MethodGet, "/text", http.StatusUnauthorized, "bad_token"},
+	}
+
+	cfg := &config.RuntimeConfig{Token: "server-token"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AuthMiddlewareWithTokens(cfg, nil, nil, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.code != "" && !strings.Contains(rr.Body.String(), tt.code) {
+				t.Fatalf("body = %s, want code %s", rr.Body.String(), tt.code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_APITokenAttachesTokenAndAttribution(t *testing.T) {
+	store := apitoken.NewStore("")
+	tok, plaintext, err := store.Create("agent", apitoken.Scope{
+		Families:       []string{"browse"},
+		AllowedDomains: []string{"example.com", "*.example.org"},
+	}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.RuntimeConfig{Token: "server-token"}
+	var got *apitoken.Token
+	var tokenHeader, domainsHeader string
+	handler := AuthMiddlewareWithTokens(cfg, nil, nil, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = apitoken.FromRequest(r)
+		tokenHeader = r.Header.Get(activity.HeaderPTTokenID)
+		domainsHeader = r.Header.Get(TokenDomainsHeader)
+		if err := tokenDomainError(r, "https://evil.test/"); err == nil {
+			t.Error("a URL outside the token domains should be rejected")
+		}
+		if err := tokenDomainError(r, "https://www.example.org/"); err != nil {
+			t.Errorf("allowed URL rejected: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Authorization", "Bearer "+plaintext)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rr.Code, rr.Body.String())
+	}
+	if got == nil || got.ID != tok.ID {
+		t.Fatalf("token on context = %+v, want %s", got, tok.ID)
+	}
+	if tokenHeader != tok.ID || domainsHeader != "example.com,*.example.org" {
+		t.Fatalf("headers = %q, %q", tokenHeader, domainsHeader)
+	}
+}
+
+func TestAuthMiddleware_RevokedAPITokenRejected(t *testing.T) {
+	store := apitoken.NewStore("")
+	tok, plaintext, err := store.Create("agent", apitoken.Scope{Families: []string{"*"}}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Revoke(tok.ID)
+
+	cfg := &config.RuntimeConfig{Token: "server-token"}
+	handler := AuthMiddlewareWithTokens(cfg, nil, nil, store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called for a revoked token")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Authorization", "Bearer "+plaintext)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rr.Code)
+	}
+}
+
+func TestTokenDomainsFromTrustedHop(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set(TokenDomainsHeader, "example.com")
+	if domains := requestTokenDomains(req); domains != nil {
+		t.Fatalf("untrusted request domains = %v, want none", domains)
+	}
+	req = req.WithContext(MarkTrustedInternalProxy(req.Context()))
+	if err := tokenDomainError(req, "https://other.test/"); err == nil {
+		t.Fatal("trusted hop should carry the token domains")
+	}
+	if err := tokenDomainError(req, "about:blank"); err != nil {
+		t.Fatalf("about:blank rejected: %v", err)
+	}
+}
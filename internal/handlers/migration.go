@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/state"
+)
+
+// migrationTabTimeout bounds capturing or reopening one tab.
+const migrationTabTimeout = 30 * time.Second
+
+// MigrationTab is a tab as the orchestrator moves it from a draining
+// instance to another one: where it was, what it was logged into and which
+// callers had it as their current tab.
+type MigrationTab struct {
+	TabID string `json:"tabId"`
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+	// SessionID is the isolated session whose browser context holds the tab.
+	SessionID string `json:"sessionId,omitempty"`
+	ProxyPool string `json:"proxyPool,omitempty"`
+	ProxyKey  string `json:"proxyKey,omitempty"`
+
+	Cookies []state.Cookie      `json:"cookies,omitempty"`
+	Origin  string              `json:"origin,omitempty"`
+	Storage state.OriginStorage `json:"storage"`
+	// Scopes are the current-tab scopes pointing at the tab, as
+	// "session:<id>" or "agent:<id>".
+	Scopes []string `json:"scopes,omitempty"`
+	// Lock is the tab's lease when the tab was exported.
+	Lock *MigrationLock `json:"lock,omitempty"`
+	// Error is set when the tab's state could not be read; it then moves
+	// with its URL only.
+	Error string `json:"error,omitempty"`
+}
+
+// MigrationLock is a tab lease carried across a migration. Fencing tokens
+// are per instance, so the reopened tab's lease gets a new LockToken.
+type MigrationLock struct {
+	Owner     string    `json:"owner"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	LockToken uint64    `json:"lockToken,omitempty"`
+}
+
+// MigrationImportResult reports a tab reopened by POST /migration/tabs.
+type MigrationImportResult struct {
+	TabID                string   `json:"tabId"`
+	URL                  string   `json:"url"`
+	CookiesRestored      int      `json:"cookiesRestored"`
+	StorageItemsRestored int      `json:"storageItemsRestored"`
+	Scopes               []string `json:"scopes,omitempty"`
+	NavigateError        string   `json:"navigateError,omitempty"`
+	// Lock is the lease re-acquired on the new tab for the same owner;
+	// LockError says why it could not be.
+	Lock      *MigrationLock `json:"lock,omitempty"`
+	LockError string         `json:"lockError,omitempty"`
+}
+
+// requireInternalProxy rejects requests that did not come from the
+// orchestrator. Migration payloads carry cookies, so they never go public.
+func requireInternalProxy(w http.ResponseWriter, r *http.Request) bool {
+	if IsTrustedInternalProxy(r) {
+		return true
+	}
+	httpx.ErrorCode(w, http.StatusForbidden, "internal_only", "this endpoint is reserved for the orchestrator", false, nil)
+	return false
+}
+
+// HandleMigrationExport lists every tab with what it takes to reopen it on
+// another instance. The orchestrator calls it while draining the instance.
+//
+// @Endpoint GET /migration/tabs
+func (h *Handlers) HandleMigrationExport(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalProxy(w, r) {
+		return
+	}
+	if !h.ensureBrowserOrRespond(w, h.Config) {
+		return
+	}
+	targets, err := h.Bridge.ListTargets()
+	if err != nil {
+		httpx.Error(w, 503, err)
+		return
+	}
+
+	proxies := map[string]bridge.TabProxy{}
+	for _, p := range h.Bridge.TabProxies() {
+		proxies[p.TabID] = p
+	}
+	sessions := map[string]string{}
+	for _, sc := range h.Bridge.SessionContexts() {
+		for _, tabID := range sc.Tabs {
+			sessions[tabID] = sc.SessionID
+		}
+	}
+
+	tabs := make([]MigrationTab, 0, len(targets))
+	for _, t := range targets {
+		if t.Type != "page" || bridge.IsTransientURL(t.URL, h.Config.Port) {
+			continue
+		}
+		tab := MigrationTab{
+			TabID:     t.TargetID,
+			URL:       t.URL,
+			Title:     t.Title,
+			SessionID: sessions[t.TargetID],
+			Scopes:    h.CurrentTabs.ScopesForTab(t.TargetID),
+		}
+		if p, ok := proxies[t.TargetID]; ok {
+			tab.ProxyPool, tab.ProxyKey = p.Pool, p.Key
+		}
+		if lock := h.Bridge.TabLockInfo(t.TargetID); lock != nil {
+			tab.Lock = &MigrationLock{Owner: lock.Owner, ExpiresAt: lock.ExpiresAt}
+		}
+		if err := h.captureMigrationState(r.Context(), &tab); err != nil {
+			tab.Error = err.Error()
+		}
+		tabs = append(tabs, tab)
+	}
+	httpx.JSON(w, 200, map[string]any{"tabs": tabs})
+}
+
+// captureMigrationState reads the cookies and origin storage of tab. Site
+// data (IndexedDB, Cache Storage) stays behind: it can be large and
+// migration has to be quick.
+func (h *Handlers) captureMigrationState(ctx context.Context, tab *MigrationTab) error {
+	tabCtx, _, err := h.Bridge.TabContext(tab.TabID)
+	if err != nil {
+		return err
+	}
+	tCtx, cancel := context.WithTimeout(tabCtx, migrationTabTimeout)
+	defer cancel()
+	go httpx.CancelOnClientDone(ctx, cancel)
+
+	cookies, err := h.Bridge.GetRawCookies(tCtx)
+	if err != nil {
+		return fmt.Errorf("get cookies: %w", err)
+	}
+	tab.Cookies = stateCookiesFromRaw(cookies)
+	storage, err := h.readTabStorage(tCtx)
+	if err != nil {
+		return err
+	}
+	if storage.Origin != "" && storage.Origin != "null" {
+		tab.Origin = storage.Origin
+		tab.Storage = state.OriginStorage{Local: storage.Local, Session: storage.Session}
+	}
+	return nil
+}
+
+// HandleMigrationImport reopens a tab exported by another instance: in the
+// same session context or proxy pool, with its cookies set before the page
+// loads, then its storage and current-tab scopes restored.
+//
+// @Endpoint POST /migration/tabs
+func (h *Handlers) HandleMigrationImport(w http.ResponseWriter, r *http.Request) {
+	if !requireInternalProxy(w, r) {
+		return
+	}
+	var tab MigrationTab
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&tab); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if !h.enforceURLDomainPolicy(w, r, tab.URL) {
+		return
+	}
+	if !h.ensureBrowserOrRespond(w, h.Config) {
+		return
+	}
+
+	newTabID, tabCtx, _, err := h.createMigratedTab(tab)
+	if err != nil {
+		writeCreateTabError(w, "create tab", err)
+		return
+	}
+	tCtx, cancel := context.WithTimeout(tabCtx, migrationTabTimeout)
+	defer cancel()
+
+	res := MigrationImportResult{TabID: newTabID, URL: tab.URL}
+	res.CookiesRestored = h.restoreCookies(tCtx, tab.Cookies)
+	if tab.URL != "" {
+		if _, err := h.Bridge.Navigate(tCtx, tab.URL, bridge.NavigateParams{}); err != nil {
+			res.NavigateError = err.Error()
+		}
+	}
+	if res.NavigateError == "" && len(tab.Storage.Local)+len(tab.Storage.Session) > 0 {
+		if origin, err := h.pageOrigin(tCtx); err == nil && origin == tab.Origin {
+			if n, err := h.restoreOriginStorage(tCtx, tab.Storage); err == nil && n > 0 {
+				res.StorageItemsRestored = n
+				// Pages read storage as they load; reload so this one sees it.
+				_, _ = h.Bridge.Navigate(tCtx, tab.URL, bridge.NavigateParams{})
+			}
+		}
+	}
+	for _, key := range tab.Scopes {
+		if scope, ok := currentTabScopeFromKey(key); ok {
+			h.CurrentTabs.Set(scope, newTabID)
+			res.Scopes = append(res.Scopes, key)
+		}
+	}
+	if tab.Lock != nil {
+		res.Lock, res.LockError = h.restoreMigrationLock(newTabID, *tab.Lock)
+	}
+
+	h.recordActivity(r, activity.Update{Action: "tab.migrate", TabID: newTabID, URL: tab.URL})
+	httpx.JSON(w, 200, res)
+}
+
+// restoreMigrationLock leases the reopened tab to the owner of the old
+// one for the rest of its lease, so its actions stay fenced.
+func (h *Handlers) restoreMigrationLock(tabID string, lock MigrationLock) (*MigrationLock, string) {
+	ttl := time.Until(lock.ExpiresAt)
+	if ttl <= 0 {
+		return nil, "lease expired during migration"
+	}
+	if err := h.Bridge.Lock(tabID, lock.Owner, ttl); err != nil {
+		return nil, err.Error()
+	}
+	info := h.Bridge.TabLockInfo(tabID)
+	if info == nil {
+		return nil, "lease lost after migration"
+	}
+	return &MigrationLock{Owner: info.Owner, ExpiresAt: info.ExpiresAt, LockToken: info.Token}, ""
+}
+
+// createMigratedTab opens a blank tab where tab lived: behind the same
+// proxy pool, in the same isolated session, or in the default context.
+func (h *Handlers) createMigratedTab(tab MigrationTab) (string, context.Context, context.CancelFunc, error) {
+	if tab.ProxyPool != "" {
+		return h.Bridge.CreateTabWithProxy("", bridge.TabProxyOptions{Pool: tab.ProxyPool, Key: tab.ProxyKey})
+	}
+	if tab.SessionID != "" {
+		return h.Bridge.CreateTabInSession("", tab.SessionID)
+	}
+	return h.Bridge.CreateTab("")
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/state"
+)
+
+type migrationBridge struct {
+	*mockBridge
+	targets []bridge.TabTarget
+}
+
+func (m *migrationBridge) ListTargets() ([]bridge.TabTarget, error) { return m.targets, nil }
+
+func trustedRequest(method, target string, body []byte) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	return req.WithContext(MarkTrustedInternalProxy(req.Context()))
+}
+
+type migrationLockBridge struct {
+	*mockBridge
+	locks map[string]*bridge.LockInfo
+}
+
+func (m *migrationLockBridge) Lock(tabID, owner string, ttl time.Duration) error {
+	m.locks[tabID] = &bridge.LockInfo{Owner: owner, ExpiresAt: time.Now().Add(ttl), Token: 7}
+	return nil
+}
+
+func (m *migrationLockBridge) TabLockInfo(tabID string) *bridge.LockInfo { return m.locks[tabID] }
+
+func TestMigrationEndpointsRejectPublicRequests(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleMigrationExport(w, httptest.NewRequest("GET", "/migration/tabs", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("export status = %d, want 403", w.Code)
+	}
+	w = httptest.NewRecorder()
+	h.HandleMigrationImport(w, httptest.NewRequest("POST", "/migration/tabs", strings.NewReader(`{"url":"https://example.com"}`)))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("import status = %d, want 403", w.Code)
+	}
+}
+
+func TestHandleMigrationExport(t *testing.T) {
+	m := &migrationBridge{
+		mockBridge: &mockBridge{
+			evaluateFn: func(_ string, result any) error {
+				*result.(*string) = `{"local":{"k":"v"},"session":{},"url":"https://example.com/a","origin":"https://example.com"}`
+				return nil
+			},
+		},
+		targets: []bridge.TabTarget{
+			{TargetID: "tab1", URL: "https://example.com/a", Title: "A", Type: "page"},
+			{TargetID: "blank", URL: "about:blank", Type: "page"},
+			{TargetID: "sw", URL: "https://example.com/sw.js", Type: "service_worker"},
+		},
+	}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+	h.CurrentTabs.Set(scopedCurrentTab(currentTabScopeAgent, "agent-1"), "tab1")
+	h.CurrentTabs.Set(scopedCurrentTab(currentTabScopeSession, "ses_1"), "tab1")
+	h.CurrentTabs.Set(scopedCurrentTab(currentTabScopeAgent, "agent-2"), "other")
+
+	w := httptest.NewRecorder()
+	h.HandleMigrationExport(w, trustedRequest("GET", "/migration/tabs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Tabs []MigrationTab `json:"tabs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Tabs) != 1 {
+		t.Fatalf("tabs = %+v, want only tab1", resp.Tabs)
+	}
+	tab := resp.Tabs[0]
+	if tab.TabID != "tab1" || tab.URL != "https://example.com/a" || tab.Origin != "https://example.com" {
+		t.Fatalf("tab = %+v", tab)
+	}
+	if tab.Storage.Local["k"] != "v" {
+		t.Fatalf("storage = %+v", tab.Storage)
+	}
+	if strings.Join(tab.Scopes, ",") != "agent:agent-1,session:ses_1" {
+		t.Fatalf("scopes = %v", tab.Scopes)
+	}
+}
+
+func TestHandleMigrationImport(t *testing.T) {
+	m := &mockBridge{
+		navigateResult: &bridge.NavigateResult{URL: "https://example.com/a"},
+		evaluateFn: func(expr string, result any) error {
+			switch r := result.(type) {
+			case *string:
+				*r = "https://example.com"
+			case *int:
+				*r = 1
+			}
+			return nil
+		},
+	}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	body, _ := json.Marshal(MigrationTab{
+		TabID:     "old",
+		URL:       "https://example.com/a",
+		SessionID: "ses_iso",
+		Cookies:   []state.Cookie{{Name: "sid", Value: "1", Domain: "example.com", Path: "/"}},
+		Origin:    "https://example.com",
+		Storage:   state.OriginStorage{Local: map[string]string{"k": "v"}},
+		Scopes:    []string{"agent:agent-1", "bogus", "global:x"},
+	})
+	w := httptest.NewRecorder()
+	h.HandleMigrationImport(w, trustedRequest("POST", "/migration/tabs", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var res MigrationImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.TabID != "tab_abc12345" || res.CookiesRestored != 1 || res.StorageItemsRestored != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if len(m.sessionTabs) != 1 || m.sessionTabs[0] != "ses_iso" {
+		t.Fatalf("CreateTabInSession calls = %v, want [ses_iso]", m.sessionTabs)
+	}
+	// Navigate once, then reload after the storage restore.
+	if len(m.navigateParams) != 2 {
+		t.Fatalf("navigations = %d, want 2", len(m.navigateParams))
+	}
+	if got, ok := h.CurrentTabs.Get(scopedCurrentTab(currentTabScopeAgent, "agent-1")); !ok || got != "tab_abc12345" {
+		t.Fatalf("agent current tab = %q, %v", got, ok)
+	}
+	if len(res.Scopes) != 1 {
+		t.Fatalf("scopes = %v, want only the agent scope", res.Scopes)
+	}
+}
+
+func TestHandleMigrationImportRestoresLock(t *testing.T) {
+	m := &migrationLockBridge{mockBridge: &mockBridge{}, locks: map[string]*bridge.LockInfo{}}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	body, _ := json.Marshal(MigrationTab{
+		TabID: "old",
+		Lock:  &MigrationLock{Owner: "agent-1", ExpiresAt: time.Now().Add(time.Minute)},
+	})
+	w := httptest.NewRecorder()
+	h.HandleMigrationImport(w, trustedRequest("POST", "/migration/tabs", body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var res MigrationImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Lock == nil || res.Lock.Owner != "agent-1" || res.Lock.LockToken != 7 || res.LockError != "" {
+		t.Fatalf("lock = %+v, error = %q", res.Lock, res.LockError)
+	}
+	if lock := m.locks[res.TabID]; lock == nil || lock.Owner != "agent-1" {
+		t.Fatalf("new tab lock = %+v", lock)
+	}
+
+	body, _ = json.Marshal(MigrationTab{
+		TabID: "old2",
+		Lock:  &MigrationLock{Owner: "agent-1", ExpiresAt: time.Now().Add(-time.Second)},
+	})
+	w = httptest.NewRecorder()
+	h.HandleMigrationImport(w, trustedRequest("POST", "/migration/tabs", body))
+	var expired MigrationImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &expired); err != nil {
+		t.Fatal(err)
+	}
+	if expired.Lock != nil || expired.LockError == "" {
+		t.Fatalf("expired lease: lock = %+v, error = %q", expired.Lock, expired.LockError)
+	}
+}
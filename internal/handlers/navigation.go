@@ -72,6 +72,9 @@ type navigateRequest struct {
 	WaitSelector   string  `json:"waitSelector"`
 	DismissBanners bool    `json:"dismissBanners"`
 	Browser        string  `json:"browser,omitempty"`
+	// ProxyPool opens the new tab behind a proxy from that pool.
+	ProxyPool string `json:"proxyPool,omitempty"`
+	ProxyKey  string `json:"proxyKey,omitempty"`
 }
 
 // decodeNavigateRequest reads the navigate input from the query string (GET) or
@@ -88,6 +91,8 @@ func decodeNavigateRequest(w http.ResponseWriter, r *http.Request) (navigateRequ
 		req.WaitSelector = q.Get("waitSelector")
 		d.Bool("dismissBanners", &req.DismissBanners)
 		req.Browser = q.Get("browser")
+		req.ProxyPool = q.Get("proxyPool")
+		req.ProxyKey = q.Get("proxyKey")
 		d.Float("waitTitle", &req.WaitTitle)
 		d.Float("timeout", &req.Timeout)
 		if err := d.Err(); err != nil {
@@ -117,6 +122,19 @@ type navTargets struct {
 func (h *Handlers) navigateToURL(w http.ResponseWriter, r *http.Request, req navigateRequest) {
 	tabID := strings.TrimSpace(req.TabID)
 
+	// A pool proxy belongs to a browser context, so only a new tab can get one.
+	if strings.TrimSpace(req.ProxyPool) != "" {
+		if tabID != "" {
+			httpx.Error(w, 400, fmt.Errorf("proxyPool applies to new tabs only; omit tabId"))
+			return
+		}
+		if isolatedSessionID(r) != "" {
+			writeCreateTabError(w, "", errProxyPoolInIsolatedSession)
+			return
+		}
+		req.NewTab = true
+	}
+
 	routing, ok := h.resolveNavigateBrowser(w, r, tabID, strings.TrimSpace(req.Browser))
 	if !ok {
 		return
@@ -220,6 +238,11 @@ func (h *Handlers) validateNavigateTargets(w http.ResponseWriter, r *http.Reques
 		return navTargets{}, false
 	}
 
+	if !enforceTokenDomains(w, r, url) {
+		h.recordNavigateRequest(r, tabID, url)
+		return navTargets{}, false
+	}
+
 	domainResult := h.IDPIGuard.CheckDomain(url)
 	if domainResult.Blocked {
 		h.recordNavigateRequest(r, tabID, url)
@@ -317,7 +340,8 @@ type staticFirstOutcome struct {
 // to rescue it.
 func (h *Handlers) tryStaticFirstNavigate(w http.ResponseWriter, r *http.Request, req navigateRequest, effectiveCfg *config.RuntimeConfig, navRoute *browserops.RouteMetadata) staticFirstOutcome {
 	sf, ok := h.Bridge.(staticFirstNavigator)
-	if !ok || !sf.StaticFirstNavigate() || !req.NewTab {
+	// The static fetch would not go through the pool proxy.
+	if !ok || !sf.StaticFirstNavigate() || !req.NewTab || req.ProxyPool != "" {
 		return staticFirstOutcome{}
 	}
 
@@ -419,6 +443,7 @@ func (h *Handlers) executeNavigate(w http.ResponseWriter, r *http.Request, req n
 			DismissBanners: req.DismissBanners,
 			Route:          navRoute,
 			MaxRedirects:   effectiveCfg.MaxRedirects,
+			Proxy:          tabProxyOptions(r, req.ProxyPool, req.ProxyKey),
 		}
 	}
 
@@ -562,7 +587,7 @@ func (h *Handlers) runNavigate(w http.ResponseWriter, r *http.Request, ex navExe
 		return
 	}
 
-	h.maybeAutoSolve(ex.ctx, ex.tabID, autoSolverTriggerNavigate)
+	h.maybeAutoSolve(r, ex.tabID, autoSolverTriggerNavigate)
 	h.dismissBanners(ex.ctx, ex.tabID, ex.dismissBanners)
 
 	navURL, _ := h.Bridge.CurrentURL(ex.ctx)
@@ -615,6 +640,7 @@ type navigateBrowserOptions struct {
 	// SkipStatic: the handler already ran (and failed) the static-first
 	// phase; the bridge must go straight to Chrome.
 	SkipStatic bool
+	Proxy      bridge.TabProxyOptions
 }
 
 // staticFirstNavigator is probed on the bridge to enable the deferred-launch
@@ -631,9 +657,9 @@ const newTabNavCeiling = 30 * time.Second
 func (h *Handlers) navigateNewTabBrowser(w http.ResponseWriter, r *http.Request, opts navigateBrowserOptions) {
 	// Create a blank tab first so the requested URL becomes the first
 	// real history entry.
-	newTabID, newCtx, _, err := h.Bridge.CreateTab("")
+	newTabID, newCtx, _, err := h.createTab(r, opts.Proxy)
 	if err != nil {
-		httpx.Error(w, 500, fmt.Errorf("new tab: %w", err))
+		writeCreateTabError(w, "new tab", err)
 		return
 	}
 
@@ -7,10 +7,19 @@ import (
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
-func (h *Handlers) waitForNavigationState(ctx context.Context, waitFor, waitSelector string) error {
+func (h *Handlers) waitForNavigationState(ctx context.Context, waitFor, waitSelector string) (err error) {
 	waitMode := strings.ToLower(strings.TrimSpace(waitFor))
+	if waitMode != "" && waitMode != "none" {
+		var span *tracing.Span
+		ctx, span = tracing.Start(ctx, "navigation.wait", tracing.String("pinchtab.wait_for", waitMode))
+		defer func() {
+			span.RecordError(err)
+			span.End()
+		}()
+	}
 	switch waitMode {
 	case "", "none":
 		return nil
@@ -278,6 +278,8 @@ func (h *Handlers) HandleNetwork(w http.ResponseWriter, r *http.Request) {
 // @Param tabId string query Tab ID (optional)
 // @Param body bool query Include response body (optional, default: false)
 //
+// WebSocket entries also carry their retained frames, oldest first.
+//
 // @Response 200 application/json Network entry details
 // @Response 404 application/json Request not found
 func (h *Handlers) HandleNetworkByID(w http.ResponseWriter, r *http.Request) {
@@ -318,6 +320,9 @@ func (h *Handlers) HandleNetworkByID(w http.ResponseWriter, r *http.Request) {
 		"entry": entry,
 		"tabId": resolvedTabID,
 	}
+	if observe.IsWebSocket(entry) {
+		result["frames"] = buf.WebSocketFrames(requestID)
+	}
 
 	if r.URL.Query().Get("body") == "true" && entry.Finished && !entry.Failed {
 		bodyMode := parseNetworkBodyMode(r)
@@ -480,6 +485,139 @@ func (h *Handlers) HandleNetworkStream(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleNetworkFrames streams the frames of a WebSocket connection via
+// Server-Sent Events. Retained frames are replayed first unless replay=false;
+// the stream ends with a close event once the connection closes.
+//
+// @Endpoint GET /network/{requestId}/frames
+// @Description Streams WebSocket frames sent and received on a captured connection
+//
+// @Param requestId string path Request ID of the WebSocket (required)
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param replay bool query Replay retained frames before live ones (optional, default: true)
+//
+// @Response 200 text/event-stream SSE stream of frame events, then a close event
+// @Response 400 application/json Request is not a WebSocket
+// @Response 404 application/json Request not found
+func (h *Handlers) HandleNetworkFrames(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpx.Problem(w, http.StatusInternalServerError, "streaming_not_supported", "streaming not supported", false, nil)
+		return
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	if !h.ensureBrowserReady(w) {
+		return
+	}
+
+	requestID := r.PathValue("requestId")
+	if requestID == "" {
+		httpx.Error(w, 400, fmt.Errorf("requestId required"))
+		return
+	}
+
+	_, resolvedTabID, ok := h.resolveNetworkTab(w, r)
+	if !ok {
+		return
+	}
+
+	nm := h.Bridge.NetworkMonitor()
+	if nm == nil {
+		httpx.Error(w, 404, fmt.Errorf("network monitoring not active"))
+		return
+	}
+	buf := nm.GetBuffer(resolvedTabID)
+	if buf == nil {
+		httpx.Error(w, 404, fmt.Errorf("no network data for tab %s", resolvedTabID))
+		return
+	}
+
+	// Subscribe before reading the retained frames so nothing slips between
+	// the replay and the live feed; sequence numbers drop the overlap.
+	subID, ch := buf.SubscribeFrames(requestID)
+	defer buf.UnsubscribeFrames(requestID, subID)
+
+	entry, found := buf.Get(requestID)
+	if !found {
+		httpx.Error(w, 404, fmt.Errorf("request %s not found", requestID))
+		return
+	}
+	if !observe.IsWebSocket(entry) {
+		httpx.ErrorCode(w, 400, "not_websocket", fmt.Sprintf("request %s is not a WebSocket", requestID), false, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher.Flush()
+
+	var lastSeq int64
+	writeFrame := func(frame bridge.WebSocketFrame) bool {
+		if frame.Seq <= lastSeq {
+			return true
+		}
+		lastSeq = frame.Seq
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "event: frame\nid: %d\ndata: %s\n\n", frame.Seq, data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	writeClose := func() {
+		if latest, ok := buf.Get(requestID); ok {
+			entry = latest
+		}
+		data, _ := json.Marshal(entry)
+		_, _ = fmt.Fprintf(w, "event: close\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	if r.URL.Query().Get("replay") != "false" {
+		for _, frame := range buf.WebSocketFrames(requestID) {
+			if !writeFrame(frame) {
+				return
+			}
+		}
+	}
+	if entry.Finished {
+		writeClose()
+		return
+	}
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case frame, ok := <-ch:
+			if !ok {
+				writeClose()
+				return
+			}
+			if !writeFrame(frame) {
+				return
+			}
+
+		case <-keepalive.C:
+			if _, err := fmt.Fprintf(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // HandleTabNetwork lists network entries for a tab identified by path ID.
 //
 // @Endpoint GET /tabs/{id}/network
@@ -506,6 +644,13 @@ func (h *Handlers) HandleTabNetworkByID(w http.ResponseWriter, r *http.Request)
 	h.HandleNetworkByID(w, req)
 }
 
+// HandleTabNetworkFrames streams WebSocket frames for a request in a tab.
+//
+// @Endpoint GET /tabs/{id}/network/{requestId}/frames
+func (h *Handlers) HandleTabNetworkFrames(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleNetworkFrames)
+}
+
 // HandleTabNetworkStream streams network entries for a tab identified by path ID.
 //
 // @Endpoint GET /tabs/{id}/network/stream
@@ -123,7 +123,7 @@ func (h *Handlers) HandleNetworkExport(w http.ResponseWriter, r *http.Request) {
 	// buffering the whole []observe.ExportEntry before encoding.
 	if output == "file" {
 		if err := h.writeExportFile(w, r, enc, ec.formatName, func(emit func(observe.ExportEntry) error) error {
-			return h.streamExportEntries(fetchCtx, nm, entries, includeBody, redactHeaders, emit)
+			return h.streamExportEntries(fetchCtx, nm, buf, entries, includeBody, redactHeaders, emit)
 		}); err != nil {
 			httpx.Error(w, 500, fmt.Errorf("write file: %w", err))
 		}
@@ -134,7 +134,7 @@ func (h *Handlers) HandleNetworkExport(w http.ResponseWriter, r *http.Request) {
 	if err := enc.Start(w); err != nil {
 		return
 	}
-	if err := h.streamExportEntries(fetchCtx, nm, entries, includeBody, redactHeaders, enc.Encode); err != nil {
+	if err := h.streamExportEntries(fetchCtx, nm, buf, entries, includeBody, redactHeaders, enc.Encode); err != nil {
 		return
 	}
 	_ = enc.Finish()
@@ -161,8 +161,9 @@ func (h *Handlers) resolveExportContext(w http.ResponseWriter, r *http.Request,
 	}
 
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, http.StatusLocked, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return exportContext{}, false
 	}
 
@@ -206,6 +207,7 @@ func resolveExportBody(ctx context.Context, nm *bridge.NetworkMonitor, entry bri
 func (h *Handlers) streamExportEntries(
 	ctx context.Context,
 	nm *bridge.NetworkMonitor,
+	buf *bridge.NetworkBuffer,
 	entries []bridge.NetworkEntry,
 	includeBody, redactHeaders bool,
 	emit func(observe.ExportEntry) error,
@@ -231,7 +233,7 @@ func (h *Handlers) streamExportEntries(
 				if includeBody && ent.Finished && !ent.Failed {
 					body, b64 = resolveExportBody(ctx, nm, ent)
 				}
-				results[idx] <- toExportEntry(ent, body, b64, redactHeaders)
+				results[idx] <- toExportEntry(ent, exportFrames(buf, ent), body, b64, redactHeaders)
 			}(i, entry)
 		}
 		wg.Wait()
@@ -288,10 +290,21 @@ func clampExportBody(body string, b64 bool) (string, bool) {
 	return body, b64
 }
 
-// toExportEntry converts a captured entry to an export entry, redacting
-// sensitive request/response headers when redactHeaders is set.
-func toExportEntry(entry bridge.NetworkEntry, body string, b64, redactHeaders bool) observe.ExportEntry {
+// exportFrames returns the retained frames of a WebSocket entry, or nil for
+// any other entry.
+func exportFrames(buf *bridge.NetworkBuffer, entry bridge.NetworkEntry) []bridge.WebSocketFrame {
+	if !observe.IsWebSocket(entry) {
+		return nil
+	}
+	return buf.WebSocketFrames(entry.RequestID)
+}
+
+// toExportEntry converts a captured entry and its WebSocket frames to an
+// export entry, redacting sensitive request/response headers when
+// redactHeaders is set.
+func toExportEntry(entry bridge.NetworkEntry, frames []bridge.WebSocketFrame, body string, b64, redactHeaders bool) observe.ExportEntry {
 	e := observe.NetworkEntryToExport(entry, body, b64)
+	e.WebSocketMessages = observe.WebSocketFramesToExport(frames)
 	if redactHeaders {
 		e.Request.Headers = observe.RedactSensitiveHeaders(e.Request.Headers)
 		e.Response.Headers = observe.RedactSensitiveHeaders(e.Response.Headers)
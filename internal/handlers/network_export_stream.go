@@ -268,7 +268,7 @@ func (s *exportStreamSession) exportEntry(entry bridge.NetworkEntry) (stop bool)
 		body, b64 = resolveExportBody(s.tabCtx, s.nm, entry)
 		<-s.bodySem
 	}
-	export := toExportEntry(entry, body, b64, s.redactHeaders)
+	export := toExportEntry(entry, exportFrames(s.buf, entry), body, b64, s.redactHeaders)
 	if err := s.enc.Encode(export); err != nil {
 		s.finalize()
 		return true
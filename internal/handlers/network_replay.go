@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/bridge/observe"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// maxReplayArchiveBytes caps the HAR file a replay may load. Archives are
+// parsed whole into memory, bodies included.
+const maxReplayArchiveBytes = 256 << 20
+
+type networkReplayRequest struct {
+	Path     string `json:"path"`
+	Match    string `json:"match,omitempty"`
+	NotFound string `json:"notFound,omitempty"`
+}
+
+// HandleNetworkReplay starts serving the active or query-specified tab from a
+// HAR archive in the state dir.
+//
+// @Endpoint POST /network/replay
+// @Description Replay a recorded HAR archive on a tab
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+// @Param body  object body  {path, match?: strict|url|path, notFound?: abort|passthrough|404}
+//
+// @Response 200 application/json {ok, tabId, replay}
+// @Response 400 application/json Invalid archive or options
+// @Response 404 application/json Tab or archive not found
+func (h *Handlers) HandleNetworkReplay(w http.ResponseWriter, r *http.Request) {
+	h.handleNetworkReplayFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleNetworkReplayStop stops replay on a tab.
+//
+// @Endpoint DELETE /network/replay
+// @Description Stop HAR replay on a tab
+//
+// @Response 200 application/json {ok, tabId, stopped, replay}
+func (h *Handlers) HandleNetworkReplayStop(w http.ResponseWriter, r *http.Request) {
+	h.handleNetworkReplayStopFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleNetworkReplayStatus reports the tab's active replay.
+//
+// @Endpoint GET /network/replay
+// @Description Show HAR replay status for a tab
+//
+// @Response 200 application/json {tabId, active, replay}
+func (h *Handlers) HandleNetworkReplayStatus(w http.ResponseWriter, r *http.Request) {
+	h.handleNetworkReplayStatusFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleTabNetworkReplay is the path-scoped wrapper for HandleNetworkReplay.
+//
+// @Endpoint POST /tabs/{id}/network/replay
+func (h *Handlers) HandleTabNetworkReplay(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handleNetworkReplayFor(w, r, tabID)
+}
+
+// HandleTabNetworkReplayStop is the path-scoped wrapper for HandleNetworkReplayStop.
+//
+// @Endpoint DELETE /tabs/{id}/network/replay
+func (h *Handlers) HandleTabNetworkReplayStop(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handleNetworkReplayStopFor(w, r, tabID)
+}
+
+// HandleTabNetworkReplayStatus is the path-scoped wrapper for HandleNetworkReplayStatus.
+//
+// @Endpoint GET /tabs/{id}/network/replay
+func (h *Handlers) HandleTabNetworkReplayStatus(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handleNetworkReplayStatusFor(w, r, tabID)
+}
+
+func (h *Handlers) handleNetworkReplayFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	tabCtx, resolvedID, ok := h.requireRouteContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, tabCtx, resolvedID); !ok {
+		return
+	}
+
+	var req networkReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode body: %w", err))
+		return
+	}
+	if req.Path == "" {
+		httpx.Error(w, 400, fmt.Errorf("path required"))
+		return
+	}
+
+	entries, status, err := h.loadReplayArchive(req.Path)
+	if err != nil {
+		httpx.Error(w, status, err)
+		return
+	}
+	replay, err := bridge.NewHARReplay(entries, bridge.ReplayOptions{
+		Match:    bridge.ReplayMatch(req.Match),
+		NotFound: bridge.ReplayNotFound(req.NotFound),
+		Source:   req.Path,
+	})
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	if err := h.Bridge.StartNetworkReplay(resolvedID, replay); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"ok":     true,
+		"tabId":  resolvedID,
+		"replay": replay.Status(),
+	})
+}
+
+// loadReplayArchive reads a HAR file addressed relative to the state dir.
+// status is the HTTP code for err.
+func (h *Handlers) loadReplayArchive(userPath string) ([]observe.ExportEntry, int, error) {
+	// SafeCreatePath vets the path (no escapes, no symlinks) without
+	// requiring it to exist, so a missing archive surfaces from Open as 404.
+	path, err := httpx.SafeCreatePath(h.Config.StateDir, userPath)
+	if err != nil {
+		return nil, 400, fmt.Errorf("invalid path: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 404, fmt.Errorf("archive not found: %s", userPath)
+		}
+		return nil, 500, fmt.Errorf("open archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 500, fmt.Errorf("stat archive: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, 400, fmt.Errorf("archive is not a regular file: %s", userPath)
+	}
+	if info.Size() > maxReplayArchiveBytes {
+		return nil, 400, fmt.Errorf("archive exceeds %d bytes (cap)", maxReplayArchiveBytes)
+	}
+	entries, err := observe.ReadHAR(io.LimitReader(f, maxReplayArchiveBytes))
+	if err != nil {
+		return nil, 400, err
+	}
+	return entries, 200, nil
+}
+
+func (h *Handlers) handleNetworkReplayStopFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	tabCtx, resolvedID, ok := h.requireRouteContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, tabCtx, resolvedID); !ok {
+		return
+	}
+	final, err := h.Bridge.StopNetworkReplay(resolvedID)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"ok":      true,
+		"tabId":   resolvedID,
+		"stopped": final != nil,
+		"replay":  final,
+	})
+}
+
+func (h *Handlers) handleNetworkReplayStatusFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	_, resolvedID, ok := h.requireRouteContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	status, err := h.Bridge.NetworkReplayStatus(resolvedID)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":  resolvedID,
+		"active": status != nil,
+		"replay": status,
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+type replayMockBridge struct {
+	mockBridge
+	replays map[string]*bridge.HARReplay
+}
+
+func (m *replayMockBridge) StartNetworkReplay(tabID string, replay *bridge.HARReplay) error {
+	m.replays[tabID] = replay
+	return nil
+}
+
+func (m *replayMockBridge) StopNetworkReplay(tabID string) (*bridge.ReplayStatus, error) {
+	rp := m.replays[tabID]
+	delete(m.replays, tabID)
+	if rp == nil {
+		return nil, nil
+	}
+	return rp.Status(), nil
+}
+
+func (m *replayMockBridge) NetworkReplayStatus(tabID string) (*bridge.ReplayStatus, error) {
+	if rp := m.replays[tabID]; rp != nil {
+		return rp.Status(), nil
+	}
+	return nil, nil
+}
+
+const testHAR = `{"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[
+{"startedDateTime":"2026-01-01T00:00:00.000Z","time":1,
+ "request":{"method":"GET","url":"https://shop.test/api/cart","httpVersion":"HTTP/1.1","headers":[],"queryString":[],"headersSize":-1,"bodySize":0},
+ "response":{"status":200,"statusText":"OK","httpVersion":"HTTP/1.1","headers":[],"content":{"size":2,"mimeType":"application/json","text":"{}"},"headersSize":-1,"bodySize":2},
+ "timings":{"send":0,"wait":1,"receive":0}}]}}`
+
+func newReplayHandler(t *testing.T) (*Handlers, *replayMockBridge) {
+	t.Helper()
+	stateDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stateDir, "exports"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stateDir, "exports", "shop.har"), []byte(testHAR), 0600); err != nil {
+		t.Fatal(err)
+	}
+	b := &replayMockBridge{replays: map[string]*bridge.HARReplay{}}
+	return New(b, &config.RuntimeConfig{AllowNetworkIntercept: true, StateDir: stateDir}, nil, nil, nil), b
+}
+
+func postReplay(h *Handlers, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/tabs/tab1/network/replay", bytes.NewReader([]byte(body)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabNetworkReplay(w, req)
+	return w
+}
+
+func TestHandleTabNetworkReplay_StartStatusStop(t *testing.T) {
+	h, b := newReplayHandler(t)
+
+	w := postReplay(h, `{"path":"exports/shop.har","match":"url","notFound":"404"}`)
+	if w.Code != 200 {
+		t.Fatalf("start: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var started struct {
+		OK     bool                `json:"ok"`
+		Replay bridge.ReplayStatus `json:"replay"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &started)
+	if !started.OK || started.Replay.Entries != 1 || started.Replay.Match != bridge.ReplayMatchURL ||
+		started.Replay.NotFound != bridge.ReplayNotFound404 || started.Replay.Source != "exports/shop.har" {
+		t.Errorf("unexpected start response: %s", w.Body.String())
+	}
+	if b.replays["tab1"] == nil {
+		t.Fatal("replay not handed to the bridge")
+	}
+
+	req := httptest.NewRequest("GET", "/tabs/tab1/network/replay", nil)
+	req.SetPathValue("id", "tab1")
+	w = httptest.NewRecorder()
+	h.HandleTabNetworkReplayStatus(w, req)
+	if w.Code != 200 || !bytes.Contains(w.Body.Bytes(), []byte(`"active":true`)) {
+		t.Errorf("status: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("DELETE", "/tabs/tab1/network/replay", nil)
+	req.SetPathValue("id", "tab1")
+	w = httptest.NewRecorder()
+	h.HandleTabNetworkReplayStop(w, req)
+	if w.Code != 200 || !bytes.Contains(w.Body.Bytes(), []byte(`"stopped":true`)) {
+		t.Errorf("stop: %d %s", w.Code, w.Body.String())
+	}
+	if b.replays["tab1"] != nil {
+		t.Error("replay still installed after stop")
+	}
+}
+
+func TestHandleTabNetworkReplay_Errors(t *testing.T) {
+	h, b := newReplayHandler(t)
+	if err := os.WriteFile(filepath.Join(h.Config.StateDir, "broken.har"), []byte(`{"log":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		body string
+		code int
+	}{
+		{`{}`, 400},
+		{`{"path":"exports/missing.har"}`, 404},
+		{`{"path":"../outside.har"}`, 400},
+		{`{"path":"/etc/passwd"}`, 400},
+		{`{"path":"broken.har"}`, 400},
+		{`{"path":"exports/shop.har","match":"fuzzy"}`, 400},
+		{`{"path":"exports/shop.har","notFound":"retry"}`, 400},
+	}
+	for _, tc := range cases {
+		if w := postReplay(h, tc.body); w.Code != tc.code {
+			t.Errorf("%s: expected %d, got %d: %s", tc.body, tc.code, w.Code, w.Body.String())
+		}
+	}
+	if len(b.replays) != 0 {
+		t.Errorf("failed requests must not install a replay: %v", b.replays)
+	}
+}
+
+func TestHandleTabNetworkReplay_CapabilityDisabled(t *testing.T) {
+	b := &replayMockBridge{replays: map[string]*bridge.HARReplay{}}
+	h := New(b, &config.RuntimeConfig{StateDir: t.TempDir()}, nil, nil, nil)
+	if w := postReplay(h, `{"path":"x.har"}`); w.Code != 403 {
+		t.Fatalf("expected 403 when capability disabled, got %d", w.Code)
+	}
+}
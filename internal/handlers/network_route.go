@@ -20,6 +20,9 @@ type routeRuleRequest struct {
 	Status       int    `json:"status,omitempty"`
 	ResourceType string `json:"resourceType,omitempty"`
 	Method       string `json:"method,omitempty"`
+
+	Frames      []bridge.RouteFrame `json:"frames,omitempty"`
+	DropPattern string              `json:"dropPattern,omitempty"`
 }
 
 // HandleNetworkRoute installs (or replaces by pattern) an interception rule on
@@ -29,7 +32,7 @@ type routeRuleRequest struct {
 // @Description Install a request interception rule on a tab
 //
 // @Param tabId   string query Tab ID (optional, uses current tab if empty)
-// @Param body    object body  {pattern, action, body?, contentType?, status?, resourceType?}
+// @Param body    object body  {pattern, action, body?, contentType?, status?, resourceType?, method?, frames?, dropPattern?}
 //
 // @Response 200 application/json {ok, rules}
 // @Response 400 application/json Validation error
@@ -170,6 +173,12 @@ func (h *Handlers) handleNetworkRouteFor(w http.ResponseWriter, r *http.Request,
 		Status:       req.Status,
 		ResourceType: req.ResourceType,
 		Method:       req.Method,
+		Frames:       req.Frames,
+		DropPattern:  req.DropPattern,
+	}
+	if err := bridge.ValidateStreamFields(rule); err != nil {
+		httpx.Error(w, 400, err)
+		return
 	}
 	if err := h.Bridge.AddRouteRule(resolvedID, rule); err != nil {
 		httpx.Error(w, 400, err)
@@ -103,6 +103,48 @@ func TestHandleTabNetworkRoute_AddFulfill_DefaultsContentType(t *testing.T) {
 	}
 }
 
+func TestHandleTabNetworkRoute_WebSocketFrames(t *testing.T) {
+	b := newRouteMockBridge()
+	h := newRouteHandler(b)
+
+	body := `{"pattern":"wss://feed.test/*","action":"fulfill","resourceType":"websocket","frames":[{"data":"hello"},{"data":"tick","delayMs":50}]}`
+	req := httptest.NewRequest("POST", "/tabs/tab1/network/route", bytes.NewReader([]byte(body)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabNetworkRoute(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got := b.rules["tab1"][0]
+	if len(got.Frames) != 2 || got.Frames[1].Data != "tick" || got.Frames[1].DelayMs != 50 {
+		t.Errorf("frames not stored as expected: %+v", got)
+	}
+}
+
+func TestHandleTabNetworkRoute_StreamFieldsRejected(t *testing.T) {
+	b := newRouteMockBridge()
+	h := newRouteHandler(b)
+
+	for _, body := range []string{
+		`{"pattern":"api","action":"fulfill","frames":[{"data":"x"}]}`,
+		`{"pattern":"api","dropPattern":"ping"}`,
+		`{"pattern":"feed","action":"fulfill","resourceType":"eventsource","body":"data: x"}`,
+		`{"pattern":"feed","resourceType":"eventsource","frames":[{"data":"x","event":"a\nb"}]}`,
+	} {
+		req := httptest.NewRequest("POST", "/tabs/tab1/network/route", bytes.NewReader([]byte(body)))
+		req.SetPathValue("id", "tab1")
+		w := httptest.NewRecorder()
+		h.HandleTabNetworkRoute(w, req)
+		if w.Code != 400 {
+			t.Errorf("%s: expected 400, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+	if len(b.rules["tab1"]) != 0 {
+		t.Errorf("rejected rules should not have been stored: %+v", b.rules["tab1"])
+	}
+}
+
 func TestHandleTabNetworkRoute_CapabilityDisabled(t *testing.T) {
 	b := newRouteMockBridge()
 	// Default RuntimeConfig has AllowNetworkIntercept=false.
@@ -906,3 +906,85 @@ func TestHandleTabNetworkStream_MissingTabID(t *testing.T) {
 		t.Fatalf("expected 400, got %d", w.Code)
 	}
 }
+
+func seedWebSocket(nm *bridge.NetworkMonitor, tabID string) *bridge.NetworkBuffer {
+	buf := nm.GetOrCreateBufferForTest(tabID)
+	buf.Add(bridge.NetworkEntry{RequestID: "ws1", URL: "wss://example.com/feed", Method: "GET", Status: 101, ResourceType: "WebSocket"})
+	buf.AddWebSocketFrame("ws1", bridge.WebSocketFrame{Type: "send", Opcode: 1, Data: "subscribe"})
+	return buf
+}
+
+func TestHandleNetworkByID_WebSocketIncludesFrames(t *testing.T) {
+	nm := bridge.NewNetworkMonitor(100)
+	seedWebSocket(nm, "tab1")
+	h := newNetworkTestHandler(nm)
+
+	req := httptest.NewRequest("GET", "/network/ws1", nil)
+	req.SetPathValue("requestId", "ws1")
+	w := httptest.NewRecorder()
+	h.HandleNetworkByID(w, req)
+
+	var resp struct {
+		Entry  bridge.NetworkEntry     `json:"entry"`
+		Frames []bridge.WebSocketFrame `json:"frames"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v (%s)", err, w.Body.String())
+	}
+	if resp.Entry.FramesSent != 1 || len(resp.Frames) != 1 || resp.Frames[0].Data != "subscribe" {
+		t.Fatalf("resp = %+v", resp)
+	}
+}
+
+func TestHandleNetworkFrames_ReplaysThenStreamsUntilClose(t *testing.T) {
+	nm := bridge.NewNetworkMonitor(100)
+	buf := seedWebSocket(nm, "tab1")
+	h := newNetworkTestHandler(nm)
+
+	req := httptest.NewRequest("GET", "/network/ws1/frames", nil)
+	req.SetPathValue("requestId", "ws1")
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.HandleNetworkFrames(w, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	buf.AddWebSocketFrame("ws1", bridge.WebSocketFrame{Type: "receive", Opcode: 1, Data: "tick"})
+	time.Sleep(50 * time.Millisecond)
+	buf.MarkWebSocketClosed("ws1")
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not end when the socket closed")
+	}
+
+	body := w.Body.String()
+	replay := strings.Index(body, "id: 1\n")
+	live := strings.Index(body, "id: 2\n")
+	closed := strings.Index(body, "event: close\n")
+	if replay < 0 || live < replay || closed < live {
+		t.Fatalf("unexpected stream:\n%s", body)
+	}
+	if strings.Count(body, "event: frame\n") != 2 || !strings.Contains(body, `"data":"tick"`) {
+		t.Fatalf("unexpected frames:\n%s", body)
+	}
+}
+
+func TestHandleNetworkFrames_RejectsHTTPRequest(t *testing.T) {
+	nm := bridge.NewNetworkMonitor(100)
+	seedBuffer(nm, "tab1")
+	h := newNetworkTestHandler(nm)
+
+	req := httptest.NewRequest("GET", "/network/r1/frames", nil)
+	req.SetPathValue("requestId", "r1")
+	w := httptest.NewRecorder()
+	h.HandleNetworkFrames(w, req)
+
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "not_websocket") {
+		t.Fatalf("expected 400 not_websocket, got %d: %s", w.Code, w.Body.String())
+	}
+}
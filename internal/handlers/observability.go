@@ -58,6 +58,8 @@ func resetObservabilityForTests() {
 	atomic.StoreUint64(&metricRequestLatencyN, 0)
 	atomic.StoreUint64(&metricRateLimited, 0)
 	atomic.StoreUint64(&metricStaleRefRetries, 0)
+	metricHTTPRequests.Reset()
+	metricHTTPDuration.Reset()
 	failureMu.Lock()
 	recentFailures = nil
 	failureMu.Unlock()
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+type permissionsRequest struct {
+	TabID       string            `json:"tabId"`
+	Origin      string            `json:"origin,omitempty"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+// HandlePermissions reports the permission state of a tab's origin.
+//
+// @Endpoint GET /permissions
+// @Description Show permission overrides, live states and prompts for a tab
+//
+// @Param tabId  string query Tab ID (optional, uses current tab if empty)
+// @Param origin string query Origin to report on (default: the tab's origin)
+//
+// @Response 200 application/json {tabId, origin, overrides, defaults, states, requests}
+func (h *Handlers) HandlePermissions(w http.ResponseWriter, r *http.Request) {
+	h.handlePermissionsFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleSetPermissions grants, denies or resets-to-prompt permissions for an
+// origin.
+//
+// @Endpoint POST /permissions
+// @Description Grant or deny browser permissions for an origin
+//
+// @Param body object body {tabId?, origin?, permissions: {name: granted|denied|prompt}}
+//
+// @Response 200 application/json {ok, tabId, origin, overrides}
+// @Response 400 application/json Unknown permission, bad setting or origin
+func (h *Handlers) HandleSetPermissions(w http.ResponseWriter, r *http.Request) {
+	var req permissionsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	h.setPermissions(w, r, req)
+}
+
+// HandleResetPermissions drops permission overrides for an origin.
+//
+// @Endpoint DELETE /permissions
+// @Description Reset permission overrides for an origin
+//
+// @Param tabId  string query Tab ID (optional, uses current tab if empty)
+// @Param origin string query Origin to reset (default: the tab's origin)
+// @Param name   string query Comma-separated permissions to reset (default: all)
+//
+// @Response 200 application/json {ok, tabId, origin, overrides}
+func (h *Handlers) HandleResetPermissions(w http.ResponseWriter, r *http.Request) {
+	h.resetPermissionsFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleTabPermissions is the path-scoped wrapper for HandlePermissions.
+//
+// @Endpoint GET /tabs/{id}/permissions
+func (h *Handlers) HandleTabPermissions(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.handlePermissionsFor(w, r, tabID)
+}
+
+// HandleTabSetPermissions is the path-scoped wrapper for HandleSetPermissions.
+//
+// @Endpoint POST /tabs/{id}/permissions
+func (h *Handlers) HandleTabSetPermissions(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+
+	var req permissionsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	if req.TabID != "" && req.TabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body %q does not match URL path %q", req.TabID, tabID))
+		return
+	}
+	req.TabID = tabID
+	h.setPermissions(w, r, req)
+}
+
+// HandleTabResetPermissions is the path-scoped wrapper for HandleResetPermissions.
+//
+// @Endpoint DELETE /tabs/{id}/permissions
+func (h *Handlers) HandleTabResetPermissions(w http.ResponseWriter, r *http.Request) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return
+	}
+	h.resetPermissionsFor(w, r, tabID)
+}
+
+func (h *Handlers) setPermissions(w http.ResponseWriter, r *http.Request, req permissionsRequest) {
+	if len(req.Permissions) == 0 {
+		httpx.Error(w, 400, fmt.Errorf("permissions required (name → granted, denied, or prompt)"))
+		return
+	}
+	if err := config.ValidatePermissions(req.Permissions); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+
+	ctx, resolvedTabID, origin, ok := h.permissionTarget(w, r, req.TabID, req.Origin)
+	if !ok {
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := h.Bridge.SetPermissions(tCtx, origin, req.Permissions); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("set permissions: %w", err))
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "permissions.set", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{
+		"ok":        true,
+		"tabId":     resolvedTabID,
+		"origin":    origin,
+		"overrides": h.Bridge.PermissionOverrides(ctx, origin),
+	})
+}
+
+func (h *Handlers) resetPermissionsFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	var names []string
+	for _, name := range strings.Split(r.URL.Query().Get("name"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !config.IsKnownPermission(name) {
+			httpx.Error(w, 400, fmt.Errorf("unknown permission %q (must be one of %s)", name, strings.Join(config.PermissionNames, ", ")))
+			return
+		}
+		names = append(names, name)
+	}
+
+	ctx, resolvedTabID, origin, ok := h.permissionTarget(w, r, tabID, r.URL.Query().Get("origin"))
+	if !ok {
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := h.Bridge.ResetPermissions(tCtx, origin, names); err != nil {
+		httpx.Error(w, 500, fmt.Errorf("reset permissions: %w", err))
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "permissions.reset", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{
+		"ok":        true,
+		"tabId":     resolvedTabID,
+		"origin":    origin,
+		"overrides": h.Bridge.PermissionOverrides(ctx, origin),
+	})
+}
+
+func (h *Handlers) handlePermissionsFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	ctx, resolvedTabID, origin, ok := h.permissionTarget(w, r, tabID, r.URL.Query().Get("origin"))
+	if !ok {
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer tCancel()
+
+	resp := map[string]any{
+		"tabId":     resolvedTabID,
+		"origin":    origin,
+		"overrides": h.Bridge.PermissionOverrides(ctx, origin),
+		"defaults":  h.Config.Permissions,
+	}
+	// Live states come from the page, so they describe the tab's own origin
+	// only; both they and the request log are best effort.
+	if current, err := h.Bridge.CurrentURL(tCtx); err == nil {
+		if currentOrigin, err := bridge.NormalizePermissionOrigin(current); err == nil && currentOrigin == origin {
+			if states, err := h.Bridge.QueryPermissions(tCtx, config.PermissionNames); err == nil {
+				resp["states"] = states
+			}
+		}
+	}
+	if requests, err := h.Bridge.PermissionRequests(resolvedTabID); err == nil {
+		resp["requests"] = requests
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// permissionTarget resolves the tab and the origin a permission call applies
+// to: the explicit origin when given, else the tab's current origin.
+func (h *Handlers) permissionTarget(w http.ResponseWriter, r *http.Request, tabID, rawOrigin string) (context.Context, string, string, bool) {
+	if err := h.ensureBrowser(h.Config); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return nil, "", "", false
+		}
+		httpx.Error(w, 500, fmt.Errorf("browser initialization: %w", err))
+		return nil, "", "", false
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		WriteTabContextError(w, err, 404)
+		return nil, "", "", false
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return nil, "", "", false
+	}
+
+	if rawOrigin == "" {
+		lookupCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		current, err := h.Bridge.CurrentURL(lookupCtx)
+		cancel()
+		if err != nil {
+			httpx.Error(w, 500, fmt.Errorf("resolve current tab url: %w", err))
+			return nil, "", "", false
+		}
+		origin, err := bridge.NormalizePermissionOrigin(current)
+		if err != nil {
+			httpx.Error(w, 400, fmt.Errorf("tab is not on an http(s) page; pass origin explicitly"))
+			return nil, "", "", false
+		}
+		return ctx, resolvedTabID, origin, true
+	}
+
+	origin, err := bridge.NormalizePermissionOrigin(rawOrigin)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return nil, "", "", false
+	}
+	if !h.enforceURLDomainPolicy(w, r, origin) {
+		return nil, "", "", false
+	}
+	return ctx, resolvedTabID, origin, true
+}
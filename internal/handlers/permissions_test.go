@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+type permissionMockBridge struct {
+	mockBridge
+	url       string
+	overrides map[string]map[string]string
+	requests  []bridge.PermissionRequest
+	resets    [][]string
+}
+
+func (m *permissionMockBridge) CurrentURL(context.Context) (string, error) { return m.url, nil }
+
+func (m *permissionMockBridge) SetPermissions(_ context.Context, origin string, perms map[string]string) error {
+	if m.overrides[origin] == nil {
+		m.overrides[origin] = map[string]string{}
+	}
+	maps.Copy(m.overrides[origin], perms)
+	return nil
+}
+
+func (m *permissionMockBridge) ResetPermissions(_ context.Context, origin string, names []string) error {
+	m.resets = append(m.resets, names)
+	if len(names) == 0 {
+		delete(m.overrides, origin)
+	}
+	for _, name := range names {
+		delete(m.overrides[origin], name)
+	}
+	return nil
+}
+
+func (m *permissionMockBridge) PermissionOverrides(_ context.Context, origin string) map[string]string {
+	return maps.Clone(m.overrides[origin])
+}
+
+func (m *permissionMockBridge) QueryPermissions(context.Context, []string) (map[string]string, error) {
+	return map[string]string{"notifications": "denied"}, nil
+}
+
+func (m *permissionMockBridge) PermissionRequests(string) ([]bridge.PermissionRequest, error) {
+	return m.requests, nil
+}
+
+func newPermissionHandler(url string) (*Handlers, *permissionMockBridge) {
+	b := &permissionMockBridge{url: url, overrides: map[string]map[string]string{}}
+	cfg := &config.RuntimeConfig{Permissions: map[string]string{"geolocation": "denied"}}
+	return New(b, cfg, nil, nil, nil), b
+}
+
+func decodeBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestHandleTabSetPermissions(t *testing.T) {
+	h, b := newPermissionHandler("https://shop.example.com/cart?x=1")
+
+	req := httptest.NewRequest("POST", "/tabs/tab1/permissions", bytes.NewReader([]byte(`{"permissions":{"notifications":"granted","camera":"denied"}}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabSetPermissions(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeBody(t, w)
+	if resp["origin"] != "https://shop.example.com" {
+		t.Fatalf("origin = %v", resp["origin"])
+	}
+	if got := b.overrides["https://shop.example.com"]; got["notifications"] != "granted" || got["camera"] != "denied" {
+		t.Fatalf("overrides = %v", b.overrides)
+	}
+
+	req = httptest.NewRequest("POST", "/permissions", bytes.NewReader([]byte(`{"origin":"https://maps.example.com/x","permissions":{"geolocation":"granted"}}`)))
+	w = httptest.NewRecorder()
+	h.HandleSetPermissions(w, req)
+	if w.Code != 200 || b.overrides["https://maps.example.com"]["geolocation"] != "granted" {
+		t.Fatalf("explicit origin: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleSetPermissions_Invalid(t *testing.T) {
+	h, b := newPermissionHandler("https://shop.example.com/")
+	for name, body := range map[string]string{
+		"unknown permission": `{"permissions":{"telepathy":"granted"}}`,
+		"bad setting":        `{"permissions":{"camera":"maybe"}}`,
+		"empty":              `{"permissions":{}}`,
+		"bad origin":         `{"origin":"file:///tmp","permissions":{"camera":"granted"}}`,
+	} {
+		w := httptest.NewRecorder()
+		h.HandleSetPermissions(w, httptest.NewRequest("POST", "/permissions", bytes.NewReader([]byte(body))))
+		if w.Code != 400 {
+			t.Errorf("%s: status %d, want 400", name, w.Code)
+		}
+	}
+	if len(b.overrides) != 0 {
+		t.Fatalf("invalid requests must not apply: %v", b.overrides)
+	}
+
+	h, _ = newPermissionHandler("about:blank")
+	w := httptest.NewRecorder()
+	h.HandleSetPermissions(w, httptest.NewRequest("POST", "/permissions", bytes.NewReader([]byte(`{"permissions":{"camera":"granted"}}`))))
+	if w.Code != 400 {
+		t.Fatalf("non-http tab without origin: status %d", w.Code)
+	}
+}
+
+func TestHandleTabResetPermissions(t *testing.T) {
+	h, b := newPermissionHandler("https://shop.example.com/")
+	b.overrides["https://shop.example.com"] = map[string]string{"camera": "granted", "microphone": "granted"}
+
+	req := httptest.NewRequest("DELETE", "/tabs/tab1/permissions?name=camera", nil)
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabResetPermissions(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	overrides, _ := decodeBody(t, w)["overrides"].(map[string]any)
+	if _, ok := overrides["camera"]; ok || overrides["microphone"] != "granted" {
+		t.Fatalf("overrides after reset = %v", overrides)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleResetPermissions(w, httptest.NewRequest("DELETE", "/permissions?name=telepathy", nil))
+	if w.Code != 400 {
+		t.Fatalf("unknown name: status %d", w.Code)
+	}
+}
+
+func TestHandleTabPermissions(t *testing.T) {
+	h, b := newPermissionHandler("https://shop.example.com/")
+	b.overrides["https://shop.example.com"] = map[string]string{"camera": "granted"}
+	b.requests = []bridge.PermissionRequest{{Name: "notifications", State: "pending", At: "2026-10-17T00:00:00Z"}}
+
+	req := httptest.NewRequest("GET", "/tabs/tab1/permissions", nil)
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabPermissions(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeBody(t, w)
+	if defaults, _ := resp["defaults"].(map[string]any); defaults["geolocation"] != "denied" {
+		t.Fatalf("defaults = %v", resp)
+	}
+	if states, _ := resp["states"].(map[string]any); states["notifications"] != "denied" {
+		t.Fatalf("states = %v", resp)
+	}
+	if requests, _ := resp["requests"].([]any); len(requests) != 1 {
+		t.Fatalf("requests = %v", resp)
+	}
+
+	// Live states describe the page's own origin only.
+	req = httptest.NewRequest("GET", "/permissions?origin=https://other.example.com", nil)
+	w = httptest.NewRecorder()
+	h.HandlePermissions(w, req)
+	if _, ok := decodeBody(t, w)["states"]; ok {
+		t.Fatal("states must be omitted for a foreign origin")
+	}
+}
+
+func TestHandleTabState_PermissionPending(t *testing.T) {
+	h, b := newPermissionHandler("https://shop.example.com/")
+	b.requests = []bridge.PermissionRequest{{Name: "camera", State: "pending"}}
+
+	req := httptest.NewRequest("GET", "/tabs/tab1/state", nil)
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabState(w, req)
+	resp := decodeBody(t, w)
+	if resp["permissionPending"] != true || resp["actionability"] != "caution" {
+		t.Fatalf("state = %v", resp)
+	}
+}
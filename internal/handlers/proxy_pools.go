@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
+)
+
+// HandleProxyPools reports the configured proxy pools, the health of every
+// proxy, and which tabs run behind which proxy.
+//
+// @Endpoint GET /proxypools
+func (h *Handlers) HandleProxyPools(w http.ResponseWriter, r *http.Request) {
+	pools := h.Bridge.ProxyPools()
+	if pools == nil {
+		pools = []proxypool.PoolStatus{}
+	}
+	tabs := h.Bridge.TabProxies()
+	if tabs == nil {
+		tabs = []bridge.TabProxy{}
+	}
+	httpx.JSON(w, 200, map[string]any{"pools": pools, "tabs": tabs})
+}
+
+// tabProxyOptions builds the pool options of a new tab. The sticky key
+// defaults to the caller's session or agent, so each keeps its proxy.
+func tabProxyOptions(r *http.Request, pool, key string) bridge.TabProxyOptions {
+	pool = strings.TrimSpace(pool)
+	key = strings.TrimSpace(key)
+	if pool == "" {
+		return bridge.TabProxyOptions{}
+	}
+	if key == "" {
+		if scope := currentTabScopeFromRequest(r); !scope.IsGlobal() {
+			key = scope.key
+		}
+	}
+	return bridge.TabProxyOptions{Pool: pool, Key: key}
+}
+
+// errProxyPoolInIsolatedSession refuses a pool proxy to an isolated
+// session: the pooled tab would need a browser context of its own, outside
+// the session's, and so escape the session's tab ownership and cleanup.
+var errProxyPoolInIsolatedSession = errors.New("proxyPool is not available to isolated sessions; their tabs share the session's browser context")
+
+// createTab opens a blank tab for the request: behind a pool proxy when one
+// is requested, else in the browser context of an isolated session. A pooled
+// tab already has a context of its own.
+func (h *Handlers) createTab(r *http.Request, proxy bridge.TabProxyOptions) (string, context.Context, context.CancelFunc, error) {
+	sessionID := isolatedSessionID(r)
+	if proxy.Pool != "" {
+		if sessionID != "" {
+			return "", nil, nil, errProxyPoolInIsolatedSession
+		}
+		return h.Bridge.CreateTabWithProxy("", proxy)
+	}
+	if sessionID != "" {
+		return h.Bridge.CreateTabInSession("", sessionID)
+	}
+	return h.Bridge.CreateTab("")
+}
+
+// writeCreateTabError maps tab creation failures: an unknown pool or a pool
+// asked for by an isolated session is the caller's mistake, an exhausted
+// pool is temporary.
+func writeCreateTabError(w http.ResponseWriter, prefix string, err error) {
+	switch {
+	case errors.Is(err, proxypool.ErrUnknownPool):
+		httpx.ErrorCode(w, 400, "unknown_proxy_pool", err.Error(), false, nil)
+	case errors.Is(err, errProxyPoolInIsolatedSession):
+		httpx.ErrorCode(w, 400, "proxy_pool_isolated_session", err.Error(), false, nil)
+	case errors.Is(err, proxypool.ErrNoHealthyProxy):
+		httpx.ErrorCode(w, http.StatusServiceUnavailable, "no_healthy_proxy", err.Error(), true, nil)
+	case prefix != "":
+		httpx.Error(w, 500, fmt.Errorf("%s: %w", prefix, err))
+	default:
+		httpx.Error(w, 500, err)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
+)
+
+func TestHandleTabNewWithProxyPoolDefaultsKeyToAgent(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new","proxyPool":"residential"}`)))
+	req.Header.Set(activity.HeaderAgentID, "crawler-1")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if len(m.tabProxyOpts) != 1 {
+		t.Fatalf("CreateTabWithProxy calls = %d, want 1", len(m.tabProxyOpts))
+	}
+	if got := m.tabProxyOpts[0]; got.Pool != "residential" || got.Key != "agent:crawler-1" {
+		t.Fatalf("proxy options = %+v", got)
+	}
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if _, ok := resp["proxy"]; !ok {
+		t.Fatalf("response has no proxy: %v", resp)
+	}
+}
+
+func TestHandleTabNewWithUnknownProxyPool(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new","proxyPool":"missing"}`)))
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 (body %s)", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["code"] != "unknown_proxy_pool" {
+		t.Fatalf("code = %v, want unknown_proxy_pool", resp["code"])
+	}
+}
+
+func TestHandleNavigateProxyPoolRejectsExistingTab(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/navigate", bytes.NewReader([]byte(`{"tabId":"tab1","url":"https://example.com","proxyPool":"residential"}`)))
+	w := httptest.NewRecorder()
+	h.HandleNavigate(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 (body %s)", w.Code, w.Body.String())
+	}
+	if len(m.tabProxyOpts) != 0 {
+		t.Fatalf("no tab should be created, got %+v", m.tabProxyOpts)
+	}
+}
+
+func TestHandleProxyPools(t *testing.T) {
+	m := &mockBridge{proxyPools: []proxypool.PoolStatus{{
+		Name:     "residential",
+		Strategy: config.ProxyPoolSticky,
+		Proxies:  []proxypool.ProxyStatus{{Server: "http://a.example:8080", Healthy: true}},
+	}}}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	h.HandleProxyPools(w, httptest.NewRequest("GET", "/proxypools", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var resp struct {
+		Pools []proxypool.PoolStatus `json:"pools"`
+		Tabs  []any                  `json:"tabs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Pools) != 1 || resp.Pools[0].Name != "residential" || resp.Tabs == nil {
+		t.Fatalf("response = %s", w.Body.String())
+	}
+}
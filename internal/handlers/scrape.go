@@ -60,7 +60,7 @@ func (h *Handlers) HandleScrape(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	if _, err := h.validateAuditTarget(req.URL, routing.EffectiveCfg); err != nil {
+	if _, err := h.validateAuditTargetFor(r, req.URL, routing.EffectiveCfg); err != nil {
 		httpx.Error(w, 400, err)
 		return
 	}
@@ -68,7 +68,7 @@ func (h *Handlers) HandleScrape(w http.ResponseWriter, r *http.Request) {
 		if strings.TrimSpace(u) == "" {
 			continue
 		}
-		if _, err := h.validateAuditTarget(u, routing.EffectiveCfg); err != nil {
+		if _, err := h.validateAuditTargetFor(r, u, routing.EffectiveCfg); err != nil {
 			httpx.Error(w, 400, fmt.Errorf("expand target %q: %w", u, err))
 			return
 		}
@@ -91,13 +91,13 @@ func (h *Handlers) HandleScrape(w http.ResponseWriter, r *http.Request) {
 		IncludePatterns: req.IncludePatterns,
 		ExcludePatterns: req.ExcludePatterns,
 	}
-	guard := h.crawlGuard(routing.EffectiveCfg)
+	guard := h.crawlGuard(r, routing.EffectiveCfg)
 	renderer := func(url string) (string, error) {
-		targets, err := h.validateAuditTarget(url, routing.EffectiveCfg)
+		targets, err := h.validateAuditTargetFor(r, url, routing.EffectiveCfg)
 		if err != nil {
 			return "", err
 		}
-		return h.renderPageHTML(runCtx, url, routing.EffectiveCfg, targets)
+		return h.renderPageHTML(runCtx, r, url, routing.EffectiveCfg, targets)
 	}
 
 	// Expand a chosen URL set instead of discovering the site when --only is
@@ -130,10 +130,10 @@ func (h *Handlers) HandleScrape(w http.ResponseWriter, r *http.Request) {
 // crawlGuard adapts this instance's navigation security stack (navguard
 // resolution checks + IDPI domain rules + trusted CIDRs) into the guard the
 // seaportal HTTP crawl applies to every fetch and redirect hop.
-func (h *Handlers) crawlGuard(cfg *config.RuntimeConfig) scrape.CrawlGuard {
+func (h *Handlers) crawlGuard(r *http.Request, cfg *config.RuntimeConfig) scrape.CrawlGuard {
 	return scrape.CrawlGuard{
 		ValidateURL: func(url string) error {
-			_, err := h.validateAuditTarget(url, cfg)
+			_, err := h.validateAuditTargetFor(r, url, cfg)
 			return err
 		},
 		TrustedResolveCIDRs: cfg.TrustedResolveCIDRs,
@@ -144,8 +144,8 @@ func (h *Handlers) crawlGuard(cfg *config.RuntimeConfig) scrape.CrawlGuard {
 // renderPageHTML navigates a fresh tab to url and returns the rendered
 // document HTML after the page settles. The same navigation guard and
 // error-page detection as auditPage apply; the tab is always closed.
-func (h *Handlers) renderPageHTML(clientCtx context.Context, url string, cfg *config.RuntimeConfig, targets navTargets) (string, error) {
-	tabID, tabCtx, _, err := h.Bridge.CreateTab("")
+func (h *Handlers) renderPageHTML(clientCtx context.Context, r *http.Request, url string, cfg *config.RuntimeConfig, targets navTargets) (string, error) {
+	tabID, tabCtx, _, err := h.createTab(r, bridge.TabProxyOptions{})
 	if err != nil {
 		return "", fmt.Errorf("new tab: %w", err)
 	}
@@ -20,6 +20,7 @@ func (h *Handlers) collectScreenshotAnnotations(
 	ctx context.Context,
 	tabID, selector string,
 ) (items []cdptk.AnnotationItem, target *cdptk.AnnotationRect, err error) {
+	epoch := h.armRefCacheEpoch(ctx)
 	rawNodes, err := bridge.FetchAXTree(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("a11y tree: %w", err)
@@ -63,9 +64,10 @@ func (h *Handlers) collectScreenshotAnnotations(
 
 	// Record refs so a subsequent click/fill on `e5` resolves the same node.
 	h.Bridge.SetRefCache(tabID, &bridge.RefCache{
-		Refs:    refs,
-		Targets: bridge.RefTargetsFromNodes(flat),
-		Nodes:   flat,
+		Refs:     refs,
+		Targets:  bridge.RefTargetsFromNodes(flat),
+		Nodes:    flat,
+		DomEpoch: epoch,
 	})
 
 	items = make([]cdptk.AnnotationItem, 0, len(flat))
@@ -60,6 +60,10 @@ func (h *Handlers) networkInterceptEnabled() bool {
 	return h != nil && h.Config != nil && h.Config.AllowNetworkIntercept
 }
 
+func (h *Handlers) webAuthnEnabled() bool {
+	return h != nil && h.Config != nil && h.Config.AllowWebAuthn
+}
+
 func (h *Handlers) endpointSecurityStates() map[string]endpointSecurityState {
 	return map[string]endpointSecurityState{
 		"evaluate": capState(routes.CapEvaluate, h.evaluateEnabled(),
@@ -85,6 +89,13 @@ func (h *Handlers) endpointSecurityStates() map[string]endpointSecurityState {
 			[]string{
 				"GET /storage", "POST /storage", "DELETE /storage",
 				"GET /tabs/{id}/storage", "POST /tabs/{id}/storage", "DELETE /tabs/{id}/storage",
+				"GET /serviceworkers", "POST /serviceworkers/unregister", "POST /serviceworkers/stop", "POST /serviceworkers/bypass",
+				"GET /tabs/{id}/serviceworkers", "POST /tabs/{id}/serviceworkers/unregister",
+				"POST /tabs/{id}/serviceworkers/stop", "POST /tabs/{id}/serviceworkers/bypass",
+				"GET /cachestorage", "GET /cachestorage/entries", "GET /cachestorage/entry", "DELETE /cachestorage",
+				"GET /tabs/{id}/cachestorage", "GET /tabs/{id}/cachestorage/entries",
+				"GET /tabs/{id}/cachestorage/entry", "DELETE /tabs/{id}/cachestorage",
+				"GET /indexeddb", "GET /indexeddb/export", "GET /tabs/{id}/indexeddb", "GET /tabs/{id}/indexeddb/export",
 				"GET /state", "GET /state/list", "GET /state/show", "POST /state/save",
 				"POST /state/load", "DELETE /state", "POST /state/clean",
 			}),
@@ -93,5 +104,22 @@ func (h *Handlers) endpointSecurityStates() map[string]endpointSecurityState {
 				"GET /network/route", "POST /network/route", "DELETE /network/route",
 				"GET /tabs/{id}/network/route", "POST /tabs/{id}/network/route", "DELETE /tabs/{id}/network/route",
 			}),
+		"webauthn": capState(routes.CapWebAuthn, h.webAuthnEnabled(),
+			[]string{
+				"GET /webauthn",
+				"POST /webauthn/authenticators",
+				"DELETE /webauthn/authenticators/{authenticatorId}",
+				"POST /webauthn/authenticators/{authenticatorId}/presence",
+				"GET /webauthn/authenticators/{authenticatorId}/credentials",
+				"POST /webauthn/authenticators/{authenticatorId}/credentials",
+				"DELETE /webauthn/authenticators/{authenticatorId}/credentials",
+				"GET /tabs/{id}/webauthn",
+				"POST /tabs/{id}/webauthn/authenticators",
+				"DELETE /tabs/{id}/webauthn/authenticators/{authenticatorId}",
+				"POST /tabs/{id}/webauthn/authenticators/{authenticatorId}/presence",
+				"GET /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials",
+				"POST /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials",
+				"DELETE /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials",
+			}),
 	}
 }
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/session"
+)
+
+// SessionIsolatedHeader marks an orchestrator → instance hop made for an
+// isolated agent session, next to X-PinchTab-Session-Id. Like every
+// X-PinchTab-* header it is stripped from public requests.
+const SessionIsolatedHeader = "X-PinchTab-Session-Isolated"
+
+// requestSessionID returns the agent session of the request: the
+// authenticated session, or the session forwarded on a trusted internal hop.
+func requestSessionID(r *http.Request) string {
+	if sess, ok := session.FromRequest(r); ok && sess != nil {
+		return strings.TrimSpace(sess.ID)
+	}
+	if IsTrustedInternalProxy(r) {
+		return strings.TrimSpace(r.Header.Get(activity.HeaderPTSessionID))
+	}
+	return ""
+}
+
+// isolatedSessionID returns the session whose tabs must live in its own
+// browser context, or "" when the request is not from an isolated session.
+func isolatedSessionID(r *http.Request) string {
+	if sess, ok := session.FromRequest(r); ok && sess != nil {
+		if sess.Isolated {
+			return strings.TrimSpace(sess.ID)
+		}
+		return ""
+	}
+	if IsTrustedInternalProxy(r) && r.Header.Get(SessionIsolatedHeader) == "1" {
+		return strings.TrimSpace(r.Header.Get(activity.HeaderPTSessionID))
+	}
+	return ""
+}
+
+// ErrTabSessionForbidden is returned for a tab that lives in another
+// isolated session's browser context. Handlers map it to 403 with code
+// `tab_session_forbidden`.
+var ErrTabSessionForbidden = errors.New("tab belongs to another agent session")
+
+// requestTokenID returns the scoped API token of the request: the
+// authenticated token, or the token forwarded on a trusted internal hop.
+func requestTokenID(r *http.Request) string {
+	if tok, ok := apitoken.FromRequest(r); ok && tok != nil {
+		return tok.ID
+	}
+	if IsTrustedInternalProxy(r) {
+		return strings.TrimSpace(r.Header.Get(activity.HeaderPTTokenID))
+	}
+	return ""
+}
+
+// checkTabSession keeps an isolated session's tabs to that session: other
+// agent sessions and scoped API tokens are refused. Callers holding the
+// server token (or a dashboard login) keep access to every tab.
+func (h *Handlers) checkTabSession(r *http.Request, tabID string) error {
+	owner := h.Bridge.TabSession(tabID)
+	if owner == "" {
+		return nil
+	}
+	sessionID := requestSessionID(r)
+	if sessionID == owner || (sessionID == "" && requestTokenID(r) == "") {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrTabSessionForbidden, tabID)
+}
+
+// enforceTabSession writes the refusal for checkTabSession; ok=false means
+// a response was written.
+func (h *Handlers) enforceTabSession(w http.ResponseWriter, r *http.Request, tabID string) bool {
+	if err := h.checkTabSession(r, tabID); err != nil {
+		WriteTabContextError(w, err, http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// HandleSessionContext reports the browser context of the caller's isolated
+// session on this instance.
+//
+// @Endpoint GET /session/context
+func (h *Handlers) HandleSessionContext(w http.ResponseWriter, r *http.Request) {
+	sessionID := requestSessionID(r)
+	if sessionID == "" {
+		httpx.ErrorCode(w, 400, "session_required", "this endpoint requires an agent session", false, nil)
+		return
+	}
+	sc, ok := h.Bridge.SessionContext(sessionID)
+	if !ok {
+		httpx.ErrorCode(w, 404, "no_session_context", "the session has no browser context on this instance", false, map[string]any{
+			"isolated": isolatedSessionID(r) != "",
+		})
+		return
+	}
+	httpx.JSON(w, 200, sc)
+}
+
+// HandleDisposeSessionContext closes the caller's session tabs and disposes
+// its browser context. The orchestrator calls it when a session is revoked
+// or expires.
+//
+// @Endpoint DELETE /session/context
+func (h *Handlers) HandleDisposeSessionContext(w http.ResponseWriter, r *http.Request) {
+	sessionID := requestSessionID(r)
+	if sessionID == "" {
+		httpx.ErrorCode(w, 400, "session_required", "this endpoint requires an agent session", false, nil)
+		return
+	}
+	closed, err := h.Bridge.DisposeSessionContext(sessionID)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "session.context.dispose"})
+	httpx.JSON(w, 200, map[string]any{"sessionId": sessionID, "tabsClosed": closed})
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/audit"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/session"
+)
+
+func TestHandleTabNewInIsolatedSession(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new"}`)))
+	req = session.WithSession(req, &session.Session{ID: "ses_iso", AgentID: "agent-1", Isolated: true})
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if len(m.sessionTabs) != 1 || m.sessionTabs[0] != "ses_iso" {
+		t.Fatalf("CreateTabInSession calls = %v, want [ses_iso]", m.sessionTabs)
+	}
+
+	req = httptest.NewRequest("GET", "/session/context", nil)
+	req = session.WithSession(req, &session.Session{ID: "ses_iso", Isolated: true})
+	w = httptest.NewRecorder()
+	h.HandleSessionContext(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("context status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var sc map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &sc)
+	if sc["browserContextId"] != "ctx-ses_iso" {
+		t.Fatalf("context = %v", sc)
+	}
+}
+
+func TestProxyPoolRejectedForIsolatedSession(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path string
+		body string
+	}{
+		{"tab", "/tab", `{"action":"new","proxyPool":"residential"}`},
+		{"navigate", "/navigate", `{"url":"https://example.com","proxyPool":"residential"}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &mockBridge{}
+			h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+			req := httptest.NewRequest("POST", tc.path, bytes.NewReader([]byte(tc.body)))
+			req = session.WithSession(req, &session.Session{ID: "ses_iso", AgentID: "agent-1", Isolated: true})
+			w := httptest.NewRecorder()
+			if tc.path == "/tab" {
+				h.HandleTab(w, req)
+			} else {
+				h.HandleNavigate(w, req)
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+			}
+			var resp map[string]any
+			_ = json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["code"] != "proxy_pool_isolated_session" {
+				t.Fatalf("code = %v", resp["code"])
+			}
+			if len(m.tabProxyOpts) != 0 || len(m.sessionTabs) != 0 {
+				t.Fatalf("tab created: proxy=%v session=%v", m.tabProxyOpts, m.sessionTabs)
+			}
+		})
+	}
+}
+
+func TestHandleTabNewSharedSessionUsesDefaultContext(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"new"}`)))
+	req = session.WithSession(req, &session.Session{ID: "ses_shared", AgentID: "agent-1"})
+	// A public client cannot claim isolation with the internal header.
+	req.Header.Set(activity.HeaderPTSessionID, "ses_other")
+	req.Header.Set(SessionIsolatedHeader, "1")
+	w := httptest.NewRecorder()
+	h.HandleTab(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if len(m.sessionTabs) != 0 {
+		t.Fatalf("CreateTabInSession calls = %v, want none", m.sessionTabs)
+	}
+}
+
+func TestHandleDisposeSessionContextFromTrustedHop(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("DELETE", "/session/context", nil)
+	req = req.WithContext(MarkTrustedInternalProxy(req.Context()))
+	req.Header.Set(activity.HeaderPTSessionID, "ses_iso")
+	req.Header.Set(SessionIsolatedHeader, "1")
+	w := httptest.NewRecorder()
+	h.HandleDisposeSessionContext(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if len(m.disposedSessions) != 1 || m.disposedSessions[0] != "ses_iso" {
+		t.Fatalf("DisposeSessionContext calls = %v", m.disposedSessions)
+	}
+}
+
+func TestHandleSessionContextRequiresSession(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{}, nil, nil, nil)
+
+	req := httptest.NewRequest("DELETE", "/session/context", nil)
+	req.Header.Set(activity.HeaderPTSessionID, "ses_spoofed")
+	w := httptest.NewRecorder()
+	h.HandleDisposeSessionContext(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 (body %s)", w.Code, w.Body.String())
+	}
+}
+
+func TestIsolatedSessionTabsRefuseOtherCallers(t *testing.T) {
+	m := &mockBridge{tabSessions: map[string]string{"tab1": "ses_a"}}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+
+	focus := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleTab(w, r)
+		return w
+	}
+	newReq := func() *http.Request {
+		return httptest.NewRequest("POST", "/tab", bytes.NewReader([]byte(`{"action":"focus","tabId":"tab1"}`)))
+	}
+
+	if w := focus(session.WithSession(newReq(), &session.Session{ID: "ses_a", Isolated: true})); w.Code != http.StatusOK {
+		t.Fatalf("owner: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	for name, req := range map[string]*http.Request{
+		"other session": session.WithSession(newReq(), &session.Session{ID: "ses_b", Isolated: true}),
+		"scoped token":  apitoken.WithToken(newReq(), &apitoken.Token{ID: "tok_1"}),
+	} {
+		w := focus(req)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("%s: status = %d, want 403 (body %s)", name, w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["code"] != "tab_session_forbidden" {
+			t.Fatalf("%s: code = %v", name, resp["code"])
+		}
+	}
+	if w := focus(newReq()); w.Code != http.StatusOK {
+		t.Fatalf("server token: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	// Tab-scoped routes resolve through tabContext and get the same check.
+	other := session.WithSession(httptest.NewRequest("GET", "/tabs/tab1/text", nil), &session.Session{ID: "ses_b", Isolated: true})
+	if _, _, err := h.tabContext(other, "tab1"); err == nil {
+		t.Fatal("another session resolved an isolated session's tab")
+	}
+	owner := session.WithSession(httptest.NewRequest("GET", "/tabs/tab1/text", nil), &session.Session{ID: "ses_a", Isolated: true})
+	if _, _, err := h.tabContext(owner, "tab1"); err != nil {
+		t.Fatalf("owner: %v", err)
+	}
+}
+
+func TestScrapeAndAuditTabsUseSessionContext(t *testing.T) {
+	m := &mockBridge{}
+	h := New(m, &config.RuntimeConfig{}, nil, nil, nil)
+	req := session.WithSession(httptest.NewRequest("POST", "/scrape", nil), &session.Session{ID: "ses_iso", Isolated: true})
+
+	_, _ = h.renderPageHTML(req.Context(), req, "https://example.com", h.Config, navTargets{})
+	_ = h.auditPage(req, "https://example.com", audit.PageOptions{}, h.Config, navTargets{})
+	if len(m.sessionTabs) != 2 || m.sessionTabs[0] != "ses_iso" || m.sessionTabs[1] != "ses_iso" {
+		t.Fatalf("CreateTabInSession calls = %v, want two for ses_iso", m.sessionTabs)
+	}
+}
@@ -0,0 +1,621 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/assets"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// Service workers, Cache Storage and IndexedDB are gated like /storage
+// (CapStateExport): they hold the same kind of session data. Service workers
+// and Cache Storage go through CDP; IndexedDB is read in the page through
+// assets.SiteDataJS and so only covers the tab's current origin.
+
+// siteDataTimeout bounds a single site data operation.
+const siteDataTimeout = 30 * time.Second
+
+// siteDataContext checks the gate and resolves the tab for a site data
+// request. It writes any error response and reports whether to continue.
+func (h *Handlers) siteDataContext(w http.ResponseWriter, r *http.Request, tabID string) (context.Context, string, bool) {
+	if !h.ensureStateExportEnabled(w) {
+		return nil, "", false
+	}
+	if err := h.ensureBrowser(h.Config); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return nil, "", false
+		}
+		httpx.Error(w, 500, fmt.Errorf("browser initialization: %w", err))
+		return nil, "", false
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		WriteTabContextError(w, err, 404)
+		return nil, "", false
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return nil, "", false
+	}
+	return ctx, resolvedTabID, true
+}
+
+// runSiteDataOp runs one operation of the in-page site data script and
+// decodes its result into out. It returns the page origin.
+func (h *Handlers) runSiteDataOp(ctx context.Context, op string, arg any, out any) (string, error) {
+	opJSON, _ := json.Marshal(op)
+	argJSON, err := json.Marshal(arg)
+	if err != nil {
+		return "", fmt.Errorf("encode %s: %w", op, err)
+	}
+	script := fmt.Sprintf("(%s)(%s, %s)", assets.SiteDataJS, opJSON, argJSON)
+
+	var raw string
+	if err := h.Bridge.Evaluate(ctx, script, &raw, bridge.EvalOpts{AwaitPromise: true}); err != nil {
+		return "", fmt.Errorf("evaluate %s: %w", op, err)
+	}
+	var resp struct {
+		Origin string          `json:"origin"`
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return "", fmt.Errorf("parse %s result: %w", op, err)
+	}
+	if resp.Error != "" {
+		return resp.Origin, fmt.Errorf("%s: %s", op, resp.Error)
+	}
+	if out != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, out); err != nil {
+			return resp.Origin, fmt.Errorf("parse %s result: %w", op, err)
+		}
+	}
+	return resp.Origin, nil
+}
+
+// pageOrigin returns the origin of the document loaded in the tab of ctx,
+// or "" for opaque origins such as about:blank.
+func (h *Handlers) pageOrigin(ctx context.Context) (string, error) {
+	var origin string
+	if err := h.Bridge.Evaluate(ctx, "window.location.origin", &origin, bridge.EvalOpts{}); err != nil {
+		return "", err
+	}
+	if origin == "null" {
+		return "", nil
+	}
+	return origin, nil
+}
+
+func decodeSiteDataBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return false
+	}
+	return true
+}
+
+// queryInt parses an optional non-negative integer query parameter.
+func queryInt(q url.Values, name string) (int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return n, nil
+}
+
+// HandleServiceWorkers lists the service worker registrations the browser
+// knows about and whether the tab bypasses them.
+//
+// @Endpoint GET /serviceworkers
+// @Description List service worker registrations and their versions
+// @Param tabId string query Tab ID (optional, defaults to the current tab)
+// @Param origin string query Only registrations whose scope is on this origin (optional)
+// @Response 200 application/json {tabId, bypass, serviceWorkers}
+func (h *Handlers) HandleServiceWorkers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ctx, tabID, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	workers, err := h.Bridge.ServiceWorkers(tCtx, tabID)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	if origin := q.Get("origin"); origin != "" {
+		workers = filterServiceWorkers(workers, origin)
+	}
+	if workers == nil {
+		workers = []bridge.ServiceWorkerInfo{}
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":          tabID,
+		"bypass":         h.Bridge.ServiceWorkerBypass(tabID),
+		"serviceWorkers": workers,
+	})
+}
+
+func filterServiceWorkers(workers []bridge.ServiceWorkerInfo, origin string) []bridge.ServiceWorkerInfo {
+	out := []bridge.ServiceWorkerInfo{}
+	for _, sw := range workers {
+		if urlOrigin(sw.ScopeURL) == origin {
+			out = append(out, sw)
+		}
+	}
+	return out
+}
+
+func urlOrigin(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// HandleUnregisterServiceWorker unregisters a service worker by scope.
+//
+// @Endpoint POST /serviceworkers/unregister
+// @Description Unregister the service worker registered for a scope URL
+// @Param tabId string body Tab ID (optional)
+// @Param scopeURL string body Registration scope URL (required)
+// @Response 200 application/json {unregistered, scopeURL}
+func (h *Handlers) HandleUnregisterServiceWorker(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID    string `json:"tabId"`
+		ScopeURL string `json:"scopeURL"`
+	}
+	if !decodeSiteDataBody(w, r, &req) {
+		return
+	}
+	if req.ScopeURL == "" {
+		httpx.Error(w, 400, fmt.Errorf("scopeURL is required"))
+		return
+	}
+	ctx, tabID, ok := h.siteDataContext(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if err := h.Bridge.UnregisterServiceWorker(tCtx, tabID, req.ScopeURL); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "serviceworkers.unregister", TabID: tabID})
+	slog.Info("service worker unregistered", "scopeURL", req.ScopeURL, "tabId", tabID, "remoteAddr", r.RemoteAddr)
+	httpx.JSON(w, 200, map[string]any{"unregistered": true, "scopeURL": req.ScopeURL})
+}
+
+// HandleStopServiceWorkers stops one running service worker version, or all
+// of them.
+//
+// @Endpoint POST /serviceworkers/stop
+// @Description Stop a running service worker (or every running worker)
+// @Param tabId string body Tab ID (optional)
+// @Param versionId string body Version to stop (optional, defaults to all)
+// @Response 200 application/json {stopped, versionId}
+func (h *Handlers) HandleStopServiceWorkers(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID     string `json:"tabId"`
+		VersionID string `json:"versionId"`
+	}
+	if !decodeSiteDataBody(w, r, &req) {
+		return
+	}
+	ctx, tabID, ok := h.siteDataContext(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if err := h.Bridge.StopServiceWorkers(tCtx, tabID, req.VersionID); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "serviceworkers.stop", TabID: tabID})
+	httpx.JSON(w, 200, map[string]any{"stopped": true, "versionId": req.VersionID})
+}
+
+// HandleServiceWorkerBypass turns service worker bypass on or off for a
+// tab's network requests.
+//
+// @Endpoint POST /serviceworkers/bypass
+// @Description Make the tab's requests skip service workers and go to the network
+// @Param tabId string body Tab ID (optional)
+// @Param enabled bool body Bypass service workers (required)
+// @Response 200 application/json {tabId, bypass}
+func (h *Handlers) HandleServiceWorkerBypass(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TabID   string `json:"tabId"`
+		Enabled *bool  `json:"enabled"`
+	}
+	if !decodeSiteDataBody(w, r, &req) {
+		return
+	}
+	if req.Enabled == nil {
+		httpx.Error(w, 400, fmt.Errorf("enabled is required"))
+		return
+	}
+	ctx, tabID, ok := h.siteDataContext(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if err := h.Bridge.SetServiceWorkerBypass(tCtx, tabID, *req.Enabled); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "serviceworkers.bypass", TabID: tabID})
+	httpx.JSON(w, 200, map[string]any{"tabId": tabID, "bypass": *req.Enabled})
+}
+
+// HandleCacheStorage lists the Cache Storage caches of an origin.
+//
+// @Endpoint GET /cachestorage
+// @Description List Cache Storage caches
+// @Param tabId string query Tab ID (optional)
+// @Param origin string query Security origin (optional, defaults to the tab's origin)
+// @Response 200 application/json {origin, caches}
+func (h *Handlers) HandleCacheStorage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ctx, _, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	origin, ok := h.cacheStorageOrigin(w, r, tCtx, q.Get("origin"))
+	if !ok {
+		return
+	}
+	caches, err := h.Bridge.CacheStorageCaches(tCtx, origin)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"origin": origin, "caches": caches})
+}
+
+// cacheStorageOrigin resolves the origin of a Cache Storage request, the
+// tab's origin unless one is given, and applies the IDPI and API token domain
+// policy to it. It writes any error response and reports whether to continue.
+func (h *Handlers) cacheStorageOrigin(w http.ResponseWriter, r *http.Request, ctx context.Context, origin string) (string, bool) {
+	if origin == "" {
+		var err error
+		if origin, err = h.pageOrigin(ctx); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("page origin: %w", err))
+			return "", false
+		}
+		if origin == "" {
+			httpx.Error(w, 400, fmt.Errorf("tab has no origin; pass origin"))
+			return "", false
+		}
+	}
+	if !h.enforceURLDomainPolicy(w, r, origin) {
+		return "", false
+	}
+	return origin, true
+}
+
+// checkCacheID makes sure cacheID belongs to an origin the caller may read.
+// The origin is the origin parameter, else the one Chrome encodes in the
+// cache ID ("<storage key>|<cache name>"), else the tab's; the cache must be
+// listed for it.
+func (h *Handlers) checkCacheID(w http.ResponseWriter, r *http.Request, ctx context.Context, cacheID string) bool {
+	origin := r.URL.Query().Get("origin")
+	if origin == "" {
+		if key, _, ok := strings.Cut(cacheID, "|"); ok {
+			origin = urlOrigin(key)
+		}
+	}
+	origin, ok := h.cacheStorageOrigin(w, r, ctx, origin)
+	if !ok {
+		return false
+	}
+	caches, err := h.Bridge.CacheStorageCaches(ctx, origin)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return false
+	}
+	for _, c := range caches {
+		if string(c.CacheID) == cacheID {
+			return true
+		}
+	}
+	httpx.ErrorCode(w, 404, "cache_not_found", fmt.Sprintf("no cache %q for %s", cacheID, origin), false, nil)
+	return false
+}
+
+// HandleCacheStorageEntries pages through the entries of a cache.
+//
+// @Endpoint GET /cachestorage/entries
+// @Description List the request/response entries of a cache
+// @Param tabId string query Tab ID (optional)
+// @Param cacheId string query Cache ID from GET /cachestorage (required)
+// @Param origin string query Security origin of the cache (optional, defaults to the one in the cache ID, then the tab's)
+// @Param skip int query Entries to skip (optional)
+// @Param limit int query Maximum entries to return (optional)
+// @Param path string query Only entries whose URL path contains this (optional)
+// @Response 200 application/json {cacheId, entries, total}
+func (h *Handlers) HandleCacheStorageEntries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cacheID := q.Get("cacheId")
+	if cacheID == "" {
+		httpx.Error(w, 400, fmt.Errorf("cacheId is required"))
+		return
+	}
+	skip, err := queryInt(q, "skip")
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	limit, err := queryInt(q, "limit")
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	ctx, _, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if !h.checkCacheID(w, r, tCtx, cacheID) {
+		return
+	}
+	entries, total, err := h.Bridge.CacheStorageEntries(tCtx, cacheID, skip, limit, q.Get("path"))
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{"cacheId": cacheID, "entries": entries, "total": total})
+}
+
+// HandleCacheStorageEntry returns the cached response body for a URL.
+//
+// @Endpoint GET /cachestorage/entry
+// @Description Fetch a cached response body
+// @Param tabId string query Tab ID (optional)
+// @Param cacheId string query Cache ID (required)
+// @Param origin string query Security origin of the cache (optional, defaults to the one in the cache ID, then the tab's)
+// @Param url string query Request URL of the entry (required)
+// @Response 200 application/json {cacheId, url, size, body} (body is base64)
+func (h *Handlers) HandleCacheStorageEntry(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cacheID, requestURL := q.Get("cacheId"), q.Get("url")
+	if cacheID == "" || requestURL == "" {
+		httpx.Error(w, 400, fmt.Errorf("cacheId and url are required"))
+		return
+	}
+	ctx, _, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if !h.checkCacheID(w, r, tCtx, cacheID) {
+		return
+	}
+	body, err := h.Bridge.CacheStorageResponse(tCtx, cacheID, requestURL)
+	if err != nil {
+		if errors.Is(err, bridge.ErrCacheEntryNotFound) {
+			httpx.Error(w, 404, err)
+			return
+		}
+		httpx.Error(w, 500, err)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"cacheId": cacheID,
+		"url":     requestURL,
+		"size":    len(body),
+		"body":    base64.StdEncoding.EncodeToString(body),
+	})
+}
+
+// HandleCacheStorageDelete deletes a cache entry, or the whole cache when no
+// url is given.
+//
+// @Endpoint DELETE /cachestorage
+// @Description Delete a Cache Storage entry or cache
+// @Param tabId string query Tab ID (optional)
+// @Param cacheId string query Cache ID (required)
+// @Param origin string query Security origin of the cache (optional, defaults to the one in the cache ID, then the tab's)
+// @Param url string query Request URL of the entry to delete (optional, defaults to the whole cache)
+// @Response 200 application/json {deleted, cacheId, url}
+func (h *Handlers) HandleCacheStorageDelete(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	cacheID, requestURL := q.Get("cacheId"), q.Get("url")
+	if cacheID == "" {
+		httpx.Error(w, 400, fmt.Errorf("cacheId is required"))
+		return
+	}
+	ctx, tabID, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	if !h.checkCacheID(w, r, tCtx, cacheID) {
+		return
+	}
+	if err := h.Bridge.DeleteCacheStorage(tCtx, cacheID, requestURL); err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "cachestorage.delete", TabID: tabID})
+	slog.Info("cache storage deleted", "cacheId", cacheID, "url", requestURL, "tabId", tabID, "remoteAddr", r.RemoteAddr)
+	httpx.JSON(w, 200, map[string]any{"deleted": true, "cacheId": cacheID, "url": requestURL})
+}
+
+// HandleIndexedDB lists the IndexedDB databases of the tab's origin with
+// their object stores, indexes and record counts.
+//
+// @Endpoint GET /indexeddb
+// @Description List IndexedDB databases for the current origin
+// @Param tabId string query Tab ID (optional)
+// @Param database string query Only this database (optional)
+// @Response 200 application/json {origin, databases}
+func (h *Handlers) HandleIndexedDB(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ctx, _, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	var databases []json.RawMessage
+	origin, err := h.runSiteDataOp(tCtx, "idb.list", map[string]any{"database": q.Get("database")}, &databases)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	if databases == nil {
+		databases = []json.RawMessage{}
+	}
+	httpx.JSON(w, 200, map[string]any{"origin": origin, "databases": databases})
+}
+
+// HandleIndexedDBExport exports IndexedDB records of the tab's origin.
+// Values that JSON cannot hold are tagged (see state.IndexedDBRecord).
+//
+// @Endpoint GET /indexeddb/export
+// @Description Export IndexedDB databases with their records
+// @Param tabId string query Tab ID (optional)
+// @Param database string query Only this database (optional)
+// @Param store string query Only this object store (optional)
+// @Param limit int query Maximum records per object store (optional)
+// @Response 200 application/json {origin, databases, truncated}
+func (h *Handlers) HandleIndexedDBExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, err := queryInt(q, "limit")
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	ctx, tabID, ok := h.siteDataContext(w, r, q.Get("tabId"))
+	if !ok {
+		return
+	}
+	tCtx, cancel := context.WithTimeout(ctx, siteDataTimeout)
+	defer cancel()
+
+	var result struct {
+		Databases []json.RawMessage `json:"databases"`
+		Truncated bool              `json:"truncated"`
+	}
+	origin, err := h.runSiteDataOp(tCtx, "idb.export", map[string]any{
+		"database": q.Get("database"),
+		"store":    q.Get("store"),
+		"limit":    limit,
+		"maxBytes": maxIndexedDBExportBytes,
+	}, &result)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+	if result.Databases == nil {
+		result.Databases = []json.RawMessage{}
+	}
+	h.recordActivity(r, activity.Update{Action: "indexeddb.export", TabID: tabID})
+	slog.Info("indexeddb exported", "origin", origin, "database", q.Get("database"), "tabId", tabID, "remoteAddr", r.RemoteAddr)
+	httpx.JSON(w, 200, map[string]any{"origin": origin, "databases": result.Databases, "truncated": result.Truncated})
+}
+
+// HandleTabServiceWorkers lists service workers for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/serviceworkers
+func (h *Handlers) HandleTabServiceWorkers(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleServiceWorkers)
+}
+
+// HandleTabUnregisterServiceWorker unregisters a service worker for a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/serviceworkers/unregister
+func (h *Handlers) HandleTabUnregisterServiceWorker(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabIDBody(w, r, h.HandleUnregisterServiceWorker)
+}
+
+// HandleTabStopServiceWorkers stops service workers for a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/serviceworkers/stop
+func (h *Handlers) HandleTabStopServiceWorkers(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabIDBody(w, r, h.HandleStopServiceWorkers)
+}
+
+// HandleTabServiceWorkerBypass sets service worker bypass for a tab identified by path ID.
+//
+// @Endpoint POST /tabs/{id}/serviceworkers/bypass
+func (h *Handlers) HandleTabServiceWorkerBypass(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabIDBody(w, r, h.HandleServiceWorkerBypass)
+}
+
+// HandleTabCacheStorage lists caches for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/cachestorage
+func (h *Handlers) HandleTabCacheStorage(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleCacheStorage)
+}
+
+// HandleTabCacheStorageEntries lists cache entries for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/cachestorage/entries
+func (h *Handlers) HandleTabCacheStorageEntries(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleCacheStorageEntries)
+}
+
+// HandleTabCacheStorageEntry fetches a cached response for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/cachestorage/entry
+func (h *Handlers) HandleTabCacheStorageEntry(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleCacheStorageEntry)
+}
+
+// HandleTabCacheStorageDelete deletes cache storage for a tab identified by path ID.
+//
+// @Endpoint DELETE /tabs/{id}/cachestorage
+func (h *Handlers) HandleTabCacheStorageDelete(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleCacheStorageDelete)
+}
+
+// HandleTabIndexedDB lists IndexedDB databases for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/indexeddb
+func (h *Handlers) HandleTabIndexedDB(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleIndexedDB)
+}
+
+// HandleTabIndexedDBExport exports IndexedDB records for a tab identified by path ID.
+//
+// @Endpoint GET /tabs/{id}/indexeddb/export
+func (h *Handlers) HandleTabIndexedDBExport(w http.ResponseWriter, r *http.Request) {
+	h.withPathTabID(w, r, h.HandleIndexedDBExport)
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/cachestorage"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/state"
+)
+
+type siteDataMockBridge struct {
+	mockBridge
+	workers  []bridge.ServiceWorkerInfo
+	bypass   map[string]bool
+	entries  map[string][]byte
+	siteOps  []string
+	idbValue string
+}
+
+func (m *siteDataMockBridge) ServiceWorkers(context.Context, string) ([]bridge.ServiceWorkerInfo, error) {
+	return m.workers, nil
+}
+
+func (m *siteDataMockBridge) SetServiceWorkerBypass(_ context.Context, tabID string, bypass bool) error {
+	m.bypass[tabID] = bypass
+	return nil
+}
+
+func (m *siteDataMockBridge) ServiceWorkerBypass(tabID string) bool { return m.bypass[tabID] }
+
+func (m *siteDataMockBridge) CacheStorageCaches(_ context.Context, origin string) ([]*bridge.CacheStorageCache, error) {
+	return []*bridge.CacheStorageCache{{CacheID: "c1", CacheName: "v1", SecurityOrigin: origin}}, nil
+}
+
+func (m *siteDataMockBridge) CacheStorageEntries(context.Context, string, int, int, string) ([]*bridge.CacheStorageEntry, int, error) {
+	return []*bridge.CacheStorageEntry{
+		{RequestURL: "https://app.example/app.js", RequestMethod: "GET", ResponseStatus: 200,
+			ResponseHeaders: []*cachestorage.Header{{Name: "Content-Type", Value: "text/javascript"}}},
+	}, 1, nil
+}
+
+func (m *siteDataMockBridge) CacheStorageResponse(_ context.Context, _, requestURL string) ([]byte, error) {
+	body, ok := m.entries[requestURL]
+	if !ok {
+		return nil, bridge.ErrCacheEntryNotFound
+	}
+	return body, nil
+}
+
+// evaluate stands in for the page: it answers the storage capture script,
+// location.origin, and the site data script ops.
+func (m *siteDataMockBridge) evaluate(expression string, result any) error {
+	switch {
+	case expression == "window.location.origin":
+		*result.(*string) = "https://app.example"
+	case strings.Contains(expression, "sessionStorage.getItem"):
+		*result.(*string) = `{"local":{},"session":{},"origin":"https://app.example","url":"https://app.example/"}`
+	default:
+		for _, op := range []string{"idb.list", "idb.export", "idb.import", "cache.restore", "sw.register"} {
+			if strings.Contains(expression, `)("`+op+`", `) {
+				m.siteOps = append(m.siteOps, op)
+				*result.(*string) = `{"origin":"https://app.example","result":` + m.idbValue + `}`
+			}
+		}
+	}
+	return nil
+}
+
+func newSiteDataHandler(t *testing.T, enabled bool) (*Handlers, *siteDataMockBridge) {
+	b := &siteDataMockBridge{bypass: map[string]bool{}, entries: map[string][]byte{}, idbValue: `{}`}
+	b.evaluateFn = b.evaluate
+	return New(b, &config.RuntimeConfig{AllowStateExport: enabled, StateDir: t.TempDir()}, nil, nil, nil), b
+}
+
+func TestHandleServiceWorkers_Disabled(t *testing.T) {
+	h, _ := newSiteDataHandler(t, false)
+	w := httptest.NewRecorder()
+	h.HandleServiceWorkers(w, httptest.NewRequest("GET", "/serviceworkers", nil))
+	if w.Code != 403 || decodeBody(t, w)["code"] != "state_export_disabled" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleServiceWorkers_OriginFilter(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.workers = []bridge.ServiceWorkerInfo{
+		{RegistrationID: "1", ScopeURL: "https://app.example/"},
+		{RegistrationID: "2", ScopeURL: "https://other.example/"},
+	}
+	w := httptest.NewRecorder()
+	h.HandleServiceWorkers(w, httptest.NewRequest("GET", "/serviceworkers?origin=https://app.example", nil))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if list, _ := decodeBody(t, w)["serviceWorkers"].([]any); len(list) != 1 {
+		t.Fatalf("serviceWorkers = %s", w.Body.String())
+	}
+}
+
+func TestHandleTabServiceWorkerBypass(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	req := httptest.NewRequest("POST", "/tabs/tab1/serviceworkers/bypass", bytes.NewReader([]byte(`{"enabled":true}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabServiceWorkerBypass(w, req)
+	if w.Code != 200 || !b.bypass["tab1"] {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleServiceWorkerBypass(w, httptest.NewRequest("POST", "/serviceworkers/bypass", bytes.NewReader([]byte(`{}`))))
+	if w.Code != 400 {
+		t.Fatalf("missing enabled: status %d", w.Code)
+	}
+}
+
+func TestHandleCacheStorageEntry(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.entries["https://app.example/app.js"] = []byte("console.log(1)")
+
+	w := httptest.NewRecorder()
+	h.HandleCacheStorageEntry(w, httptest.NewRequest("GET", "/cachestorage/entry?cacheId=c1&url=https://app.example/app.js", nil))
+	if w.Code != 200 || decodeBody(t, w)["body"] != "Y29uc29sZS5sb2coMSk=" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleCacheStorageEntry(w, httptest.NewRequest("GET", "/cachestorage/entry?cacheId=c1&url=https://app.example/missing", nil))
+	if w.Code != 404 {
+		t.Fatalf("missing entry: status %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleCacheStorageEntries(w, httptest.NewRequest("GET", "/cachestorage/entries?cacheId=c1&limit=-1", nil))
+	if w.Code != 400 {
+		t.Fatalf("negative limit: status %d", w.Code)
+	}
+}
+
+func TestHandleCacheStorage_DefaultsToPageOrigin(t *testing.T) {
+	h, _ := newSiteDataHandler(t, true)
+	w := httptest.NewRecorder()
+	h.HandleCacheStorage(w, httptest.NewRequest("GET", "/cachestorage", nil))
+	if w.Code != 200 || decodeBody(t, w)["origin"] != "https://app.example" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCacheStorage_OriginOutsideTokenDomains(t *testing.T) {
+	h, _ := newSiteDataHandler(t, true)
+	req := httptest.NewRequest("GET", "/cachestorage?origin=https://bank.example", nil)
+	req = apitoken.WithToken(req, &apitoken.Token{Scope: apitoken.Scope{AllowedDomains: []string{"app.example"}}})
+	w := httptest.NewRecorder()
+	h.HandleCacheStorage(w, req)
+	if w.Code != 403 || decodeBody(t, w)["code"] != "token_domain_forbidden" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCacheStorage_OriginBlockedByIDPI(t *testing.T) {
+	h, _ := newSiteDataHandler(t, true)
+	h.Config.AllowedDomains = []string{"app.example"}
+	h.Config.IDPI = config.IDPIConfig{Enabled: true, StrictMode: true}
+	w := httptest.NewRecorder()
+	h.HandleCacheStorage(w, httptest.NewRequest("GET", "/cachestorage?origin=https://bank.example", nil))
+	if w.Code != 403 || decodeBody(t, w)["code"] != "idpi_domain_blocked" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCacheStorageEntry_ChecksCacheOrigin(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.entries["https://bank.example/account"] = []byte("secret")
+	token := &apitoken.Token{Scope: apitoken.Scope{AllowedDomains: []string{"app.example"}}}
+
+	// The origin encoded in the cache ID is checked.
+	req := httptest.NewRequest("GET", "/cachestorage/entry?cacheId="+url.QueryEscape("https://bank.example/|v1")+"&url=https://bank.example/account", nil)
+	w := httptest.NewRecorder()
+	h.HandleCacheStorageEntry(w, apitoken.WithToken(req, token))
+	if w.Code != 403 || decodeBody(t, w)["code"] != "token_domain_forbidden" {
+		t.Fatalf("cache ID origin: status %d: %s", w.Code, w.Body.String())
+	}
+
+	// A cache ID the allowed origin does not own is refused.
+	for _, handler := range []func(http.ResponseWriter, *http.Request){h.HandleCacheStorageEntries, h.HandleCacheStorageEntry, h.HandleCacheStorageDelete} {
+		req = httptest.NewRequest("GET", "/cachestorage/entry?cacheId=c9&url=https://bank.example/account", nil)
+		w = httptest.NewRecorder()
+		handler(w, apitoken.WithToken(req, token))
+		if w.Code != 404 || decodeBody(t, w)["code"] != "cache_not_found" {
+			t.Fatalf("foreign cache ID: status %d: %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleIndexedDBExport(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.idbValue = `{"databases":[{"name":"app","version":2,"objectStores":[]}],"truncated":true}`
+	w := httptest.NewRecorder()
+	h.HandleIndexedDBExport(w, httptest.NewRequest("GET", "/indexeddb/export?database=app", nil))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeBody(t, w)
+	if dbs, _ := resp["databases"].([]any); len(dbs) != 1 || resp["truncated"] != true || resp["origin"] != "https://app.example" {
+		t.Fatalf("resp = %v", resp)
+	}
+}
+
+func TestCaptureBrowserStateIncludesSiteData(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.workers = []bridge.ServiceWorkerInfo{{
+		RegistrationID: "1",
+		ScopeURL:       "https://app.example/",
+		Versions:       []bridge.ServiceWorkerVersion{{VersionID: "7", ScriptURL: "https://app.example/sw.js", Status: "activated"}},
+	}}
+	b.entries["https://app.example/app.js"] = []byte("js")
+	b.idbValue = `{"databases":[{"name":"app","version":1,"objectStores":[{"name":"kv","keyPath":null,"autoIncrement":false,"records":[{"key":"a","value":{"$date":"2026-01-01T00:00:00.000Z"}}]}]}]}`
+
+	captured, err := h.captureBrowserState(context.Background(), httptest.NewRequest("GET", "/state", nil), "tab1", nil, resolvedStateOrigins{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf := captured.file
+	const origin = "https://app.example"
+	if sw := sf.ServiceWorkers[origin]; len(sw) != 1 || sw[0].ScriptURL != "https://app.example/sw.js" {
+		t.Fatalf("serviceWorkers = %+v", sf.ServiceWorkers)
+	}
+	if c := sf.CacheStorage[origin]; len(c) != 1 || len(c[0].Entries) != 1 || c[0].Entries[0].Body != "anM=" {
+		t.Fatalf("cacheStorage = %+v", sf.CacheStorage)
+	}
+	dbs := sf.IndexedDB[origin]
+	if len(dbs) != 1 || len(dbs[0].ObjectStores) != 1 || len(dbs[0].ObjectStores[0].Records) != 1 {
+		t.Fatalf("indexedDB = %+v", sf.IndexedDB)
+	}
+	if string(dbs[0].ObjectStores[0].Records[0].Value) != `{"$date":"2026-01-01T00:00:00.000Z"}` {
+		t.Fatalf("tagged value = %s", dbs[0].ObjectStores[0].Records[0].Value)
+	}
+}
+
+func TestHandleStateLoadRestoresSiteDataForCurrentOrigin(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.idbValue = `{"records":3,"entries":2,"registered":1}`
+	sf := &state.StateFile{
+		Name:    "offline",
+		Origins: []string{"https://app.example"},
+		IndexedDB: map[string][]state.IndexedDBDatabase{
+			"https://app.example":   {{Name: "app", Version: 1}},
+			"https://other.example": {{Name: "other", Version: 1}},
+		},
+		CacheStorage:   map[string][]state.Cache{"https://app.example": {{Name: "v1"}}},
+		ServiceWorkers: map[string][]state.ServiceWorker{"https://app.example": {{ScopeURL: "https://app.example/", ScriptURL: "https://app.example/sw.js"}}},
+	}
+	if _, err := state.Save(h.Config.StateDir, sf, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.HandleStateLoad(w, httptest.NewRequest("POST", "/state/load", strings.NewReader(`{"name":"offline","tabId":"tab1"}`)))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if got := strings.Join(b.siteOps, ","); got != "idb.import,cache.restore,sw.register" {
+		t.Fatalf("site data ops = %s", got)
+	}
+	for _, expr := range b.evaluateExprs {
+		if strings.Contains(expr, `"other"`) {
+			t.Fatal("restored IndexedDB of another origin")
+		}
+	}
+	var resp map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["indexedDBRecordsRestored"] != float64(3) || resp["cacheEntriesRestored"] != float64(2) || resp["serviceWorkersRegistered"] != float64(1) {
+		t.Fatalf("resp = %v", resp)
+	}
+}
+
+func TestHandleStateSaveCapturesExtraOrigins(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.visitedOrigins = []string{"https://sso.example", "https://empty.example"}
+	// Helper targets answer the local storage script for the origin they were
+	// opened on; only the SSO origin has anything stored.
+	page := b.evaluateFn
+	b.evaluateFn = func(expression string, result any) error {
+		if strings.Contains(expression, "localStorage.key") && !strings.Contains(expression, "sessionStorage") {
+			value := `{}`
+			if b.originTargets[len(b.originTargets)-1] == "https://sso.example" {
+				value = `{"token":"t"}`
+			}
+			*result.(*string) = value
+			return nil
+		}
+		return page(expression, result)
+	}
+
+	body := `{"name":"multi","tabId":"tab1","origins":["https://APP.example/login","https://idp.example"],"visitedOrigins":true}`
+	w := httptest.NewRecorder()
+	h.HandleStateSave(w, httptest.NewRequest("POST", "/state/save", strings.NewReader(body)))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	sf, err := state.Load(state.ResolvePath(h.Config.StateDir, "multi"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tab's own origin is captured in the tab, not again through a helper.
+	if got := strings.Join(b.originTargets, ","); got != "https://empty.example,https://idp.example,https://sso.example" {
+		t.Fatalf("helper targets = %s", got)
+	}
+	if sf.Storage["https://sso.example"].Local["token"] != "t" {
+		t.Fatalf("sso storage = %+v", sf.Storage["https://sso.example"])
+	}
+	if _, ok := sf.Storage["https://idp.example"]; !ok {
+		t.Fatal("explicit origin with empty storage should still be saved")
+	}
+	if _, ok := sf.Storage["https://empty.example"]; ok {
+		t.Fatal("visited origin with empty storage should be skipped")
+	}
+}
+
+func TestHandleStateOriginsLimitedToTokenDomains(t *testing.T) {
+	h, b := newSiteDataHandler(t, true)
+	b.visitedOrigins = []string{"https://sso.example", "https://bank.example"}
+	token := &apitoken.Token{Scope: apitoken.Scope{AllowedDomains: []string{"app.example", "sso.example"}}}
+
+	body := `{"name":"scoped","tabId":"tab1","origins":["https://bank.example/login"],"visitedOrigins":true}`
+	req := apitoken.WithToken(httptest.NewRequest("POST", "/state/save", strings.NewReader(body)), token)
+	w := httptest.NewRecorder()
+	h.HandleStateSave(w, req)
+	if w.Code != 200 {
+		t.Fatalf("save: status %d: %s", w.Code, w.Body.String())
+	}
+	if got := strings.Join(b.originTargets, ","); got != "https://sso.example" {
+		t.Fatalf("helper targets = %s, want only the token's domains", got)
+	}
+	sf, err := state.Load(state.ResolvePath(h.Config.StateDir, "scoped"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sf.Storage["https://bank.example"]; ok {
+		t.Fatal("saved storage of an origin outside the token's domains")
+	}
+	if errs, _ := sf.Metadata["storageErrors"].(map[string]any); errs["https://bank.example"] == nil {
+		t.Fatalf("storageErrors = %v", sf.Metadata["storageErrors"])
+	}
+
+	b.originTargets = nil
+	sf = &state.StateFile{
+		Name:    "seed",
+		Cookies: []state.Cookie{{Name: "sid", Value: "x", Domain: ".bank.example", Path: "/"}},
+		Storage: map[string]state.OriginStorage{"https://bank.example": {Local: map[string]string{"k": "v"}}},
+	}
+	if _, err := state.Save(h.Config.StateDir, sf, ""); err != nil {
+		t.Fatal(err)
+	}
+	req = apitoken.WithToken(httptest.NewRequest("POST", "/state/load", strings.NewReader(`{"name":"seed","tabId":"tab1"}`)), token)
+	w = httptest.NewRecorder()
+	h.HandleStateLoad(w, req)
+	if w.Code != 200 {
+		t.Fatalf("load: status %d: %s", w.Code, w.Body.String())
+	}
+	if len(b.originTargets) != 0 {
+		t.Fatalf("seeded storage outside the token's domains: %v", b.originTargets)
+	}
+	if resp := decodeBody(t, w); resp["cookiesRestored"] != float64(0) {
+		t.Fatalf("cookiesRestored = %v, want 0", resp["cookiesRestored"])
+	}
+}
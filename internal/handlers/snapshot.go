@@ -109,6 +109,10 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 	var url, title string
 	var scopeNodeID int64
 
+	// Arm before reading the tree so mutations during the read are counted
+	// against the refs handed out.
+	epoch := h.armRefCacheEpoch(tCtx)
+
 	frameScope := h.selectorFrameID(resolvedTabID)
 	if frameScope != "" || selector != "" {
 		// Frame-scoped or selector-scoped: inline AX tree fetch with scoping.
@@ -194,9 +198,10 @@ func (h *Handlers) HandleSnapshot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.Bridge.SetRefCache(resolvedTabID, &bridge.RefCache{
-		Refs:    refs,
-		Targets: bridge.RefTargetsFromNodes(flat),
-		Nodes:   flat,
+		Refs:     refs,
+		Targets:  bridge.RefTargetsFromNodes(flat),
+		Nodes:    flat,
+		DomEpoch: epoch,
 	})
 
 	h.recordResolvedURL(r, url)
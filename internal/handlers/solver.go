@@ -78,8 +78,9 @@ func (h *Handlers) HandleSolve(w http.ResponseWriter, r *http.Request) {
 	}
 
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, 423, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return
 	}
 
@@ -93,7 +94,7 @@ func (h *Handlers) HandleSolve(w http.ResponseWriter, r *http.Request) {
 	}
 	h.recordActivity(r, activity.Update{Action: action, TabID: resolvedTabID})
 
-	page, executor, err := adapters.NewFromBridge(h.Bridge, resolvedTabID)
+	page, executor, err := adapters.NewFromBridge(h.Bridge, resolvedTabID, h.autoSolverNavigateGuard(requestTokenDomains(r)))
 	if err != nil {
 		httpx.Error(w, 500, fmt.Errorf("resolve solve tab: %w", err))
 		return
@@ -162,6 +163,9 @@ func (h *Handlers) HandleSolve(w http.ResponseWriter, r *http.Request) {
 		"attempts":      result.Attempts,
 		"title":         title,
 	}
+	if result.LLMUsage != nil {
+		resp["llmUsage"] = result.LLMUsage
+	}
 
 	// If a challenge was detected but the solver couldn't resolve it, flip the
 	// tab into paused_handoff so subsequent actions block and the caller can
@@ -208,12 +212,16 @@ func (h *Handlers) HandleAutoSolverConfig(w http.ResponseWriter, r *http.Request
 	triggerOnNavigate := true
 	triggerOnAction := true
 	llmProvider := ""
+	llmModel := ""
+	llmBaseURL := ""
 
 	if h != nil && h.Config != nil {
 		autoTrigger = h.Config.AutoSolver.AutoTrigger
 		triggerOnNavigate = h.Config.AutoSolver.TriggerOnNavigate
 		triggerOnAction = h.Config.AutoSolver.TriggerOnAction
 		llmProvider = h.Config.AutoSolver.LLMProvider
+		llmModel = h.Config.AutoSolver.LLM.Model
+		llmBaseURL = h.Config.AutoSolver.LLM.BaseURL
 	}
 
 	httpx.JSON(w, 200, map[string]any{
@@ -227,6 +235,8 @@ func (h *Handlers) HandleAutoSolverConfig(w http.ResponseWriter, r *http.Request
 		"retryMaxDelayMs":   int(cfg.RetryMaxDelay / time.Millisecond),
 		"solvers":           h.availableAutoSolverNames(),
 		"llmProvider":       llmProvider,
+		"llmModel":          llmModel,
+		"llmBaseUrl":        llmBaseURL,
 		"llmFallback":       cfg.LLMFallback,
 	})
 }
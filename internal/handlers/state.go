@@ -61,6 +61,7 @@ type stateSaveRequest struct {
 	Encrypt  bool                   `json:"encrypt"`
 	TabID    string                 `json:"tabId"`
 	Metadata map[string]interface{} `json:"metadata"`
+	stateOriginOptions
 }
 
 // HandleStateSave captures the current browser state and writes it to disk.
@@ -92,8 +93,13 @@ func (h *Handlers) HandleStateSave(w http.ResponseWriter, r *http.Request) {
 	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
 		return
 	}
+	extra, err := req.resolve(ctx, h.Bridge, r)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
-	captured, err := h.captureBrowserState(ctx, resolvedTabID, req.Metadata)
+	captured, err := h.captureBrowserState(ctx, r, resolvedTabID, req.Metadata, extra)
 	if err != nil {
 		httpx.Error(w, 500, fmt.Errorf("capture state: %w", err))
 		return
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
@@ -22,6 +23,10 @@ type currentBrowserStateResponse struct {
 	Cookies  []state.Cookie                 `json:"cookies"`
 	Storage  map[string]state.OriginStorage `json:"storage"`
 	Metadata map[string]interface{}         `json:"metadata,omitempty"`
+
+	ServiceWorkers map[string][]state.ServiceWorker     `json:"serviceWorkers,omitempty"`
+	CacheStorage   map[string][]state.Cache             `json:"cacheStorage,omitempty"`
+	IndexedDB      map[string][]state.IndexedDBDatabase `json:"indexedDB,omitempty"`
 }
 
 type capturedBrowserState struct {
@@ -37,8 +42,16 @@ func (h *Handlers) HandleStateCurrent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tabID := r.URL.Query().Get("tabId")
-	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	q := r.URL.Query()
+	opts := stateOriginOptions{Visited: q.Get("visitedOrigins") == "true"}
+	for _, v := range q["origins"] {
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				opts.Origins = append(opts.Origins, origin)
+			}
+		}
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, q.Get("tabId"))
 	if err != nil {
 		WriteTabContextError(w, err, 404)
 		return
@@ -46,8 +59,13 @@ func (h *Handlers) HandleStateCurrent(w http.ResponseWriter, r *http.Request) {
 	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
 		return
 	}
+	extra, err := opts.resolve(ctx, h.Bridge, r)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
-	captured, err := h.captureBrowserState(ctx, resolvedTabID, nil)
+	captured, err := h.captureBrowserState(ctx, r, resolvedTabID, nil, extra)
 	if err != nil {
 		httpx.Error(w, 500, fmt.Errorf("capture state: %w", err))
 		return
@@ -61,10 +79,17 @@ func (h *Handlers) HandleStateCurrent(w http.ResponseWriter, r *http.Request) {
 		Cookies:  captured.file.Cookies,
 		Storage:  captured.file.Storage,
 		Metadata: captured.file.Metadata,
+
+		ServiceWorkers: captured.file.ServiceWorkers,
+		CacheStorage:   captured.file.CacheStorage,
+		IndexedDB:      captured.file.IndexedDB,
 	})
 }
 
-// HandleStateLoad reads a state file and restores cookies and storage.
+// HandleStateLoad reads a state file and restores cookies and storage, plus
+// the IndexedDB, Cache Storage and service workers saved for the tab's
+// current origin. Local storage of other origins is seeded through hidden
+// helper targets.
 func (h *Handlers) HandleStateLoad(w http.ResponseWriter, r *http.Request) {
 	if !h.ensureStateExportEnabled(w) {
 		return
@@ -112,26 +137,19 @@ func (h *Handlers) HandleStateLoad(w http.ResponseWriter, r *http.Request) {
 	tCtx, tCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer tCancel()
 
-	cookiesRestored := 0
-	for _, c := range sf.Cookies {
-		if err := h.Bridge.SetRawCookie(tCtx, bridge.RawSetCookieParams{
-			Name:     c.Name,
-			Value:    c.Value,
-			Domain:   c.Domain,
-			Path:     c.Path,
-			Secure:   c.Secure,
-			HTTPOnly: c.HTTPOnly,
-			SameSite: c.SameSite,
-		}); err == nil {
-			cookiesRestored++
-		}
+	cookiesRestored := h.restoreCookies(tCtx, tokenDomainCookies(r, sf.Cookies))
+
+	pageOrigin, err := h.pageOrigin(tCtx)
+	if err != nil {
+		httpx.Error(w, 500, fmt.Errorf("page origin: %w", err))
+		return
 	}
+	storageRestored, seeded, storageErrors := h.restoreStorage(tCtx, r, sf, pageOrigin)
 
-	storageRestored := 0
-	for _, originStorage := range sf.Storage {
-		if n, err := h.restoreOriginStorage(tCtx, originStorage); err == nil {
-			storageRestored += n
-		}
+	var siteData *siteDataRestore
+	if hasSiteData(sf, pageOrigin) {
+		res := h.restoreSiteData(tCtx, sf, pageOrigin)
+		siteData = &res
 	}
 
 	slog.Info("state loaded",
@@ -139,16 +157,66 @@ func (h *Handlers) HandleStateLoad(w http.ResponseWriter, r *http.Request) {
 		"path", path,
 		"cookiesRestored", cookiesRestored,
 		"storageItemsRestored", storageRestored,
+		"originsSeeded", len(seeded),
+		"siteDataRestored", siteData != nil,
 		"tabId", resolvedTabID,
 		"remoteAddr", r.RemoteAddr,
 	)
 
-	httpx.JSON(w, 200, map[string]any{
+	resp := map[string]any{
 		"name":                 req.Name,
 		"cookiesRestored":      cookiesRestored,
 		"storageItemsRestored": storageRestored,
 		"origins":              sf.Origins,
-	})
+		"originsSeeded":        seeded,
+	}
+	if len(storageErrors) > 0 {
+		resp["storageErrors"] = storageErrors
+	}
+	if siteData != nil {
+		resp["indexedDBRecordsRestored"] = siteData.IndexedDBRecords
+		resp["cacheEntriesRestored"] = siteData.CacheEntries
+		resp["serviceWorkersRegistered"] = siteData.ServiceWorkers
+		if len(siteData.Errors) > 0 {
+			resp["siteDataErrors"] = siteData.Errors
+		}
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// tokenDomainCookies drops the cookies whose domain is outside the
+// request's API token domains.
+func tokenDomainCookies(r *http.Request, cookies []state.Cookie) []state.Cookie {
+	if len(requestTokenDomains(r)) == 0 {
+		return cookies
+	}
+	out := make([]state.Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		if tokenDomainAllows(r, "https://"+strings.TrimPrefix(c.Domain, ".")+"/") {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// restoreCookies sets cookies in the tab's browser context and returns how
+// many were accepted.
+func (h *Handlers) restoreCookies(ctx context.Context, cookies []state.Cookie) int {
+	n := 0
+	for _, c := range cookies {
+		if err := h.Bridge.SetRawCookie(ctx, bridge.RawSetCookieParams{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: c.SameSite,
+		}); err == nil {
+			n++
+		}
+	}
+	return n
 }
 
 // restoreOriginStorage restores one origin's local and session storage in a single
@@ -171,7 +239,9 @@ func (h *Handlers) restoreOriginStorage(ctx context.Context, originStorage state
 	return n, nil
 }
 
-func (h *Handlers) captureBrowserState(ctx context.Context, resolvedTabID string, extraMetadata map[string]interface{}) (*capturedBrowserState, error) {
+// captureBrowserState captures cookies, the storage and site data of the
+// tab's origin, and the local storage of the extra origins.
+func (h *Handlers) captureBrowserState(ctx context.Context, r *http.Request, resolvedTabID string, extraMetadata map[string]interface{}, extra resolvedStateOrigins) (*capturedBrowserState, error) {
 	tCtx, tCancel := context.WithTimeout(ctx, 30*time.Second)
 	defer tCancel()
 
@@ -179,7 +249,94 @@ func (h *Handlers) captureBrowserState(ctx context.Context, resolvedTabID string
 	if err != nil {
 		return nil, fmt.Errorf("get cookies: %w", err)
 	}
+	storageResult, err := h.readTabStorage(tCtx)
+	if err != nil {
+		return nil, err
+	}
+	stateCookies := stateCookiesFromRaw(cookies)
+
+	origins := []string{}
+	storageMap := map[string]state.OriginStorage{}
+	if storageResult.Origin != "" {
+		origins = append(origins, storageResult.Origin)
+		storageMap[storageResult.Origin] = state.OriginStorage{
+			Local:   storageResult.Local,
+			Session: storageResult.Session,
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"url":       storageResult.URL,
+		"title":     storageResult.Title,
+		"origin":    storageResult.Origin,
+		"userAgent": storageResult.UserAgent,
+	}
+	if storageResult.Error != "" {
+		metadata["storageError"] = storageResult.Error
+	}
 
+	file := &state.StateFile{
+		SavedAt:  time.Now(),
+		Origins:  origins,
+		Cookies:  stateCookies,
+		Storage:  storageMap,
+		Metadata: metadata,
+	}
+	if storageResult.Origin != "" && storageResult.Origin != "null" {
+		siteData := h.captureSiteData(tCtx, resolvedTabID, storageResult.Origin)
+		if len(siteData.serviceWorkers) > 0 {
+			file.ServiceWorkers = map[string][]state.ServiceWorker{storageResult.Origin: siteData.serviceWorkers}
+		}
+		if len(siteData.caches) > 0 {
+			file.CacheStorage = map[string][]state.Cache{storageResult.Origin: siteData.caches}
+		}
+		if len(siteData.indexedDB) > 0 {
+			file.IndexedDB = map[string][]state.IndexedDBDatabase{storageResult.Origin: siteData.indexedDB}
+		}
+		if siteData.truncated {
+			metadata["siteDataTruncated"] = true
+		}
+		if len(siteData.errors) > 0 {
+			metadata["siteDataErrors"] = siteData.errors
+		}
+	}
+	if len(extra.origins) > 0 {
+		h.captureExtraOrigins(ctx, r, file, extra)
+	}
+	for k, v := range extraMetadata {
+		metadata[k] = v
+	}
+
+	return &capturedBrowserState{
+		tabID: resolvedTabID,
+		url:   storageResult.URL,
+		title: storageResult.Title,
+		file:  file,
+	}, nil
+}
+
+func firstOrigin(origins []string) string {
+	if len(origins) == 0 {
+		return ""
+	}
+	return origins[0]
+}
+
+// tabStorage is the storage of a tab's origin plus what the page reports
+// about itself.
+type tabStorage struct {
+	Local     map[string]string `json:"local"`
+	Session   map[string]string `json:"session"`
+	URL       string            `json:"url"`
+	Title     string            `json:"title"`
+	Origin    string            `json:"origin"`
+	UserAgent string            `json:"userAgent"`
+	Error     string            `json:"error"`
+}
+
+// readTabStorage reads the local and session storage of the tab's origin.
+// Storage the page denies access to comes back empty with Error set.
+func (h *Handlers) readTabStorage(ctx context.Context) (*tabStorage, error) {
 	storageScript := `
 		(function() {
 			try {
@@ -216,23 +373,24 @@ func (h *Handlers) captureBrowserState(ctx context.Context, resolvedTabID string
 	`
 
 	var storageJSON string
-	if err := h.Bridge.Evaluate(tCtx, storageScript, &storageJSON, bridge.EvalOpts{}); err != nil {
+	if err := h.Bridge.Evaluate(ctx, storageScript, &storageJSON, bridge.EvalOpts{}); err != nil {
 		return nil, fmt.Errorf("evaluate storage: %w", err)
 	}
 
-	var storageResult struct {
-		Local     map[string]string `json:"local"`
-		Session   map[string]string `json:"session"`
-		URL       string            `json:"url"`
-		Title     string            `json:"title"`
-		Origin    string            `json:"origin"`
-		UserAgent string            `json:"userAgent"`
-		Error     string            `json:"error"`
-	}
+	var storageResult tabStorage
 	if err := json.Unmarshal([]byte(storageJSON), &storageResult); err != nil {
 		return nil, fmt.Errorf("parse storage result: %w", err)
 	}
+	if storageResult.Local == nil {
+		storageResult.Local = map[string]string{}
+	}
+	if storageResult.Session == nil {
+		storageResult.Session = map[string]string{}
+	}
+	return &storageResult, nil
+}
 
+func stateCookiesFromRaw(cookies []bridge.RawCookie) []state.Cookie {
 	stateCookies := make([]state.Cookie, len(cookies))
 	for i, c := range cookies {
 		stateCookies[i] = state.Cookie{
@@ -246,53 +404,5 @@ func (h *Handlers) captureBrowserState(ctx context.Context, resolvedTabID string
 			Expires:  c.Expires,
 		}
 	}
-	if storageResult.Local == nil {
-		storageResult.Local = map[string]string{}
-	}
-	if storageResult.Session == nil {
-		storageResult.Session = map[string]string{}
-	}
-
-	origins := []string{}
-	storageMap := map[string]state.OriginStorage{}
-	if storageResult.Origin != "" {
-		origins = append(origins, storageResult.Origin)
-		storageMap[storageResult.Origin] = state.OriginStorage{
-			Local:   storageResult.Local,
-			Session: storageResult.Session,
-		}
-	}
-
-	metadata := map[string]interface{}{
-		"url":       storageResult.URL,
-		"title":     storageResult.Title,
-		"origin":    storageResult.Origin,
-		"userAgent": storageResult.UserAgent,
-	}
-	if storageResult.Error != "" {
-		metadata["storageError"] = storageResult.Error
-	}
-	for k, v := range extraMetadata {
-		metadata[k] = v
-	}
-
-	return &capturedBrowserState{
-		tabID: resolvedTabID,
-		url:   storageResult.URL,
-		title: storageResult.Title,
-		file: &state.StateFile{
-			SavedAt:  time.Now(),
-			Origins:  origins,
-			Cookies:  stateCookies,
-			Storage:  storageMap,
-			Metadata: metadata,
-		},
-	}, nil
-}
-
-func firstOrigin(origins []string) string {
-	if len(origins) == 0 {
-		return ""
-	}
-	return origins[0]
+	return stateCookies
 }
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/state"
+)
+
+// Saved state can carry local storage for origins other than the tab's own,
+// e.g. an SSO provider that took part in a login. Those origins are read and
+// written in hidden helper targets (Bridge.WithOriginTarget) in the tab's
+// browser context, which share local storage with the context's tabs but not
+// session storage, so only the tab's own origin keeps its session storage.
+
+const (
+	// maxStateOrigins caps how many extra origins one capture visits.
+	maxStateOrigins = 50
+	// stateOriginTimeout bounds the helper target work for one origin.
+	stateOriginTimeout = 10 * time.Second
+)
+
+// stateOriginOptions selects the extra origins a capture covers.
+type stateOriginOptions struct {
+	Origins []string `json:"origins,omitempty"`
+	// Visited adds every origin loaded in this browser session.
+	Visited bool `json:"visitedOrigins,omitempty"`
+}
+
+// normalizeStateOrigin turns an http(s) URL or origin into scheme://host[:port].
+func normalizeStateOrigin(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid origin %q: want http(s)://host[:port]", raw)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// resolvedStateOrigins is the outcome of stateOriginOptions.resolve.
+type resolvedStateOrigins struct {
+	origins  []string
+	explicit map[string]bool
+}
+
+// resolve returns the extra origins to capture, normalized, deduplicated and
+// sorted. Explicit origins beyond maxStateOrigins are an error; visited
+// origins are silently limited to what is left, and to the request's API
+// token domains. Visited origins are those of the browser context of the
+// tab of ctx.
+func (o stateOriginOptions) resolve(ctx context.Context, b bridge.BridgeAPI, r *http.Request) (resolvedStateOrigins, error) {
+	if len(o.Origins) > maxStateOrigins {
+		return resolvedStateOrigins{}, fmt.Errorf("too many origins: %d (max %d)", len(o.Origins), maxStateOrigins)
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, raw := range o.Origins {
+		origin, err := normalizeStateOrigin(raw)
+		if err != nil {
+			return resolvedStateOrigins{}, err
+		}
+		if !seen[origin] {
+			seen[origin] = true
+			out = append(out, origin)
+		}
+	}
+	explicit := make(map[string]bool, len(seen))
+	for origin := range seen {
+		explicit[origin] = true
+	}
+	if o.Visited {
+		for _, origin := range b.VisitedOrigins(ctx) {
+			if len(out) >= maxStateOrigins {
+				break
+			}
+			if !seen[origin] && tokenDomainAllows(r, origin+"/") {
+				seen[origin] = true
+				out = append(out, origin)
+			}
+		}
+	}
+	sort.Strings(out)
+	return resolvedStateOrigins{origins: out, explicit: explicit}, nil
+}
+
+// originBlocked reports why the API token domains or the IDPI domain policy
+// forbid touching origin, or "".
+func (h *Handlers) originBlocked(r *http.Request, origin string) string {
+	if !tokenDomainAllows(r, origin+"/") {
+		return "outside the API token's allowed domains"
+	}
+	if !h.currentTabDomainPolicyEnabled() {
+		return ""
+	}
+	if p := bridge.EvaluateTabPolicy(origin+"/", h.Config.IDPI, h.Config.AllowedDomains); p.Blocked {
+		return p.Reason
+	}
+	return ""
+}
+
+// captureOriginLocalStorage reads the local storage of origin through a
+// helper target.
+func (h *Handlers) captureOriginLocalStorage(ctx context.Context, r *http.Request, origin string) (map[string]string, error) {
+	if reason := h.originBlocked(r, origin); reason != "" {
+		return nil, fmt.Errorf("blocked by domain policy: %s", reason)
+	}
+	oCtx, cancel := context.WithTimeout(ctx, stateOriginTimeout)
+	defer cancel()
+
+	local := map[string]string{}
+	err := h.Bridge.WithOriginTarget(oCtx, origin, func(tCtx context.Context) error {
+		var raw string
+		script := `(function(){
+			var out = {};
+			for (var i = 0; i < localStorage.length; i++) {
+				var k = localStorage.key(i);
+				out[k] = localStorage.getItem(k);
+			}
+			return JSON.stringify(out);
+		})()`
+		if err := h.Bridge.Evaluate(tCtx, script, &raw, bridge.EvalOpts{}); err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(raw), &local)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return local, nil
+}
+
+// captureExtraOrigins adds the local storage of origins to file, skipping
+// the tab's own origin, which the caller already captured in full. Visited
+// origins with nothing stored are left out; failures end up in
+// metadata.storageErrors.
+func (h *Handlers) captureExtraOrigins(ctx context.Context, r *http.Request, file *state.StateFile, extra resolvedStateOrigins) {
+	errs := map[string]string{}
+	for _, origin := range extra.origins {
+		if _, done := file.Storage[origin]; done {
+			continue
+		}
+		local, err := h.captureOriginLocalStorage(ctx, r, origin)
+		if err != nil {
+			errs[origin] = err.Error()
+			continue
+		}
+		if len(local) == 0 && !extra.explicit[origin] {
+			continue
+		}
+		file.Storage[origin] = state.OriginStorage{Local: local, Session: map[string]string{}}
+		file.Origins = append(file.Origins, origin)
+	}
+	if len(errs) > 0 {
+		file.Metadata["storageErrors"] = errs
+	}
+}
+
+// restoreStorage restores every origin's storage in sf. The tab's own origin
+// is restored in the tab itself; other origins get their local storage back
+// through helper targets, since session storage cannot be moved between tabs.
+// It returns the items restored, the origins seeded through helpers, and
+// per-origin errors.
+func (h *Handlers) restoreStorage(ctx context.Context, r *http.Request, sf *state.StateFile, pageOrigin string) (int, []string, map[string]string) {
+	restored := 0
+	seeded := []string{}
+	errs := map[string]string{}
+
+	origins := make([]string, 0, len(sf.Storage))
+	for origin := range sf.Storage {
+		origins = append(origins, origin)
+	}
+	sort.Strings(origins)
+
+	for _, origin := range origins {
+		stored := sf.Storage[origin]
+		if origin == pageOrigin {
+			if n, err := h.restoreOriginStorage(ctx, stored); err == nil {
+				restored += n
+			} else {
+				errs[origin] = err.Error()
+			}
+			continue
+		}
+		if len(stored.Local) == 0 {
+			continue
+		}
+		if _, err := normalizeStateOrigin(origin); err != nil {
+			errs[origin] = err.Error()
+			continue
+		}
+		if reason := h.originBlocked(r, origin); reason != "" {
+			errs[origin] = "blocked by domain policy: " + reason
+			continue
+		}
+		oCtx, cancel := context.WithTimeout(ctx, stateOriginTimeout)
+		err := h.Bridge.WithOriginTarget(oCtx, origin, func(tCtx context.Context) error {
+			n, err := h.restoreOriginStorage(tCtx, state.OriginStorage{Local: stored.Local})
+			restored += n
+			return err
+		})
+		cancel()
+		if err != nil {
+			errs[origin] = err.Error()
+			continue
+		}
+		seeded = append(seeded, origin)
+	}
+	return restored, seeded, errs
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/state"
+)
+
+// Saved state carries the service workers, Cache Storage and IndexedDB of the
+// captured tab's origin. Bodies and records count against these budgets so a
+// large offline cache cannot blow up a state file; whatever does not fit is
+// left out and reported under metadata.siteDataTruncated.
+const (
+	maxCacheStorageStateBytes = 16 << 20
+	maxIndexedDBExportBytes   = 16 << 20
+)
+
+// siteDataCapture is the site data captured for one origin.
+type siteDataCapture struct {
+	serviceWorkers []state.ServiceWorker
+	caches         []state.Cache
+	indexedDB      []state.IndexedDBDatabase
+	truncated      bool
+	errors         []string
+}
+
+// captureSiteData captures the service workers, caches and IndexedDB
+// databases of origin from the tab of ctx. Failures are collected rather than
+// returned: cookies and storage are still worth saving without them.
+func (h *Handlers) captureSiteData(ctx context.Context, tabID, origin string) siteDataCapture {
+	var c siteDataCapture
+
+	if workers, err := h.Bridge.ServiceWorkers(ctx, tabID); err != nil {
+		c.errors = append(c.errors, fmt.Sprintf("service workers: %v", err))
+	} else {
+		for _, sw := range filterServiceWorkers(workers, origin) {
+			if script := serviceWorkerScript(sw); script != "" {
+				c.serviceWorkers = append(c.serviceWorkers, state.ServiceWorker{ScopeURL: sw.ScopeURL, ScriptURL: script})
+			}
+		}
+	}
+
+	caches, truncated, err := h.captureCacheStorage(ctx, origin)
+	if err != nil {
+		c.errors = append(c.errors, fmt.Sprintf("cache storage: %v", err))
+	}
+	c.caches = caches
+	c.truncated = truncated
+
+	var exported struct {
+		Databases []state.IndexedDBDatabase `json:"databases"`
+		Truncated bool                      `json:"truncated"`
+	}
+	if _, err := h.runSiteDataOp(ctx, "idb.export", map[string]any{"maxBytes": maxIndexedDBExportBytes}, &exported); err != nil {
+		c.errors = append(c.errors, fmt.Sprintf("indexeddb: %v", err))
+	}
+	c.indexedDB = exported.Databases
+	c.truncated = c.truncated || exported.Truncated
+	return c
+}
+
+// serviceWorkerScript returns the script of the registration's active
+// version, falling back to its newest one.
+func serviceWorkerScript(sw bridge.ServiceWorkerInfo) string {
+	script := ""
+	for _, v := range sw.Versions {
+		if v.Status == "activated" {
+			return v.ScriptURL
+		}
+		script = v.ScriptURL
+	}
+	return script
+}
+
+// captureCacheStorage reads every GET entry of every cache of origin, bodies
+// included, until maxCacheStorageStateBytes is used up.
+func (h *Handlers) captureCacheStorage(ctx context.Context, origin string) ([]state.Cache, bool, error) {
+	caches, err := h.Bridge.CacheStorageCaches(ctx, origin)
+	if err != nil {
+		return nil, false, err
+	}
+	budget := maxCacheStorageStateBytes
+	truncated := false
+	var out []state.Cache
+	for _, cache := range caches {
+		entries, _, err := h.Bridge.CacheStorageEntries(ctx, string(cache.CacheID), 0, 0, "")
+		if err != nil {
+			return out, truncated, err
+		}
+		saved := state.Cache{Name: cache.CacheName, Entries: []state.CacheEntry{}}
+		for _, e := range entries {
+			if e.RequestMethod != "" && e.RequestMethod != "GET" {
+				continue
+			}
+			body, err := h.Bridge.CacheStorageResponse(ctx, string(cache.CacheID), e.RequestURL)
+			if err != nil {
+				continue
+			}
+			if len(body) > budget {
+				truncated = true
+				continue
+			}
+			budget -= len(body)
+			entry := state.CacheEntry{
+				RequestURL:    e.RequestURL,
+				RequestMethod: e.RequestMethod,
+				Status:        int(e.ResponseStatus),
+				StatusText:    e.ResponseStatusText,
+				Body:          base64.StdEncoding.EncodeToString(body),
+			}
+			for _, hdr := range e.ResponseHeaders {
+				entry.Headers = append(entry.Headers, state.Header{Name: hdr.Name, Value: hdr.Value})
+			}
+			saved.Entries = append(saved.Entries, entry)
+		}
+		out = append(out, saved)
+	}
+	return out, truncated, nil
+}
+
+// siteDataRestore counts what restoreSiteData put back.
+type siteDataRestore struct {
+	IndexedDBRecords int      `json:"indexedDBRecordsRestored"`
+	CacheEntries     int      `json:"cacheEntriesRestored"`
+	ServiceWorkers   int      `json:"serviceWorkersRegistered"`
+	Errors           []string `json:"siteDataErrors,omitempty"`
+}
+
+// hasSiteData reports whether sf carries any site data for origin.
+func hasSiteData(sf *state.StateFile, origin string) bool {
+	return len(sf.IndexedDB[origin]) > 0 || len(sf.CacheStorage[origin]) > 0 || len(sf.ServiceWorkers[origin]) > 0
+}
+
+// restoreSiteData restores the saved site data of origin into the tab of
+// ctx, which must already be on that origin. IndexedDB and caches go first
+// so a re-registered service worker finds them in place.
+func (h *Handlers) restoreSiteData(ctx context.Context, sf *state.StateFile, origin string) siteDataRestore {
+	var res siteDataRestore
+	if dbs := sf.IndexedDB[origin]; len(dbs) > 0 {
+		var out struct {
+			Records int      `json:"records"`
+			Errors  []string `json:"errors"`
+		}
+		if _, err := h.runSiteDataOp(ctx, "idb.import", map[string]any{"databases": dbs}, &out); err != nil {
+			res.Errors = append(res.Errors, err.Error())
+		}
+		res.IndexedDBRecords = out.Records
+		res.Errors = append(res.Errors, out.Errors...)
+	}
+	if caches := sf.CacheStorage[origin]; len(caches) > 0 {
+		var out struct {
+			Entries int      `json:"entries"`
+			Errors  []string `json:"errors"`
+		}
+		if _, err := h.runSiteDataOp(ctx, "cache.restore", map[string]any{"caches": caches}, &out); err != nil {
+			res.Errors = append(res.Errors, err.Error())
+		}
+		res.CacheEntries = out.Entries
+		res.Errors = append(res.Errors, out.Errors...)
+	}
+	if workers := sf.ServiceWorkers[origin]; len(workers) > 0 {
+		var out struct {
+			Registered int      `json:"registered"`
+			Errors     []string `json:"errors"`
+		}
+		if _, err := h.runSiteDataOp(ctx, "sw.register", map[string]any{"workers": workers}, &out); err != nil {
+			res.Errors = append(res.Errors, err.Error())
+		}
+		res.ServiceWorkers = out.Registered
+		res.Errors = append(res.Errors, out.Errors...)
+	}
+	return res
+}
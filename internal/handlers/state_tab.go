@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -21,6 +22,10 @@ type tabStateResponse struct {
 	Dialog        interface{}  `json:"dialog,omitempty"`
 	Load          tabLoadState `json:"load"`
 	Actionability string       `json:"actionability"`
+	// PermissionRequests lists the permission prompts the current document
+	// raised; a pending one means the page is waiting on the user.
+	PermissionRequests []bridge.PermissionRequest `json:"permissionRequests,omitempty"`
+	PermissionPending  bool                       `json:"permissionPending"`
 }
 
 // HandleTabState returns lightweight tab/page state signals for agent workflows.
@@ -40,6 +45,9 @@ func (h *Handlers) HandleTabState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	_, resolvedTabID, err := h.Bridge.TabContext(tabID)
+	if err == nil {
+		err = h.checkTabSession(r, resolvedTabID)
+	}
 	if err != nil {
 		WriteTabContextError(w, err, http.StatusNotFound)
 		return
@@ -70,6 +78,19 @@ func (h *Handlers) HandleTabState(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if requests, err := h.Bridge.PermissionRequests(resolvedTabID); err == nil && len(requests) > 0 {
+		resp.PermissionRequests = requests
+		for _, req := range requests {
+			if req.State == "pending" {
+				resp.PermissionPending = true
+				if resp.Actionability == "ready" {
+					resp.Actionability = "caution"
+				}
+				break
+			}
+		}
+	}
+
 	if bridgeWithState, ok := h.Bridge.(interface {
 		GetDocumentReadyState(string) (string, error)
 		IsNetworkIdle(string) (bool, bool)
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -35,8 +37,9 @@ func TestHandleTabState(t *testing.T) {
 }
 
 // TestHandleStateLoadBatchesStorage verifies HandleStateLoad restores each origin's
-// storage in a single Evaluate call (one per origin, not one per key) and that the
-// per-origin item count returned by the in-page script propagates to the response.
+// storage in a single Evaluate call (one per origin, not one per key): the tab's own
+// origin in the tab, any other origin in a helper target. The per-origin item count
+// returned by the in-page script propagates to the response.
 func TestHandleStateLoadBatchesStorage(t *testing.T) {
 	dir := t.TempDir()
 	sf := &state.StateFile{
@@ -59,11 +62,15 @@ func TestHandleStateLoadBatchesStorage(t *testing.T) {
 
 	// The mock cannot run JS, so it stands in for the in-page script's return value
 	// by counting the setItem call-sites in the bulk script (two per origin: the local
-	// and session loops) and propagating that through the result pointer.
+	// and session loops) and propagating that through the result pointer. The tab
+	// reports https://a.example as its origin.
 	mb := &mockBridge{
 		evaluateFn: func(expression string, result any) error {
-			if p, ok := result.(*int); ok {
+			switch p := result.(type) {
+			case *int:
 				*p = strings.Count(expression, "setItem")
+			case *string:
+				*p = "https://a.example"
 			}
 			return nil
 		},
@@ -78,10 +85,19 @@ func TestHandleStateLoadBatchesStorage(t *testing.T) {
 		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
 	}
 
-	// One Evaluate per origin proves batching; the old per-key loop would have made
-	// one call per stored key (6 total) instead of 2.
-	if mb.evaluateCalls != len(sf.Storage) {
-		t.Fatalf("expected %d Evaluate calls (one per origin), got %d", len(sf.Storage), mb.evaluateCalls)
+	// One origin lookup plus one Evaluate per origin proves batching; the old per-key
+	// loop would have made one call per stored key (6 total) instead of 2.
+	if mb.evaluateCalls != 1+len(sf.Storage) {
+		t.Fatalf("expected %d Evaluate calls (origin + one per origin), got %d", 1+len(sf.Storage), mb.evaluateCalls)
+	}
+	if len(mb.originTargets) != 1 || mb.originTargets[0] != "https://b.example" {
+		t.Fatalf("expected only https://b.example seeded in a helper target, got %v", mb.originTargets)
+	}
+	// Session storage cannot reach the tab through a helper target.
+	for _, expr := range mb.evaluateExprs {
+		if strings.Contains(expr, `"sv2"`) {
+			t.Fatalf("session storage of another origin restored: %s", expr)
+		}
 	}
 
 	var got map[string]any
@@ -93,8 +109,42 @@ func TestHandleStateLoadBatchesStorage(t *testing.T) {
 	if got["storageItemsRestored"] != float64(2*len(sf.Storage)) {
 		t.Fatalf("expected storageItemsRestored=%d, got %v", 2*len(sf.Storage), got["storageItemsRestored"])
 	}
+	if seeded, _ := got["originsSeeded"].([]any); len(seeded) != 1 || seeded[0] != "https://b.example" {
+		t.Fatalf("expected originsSeeded=[https://b.example], got %v", got["originsSeeded"])
+	}
 	origins, ok := got["origins"].([]any)
 	if !ok || len(origins) != len(sf.Origins) {
 		t.Fatalf("expected %d origins, got %v", len(sf.Origins), got["origins"])
 	}
 }
+
+func TestHandleStateSaveRejectsInvalidOrigin(t *testing.T) {
+	h := New(&mockBridge{}, &config.RuntimeConfig{AllowStateExport: true, StateDir: t.TempDir()}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	h.HandleStateSave(w, httptest.NewRequest("POST", "/state/save", strings.NewReader(`{"origins":["file:///etc"]}`)))
+	if w.Code != 400 {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStateOriginOptionsResolve(t *testing.T) {
+	mb := &mockBridge{visitedOrigins: []string{"https://b.example", "https://a.example"}}
+	got, err := stateOriginOptions{Origins: []string{"HTTPS://A.example/path", "https://c.example:8443"}, Visited: true}.resolve(context.Background(), mb, httptest.NewRequest("GET", "/state", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got.origins, ",") != "https://a.example,https://b.example,https://c.example:8443" {
+		t.Fatalf("origins = %v", got.origins)
+	}
+	if !got.explicit["https://a.example"] || got.explicit["https://b.example"] {
+		t.Fatalf("explicit = %v", got.explicit)
+	}
+
+	many := make([]string, maxStateOrigins+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("https://%d.example", i)
+	}
+	if _, err := (stateOriginOptions{Origins: many}).resolve(context.Background(), mb, httptest.NewRequest("GET", "/state", nil)); err == nil {
+		t.Fatal("expected error above maxStateOrigins")
+	}
+}
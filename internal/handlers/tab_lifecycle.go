@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
 
@@ -16,9 +17,11 @@ const tabActionNew = "new"
 
 func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Action string `json:"action"`
-		TabID  string `json:"tabId"`
-		URL    string `json:"url"`
+		Action    string `json:"action"`
+		TabID     string `json:"tabId"`
+		URL       string `json:"url"`
+		ProxyPool string `json:"proxyPool"`
+		ProxyKey  string `json:"proxyKey"`
 	}
 	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
 		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
@@ -32,10 +35,10 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 		// solve post-steps, and {tabId,url,title,route} response. A blank/about:blank
 		// "new" stays on the lightweight create-only path.
 		if req.URL != "" && req.URL != "about:blank" {
-			h.navigateToURL(w, r, navigateRequest{URL: req.URL, NewTab: true})
+			h.navigateToURL(w, r, navigateRequest{URL: req.URL, NewTab: true, ProxyPool: req.ProxyPool, ProxyKey: req.ProxyKey})
 			return
 		}
-		h.createBlankTab(w, r)
+		h.createBlankTab(w, r, tabProxyOptions(r, req.ProxyPool, req.ProxyKey))
 
 	case "focus":
 		if req.TabID == "" {
@@ -45,6 +48,9 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 		if !h.ensureBrowserOrRespond(w, h.Config) {
 			return
 		}
+		if !h.enforceTabSession(w, r, req.TabID) {
+			return
+		}
 		if err := h.Bridge.FocusTab(req.TabID); err != nil {
 			WriteTabContextError(w, err, 404)
 			return
@@ -64,14 +70,16 @@ func (h *Handlers) HandleTab(w http.ResponseWriter, r *http.Request) {
 // about:blank form of POST /tab {"action":"new"}. The URL form converges onto the
 // shared navigate pipeline (navigateToURL); this path has no URL to validate,
 // route, or navigate, so it reports {tabId,url,title} and a tab.new activity.
-func (h *Handlers) createBlankTab(w http.ResponseWriter, r *http.Request) {
+// With a proxy pool the tab opens in a browser context behind a proxy from
+// that pool, reported under "proxy".
+func (h *Handlers) createBlankTab(w http.ResponseWriter, r *http.Request, proxy bridge.TabProxyOptions) {
 	if !h.ensureBrowserOrRespond(w, h.Config) {
 		return
 	}
 
-	newTabID, ctx, _, err := h.Bridge.CreateTab("")
+	newTabID, ctx, _, err := h.createTab(r, proxy)
 	if err != nil {
-		httpx.Error(w, 500, err)
+		writeCreateTabError(w, "", err)
 		return
 	}
 
@@ -80,7 +88,11 @@ func (h *Handlers) createBlankTab(w http.ResponseWriter, r *http.Request) {
 
 	h.setCurrentTabForRequest(r, newTabID)
 	h.recordActivity(r, activity.Update{Action: "tab.new", TabID: newTabID, URL: curURL})
-	httpx.JSON(w, 200, map[string]any{"tabId": newTabID, "url": curURL, "title": title})
+	resp := map[string]any{"tabId": newTabID, "url": curURL, "title": title}
+	if proxy.Pool != "" {
+		resp["proxy"] = proxy
+	}
+	httpx.JSON(w, 200, resp)
 }
 
 // HandleTabClose closes the tab identified by the path. It is the tab-scoped
@@ -135,6 +147,8 @@ func (h *Handlers) closeTab(w http.ResponseWriter, r *http.Request, tabID string
 			return
 		}
 		tabID = resolvedTabID
+	} else if !h.enforceTabSession(w, r, tabID) {
+		return
 	}
 
 	if err := h.Bridge.CloseTab(tabID); err != nil {
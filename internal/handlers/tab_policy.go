@@ -27,7 +27,26 @@ func (h *Handlers) currentTabDomainPolicyEnabled() bool {
 		len(h.Config.AllowedDomains) > 0
 }
 
+// enforceCurrentTabDomainPolicy blocks the request when the tab's current
+// URL fails the IDPI allowlist or the API token's allowed domains.
 func (h *Handlers) enforceCurrentTabDomainPolicy(w http.ResponseWriter, r *http.Request, ctx context.Context, tabID string) (string, bool) {
+	currentURL, ok := h.enforceIDPITabDomainPolicy(w, r, ctx, tabID)
+	if !ok || len(requestTokenDomains(r)) == 0 {
+		return currentURL, ok
+	}
+	if currentURL == "" {
+		lookupCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		var err error
+		if currentURL, err = h.Bridge.CurrentURL(lookupCtx); err != nil {
+			httpx.Error(w, 500, fmt.Errorf("resolve current tab url: %w", err))
+			return "", false
+		}
+	}
+	return currentURL, enforceTokenDomains(w, r, currentURL)
+}
+
+func (h *Handlers) enforceIDPITabDomainPolicy(w http.ResponseWriter, r *http.Request, ctx context.Context, tabID string) (string, bool) {
 	if !h.currentTabDomainPolicyEnabled() {
 		return "", true
 	}
@@ -75,11 +94,14 @@ func (h *Handlers) applyTabPolicyState(w http.ResponseWriter, state bridge.TabPo
 	return state.CurrentURL, true
 }
 
-func (h *Handlers) enforceURLDomainPolicy(w http.ResponseWriter, url string) bool {
-	if !h.currentTabDomainPolicyEnabled() {
+func (h *Handlers) enforceURLDomainPolicy(w http.ResponseWriter, r *http.Request, url string) bool {
+	if url == "" {
 		return true
 	}
-	if url == "" {
+	if !enforceTokenDomains(w, r, url) {
+		return false
+	}
+	if !h.currentTabDomainPolicyEnabled() {
 		return true
 	}
 
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/handoff"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/routes"
+)
+
+// takeoverInputTimeout bounds a single forwarded input event.
+const takeoverInputTimeout = 10 * time.Second
+
+// takeoverInputKinds are the low-level action kinds a human may send over the
+// takeover stream. Everything else (navigate, evaluate, ...) is refused: the
+// link grants hands on the page, not the API.
+var takeoverInputKinds = map[string]bool{
+	bridge.ActionClick:          true,
+	bridge.ActionScroll:         true,
+	bridge.ActionPress:          true,
+	bridge.ActionMouseMove:      true,
+	bridge.ActionMouseDown:      true,
+	bridge.ActionMouseUp:        true,
+	bridge.ActionMouseWheel:     true,
+	bridge.ActionKeyboardInsert: true,
+	bridge.ActionKeyDown:        true,
+	bridge.ActionKeyUp:          true,
+}
+
+// takeoverLinkAllowed reports whether r is a takeover request carrying a link
+// token that is well-formed, unexpired, and scoped to the tab in its path.
+// Those requests skip bearer auth; the handlers re-check the link against
+// the live pause before doing anything.
+func takeoverLinkAllowed(secret string, r *http.Request) bool {
+	tabID, ok := takeoverPathTab(r.URL.Path)
+	if !ok {
+		return false
+	}
+	claims, err := handoff.VerifyLink(secret, r.URL.Query().Get("t"), time.Now())
+	return err == nil && claims.TabID == tabID
+}
+
+// takeoverPathTab extracts the tab ID from /tabs/{id}/takeover[/stream|/resume].
+func takeoverPathTab(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/tabs/")
+	if !ok {
+		return "", false
+	}
+	tabID, sub, ok := strings.Cut(rest, "/")
+	if !ok || tabID == "" {
+		return "", false
+	}
+	switch sub {
+	case "takeover", "takeover/stream", "takeover/resume":
+		return tabID, true
+	}
+	return "", false
+}
+
+// requireTakeoverLink validates the request's link token against the tab's
+// current pause. It writes the error response and returns false on failure.
+func (h *Handlers) requireTakeoverLink(w http.ResponseWriter, r *http.Request) (string, bridge.TabHandoffState, handoff.LinkClaims, bool) {
+	tabID := strings.TrimSpace(r.PathValue("id"))
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return "", bridge.TabHandoffState{}, handoff.LinkClaims{}, false
+	}
+	claims, err := handoff.VerifyLink(h.Config.Token, r.URL.Query().Get("t"), time.Now())
+	switch {
+	case errors.Is(err, handoff.ErrLinkExpired):
+		httpx.ErrorCode(w, 401, "takeover_link_expired", "takeover link expired", false, nil)
+		return "", bridge.TabHandoffState{}, claims, false
+	case err != nil || claims.TabID != tabID:
+		httpx.ErrorCode(w, 401, "bad_takeover_link", "invalid takeover link", false, nil)
+		return "", bridge.TabHandoffState{}, claims, false
+	}
+	ctrl, ok := h.handoffController()
+	if !ok {
+		httpx.ErrorCode(w, 501, "handoff_not_supported", "bridge does not support handoff state", false, nil)
+		return "", bridge.TabHandoffState{}, claims, false
+	}
+	state, paused := ctrl.TabHandoffState(tabID)
+	if !paused || state.PausedAt.UnixNano() != claims.Generation {
+		httpx.ErrorCode(w, 410, "handoff_not_active", "this handoff has already been resumed", false, nil)
+		return "", bridge.TabHandoffState{}, claims, false
+	}
+	return tabID, state, claims, true
+}
+
+// takeoverOriginAllowed keeps other sites from opening the takeover socket
+// with a leaked link: the Origin must be this server, as seen by the
+// request or as published in handoff.publicUrl.
+func (h *Handlers) takeoverOriginAllowed(r *http.Request) bool {
+	origin := strings.TrimSpace(r.Header.Get("Origin"))
+	if origin == "" {
+		return false
+	}
+	if sameOriginRequest(origin, r, h.Config.TrustProxyHeaders) {
+		return true
+	}
+	public, err := url.Parse(h.Config.Handoff.PublicURL)
+	if err != nil || public.Host == "" {
+		return false
+	}
+	return strings.EqualFold(origin, public.Scheme+"://"+public.Host)
+}
+
+// handoffStillActive reports whether the pause a link was minted for is
+// still the tab's current one.
+func (h *Handlers) handoffStillActive(tabID string, claims handoff.LinkClaims) bool {
+	ctrl, ok := h.handoffController()
+	if !ok {
+		return false
+	}
+	state, paused := ctrl.TabHandoffState(tabID)
+	return paused && state.PausedAt.UnixNano() == claims.Generation &&
+		time.Now().Before(claims.Expiry())
+}
+
+// HandleTabTakeover describes the paused tab behind a takeover link.
+//
+// @Endpoint GET /tabs/{id}/takeover
+// @Description Describe a tab paused for handoff (authenticated by a takeover link)
+//
+// @Param t string query Signed takeover link token (required)
+//
+// @Response 200 application/json {tabId, status, reason, url, title, pausedAt, linkExpiresAt, screencast}
+// @Response 401 application/json Invalid or expired link
+// @Response 410 application/json Handoff already resumed
+func (h *Handlers) HandleTabTakeover(w http.ResponseWriter, r *http.Request) {
+	tabID, state, claims, ok := h.requireTakeoverLink(w, r)
+	if !ok {
+		return
+	}
+	pageURL, title := h.tabPageInfo(tabID)
+	resp := map[string]any{
+		"tabId":         tabID,
+		"status":        state.Status,
+		"reason":        state.Reason,
+		"url":           pageURL,
+		"title":         title,
+		"pausedAt":      state.PausedAt.Format(time.RFC3339),
+		"linkExpiresAt": claims.Expiry().Format(time.RFC3339),
+		"screencast":    h.Config.AllowScreencast,
+	}
+	if !state.ExpiresAt.IsZero() {
+		resp["expiresAt"] = state.ExpiresAt.Format(time.RFC3339)
+	}
+	httpx.JSON(w, 200, resp)
+}
+
+// HandleTabTakeoverStream streams the paused tab's screencast and forwards
+// the human's input. Frames go out as binary messages; input comes in as
+// JSON text messages shaped like action requests ({kind, x, y, frameW,
+// frameH, key, text, modifiers, ...}) restricted to pointer and keyboard
+// kinds. The stream closes once the handoff is resumed or the link expires.
+//
+// @Endpoint GET /tabs/{id}/takeover/stream
+// @Description Live screencast with input forwarding for a tab paused for handoff
+//
+// @Param t        string query Signed takeover link token (required)
+// @Param quality  int    query JPEG quality 1-100 (optional, default: 60)
+// @Param maxWidth int    query Max frame width (optional, default: 1280)
+// @Param fps      int    query Frames per second 1-30 (optional, default: 10)
+func (h *Handlers) HandleTabTakeoverStream(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.AllowScreencast {
+		h.writeCapabilityDisabled(w, routes.CapScreencast)
+		return
+	}
+	tabID, _, claims, ok := h.requireTakeoverLink(w, r)
+	if !ok {
+		return
+	}
+	if !h.takeoverOriginAllowed(r) {
+		httpx.ErrorCode(w, http.StatusForbidden, "origin_forbidden", "takeover stream requires a same-origin browser", false, map[string]any{
+			"sameOriginRequired": true,
+		})
+		return
+	}
+	ctx, resolvedTabID, err := h.Bridge.TabContext(tabID)
+	if err != nil {
+		WriteTabContextError(w, err, 404)
+		return
+	}
+
+	quality := queryParamInt(r, "quality", 60)
+	maxWidth := queryParamInt(r, "maxWidth", 1280)
+	fps := queryParamInt(r, "fps", 10)
+	if fps > 30 {
+		fps = 30
+	}
+
+	conn, _, _, err := ws.UpgradeHTTP(r, w)
+	if err != nil {
+		slog.Error("takeover: ws upgrade failed", "err", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	var writeMu sync.Mutex
+	write := func(op ws.OpCode, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return wsutil.WriteServerMessage(conn, op, payload)
+	}
+
+	var once sync.Once
+	done := make(chan struct{})
+	stop := func() { once.Do(func() { close(done) }) }
+
+	slog.Info("takeover started", "tab", resolvedTabID)
+	defer slog.Info("takeover ended", "tab", resolvedTabID)
+
+	go func() {
+		defer stop()
+		for {
+			msg, op, err := wsutil.ReadClientData(conn)
+			if err != nil {
+				return
+			}
+			if op != ws.OpText {
+				continue
+			}
+			if !h.handoffStillActive(resolvedTabID, claims) {
+				return
+			}
+			if err := h.dispatchTakeoverInput(ctx, resolvedTabID, msg); err != nil {
+				reply, _ := json.Marshal(map[string]string{"type": "error", "error": err.Error()})
+				if write(ws.OpText, reply) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	stream, err := h.Bridge.StartScreencast(ctx, bridge.ScreencastOpts{
+		Quality:       quality,
+		MaxWidth:      maxWidth,
+		MaxHeight:     maxWidth * 3 / 4,
+		EveryNthFrame: 1,
+		FPS:           fps,
+	})
+	if err != nil {
+		slog.Error("takeover: start screencast failed", "err", err, "tab", resolvedTabID)
+		return
+	}
+	defer stream.Close()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case frame, ok := <-stream.Frames:
+			if !ok {
+				return
+			}
+			if err := write(ws.OpBinary, frame); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if !h.handoffStillActive(resolvedTabID, claims) {
+				reply, _ := json.Marshal(map[string]string{"type": "resumed"})
+				_ = write(ws.OpText, reply)
+				return
+			}
+			if err := write(ws.OpPing, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// dispatchTakeoverInput runs one input message from the takeover stream.
+func (h *Handlers) dispatchTakeoverInput(ctx context.Context, tabID string, msg []byte) error {
+	var req bridge.ActionRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return fmt.Errorf("decode input: %w", err)
+	}
+	if !takeoverInputKinds[req.Kind] {
+		return fmt.Errorf("input kind %q not allowed during takeover", req.Kind)
+	}
+	// Only pointer and keyboard fields are honoured; element targeting and
+	// navigation side effects stay with the agent.
+	req = bridge.ActionRequest{
+		TabID:     tabID,
+		Kind:      req.Kind,
+		Text:      req.Text,
+		Key:       req.Key,
+		X:         req.X,
+		Y:         req.Y,
+		HasXY:     req.HasXY,
+		Button:    req.Button,
+		FrameW:    req.FrameW,
+		FrameH:    req.FrameH,
+		Modifiers: req.Modifiers,
+		ScrollX:   req.ScrollX,
+		ScrollY:   req.ScrollY,
+		DeltaX:    req.DeltaX,
+		DeltaY:    req.DeltaY,
+		Fast:      true,
+	}
+	actCtx, cancel := context.WithTimeout(ctx, takeoverInputTimeout)
+	defer cancel()
+	_, err := h.Bridge.ExecuteAction(actCtx, req.Kind, req)
+	return err
+}
+
+// HandleTabTakeoverResume ends a handoff from the takeover view and hands the
+// tab back to the agent with the human's outcome.
+//
+// @Endpoint POST /tabs/{id}/takeover/resume
+// @Description Resume a tab paused for handoff (authenticated by a takeover link)
+//
+// @Param t    string query Signed takeover link token (required)
+// @Param body object body  {status?, resolvedData?}
+//
+// @Response 200 application/json {tabId, status, resume}
+// @Response 410 application/json Handoff already resumed
+func (h *Handlers) HandleTabTakeoverResume(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status string         `json:"status"`
+		Data   map[string]any `json:"resolvedData"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return
+	}
+	tabID, _, _, ok := h.requireTakeoverLink(w, r)
+	if !ok {
+		return
+	}
+	resume := bridge.TabHandoffResume{
+		Status:    strings.TrimSpace(req.Status),
+		Data:      req.Data,
+		ResumedBy: "takeover",
+		ResumedAt: time.Now().UTC(),
+	}
+	if err := h.resumeTabFromHandoff(r, tabID, resume); err != nil {
+		httpx.ErrorCode(w, 500, "resume_failed", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":  tabID,
+		"status": "active",
+		"resume": resume,
+	})
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/handoff"
+)
+
+// takeoverBridge keeps real handoff state so link generations line up.
+type takeoverBridge struct {
+	mockBridge
+	state    bridge.TabHandoffState
+	has      bool
+	resume   *bridge.TabHandoffResume
+	lastKind string
+	lastReq  bridge.ActionRequest
+}
+
+func (m *takeoverBridge) SetTabHandoff(tabID, reason string, timeout time.Duration) error {
+	now := time.Now().UTC()
+	m.state = bridge.TabHandoffState{Status: "paused_handoff", Reason: reason, PausedAt: now, LastUpdatedAt: now}
+	if timeout > 0 {
+		m.state.ExpiresAt = now.Add(timeout)
+	}
+	m.has = true
+	m.resume = nil
+	return nil
+}
+
+func (m *takeoverBridge) ResumeTabHandoff(tabID string) error {
+	m.has = false
+	return nil
+}
+
+func (m *takeoverBridge) TabHandoffState(tabID string) (bridge.TabHandoffState, bool) {
+	return m.state, m.has
+}
+
+func (m *takeoverBridge) RecordTabResume(tabID string, resume bridge.TabHandoffResume) {
+	m.resume = &resume
+}
+
+func (m *takeoverBridge) LastTabResume(tabID string) (bridge.TabHandoffResume, bool) {
+	if m.resume == nil {
+		return bridge.TabHandoffResume{}, false
+	}
+	return *m.resume, true
+}
+
+func (m *takeoverBridge) ExecuteAction(ctx context.Context, kind string, req bridge.ActionRequest) (map[string]any, error) {
+	m.lastKind = kind
+	m.lastReq = req
+	return map[string]any{"ok": true}, nil
+}
+
+func newTakeoverTestServer(t *testing.T, cfg *config.RuntimeConfig) (*takeoverBridge, http.Handler) {
+	t.Helper()
+	b := &takeoverBridge{}
+	h := New(b, cfg, nil, nil, nil)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux, func() {})
+	return b, AuthMiddleware(cfg, mux)
+}
+
+func serveJSON(t *testing.T, srv http.Handler, method, target, auth, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	if auth != "" {
+		req.Header.Set("Authorization", "Bearer "+auth)
+	}
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	var out map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	return w.Code, out
+}
+
+func takeoverToken(t *testing.T, takeoverURL string) string {
+	t.Helper()
+	u, err := url.Parse(takeoverURL)
+	if err != nil {
+		t.Fatalf("parse takeover url %q: %v", takeoverURL, err)
+	}
+	return u.Query().Get("t")
+}
+
+func TestTakeover_LinkLifecycle(t *testing.T) {
+	cfg := &config.RuntimeConfig{Token: "secret", Handoff: config.HandoffConfig{PublicURL: "https://pinch.example"}}
+	b, srv := newTakeoverTestServer(t, cfg)
+
+	code, body := serveJSON(t, srv, "POST", "/tabs/tab1/handoff", "secret", `{"reason":"2fa"}`)
+	if code != 200 {
+		t.Fatalf("handoff: %d %v", code, body)
+	}
+	link, _ := body["takeoverUrl"].(string)
+	if link == "" || body["linkExpiresAt"] == nil {
+		t.Fatalf("handoff response missing takeover link: %v", body)
+	}
+	tok := takeoverToken(t, link)
+
+	// The link alone authenticates the takeover routes.
+	code, body = serveJSON(t, srv, "GET", "/tabs/tab1/takeover?t="+tok, "", "")
+	if code != 200 || body["reason"] != "2fa" {
+		t.Fatalf("takeover state: %d %v", code, body)
+	}
+	// ...but nothing else.
+	if code, _ := serveJSON(t, srv, "GET", "/tabs/tab1/handoff?t="+tok, "", ""); code != 401 {
+		t.Fatalf("link must not authenticate other routes, got %d", code)
+	}
+
+	code, body = serveJSON(t, srv, "POST", "/tabs/tab1/takeover/resume?t="+tok, "", `{"status":"solved","resolvedData":{"code":"123"}}`)
+	if code != 200 {
+		t.Fatalf("takeover resume: %d %v", code, body)
+	}
+	if b.has {
+		t.Fatal("expected tab to be resumed")
+	}
+
+	code, body = serveJSON(t, srv, "GET", "/tabs/tab1/handoff", "secret", "")
+	last, _ := body["lastResume"].(map[string]any)
+	if code != 200 || last["resumedBy"] != "takeover" || last["status"] != "solved" {
+		t.Fatalf("handoff status after resume: %d %v", code, body)
+	}
+
+	// A used link is dead once the pause it was minted for ends.
+	if code, _ := serveJSON(t, srv, "POST", "/tabs/tab1/takeover/resume?t="+tok, "", `{}`); code != 410 {
+		t.Fatalf("reused link: got %d, want 410", code)
+	}
+}
+
+func TestTakeover_RejectsBadLinks(t *testing.T) {
+	cfg := &config.RuntimeConfig{Token: "secret"}
+	b, srv := newTakeoverTestServer(t, cfg)
+	if err := b.SetTabHandoff("tab1", "2fa", 0); err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+	gen := b.state.PausedAt.UnixNano()
+
+	otherTab, _ := handoff.SignLink("secret", handoff.LinkClaims{TabID: "tab2", Generation: gen, ExpiresAt: exp})
+	otherKey, _ := handoff.SignLink("other", handoff.LinkClaims{TabID: "tab1", Generation: gen, ExpiresAt: exp})
+	stale, _ := handoff.SignLink("secret", handoff.LinkClaims{TabID: "tab1", Generation: gen - 1, ExpiresAt: exp})
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"missing", "", 401},
+		{"other tab", otherTab, 401},
+		{"other key", otherKey, 401},
+		{"earlier pause", stale, 410},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, body := serveJSON(t, srv, "GET", "/tabs/tab1/takeover?t="+url.QueryEscape(tt.token), "", ""); code != tt.want {
+				t.Fatalf("got %d, want %d: %v", code, tt.want, body)
+			}
+		})
+	}
+}
+
+func TestDispatchTakeoverInput(t *testing.T) {
+	b := &takeoverBridge{}
+	h := New(b, &config.RuntimeConfig{}, nil, nil, nil)
+
+	err := h.dispatchTakeoverInput(context.Background(), "tab1", []byte(`{"kind":"click","x":100,"y":50,"frameW":1600,"frameH":1200,"selector":"#x"}`))
+	if err != nil {
+		t.Fatalf("click: %v", err)
+	}
+	if b.lastKind != bridge.ActionClick || !b.lastReq.HasXY || b.lastReq.FrameW != 1600 || b.lastReq.TabID != "tab1" {
+		t.Fatalf("forwarded request = %q %+v", b.lastKind, b.lastReq)
+	}
+	if b.lastReq.Selector != "" {
+		t.Fatalf("selector should be dropped, got %q", b.lastReq.Selector)
+	}
+
+	for _, kind := range []string{"navigate", "type", "fill"} {
+		if err := h.dispatchTakeoverInput(context.Background(), "tab1", []byte(`{"kind":"`+kind+`"}`)); err == nil {
+			t.Fatalf("kind %q should be refused", kind)
+		}
+	}
+}
+
+func TestHandoff_NotifiesWebhook(t *testing.T) {
+	got := make(chan handoff.Event, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev handoff.Event
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &ev)
+		got <- ev
+	}))
+	defer hook.Close()
+
+	cfg := &config.RuntimeConfig{Token: "secret", Handoff: config.HandoffConfig{Webhooks: []string{hook.URL}}}
+	_, srv := newTakeoverTestServer(t, cfg)
+	if code, body := serveJSON(t, srv, "POST", "/tabs/tab1/handoff", "secret", `{"reason":"2fa"}`); code != 200 {
+		t.Fatalf("handoff: %d %v", code, body)
+	}
+
+	select {
+	case ev := <-got:
+		if ev.Event != handoff.EventRequested || ev.TabID != "tab1" || ev.Reason != "2fa" {
+			t.Fatalf("event = %+v", ev)
+		}
+		if tok := takeoverToken(t, ev.TakeoverURL); tok == "" {
+			t.Fatalf("event missing takeover link: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestTakeoverStream_RequiresSameOrigin(t *testing.T) {
+	cfg := &config.RuntimeConfig{
+		Token:           "secret",
+		AllowScreencast: true,
+		Handoff:         config.HandoffConfig{PublicURL: "https://pinch.example"},
+	}
+	b, srv := newTakeoverTestServer(t, cfg)
+	if err := b.SetTabHandoff("tab1", "2fa", 0); err != nil {
+		t.Fatal(err)
+	}
+	tok, _ := handoff.SignLink("secret", handoff.LinkClaims{
+		TabID:      "tab1",
+		Generation: b.state.PausedAt.UnixNano(),
+		ExpiresAt:  time.Now().Add(time.Minute).Unix(),
+	})
+
+	for _, origin := range []string{"", "https://evil.example", "http://pinch.example"} {
+		req := httptest.NewRequest("GET", "http://127.0.0.1:9867/tabs/tab1/takeover/stream?t="+url.QueryEscape(tok), nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("origin %q: status = %d, want 403: %s", origin, w.Code, w.Body.String())
+		}
+	}
+
+	h := &Handlers{Config: cfg}
+	for _, origin := range []string{"http://127.0.0.1:9867", "https://pinch.example"} {
+		req := httptest.NewRequest("GET", "http://127.0.0.1:9867/tabs/tab1/takeover/stream", nil)
+		req.Header.Set("Origin", origin)
+		if !h.takeoverOriginAllowed(req) {
+			t.Errorf("origin %q should be allowed", origin)
+		}
+	}
+}
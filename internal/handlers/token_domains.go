@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// TokenDomainsHeader carries the URL domain allowlist of the API token
+// behind an orchestrator → instance hop, comma separated. Like every
+// X-PinchTab-* header it is stripped from public requests.
+const TokenDomainsHeader = "X-PinchTab-Token-Domains"
+
+// requestTokenDomains returns the domain allowlist of the request's API
+// token: the authenticated token's, or the one forwarded on a trusted
+// internal hop. Nil means the request is not domain limited.
+func requestTokenDomains(r *http.Request) []string {
+	if tok, ok := apitoken.FromRequest(r); ok {
+		return tok.Scope.AllowedDomains
+	}
+	if IsTrustedInternalProxy(r) {
+		if v := strings.TrimSpace(r.Header.Get(TokenDomainsHeader)); v != "" {
+			return strings.Split(v, ",")
+		}
+	}
+	return nil
+}
+
+// tokenDomainError reports a url outside the request's token domains.
+func tokenDomainError(r *http.Request, url string) error {
+	domains := requestTokenDomains(r)
+	if len(domains) == 0 || url == "" || apitoken.URLAllowed(url, domains) {
+		return nil
+	}
+	return fmt.Errorf("%s is outside the API token's allowed domains", url)
+}
+
+// tokenDomainAllows reports whether url is inside the request's token
+// domains. Requests that are not domain limited allow every url.
+func tokenDomainAllows(r *http.Request, url string) bool {
+	return tokenDomainError(r, url) == nil
+}
+
+func enforceTokenDomains(w http.ResponseWriter, r *http.Request, url string) bool {
+	if err := tokenDomainError(r, url); err != nil {
+		httpx.ErrorCode(w, http.StatusForbidden, "token_domain_forbidden", err.Error(), false, map[string]any{
+			"url": url,
+		})
+		return false
+	}
+	return true
+}
@@ -176,8 +176,9 @@ func (h *Handlers) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	owner := resolveOwner(r, "")
-	if err := h.enforceTabLease(resolvedTabID, owner); err != nil {
-		httpx.ErrorCode(w, http.StatusLocked, "tab_locked", err.Error(), false, nil)
+	lockToken := resolveLockToken(r, 0)
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
 		return
 	}
 	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/routes"
+)
+
+type addAuthenticatorRequest struct {
+	TabID string `json:"tabId"`
+	bridge.WebAuthnAuthenticatorOptions
+}
+
+type authenticatorPresenceRequest struct {
+	TabID        string `json:"tabId"`
+	UserPresence *bool  `json:"userPresence"`
+	UserVerified *bool  `json:"userVerified"`
+}
+
+type importCredentialRequest struct {
+	TabID      string                     `json:"tabId"`
+	Credential *bridge.WebAuthnCredential `json:"credential"`
+}
+
+// HandleWebAuthn lists the virtual authenticators attached to a tab.
+//
+// @Endpoint GET /webauthn
+// @Description List virtual WebAuthn authenticators for a tab
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json {tabId, authenticators}
+// @Response 403 application/json security.allowWebAuthn is disabled
+func (h *Handlers) HandleWebAuthn(w http.ResponseWriter, r *http.Request) {
+	h.handleWebAuthnFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleAddAuthenticator attaches a virtual authenticator to a tab and seeds
+// it with the credentials stored in the profile.
+//
+// @Endpoint POST /webauthn/authenticators
+// @Description Add a virtual WebAuthn authenticator
+//
+// @Param body object body {tabId?, protocol?: ctap2|u2f, transport?: internal|usb|nfc|ble, hasResidentKey?, hasUserVerification?, isUserVerified?, userPresence?}
+//
+// @Response 200 application/json {ok, tabId, authenticator, restored}
+// @Response 400 application/json Invalid options
+// @Response 403 application/json security.allowWebAuthn is disabled
+func (h *Handlers) HandleAddAuthenticator(w http.ResponseWriter, r *http.Request) {
+	var req addAuthenticatorRequest
+	if !decodeWebAuthnBody(w, r, &req) {
+		return
+	}
+	h.addAuthenticator(w, r, req.TabID, req.WebAuthnAuthenticatorOptions)
+}
+
+// HandleRemoveAuthenticator detaches a virtual authenticator. Its credentials
+// stay in the profile store.
+//
+// @Endpoint DELETE /webauthn/authenticators/{authenticatorId}
+// @Description Remove a virtual WebAuthn authenticator
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json {ok, tabId, authenticatorId}
+// @Response 404 application/json Unknown authenticator
+func (h *Handlers) HandleRemoveAuthenticator(w http.ResponseWriter, r *http.Request) {
+	h.removeAuthenticatorFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleAuthenticatorPresence toggles automatic user-presence responses and
+// the user verification result.
+//
+// @Endpoint POST /webauthn/authenticators/{authenticatorId}/presence
+// @Description Toggle user presence and verification for an authenticator
+//
+// @Param body object body {tabId?, userPresence?, userVerified?}
+//
+// @Response 200 application/json {ok, tabId, authenticator}
+// @Response 404 application/json Unknown authenticator
+func (h *Handlers) HandleAuthenticatorPresence(w http.ResponseWriter, r *http.Request) {
+	var req authenticatorPresenceRequest
+	if !decodeWebAuthnBody(w, r, &req) {
+		return
+	}
+	h.authenticatorPresence(w, r, req)
+}
+
+// HandleWebAuthnCredentials lists an authenticator's credentials with their
+// private keys redacted.
+//
+// @Endpoint GET /webauthn/authenticators/{authenticatorId}/credentials
+// @Description List credentials held by a virtual authenticator
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json {tabId, authenticatorId, credentials, privateKeysRedacted}
+// @Response 404 application/json Unknown authenticator
+func (h *Handlers) HandleWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	h.webAuthnCredentialsFor(w, r, r.URL.Query().Get("tabId"), false)
+}
+
+// HandleExportWebAuthnCredentials lists an authenticator's credentials with
+// their private keys. Only the server token or an elevated dashboard admin
+// may call it.
+//
+// @Endpoint POST /webauthn/authenticators/{authenticatorId}/credentials/export
+// @Description Export credentials of a virtual authenticator, private keys included
+//
+// @Param tabId string query Tab ID (optional, uses current tab if empty)
+//
+// @Response 200 application/json {tabId, authenticatorId, credentials}
+// @Response 403 application/json Caller is an agent session or scoped API token
+// @Response 404 application/json Unknown authenticator
+func (h *Handlers) HandleExportWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	h.webAuthnCredentialsFor(w, r, r.URL.Query().Get("tabId"), true)
+}
+
+// HandleImportWebAuthnCredential imports a credential into an authenticator
+// and the profile store.
+//
+// @Endpoint POST /webauthn/authenticators/{authenticatorId}/credentials
+// @Description Import a credential into a virtual authenticator
+//
+// @Param body object body {tabId?, credential: {credentialId, rpId, privateKey, userHandle?, isResidentCredential?, signCount?, userName?, userDisplayName?}}
+//
+// @Response 200 application/json {ok, tabId, authenticatorId, credentialId}
+// @Response 400 application/json Missing or invalid credential
+func (h *Handlers) HandleImportWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	var req importCredentialRequest
+	if !decodeWebAuthnBody(w, r, &req) {
+		return
+	}
+	h.importWebAuthnCredential(w, r, req)
+}
+
+// HandleRemoveWebAuthnCredential deletes a credential from an authenticator
+// and the profile store.
+//
+// @Endpoint DELETE /webauthn/authenticators/{authenticatorId}/credentials
+// @Description Remove a credential from a virtual authenticator
+//
+// @Param tabId        string query Tab ID (optional, uses current tab if empty)
+// @Param credentialId string query Credential ID (required)
+//
+// @Response 200 application/json {ok, tabId, authenticatorId, credentialId}
+func (h *Handlers) HandleRemoveWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	h.removeWebAuthnCredentialFor(w, r, r.URL.Query().Get("tabId"))
+}
+
+// HandleTabWebAuthn is the path-scoped wrapper for HandleWebAuthn.
+//
+// @Endpoint GET /tabs/{id}/webauthn
+func (h *Handlers) HandleTabWebAuthn(w http.ResponseWriter, r *http.Request) {
+	if tabID, ok := webAuthnPathTab(w, r); ok {
+		h.handleWebAuthnFor(w, r, tabID)
+	}
+}
+
+// HandleTabAddAuthenticator is the path-scoped wrapper for HandleAddAuthenticator.
+//
+// @Endpoint POST /tabs/{id}/webauthn/authenticators
+func (h *Handlers) HandleTabAddAuthenticator(w http.ResponseWriter, r *http.Request) {
+	tabID, ok := webAuthnPathTab(w, r)
+	if !ok {
+		return
+	}
+	var req addAuthenticatorRequest
+	if !decodeWebAuthnBody(w, r, &req) || !matchBodyTab(w, req.TabID, tabID) {
+		return
+	}
+	h.addAuthenticator(w, r, tabID, req.WebAuthnAuthenticatorOptions)
+}
+
+// HandleTabRemoveAuthenticator is the path-scoped wrapper for HandleRemoveAuthenticator.
+//
+// @Endpoint DELETE /tabs/{id}/webauthn/authenticators/{authenticatorId}
+func (h *Handlers) HandleTabRemoveAuthenticator(w http.ResponseWriter, r *http.Request) {
+	if tabID, ok := webAuthnPathTab(w, r); ok {
+		h.removeAuthenticatorFor(w, r, tabID)
+	}
+}
+
+// HandleTabAuthenticatorPresence is the path-scoped wrapper for HandleAuthenticatorPresence.
+//
+// @Endpoint POST /tabs/{id}/webauthn/authenticators/{authenticatorId}/presence
+func (h *Handlers) HandleTabAuthenticatorPresence(w http.ResponseWriter, r *http.Request) {
+	tabID, ok := webAuthnPathTab(w, r)
+	if !ok {
+		return
+	}
+	var req authenticatorPresenceRequest
+	if !decodeWebAuthnBody(w, r, &req) || !matchBodyTab(w, req.TabID, tabID) {
+		return
+	}
+	req.TabID = tabID
+	h.authenticatorPresence(w, r, req)
+}
+
+// HandleTabWebAuthnCredentials is the path-scoped wrapper for HandleWebAuthnCredentials.
+//
+// @Endpoint GET /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials
+func (h *Handlers) HandleTabWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	if tabID, ok := webAuthnPathTab(w, r); ok {
+		h.webAuthnCredentialsFor(w, r, tabID, false)
+	}
+}
+
+// HandleTabExportWebAuthnCredentials is the path-scoped wrapper for HandleExportWebAuthnCredentials.
+//
+// @Endpoint POST /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials/export
+func (h *Handlers) HandleTabExportWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	if tabID, ok := webAuthnPathTab(w, r); ok {
+		h.webAuthnCredentialsFor(w, r, tabID, true)
+	}
+}
+
+// HandleTabImportWebAuthnCredential is the path-scoped wrapper for HandleImportWebAuthnCredential.
+//
+// @Endpoint POST /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials
+func (h *Handlers) HandleTabImportWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	tabID, ok := webAuthnPathTab(w, r)
+	if !ok {
+		return
+	}
+	var req importCredentialRequest
+	if !decodeWebAuthnBody(w, r, &req) || !matchBodyTab(w, req.TabID, tabID) {
+		return
+	}
+	req.TabID = tabID
+	h.importWebAuthnCredential(w, r, req)
+}
+
+// HandleTabRemoveWebAuthnCredential is the path-scoped wrapper for HandleRemoveWebAuthnCredential.
+//
+// @Endpoint DELETE /tabs/{id}/webauthn/authenticators/{authenticatorId}/credentials
+func (h *Handlers) HandleTabRemoveWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	if tabID, ok := webAuthnPathTab(w, r); ok {
+		h.removeWebAuthnCredentialFor(w, r, tabID)
+	}
+}
+
+func (h *Handlers) handleWebAuthnFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	_, resolvedTabID, ok := h.requireWebAuthnContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	httpx.JSON(w, 200, map[string]any{
+		"tabId":          resolvedTabID,
+		"authenticators": h.Bridge.VirtualAuthenticators(resolvedTabID),
+	})
+}
+
+func (h *Handlers) addAuthenticator(w http.ResponseWriter, r *http.Request, tabID string, opts bridge.WebAuthnAuthenticatorOptions) {
+	if !h.webAuthnEnabled() {
+		h.writeCapabilityDisabled(w, routes.CapWebAuthn)
+		return
+	}
+	if _, err := opts.Normalize(); err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	auth, restored, err := h.Bridge.AddVirtualAuthenticator(tCtx, resolvedTabID, opts)
+	if err != nil {
+		httpx.Error(w, 500, err)
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "webauthn.authenticator.add", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{
+		"ok":            true,
+		"tabId":         resolvedTabID,
+		"authenticator": auth,
+		"restored":      restored,
+	})
+}
+
+func (h *Handlers) removeAuthenticatorFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	authID := r.PathValue("authenticatorId")
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := h.Bridge.RemoveVirtualAuthenticator(tCtx, resolvedTabID, authID); err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "webauthn.authenticator.remove", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{"ok": true, "tabId": resolvedTabID, "authenticatorId": authID})
+}
+
+func (h *Handlers) authenticatorPresence(w http.ResponseWriter, r *http.Request, req authenticatorPresenceRequest) {
+	if !h.webAuthnEnabled() {
+		h.writeCapabilityDisabled(w, routes.CapWebAuthn)
+		return
+	}
+	if req.UserPresence == nil && req.UserVerified == nil {
+		httpx.Error(w, 400, fmt.Errorf("userPresence or userVerified required"))
+		return
+	}
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	auth, err := h.Bridge.SetAuthenticatorPresence(tCtx, resolvedTabID, r.PathValue("authenticatorId"), req.UserPresence, req.UserVerified)
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "webauthn.presence", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{"ok": true, "tabId": resolvedTabID, "authenticator": auth})
+}
+
+func (h *Handlers) webAuthnCredentialsFor(w http.ResponseWriter, r *http.Request, tabID string, export bool) {
+	if export && (requestSessionID(r) != "" || requestTokenID(r) != "") {
+		httpx.ErrorCode(w, http.StatusForbidden, "export_forbidden",
+			"exporting private keys requires the server token", false, nil)
+		return
+	}
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	authID := r.PathValue("authenticatorId")
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	creds, err := h.Bridge.WebAuthnCredentials(tCtx, resolvedTabID, authID)
+	if err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+	if export {
+		h.recordActivity(r, activity.Update{Action: "webauthn.credential.export", TabID: resolvedTabID})
+		httpx.JSON(w, 200, map[string]any{"tabId": resolvedTabID, "authenticatorId": authID, "credentials": creds})
+		return
+	}
+	redacted := make([]bridge.WebAuthnCredential, 0, len(creds))
+	for _, c := range creds {
+		cp := *c
+		cp.PrivateKey = ""
+		redacted = append(redacted, cp)
+	}
+	httpx.JSON(w, 200, map[string]any{"tabId": resolvedTabID, "authenticatorId": authID, "credentials": redacted, "privateKeysRedacted": true})
+}
+
+func (h *Handlers) importWebAuthnCredential(w http.ResponseWriter, r *http.Request, req importCredentialRequest) {
+	if !h.webAuthnEnabled() {
+		h.writeCapabilityDisabled(w, routes.CapWebAuthn)
+		return
+	}
+	c := req.Credential
+	if c == nil || c.CredentialID == "" || c.RpID == "" || c.PrivateKey == "" {
+		httpx.Error(w, 400, fmt.Errorf("credential requires credentialId, rpId and privateKey"))
+		return
+	}
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, req.TabID)
+	if !ok {
+		return
+	}
+	authID := r.PathValue("authenticatorId")
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := h.Bridge.AddWebAuthnCredential(tCtx, resolvedTabID, authID, c); err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "webauthn.credential.import", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{"ok": true, "tabId": resolvedTabID, "authenticatorId": authID, "credentialId": c.CredentialID})
+}
+
+func (h *Handlers) removeWebAuthnCredentialFor(w http.ResponseWriter, r *http.Request, tabID string) {
+	if !h.webAuthnEnabled() {
+		h.writeCapabilityDisabled(w, routes.CapWebAuthn)
+		return
+	}
+	credID := r.URL.Query().Get("credentialId")
+	if credID == "" {
+		httpx.Error(w, 400, fmt.Errorf("credentialId required"))
+		return
+	}
+	ctx, resolvedTabID, ok := h.requireWebAuthnContext(w, r, tabID)
+	if !ok {
+		return
+	}
+	authID := r.PathValue("authenticatorId")
+	tCtx, tCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer tCancel()
+
+	if err := h.Bridge.RemoveWebAuthnCredential(tCtx, resolvedTabID, authID, credID); err != nil {
+		writeWebAuthnError(w, err)
+		return
+	}
+
+	h.recordActivity(r, activity.Update{Action: "webauthn.credential.remove", TabID: resolvedTabID})
+
+	httpx.JSON(w, 200, map[string]any{"ok": true, "tabId": resolvedTabID, "authenticatorId": authID, "credentialId": credID})
+}
+
+// requireWebAuthnContext is the shared prelude for the webauthn handlers: the
+// capability gate, browser startup, tab resolution and the tab's domain
+// policy. On failure the response has been written and ok=false.
+func (h *Handlers) requireWebAuthnContext(w http.ResponseWriter, r *http.Request, tabID string) (context.Context, string, bool) {
+	if !h.webAuthnEnabled() {
+		h.writeCapabilityDisabled(w, routes.CapWebAuthn)
+		return nil, "", false
+	}
+	if err := h.ensureBrowser(h.Config); err != nil {
+		if h.writeBridgeUnavailable(w, err) {
+			return nil, "", false
+		}
+		httpx.Error(w, 500, fmt.Errorf("browser initialization: %w", err))
+		return nil, "", false
+	}
+	ctx, resolvedTabID, err := h.tabContext(r, tabID)
+	if err != nil {
+		WriteTabContextError(w, err, 404)
+		return nil, "", false
+	}
+	if _, ok := h.enforceCurrentTabDomainPolicy(w, r, ctx, resolvedTabID); !ok {
+		return nil, "", false
+	}
+	return ctx, resolvedTabID, true
+}
+
+func writeWebAuthnError(w http.ResponseWriter, err error) {
+	if errors.Is(err, bridge.ErrAuthenticatorNotFound) {
+		httpx.Error(w, 404, err)
+		return
+	}
+	httpx.Error(w, 500, err)
+}
+
+func webAuthnPathTab(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tabID := r.PathValue("id")
+	if tabID == "" {
+		httpx.Error(w, 400, fmt.Errorf("tab id required"))
+		return "", false
+	}
+	return tabID, true
+}
+
+func matchBodyTab(w http.ResponseWriter, bodyTabID, tabID string) bool {
+	if bodyTabID != "" && bodyTabID != tabID {
+		httpx.Error(w, 400, fmt.Errorf("tabId in body %q does not match URL path %q", bodyTabID, tabID))
+		return false
+	}
+	return true
+}
+
+func decodeWebAuthnBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		httpx.Error(w, 400, fmt.Errorf("decode: %w", err))
+		return false
+	}
+	return true
+}
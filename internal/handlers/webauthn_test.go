@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/session"
+)
+
+type webAuthnMockBridge struct {
+	mockBridge
+	auths    map[string]*bridge.VirtualAuthenticator
+	imported []*bridge.WebAuthnCredential
+}
+
+func (m *webAuthnMockBridge) AddVirtualAuthenticator(_ context.Context, tabID string, opts bridge.WebAuthnAuthenticatorOptions) (*bridge.VirtualAuthenticator, int, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, 0, err
+	}
+	a := &bridge.VirtualAuthenticator{ID: "auth1", TabID: tabID, Protocol: opts.Protocol, Transport: opts.Transport,
+		HasResidentKey: opts.HasResidentKey, UserPresence: *opts.UserPresence}
+	m.auths[a.ID] = a
+	return a, 2, nil
+}
+
+func (m *webAuthnMockBridge) VirtualAuthenticators(string) []bridge.VirtualAuthenticator {
+	out := []bridge.VirtualAuthenticator{}
+	for _, a := range m.auths {
+		out = append(out, *a)
+	}
+	return out
+}
+
+func (m *webAuthnMockBridge) SetAuthenticatorPresence(_ context.Context, _, id string, userPresence, _ *bool) (*bridge.VirtualAuthenticator, error) {
+	a := m.auths[id]
+	if a == nil {
+		return nil, bridge.ErrAuthenticatorNotFound
+	}
+	if userPresence != nil {
+		a.UserPresence = *userPresence
+	}
+	return a, nil
+}
+
+func (m *webAuthnMockBridge) AddWebAuthnCredential(_ context.Context, _, id string, cred *bridge.WebAuthnCredential) error {
+	if m.auths[id] == nil {
+		return bridge.ErrAuthenticatorNotFound
+	}
+	m.imported = append(m.imported, cred)
+	return nil
+}
+
+func (m *webAuthnMockBridge) WebAuthnCredentials(_ context.Context, _, id string) ([]*bridge.WebAuthnCredential, error) {
+	if m.auths[id] == nil {
+		return nil, bridge.ErrAuthenticatorNotFound
+	}
+	return []*bridge.WebAuthnCredential{{CredentialID: "Y3JlZA==", RpID: "example.com", PrivateKey: "a2V5"}}, nil
+}
+
+func newWebAuthnHandler(enabled bool) (*Handlers, *webAuthnMockBridge) {
+	b := &webAuthnMockBridge{auths: map[string]*bridge.VirtualAuthenticator{}}
+	return New(b, &config.RuntimeConfig{AllowWebAuthn: enabled}, nil, nil, nil), b
+}
+
+func TestHandleWebAuthn_Disabled(t *testing.T) {
+	h, _ := newWebAuthnHandler(false)
+	req := httptest.NewRequest("POST", "/tabs/tab1/webauthn/authenticators", bytes.NewReader([]byte(`{}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabAddAuthenticator(w, req)
+	if w.Code != 403 || decodeBody(t, w)["code"] != "webauthn_disabled" {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleTabAddAuthenticator(t *testing.T) {
+	h, b := newWebAuthnHandler(true)
+	req := httptest.NewRequest("POST", "/tabs/tab1/webauthn/authenticators", bytes.NewReader([]byte(`{"hasResidentKey":true,"hasUserVerification":true}`)))
+	req.SetPathValue("id", "tab1")
+	w := httptest.NewRecorder()
+	h.HandleTabAddAuthenticator(w, req)
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	resp := decodeBody(t, w)
+	auth, _ := resp["authenticator"].(map[string]any)
+	if auth["protocol"] != "ctap2" || auth["transport"] != "internal" || resp["restored"] != float64(2) {
+		t.Fatalf("resp = %v", resp)
+	}
+	if len(b.auths) != 1 {
+		t.Fatalf("authenticators = %v", b.auths)
+	}
+
+	req = httptest.NewRequest("GET", "/tabs/tab1/webauthn", nil)
+	req.SetPathValue("id", "tab1")
+	w = httptest.NewRecorder()
+	h.HandleTabWebAuthn(w, req)
+	if list, _ := decodeBody(t, w)["authenticators"].([]any); len(list) != 1 {
+		t.Fatalf("list = %s", w.Body.String())
+	}
+}
+
+func TestHandleAddAuthenticator_InvalidOptions(t *testing.T) {
+	h, b := newWebAuthnHandler(true)
+	w := httptest.NewRecorder()
+	h.HandleAddAuthenticator(w, httptest.NewRequest("POST", "/webauthn/authenticators", bytes.NewReader([]byte(`{"protocol":"u2f","hasResidentKey":true}`))))
+	if w.Code != 400 || len(b.auths) != 0 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleTabAuthenticatorPresence(t *testing.T) {
+	h, b := newWebAuthnHandler(true)
+	b.auths["auth1"] = &bridge.VirtualAuthenticator{ID: "auth1", UserPresence: true}
+
+	req := httptest.NewRequest("POST", "/tabs/tab1/webauthn/authenticators/auth1/presence", bytes.NewReader([]byte(`{"userPresence":false}`)))
+	req.SetPathValue("id", "tab1")
+	req.SetPathValue("authenticatorId", "auth1")
+	w := httptest.NewRecorder()
+	h.HandleTabAuthenticatorPresence(w, req)
+	if w.Code != 200 || b.auths["auth1"].UserPresence {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/tabs/tab1/webauthn/authenticators/nope/presence", bytes.NewReader([]byte(`{"userPresence":true}`)))
+	req.SetPathValue("id", "tab1")
+	req.SetPathValue("authenticatorId", "nope")
+	w = httptest.NewRecorder()
+	h.HandleTabAuthenticatorPresence(w, req)
+	if w.Code != 404 {
+		t.Fatalf("unknown authenticator: status %d", w.Code)
+	}
+}
+
+func TestHandleTabImportWebAuthnCredential(t *testing.T) {
+	h, b := newWebAuthnHandler(true)
+	b.auths["auth1"] = &bridge.VirtualAuthenticator{ID: "auth1"}
+
+	importCred := func(body string) int {
+		req := httptest.NewRequest("POST", "/tabs/tab1/webauthn/authenticators/auth1/credentials", bytes.NewReader([]byte(body)))
+		req.SetPathValue("id", "tab1")
+		req.SetPathValue("authenticatorId", "auth1")
+		w := httptest.NewRecorder()
+		h.HandleTabImportWebAuthnCredential(w, req)
+		return w.Code
+	}
+	if code := importCred(`{"credential":{"credentialId":"Y3JlZA==","rpId":"example.com"}}`); code != 400 {
+		t.Fatalf("missing private key: status %d", code)
+	}
+	if code := importCred(`{"credential":{"credentialId":"Y3JlZA==","rpId":"example.com","privateKey":"a2V5"}}`); code != 200 {
+		t.Fatalf("import: status %d", code)
+	}
+	if len(b.imported) != 1 || b.imported[0].RpID != "example.com" {
+		t.Fatalf("imported = %v", b.imported)
+	}
+}
+
+func TestWebAuthnCredentials_RedactsPrivateKeysUnlessExported(t *testing.T) {
+	h, b := newWebAuthnHandler(true)
+	b.auths["auth1"] = &bridge.VirtualAuthenticator{ID: "auth1"}
+
+	call := func(handler http.HandlerFunc, method string, mutate func(*http.Request) *http.Request) (int, map[string]any) {
+		req := httptest.NewRequest(method, "/tabs/tab1/webauthn/authenticators/auth1/credentials", nil)
+		req.SetPathValue("id", "tab1")
+		req.SetPathValue("authenticatorId", "auth1")
+		if mutate != nil {
+			req = mutate(req)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		var out map[string]any
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out
+	}
+	privateKey := func(out map[string]any) any {
+		creds, _ := out["credentials"].([]any)
+		if len(creds) != 1 {
+			t.Fatalf("credentials = %v", out["credentials"])
+		}
+		return creds[0].(map[string]any)["privateKey"]
+	}
+
+	code, out := call(h.HandleTabWebAuthnCredentials, "GET", nil)
+	if code != 200 || privateKey(out) != "" || out["privateKeysRedacted"] != true {
+		t.Fatalf("list: %d %v", code, out)
+	}
+
+	code, out = call(h.HandleTabExportWebAuthnCredentials, "POST", nil)
+	if code != 200 || privateKey(out) != "a2V5" {
+		t.Fatalf("export: %d %v", code, out)
+	}
+
+	for name, mutate := range map[string]func(*http.Request) *http.Request{
+		"agent session": func(r *http.Request) *http.Request {
+			return session.WithSession(r, &session.Session{ID: "ses_1"})
+		},
+		"scoped token": func(r *http.Request) *http.Request {
+			return apitoken.WithToken(r, &apitoken.Token{ID: "tok_1"})
+		},
+	} {
+		if code, out := call(h.HandleTabExportWebAuthnCredentials, "POST", mutate); code != 403 || out["code"] != "export_forbidden" {
+			t.Fatalf("%s export: %d %v", name, code, out)
+		}
+	}
+}
+
+func TestWebAuthnExportIsAdminRoute(t *testing.T) {
+	for _, path := range []string{
+		"/webauthn/authenticators/auth1/credentials/export",
+		"/tabs/tab1/webauthn/authenticators/auth1/credentials/export",
+	} {
+		if !cookieAdminRoute(httptest.NewRequest("POST", path, nil)) {
+			t.Errorf("%s should need elevation", path)
+		}
+	}
+	if cookieAdminRoute(httptest.NewRequest("GET", "/webauthn/authenticators/auth1/credentials", nil)) {
+		t.Error("the redacted listing should not need elevation")
+	}
+}
@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/routes"
+	"github.com/pinchtab/pinchtab/internal/workflow"
+)
+
+// maxConcurrentWorkflowRuns caps the workflow runs in flight on one
+// instance, sync and async together.
+const maxConcurrentWorkflowRuns = 8
+
+// workflowRunRequest is the JSON body for POST /workflows/run. Workflow is
+// either an inline JSON document or a string holding YAML/JSON source.
+type workflowRunRequest struct {
+	TabID     string          `json:"tabId,omitempty"`
+	Owner     string          `json:"owner,omitempty"`
+	LockToken uint64          `json:"lockToken,omitempty"`
+	Workflow  json.RawMessage `json:"workflow"`
+	Vars      map[string]any  `json:"vars,omitempty"`
+	Async     bool            `json:"async,omitempty"`
+}
+
+// HandleWorkflowRun runs a declarative workflow against one tab.
+//
+// @Endpoint POST /workflows/run
+// @Description Run a YAML/JSON workflow of navigate, action, wait, extract, if and loop steps
+//
+// @Param tabId string body Tab to run against (optional - current tab when omitted)
+// @Param workflow object body Workflow document, or a string of YAML/JSON source (required)
+// @Param vars object body Variables overriding the document's vars (optional)
+// @Param async bool body Return 202 with a runId instead of waiting (optional, default: false)
+//
+// @Response 200 application/json Returns the finished run with outputs and per-step trace
+// @Response 202 application/json Returns {runId, status} for async runs
+// @Response 400 application/json Invalid workflow document
+// @Response 403 application/json Macro capability disabled
+// @Response 429 application/json Too many workflow runs in flight
+//
+// @Example curl run:
+//
+//	curl -X POST http://localhost:9867/workflows/run \
+//	  -H "Content-Type: application/json" \
+//	  -d '{"workflow":{"steps":[{"navigate":{"url":"https://pinchtab.com"}},{"id":"title","extract":{"selector":"h1"}}]}}'
+func (h *Handlers) HandleWorkflowRun(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.AllowMacro {
+		h.writeCapabilityDisabled(w, routes.CapMacro)
+		return
+	}
+
+	req, doc, ok := decodeWorkflowRunRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := doc.Validate(h.Bridge.AvailableActions()); err != nil {
+		httpx.ErrorCode(w, 400, "invalid_workflow", err.Error(), false, nil)
+		return
+	}
+	owner := resolveOwner(r, req.Owner)
+	lockToken := resolveLockToken(r, req.LockToken)
+
+	_, resolvedTabID, err := h.tabContext(r, req.TabID)
+	if err != nil {
+		WriteTabContextError(w, err, 404)
+		return
+	}
+	if err := h.enforceTabLease(resolvedTabID, owner, lockToken); err != nil {
+		writeTabLeaseError(w, err)
+		return
+	}
+
+	run := workflow.NewRun(doc, resolvedTabID, owner, req.Vars)
+	run.Caller = workflowCaller(r)
+	if !h.workflows.TryPut(run, maxConcurrentWorkflowRuns) {
+		httpx.ErrorCode(w, 429, "too_many_workflow_runs",
+			fmt.Sprintf("%d workflow runs are already in flight", maxConcurrentWorkflowRuns), true, nil)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "workflow.run", TabID: resolvedTabID})
+
+	if req.Async {
+		// The run outlives the request: keep its values (auth, session) but
+		// not its cancellation. POST /workflows/runs/{id}/cancel stops it.
+		ctx := context.WithoutCancel(r.Context())
+		runner := &workflowRunner{h: h, base: r.WithContext(ctx), owner: owner, lockToken: lockToken}
+		go workflow.Execute(ctx, run, doc, runner)
+		httpx.JSON(w, 202, map[string]any{"runId": run.ID, "status": workflow.StatusRunning, "tabId": resolvedTabID})
+		return
+	}
+
+	runner := &workflowRunner{h: h, base: r, owner: owner, lockToken: lockToken}
+	workflow.Execute(r.Context(), run, doc, runner)
+	httpx.JSON(w, 200, run.Snapshot())
+}
+
+// HandleWorkflowRunGet returns the progress or result of a workflow run.
+//
+// @Endpoint GET /workflows/runs/{id}
+// @Description Get a workflow run's status, outputs and per-step trace
+//
+// @Response 200 application/json Returns the run
+// @Response 404 application/json Unknown or expired run, or one started by another caller
+func (h *Handlers) HandleWorkflowRunGet(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.AllowMacro {
+		h.writeCapabilityDisabled(w, routes.CapMacro)
+		return
+	}
+	run, ok := h.callerWorkflowRun(w, r)
+	if !ok {
+		return
+	}
+	httpx.JSON(w, 200, run)
+}
+
+// HandleWorkflowRunCancel stops a running workflow at its next step.
+//
+// @Endpoint POST /workflows/runs/{id}/cancel
+// @Description Cancel a running workflow
+//
+// @Response 200 application/json Returns {runId, status: "cancelling"}
+// @Response 404 application/json Unknown or expired run, or one started by another caller
+// @Response 409 application/json The run has already finished
+func (h *Handlers) HandleWorkflowRunCancel(w http.ResponseWriter, r *http.Request) {
+	if !h.Config.AllowMacro {
+		h.writeCapabilityDisabled(w, routes.CapMacro)
+		return
+	}
+	run, ok := h.callerWorkflowRun(w, r)
+	if !ok {
+		return
+	}
+	if !h.workflows.Cancel(run.ID) {
+		httpx.ErrorCode(w, 409, "workflow_run_finished",
+			fmt.Sprintf("workflow run %q already %s", run.ID, run.Status), false, nil)
+		return
+	}
+	h.recordActivity(r, activity.Update{Action: "workflow.cancel", TabID: run.TabID})
+	httpx.JSON(w, 200, map[string]any{"runId": run.ID, "status": "cancelling"})
+}
+
+// callerWorkflowRun looks up the {id} run for the calling session, agent
+// or token. Runs of other callers answer 404 so their ids do not leak.
+func (h *Handlers) callerWorkflowRun(w http.ResponseWriter, r *http.Request) (*workflow.Run, bool) {
+	id := r.PathValue("id")
+	run := h.workflows.Get(id)
+	if run != nil {
+		if caller := workflowCaller(r); caller != "" && caller != run.Caller {
+			run = nil
+		}
+	}
+	if run == nil {
+		httpx.ErrorCode(w, 404, "not_found", fmt.Sprintf("workflow run %q not found", id), false, nil)
+		return nil, false
+	}
+	return run, true
+}
+
+// workflowCaller identifies who starts or reads a workflow run: the agent
+// session or agent id, else the scoped API token. Callers holding the
+// server token identify as "" and may read every run.
+func workflowCaller(r *http.Request) string {
+	if scope := currentTabScopeFromRequest(r); !scope.IsGlobal() {
+		return scope.key
+	}
+	if id := requestTokenID(r); id != "" {
+		return "token:" + id
+	}
+	return ""
+}
+
+// decodeWorkflowRunRequest accepts either the JSON envelope or, with a YAML
+// content type, the bare workflow document (tabId, owner and async then come
+// from the query string).
+func decodeWorkflowRunRequest(w http.ResponseWriter, r *http.Request) (workflowRunRequest, *workflow.Document, bool) {
+	var req workflowRunRequest
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		httpx.ErrorCode(w, 400, "bad_request", fmt.Sprintf("read body: %v", err), false, nil)
+		return req, nil, false
+	}
+
+	source := body
+	if isYAMLContentType(r.Header.Get("Content-Type")) {
+		q := r.URL.Query()
+		req.TabID = q.Get("tabId")
+		req.Owner = q.Get("owner")
+		req.Async = q.Get("async") == "true"
+	} else {
+		if err := json.Unmarshal(body, &req); err != nil {
+			httpx.ErrorCode(w, 400, "bad_request", fmt.Sprintf("decode: %v", err), false, nil)
+			return req, nil, false
+		}
+		source = req.Workflow
+		var text string
+		if err := json.Unmarshal(req.Workflow, &text); err == nil {
+			source = []byte(text)
+		}
+	}
+
+	doc, err := workflow.Parse(source)
+	if err != nil {
+		httpx.ErrorCode(w, 400, "invalid_workflow", err.Error(), false, nil)
+		return req, nil, false
+	}
+	return req, doc, true
+}
+
+func isYAMLContentType(ct string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// workflowRunner implements workflow.Runner on top of the existing handlers:
+// action, navigate and wait steps are dispatched in-process to HandleAction,
+// HandleNavigate and HandleWait so they get the same validation, policy
+// checks, action registry and wait semantics as the HTTP endpoints.
+type workflowRunner struct {
+	h         *Handlers
+	base      *http.Request
+	owner     string
+	lockToken uint64
+}
+
+func (wr *workflowRunner) Action(ctx context.Context, tabID string, params map[string]any) (any, error) {
+	setDefault(params, "tabId", tabID)
+	out, header, err := wr.dispatch(ctx, "/action", wr.h.HandleAction, params)
+	if err != nil {
+		return nil, err
+	}
+	// Follow a click that opened and auto-switched to a new tab.
+	if switched := header.Get(activity.HeaderPTTabID); switched != "" && switched != tabID {
+		out["tabId"] = switched
+	}
+	return out, nil
+}
+
+func (wr *workflowRunner) Navigate(ctx context.Context, tabID string, params map[string]any) (any, error) {
+	if newTab, _ := params["newTab"].(bool); !newTab {
+		setDefault(params, "tabId", tabID)
+	}
+	out, _, err := wr.dispatch(ctx, "/navigate", wr.h.HandleNavigate, params)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (wr *workflowRunner) Wait(ctx context.Context, tabID string, params map[string]any) (any, error) {
+	setDefault(params, "tabId", tabID)
+	out, _, err := wr.dispatch(ctx, "/wait", wr.h.HandleWait, params)
+	if err != nil {
+		return nil, err
+	}
+	if waited, _ := out["waited"].(bool); !waited {
+		msg, _ := out["error"].(string)
+		if msg == "" {
+			msg = "wait condition not met"
+		}
+		return out, fmt.Errorf("%s", msg)
+	}
+	return out, nil
+}
+
+func (wr *workflowRunner) Extract(ctx context.Context, tabID string, ex workflow.Extract) (any, error) {
+	fields, err := json.Marshal(ex.Fields)
+	if err != nil {
+		return nil, err
+	}
+	js := fmt.Sprintf(workflowExtractJS, jsonStr(ex.Selector), jsonStr(ex.Attr), ex.All, string(fields))
+	var raw string
+	pageURL, err := wr.evaluate(ctx, tabID, js, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("extract: %w", err)
+	}
+	// IDPI: scan the extracted content as /text does.
+	if result := wr.h.ContentGuard.Scan(raw, pageURL); result.Blocked {
+		return nil, fmt.Errorf("extract: content blocked by IDPI scanner: %s", result.BlockReason)
+	}
+	var out any
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, fmt.Errorf("extract: decode result: %w", err)
+	}
+	if out == nil && !ex.All {
+		return nil, fmt.Errorf("extract: no element matches %s", ex.Selector)
+	}
+	return out, nil
+}
+
+func (wr *workflowRunner) Check(ctx context.Context, tabID string, c workflow.Condition) (bool, error) {
+	var js string
+	switch {
+	case c.Exists != "":
+		js, _ = buildSelectorJS(c.Exists, "visible")
+	case c.NotExists != "":
+		js, _ = buildSelectorJS(c.NotExists, "hidden")
+	case c.Text != "":
+		js = fmt.Sprintf(`!!(document.body && document.body.innerText.includes(%s))`, jsonStr(c.Text))
+	case c.URL != "":
+		js = buildURLMatchJS(c.URL)
+	case c.Fn != "":
+		if !wr.h.evaluateEnabled() {
+			return false, fmt.Errorf("fn conditions require security.allowEvaluate")
+		}
+		js = fmt.Sprintf(`!!(function(){try{return %s}catch(e){return false}})()`, c.Fn)
+	default:
+		return false, fmt.Errorf("empty condition")
+	}
+	var matched bool
+	if _, err := wr.evaluate(ctx, tabID, js, &matched); err != nil {
+		return false, fmt.Errorf("condition: %w", err)
+	}
+	return matched, nil
+}
+
+// evaluate runs js in the tab, bounded by the workflow's ctx (the tab context
+// itself is owned by the browser, not the run). Like the read endpoints it
+// first applies the IDPI and API token domain policy to the tab's current
+// URL, which it returns when known.
+func (wr *workflowRunner) evaluate(ctx context.Context, tabID, js string, out any) (string, error) {
+	if err := wr.h.enforceTabNotPausedForHandoff(tabID); err != nil {
+		return "", err
+	}
+	tabCtx, _, err := wr.h.tabContext(wr.base, tabID)
+	if err != nil {
+		return "", err
+	}
+	rec := newCapturedResponse()
+	pageURL, ok := wr.h.enforceCurrentTabDomainPolicy(rec, wr.base, tabCtx, tabID)
+	if !ok {
+		return "", fmt.Errorf("%s (status %d)", rec.errorMessage(), rec.status)
+	}
+	tCtx, cancel := context.WithTimeout(tabCtx, wr.h.Config.ActionTimeout)
+	defer cancel()
+	go httpx.CancelOnClientDone(ctx, cancel)
+	return pageURL, wr.h.evalRuntime(tCtx, js, out, bridge.EvalOpts{})
+}
+
+// dispatch invokes handler in-process with params as the JSON body and
+// returns the decoded response. Non-2xx responses become errors carrying the
+// handler's error message.
+func (wr *workflowRunner) dispatch(ctx context.Context, path string, handler http.HandlerFunc, params map[string]any) (map[string]any, http.Header, error) {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode %s: %w", path, err)
+	}
+	sub := wr.base.Clone(ctx)
+	sub.Method = http.MethodPost
+	sub.URL = &url.URL{Path: path}
+	sub.RequestURI = path
+	sub.Body = io.NopCloser(bytes.NewReader(payload))
+	sub.ContentLength = int64(len(payload))
+	sub.Header.Set("Content-Type", "application/json")
+	if wr.owner != "" {
+		sub.Header.Set("X-Owner", wr.owner)
+	}
+	if wr.lockToken != 0 {
+		sub.Header.Set("X-Lock-Token", strconv.FormatUint(wr.lockToken, 10))
+	}
+
+	rec := newCapturedResponse()
+	handler(rec, sub)
+
+	var out map[string]any
+	if err := json.Unmarshal(rec.body.Bytes(), &out); err != nil {
+		return nil, nil, fmt.Errorf("%s: decode response (status %d): %w", path, rec.status, err)
+	}
+	if rec.status >= 300 {
+		return nil, nil, fmt.Errorf("%s: %s (status %d)", strings.TrimPrefix(path, "/"), rec.errorMessage(), rec.status)
+	}
+	return out, rec.header, nil
+}
+
+func setDefault(params map[string]any, key string, value any) {
+	if v, ok := params[key]; !ok || v == "" {
+		params[key] = value
+	}
+}
+
+// capturedResponse is a minimal http.ResponseWriter that buffers an
+// in-process sub-request's response.
+type capturedResponse struct {
+	header http.Header
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func newCapturedResponse() *capturedResponse {
+	return &capturedResponse{header: http.Header{}, status: http.StatusOK}
+}
+
+// errorMessage returns the "error" field of a buffered error response, or
+// the status text.
+func (c *capturedResponse) errorMessage() string {
+	var out map[string]any
+	_ = json.Unmarshal(c.body.Bytes(), &out)
+	if msg, _ := out["error"].(string); msg != "" {
+		return msg
+	}
+	return http.StatusText(c.status)
+}
+
+func (c *capturedResponse) Header() http.Header { return c.header }
+
+func (c *capturedResponse) WriteHeader(status int) {
+	if c.wrote {
+		return
+	}
+	c.status = status
+	c.wrote = true
+}
+
+func (c *capturedResponse) Write(b []byte) (int, error) {
+	c.wrote = true
+	return c.body.Write(b)
+}
+
+// workflowExtractJS reads text, HTML or an attribute from the elements
+// matching a selector (css:, xpath:, text: prefixes as for /wait). Field
+// specs may end in @attr to read an attribute instead of text. The result is
+// JSON-encoded so arbitrary shapes survive the CDP round trip.
+const workflowExtractJS = `(function(){
+	var sel = %s, attr = %s, all = %t, fields = %s;
+	function q(root, s, many) {
+		var out = [];
+		if (s.indexOf('xpath:') === 0 || s.indexOf('//') === 0 || s.indexOf('(//') === 0) {
+			var x = s.indexOf('xpath:') === 0 ? s.slice(6) : s;
+			var r = document.evaluate(x, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			for (var i = 0; i < r.snapshotLength; i++) out.push(r.snapshotItem(i));
+		} else if (s.indexOf('text:') === 0) {
+			var t = s.slice(5), w = document.createTreeWalker(root, NodeFilter.SHOW_TEXT);
+			while (w.nextNode()) {
+				var p = w.currentNode.parentElement;
+				if (p && w.currentNode.textContent.includes(t) && out.indexOf(p) < 0) out.push(p);
+			}
+		} else {
+			out = Array.prototype.slice.call(root.querySelectorAll(s.indexOf('css:') === 0 ? s.slice(4) : s));
+		}
+		return many ? out : out.slice(0, 1);
+	}
+	function read(el, a) {
+		if (!el) return null;
+		if (!a || a === 'text') return (el.innerText || el.textContent || '').trim();
+		if (a === 'html') return el.outerHTML;
+		return el.getAttribute(a);
+	}
+	var values = q(document, sel, all).map(function(el) {
+		if (!fields) return read(el, attr);
+		var o = {};
+		Object.keys(fields).forEach(function(k) {
+			var spec = fields[k], a = 'text', m = /@([\w-]+)$/.exec(spec);
+			if (m) { a = m[1]; spec = spec.slice(0, m.index); }
+			o[k] = read(spec ? q(el, spec, false)[0] : el, a);
+		});
+		return o;
+	});
+	return JSON.stringify(all ? values : (values.length ? values[0] : null));
+})()`
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/workflow"
+)
+
+func newWorkflowTestHandlers(m *mockBridge) *Handlers {
+	return New(m, &config.RuntimeConfig{ActionTimeout: time.Second, AllowMacro: true}, nil, nil, nil)
+}
+
+func workflowPageEval(expression string, result any) error {
+	switch out := result.(type) {
+	case *bool:
+		*out = strings.Contains(expression, ".banner")
+	case *string:
+		*out = `["first","second"]`
+	}
+	return nil
+}
+
+func TestHandleWorkflowRun_Sync(t *testing.T) {
+	m := &mockBridge{evaluateFn: workflowPageEval}
+	h := newWorkflowTestHandlers(m)
+
+	body := `{"workflow":{"steps":[
+		{"if":{"exists":".banner"},"then":[{"action":{"kind":"click"}}]},
+		{"id":"rows","extract":{"selector":".row","all":true}},
+		{"wait":{"ms":1}},
+		{"set":{"first":"${steps.rows.output.0}"}}
+	]}}`
+	req := httptest.NewRequest("POST", "/workflows/run", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var run workflow.Run
+	if err := json.Unmarshal(w.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if run.Status != workflow.StatusDone {
+		t.Fatalf("status = %s (%s), trace=%+v", run.Status, run.Error, run.Trace)
+	}
+	if run.Vars["first"] != "first" {
+		t.Errorf("vars = %+v", run.Vars)
+	}
+	if len(run.Trace) != 5 {
+		t.Errorf("trace has %d entries, want 5 (if, action, extract, wait, set)", len(run.Trace))
+	}
+	if run.Trace[1].Type != workflow.StepAction || run.Trace[1].Status != workflow.TraceOK {
+		t.Errorf("action trace = %+v", run.Trace[1])
+	}
+}
+
+func TestHandleWorkflowRun_YAMLAsyncAndGet(t *testing.T) {
+	h := newWorkflowTestHandlers(&mockBridge{evaluateFn: workflowPageEval})
+
+	doc := "steps:\n  - id: rows\n    extract: {selector: .row}\n"
+	req := httptest.NewRequest("POST", "/workflows/run?async=true", strings.NewReader(doc))
+	req.Header.Set("Content-Type", "application/yaml")
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+	if w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var accepted struct {
+		RunID string `json:"runId"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &accepted)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		req := httptest.NewRequest("GET", "/workflows/runs/"+accepted.RunID, nil)
+		req.SetPathValue("id", accepted.RunID)
+		w := httptest.NewRecorder()
+		h.HandleWorkflowRunGet(w, req)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var run workflow.Run
+		_ = json.Unmarshal(w.Body.Bytes(), &run)
+		if run.Status.IsTerminal() {
+			if run.Status != workflow.StatusDone {
+				t.Fatalf("status = %s (%s)", run.Status, run.Error)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("async run did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandleWorkflowRun_Rejections(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *config.RuntimeConfig
+		body   string
+		status int
+		code   string
+	}{
+		{"macro disabled", &config.RuntimeConfig{}, `{"workflow":{"steps":[{"wait":{"ms":1}}]}}`, 403, "macro_disabled"},
+		{"unknown kind", nil, `{"workflow":{"steps":[{"action":{"kind":"teleport"}}]}}`, 400, "invalid_workflow"},
+		{"bad yaml string", nil, `{"workflow":"steps: [unclosed"}`, 400, "invalid_workflow"},
+		{"missing workflow", nil, `{}`, 400, "invalid_workflow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWorkflowTestHandlers(&mockBridge{})
+			if tt.cfg != nil {
+				h.Config = tt.cfg
+			}
+			req := httptest.NewRequest("POST", "/workflows/run", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.HandleWorkflowRun(w, req)
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.code) {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body.String(), tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandleWorkflowRunGet_NotFound(t *testing.T) {
+	h := newWorkflowTestHandlers(&mockBridge{})
+	req := httptest.NewRequest("GET", "/workflows/runs/wfr_missing", nil)
+	req.SetPathValue("id", "wfr_missing")
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRunGet(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleWorkflowRunGet_ScopedToCaller(t *testing.T) {
+	h := newWorkflowTestHandlers(&mockBridge{evaluateFn: workflowPageEval})
+
+	req := httptest.NewRequest("POST", "/workflows/run", strings.NewReader(`{"tabId":"tab1","workflow":{"steps":[{"id":"rows","extract":{"selector":".row"}}]}}`))
+	req.Header.Set(activity.HeaderAgentID, "agent-a")
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+	if w.Code != 200 {
+		t.Fatalf("run status = %d: %s", w.Code, w.Body.String())
+	}
+	var run workflow.Run
+	_ = json.Unmarshal(w.Body.Bytes(), &run)
+
+	get := func(agent string) int {
+		req := httptest.NewRequest("GET", "/workflows/runs/"+run.ID, nil)
+		req.SetPathValue("id", run.ID)
+		if agent != "" {
+			req.Header.Set(activity.HeaderAgentID, agent)
+		}
+		w := httptest.NewRecorder()
+		h.HandleWorkflowRunGet(w, req)
+		return w.Code
+	}
+	if got := get("agent-a"); got != 200 {
+		t.Fatalf("creator GET = %d, want 200", got)
+	}
+	if got := get("agent-b"); got != 404 {
+		t.Fatalf("other agent GET = %d, want 404", got)
+	}
+	if got := get(""); got != 200 {
+		t.Fatalf("unscoped GET = %d, want 200", got)
+	}
+}
+
+func TestHandleWorkflowRunCancel(t *testing.T) {
+	h := newWorkflowTestHandlers(&mockBridge{})
+	doc, err := workflow.Parse([]byte(`{"steps":[{"wait":{"ms":1}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := workflow.NewRun(doc, "tab1", "", nil)
+	run.Caller = "agent:agent-a"
+	h.workflows.Put(run)
+
+	cancel := func(agent string) int {
+		req := httptest.NewRequest("POST", "/workflows/runs/"+run.ID+"/cancel", nil)
+		req.SetPathValue("id", run.ID)
+		req.Header.Set(activity.HeaderAgentID, agent)
+		w := httptest.NewRecorder()
+		h.HandleWorkflowRunCancel(w, req)
+		return w.Code
+	}
+	if got := cancel("agent-b"); got != 404 {
+		t.Fatalf("other agent cancel = %d, want 404", got)
+	}
+	if got := cancel("agent-a"); got != 200 {
+		t.Fatalf("creator cancel = %d, want 200", got)
+	}
+	workflow.Execute(context.Background(), run, doc, &workflowRunner{h: h, base: httptest.NewRequest("POST", "/workflows/run", nil)})
+	if got := run.Snapshot().Status; got != workflow.StatusCancelled {
+		t.Fatalf("status = %s, want cancelled", got)
+	}
+	if got := cancel("agent-a"); got != 409 {
+		t.Fatalf("cancel of a finished run = %d, want 409", got)
+	}
+}
+
+func TestHandleWorkflowRun_ConcurrencyLimit(t *testing.T) {
+	h := newWorkflowTestHandlers(&mockBridge{})
+	for i := range maxConcurrentWorkflowRuns {
+		h.workflows.Put(&workflow.Run{ID: fmt.Sprintf("wfr_busy%d", i), Status: workflow.StatusRunning})
+	}
+	req := httptest.NewRequest("POST", "/workflows/run", strings.NewReader(`{"workflow":{"steps":[{"wait":{"ms":1}}]}}`))
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, req)
+	if w.Code != 429 || !strings.Contains(w.Body.String(), "too_many_workflow_runs") {
+		t.Fatalf("got %d %s, want 429 too_many_workflow_runs", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleWorkflowRun_ExtractBlockedTabDomain(t *testing.T) {
+	b := &policyMockBridge{
+		mockBridge: mockBridge{evaluateFn: workflowPageEval},
+		state: bridge.TabPolicyState{
+			CurrentURL: "https://evil.example.net",
+			Blocked:    true,
+			Reason:     `domain "evil.example.net" is not in the allowed list`,
+			UpdatedAt:  time.Now(),
+		},
+		hasState: true,
+	}
+	h := New(b, &config.RuntimeConfig{
+		ActionTimeout:  time.Second,
+		AllowMacro:     true,
+		AllowedDomains: []string{"example.com"},
+		IDPI:           config.IDPIConfig{Enabled: true, StrictMode: true},
+	}, nil, nil, nil)
+
+	body := `{"tabId":"tab1","workflow":{"steps":[{"id":"body","extract":{"selector":"body"}}]}}`
+	w := httptest.NewRecorder()
+	h.HandleWorkflowRun(w, httptest.NewRequest("POST", "/workflows/run", bytes.NewReader([]byte(body))))
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var run workflow.Run
+	if err := json.Unmarshal(w.Body.Bytes(), &run); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if run.Status != workflow.StatusFailed || !strings.Contains(run.Error, "blocked by IDPI") {
+		t.Fatalf("status = %s, error = %q, want failed on the blocked domain", run.Status, run.Error)
+	}
+	if out := run.Outputs["body"]; out != nil {
+		t.Fatalf("outputs leaked content from a blocked tab: %+v", run.Outputs)
+	}
+}
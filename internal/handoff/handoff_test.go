@@ -0,0 +1,129 @@
+package handoff
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyLink_RoundTrip(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	claims := LinkClaims{TabID: "tab_1", Generation: 42, ExpiresAt: now.Add(time.Minute).Unix()}
+	token, err := SignLink("secret", claims)
+	if err != nil {
+		t.Fatalf("SignLink: %v", err)
+	}
+	got, err := VerifyLink("secret", token, now)
+	if err != nil {
+		t.Fatalf("VerifyLink: %v", err)
+	}
+	if got != claims {
+		t.Fatalf("claims = %+v, want %+v", got, claims)
+	}
+}
+
+func TestVerifyLink_Rejects(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	token, err := SignLink("secret", LinkClaims{TabID: "tab_1", ExpiresAt: now.Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatalf("SignLink: %v", err)
+	}
+	body, sig, _ := strings.Cut(token, ".")
+	forged, _ := SignLink("secret", LinkClaims{TabID: "tab_2", ExpiresAt: now.Add(time.Minute).Unix()})
+	forgedBody, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		now    time.Time
+		want   error
+	}{
+		{"wrong secret", "other", token, now, ErrInvalidLink},
+		{"empty secret", "", token, now, ErrInvalidLink},
+		{"swapped payload", "secret", forgedBody + "." + sig, now, ErrInvalidLink},
+		{"missing signature", "secret", body, now, ErrInvalidLink},
+		{"garbage", "secret", "not-a-token", now, ErrInvalidLink},
+		{"expired", "secret", token, now.Add(2 * time.Minute), ErrLinkExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyLink(tt.secret, tt.token, tt.now); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignLink_RequiresSecret(t *testing.T) {
+	if _, err := SignLink(" ", LinkClaims{TabID: "tab_1"}); err == nil {
+		t.Fatal("expected error without a secret")
+	}
+}
+
+func TestTakeoverURL(t *testing.T) {
+	got := TakeoverURL("https://pinch.example/", "tab_1", "a.b")
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("parse %q: %v", got, err)
+	}
+	if u.Host != "pinch.example" || u.Path != TakeoverPath {
+		t.Fatalf("url = %q", got)
+	}
+	if u.Query().Get("tab") != "tab_1" || u.Query().Get("t") != "a.b" {
+		t.Fatalf("query = %q", u.RawQuery)
+	}
+	if rel := TakeoverURL("", "tab_1", "a.b"); !strings.HasPrefix(rel, TakeoverPath+"?") {
+		t.Fatalf("relative url = %q", rel)
+	}
+}
+
+func TestNewNotifier_NilWithoutTargets(t *testing.T) {
+	if n := NewNotifier([]string{" ", ""}, "s"); n != nil {
+		t.Fatal("expected nil notifier without webhook urls")
+	}
+	var n *Notifier
+	n.Notify(Event{Event: EventRequested, TabID: "tab_1"}) // must not panic
+}
+
+func TestNotifier_DeliversSignedEvent(t *testing.T) {
+	type delivery struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan delivery, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- delivery{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := NewNotifier([]string{srv.URL}, "hook-secret")
+	n.Notify(Event{Event: EventRequested, TabID: "tab_1", TakeoverURL: "https://x/dashboard/takeover?t=1"})
+
+	select {
+	case d := <-got:
+		if d.header.Get("X-PinchTab-Event") != EventRequested {
+			t.Fatalf("event header = %q", d.header.Get("X-PinchTab-Event"))
+		}
+		if want := Signature("hook-secret", d.body); d.header.Get(SignatureHeader) != want {
+			t.Fatalf("signature = %q, want %q", d.header.Get(SignatureHeader), want)
+		}
+		var ev Event
+		if err := json.Unmarshal(d.body, &ev); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if ev.TabID != "tab_1" || ev.TakeoverURL == "" || ev.Time == "" {
+			t.Fatalf("event = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
@@ -0,0 +1,109 @@
+// Package handoff carries the out-of-band side of a human handoff: signed
+// takeover links that let an operator open a paused tab without an API token,
+// and the webhook notifications that tell them to.
+package handoff
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidLink is returned for tokens that are malformed, signed with a
+	// different key, or scoped to another tab.
+	ErrInvalidLink = errors.New("invalid takeover link")
+	// ErrLinkExpired is returned for well-formed tokens past their expiry.
+	ErrLinkExpired = errors.New("takeover link expired")
+)
+
+// linkKeyLabel domain-separates the link key from every other use of the
+// server token.
+const linkKeyLabel = "pinchtab-handoff-link-v1"
+
+// TakeoverPath is the dashboard route that renders the takeover view.
+const TakeoverPath = "/dashboard/takeover"
+
+// LinkClaims is the payload of a takeover link.
+type LinkClaims struct {
+	TabID string `json:"tab"`
+	// Generation pins the link to one pause of the tab (the pause timestamp in
+	// nanoseconds), so a link minted for an earlier handoff stops working once
+	// the tab is resumed and paused again.
+	Generation int64 `json:"gen"`
+	ExpiresAt  int64 `json:"exp"` // Unix seconds
+}
+
+// Expiry returns the claims' expiry as a time.
+func (c LinkClaims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// SignLink mints a takeover token for claims. secret is the server token,
+// which every instance shares with the orchestrator, so a link minted by the
+// instance owning the tab verifies at the orchestrator's front door too.
+func SignLink(secret string, claims LinkClaims) (string, error) {
+	if strings.TrimSpace(secret) == "" {
+		return "", errors.New("handoff links require a server token")
+	}
+	if claims.TabID == "" {
+		return "", errors.New("tab id required")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(linkMAC(secret, body)), nil
+}
+
+// VerifyLink checks token's signature and expiry and returns its claims.
+func VerifyLink(secret, token string, now time.Time) (LinkClaims, error) {
+	if strings.TrimSpace(secret) == "" {
+		return LinkClaims{}, ErrInvalidLink
+	}
+	body, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || body == "" || sig == "" {
+		return LinkClaims{}, ErrInvalidLink
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || subtle.ConstantTimeCompare(got, linkMAC(secret, body)) != 1 {
+		return LinkClaims{}, ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return LinkClaims{}, ErrInvalidLink
+	}
+	var claims LinkClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.TabID == "" {
+		return LinkClaims{}, ErrInvalidLink
+	}
+	if !now.Before(claims.Expiry()) {
+		return claims, ErrLinkExpired
+	}
+	return claims, nil
+}
+
+// TakeoverURL builds the link a human follows. With an empty publicURL the
+// result is root-relative, which the dashboard resolves against its own
+// origin.
+func TakeoverURL(publicURL, tabID, token string) string {
+	q := url.Values{}
+	q.Set("tab", tabID)
+	q.Set("t", token)
+	return strings.TrimRight(publicURL, "/") + TakeoverPath + "?" + q.Encode()
+}
+
+func linkMAC(secret, body string) []byte {
+	key := hmac.New(sha256.New, []byte(secret))
+	key.Write([]byte(linkKeyLabel))
+	mac := hmac.New(sha256.New, key.Sum(nil))
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,144 @@
+package handoff
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	internalurls "github.com/pinchtab/pinchtab/internal/urls"
+)
+
+// Webhook event names, sent in the X-PinchTab-Event header and the body.
+const (
+	EventRequested = "handoff.requested"
+	EventResumed   = "handoff.resumed"
+)
+
+// SignatureHeader carries "sha256=<hex HMAC of the body>" when a webhook
+// secret is configured.
+const SignatureHeader = "X-PinchTab-Signature"
+
+const (
+	webhookTimeout      = 10 * time.Second
+	maxInflightWebhooks = 16
+)
+
+// Event is the JSON body POSTed to handoff webhooks.
+type Event struct {
+	Event       string         `json:"event"`
+	TabID       string         `json:"tabId"`
+	Reason      string         `json:"reason,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Hint        string         `json:"hint,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	TakeoverURL string         `json:"takeoverUrl,omitempty"`
+	ExpiresAt   string         `json:"expiresAt,omitempty"`
+	Status      string         `json:"status,omitempty"`
+	Data        map[string]any `json:"resolvedData,omitempty"`
+	ResumedBy   string         `json:"resumedBy,omitempty"`
+	Time        string         `json:"time"`
+}
+
+// Notifier delivers handoff events to the configured webhooks. Delivery is
+// best-effort and asynchronous: failures are logged, never surfaced to the
+// request that triggered the handoff.
+type Notifier struct {
+	urls   []string
+	secret string
+	client *http.Client
+	sem    chan struct{}
+}
+
+// NewNotifier returns a notifier for urls, or nil when there are none. A nil
+// *Notifier is safe to call.
+func NewNotifier(urls []string, secret string) *Notifier {
+	var targets []string
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	return &Notifier{
+		urls:   targets,
+		secret: secret,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		sem: make(chan struct{}, maxInflightWebhooks),
+	}
+}
+
+// Notify fans ev out to every webhook in the background.
+func (n *Notifier) Notify(ev Event) {
+	if n == nil {
+		return
+	}
+	if ev.Time == "" {
+		ev.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		slog.Warn("handoff webhook: failed to marshal event", "tab", ev.TabID, "err", err)
+		return
+	}
+	for _, target := range n.urls {
+		select {
+		case n.sem <- struct{}{}:
+			go func(target string) {
+				defer func() { <-n.sem }()
+				n.send(target, ev, payload)
+			}(target)
+		default:
+			slog.Warn("handoff webhook: too many in-flight deliveries, dropping", "tab", ev.TabID, "event", ev.Event)
+		}
+	}
+}
+
+func (n *Notifier) send(target string, ev Event, payload []byte) {
+	logURL := internalurls.RedactForLog(target)
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		slog.Warn("handoff webhook: failed to create request", "url", logURL, "err", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-PinchTab-Event", ev.Event)
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Signature(n.secret, payload))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		slog.Warn("handoff webhook: delivery failed", "tab", ev.TabID, "url", logURL, "err", err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Warn("handoff webhook: non-success response", "tab", ev.TabID, "url", logURL, "status", resp.StatusCode)
+		return
+	}
+	slog.Info("handoff webhook: delivered", "tab", ev.TabID, "event", ev.Event, "url", logURL)
+}
+
+// Signature is the SignatureHeader value for body under secret. Receivers
+// recompute it over the raw request body to authenticate a delivery.
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,61 @@
+package allocation
+
+import (
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// Requirements are what a request needs from the instance that serves it.
+// Empty fields match any instance.
+type Requirements struct {
+	Browser     string // browser provider, already normalized
+	Mode        string // "headless" or "headed"
+	ProxyRegion string
+	// Allowed limits the instances the caller may use, such as those an API
+	// token is restricted to.
+	Allowed func(bridge.Instance) bool
+}
+
+// IsZero reports whether the request needs nothing in particular.
+func (r Requirements) IsZero() bool {
+	return r.Browser == "" && r.Mode == "" && r.ProxyRegion == "" && r.Allowed == nil
+}
+
+// Matches reports whether inst meets every requirement.
+func (r Requirements) Matches(inst bridge.Instance) bool {
+	if r.Browser != "" && inst.Browser != r.Browser {
+		return false
+	}
+	if r.Mode != "" && instanceMode(inst) != r.Mode {
+		return false
+	}
+	if r.ProxyRegion != "" && !strings.EqualFold(inst.ProxyRegion, r.ProxyRegion) {
+		return false
+	}
+	if r.Allowed != nil && !r.Allowed(inst) {
+		return false
+	}
+	return true
+}
+
+// Filter returns the candidates that meet req, in their original order.
+func Filter(candidates []bridge.Instance, req Requirements) []bridge.Instance {
+	if req.IsZero() {
+		return candidates
+	}
+	out := make([]bridge.Instance, 0, len(candidates))
+	for _, c := range candidates {
+		if req.Matches(c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func instanceMode(inst bridge.Instance) string {
+	if inst.Mode == "headless" || inst.Mode == "headed" {
+		return inst.Mode
+	}
+	return bridge.ModeFromHeadless(inst.Headless)
+}
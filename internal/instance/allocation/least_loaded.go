@@ -0,0 +1,176 @@
+package allocation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+// Load is how busy an instance is. Scrapes lag behind; LeastLoaded adds its
+// own picks since the last reading.
+type Load struct {
+	Tabs            int
+	QueuedActions   int     // tab-executor actions waiting for a slot
+	InFlightActions int     // tab-executor actions running
+	LatencyMs       float64 // mean request latency since the previous reading
+	MemoryMB        float64 // browser process tree RSS
+	At              time.Time
+}
+
+// LoadSource reports the latest load of an instance.
+type LoadSource interface {
+	InstanceLoad(instanceID string) (Load, bool)
+}
+
+// LoadAware is implemented by policies that need instance load. The
+// orchestrator injects its LoadSource when such a policy is set.
+type LoadAware interface {
+	SetLoadSource(src LoadSource)
+}
+
+// Weights scale each load signal in the LeastLoaded score. Every signal is
+// normalized against the busiest candidate first, so weights are relative.
+type Weights struct {
+	Tabs     float64 `json:"tabs,omitempty"`
+	Queue    float64 `json:"queue,omitempty"`
+	InFlight float64 `json:"inflight,omitempty"`
+	Latency  float64 `json:"latency,omitempty"`
+	Memory   float64 `json:"memory,omitempty"`
+}
+
+// IsZero reports whether no weight is set.
+func (w Weights) IsZero() bool {
+	return w == Weights{}
+}
+
+// DefaultWeights favor the executor signals, which react first to load.
+var DefaultWeights = Weights{Tabs: 1, Queue: 2, InFlight: 2, Latency: 1, Memory: 1}
+
+// LeastLoaded picks the candidate with the lowest weighted load score.
+// Candidates without a load reading rank after those with one; ties keep
+// candidate order.
+type LeastLoaded struct {
+	name    string
+	weights Weights
+
+	mu     sync.Mutex
+	source LoadSource
+	picks  map[string]pickCount
+}
+
+// pickCount is how often an instance was picked since its load reading At.
+type pickCount struct {
+	at time.Time
+	n  int
+}
+
+// NewLeastLoaded creates the least_loaded policy with DefaultWeights.
+func NewLeastLoaded() *LeastLoaded {
+	return &LeastLoaded{name: "least_loaded", weights: DefaultWeights, picks: map[string]pickCount{}}
+}
+
+// NewWeighted creates the weighted policy. Zero weights fall back to
+// DefaultWeights.
+func NewWeighted(w Weights) *LeastLoaded {
+	if w.IsZero() {
+		w = DefaultWeights
+	}
+	return &LeastLoaded{name: "weighted", weights: w, picks: map[string]pickCount{}}
+}
+
+func (p *LeastLoaded) Name() string { return p.name }
+
+// Weights returns the weights the policy scores with.
+func (p *LeastLoaded) Weights() Weights { return p.weights }
+
+func (p *LeastLoaded) SetLoadSource(src LoadSource) {
+	p.mu.Lock()
+	p.source = src
+	p.mu.Unlock()
+}
+
+func (p *LeastLoaded) Select(candidates []bridge.Instance) (bridge.Instance, error) {
+	if len(candidates) == 0 {
+		return bridge.Instance{}, ErrNoCandidates
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.source == nil {
+		return candidates[0], nil
+	}
+
+	loads := make([]Load, len(candidates))
+	known := make([]bool, len(candidates))
+	var peak Load
+	for i, c := range candidates {
+		l, ok := p.source.InstanceLoad(c.ID)
+		if !ok {
+			continue
+		}
+		if pc := p.picks[c.ID]; pc.at.Equal(l.At) {
+			l.InFlightActions += pc.n
+		}
+		loads[i], known[i] = l, true
+		peak.Tabs = max(peak.Tabs, l.Tabs)
+		peak.QueuedActions = max(peak.QueuedActions, l.QueuedActions)
+		peak.InFlightActions = max(peak.InFlightActions, l.InFlightActions)
+		peak.LatencyMs = max(peak.LatencyMs, l.LatencyMs)
+		peak.MemoryMB = max(peak.MemoryMB, l.MemoryMB)
+	}
+
+	best := -1
+	bestScore := 0.0
+	for i := range candidates {
+		if !known[i] {
+			continue
+		}
+		score := p.score(loads[i], peak)
+		if best < 0 || score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return candidates[0], nil
+	}
+
+	id := candidates[best].ID
+	pc := p.picks[id]
+	if !pc.at.Equal(loads[best].At) {
+		pc = pickCount{at: loads[best].At}
+	}
+	pc.n++
+	p.picks[id] = pc
+	if len(p.picks) > 4*len(candidates)+16 {
+		p.prunePicks(candidates)
+	}
+	return candidates[best], nil
+}
+
+func (p *LeastLoaded) score(l, peak Load) float64 {
+	ratio := func(v, max float64) float64 {
+		if max <= 0 {
+			return 0
+		}
+		return v / max
+	}
+	w := p.weights
+	return w.Tabs*ratio(float64(l.Tabs), float64(peak.Tabs)) +
+		w.Queue*ratio(float64(l.QueuedActions), float64(peak.QueuedActions)) +
+		w.InFlight*ratio(float64(l.InFlightActions), float64(peak.InFlightActions)) +
+		w.Latency*ratio(l.LatencyMs, peak.LatencyMs) +
+		w.Memory*ratio(l.MemoryMB, peak.MemoryMB)
+}
+
+// prunePicks drops counts of instances that are no longer candidates.
+func (p *LeastLoaded) prunePicks(candidates []bridge.Instance) {
+	keep := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		keep[c.ID] = true
+	}
+	for id := range p.picks {
+		if !keep[id] {
+			delete(p.picks, id)
+		}
+	}
+}
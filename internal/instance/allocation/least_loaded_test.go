@@ -0,0 +1,140 @@
+package allocation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+type loadMap map[string]allocation.Load
+
+func (m loadMap) InstanceLoad(id string) (allocation.Load, bool) {
+	l, ok := m[id]
+	return l, ok
+}
+
+func TestLeastLoaded_PicksLowestScore(t *testing.T) {
+	at := time.Now()
+	p := allocation.NewLeastLoaded()
+	p.SetLoadSource(loadMap{
+		"a": {Tabs: 10, QueuedActions: 4, InFlightActions: 2, LatencyMs: 900, MemoryMB: 800, At: at},
+		"b": {Tabs: 2, InFlightActions: 1, LatencyMs: 100, MemoryMB: 300, At: at},
+		"c": {Tabs: 6, QueuedActions: 1, InFlightActions: 2, LatencyMs: 400, MemoryMB: 500, At: at},
+	})
+	got, err := p.Select(candidates("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "b" {
+		t.Errorf("expected b, got %s", got.ID)
+	}
+}
+
+func TestLeastLoaded_SpreadsBetweenScrapes(t *testing.T) {
+	at := time.Now()
+	p := allocation.NewLeastLoaded()
+	p.SetLoadSource(loadMap{"a": {At: at}, "b": {At: at}})
+
+	seen := map[string]int{}
+	for range 4 {
+		got, err := p.Select(candidates("a", "b"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[got.ID]++
+	}
+	if seen["a"] != 2 || seen["b"] != 2 {
+		t.Errorf("picks between scrapes should alternate, got %v", seen)
+	}
+}
+
+func TestLeastLoaded_UnknownLoadRanksLast(t *testing.T) {
+	p := allocation.NewLeastLoaded()
+	p.SetLoadSource(loadMap{"b": {Tabs: 50, QueuedActions: 10, At: time.Now()}})
+	got, err := p.Select(candidates("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "b" {
+		t.Errorf("expected the instance with a reading, got %s", got.ID)
+	}
+}
+
+func TestLeastLoaded_NoSourceSelectsFirst(t *testing.T) {
+	got, err := allocation.NewLeastLoaded().Select(candidates("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "a" {
+		t.Errorf("expected a, got %s", got.ID)
+	}
+}
+
+func TestLeastLoaded_EmptyReturnsError(t *testing.T) {
+	if _, err := allocation.NewLeastLoaded().Select(nil); err == nil {
+		t.Error("expected error for empty candidates")
+	}
+}
+
+func TestWeighted_WeightsChangeThePick(t *testing.T) {
+	at := time.Now()
+	loads := loadMap{
+		"a": {Tabs: 1, MemoryMB: 2000, At: at},
+		"b": {Tabs: 20, MemoryMB: 200, At: at},
+	}
+
+	byTabs := allocation.NewWeighted(allocation.Weights{Tabs: 1})
+	byTabs.SetLoadSource(loads)
+	if got, _ := byTabs.Select(candidates("a", "b")); got.ID != "a" {
+		t.Errorf("tabs weight: expected a, got %s", got.ID)
+	}
+
+	byMemory := allocation.NewWeighted(allocation.Weights{Memory: 1})
+	byMemory.SetLoadSource(loads)
+	if got, _ := byMemory.Select(candidates("a", "b")); got.ID != "b" {
+		t.Errorf("memory weight: expected b, got %s", got.ID)
+	}
+}
+
+func TestWeighted_ZeroWeightsUseDefaults(t *testing.T) {
+	p := allocation.NewWeighted(allocation.Weights{})
+	if p.Weights() != allocation.DefaultWeights {
+		t.Errorf("weights = %+v, want defaults", p.Weights())
+	}
+}
+
+func TestFilter_Requirements(t *testing.T) {
+	insts := []bridge.Instance{
+		{ID: "chrome-headless", Browser: "chrome", Mode: "headless", Headless: true},
+		{ID: "chrome-headed", Browser: "chrome", Mode: "headed", ProxyRegion: "eu-west"},
+		{ID: "cloak-eu", Browser: "cloak", Headless: true, ProxyRegion: "eu-west"},
+	}
+	tests := []struct {
+		name string
+		req  allocation.Requirements
+		want []string
+	}{
+		{"none", allocation.Requirements{}, []string{"chrome-headless", "chrome-headed", "cloak-eu"}},
+		{"browser", allocation.Requirements{Browser: "chrome"}, []string{"chrome-headless", "chrome-headed"}},
+		{"headed", allocation.Requirements{Mode: "headed"}, []string{"chrome-headed"}},
+		{"headless from flag", allocation.Requirements{Mode: "headless"}, []string{"chrome-headless", "cloak-eu"}},
+		{"region ignores case", allocation.Requirements{ProxyRegion: "EU-West"}, []string{"chrome-headed", "cloak-eu"}},
+		{"all", allocation.Requirements{Browser: "cloak", Mode: "headless", ProxyRegion: "eu-west"}, []string{"cloak-eu"}},
+		{"no match", allocation.Requirements{ProxyRegion: "us-east"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocation.Filter(insts, tt.req)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d instances, want %v", len(got), tt.want)
+			}
+			for i, inst := range got {
+				if inst.ID != tt.want[i] {
+					t.Errorf("got[%d] = %s, want %s", i, inst.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
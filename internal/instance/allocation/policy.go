@@ -31,7 +31,11 @@ func New(name string) (Policy, error) {
 		return NewRoundRobin(), nil
 	case "random":
 		return &Random{}, nil
+	case "least_loaded":
+		return NewLeastLoaded(), nil
+	case "weighted":
+		return NewWeighted(DefaultWeights), nil
 	default:
-		return nil, fmt.Errorf("unknown allocation policy: %q (available: fcfs, round_robin, random)", name)
+		return nil, fmt.Errorf("unknown allocation policy: %q (available: fcfs, round_robin, random, least_loaded, weighted)", name)
 	}
 }
@@ -94,6 +94,8 @@ func TestNew_KnownPolicies(t *testing.T) {
 		{"", "fcfs"},
 		{"round_robin", "round_robin"},
 		{"random", "random"},
+		{"least_loaded", "least_loaded"},
+		{"weighted", "weighted"},
 	}
 	for _, tt := range tests {
 		p, err := allocation.New(tt.name)
@@ -3,6 +3,7 @@ package instance_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	bridgepkg "github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/instance"
@@ -257,6 +258,24 @@ func TestLocator_StaleCache_InstanceGone(t *testing.T) {
 	}
 }
 
+func TestLocator_AliasFollowsMigrationsUntilExpiry(t *testing.T) {
+	locator := instance.NewLocator(instance.NewRepository(newMockLauncher()), newMockFetcher())
+
+	locator.Alias("tab_a", "tab_b", time.Minute)
+	locator.Alias("tab_b", "tab_c", time.Minute)
+	if got, ok := locator.ResolveAlias("tab_a"); !ok || got != "tab_c" {
+		t.Fatalf("ResolveAlias(tab_a) = %q, %v; want tab_c", got, ok)
+	}
+	if _, ok := locator.ResolveAlias("tab_c"); ok {
+		t.Error("a live tab should have no alias")
+	}
+
+	locator.Alias("tab_x", "tab_y", -time.Second)
+	if _, ok := locator.ResolveAlias("tab_x"); ok {
+		t.Error("an expired alias should not resolve")
+	}
+}
+
 func TestAllocator_FCFS(t *testing.T) {
 	launcher := newMockLauncher()
 	repo := instance.NewRepository(launcher)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/bridge"
 )
@@ -12,8 +13,9 @@ import (
 // Uses an in-memory cache for O(1) lookups, falling back to
 // querying bridge instances on cache miss.
 type Locator struct {
-	mu    sync.RWMutex
-	cache map[string]string // tabID → instanceID
+	mu      sync.RWMutex
+	cache   map[string]string   // tabID → instanceID
+	aliases map[string]tabAlias // old tabID → migrated tabID
 
 	repo    *Repository
 	fetcher TabFetcher
@@ -23,6 +25,7 @@ type Locator struct {
 func NewLocator(repo *Repository, fetcher TabFetcher) *Locator {
 	return &Locator{
 		cache:   make(map[string]string),
+		aliases: make(map[string]tabAlias),
 		repo:    repo,
 		fetcher: fetcher,
 	}
@@ -127,3 +130,43 @@ func (l *Locator) CacheSize() int {
 	defer l.mu.RUnlock()
 	return len(l.cache)
 }
+
+// tabAlias points a tab id that no longer exists at the tab that replaced
+// it, until expires.
+type tabAlias struct {
+	to      string
+	expires time.Time
+}
+
+// Alias records that tab from now lives on as tab to, for ttl.
+func (l *Locator) Alias(from, to string, ttl time.Duration) {
+	if from == "" || to == "" || from == to {
+		return
+	}
+	now := time.Now()
+	l.mu.Lock()
+	for id, a := range l.aliases {
+		if now.After(a.expires) {
+			delete(l.aliases, id)
+		}
+	}
+	l.aliases[from] = tabAlias{to: to, expires: now.Add(ttl)}
+	l.mu.Unlock()
+}
+
+// ResolveAlias returns the tab that replaced tabID, following chains left
+// by repeated migrations. It reports false when tabID has no live alias.
+func (l *Locator) ResolveAlias(tabID string) (string, bool) {
+	now := time.Now()
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	resolved := tabID
+	for range len(l.aliases) {
+		a, ok := l.aliases[resolved]
+		if !ok || now.After(a.expires) {
+			break
+		}
+		resolved = a.to
+	}
+	return resolved, resolved != tabID
+}
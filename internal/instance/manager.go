@@ -1,6 +1,8 @@
 package instance
 
 import (
+	"time"
+
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 )
@@ -68,6 +70,16 @@ func (m *Manager) InvalidateTab(tabID string) {
 	m.Locator.Invalidate(tabID)
 }
 
+// AliasTab routes the old id of a migrated tab to its new id for ttl.
+func (m *Manager) AliasTab(from, to string, ttl time.Duration) {
+	m.Locator.Alias(from, to, ttl)
+}
+
+// ResolveTabAlias returns the id that replaced a migrated tab.
+func (m *Manager) ResolveTabAlias(tabID string) (string, bool) {
+	return m.Locator.ResolveAlias(tabID)
+}
+
 // Allocate selects a running instance using the configured policy.
 func (m *Manager) Allocate() (*bridge.Instance, error) {
 	return m.Allocator.Allocate()
@@ -62,6 +62,10 @@ func handlerMap(c *Client) map[string]func(context.Context, mcp.CallToolRequest)
 
 		"pinchtab_dialog": handleDialog(c),
 
+		"pinchtab_permissions":       handlePermissions(c),
+		"pinchtab_set_permission":    handleSetPermission(c),
+		"pinchtab_reset_permissions": handleResetPermissions(c),
+
 		"pinchtab_scrape": handleScrape(c),
 	}
 }
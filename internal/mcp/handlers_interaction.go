@@ -172,6 +172,13 @@ func handleAction(c *Client, kind string) func(context.Context, mcp.CallToolRequ
 			payload["value"] = value
 		}
 
+		if epoch := optTrimmedString(r, "expectedEpoch"); epoch != "" {
+			payload["expectedEpoch"] = epoch
+			if remap, ok := optBool(r, "remapOnStale"); ok && remap {
+				payload["remapOnStale"] = true
+			}
+		}
+
 		body, code, err := c.Post(ctx, "/action", payload)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
@@ -50,6 +50,23 @@ func TestHandleClickMode(t *testing.T) {
 	}
 }
 
+func TestHandleClickExpectedEpoch(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_click", map[string]any{
+		"ref":           "e5",
+		"expectedEpoch": "ep_abc",
+		"remapOnStale":  true,
+	}, srv)
+
+	resp := resultJSON(t, r)
+	body, _ := resp["body"].(map[string]any)
+	if body["expectedEpoch"] != "ep_abc" || body["remapOnStale"] != true {
+		t.Fatalf("epoch fields not forwarded: %v", body)
+	}
+}
+
 func TestHandleClickModeRejectsInvalidValue(t *testing.T) {
 	srv := mockPinchTab()
 	defer srv.Close()
@@ -108,6 +108,13 @@ func handleNetworkRoute(c *Client) func(context.Context, mcp.CallToolRequest) (*
 		if method := optString(r, "method"); method != "" {
 			payload["method"] = method
 		}
+		// Frames go through as given; the server validates their shape.
+		if frames, ok := r.GetArguments()["frames"].([]any); ok && len(frames) > 0 {
+			payload["frames"] = frames
+		}
+		if drop := optString(r, "dropPattern"); drop != "" {
+			payload["dropPattern"] = drop
+		}
 
 		path := "/tabs/" + url.PathEscape(tabID) + "/network/route"
 		respBody, code, err := c.Post(ctx, path, payload)
@@ -111,6 +111,27 @@ func TestHandleNetworkRoute_Abort(t *testing.T) {
 	}
 }
 
+func TestHandleNetworkRoute_WebSocketFrames(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_network_route", map[string]any{
+		"tabId":        "t1",
+		"pattern":      "wss://feed.test/*",
+		"action":       "continue",
+		"resourceType": "websocket",
+		"frames":       []any{map[string]any{"data": "hello", "delayMs": float64(100)}},
+		"dropPattern":  "*heartbeat*",
+	}, srv)
+
+	text := resultText(t, r)
+	for _, want := range []string{`"frames":[{"data":"hello","delayMs":100}]`, `"dropPattern":"*heartbeat*"`, `"resourceType":"websocket"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %s in body echo, got %s", want, text)
+		}
+	}
+}
+
 func TestHandleNetworkRoute_Fulfill_PassesAllFields(t *testing.T) {
 	srv := mockPinchTab()
 	defer srv.Close()
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func permissionsPath(tabID string) string {
+	if tabID == "" {
+		return "/permissions"
+	}
+	return "/tabs/" + url.PathEscape(tabID) + "/permissions"
+}
+
+func handlePermissions(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if origin := optString(r, "origin"); origin != "" {
+			q.Set("origin", origin)
+		}
+		body, code, err := c.Get(ctx, permissionsPath(optString(r, "tabId")), q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleSetPermission(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := r.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		setting, err := r.RequireString("setting")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if setting != "granted" && setting != "denied" && setting != "prompt" {
+			return mcp.NewToolResultError("setting must be 'granted', 'denied' or 'prompt'"), nil
+		}
+		payload := map[string]any{"permissions": map[string]string{name: setting}}
+		if origin := optString(r, "origin"); origin != "" {
+			payload["origin"] = origin
+		}
+		body, code, err := c.Post(ctx, permissionsPath(optString(r, "tabId")), payload)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
+
+func handleResetPermissions(c *Client) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, r mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := url.Values{}
+		if name := optString(r, "name"); name != "" {
+			q.Set("name", name)
+		}
+		if origin := optString(r, "origin"); origin != "" {
+			q.Set("origin", origin)
+		}
+		body, code, err := c.Delete(ctx, permissionsPath(optString(r, "tabId")), q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return resultFromBytes(body, code)
+	}
+}
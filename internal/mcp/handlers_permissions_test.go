@@ -0,0 +1,46 @@
+package mcp
+
+import "testing"
+
+func TestHandleSetPermission(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_set_permission", map[string]any{
+		"name": "geolocation", "setting": "granted", "origin": "https://maps.example.com", "tabId": "t1",
+	}, srv)
+	m := resultJSON(t, r)
+	if m["path"] != "/tabs/t1/permissions" || m["method"] != "POST" {
+		t.Fatalf("unexpected request: %v", m)
+	}
+	body, _ := m["body"].(map[string]any)
+	perms, _ := body["permissions"].(map[string]any)
+	if perms["geolocation"] != "granted" || body["origin"] != "https://maps.example.com" {
+		t.Errorf("unexpected body: %v", body)
+	}
+}
+
+func TestHandleSetPermissionInvalidSetting(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_set_permission", map[string]any{"name": "camera", "setting": "maybe"}, srv)
+	if !r.IsError {
+		t.Error("expected error for invalid setting")
+	}
+}
+
+func TestHandleResetPermissions(t *testing.T) {
+	srv := mockPinchTab()
+	defer srv.Close()
+
+	r := callTool(t, "pinchtab_reset_permissions", map[string]any{"name": "camera,microphone"}, srv)
+	m := resultJSON(t, r)
+	if m["path"] != "/permissions" || m["method"] != "DELETE" {
+		t.Fatalf("unexpected request: %v", m)
+	}
+	q, _ := m["query"].(map[string]any)
+	if names, _ := q["name"].([]any); len(names) != 1 || names[0] != "camera,microphone" {
+		t.Errorf("unexpected query: %v", q)
+	}
+}
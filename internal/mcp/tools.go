@@ -2,6 +2,12 @@ package mcp
 
 import "github.com/mark3labs/mcp-go/mcp"
 
+// Stale-ref guard parameters shared by every tool that acts on a ref.
+var (
+	expectedEpochParam = mcp.WithString("expectedEpoch", mcp.Description("epoch.domEpoch from pinchtab_capture that the ref came from. The action is refused with stale_epoch if the page has changed since."))
+	remapOnStaleParam  = mcp.WithBoolean("remapOnStale", mcp.Description("With expectedEpoch: re-resolve a stale ref semantically instead of refusing; the response reports the remap"))
+)
+
 // allTools returns every MCP tool exposed by the PinchTab MCP server.
 func allTools() []mcp.Tool {
 	return []mcp.Tool{
@@ -86,6 +92,8 @@ func allTools() []mcp.Tool {
 			mcp.WithBoolean("waitNav", mcp.Description("Wait for navigation after the click when the element triggers a page change")),
 			mcp.WithString("mode", mcp.Description("Optional click delivery override: 'dom' for element.click() or 'dispatch' for synthetic click events on the target")),
 			mcp.WithBoolean("snap", mcp.Description("Return interactive compact snapshot after click (saves a round-trip)")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -96,6 +104,8 @@ func allTools() []mcp.Tool {
 			mcp.WithString("ref", mcp.Description("(deprecated) Element ref from snapshot — use 'selector' instead")),
 			mcp.WithString("query", mcp.Description("Alias for semantic targeting when selector is omitted")),
 			mcp.WithString("text", mcp.Required(), mcp.Description("Text to type")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -115,6 +125,8 @@ func allTools() []mcp.Tool {
 			mcp.WithNumber("x", mcp.Description("Optional X coordinate for coordinate hover")),
 			mcp.WithNumber("y", mcp.Description("Optional Y coordinate for coordinate hover")),
 			mcp.WithNumber("nodeId", mcp.Description("Optional backend node ID to target directly")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -125,6 +137,8 @@ func allTools() []mcp.Tool {
 			mcp.WithString("ref", mcp.Description("(deprecated) Element ref from snapshot — use 'selector' instead")),
 			mcp.WithString("query", mcp.Description("Alias for semantic targeting when selector is omitted")),
 			mcp.WithNumber("nodeId", mcp.Description("Optional backend node ID to target directly")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -135,6 +149,8 @@ func allTools() []mcp.Tool {
 			mcp.WithString("ref", mcp.Description("(deprecated) Element ref from snapshot — use 'selector' instead")),
 			mcp.WithString("query", mcp.Description("Alias for semantic targeting when selector is omitted")),
 			mcp.WithString("value", mcp.Required(), mcp.Description("Option value or visible text to select")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithBoolean("snap", mcp.Description("Return interactive compact snapshot after select (saves a round-trip)")),
 			mcp.WithString("browser",
@@ -152,6 +168,8 @@ func allTools() []mcp.Tool {
 			mcp.WithNumber("steps", mcp.Description("Multiplier for direction-based scrolling (default 1)")),
 			mcp.WithNumber("x", mcp.Description("Optional X coordinate for wheel target")),
 			mcp.WithNumber("y", mcp.Description("Optional Y coordinate for wheel target")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -161,6 +179,8 @@ func allTools() []mcp.Tool {
 			mcp.WithString("selector", mcp.Description("Unified selector: ref (e.g. 'e5'), CSS, XPath, text, or semantic. Non-ref selectors resolve in the current frame scope.")),
 			mcp.WithString("ref", mcp.Description("(deprecated) Element ref — use 'selector' instead")),
 			mcp.WithString("query", mcp.Description("Alias for semantic targeting when selector is omitted")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithString("browser",
 				mcp.Description("Browser to use for this request (e.g. chrome, cloak, ghost-chrome).")),
@@ -171,6 +191,8 @@ func allTools() []mcp.Tool {
 			mcp.WithString("ref", mcp.Description("(deprecated) Element ref — use 'selector' instead")),
 			mcp.WithString("query", mcp.Description("Alias for semantic targeting when selector is omitted")),
 			mcp.WithString("value", mcp.Required(), mcp.Description("Value to fill")),
+			expectedEpochParam,
+			remapOnStaleParam,
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 			mcp.WithBoolean("snap", mcp.Description("Return interactive compact snapshot after fill (saves a round-trip)")),
 			mcp.WithString("browser",
@@ -306,7 +328,7 @@ func allTools() []mcp.Tool {
 			mcp.WithString("tabId", mcp.Description("Target tab ID (optional, clears all if empty)")),
 		),
 		mcp.NewTool("pinchtab_network_route",
-			mcp.WithDescription("Install a request interception rule on a tab. Action 'abort' blocks matching requests, 'fulfill' returns a mocked response body, 'continue' passes through. Fulfill is BLOCKED on hosts in security.allowedDomains (the operator's authorized/sensitive surfaces) and ALLOWED on other hosts; matched fulfill rules on allowlisted hosts fall through to a real network fetch."),
+			mcp.WithDescription("Install a request interception rule on a tab. Action 'abort' blocks matching requests, 'fulfill' returns a mocked response body, 'continue' passes through. Fulfill is BLOCKED on hosts in security.allowedDomains (the operator's authorized/sensitive surfaces) and ALLOWED on other hosts; matched fulfill rules on allowlisted hosts fall through to a real network fetch. With resourceType 'websocket' or 'eventsource' the rule targets live connections: 'abort' fails the connection, 'fulfill' opens a fake connection that delivers frames, and 'continue' keeps the real connection while injecting frames and discarding ones matching dropPattern."),
 			mcp.WithString("tabId", mcp.Required(), mcp.Description("Target tab ID")),
 			mcp.WithString("pattern", mcp.Required(), mcp.Description("URL pattern: substring (no wildcards) or glob ('*', '?')")),
 			mcp.WithString("action", mcp.Description("'continue' (default), 'abort', or 'fulfill'")),
@@ -315,6 +337,20 @@ func allTools() []mcp.Tool {
 			mcp.WithNumber("status", mcp.Description("Response status code for fulfill (default 200)")),
 			mcp.WithString("resourceType", mcp.Description("Limit to a CDP resource category (e.g. script, image, xhr, fetch, stylesheet)")),
 			mcp.WithString("method", mcp.Description("Limit to an HTTP method (GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS). Fulfill rules without method skip OPTIONS preflights to avoid breaking or bypassing CORS.")),
+			mcp.WithArray("frames",
+				mcp.Description("Scripted server frames for websocket/eventsource rules, delivered in order"),
+				mcp.Items(map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"data":    map[string]any{"type": "string", "description": "Frame payload"},
+						"event":   map[string]any{"type": "string", "description": "SSE event name (eventsource only)"},
+						"id":      map[string]any{"type": "string", "description": "SSE event id (eventsource only)"},
+						"delayMs": map[string]any{"type": "number", "description": "Delay after the previous frame"},
+					},
+					"required": []string{"data"},
+				}),
+			),
+			mcp.WithString("dropPattern", mcp.Description("Discard websocket/eventsource frames matching this substring or glob (action=continue)")),
 		),
 		mcp.NewTool("pinchtab_network_unroute",
 			mcp.WithDescription("Remove a tab's interception rule by pattern, or all rules if pattern is omitted"),
@@ -345,6 +381,25 @@ func allTools() []mcp.Tool {
 			mcp.WithString("tabId", mcp.Description("Target tab ID")),
 		),
 
+		mcp.NewTool("pinchtab_permissions",
+			mcp.WithDescription("Show browser permission state for a tab's origin: overrides set via pinchtab_set_permission, instance defaults, the page's live permission states, and permission prompts the page has raised (pending ones block on a user decision)."),
+			mcp.WithString("origin", mcp.Description("Origin to report on (default: the tab's current origin)")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+		mcp.NewTool("pinchtab_set_permission",
+			mcp.WithDescription("Grant or deny a browser permission (geolocation, notifications, camera, microphone, clipboard-read, clipboard-write, midi, midi-sysex, ...) for an origin so the page never shows a prompt."),
+			mcp.WithString("name", mcp.Required(), mcp.Description("Permission name, e.g. 'geolocation', 'notifications', 'camera', 'clipboard-read', 'midi'")),
+			mcp.WithString("setting", mcp.Required(), mcp.Description("'granted', 'denied' or 'prompt'")),
+			mcp.WithString("origin", mcp.Description("Origin to apply to (default: the tab's current origin)")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+		mcp.NewTool("pinchtab_reset_permissions",
+			mcp.WithDescription("Drop permission overrides for an origin, restoring the instance defaults."),
+			mcp.WithString("name", mcp.Description("Comma-separated permissions to reset (default: all)")),
+			mcp.WithString("origin", mcp.Description("Origin to reset (default: the tab's current origin)")),
+			mcp.WithString("tabId", mcp.Description("Target tab ID")),
+		),
+
 		mcp.NewTool("pinchtab_scrape",
 			mcp.WithDescription("Scrape a whole site into a page tree of markdown. Pages are discovered and extracted over plain HTTP first (SeaPortal: sitemap/link crawl, URL-pattern sampling); only pages whose HTTP extraction is thin, blocked, or failed are re-rendered in the real browser, so JS-only content still lands. Each page records its content source (http|browser) and routing verdict. For a LARGE site, first call with preview=true for a cheap outline (titles, sizes, snippets, routing verdicts — no bodies, no browser), then expand the pages you want with only=<comma-separated urls>. Full reports can be large, so prefer preview then only over scraping everything."),
 			mcp.WithString("url", mcp.Required(), mcp.Description("Site URL to crawl (discovery seeds from this host's root)")),
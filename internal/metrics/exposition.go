@@ -0,0 +1,286 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format is a text exposition format.
+type Format int
+
+const (
+	// FormatOpenMetrics is OpenMetrics 1.0.0 text.
+	FormatOpenMetrics Format = iota
+	// FormatText is the Prometheus 0.0.4 text format.
+	FormatText
+)
+
+const (
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	textContentType        = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// ContentType returns the Content-Type header for f.
+func (f Format) ContentType() string {
+	if f == FormatText {
+		return textContentType
+	}
+	return openMetricsContentType
+}
+
+// Negotiate picks OpenMetrics when the Accept header asks for it and falls
+// back to the Prometheus text format otherwise, as scrapers expect.
+func Negotiate(accept string) Format {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), "application/openmetrics-text") {
+			return FormatOpenMetrics
+		}
+	}
+	return FormatText
+}
+
+// ServeHTTP writes families in the format negotiated from r.
+func ServeHTTP(w http.ResponseWriter, r *http.Request, families []Family) {
+	format := Negotiate(r.Header.Get("Accept"))
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_ = Write(w, families, format)
+}
+
+// Write renders families in the given format.
+func Write(w io.Writer, families []Family, format Format) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		name := f.Name
+		typ := string(f.Type)
+		if format == FormatText && f.Type == Counter {
+			// The 0.0.4 format names counters by their sample name.
+			name += "_total"
+		}
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(f.Help, format))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, typ)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name)
+			bw.WriteString(s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	if format == FormatOpenMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeLabels(bw *bufio.Writer, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	bw.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(l.Name)
+		bw.WriteString(`="`)
+		bw.WriteString(labelEscaper.Replace(l.Value))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var (
+	labelEscaper       = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper        = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	openMetricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+func escapeHelp(help string, format Format) string {
+	if format == FormatOpenMetrics {
+		return openMetricsEscaper.Replace(help)
+	}
+	return helpEscaper.Replace(help)
+}
+
+// sampleSuffixes lists the suffixes a sample name may add to its family.
+var sampleSuffixes = map[Type][]string{
+	Counter:   {"_total", "_created"},
+	Gauge:     {""},
+	Histogram: {"_bucket", "_sum", "_count", "_created"},
+}
+
+// Parse reads text in either exposition format. It understands what Write
+// produces, which is all it is used for: re-exporting the metrics of child
+// instances. Timestamps and exemplars are dropped.
+func Parse(r io.Reader) ([]Family, error) {
+	var (
+		out     []Family
+		current *Family
+		help    = map[string]string{}
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			fields := strings.SplitN(text, " ", 4)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "HELP":
+				if len(fields) == 4 {
+					help[fields[2]] = unescapeHelp(fields[3])
+				}
+			case "TYPE":
+				if len(fields) != 4 {
+					return nil, fmt.Errorf("line %d: malformed TYPE", line)
+				}
+				typ := Type(fields[3])
+				if _, ok := sampleSuffixes[typ]; !ok {
+					typ = Gauge
+				}
+				name := fields[2]
+				h := help[name]
+				if typ == Counter {
+					name = strings.TrimSuffix(name, "_total")
+				}
+				out = append(out, Family{Name: name, Help: h, Type: typ})
+				current = &out[len(out)-1]
+			}
+			continue
+		}
+
+		sample, name, err := parseSample(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if current == nil || !sampleBelongs(current, name, &sample) {
+			out = append(out, Family{Name: name, Type: Gauge})
+			current = &out[len(out)-1]
+		}
+		current.Samples = append(current.Samples, sample)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func sampleBelongs(f *Family, name string, s *Sample) bool {
+	for _, suffix := range sampleSuffixes[f.Type] {
+		if name == f.Name+suffix {
+			s.Suffix = suffix
+			return true
+		}
+	}
+	return false
+}
+
+func parseSample(text string) (Sample, string, error) {
+	var s Sample
+	end := strings.IndexAny(text, "{ ")
+	if end <= 0 {
+		return s, "", fmt.Errorf("malformed sample %q", text)
+	}
+	name := text[:end]
+	rest := text[end:]
+	if rest[0] == '{' {
+		labels, n, err := parseLabels(rest)
+		if err != nil {
+			return s, "", err
+		}
+		s.Labels = labels
+		rest = rest[n:]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return s, "", fmt.Errorf("sample %s has no value", name)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, "", fmt.Errorf("sample %s: %w", name, err)
+	}
+	s.Value = v
+	return s, name, nil
+}
+
+// parseLabels parses a {name="value",...} block and returns the labels and
+// the number of bytes consumed.
+func parseLabels(text string) ([]Label, int, error) {
+	var labels []Label
+	i := 1
+	for {
+		for i < len(text) && (text[i] == ' ' || text[i] == ',') {
+			i++
+		}
+		if i < len(text) && text[i] == '}' {
+			return labels, i + 1, nil
+		}
+		eq := strings.IndexByte(text[i:], '=')
+		if eq <= 0 || i+eq+1 >= len(text) || text[i+eq+1] != '"' {
+			return nil, 0, fmt.Errorf("malformed labels in %q", text)
+		}
+		name := strings.TrimSpace(text[i : i+eq])
+		i += eq + 2
+		var b strings.Builder
+		for {
+			if i >= len(text) {
+				return nil, 0, fmt.Errorf("unterminated label value in %q", text)
+			}
+			c := text[i]
+			if c == '"' {
+				i++
+				break
+			}
+			if c == '\\' && i+1 < len(text) {
+				i++
+				switch text[i] {
+				case 'n':
+					b.WriteByte('\n')
+				default:
+					b.WriteByte(text[i])
+				}
+				i++
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		}
+		labels = append(labels, Label{Name: name, Value: b.String()})
+	}
+}
+
+func unescapeHelp(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
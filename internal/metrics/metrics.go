@@ -0,0 +1,335 @@
+// Package metrics is a small, dependency-free metrics registry that renders
+// the OpenMetrics and Prometheus text exposition formats. It covers what
+// PinchTab needs: labelled counters and histograms updated on the hot path,
+// plus collectors that build gauges at scrape time.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is a metric family type.
+type Type string
+
+const (
+	Counter   Type = "counter"
+	Gauge     Type = "gauge"
+	Histogram Type = "histogram"
+)
+
+// Label is one name/value pair on a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one line of a family. Suffix is appended to the family name,
+// e.g. "_total" for counters or "_bucket" for histograms.
+type Sample struct {
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named group of samples sharing a type and help text. Counter
+// family names do not carry the _total suffix; their samples do.
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Samples []Sample
+}
+
+// Collector builds families at scrape time.
+type Collector func() []Family
+
+// DefaultLatencyBuckets are the request latency histogram bounds in seconds.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds the metrics of one process.
+type Registry struct {
+	mu         sync.Mutex
+	names      map[string]bool
+	counters   []*CounterVec
+	histograms []*HistogramVec
+	collectors []Collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Default is the process-wide registry.
+var Default = NewRegistry()
+
+func (r *Registry) claim(name string) {
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+}
+
+// NewCounterVec registers a counter with the given label names.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]*counterEntry)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.claim(name)
+	r.counters = append(r.counters, c)
+	return c
+}
+
+// NewHistogramVec registers a histogram with the given upper bounds and
+// label names. Buckets must be sorted; +Inf is implied.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{name: name, help: help, buckets: buckets, labels: labels, values: make(map[string]*histogramEntry)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.claim(name)
+	r.histograms = append(r.histograms, h)
+	return h
+}
+
+// Register adds a collector that is called on every Gather.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// Gather snapshots every registered metric, sorted by family name.
+func (r *Registry) Gather() []Family {
+	r.mu.Lock()
+	counters := slices.Clone(r.counters)
+	histograms := slices.Clone(r.histograms)
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	var out []Family
+	for _, c := range counters {
+		out = append(out, c.family())
+	}
+	for _, h := range histograms {
+		out = append(out, h.family())
+	}
+	for _, c := range collectors {
+		out = append(out, c()...)
+	}
+	return Merge(out)
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*counterEntry
+}
+
+type counterEntry struct {
+	labels []Label
+	value  float64
+}
+
+// Inc adds one to the counter for the given label values.
+func (c *CounterVec) Inc(values ...string) { c.Add(1, values...) }
+
+// Add adds v, which must not be negative, to the counter for the given
+// label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	key := labelKey(c.name, c.labels, values)
+	c.mu.Lock()
+	e := c.values[key]
+	if e == nil {
+		e = &counterEntry{labels: pair(c.labels, values)}
+		c.values[key] = e
+	}
+	e.value += v
+	c.mu.Unlock()
+}
+
+// Reset drops every series. Used by tests.
+func (c *CounterVec) Reset() {
+	c.mu.Lock()
+	c.values = make(map[string]*counterEntry)
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) family() Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f := Family{Name: c.name, Help: c.help, Type: Counter}
+	for _, key := range sortedKeys(c.values) {
+		e := c.values[key]
+		f.Samples = append(f.Samples, Sample{Suffix: "_total", Labels: e.labels, Value: e.value})
+	}
+	return f
+}
+
+// HistogramVec records observations into cumulative buckets per label set.
+type HistogramVec struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  []string
+
+	mu     sync.Mutex
+	values map[string]*histogramEntry
+}
+
+type histogramEntry struct {
+	labels []Label
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Observe records v for the given label values.
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	key := labelKey(h.name, h.labels, values)
+	h.mu.Lock()
+	e := h.values[key]
+	if e == nil {
+		e = &histogramEntry{labels: pair(h.labels, values), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = e
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		e.counts[i]++
+	}
+	e.count++
+	e.sum += v
+	h.mu.Unlock()
+}
+
+// Reset drops every series. Used by tests.
+func (h *HistogramVec) Reset() {
+	h.mu.Lock()
+	h.values = make(map[string]*histogramEntry)
+	h.mu.Unlock()
+}
+
+func (h *HistogramVec) family() Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f := Family{Name: h.name, Help: h.help, Type: Histogram}
+	for _, key := range sortedKeys(h.values) {
+		e := h.values[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += e.counts[i]
+			f.Samples = append(f.Samples, Sample{
+				Suffix: "_bucket",
+				Labels: withLabel(e.labels, "le", formatFloat(bound)),
+				Value:  float64(cumulative),
+			})
+		}
+		f.Samples = append(f.Samples,
+			Sample{Suffix: "_bucket", Labels: withLabel(e.labels, "le", "+Inf"), Value: float64(e.count)},
+			Sample{Suffix: "_sum", Labels: e.labels, Value: e.sum},
+			Sample{Suffix: "_count", Labels: e.labels, Value: float64(e.count)},
+		)
+	}
+	return f
+}
+
+// Merge combines families that share a name, keeping the first help text
+// and type, and returns them sorted by name. Samples keep their order so
+// histogram series stay contiguous.
+func Merge(families []Family) []Family {
+	index := make(map[string]int, len(families))
+	var out []Family
+	for _, f := range families {
+		if i, ok := index[f.Name]; ok {
+			out[i].Samples = append(out[i].Samples, f.Samples...)
+			continue
+		}
+		index[f.Name] = len(out)
+		f.Samples = slices.Clone(f.Samples)
+		out = append(out, f)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// WithLabels returns a copy of families with labels prepended to every
+// sample. Existing labels of the same name are replaced.
+func WithLabels(families []Family, labels ...Label) []Family {
+	out := make([]Family, len(families))
+	for i, f := range families {
+		out[i] = f
+		out[i].Samples = make([]Sample, len(f.Samples))
+		for j, s := range f.Samples {
+			merged := slices.Clone(labels)
+			for _, l := range s.Labels {
+				if !hasLabel(labels, l.Name) {
+					merged = append(merged, l)
+				}
+			}
+			s.Labels = merged
+			out[i].Samples[j] = s
+		}
+	}
+	return out
+}
+
+func hasLabel(labels []Label, name string) bool {
+	for _, l := range labels {
+		if l.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func withLabel(labels []Label, name, value string) []Label {
+	out := make([]Label, 0, len(labels)+1)
+	out = append(out, labels...)
+	return append(out, Label{Name: name, Value: value})
+}
+
+func pair(names, values []string) []Label {
+	labels := make([]Label, len(names))
+	for i, n := range names {
+		labels[i] = Label{Name: n, Value: values[i]}
+	}
+	return labels
+}
+
+func labelKey(metric string, names, values []string) string {
+	if len(values) != len(names) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", metric, len(names), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("app_requests", "Requests served.", "route", "code")
+	latency := r.NewHistogramVec("app_latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	r.Register(func() []Family {
+		return []Family{{Name: "app_queue_depth", Help: "Queued tasks.", Type: Gauge, Samples: []Sample{
+			{Labels: []Label{{Name: "agent_id", Value: `a"1`}}, Value: 3},
+		}}}
+	})
+
+	requests.Inc("/tabs", "200")
+	requests.Add(2, "/tabs", "200")
+	latency.Observe(0.05, "/tabs")
+	latency.Observe(0.5, "/tabs")
+	latency.Observe(5, "/tabs")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r.Gather(), FormatOpenMetrics); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP app_latency_seconds Request latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{route="/tabs",le="0.1"} 1
+app_latency_seconds_bucket{route="/tabs",le="1"} 2
+app_latency_seconds_bucket{route="/tabs",le="+Inf"} 3
+app_latency_seconds_sum{route="/tabs"} 5.55
+app_latency_seconds_count{route="/tabs"} 3
+# HELP app_queue_depth Queued tasks.
+# TYPE app_queue_depth gauge
+app_queue_depth{agent_id="a\"1"} 3
+# HELP app_requests Requests served.
+# TYPE app_requests counter
+app_requests_total{route="/tabs",code="200"} 3
+# EOF
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("exposition mismatch\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteTextFormatNamesCountersWithTotal(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("app_hits", "Hits.").Inc()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r.Gather(), FormatText); err != nil {
+		t.Fatal(err)
+	}
+	want := "# HELP app_hits_total Hits.\n# TYPE app_hits_total counter\napp_hits_total 1\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]Format{
+		"":                         FormatText,
+		"text/plain;version=0.0.4": FormatText,
+		"application/openmetrics-text;version=1.0.0,text/plain;q=0.5": FormatOpenMetrics,
+		"*/*": FormatText,
+	}
+	for accept, want := range cases {
+		if got := Negotiate(accept); got != want {
+			t.Errorf("Negotiate(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("app_requests", "Requests\nserved.", "route").Inc(`/a\b`)
+	r.NewHistogramVec("app_latency_seconds", "Latency.", []float64{1}, "route").Observe(0.5, "/a")
+	r.Register(func() []Family {
+		return []Family{{Name: "app_up", Type: Gauge, Samples: []Sample{{Value: 1}}}}
+	})
+	families := r.Gather()
+
+	for _, format := range []Format{FormatOpenMetrics, FormatText} {
+		var buf bytes.Buffer
+		if err := Write(&buf, families, format); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("format %v: %v", format, err)
+		}
+		var again bytes.Buffer
+		if err := Write(&again, parsed, FormatOpenMetrics); err != nil {
+			t.Fatal(err)
+		}
+		var orig bytes.Buffer
+		_ = Write(&orig, families, FormatOpenMetrics)
+		if again.String() != orig.String() {
+			t.Fatalf("format %v round trip mismatch\n got:\n%s\nwant:\n%s", format, again.String(), orig.String())
+		}
+	}
+}
+
+func TestWithLabelsAndMerge(t *testing.T) {
+	child := []Family{{Name: "app_tabs", Type: Gauge, Samples: []Sample{
+		{Labels: []Label{{Name: "instance_id", Value: "spoofed"}}, Value: 2},
+	}}}
+	own := []Family{{Name: "app_tabs", Help: "Open tabs.", Type: Gauge, Samples: []Sample{{Value: 1}}}}
+
+	merged := Merge(append(own, WithLabels(child, Label{Name: "instance_id", Value: "inst_1"})...))
+	if len(merged) != 1 {
+		t.Fatalf("expected one family, got %d", len(merged))
+	}
+	var buf bytes.Buffer
+	_ = Write(&buf, merged, FormatOpenMetrics)
+	if !strings.Contains(buf.String(), `app_tabs{instance_id="inst_1"} 2`) {
+		t.Fatalf("child sample not relabelled:\n%s", buf.String())
+	}
+	if strings.Contains(buf.String(), "spoofed") {
+		t.Fatalf("child label should be replaced:\n%s", buf.String())
+	}
+}
+
+func TestServeHTTPContentType(t *testing.T) {
+	req := httptest.NewRequest("GET", "/metrics/prometheus", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	ServeHTTP(w, req, nil)
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("content type = %q", ct)
+	}
+	if w.Body.String() != "# EOF\n" {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := NewRegistry().NewCounterVec("app_x", "", "a")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	c.Inc()
+}
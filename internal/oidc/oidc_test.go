@@ -0,0 +1,131 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/oidc/oidctest"
+)
+
+const redirectURL = "http://127.0.0.1:9867/api/auth/oidc/callback"
+
+func login(t *testing.T, idp *oidctest.Provider, p *Provider) (*Claims, error) {
+	t.Helper()
+	verifier, _ := NewVerifier()
+	authURL := p.AuthCodeURL(redirectURL, "state-1", "nonce-1", verifier, nil)
+	back, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Query().Get("state") != "state-1" {
+		t.Fatalf("state = %q", back.Query().Get("state"))
+	}
+	return p.Exchange(context.Background(), back.Query().Get("code"), redirectURL, verifier, "nonce-1")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewProvider("pinchtab")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "u-42", Email: "ada@example.com", Groups: []string{"ops", "eng"}})
+
+	p, err := Discover(context.Background(), Config{Issuer: idp.Issuer, ClientID: "pinchtab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := login(t, idp, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "u-42" || claims.Email != "ada@example.com" || claims.AuthTime.IsZero() {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if groups := claims.StringList("groups"); len(groups) != 2 || groups[0] != "ops" {
+		t.Fatalf("groups = %v", groups)
+	}
+}
+
+func TestExchangeChecksPKCEAndSecret(t *testing.T) {
+	idp := oidctest.NewProvider("pinchtab")
+	defer idp.Close()
+	idp.ClientSecret = "s3cret"
+
+	p, err := Discover(context.Background(), Config{Issuer: idp.Issuer, ClientID: "pinchtab", ClientSecret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, _ := NewVerifier()
+	back, err := idp.Authorize(p.AuthCodeURL(redirectURL, "s", "n", verifier, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(context.Background(), back.Query().Get("code"), redirectURL, verifier+"x", "n"); err == nil {
+		t.Fatal("a wrong code verifier was accepted")
+	}
+
+	wrongSecret, _ := Discover(context.Background(), Config{Issuer: idp.Issuer, ClientID: "pinchtab", ClientSecret: "nope"})
+	if _, err := login(t, idp, wrongSecret); err == nil {
+		t.Fatal("a wrong client secret was accepted")
+	}
+	if _, err := login(t, idp, p); err != nil {
+		t.Fatalf("confidential client login: %v", err)
+	}
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	idp := oidctest.NewProvider("pinchtab")
+	defer idp.Close()
+	p, err := Discover(context.Background(), Config{Issuer: idp.Issuer, ClientID: "pinchtab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, claims := range map[string]map[string]any{
+		"expired":        {"exp": time.Now().Add(-time.Hour).Unix()},
+		"other audience": {"aud": "someone-else"},
+		"other issuer":   {"iss": "https://evil.example.com"},
+		"multi aud":      {"aud": []string{"pinchtab", "other"}},
+		"wrong nonce":    {"nonce": "replayed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			idp.SetClaims(claims)
+			if _, err := login(t, idp, p); err == nil {
+				t.Fatal("expected verification to fail")
+			}
+		})
+	}
+
+	idp.SetClaims(nil)
+	if _, err := login(t, idp, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Verify(context.Background(), "a.b.c", ""); err == nil {
+		t.Fatal("a garbage token was accepted")
+	}
+}
+
+func TestVerifyRejectsTamperedSignature(t *testing.T) {
+	idp := oidctest.NewProvider("pinchtab")
+	defer idp.Close()
+	p, err := Discover(context.Background(), Config{Issuer: idp.Issuer, ClientID: "pinchtab"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := login(t, idp, p); err != nil {
+		t.Fatal(err)
+	}
+	// An unsigned token must never verify, even with valid claims.
+	unsigned := "eyJhbGciOiJub25lIn0." + strings.Repeat("e", 10) + "."
+	if _, err := p.Verify(context.Background(), unsigned, ""); err == nil {
+		t.Fatal(`an "alg":"none" token was accepted`)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewProvider("pinchtab")
+	defer idp.Close()
+	if _, err := Discover(context.Background(), Config{Issuer: idp.Issuer + "/tenant", ClientID: "pinchtab"}); err == nil {
+		t.Fatal("discovery from another issuer was accepted")
+	}
+}
@@ -0,0 +1,318 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway allowed on token timestamps.
+const clockSkew = time.Minute
+
+// keysRefreshInterval limits how often an unknown key id triggers a JWKS
+// refetch, so forged tokens cannot be used to hammer the provider.
+const keysRefreshInterval = time.Minute
+
+// Claims are the verified claims of an ID token.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	Name              string
+	PreferredUsername string
+	Nonce             string
+	IssuedAt          time.Time
+	Expiry            time.Time
+	AuthTime          time.Time // zero when the provider omits auth_time
+
+	raw map[string]any
+}
+
+// StringList returns a claim holding a string or a list of strings, such as
+// a groups claim.
+func (c *Claims) StringList(name string) []string {
+	switch v := c.raw[name].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// Verify checks an ID token's signature, issuer, audience, lifetime and
+// nonce, and returns its claims.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("oidc: malformed id token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("oidc: id token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("oidc: id token signature: %w", err)
+	}
+	key, err := p.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, fmt.Errorf("oidc: id token claims: %w", err)
+	}
+	c := &Claims{
+		Issuer:            stringClaim(raw, "iss"),
+		Subject:           stringClaim(raw, "sub"),
+		Email:             stringClaim(raw, "email"),
+		Name:              stringClaim(raw, "name"),
+		PreferredUsername: stringClaim(raw, "preferred_username"),
+		Nonce:             stringClaim(raw, "nonce"),
+		IssuedAt:          timeClaim(raw, "iat"),
+		Expiry:            timeClaim(raw, "exp"),
+		AuthTime:          timeClaim(raw, "auth_time"),
+		raw:               raw,
+	}
+
+	now := p.now()
+	switch {
+	case c.Issuer != p.metadata.Issuer:
+		return nil, fmt.Errorf("oidc: id token issuer %q, want %q", c.Issuer, p.metadata.Issuer)
+	case c.Subject == "":
+		return nil, errors.New("oidc: id token has no subject")
+	case !audienceAllows(raw, p.cfg.ClientID):
+		return nil, errors.New("oidc: id token was not issued to this client")
+	case c.Expiry.IsZero() || now.After(c.Expiry.Add(clockSkew)):
+		return nil, errors.New("oidc: id token has expired")
+	case c.IssuedAt.After(now.Add(clockSkew)):
+		return nil, errors.New("oidc: id token was issued in the future")
+	case nonce != "" && c.Nonce != nonce:
+		return nil, errors.New("oidc: id token nonce does not match")
+	}
+	return c, nil
+}
+
+// audienceAllows applies the OIDC audience rules: aud must contain the
+// client, and a token for several audiences must name it as azp.
+func audienceAllows(raw map[string]any, clientID string) bool {
+	var aud []string
+	switch v := raw["aud"].(type) {
+	case string:
+		aud = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+	}
+	if !slices.Contains(aud, clientID) {
+		return false
+	}
+	if azp := stringClaim(raw, "azp"); azp != "" || len(aud) > 1 {
+		return azp == clientID
+	}
+	return true
+}
+
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKeyLocked(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetched.IsZero() && p.now().Sub(p.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+	}
+	keys, err := p.fetchKeys(ctx)
+	p.keysFetched = p.now()
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	if key, ok := p.lookupKeyLocked(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown signing key %q", kid)
+}
+
+func (p *Provider) lookupKeyLocked(kid string) (any, bool) {
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok
+	}
+	// A token without kid is only accepted when the set has a single key.
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, p.client, p.metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc: fetch signing keys: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("oidc: provider publishes no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("bad exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func verifySignature(alg string, key any, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		// "none" and HMAC algorithms are never accepted.
+		return fmt.Errorf("oidc: unsupported id token algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") || rsa.VerifyPKCS1v15(pub, hash, digest, sig) != nil {
+			return errors.New("oidc: invalid id token signature")
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(sig) != 2*size {
+			return errors.New("oidc: invalid id token signature")
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("oidc: invalid id token signature")
+		}
+	default:
+		return errors.New("oidc: invalid id token signature")
+	}
+	return nil
+}
+
+func decodeSegment(seg string, out any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("bad key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func stringClaim(raw map[string]any, name string) string {
+	s, _ := raw[name].(string)
+	return s
+}
+
+func timeClaim(raw map[string]any, name string) time.Time {
+	if f, ok := raw[name].(float64); ok && f > 0 {
+		return time.Unix(int64(f), 0)
+	}
+	return time.Time{}
+}
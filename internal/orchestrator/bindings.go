@@ -15,11 +15,18 @@ import (
 // hooks into via session.Store, so sessions can be evicted promptly. Agents
 // have no lifecycle signal, so agent bindings are bounded by an idle TTL and
 // an LRU cap to prevent unbounded growth.
+//
+// A draining instance takes no new bindings. Its existing ones keep
+// resolving until the drain moves them to the instance that took its tabs.
+// While the tabs themselves are moved the instance is also migrating, and
+// requests naming its tabs by id are held off.
 type Bindings struct {
 	mu        sync.RWMutex
 	session   map[string]string    // sessionID → instanceID
 	agent     map[string]string    // agentID   → instanceID
 	agentSeen map[string]time.Time // agentID   → last access
+	draining  map[string]bool      // instanceID → draining
+	migrating map[string]bool      // instanceID → tabs being moved
 	now       func() time.Time
 }
 
@@ -33,6 +40,8 @@ func NewBindings(now func() time.Time) *Bindings {
 		session:   make(map[string]string),
 		agent:     make(map[string]string),
 		agentSeen: make(map[string]time.Time),
+		draining:  make(map[string]bool),
+		migrating: make(map[string]bool),
 		now:       now,
 	}
 }
@@ -65,25 +74,30 @@ func (b *Bindings) ResolveAgent(id string) (string, bool) {
 }
 
 // BindSession associates a session id with an instance. Pass an empty
-// instance id to no-op; pass an empty session id to no-op.
+// instance id to no-op; pass an empty session id to no-op. Binding to a
+// draining instance is a no-op too.
 func (b *Bindings) BindSession(id, instanceID string) {
 	if b == nil || id == "" || instanceID == "" {
 		return
 	}
 	b.mu.Lock()
-	b.session[id] = instanceID
+	if !b.draining[instanceID] {
+		b.session[id] = instanceID
+	}
 	b.mu.Unlock()
 }
 
 // BindAgent associates an agent id with an instance and updates its idle
-// timestamp.
+// timestamp, unless the instance is draining.
 func (b *Bindings) BindAgent(id, instanceID string) {
 	if b == nil || id == "" || instanceID == "" {
 		return
 	}
 	b.mu.Lock()
-	b.agent[id] = instanceID
-	b.agentSeen[id] = b.now()
+	if !b.draining[instanceID] {
+		b.agent[id] = instanceID
+		b.agentSeen[id] = b.now()
+	}
 	b.mu.Unlock()
 }
 
@@ -116,6 +130,7 @@ func (b *Bindings) ClearInstance(instanceID string) {
 		return
 	}
 	b.mu.Lock()
+	delete(b.draining, instanceID)
 	for id, target := range b.session {
 		if target == instanceID {
 			delete(b.session, id)
@@ -130,6 +145,92 @@ func (b *Bindings) ClearInstance(instanceID string) {
 	b.mu.Unlock()
 }
 
+// Drain stops new bindings to the instance. It reports false when the
+// instance is already draining.
+func (b *Bindings) Drain(instanceID string) bool {
+	if b == nil || instanceID == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.draining[instanceID] {
+		return false
+	}
+	b.draining[instanceID] = true
+	return true
+}
+
+// Undrain lets the instance take new bindings again.
+func (b *Bindings) Undrain(instanceID string) {
+	if b == nil || instanceID == "" {
+		return
+	}
+	b.mu.Lock()
+	delete(b.draining, instanceID)
+	b.mu.Unlock()
+}
+
+// Draining reports whether the instance is draining.
+func (b *Bindings) Draining(instanceID string) bool {
+	if b == nil || instanceID == "" {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.draining[instanceID]
+}
+
+// SetMigrating marks whether a draining instance's tabs are being moved.
+func (b *Bindings) SetMigrating(instanceID string, migrating bool) {
+	if b == nil || instanceID == "" {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !migrating {
+		delete(b.migrating, instanceID)
+		return
+	}
+	if b.migrating == nil {
+		b.migrating = make(map[string]bool)
+	}
+	b.migrating[instanceID] = true
+}
+
+// Migrating reports whether the instance's tabs are being moved.
+func (b *Bindings) Migrating(instanceID string) bool {
+	if b == nil || instanceID == "" {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.migrating[instanceID]
+}
+
+// MoveInstance points every binding of from at to and returns how many
+// moved. A drain calls it once the tabs live on to.
+func (b *Bindings) MoveInstance(from, to string) int {
+	if b == nil || from == "" || to == "" || from == to {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for id, target := range b.session {
+		if target == from {
+			b.session[id] = to
+			n++
+		}
+	}
+	for id, target := range b.agent {
+		if target == from {
+			b.agent[id] = to
+			n++
+		}
+	}
+	return n
+}
+
 // PruneAgents drops agent bindings that have been idle longer than the
 // given duration, then enforces an LRU cap by evicting the oldest entries
 // until the count fits. A non-positive `idle` disables the TTL pass; a
@@ -170,6 +271,28 @@ func (b *Bindings) PruneAgents(idle time.Duration, max int) {
 	}
 }
 
+// BoundTo returns how many session and agent bindings point at the given
+// instance.
+func (b *Bindings) BoundTo(instanceID string) int {
+	if b == nil || instanceID == "" {
+		return 0
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, target := range b.session {
+		if target == instanceID {
+			n++
+		}
+	}
+	for _, target := range b.agent {
+		if target == instanceID {
+			n++
+		}
+	}
+	return n
+}
+
 // Counts returns the current number of session and agent bindings. Useful
 // for metrics and tests.
 func (b *Bindings) Counts() (sessions, agents int) {
@@ -103,6 +103,45 @@ func TestBindings_ClearInstanceDropsEveryReference(t *testing.T) {
 	}
 }
 
+func TestBindings_DrainBlocksNewBindingsAndMoveRebinds(t *testing.T) {
+	b := NewBindings(nil)
+	b.BindSession("ses_1", "inst_a")
+	b.BindAgent("agent-1", "inst_a")
+
+	if !b.Drain("inst_a") {
+		t.Fatal("first Drain should succeed")
+	}
+	if b.Drain("inst_a") {
+		t.Fatal("second Drain of the same instance should report false")
+	}
+	b.BindSession("ses_2", "inst_a")
+	b.BindAgent("agent-2", "inst_a")
+	if _, ok := b.ResolveSession("ses_2"); ok {
+		t.Fatal("draining instance should take no new session binding")
+	}
+	if _, ok := b.ResolveAgent("agent-2"); ok {
+		t.Fatal("draining instance should take no new agent binding")
+	}
+	if inst, _ := b.ResolveSession("ses_1"); inst != "inst_a" {
+		t.Fatalf("existing binding should keep resolving during drain, got %q", inst)
+	}
+
+	if n := b.MoveInstance("inst_a", "inst_b"); n != 2 {
+		t.Fatalf("MoveInstance moved %d, want 2", n)
+	}
+	if inst, _ := b.ResolveSession("ses_1"); inst != "inst_b" {
+		t.Fatalf("session bound to %q, want inst_b", inst)
+	}
+	if inst, _ := b.ResolveAgent("agent-1"); inst != "inst_b" {
+		t.Fatalf("agent bound to %q, want inst_b", inst)
+	}
+
+	b.ClearInstance("inst_a")
+	if b.Draining("inst_a") {
+		t.Fatal("ClearInstance should end the drain")
+	}
+}
+
 func TestBindings_NilSafe(t *testing.T) {
 	var b *Bindings
 	b.BindSession("a", "b")
@@ -43,6 +43,9 @@ func (o *Orchestrator) buildChildFileConfig(effectiveCfg *config.RuntimeConfig,
 	fc.Server.StateDir = instanceStateDir
 	activityEnabled := false
 	fc.Observability.Activity.Enabled = &activityEnabled
+	// Children append to the server's trace file so a whole trace, proxy hop
+	// and bridge spans alike, is read from one place.
+	fc.Observability.Tracing.File = effectiveCfg.TracingFile()
 	fc.SetBrowserDebugPort(cdpPort)
 	fc.Profiles.BaseDir = filepath.Dir(profilePath)
 	fc.Profiles.DefaultProfile = filepath.Base(profilePath)
@@ -2,7 +2,10 @@ package orchestrator
 
 import (
 	"net/http"
+	"slices"
 
+	"github.com/pinchtab/pinchtab/internal/api/types"
+	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 	"github.com/pinchtab/pinchtab/internal/routes"
 )
@@ -28,6 +31,8 @@ func (o *Orchestrator) Allows(cap routes.Capability) bool {
 		return o.AllowsStateExport()
 	case routes.CapNetworkIntercept:
 		return o.AllowsNetworkIntercept()
+	case routes.CapWebAuthn:
+		return o.AllowsWebAuthn()
 	default:
 		return false
 	}
@@ -53,13 +58,13 @@ func (o *Orchestrator) RegisterHandlers(mux *http.ServeMux) {
 
 func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 	if !skipLaunch {
-		mux.HandleFunc("POST /profiles/{id}/start", o.handleStartByID)
+		mux.HandleFunc("POST /profiles/{id}/start", o.tokenProfileScoped(o.handleStartByID))
 	}
-	mux.HandleFunc("POST /profiles/{id}/stop", o.handleStopByID)
-	mux.HandleFunc("GET /profiles/{id}/instance", o.handleProfileInstance)
+	mux.HandleFunc("POST /profiles/{id}/stop", o.tokenProfileScoped(o.handleStopByID))
+	mux.HandleFunc("GET /profiles/{id}/instance", o.tokenProfileScoped(o.handleProfileInstance))
 
 	mux.HandleFunc("GET /instances", o.handleList)
-	mux.HandleFunc("GET /instances/{id}", o.handleGetInstance)
+	mux.HandleFunc("GET /instances/{id}", o.tokenInstanceScoped(o.handleGetInstance))
 	mux.HandleFunc("GET /instances/tabs", o.handleAllTabs)
 	mux.HandleFunc("GET /instances/metrics", o.handleAllMetrics)
 	if !skipLaunch {
@@ -69,26 +74,26 @@ func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 	mux.HandleFunc("POST /instances/attach", o.handleAttachInstance)
 	mux.HandleFunc("POST /instances/attach-bridge", o.handleAttachBridge)
 	if !skipLaunch {
-		mux.HandleFunc("POST /instances/{id}/start", o.handleStartByInstanceID)
+		mux.HandleFunc("POST /instances/{id}/start", o.tokenInstanceScoped(o.handleStartByInstanceID))
 	}
-	mux.HandleFunc("POST /instances/{id}/restart", o.handleRestartByInstanceID)
-	mux.HandleFunc("POST /instances/{id}/stop", o.handleStopByInstanceID)
-	mux.HandleFunc("GET /instances/{id}/logs", o.handleLogsByID)
-	mux.HandleFunc("GET /instances/{id}/logs/stream", o.handleLogsStreamByID)
-	mux.HandleFunc("GET /instances/{id}/tabs", o.handleInstanceTabs)
-	mux.HandleFunc("POST /instances/{id}/tabs/open", o.handleInstanceTabOpen)
-	mux.HandleFunc("POST /instances/{id}/tab", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/restart", o.tokenInstanceScoped(o.handleRestartByInstanceID))
+	mux.HandleFunc("POST /instances/{id}/stop", o.tokenInstanceScoped(o.handleStopByInstanceID))
+	mux.HandleFunc("GET /instances/{id}/logs", o.tokenInstanceScoped(o.handleLogsByID))
+	mux.HandleFunc("GET /instances/{id}/logs/stream", o.tokenInstanceScoped(o.handleLogsStreamByID))
+	mux.HandleFunc("GET /instances/{id}/tabs", o.tokenInstanceScoped(o.handleInstanceTabs))
+	mux.HandleFunc("POST /instances/{id}/tabs/open", o.tokenInstanceScoped(o.handleInstanceTabOpen))
+	mux.HandleFunc("POST /instances/{id}/tab", o.tokenInstanceScoped(o.proxyToInstance))
 	// Disposable, cookie-authenticated CLI runs access their isolated child
 	// through these routes because a child's loopback URL is not reachable by
 	// remote clients.
-	mux.HandleFunc("POST /instances/{id}/close", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/close", o.tokenInstanceScoped(o.proxyToInstance))
 	cookiesMeta, _ := routes.Meta(routes.CapCookies)
-	registerCapabilityRoute(mux, "POST /instances/{id}/cookies", o.Allows(routes.CapCookies), cookiesMeta.Label, cookiesMeta.Setting, cookiesMeta.DisabledCode, o.proxyToInstance)
-	mux.HandleFunc("POST /instances/{id}/audit", o.proxyToInstance)
-	mux.HandleFunc("POST /instances/{id}/scrape", o.proxyToInstance)
+	registerCapabilityRoute(mux, "POST /instances/{id}/cookies", o.Allows(routes.CapCookies), cookiesMeta.Label, cookiesMeta.Setting, cookiesMeta.DisabledCode, o.tokenInstanceScoped(o.proxyToInstance))
+	mux.HandleFunc("POST /instances/{id}/audit", o.tokenInstanceScoped(o.proxyToInstance))
+	mux.HandleFunc("POST /instances/{id}/scrape", o.tokenInstanceScoped(o.proxyToInstance))
 	screencastMeta, _ := routes.Meta(routes.CapScreencast)
-	registerCapabilityRoute(mux, "GET /instances/{id}/proxy/screencast", o.Allows(routes.CapScreencast), screencastMeta.Label, screencastMeta.Setting, screencastMeta.DisabledCode, o.handleProxyScreencast)
-	registerCapabilityRoute(mux, "GET /instances/{id}/screencast", o.Allows(routes.CapScreencast), screencastMeta.Label, screencastMeta.Setting, screencastMeta.DisabledCode, o.proxyToInstance)
+	registerCapabilityRoute(mux, "GET /instances/{id}/proxy/screencast", o.Allows(routes.CapScreencast), screencastMeta.Label, screencastMeta.Setting, screencastMeta.DisabledCode, o.tokenInstanceScoped(o.handleProxyScreencast))
+	registerCapabilityRoute(mux, "GET /instances/{id}/screencast", o.Allows(routes.CapScreencast), screencastMeta.Label, screencastMeta.Setting, screencastMeta.DisabledCode, o.tokenInstanceScoped(o.proxyToInstance))
 
 	// Tab operations - generic proxy (all route to the appropriate instance).
 	// Sourced from the shared route catalogue to stay in sync with bridge and strategy.
@@ -107,19 +112,31 @@ func (o *Orchestrator) registerHandlers(mux *http.ServeMux, skipLaunch bool) {
 	}
 
 	// Cache operations - per-instance (browser-wide shorthands are in strategy routes)
-	mux.HandleFunc("POST /instances/{id}/cache/clear", o.proxyToInstance)
-	mux.HandleFunc("GET /instances/{id}/cache/status", o.proxyToInstance)
+	mux.HandleFunc("POST /instances/{id}/cache/clear", o.tokenInstanceScoped(o.proxyToInstance))
+	mux.HandleFunc("GET /instances/{id}/cache/status", o.tokenInstanceScoped(o.proxyToInstance))
 }
 
 func (o *Orchestrator) handleList(w http.ResponseWriter, r *http.Request) {
-	httpx.JSON(w, 200, o.List())
+	list := o.List()
+	if tok, ok := instanceRestrictedToken(r); ok {
+		list = slices.DeleteFunc(list, func(inst bridge.Instance) bool { return !tokenAllowsInstance(tok, inst) })
+	}
+	httpx.JSON(w, 200, list)
 }
 
 func (o *Orchestrator) handleAllTabs(w http.ResponseWriter, r *http.Request) {
 	fresh := r.URL.Query().Get("fresh") == "1"
-	httpx.JSON(w, 200, o.allTabs(fresh))
+	tabs := o.allTabs(fresh)
+	if _, ok := instanceRestrictedToken(r); ok {
+		tabs = slices.DeleteFunc(tabs, func(tab bridge.InstanceTab) bool { return !o.tokenVisibleInstance(r, tab.InstanceID) })
+	}
+	httpx.JSON(w, 200, tabs)
 }
 
 func (o *Orchestrator) handleAllMetrics(w http.ResponseWriter, r *http.Request) {
-	httpx.JSON(w, 200, o.AllMetrics())
+	metrics := o.AllMetrics()
+	if _, ok := instanceRestrictedToken(r); ok {
+		metrics = slices.DeleteFunc(metrics, func(m types.InstanceMetrics) bool { return !o.tokenVisibleInstance(r, m.InstanceID) })
+	}
+	httpx.JSON(w, 200, metrics)
 }
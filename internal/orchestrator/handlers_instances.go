@@ -1,12 +1,14 @@
 package orchestrator
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 )
 
 type startInstanceRequest struct {
@@ -24,6 +27,8 @@ type startInstanceRequest struct {
 	SecurityPolicy  *bridge.SecurityPolicy `json:"securityPolicy,omitempty"`
 	Browser         string                 `json:"browser,omitempty"`
 	FallbackTargets []string               `json:"fallbackTargets,omitempty"`
+	ProxyPool       string                 `json:"proxyPool,omitempty"`
+	ProxyKey        string                 `json:"proxyKey,omitempty"`
 }
 
 func (o *Orchestrator) handleGetInstance(w http.ResponseWriter, r *http.Request) {
@@ -68,6 +73,26 @@ func (o *Orchestrator) handleLaunchByName(w http.ResponseWriter, r *http.Request
 
 func (o *Orchestrator) handleStopByInstanceID(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	drain, timeout, ok := parseDrainRequest(w, r)
+	if !ok {
+		return
+	}
+	if drain {
+		res, ok := o.drainForRequest(w, r, id, timeout)
+		if !ok {
+			return
+		}
+		if err := o.Stop(id); err != nil {
+			// The instance is still up; let it take work again rather than
+			// leave it draining with nothing to end the drain.
+			o.bindings.Undrain(id)
+			httpx.Error(w, 500, fmt.Errorf("stop after drain: %w", err))
+			return
+		}
+		authn.AuditLog(r, "instance.stopped", "instanceId", id, "drain", true, "targetId", res.TargetID)
+		httpx.JSON(w, 200, map[string]any{"status": "stopped", "id": id, "drain": res})
+		return
+	}
 	if err := o.Stop(id); err != nil {
 		httpx.Error(w, 404, err)
 		return
@@ -91,12 +116,101 @@ func (o *Orchestrator) handleRestartByInstanceID(w http.ResponseWriter, r *http.
 		return
 	}
 
+	drain, timeout, ok := parseDrainRequest(w, r)
+	if !ok {
+		return
+	}
+
 	targetURL, err := o.instancePathURL(inst, "/browser/restart", "")
 	if err != nil {
 		httpx.Error(w, 502, err)
 		return
 	}
-	o.proxyToURL(w, r, targetURL)
+	if !drain {
+		o.proxyToURL(w, r, targetURL)
+		return
+	}
+
+	res, ok := o.drainForRequest(w, r, id, timeout)
+	if !ok {
+		return
+	}
+	// The tabs live on the target now; the restarted browser comes back
+	// empty and takes new work again.
+	defer o.bindings.Undrain(id)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), instanceRestartTimeout)
+	defer cancel()
+	resp, body, err := o.doInstanceRequest(ctx, http.MethodPost, http.Header{}, nil, targetURL, inst)
+	if err != nil {
+		httpx.Error(w, 502, fmt.Errorf("restart after drain: %w", err))
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		httpx.Error(w, 502, fmt.Errorf("restart after drain: status %d: %s", resp.StatusCode, compactBody(body)))
+		return
+	}
+	authn.AuditLog(r, "instance.restarted", "instanceId", id, "drain", true, "targetId", res.TargetID)
+	httpx.JSON(w, 200, map[string]any{"status": "browser_restarted", "id": id, "drain": res})
+}
+
+// instanceRestartTimeout bounds the browser restart that ends a drain.
+const instanceRestartTimeout = 60 * time.Second
+
+// parseDrainRequest reads ?mode=drain and ?timeout=<seconds> from a stop or
+// restart request. ok is false once an error response has been written.
+func parseDrainRequest(w http.ResponseWriter, r *http.Request) (drain bool, timeout time.Duration, ok bool) {
+	q := r.URL.Query()
+	switch mode := strings.TrimSpace(q.Get("mode")); mode {
+	case "":
+		return false, 0, true
+	case "drain":
+	default:
+		httpx.Error(w, 400, fmt.Errorf("invalid mode %q (use drain)", mode))
+		return false, 0, false
+	}
+	timeout = defaultDrainTimeout
+	if v := strings.TrimSpace(q.Get("timeout")); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n <= 0 || n > maxDrainTimeout.Seconds() {
+			httpx.Error(w, 400, fmt.Errorf("invalid timeout %q (seconds, up to %d)", v, int(maxDrainTimeout.Seconds())))
+			return false, 0, false
+		}
+		timeout = time.Duration(n * float64(time.Second))
+	}
+	return true, timeout, true
+}
+
+// drainForRequest drains a running instance for a stop or restart request,
+// writing the error response when it cannot.
+func (o *Orchestrator) drainForRequest(w http.ResponseWriter, r *http.Request, id string, timeout time.Duration) (*DrainResult, bool) {
+	o.mu.RLock()
+	inst, ok := o.instances[id]
+	o.mu.RUnlock()
+	if !ok {
+		httpx.Error(w, 404, fmt.Errorf("instance %q not found", id))
+		return nil, false
+	}
+	if !instanceIsActive(inst) || inst.Status != "running" {
+		httpx.Error(w, 503, fmt.Errorf("instance %q is not running (status: %s)", id, inst.Status))
+		return nil, false
+	}
+
+	// The wait, a replica launch and the migration outlast the server's
+	// write timeout. A client hanging up must not abort a half-done
+	// migration either.
+	httpx.ExtendWriteDeadline(w, timeout+routeInstanceReadyWait+drainMigrateTimeout+instanceRestartTimeout)
+	res, err := o.Drain(context.WithoutCancel(r.Context()), id, timeout)
+	switch {
+	case err == nil:
+		return res, true
+	case errors.Is(err, ErrInstanceDraining):
+		httpx.ErrorCode(w, http.StatusConflict, "instance_draining", err.Error(), true, nil)
+	case errors.Is(err, ErrNoDrainTarget):
+		httpx.ErrorCode(w, http.StatusServiceUnavailable, "no_drain_target", err.Error(), true, nil)
+	default:
+		httpx.Error(w, 502, err)
+	}
+	return nil, false
 }
 
 func (o *Orchestrator) handleStartByInstanceID(w http.ResponseWriter, r *http.Request) {
@@ -113,6 +227,7 @@ func (o *Orchestrator) handleStartByInstanceID(w http.ResponseWriter, r *http.Re
 	port := inst.Port
 	profileName := inst.ProfileName
 	headless := inst.Headless
+	proxyPool, proxyKey := inst.proxyPool, inst.proxyKey
 	o.mu.RUnlock()
 
 	if inst.Attached && inst.AttachType != "bridge" {
@@ -137,6 +252,8 @@ func (o *Orchestrator) handleStartByInstanceID(w http.ResponseWriter, r *http.Re
 
 	started, err := o.LaunchWithOptions(profileName, port, headless, LaunchOptions{
 		SecurityPolicy: inst.requestedSecurityPolicy,
+		ProxyPool:      proxyPool,
+		ProxyKey:       proxyKey,
 	})
 	if err != nil {
 		writeLaunchError(w, err)
@@ -251,6 +368,10 @@ func (o *Orchestrator) startInstanceWithRequest(w http.ResponseWriter, r *http.R
 	var profileName string
 	var err error
 
+	if tok, ok := instanceRestrictedToken(r); ok && (req.ProfileID == "" || !o.tokenAllowsProfile(tok, req.ProfileID)) {
+		writeTokenInstanceForbidden(w, tok)
+		return
+	}
 	if req.ProfileID != "" {
 		profileName, err = o.resolveProfileName(req.ProfileID)
 		if err != nil {
@@ -269,6 +390,8 @@ func (o *Orchestrator) startInstanceWithRequest(w http.ResponseWriter, r *http.R
 		ExtensionPaths: req.ExtensionPaths,
 		SecurityPolicy: req.SecurityPolicy,
 		Browser:        req.Browser,
+		ProxyPool:      req.ProxyPool,
+		ProxyKey:       req.ProxyKey,
 	}
 
 	inst, err := o.LaunchWithTargetSelection(profileName, req.Port, headless, req.Browser, req.FallbackTargets, opts)
@@ -288,6 +411,14 @@ func writeLaunchError(w http.ResponseWriter, err error) {
 		httpx.Error(w, http.StatusBadRequest, err)
 		return
 	}
+	if errors.Is(err, proxypool.ErrUnknownPool) {
+		httpx.ErrorCode(w, http.StatusBadRequest, "unknown_proxy_pool", err.Error(), false, nil)
+		return
+	}
+	if errors.Is(err, proxypool.ErrNoHealthyProxy) {
+		httpx.ErrorCode(w, http.StatusServiceUnavailable, "no_healthy_proxy", err.Error(), true, nil)
+		return
+	}
 	var exhausted *FallbackExhaustedError
 	if errors.As(err, &exhausted) {
 		attempts := make([]map[string]string, 0, len(exhausted.Attempts))
@@ -361,6 +492,11 @@ func (o *Orchestrator) handleInstanceTabs(w http.ResponseWriter, r *http.Request
 }
 
 func (o *Orchestrator) handleAttachInstance(w http.ResponseWriter, r *http.Request) {
+	// An attached browser belongs to no profile a restricted token names.
+	if tok, ok := instanceRestrictedToken(r); ok {
+		writeTokenInstanceForbidden(w, tok)
+		return
+	}
 	var req struct {
 		CdpURL   string `json:"cdpUrl"`
 		Name     string `json:"name,omitempty"`
@@ -417,6 +553,11 @@ func (o *Orchestrator) handleAttachInstance(w http.ResponseWriter, r *http.Reque
 }
 
 func (o *Orchestrator) handleAttachBridge(w http.ResponseWriter, r *http.Request) {
+	// An attached browser belongs to no profile a restricted token names.
+	if tok, ok := instanceRestrictedToken(r); ok {
+		writeTokenInstanceForbidden(w, tok)
+		return
+	}
 	var req struct {
 		BaseURL string `json:"baseUrl"`
 		Name    string `json:"name,omitempty"`
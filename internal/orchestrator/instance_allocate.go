@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+// ExtractInstanceRequirements reads what a shorthand request needs from its
+// instance: ?browserMode=headless|headed and ?proxyRegion=. The browser
+// provider is resolved separately from ?browser.
+func ExtractInstanceRequirements(r *http.Request) (allocation.Requirements, error) {
+	var req allocation.Requirements
+	if r == nil {
+		return req, nil
+	}
+	q := r.URL.Query()
+	switch mode := strings.ToLower(strings.TrimSpace(q.Get("browserMode"))); mode {
+	case "", "headless", "headed":
+		req.Mode = mode
+	default:
+		return req, fmt.Errorf("invalid browserMode %q (use headless or headed)", q.Get("browserMode"))
+	}
+	req.ProxyRegion = strings.TrimSpace(q.Get("proxyRegion"))
+	if tok, ok := instanceRestrictedToken(r); ok {
+		req.Allowed = func(inst bridge.Instance) bool { return tokenAllowsInstance(tok, inst) }
+	}
+	return req, nil
+}
+
+// runningCandidates lists running instances with a URL that meet req,
+// oldest first. Draining instances take no new work.
+func (o *Orchestrator) runningCandidates(req allocation.Requirements) []bridge.Instance {
+	o.mu.RLock()
+	candidates := make([]bridge.Instance, 0, len(o.instances))
+	for _, inst := range o.instances {
+		if inst.Status != "running" || !instanceIsActive(inst) || inst.URL == "" || o.bindings.Draining(inst.ID) {
+			continue
+		}
+		if !req.Matches(inst.Instance) {
+			continue
+		}
+		c := inst.Instance
+		c.URL = inst.URL
+		candidates = append(candidates, c)
+	}
+	o.mu.RUnlock()
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].StartTime.Equal(candidates[j].StartTime) {
+			return candidates[i].URL < candidates[j].URL
+		}
+		return candidates[i].StartTime.Before(candidates[j].StartTime)
+	})
+	return candidates
+}
+
+// allocateURL returns the URL of the running instance the allocation policy
+// picks among those meeting req, or "" when none does. Under fcfs this is
+// the oldest instance.
+func (o *Orchestrator) allocateURL(req allocation.Requirements) string {
+	candidates := o.runningCandidates(req)
+	if len(candidates) == 0 {
+		return ""
+	}
+	if o.instanceMgr == nil {
+		return candidates[0].URL
+	}
+	selected, err := o.instanceMgr.Allocator.Policy().Select(candidates)
+	if err != nil {
+		return candidates[0].URL
+	}
+	return selected.URL
+}
+
+// proxyPoolForRegion returns the configured pool serving region.
+func (o *Orchestrator) proxyPoolForRegion(region string) (string, error) {
+	var pools config.ProxyPoolsConfig
+	if o.runtimeCfg != nil {
+		pools = o.runtimeCfg.ProxyPools
+	}
+	pool, ok := config.ProxyPoolForRegion(pools, region)
+	if !ok {
+		return "", fmt.Errorf("no proxy pool configured for region %q", region)
+	}
+	return pool, nil
+}
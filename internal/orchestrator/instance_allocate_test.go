@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func addRunningInstance(o *Orchestrator, inst bridge.Instance) {
+	o.instances[inst.ID] = &InstanceInternal{
+		Instance: inst,
+		URL:      inst.URL,
+		cmd:      &mockCmd{pid: 1, isAlive: true},
+	}
+}
+
+func TestFirstRunningURLForRequest_FiltersByRequirements(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	now := time.Now()
+	addRunningInstance(o, bridge.Instance{ID: "plain", URL: "http://plain.local", Status: "running", Mode: "headless", Headless: true, StartTime: now})
+	addRunningInstance(o, bridge.Instance{ID: "headed", URL: "http://headed.local", Status: "running", Mode: "headed", StartTime: now.Add(time.Second)})
+	addRunningInstance(o, bridge.Instance{ID: "eu", URL: "http://eu.local", Status: "running", Mode: "headless", Headless: true, ProxyRegion: "eu-west", StartTime: now.Add(2 * time.Second)})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "http://plain.local"},
+		{"?browserMode=headed", "http://headed.local"},
+		{"?proxyRegion=eu-west", "http://eu.local"},
+		{"?browserMode=headed&proxyRegion=eu-west", ""},
+	}
+	for _, tt := range tests {
+		url, status, err := o.FirstRunningURLForRequest(httptest.NewRequest(http.MethodGet, "/text"+tt.query, nil))
+		if err != nil {
+			t.Fatalf("%q: status=%d err=%v", tt.query, status, err)
+		}
+		if url != tt.want {
+			t.Errorf("%q: url = %q, want %q", tt.query, url, tt.want)
+		}
+	}
+}
+
+func TestFirstRunningURLForRequest_InvalidBrowserMode(t *testing.T) {
+	o := NewOrchestrator(t.TempDir())
+	_, status, err := o.FirstRunningURLForRequest(httptest.NewRequest(http.MethodGet, "/text?browserMode=kiosk", nil))
+	if err == nil || status != http.StatusBadRequest {
+		t.Fatalf("status=%d err=%v, want 400", status, err)
+	}
+}
+
+func TestFirstRunningURLForRequest_LeastLoaded(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{AllocationPolicy: "least_loaded"})
+	now := time.Now()
+	addRunningInstance(o, bridge.Instance{ID: "busy", URL: "http://busy.local", Status: "running", StartTime: now})
+	addRunningInstance(o, bridge.Instance{ID: "idle", URL: "http://idle.local", Status: "running", StartTime: now.Add(time.Second)})
+	o.loads.update([]InstanceLoad{
+		{InstanceID: "busy", Scraped: true, Tabs: 12, QueuedActions: 5, InFlightActions: 4, MemoryMB: 900, At: now},
+		{InstanceID: "idle", Scraped: true, Tabs: 1, MemoryMB: 200, At: now},
+	})
+
+	url, _, err := o.FirstRunningURLForRequest(httptest.NewRequest(http.MethodGet, "/text", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "http://idle.local" {
+		t.Errorf("url = %q, want the idle instance", url)
+	}
+}
+
+func TestRouteForRequest_UnknownProxyRegion(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{ProxyPools: config.ProxyPoolsConfig{
+		"eu": {Region: "eu-west", Proxies: []config.BrowserProxyConfig{{Server: "http://eu.proxy:8080"}}},
+	}})
+
+	_, status, err := o.RouteForRequest(httptest.NewRequest(http.MethodGet, "/text?proxyRegion=ap-south", nil))
+	if err == nil || status != http.StatusBadRequest {
+		t.Fatalf("status=%d err=%v, want 400 for a region without a pool", status, err)
+	}
+}
@@ -0,0 +1,379 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/handlers"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+	"github.com/pinchtab/pinchtab/internal/readiness"
+)
+
+const (
+	// defaultDrainTimeout is how long a drain waits for in-flight work;
+	// maxDrainTimeout caps what a caller may ask for.
+	defaultDrainTimeout = 60 * time.Second
+	maxDrainTimeout     = 10 * time.Minute
+	drainPollInterval   = 500 * time.Millisecond
+	// drainMigrateTimeout bounds exporting and reopening all tabs.
+	drainMigrateTimeout = 2 * time.Minute
+	// migratedTabAliasTTL is how long requests naming a migrated tab's old
+	// id are rerouted to its new id.
+	migratedTabAliasTTL = 30 * time.Minute
+)
+
+// MigratedTabHeader names, on a response, the old tab id a request used
+// when the orchestrator rerouted it to the tab's migrated id.
+const MigratedTabHeader = "X-PinchTab-Migrated-From"
+
+var (
+	// ErrInstanceDraining is returned when a drain is already under way.
+	ErrInstanceDraining = errors.New("instance is already draining")
+	// ErrNoDrainTarget is returned when no instance can take the tabs.
+	ErrNoDrainTarget = errors.New("no instance to migrate tabs to")
+)
+
+// DrainTaskSource reports scheduler work still aimed at tabs, so a drain
+// can wait for it. The scheduler implements it.
+type DrainTaskSource interface {
+	ActiveTasksForTabs(tabIDs []string) int
+}
+
+// SetDrainTaskSource makes drains wait for the source's tasks.
+func (o *Orchestrator) SetDrainTaskSource(src DrainTaskSource) {
+	o.mu.Lock()
+	o.drainTasks = src
+	o.mu.Unlock()
+}
+
+// MigratedTab is where one tab of a drained instance went. Tab ids change
+// across instances; To is the id on the target, and requests naming From
+// are rerouted to it for a while. Lock is the tab's lease re-acquired on
+// the target with a new fencing token.
+type MigratedTab struct {
+	From      string                  `json:"from"`
+	To        string                  `json:"to,omitempty"`
+	URL       string                  `json:"url"`
+	Lock      *handlers.MigrationLock `json:"lock,omitempty"`
+	LockError string                  `json:"lockError,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// DrainResult reports a drain.
+type DrainResult struct {
+	InstanceID     string `json:"instanceId"`
+	TargetID       string `json:"targetId,omitempty"`
+	TargetLaunched bool   `json:"targetLaunched,omitempty"`
+	// TimedOut is set when in-flight work had not finished by the timeout;
+	// the tabs were migrated anyway.
+	TimedOut      bool          `json:"timedOut,omitempty"`
+	WaitMs        int64         `json:"waitMs"`
+	Tabs          []MigratedTab `json:"tabs"`
+	BindingsMoved int           `json:"bindingsMoved"`
+}
+
+// Drain moves an instance's work elsewhere ahead of a stop or restart. It
+// stops new bindings to the instance, waits up to timeout for its in-flight
+// actions and scheduler tasks, then reopens each tab — URL, cookies,
+// storage and current-tab scopes — on another instance of the same profile
+// and moves the session and agent bindings there.
+//
+// From the export until the tabs are reopened the instance is migrating:
+// requests naming its tabs get 409 instance_draining and, retried, follow
+// the tabs to their new ids.
+//
+// The instance stays draining afterwards so nothing new lands on it; stopping
+// it ends the drain, and a restart calls Undrain on the bindings. On error
+// the drain is undone and the instance keeps its tabs.
+func (o *Orchestrator) Drain(ctx context.Context, id string, timeout time.Duration) (*DrainResult, error) {
+	o.mu.RLock()
+	src, ok := o.instances[id]
+	o.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("instance %q not found", id)
+	}
+	if !o.bindings.Drain(id) {
+		return nil, fmt.Errorf("%w: %q", ErrInstanceDraining, id)
+	}
+	info := src.Instance
+	o.emitEvent("instance.draining", &info)
+
+	res := &DrainResult{InstanceID: id, Tabs: []MigratedTab{}}
+	started := time.Now()
+	res.TimedOut = !o.waitDrainIdle(ctx, src, timeout)
+	res.WaitMs = time.Since(started).Milliseconds()
+
+	migrateCtx, cancel := context.WithTimeout(ctx, drainMigrateTimeout)
+	defer cancel()
+	o.bindings.SetMigrating(id, true)
+	defer o.bindings.SetMigrating(id, false)
+	tabs, err := o.exportTabs(migrateCtx, src)
+	if err != nil {
+		o.bindings.Undrain(id)
+		return nil, fmt.Errorf("export tabs: %w", err)
+	}
+	if len(tabs) > 0 {
+		target, launched, err := o.drainTarget(src)
+		if err != nil {
+			o.bindings.Undrain(id)
+			return nil, fmt.Errorf("%w: %v", ErrNoDrainTarget, err)
+		}
+		res.TargetID, res.TargetLaunched = target.ID, launched
+		for _, tab := range tabs {
+			res.Tabs = append(res.Tabs, o.migrateTab(migrateCtx, target, tab))
+		}
+		res.BindingsMoved = o.bindings.MoveInstance(id, target.ID)
+		if o.tabsCache != nil {
+			o.tabsCache.Invalidate(id)
+			o.tabsCache.Invalidate(target.ID)
+		}
+	}
+
+	slog.Info("instance drained", "id", id, "target", res.TargetID, "tabs", len(res.Tabs),
+		"bindingsMoved", res.BindingsMoved, "timedOut", res.TimedOut, "waitMs", res.WaitMs)
+	o.emitEvent("instance.drained", &info)
+	return res, nil
+}
+
+// waitDrainIdle polls inst until it runs no tab actions and the scheduler
+// holds no unfinished task for its tabs. It reports false on timeout.
+func (o *Orchestrator) waitDrainIdle(ctx context.Context, inst *InstanceInternal, timeout time.Duration) bool {
+	_, err := readiness.WaitUntil(ctx, timeout, drainPollInterval, func() (struct{}, bool, error) {
+		return struct{}{}, o.drainIdle(ctx, inst), nil
+	})
+	return err == nil
+}
+
+func (o *Orchestrator) drainIdle(ctx context.Context, inst *InstanceInternal) bool {
+	var load InstanceLoad
+	o.scrapeLoad(ctx, inst, &load)
+	if load.QueuedActions+load.InFlightActions > 0 {
+		return false
+	}
+	o.mu.RLock()
+	tasks := o.drainTasks
+	o.mu.RUnlock()
+	if tasks == nil {
+		return true
+	}
+	remote, err := o.fetchTabs(inst)
+	if err != nil {
+		return true
+	}
+	ids := make([]string, 0, len(remote))
+	for _, tab := range remote {
+		ids = append(ids, tab.ID)
+	}
+	return tasks.ActiveTasksForTabs(ids) == 0
+}
+
+// drainTarget picks the instance that takes src's tabs: a running instance
+// of the same profile, or a replica of it, with the same browser, mode and
+// proxy region. The allocation policy chooses among several. With none
+// running, a replica is launched.
+func (o *Orchestrator) drainTarget(src *InstanceInternal) (*InstanceInternal, bool, error) {
+	family := ProfileFamily(src.ProfileName)
+	req := allocation.Requirements{Browser: src.Browser, ProxyRegion: src.ProxyRegion}
+	if src.Mode == "headless" || src.Mode == "headed" {
+		req.Mode = src.Mode
+	} else {
+		req.Mode = bridge.ModeFromHeadless(src.Headless)
+	}
+
+	var candidates []bridge.Instance
+	for _, c := range o.runningCandidates(req) {
+		// Attached bridges do not accept the orchestrator's internal token.
+		if c.ID != src.ID && !c.Attached && ProfileFamily(c.ProfileName) == family {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) > 0 {
+		picked := candidates[0]
+		if o.instanceMgr != nil {
+			if selected, err := o.instanceMgr.Allocator.Policy().Select(candidates); err == nil {
+				picked = selected
+			}
+		}
+		o.mu.RLock()
+		target := o.instances[picked.ID]
+		o.mu.RUnlock()
+		if target != nil {
+			return target, false, nil
+		}
+	}
+
+	launched, err := o.LaunchWithOptions(ReplicaProfileName(family), "", src.Headless, LaunchOptions{
+		SecurityPolicy:    src.requestedSecurityPolicy,
+		RequestedProvider: src.requestedProvider,
+		Browser:           src.browser,
+		ProxyPool:         src.proxyPool,
+		ProxyKey:          src.proxyKey,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("launch replica: %w", err)
+	}
+	o.mu.RLock()
+	target := o.instances[launched.ID]
+	o.mu.RUnlock()
+	if target == nil {
+		return nil, false, fmt.Errorf("replica %q disappeared before becoming ready", launched.ID)
+	}
+	if _, err := o.waitForRequestRouteReady(target, routeInstanceReadyWait); err != nil {
+		return nil, false, err
+	}
+	return target, true, nil
+}
+
+// exportTabs reads every tab of inst with the state needed to reopen it.
+func (o *Orchestrator) exportTabs(ctx context.Context, inst *InstanceInternal) ([]handlers.MigrationTab, error) {
+	target, err := o.instancePathURL(inst, "/migration/tabs", "")
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := o.doInstanceRequest(ctx, http.MethodGet, http.Header{}, nil, target, inst)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, compactBody(body))
+	}
+	var result struct {
+		Tabs []handlers.MigrationTab `json:"tabs"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Tabs, nil
+}
+
+// migrateTab reopens tab on target, points the tab locator at it and
+// aliases the old id to the new one.
+func (o *Orchestrator) migrateTab(ctx context.Context, target *InstanceInternal, tab handlers.MigrationTab) MigratedTab {
+	m := MigratedTab{From: tab.TabID, URL: tab.URL}
+	res, err := o.importTab(ctx, target, tab)
+	if err != nil {
+		m.Error = err.Error()
+		return m
+	}
+	m.To = res.TabID
+	m.Lock, m.LockError = res.Lock, res.LockError
+	switch {
+	case res.NavigateError != "":
+		m.Error = "navigate: " + res.NavigateError
+	case tab.Error != "":
+		m.Error = "state not captured: " + tab.Error
+	}
+	if o.instanceMgr != nil {
+		o.instanceMgr.InvalidateTab(tab.TabID)
+		o.instanceMgr.RegisterTab(res.TabID, target.ID)
+		o.instanceMgr.AliasTab(tab.TabID, res.TabID, migratedTabAliasTTL)
+	}
+	return m
+}
+
+// refuseMigratingTab answers 409 instance_draining when the instance owning
+// tabID is moving its tabs, so no request acts on a tab about to be reopened
+// elsewhere. It reports whether it wrote the response.
+func (o *Orchestrator) refuseMigratingTab(w http.ResponseWriter, instanceID, tabID string) bool {
+	if !o.bindings.Migrating(instanceID) {
+		return false
+	}
+	httpx.ErrorCode(w, http.StatusConflict, "instance_draining",
+		fmt.Sprintf("tab %q is moving off draining instance %q; retry shortly", tabID, instanceID), true, nil)
+	return true
+}
+
+// followMigratedTab reroutes a request naming a migrated tab's old id to
+// its new id, rewriting the id where the request carried it. Requests for
+// other tabs come back unchanged.
+func (o *Orchestrator) followMigratedTab(w http.ResponseWriter, r *http.Request, tabID string, src TabIDSource) (*http.Request, string) {
+	if o.instanceMgr == nil || tabID == "" {
+		return r, tabID
+	}
+	newID, ok := o.instanceMgr.ResolveTabAlias(tabID)
+	if !ok {
+		return r, tabID
+	}
+	r2 := r.Clone(r.Context())
+	switch src {
+	case TabIDSourcePath:
+		segments := strings.Split(r2.URL.Path, "/")
+		for i, seg := range segments {
+			if seg == tabID {
+				segments[i] = newID
+			}
+		}
+		r2.URL.Path = strings.Join(segments, "/")
+		r2.URL.RawPath = ""
+		r2.SetPathValue("id", newID)
+	case TabIDSourceQuery:
+		q := r2.URL.Query()
+		q.Set("tabId", newID)
+		r2.URL.RawQuery = q.Encode()
+	case TabIDSourceBody:
+		if !rewriteBodyTabID(r2, newID) {
+			return r, tabID
+		}
+	}
+	w.Header().Set(MigratedTabHeader, tabID)
+	return r2, newID
+}
+
+// rewriteBodyTabID replaces the tabId field of a JSON body that
+// ExtractExplicitTabID has already buffered.
+func rewriteBodyTabID(r *http.Request, tabID string) bool {
+	buf, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &body); err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(buf))
+		return false
+	}
+	body["tabId"], _ = json.Marshal(tabID)
+	out, err := json.Marshal(body)
+	if err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(buf))
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(out))
+	r.ContentLength = int64(len(out))
+	r.Header.Set("Content-Length", strconv.Itoa(len(out)))
+	return true
+}
+
+func (o *Orchestrator) importTab(ctx context.Context, inst *InstanceInternal, tab handlers.MigrationTab) (*handlers.MigrationImportResult, error) {
+	target, err := o.instancePathURL(inst, "/migration/tabs", "")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(tab)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	resp, body, err := o.doInstanceRequest(ctx, http.MethodPost, header, payload, target, inst)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("import tab: status %d: %s", resp.StatusCode, compactBody(body))
+	}
+	var res handlers.MigrationImportResult
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
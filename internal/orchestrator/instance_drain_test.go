@@ -0,0 +1,287 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/handlers"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+type fakeDrainTasks struct{ remaining atomic.Int32 }
+
+func (f *fakeDrainTasks) ActiveTasksForTabs(tabIDs []string) int {
+	if len(tabIDs) == 0 {
+		return 0
+	}
+	// Each poll sees one task fewer.
+	return int(max(f.remaining.Add(-1)+1, 0))
+}
+
+func TestProfileFamily(t *testing.T) {
+	tests := map[string]string{
+		"work":                        "work",
+		"instance-work-scale-1a2b":    "work",
+		"instance-a-scale-b-scale-ff": "a-scale-b",
+		"instance-work-scale-zz":      "instance-work-scale-zz",
+		"instance-work":               "instance-work",
+	}
+	for name, want := range tests {
+		if got := ProfileFamily(name); got != want {
+			t.Errorf("ProfileFamily(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if replica := ReplicaProfileName("work"); ProfileFamily(replica) != "work" || !IsReplicaOf(replica, "work") {
+		t.Errorf("ReplicaProfileName(work) = %q does not map back to work", replica)
+	}
+}
+
+func TestAllocateURLSkipsDrainingInstances(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	now := time.Now()
+	addRunningInstance(o, bridge.Instance{ID: "old", URL: "http://old.local", Status: "running", StartTime: now})
+	addRunningInstance(o, bridge.Instance{ID: "new", URL: "http://new.local", Status: "running", StartTime: now.Add(time.Second)})
+
+	o.bindings.Drain("old")
+	if got := o.allocateURL(allocation.Requirements{}); got != "http://new.local" {
+		t.Fatalf("allocateURL = %q, want the non-draining instance", got)
+	}
+	if got := o.FirstRunningURL(); got != "http://new.local" {
+		t.Fatalf("FirstRunningURL = %q, want the non-draining instance", got)
+	}
+}
+
+func TestRestartByInstanceIDDrainMigratesTabs(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+
+	var mu sync.Mutex
+	var restarted, migratingDuringExport bool
+	var imported []handlers.MigrationTab
+	var inflight atomic.Int32
+	inflight.Store(1)
+
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /metrics/prometheus":
+			n := max(inflight.Add(-1)+1, 0)
+			_, _ = fmt.Fprintf(w, "# TYPE pinchtab_tab_actions_inflight gauge\npinchtab_tab_actions_inflight %d\n# EOF\n", n)
+		case "GET /tabs":
+			_, _ = io.WriteString(w, `{"tabs":[{"id":"T1","url":"https://example.com/a"}]}`)
+		case "GET /migration/tabs":
+			mu.Lock()
+			migratingDuringExport = o.bindings.Migrating("inst_src")
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"tabs": []handlers.MigrationTab{{
+				TabID:  "T1",
+				URL:    "https://example.com/a",
+				Origin: "https://example.com",
+				Scopes: []string{"agent:agent-1"},
+			}}})
+		case "POST /browser/restart":
+			mu.Lock()
+			restarted = true
+			mu.Unlock()
+			_, _ = io.WriteString(w, `{"status":"browser_restarted"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(src.Close)
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/migration/tabs" {
+			http.NotFound(w, r)
+			return
+		}
+		var tab handlers.MigrationTab
+		_ = json.NewDecoder(r.Body).Decode(&tab)
+		mu.Lock()
+		imported = append(imported, tab)
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(handlers.MigrationImportResult{TabID: "T9", URL: tab.URL})
+	}))
+	t.Cleanup(dst.Close)
+	o.client = &http.Client{}
+
+	now := time.Now()
+	addRunningInstance(o, bridge.Instance{ID: "inst_src", ProfileName: "work", URL: src.URL, Status: "running", Headless: true, StartTime: now})
+	addRunningInstance(o, bridge.Instance{ID: "inst_dst", ProfileName: "instance-work-scale-1f", URL: dst.URL, Status: "running", Headless: true, StartTime: now.Add(time.Second)})
+	addRunningInstance(o, bridge.Instance{ID: "inst_other", ProfileName: "other", URL: dst.URL, Status: "running", Headless: true, StartTime: now.Add(-time.Second)})
+	for _, inst := range o.instances {
+		o.syncInstanceToManager(&inst.Instance)
+	}
+	o.bindings.BindAgent("agent-1", "inst_src")
+	o.bindings.BindSession("ses_1", "inst_src")
+	tasks := &fakeDrainTasks{}
+	tasks.remaining.Store(1)
+	o.SetDrainTaskSource(tasks)
+
+	req := httptest.NewRequest(http.MethodPost, "/instances/inst_src/restart?mode=drain&timeout=10", nil)
+	req.SetPathValue("id", "inst_src")
+	w := httptest.NewRecorder()
+	o.handleRestartByInstanceID(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Status string      `json:"status"`
+		Drain  DrainResult `json:"drain"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	d := resp.Drain
+	if resp.Status != "browser_restarted" || d.TargetID != "inst_dst" || d.TimedOut || d.BindingsMoved != 2 {
+		t.Fatalf("response = %+v", resp)
+	}
+	if len(d.Tabs) != 1 || d.Tabs[0].From != "T1" || d.Tabs[0].To != "T9" || d.Tabs[0].Error != "" {
+		t.Fatalf("tabs = %+v", d.Tabs)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !restarted {
+		t.Fatal("browser restart was not requested after the drain")
+	}
+	if !migratingDuringExport || o.bindings.Migrating("inst_src") {
+		t.Fatalf("migrating during export = %v, after drain = %v; want true, false", migratingDuringExport, o.bindings.Migrating("inst_src"))
+	}
+	if len(imported) != 1 || len(imported[0].Scopes) != 1 || imported[0].Scopes[0] != "agent:agent-1" {
+		t.Fatalf("imported = %+v", imported)
+	}
+	if inst, _ := o.bindings.ResolveAgent("agent-1"); inst != "inst_dst" {
+		t.Fatalf("agent bound to %q, want inst_dst", inst)
+	}
+	if o.bindings.Draining("inst_src") {
+		t.Fatal("restart should end the drain")
+	}
+	if owner, err := o.instanceMgr.FindInstanceByTabID("T9"); err != nil || owner.ID != "inst_dst" {
+		t.Fatalf("T9 owner = %+v, %v", owner, err)
+	}
+	if to, ok := o.instanceMgr.ResolveTabAlias("T1"); !ok || to != "T9" {
+		t.Fatalf("T1 alias = %q, %v; want T9", to, ok)
+	}
+}
+
+func TestFollowMigratedTabRewritesOldIDs(t *testing.T) {
+	o := NewOrchestrator(t.TempDir())
+	o.instanceMgr.AliasTab("T1", "T9", time.Minute)
+
+	req := httptest.NewRequest(http.MethodGet, "/tabs/T1/snapshot", nil)
+	req.SetPathValue("id", "T1")
+	w := httptest.NewRecorder()
+	got, id := o.followMigratedTab(w, req, "T1", TabIDSourcePath)
+	if id != "T9" || got.URL.Path != "/tabs/T9/snapshot" || got.PathValue("id") != "T9" {
+		t.Fatalf("path rewrite = %q %q %q", id, got.URL.Path, got.PathValue("id"))
+	}
+	if w.Header().Get(MigratedTabHeader) != "T1" {
+		t.Fatalf("%s = %q, want T1", MigratedTabHeader, w.Header().Get(MigratedTabHeader))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/snapshot?tabId=T1&filter=interactive", nil)
+	got, _ = o.followMigratedTab(httptest.NewRecorder(), req, "T1", TabIDSourceQuery)
+	if got.URL.Query().Get("tabId") != "T9" || got.URL.Query().Get("filter") != "interactive" {
+		t.Fatalf("query rewrite = %q", got.URL.RawQuery)
+	}
+
+	body := `{"tabId":"T1","kind":"click","ref":"e5"}`
+	req = httptest.NewRequest(http.MethodPost, "/action", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	tabID, src := ExtractExplicitTabID(req)
+	got, _ = o.followMigratedTab(httptest.NewRecorder(), req, tabID, src)
+	var decoded map[string]string
+	if err := json.NewDecoder(got.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["tabId"] != "T9" || decoded["ref"] != "e5" {
+		t.Fatalf("body rewrite = %v", decoded)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tabs/T2/snapshot", nil)
+	if got, id := o.followMigratedTab(httptest.NewRecorder(), req, "T2", TabIDSourcePath); got != req || id != "T2" {
+		t.Fatal("a tab without an alias should pass through")
+	}
+}
+
+func TestTabRequestsHeldWhileDrainMovesTabs(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	_, gotPath := newBackendInstance(t, o, "inst_src")
+	newBackendInstance(t, o, "inst_dst")
+	o.instanceMgr.Locator.Register("T1", "inst_src")
+	o.bindings.SetMigrating("inst_src", true)
+
+	req := httptest.NewRequest(http.MethodGet, "/tabs/T1/snapshot", nil)
+	req.SetPathValue("id", "T1")
+	w := httptest.NewRecorder()
+	o.proxyTabRequest(w, req)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "instance_draining") {
+		t.Fatalf("tab path: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	o.WrapShorthand(http.NotFound)(w, httptest.NewRequest(http.MethodGet, "/snapshot?tabId=T1", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("tabId query: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if *gotPath != "" {
+		t.Fatalf("migrating instance was hit at %q", *gotPath)
+	}
+
+	o.bindings.SetMigrating("inst_src", false)
+	w = httptest.NewRecorder()
+	o.proxyTabRequest(w, req)
+	if w.Code != http.StatusOK || *gotPath != "/tabs/T1/snapshot" {
+		t.Fatalf("after migration: status = %d, path = %q", w.Code, *gotPath)
+	}
+}
+
+func TestStopByInstanceIDRejectsBadDrainParams(t *testing.T) {
+	o := NewOrchestrator(t.TempDir())
+	for _, query := range []string{"?mode=graceful", "?mode=drain&timeout=0", "?mode=drain&timeout=9999"} {
+		req := httptest.NewRequest(http.MethodPost, "/instances/x/stop"+query, nil)
+		req.SetPathValue("id", "x")
+		w := httptest.NewRecorder()
+		o.handleStopByInstanceID(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestDrainWithoutTargetKeepsInstance(t *testing.T) {
+	alwaysAlive(t)
+	// The replica launch is the only way to find a target, and it fails.
+	o := NewOrchestratorWithRunner(t.TempDir(), &mockRunner{runErr: errors.New("no browser")})
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/migration/tabs":
+			_, _ = io.WriteString(w, `{"tabs":[{"tabId":"T1","url":"https://example.com"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(src.Close)
+	o.client = &http.Client{}
+	addRunningInstance(o, bridge.Instance{ID: "inst_src", ProfileName: "work", URL: src.URL, Status: "running", Headless: true})
+
+	_, err := o.Drain(t.Context(), "inst_src", 10*time.Millisecond)
+	if err == nil {
+		t.Fatal("Drain should fail without a target")
+	}
+	if o.bindings.Draining("inst_src") {
+		t.Fatal("a failed drain should be undone")
+	}
+}
@@ -139,6 +139,30 @@ func (o *Orchestrator) LaunchWithOptions(name, port string, headless bool, opts
 		}
 	}
 
+	proxyPool := strings.TrimSpace(opts.ProxyPool)
+	proxyKey := strings.TrimSpace(opts.ProxyKey)
+	proxyServer, proxyRegion := "", ""
+	if proxyPool != "" {
+		if proxyKey == "" {
+			proxyKey = name
+		}
+		px, err := o.proxyPoolManager().Acquire(proxyPool, proxyKey)
+		if err != nil {
+			return nil, err
+		}
+		// Copy before overriding: effectiveCfg may be the shared runtime config.
+		pooledCfg := config.RuntimeConfig{}
+		if effectiveCfg != nil {
+			pooledCfg = *effectiveCfg
+		}
+		pooledCfg.Proxy = px
+		effectiveCfg = &pooledCfg
+		proxyServer = px.Redacted().Server
+		if o.runtimeCfg != nil {
+			proxyRegion = o.runtimeCfg.ProxyPools[proxyPool].Region
+		}
+	}
+
 	childConfigPath, err := o.writeChildConfig(effectiveCfg, port, cdpPort, profilePath, instanceStateDir, headless, opts.ExtensionPaths, effectivePolicy)
 	if err != nil {
 		return nil, fmt.Errorf("write child config: %w", err)
@@ -189,6 +213,9 @@ func (o *Orchestrator) LaunchWithOptions(name, port string, headless bool, opts
 			StartTime:      time.Now(),
 			SecurityPolicy: effectivePolicy,
 			Browser:        browser,
+			ProxyPool:      proxyPool,
+			Proxy:          proxyServer,
+			ProxyRegion:    proxyRegion,
 		},
 		URL:     o.childInstanceBaseURL(port),
 		cdpPort: cdpPort,
@@ -199,6 +226,8 @@ func (o *Orchestrator) LaunchWithOptions(name, port string, headless bool, opts
 		requestedProvider:       opts.RequestedProvider,
 		browser:                 opts.Browser,
 		effectiveBinary:         effectiveBinaryFromCfg(effectiveCfg),
+		proxyPool:               proxyPool,
+		proxyKey:                proxyKey,
 	}
 
 	o.mu.Lock()
@@ -0,0 +1,112 @@
+package orchestrator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+// InstanceLoad is how busy a running instance is, read from its metrics
+// exposition and the orchestrator's routing state.
+type InstanceLoad struct {
+	InstanceID  string    `json:"instanceId"`
+	ProfileName string    `json:"profileName"`
+	StartTime   time.Time `json:"startTime"`
+	Tabs        int       `json:"tabs"`
+	MemoryMB    float64   `json:"memoryMB"`
+	// CPUSeconds is cumulative; rates come from comparing two readings.
+	CPUSeconds float64 `json:"cpuSeconds"`
+	// QueuedActions and InFlightActions are the tab executor's backlog.
+	QueuedActions   int `json:"queuedActions"`
+	InFlightActions int `json:"inflightActions"`
+	// RequestSeconds and Requests are the cumulative sum and count of the
+	// instance's request latency histogram.
+	RequestSeconds float64 `json:"requestSeconds"`
+	Requests       float64 `json:"requests"`
+	// Bindings counts the sessions and agents routed to the instance.
+	Bindings int `json:"bindings"`
+	// Scraped is false when the instance's metrics could not be read; the
+	// browser gauges are then zero.
+	Scraped bool      `json:"scraped"`
+	At      time.Time `json:"at"`
+}
+
+// InstanceLoads scrapes every running instance, sorted by start time. A
+// failed scrape still yields an entry so callers see the instance.
+func (o *Orchestrator) InstanceLoads(ctx context.Context) []InstanceLoad {
+	o.mu.RLock()
+	instances := make([]*InstanceInternal, 0, len(o.instances))
+	for _, inst := range o.instances {
+		if inst.Status == "running" && instanceIsActive(inst) {
+			instances = append(instances, inst)
+		}
+	}
+	o.mu.RUnlock()
+
+	loads := make([]InstanceLoad, len(instances))
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		loads[i] = InstanceLoad{
+			InstanceID:  inst.ID,
+			ProfileName: inst.ProfileName,
+			StartTime:   inst.StartTime,
+			Bindings:    o.bindings.BoundTo(inst.ID),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			o.scrapeLoad(ctx, inst, &loads[i])
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(loads, func(i, j int) bool {
+		if loads[i].StartTime.Equal(loads[j].StartTime) {
+			return loads[i].InstanceID < loads[j].InstanceID
+		}
+		return loads[i].StartTime.Before(loads[j].StartTime)
+	})
+	return loads
+}
+
+// scrapeLoad reads the load gauges of one instance into load. Scraped stays
+// false when its metrics cannot be read.
+func (o *Orchestrator) scrapeLoad(ctx context.Context, inst *InstanceInternal, load *InstanceLoad) {
+	families, err := o.fetchExposition(ctx, inst)
+	load.At = time.Now()
+	if err != nil {
+		return
+	}
+	load.Scraped = true
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		switch f.Name {
+		case "pinchtab_browser_tabs":
+			load.Tabs = int(f.Samples[0].Value)
+		case "pinchtab_browser_memory_bytes":
+			load.MemoryMB = f.Samples[0].Value / (1024 * 1024)
+		case "pinchtab_browser_cpu_seconds":
+			if f.Type == metrics.Counter {
+				load.CPUSeconds = f.Samples[0].Value
+			}
+		case "pinchtab_tab_actions_queued":
+			load.QueuedActions = int(f.Samples[0].Value)
+		case "pinchtab_tab_actions_inflight":
+			load.InFlightActions = int(f.Samples[0].Value)
+		case "pinchtab_http_request_duration_seconds":
+			for _, s := range f.Samples {
+				switch s.Suffix {
+				case "_sum":
+					load.RequestSeconds += s.Value
+				case "_count":
+					load.Requests += s.Value
+				}
+			}
+		}
+	}
+}
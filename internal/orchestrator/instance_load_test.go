@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+func TestInstanceLoadsReadsBrowserMetrics(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metrics.FormatOpenMetrics.ContentType())
+		_, _ = w.Write([]byte("# TYPE pinchtab_browser_tabs gauge\npinchtab_browser_tabs 4\n" +
+			"# TYPE pinchtab_browser_memory_bytes gauge\npinchtab_browser_memory_bytes 209715200\n" +
+			"# TYPE pinchtab_browser_cpu_seconds counter\npinchtab_browser_cpu_seconds_total 12.5\n" +
+			"# TYPE pinchtab_tab_actions_queued gauge\npinchtab_tab_actions_queued 3\n" +
+			"# TYPE pinchtab_tab_actions_inflight gauge\npinchtab_tab_actions_inflight 2\n" +
+			"# TYPE pinchtab_http_request_duration_seconds histogram\n" +
+			"pinchtab_http_request_duration_seconds_bucket{route=\"/a\",le=\"+Inf\"} 4\n" +
+			"pinchtab_http_request_duration_seconds_sum{route=\"/a\"} 1.5\n" +
+			"pinchtab_http_request_duration_seconds_count{route=\"/a\"} 4\n" +
+			"pinchtab_http_request_duration_seconds_bucket{route=\"/b\",le=\"+Inf\"} 1\n" +
+			"pinchtab_http_request_duration_seconds_sum{route=\"/b\"} 0.5\n" +
+			"pinchtab_http_request_duration_seconds_count{route=\"/b\"} 1\n# EOF\n"))
+	}))
+	t.Cleanup(srv.Close)
+	o.client = srv.Client()
+
+	now := time.Now()
+	o.instances["inst_a"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_a", ProfileName: "work", Status: "running", URL: srv.URL, StartTime: now},
+		URL:      srv.URL,
+		cmd:      &mockCmd{pid: 1, isAlive: true},
+	}
+	o.instances["inst_b"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_b", ProfileName: "down", Status: "running", URL: "http://127.0.0.1:1", StartTime: now.Add(-time.Minute)},
+		URL:      "http://127.0.0.1:1",
+		cmd:      &mockCmd{pid: 2, isAlive: true},
+	}
+	o.bindings.BindSession("ses_1", "inst_a")
+	o.bindings.BindAgent("agent-1", "inst_a")
+
+	loads := o.InstanceLoads(context.Background())
+	if len(loads) != 2 {
+		t.Fatalf("loads = %+v, want 2 entries", loads)
+	}
+	if loads[0].InstanceID != "inst_b" || loads[0].Scraped {
+		t.Fatalf("loads[0] = %+v, want unscraped inst_b first", loads[0])
+	}
+	a := loads[1]
+	if !a.Scraped || a.Tabs != 4 || a.MemoryMB != 200 || a.CPUSeconds != 12.5 || a.Bindings != 2 {
+		t.Fatalf("loads[1] = %+v", a)
+	}
+	if a.QueuedActions != 3 || a.InFlightActions != 2 || a.RequestSeconds != 2 || a.Requests != 5 {
+		t.Fatalf("loads[1] executor/latency = %+v", a)
+	}
+}
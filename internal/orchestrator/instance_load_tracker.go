@@ -0,0 +1,99 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+// loadRefreshInterval is how often maintenance re-scrapes instance load for
+// load-aware allocation policies.
+const loadRefreshInterval = 5 * time.Second
+
+// loadTracker keeps the latest load of each running instance for allocation
+// policies. It implements allocation.LoadSource.
+type loadTracker struct {
+	mu    sync.RWMutex
+	loads map[string]allocation.Load
+	// last holds the previous reading, for latency since then.
+	last map[string]InstanceLoad
+}
+
+func newLoadTracker() *loadTracker {
+	return &loadTracker{
+		loads: make(map[string]allocation.Load),
+		last:  make(map[string]InstanceLoad),
+	}
+}
+
+// InstanceLoad returns the latest scraped load of instanceID.
+func (t *loadTracker) InstanceLoad(instanceID string) (allocation.Load, bool) {
+	if t == nil {
+		return allocation.Load{}, false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	l, ok := t.loads[instanceID]
+	return l, ok
+}
+
+// update replaces the tracked loads. Instances missing from loads, or whose
+// scrape failed, are dropped so policies treat them as unknown.
+func (t *loadTracker) update(loads []InstanceLoad) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	next := make(map[string]allocation.Load, len(loads))
+	last := make(map[string]InstanceLoad, len(loads))
+	for _, l := range loads {
+		if !l.Scraped {
+			continue
+		}
+		load := allocation.Load{
+			Tabs:            l.Tabs,
+			QueuedActions:   l.QueuedActions,
+			InFlightActions: l.InFlightActions,
+			MemoryMB:        l.MemoryMB,
+			At:              l.At,
+		}
+		prev, seen := t.last[l.InstanceID]
+		if dn := l.Requests - prev.Requests; seen && dn > 0 {
+			load.LatencyMs = (l.RequestSeconds - prev.RequestSeconds) / dn * 1000
+		} else if old, ok := t.loads[l.InstanceID]; ok {
+			// No requests since the last reading: keep the last latency.
+			load.LatencyMs = old.LatencyMs
+		}
+		next[l.InstanceID] = load
+		last[l.InstanceID] = l
+	}
+	t.loads, t.last = next, last
+}
+
+func (t *loadTracker) forget(instanceID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	delete(t.loads, instanceID)
+	delete(t.last, instanceID)
+	t.mu.Unlock()
+}
+
+// RefreshLoads scrapes every running instance and feeds the readings to the
+// load-aware allocation policy.
+func (o *Orchestrator) RefreshLoads(ctx context.Context) {
+	if o == nil || o.loads == nil {
+		return
+	}
+	o.loads.update(o.InstanceLoads(ctx))
+}
+
+// loadAwarePolicy reports whether the allocation policy uses instance load.
+func (o *Orchestrator) loadAwarePolicy() bool {
+	if o.instanceMgr == nil {
+		return false
+	}
+	_, ok := o.instanceMgr.Allocator.Policy().(allocation.LoadAware)
+	return ok
+}
@@ -0,0 +1,71 @@
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
+)
+
+func TestLoadTrackerLatencySincePreviousReading(t *testing.T) {
+	tr := newLoadTracker()
+	at := time.Now()
+	tr.update([]InstanceLoad{{InstanceID: "a", Scraped: true, Tabs: 3, RequestSeconds: 10, Requests: 100, At: at}})
+
+	l, ok := tr.InstanceLoad("a")
+	if !ok || l.Tabs != 3 || l.LatencyMs != 0 {
+		t.Fatalf("first reading = %+v ok=%v, want tabs 3 and no latency", l, ok)
+	}
+
+	tr.update([]InstanceLoad{{InstanceID: "a", Scraped: true, RequestSeconds: 12, Requests: 110, At: at.Add(time.Second)}})
+	if l, _ := tr.InstanceLoad("a"); l.LatencyMs != 200 {
+		t.Fatalf("latency = %v, want 200ms over the last 10 requests", l.LatencyMs)
+	}
+
+	// No new requests: the last latency stands.
+	tr.update([]InstanceLoad{{InstanceID: "a", Scraped: true, RequestSeconds: 12, Requests: 110, At: at.Add(2 * time.Second)}})
+	if l, _ := tr.InstanceLoad("a"); l.LatencyMs != 200 {
+		t.Fatalf("idle latency = %v, want 200", l.LatencyMs)
+	}
+}
+
+func TestLoadTrackerDropsUnscrapedAndGoneInstances(t *testing.T) {
+	tr := newLoadTracker()
+	tr.update([]InstanceLoad{
+		{InstanceID: "a", Scraped: true},
+		{InstanceID: "b", Scraped: true},
+	})
+	tr.update([]InstanceLoad{{InstanceID: "a", Scraped: false}})
+	if _, ok := tr.InstanceLoad("a"); ok {
+		t.Error("failed scrape should leave no reading")
+	}
+	if _, ok := tr.InstanceLoad("b"); ok {
+		t.Error("instance missing from the scrape should be dropped")
+	}
+}
+
+func TestSetAllocationPolicyInjectsLoadsAndWeights(t *testing.T) {
+	o := NewOrchestrator(t.TempDir())
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{
+		AllocationPolicy:  "weighted",
+		AllocationWeights: config.AllocationWeights{Memory: 3},
+	})
+	p, ok := o.InstanceManager().Allocator.Policy().(*allocation.LeastLoaded)
+	if !ok {
+		t.Fatalf("policy = %T, want *allocation.LeastLoaded", o.InstanceManager().Allocator.Policy())
+	}
+	if p.Name() != "weighted" || p.Weights() != (allocation.Weights{Memory: 3}) {
+		t.Fatalf("policy %s weights = %+v", p.Name(), p.Weights())
+	}
+	if !o.loadAwarePolicy() {
+		t.Error("weighted policy should be load-aware")
+	}
+
+	if err := o.SetAllocationPolicy("fcfs"); err != nil {
+		t.Fatal(err)
+	}
+	if o.loadAwarePolicy() {
+		t.Error("fcfs should not be load-aware")
+	}
+}
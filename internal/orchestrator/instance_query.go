@@ -98,7 +98,7 @@ func (o *Orchestrator) firstRunningURL(match func(*InstanceInternal) bool) strin
 	var candidates []candidate
 	for _, inst := range o.instances {
 		if inst.Status == "running" && instanceIsActive(inst) {
-			if inst.URL == "" {
+			if inst.URL == "" || o.bindings.Draining(inst.ID) {
 				continue
 			}
 			if match != nil && !match(inst) {
@@ -119,7 +119,14 @@ func (o *Orchestrator) firstRunningURL(match func(*InstanceInternal) bool) strin
 	return candidates[0].url
 }
 
+// FirstRunningURLForRequest picks the running instance for a shorthand
+// request with the allocation policy, among the instances that serve the
+// requested browser and meet the request's requirements.
 func (o *Orchestrator) FirstRunningURLForRequest(r *http.Request) (string, int, error) {
+	req, err := ExtractInstanceRequirements(r)
+	if err != nil {
+		return "", http.StatusBadRequest, err
+	}
 	requested := ExtractRequestedBrowser(r)
 	if requested == "" {
 		resolved, err := config.ResolveDefaultBrowserTarget(o.runtimeCfg)
@@ -130,9 +137,9 @@ func (o *Orchestrator) FirstRunningURLForRequest(r *http.Request) (string, int,
 			if resolved.Provider == "" {
 				return "", http.StatusBadRequest, fmt.Errorf("no default browser target configured and none requested")
 			}
-			return o.FirstRunningURLForBrowser(resolved.Provider), 0, nil
+			req.Browser = config.NormalizeBrowser(resolved.Provider)
 		}
-		return o.FirstRunningURL(), 0, nil
+		return o.allocateURL(req), 0, nil
 	}
 
 	if _, err := config.ParseBrowser(requested, nil); err != nil {
@@ -140,19 +147,20 @@ func (o *Orchestrator) FirstRunningURLForRequest(r *http.Request) (string, int,
 	}
 
 	normalized := config.NormalizeBrowser(requested)
+	req.Browser = normalized
 	if o.runtimeCfg != nil && len(o.runtimeCfg.Targets) > 0 {
 		matches := config.TargetsForBrowser(o.runtimeCfg, requested)
 		if len(matches) == 0 {
 			return "", http.StatusBadRequest, fmt.Errorf("no browser target configured for browser %q", requested)
 		}
-		u := o.FirstRunningURLForBrowser(normalized)
+		u := o.allocateURL(req)
 		if u == "" {
 			return "", http.StatusConflict, fmt.Errorf("no running instance for browser %q", requested)
 		}
 		return u, 0, nil
 	}
 
-	if u := o.FirstRunningURLForBrowser(normalized); u != "" {
+	if u := o.allocateURL(req); u != "" {
 		return u, 0, nil
 	}
 	// The requested browser has no running instance. If a single instance with a
@@ -232,6 +232,7 @@ func (o *Orchestrator) Shutdown() {
 		}(id)
 	}
 	wg.Wait()
+	o.proxyPoolManager().Close()
 }
 
 func (o *Orchestrator) ForceShutdown() {
@@ -0,0 +1,95 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+// instanceScrapeTimeout bounds how long one slow instance can hold up a
+// metrics scrape of the server.
+const instanceScrapeTimeout = 5 * time.Second
+
+// InstanceMetricFamilies scrapes every running instance's exposition and
+// relabels it with instance_id, profile_id and profile_name so series from
+// different browsers stay apart. pinchtab_instance_up reports whether each
+// scrape succeeded.
+func (o *Orchestrator) InstanceMetricFamilies(ctx context.Context) []metrics.Family {
+	o.mu.RLock()
+	instances := make([]*InstanceInternal, 0, len(o.instances))
+	for _, inst := range o.instances {
+		if inst.Status == "running" && instanceIsActive(inst) {
+			instances = append(instances, inst)
+		}
+	}
+	o.mu.RUnlock()
+
+	up := metrics.Family{Name: "pinchtab_instance_up", Help: "Whether the last metrics scrape of the instance succeeded.", Type: metrics.Gauge}
+	scraped := make([][]metrics.Family, len(instances))
+	ok := make([]bool, len(instances))
+
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			families, err := o.fetchExposition(ctx, inst)
+			if err != nil {
+				return
+			}
+			scraped[i] = metrics.WithLabels(families, instanceLabels(inst)...)
+			ok[i] = true
+		}()
+	}
+	wg.Wait()
+
+	out := []metrics.Family{up}
+	for i, inst := range instances {
+		value := 0.0
+		if ok[i] {
+			value = 1
+			out = append(out, scraped[i]...)
+		}
+		out[0].Samples = append(out[0].Samples, metrics.Sample{Labels: instanceLabels(inst), Value: value})
+	}
+	return out
+}
+
+func instanceLabels(inst *InstanceInternal) []metrics.Label {
+	return []metrics.Label{
+		{Name: "instance_id", Value: inst.ID},
+		{Name: "profile_id", Value: inst.ProfileID},
+		{Name: "profile_name", Value: inst.ProfileName},
+	}
+}
+
+func (o *Orchestrator) fetchExposition(ctx context.Context, inst *InstanceInternal) ([]metrics.Family, error) {
+	target, err := o.instancePathURL(inst, "/metrics/prometheus", "")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, instanceScrapeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", metrics.FormatOpenMetrics.ContentType())
+	tagOrchestratorMonitoringRequest(req)
+	o.applyInstanceAuth(req, inst)
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch metrics: status %d", resp.StatusCode)
+	}
+	return metrics.Parse(resp.Body)
+}
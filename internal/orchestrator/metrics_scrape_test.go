@@ -0,0 +1,64 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+func TestInstanceMetricFamiliesRelabelsAndReportsUp(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+
+	var accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics/prometheus" {
+			w.WriteHeader(404)
+			return
+		}
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", metrics.FormatOpenMetrics.ContentType())
+		_, _ = w.Write([]byte("# TYPE pinchtab_browser_tabs gauge\npinchtab_browser_tabs 3\n# EOF\n"))
+	}))
+	t.Cleanup(srv.Close)
+	o.client = srv.Client()
+
+	o.instances["inst_a"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_a", ProfileID: "prof_a", ProfileName: "work", Status: "running", URL: srv.URL},
+		URL:      srv.URL,
+		cmd:      &mockCmd{pid: 1, isAlive: true},
+	}
+	o.instances["inst_b"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_b", ProfileID: "prof_b", ProfileName: "down", Status: "running", URL: "http://127.0.0.1:1"},
+		URL:      "http://127.0.0.1:1",
+		cmd:      &mockCmd{pid: 2, isAlive: true},
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.Write(&buf, metrics.Merge(o.InstanceMetricFamilies(context.Background())), metrics.FormatOpenMetrics); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(accept, "application/openmetrics-text") {
+		t.Fatalf("instance scrape Accept = %q", accept)
+	}
+	for _, want := range []string{
+		`pinchtab_browser_tabs{instance_id="inst_a",profile_id="prof_a",profile_name="work"} 3`,
+		`pinchtab_instance_up{instance_id="inst_a",profile_id="prof_a",profile_name="work"} 1`,
+		`pinchtab_instance_up{instance_id="inst_b",profile_id="prof_b",profile_name="down"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `instance_id="inst_b",profile_id="prof_b",profile_name="down"} 3`) {
+		t.Errorf("unreachable instance should not report browser metrics:\n%s", out)
+	}
+}
@@ -16,12 +16,16 @@ import (
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/ids"
 	"github.com/pinchtab/pinchtab/internal/instance"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 	"github.com/pinchtab/pinchtab/internal/profiles"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 )
 
 type InstanceEvent struct {
 	Type     string           `json:"type"` // "instance.started", "instance.stopped", "instance.error"
 	Instance *bridge.Instance `json:"instance"`
+	// Reason explains strategy decisions such as "autoscale.scale_up".
+	Reason string `json:"reason,omitempty"`
 }
 
 type EventHandler func(InstanceEvent)
@@ -60,6 +64,18 @@ type Orchestrator struct {
 	runtimeCfg       *config.RuntimeConfig
 	fallbackLauncher Launcher
 
+	// loads feeds load-aware allocation policies; refreshed by
+	// RunMaintenance.
+	loads *loadTracker
+
+	// drainTasks lets drains wait for scheduler tasks on the tabs.
+	drainTasks DrainTaskSource
+
+	// proxyPools hands out browser.proxyPools proxies to launched
+	// instances; built on first use.
+	proxyPoolsOnce sync.Once
+	proxyPools     *proxypool.Manager
+
 	// attachHealthCheckTimeout overrides the default health-check timeout in tests.
 	attachHealthCheckTimeout time.Duration
 }
@@ -71,11 +87,14 @@ func (o *Orchestrator) OnEvent(handler EventHandler) {
 }
 
 func (o *Orchestrator) emitEvent(eventType string, inst *bridge.Instance) {
+	o.dispatchEvent(InstanceEvent{Type: eventType, Instance: inst})
+}
+
+func (o *Orchestrator) dispatchEvent(evt InstanceEvent) {
 	o.mu.RLock()
 	handlers := make([]EventHandler, len(o.eventHandlers))
 	copy(handlers, o.eventHandlers)
 	o.mu.RUnlock()
-	evt := InstanceEvent{Type: eventType, Instance: inst}
 	for _, handler := range handlers {
 		handler(evt)
 	}
@@ -85,6 +104,12 @@ func (o *Orchestrator) EmitEvent(eventType string, inst *bridge.Instance) {
 	o.emitEvent(eventType, inst)
 }
 
+// EmitEventWithReason emits an event carrying the reason for a strategy
+// decision.
+func (o *Orchestrator) EmitEventWithReason(eventType string, inst *bridge.Instance, reason string) {
+	o.dispatchEvent(InstanceEvent{Type: eventType, Instance: inst, Reason: reason})
+}
+
 type InstanceInternal struct {
 	bridge.Instance
 	URL   string
@@ -102,6 +127,10 @@ type InstanceInternal struct {
 	effectiveBinary   string
 
 	lastFailureReason LaunchFailureReason
+
+	// proxyPool/proxyKey are replayed when the instance is started again.
+	proxyPool string
+	proxyKey  string
 }
 
 type LaunchOptions struct {
@@ -115,6 +144,11 @@ type LaunchOptions struct {
 	// re-deriving a target from Browser — with several targets sharing a
 	// provider, re-derivation picks the wrong one.
 	TargetName string
+	// ProxyPool launches the instance behind a proxy from that pool instead
+	// of browser.proxy. ProxyKey pins the choice under the sticky strategy
+	// and defaults to the profile name.
+	ProxyPool string
+	ProxyKey  string
 }
 
 type AttachOptions struct {
@@ -166,6 +200,7 @@ func NewOrchestratorWithRunner(baseDir string, runner HostRunner) *Orchestrator
 		tabsCache:      NewTabsCache(0, nil),
 		portAllocator:  NewPortAllocator(9868, 9968),
 		idMgr:          ids.NewManager(),
+		loads:          newLoadTracker(),
 	}
 
 	orch.registerInstanceCleanupHook()
@@ -219,6 +254,7 @@ func (o *Orchestrator) registerInstanceCleanupHook() {
 			if evt.Instance != nil {
 				o.bindings.ClearInstance(evt.Instance.ID)
 				o.tabsCache.Invalidate(evt.Instance.ID)
+				o.loads.forget(evt.Instance.ID)
 			}
 		}
 	})
@@ -243,12 +279,20 @@ func (o *Orchestrator) RunMaintenance(ctx context.Context) {
 	)
 	t := time.NewTicker(tick)
 	defer t.Stop()
+	loadTicker := time.NewTicker(loadRefreshInterval)
+	defer loadTicker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-t.C:
 			o.bindings.PruneAgents(idleTTL, maxAgent)
+		case <-loadTicker.C:
+			if o.loadAwarePolicy() {
+				refreshCtx, cancel := context.WithTimeout(ctx, loadRefreshInterval)
+				o.RefreshLoads(refreshCtx)
+				cancel()
+			}
 		}
 	}
 }
@@ -273,8 +317,32 @@ func (o *Orchestrator) InstanceManager() *instance.Manager {
 	return o.instanceMgr
 }
 
+// SetAllocationPolicy swaps the allocation policy. The weighted policy takes
+// its weights from the runtime config; load-aware policies read the loads
+// RunMaintenance refreshes.
 func (o *Orchestrator) SetAllocationPolicy(name string) error {
-	return o.instanceMgr.SetAllocationPolicy(name)
+	if err := o.instanceMgr.SetAllocationPolicy(name); err != nil {
+		return err
+	}
+	policy := o.instanceMgr.Allocator.Policy()
+	if name == "weighted" && o.runtimeCfg != nil {
+		policy = allocation.NewWeighted(allocationWeights(o.runtimeCfg.AllocationWeights))
+		o.instanceMgr.Allocator.SetPolicy(policy)
+	}
+	if aware, ok := policy.(allocation.LoadAware); ok {
+		aware.SetLoadSource(o.loads)
+	}
+	return nil
+}
+
+func allocationWeights(w config.AllocationWeights) allocation.Weights {
+	return allocation.Weights{
+		Tabs:     w.Tabs,
+		Queue:    w.Queue,
+		InFlight: w.InFlight,
+		Latency:  w.Latency,
+		Memory:   w.Memory,
+	}
 }
 
 type orchestratorLauncher struct {
@@ -349,10 +417,27 @@ func (o *Orchestrator) AllowsNetworkIntercept() bool {
 	return o != nil && o.runtimeCfg != nil && o.runtimeCfg.AllowNetworkIntercept
 }
 
+func (o *Orchestrator) AllowsWebAuthn() bool {
+	return o != nil && o.runtimeCfg != nil && o.runtimeCfg.AllowWebAuthn
+}
+
 func (o *Orchestrator) SetPortRange(start, end int) {
 	o.portAllocator = NewPortAllocator(start, end)
 }
 
+// instancePortsPerLaunch is what a launched instance takes from the port
+// range: its HTTP port and Chrome's debug port.
+const instancePortsPerLaunch = 2
+
+// LaunchCapacity returns how many more instances the port range
+// (instancePortStart..instancePortEnd) has room for.
+func (o *Orchestrator) LaunchCapacity() int {
+	if o == nil || o.portAllocator == nil {
+		return 0
+	}
+	return o.portAllocator.FreeCount() / instancePortsPerLaunch
+}
+
 func installStableBinary(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -369,3 +454,15 @@ func installStableBinary(src, dst string) error {
 	_, err = io.Copy(out, in)
 	return err
 }
+
+// proxyPoolManager returns the pool manager for instance launches.
+func (o *Orchestrator) proxyPoolManager() *proxypool.Manager {
+	o.proxyPoolsOnce.Do(func() {
+		var pools config.ProxyPoolsConfig
+		if o.runtimeCfg != nil {
+			pools = o.runtimeCfg.ProxyPools
+		}
+		o.proxyPools = proxypool.NewManager(pools)
+	})
+	return o.proxyPools
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/bridge"
 	"github.com/pinchtab/pinchtab/internal/browsers/providerhooks"
 	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/proxypool"
 )
 
 func envMap(items []string) map[string]string {
@@ -1365,3 +1366,50 @@ func TestFirstRunningURLForBrowser_SkipsBrowserlessInstances(t *testing.T) {
 		t.Fatal("non-specific lookup should still reach legacy instances")
 	}
 }
+
+func TestOrchestrator_LaunchWithOptions_ProxyPool(t *testing.T) {
+	old := processAliveFunc
+	processAliveFunc = func(pid int) bool { return pid > 0 }
+	defer func() { processAliveFunc = old }()
+	stubPortAvailability(t, func(int) bool { return true })
+
+	runner := &mockRunner{portAvail: true}
+	o := NewOrchestratorWithRunner(t.TempDir(), runner)
+	o.ApplyRuntimeConfig(&config.RuntimeConfig{
+		Proxy: config.BrowserProxyConfig{Server: "http://default.example:8080"},
+		ProxyPools: config.ProxyPoolsConfig{"residential": {
+			Strategy: config.ProxyPoolSticky,
+			Proxies: []config.BrowserProxyConfig{
+				{Server: "http://a.example:8080", Username: "u", Password: "p"},
+				{Server: "http://b.example:8080"},
+			},
+		}},
+	})
+
+	inst, err := o.LaunchWithOptions("pooled", "9060", true, LaunchOptions{ProxyPool: "residential"})
+	if err != nil {
+		t.Fatalf("Launch failed: %v", err)
+	}
+	if inst.ProxyPool != "residential" || inst.Proxy != "http://a.example:8080" {
+		t.Fatalf("instance proxy = %q/%q", inst.ProxyPool, inst.Proxy)
+	}
+
+	data, err := os.ReadFile(envMap(runner.env)["PINCHTAB_CONFIG"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fc config.FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Browser.Proxy.Server != "http://a.example:8080" || fc.Browser.Proxy.Username != "u" {
+		t.Fatalf("child proxy = %+v", fc.Browser.Proxy)
+	}
+	if o.runtimeCfg.Proxy.Server != "http://default.example:8080" {
+		t.Fatalf("shared runtime config was modified: %+v", o.runtimeCfg.Proxy)
+	}
+
+	if _, err := o.LaunchWithOptions("other", "9062", true, LaunchOptions{ProxyPool: "dc"}); !errors.Is(err, proxypool.ErrUnknownPool) {
+		t.Fatalf("unknown pool err = %v", err)
+	}
+}
@@ -112,6 +112,21 @@ func (pa *PortAllocator) AllocatedPorts() []int {
 	return ports
 }
 
+// FreeCount returns how many ports of the range are not reserved. Ports held
+// by other processes are only discovered by AllocatePort.
+func (pa *PortAllocator) FreeCount() int {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+
+	free := pa.end - pa.start + 1
+	for port := range pa.allocated {
+		if port >= pa.start && port <= pa.end {
+			free--
+		}
+	}
+	return free
+}
+
 func isPortAvailableInt(port int) bool {
 	addr := fmt.Sprintf("127.0.0.1:%d", port)
 	listener, err := net.Listen("tcp", addr)
@@ -12,6 +12,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/handlers"
 	"github.com/pinchtab/pinchtab/internal/httpx"
 	iproxy "github.com/pinchtab/pinchtab/internal/proxy"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 // proxyTabRequest is a generic handler that proxies requests to the instance
@@ -25,16 +26,25 @@ func (o *Orchestrator) proxyTabRequest(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, 400, fmt.Errorf("tab id required"))
 		return
 	}
+	r, tabID = o.followMigratedTab(w, r, tabID, TabIDSourcePath)
 
 	// Enrich activity with action/navigate details from the request body
 	// before proxying, so the dashboard stream shows meaningful labels.
 	activity.EnrichRouteActivity(r)
 
+	_, span := tracing.Start(r.Context(), "orchestrator.resolve_tab", tracing.String("pinchtab.tab_id", tabID))
 	inst, err := o.resolveInstanceForTab(tabID)
 	if err != nil {
+		span.RecordError(err)
+		span.End()
 		httpx.Error(w, 404, err)
 		return
 	}
+	span.SetAttributes(tracing.String("pinchtab.instance_id", inst.ID))
+	span.End()
+	if o.refuseMigratingTab(w, inst.ID, tabID) {
+		return
+	}
 	o.proxyResolvedTab(w, r, inst, tabID)
 }
 
@@ -125,6 +135,9 @@ func (o *Orchestrator) proxyToURL(w http.ResponseWriter, r *http.Request, target
 	// Resolve the target instance once; RewriteRequest/OnResponseHeaders always
 	// act on targetURL, so they reuse this instead of re-scanning o.instances.
 	targetInst := o.proxyTargetInstance(targetURL)
+	if !requestAllowsInstance(w, r, targetInst) {
+		return
+	}
 	iproxy.Forward(w, r, targetURL, iproxy.Options{
 		Client: o.client,
 		AllowedURL: func(u *url.URL) bool {
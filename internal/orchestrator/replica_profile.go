@@ -0,0 +1,43 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A profile runs on one instance at a time. Extra instances serving it —
+// autoscale replicas, drain targets — run on fresh temporary profiles
+// named after it.
+const (
+	replicaProfilePrefix = "instance-"
+	replicaProfileInfix  = "-scale-"
+)
+
+// ReplicaProfileName names a new temporary profile serving profile.
+func ReplicaProfileName(profile string) string {
+	return fmt.Sprintf("%s%s%s%x", replicaProfilePrefix, profile, replicaProfileInfix, time.Now().UnixNano())
+}
+
+// IsReplicaOf reports whether name is a temporary profile serving profile.
+func IsReplicaOf(name, profile string) bool {
+	return strings.HasPrefix(name, replicaProfilePrefix+profile+replicaProfileInfix)
+}
+
+// ProfileFamily returns the profile a replica profile serves, or name
+// itself when it is not a replica.
+func ProfileFamily(name string) string {
+	rest, ok := strings.CutPrefix(name, replicaProfilePrefix)
+	if !ok {
+		return name
+	}
+	i := strings.LastIndex(rest, replicaProfileInfix)
+	if i <= 0 {
+		return name
+	}
+	if _, err := strconv.ParseUint(rest[i+len(replicaProfileInfix):], 16, 64); err != nil {
+		return name
+	}
+	return rest[:i]
+}
@@ -14,8 +14,10 @@ import (
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/handlers"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/instance/allocation"
 	"github.com/pinchtab/pinchtab/internal/readiness"
 	"github.com/pinchtab/pinchtab/internal/session"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 // RoutingDecision identifies which precedence rule selected the target
@@ -41,6 +43,10 @@ func (o *Orchestrator) RouteForRequest(r *http.Request) (string, int, error) {
 		return "", http.StatusServiceUnavailable, fmt.Errorf("no orchestrator configured")
 	}
 
+	req, err := ExtractInstanceRequirements(r)
+	if err != nil {
+		return "", http.StatusBadRequest, err
+	}
 	requestedBrowser := ExtractRequestedBrowser(r)
 	if requestedBrowser != "" {
 		var available []string
@@ -50,10 +56,12 @@ func (o *Orchestrator) RouteForRequest(r *http.Request) (string, int, error) {
 		if _, err := config.ParseBrowser(requestedBrowser, available); err != nil {
 			return "", http.StatusBadRequest, fmt.Errorf("unknown browser %q: %w", requestedBrowser, err)
 		}
-		if url := o.FirstRunningURLForBrowser(requestedBrowser); url != "" {
+		browserReq := req
+		browserReq.Browser = config.NormalizeBrowser(requestedBrowser)
+		if url := o.allocateURL(browserReq); url != "" {
 			return url, 0, nil
 		}
-		return o.launchAndWaitForRequestRoute(autoLaunchProfileName(requestedBrowser), requestedBrowser)
+		return o.launchAndWaitForRequestRoute(autoLaunchProfileName(requestedBrowser), requestedBrowser, req)
 	}
 
 	target, status, err := o.FirstRunningURLForRequest(r)
@@ -64,12 +72,32 @@ func (o *Orchestrator) RouteForRequest(r *http.Request) (string, int, error) {
 		return target, 0, nil
 	}
 
-	return o.launchAndWaitForRequestRoute("default", "")
+	return o.launchAndWaitForRequestRoute("default", "", req)
 }
 
-func (o *Orchestrator) launchAndWaitForRequestRoute(profileName, requestedTarget string) (string, int, error) {
+// launchAndWaitForRequestRoute launches an instance meeting req: headed when
+// asked for, behind the region's proxy pool when one is named. Such launches
+// get their own profile so they never collide with the plain one.
+func (o *Orchestrator) launchAndWaitForRequestRoute(profileName, requestedTarget string, req allocation.Requirements) (string, int, error) {
+	// A caller limited to some instances never gets a fresh one.
+	if req.Allowed != nil {
+		return "", http.StatusForbidden, fmt.Errorf("no running instance the API token may use")
+	}
+	opts := LaunchOptions{}
+	if req.ProxyRegion != "" {
+		pool, err := o.proxyPoolForRegion(req.ProxyRegion)
+		if err != nil {
+			return "", http.StatusBadRequest, err
+		}
+		opts.ProxyPool = pool
+		profileName += "-" + pool
+	}
+	headless := req.Mode != "headed"
+	if !headless {
+		profileName += "-headed"
+	}
 	slog.Info("request route: no running instance, auto-launching", "profile", profileName, "target", requestedTarget)
-	launched, err := o.LaunchWithTargetSelection(profileName, "", true, requestedTarget, nil, LaunchOptions{})
+	launched, err := o.LaunchWithTargetSelection(profileName, "", headless, requestedTarget, nil, opts)
 	if err != nil {
 		status := statusForRouteLaunchSelectionError(err)
 		return "", status, fmt.Errorf("auto-launch failed: %w", err)
@@ -157,7 +185,12 @@ func (o *Orchestrator) WrapShorthand(fallback http.HandlerFunc) http.HandlerFunc
 		return fallback
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		if tabID, _ := ExtractExplicitTabID(r); tabID != "" {
+		ctx, span := tracing.Start(r.Context(), "orchestrator.route")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		if tabID, src := ExtractExplicitTabID(r); tabID != "" {
+			r, tabID = o.followMigratedTab(w, r, tabID, src)
 			if o.routeByTabOwner(w, r, tabID) {
 				return
 			}
@@ -172,6 +205,7 @@ func (o *Orchestrator) WrapShorthand(fallback http.HandlerFunc) http.HandlerFunc
 			}
 		}
 
+		span.SetAttributes(tracing.String("pinchtab.routing.decision", "strategy"))
 		fallback(w, r)
 	}
 }
@@ -187,7 +221,7 @@ func (o *Orchestrator) routeByTabOwner(w http.ResponseWriter, r *http.Request, t
 			if writeBrowserConflict(w, tabID, inst, requestedBrowser) {
 				return true
 			}
-			if !o.allowCrossInstance(w, r, inst.ID) {
+			if !o.allowCrossInstance(w, r, inst.ID) || o.refuseMigratingTab(w, inst.ID, tabID) {
 				return true
 			}
 			o.proxyToInstanceForRoute(w, r, inst, tabID, RoutingDecisionTabOwner)
@@ -202,7 +236,7 @@ func (o *Orchestrator) routeByTabOwner(w http.ResponseWriter, r *http.Request, t
 		if writeBrowserConflict(w, tabID, &internal.Instance, requestedBrowser) {
 			return true
 		}
-		if !o.allowCrossInstance(w, r, internal.ID) {
+		if !o.allowCrossInstance(w, r, internal.ID) || o.refuseMigratingTab(w, internal.ID, tabID) {
 			return true
 		}
 		o.proxyToInstanceForRoute(w, r, &internal.Instance, tabID, RoutingDecisionTabOwner)
@@ -213,7 +247,7 @@ func (o *Orchestrator) routeByTabOwner(w http.ResponseWriter, r *http.Request, t
 	// — preserves legacy ergonomics for users running `--tab` against a
 	// just-created tab whose id has not propagated to the dashboard yet.
 	if only := o.singleRunningInstance(); only != nil {
-		if writeBrowserConflict(w, tabID, &only.Instance, requestedBrowser) {
+		if writeBrowserConflict(w, tabID, &only.Instance, requestedBrowser) || o.refuseMigratingTab(w, only.ID, tabID) {
 			return true
 		}
 		o.proxyToInstanceForRoute(w, r, &only.Instance, tabID, RoutingDecisionFallback)
@@ -351,6 +385,10 @@ func (o *Orchestrator) proxyToInstanceForRoute(w http.ResponseWriter, r *http.Re
 		httpx.Error(w, http.StatusInternalServerError, fmt.Errorf("nil instance for routing decision %q", decision))
 		return
 	}
+	tracing.SpanFromContext(r.Context()).SetAttributes(
+		tracing.String("pinchtab.routing.decision", string(decision)),
+		tracing.String("pinchtab.instance_id", inst.ID),
+	)
 	activity.EnrichRouteActivity(r)
 	update := activity.Update{
 		InstanceID:  inst.ID,
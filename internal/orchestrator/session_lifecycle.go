@@ -1,9 +1,22 @@
 package orchestrator
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/handlers"
 	"github.com/pinchtab/pinchtab/internal/session"
 )
 
+// sessionContextDisposeTimeout bounds how long one instance can take to drop
+// an isolated session's browser context.
+const sessionContextDisposeTimeout = 10 * time.Second
+
 // SessionLifecycleHook returns a session.LifecycleHook the caller can wire
 // into a session.Store via OnLifecycle. The hook drops the session →
 // instance binding so a future request that reuses the same session id
@@ -12,8 +25,9 @@ import (
 //
 // The instance-side scoped current-tab map is bounded (LRU cap + stale
 // detection on the bridge), so we deliberately do NOT propagate eviction
-// over the network. Dead entries on the instance get pushed out by normal
-// traffic; this avoids a separate trusted internal HTTP surface.
+// over the network. Isolated sessions are the exception: their browser
+// contexts hold cookies and storage, so every running instance is told to
+// dispose of the session's context.
 func (o *Orchestrator) SessionLifecycleHook() session.LifecycleHook {
 	if o == nil {
 		return func(session.LifecycleEvent) {}
@@ -23,5 +37,59 @@ func (o *Orchestrator) SessionLifecycleHook() session.LifecycleHook {
 			return
 		}
 		o.bindings.ClearSession(evt.SessionID)
+		if evt.Isolated {
+			o.disposeSessionContexts(context.Background(), evt.SessionID)
+		}
+	}
+}
+
+// disposeSessionContexts asks every running instance to dispose of the
+// browser context of sessionID. Failures are logged: an instance that is
+// gone took the context with it.
+func (o *Orchestrator) disposeSessionContexts(ctx context.Context, sessionID string) {
+	o.mu.RLock()
+	instances := make([]*InstanceInternal, 0, len(o.instances))
+	for _, inst := range o.instances {
+		if inst.Status == "running" && instanceIsActive(inst) {
+			instances = append(instances, inst)
+		}
+	}
+	o.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := o.disposeSessionContext(ctx, inst, sessionID); err != nil {
+				slog.Warn("dispose session context failed", "instance", inst.ID, "session", sessionID, "err", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (o *Orchestrator) disposeSessionContext(ctx context.Context, inst *InstanceInternal, sessionID string) error {
+	target, err := o.instancePathURL(inst, "/session/context", "")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sessionContextDisposeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, target.String(), nil)
+	if err != nil {
+		return err
+	}
+	o.applyInstanceAuth(req, inst)
+	req.Header.Set(activity.HeaderPTSessionID, sessionID)
+	req.Header.Set(handlers.SessionIsolatedHeader, "1")
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("dispose session context: status %d", resp.StatusCode)
 	}
+	return nil
 }
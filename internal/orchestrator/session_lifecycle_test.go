@@ -1,8 +1,14 @@
 package orchestrator
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
+	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/handlers"
 	"github.com/pinchtab/pinchtab/internal/session"
 )
 
@@ -27,3 +33,38 @@ func TestSessionLifecycleHook_NoopOnEmptyID(t *testing.T) {
 		t.Fatal("unrelated binding should not be touched")
 	}
 }
+
+func TestSessionLifecycleHook_DisposesIsolatedContexts(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Method+" "+r.URL.Path+" "+r.Header.Get(activity.HeaderPTSessionID)+" "+r.Header.Get(handlers.SessionIsolatedHeader))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"tabsClosed":1}`))
+	}))
+	t.Cleanup(srv.Close)
+	o.client = srv.Client()
+	o.instances["inst_a"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_a", Status: "running", URL: srv.URL},
+		URL:      srv.URL,
+		cmd:      &mockCmd{pid: 1, isAlive: true},
+	}
+	o.instances["inst_b"] = &InstanceInternal{
+		Instance: bridge.Instance{ID: "inst_b", Status: "stopped", URL: srv.URL},
+		URL:      srv.URL,
+	}
+
+	hook := o.SessionLifecycleHook()
+	hook(session.LifecycleEvent{SessionID: "ses_plain", Reason: session.LifecycleReasonRevoked})
+	hook(session.LifecycleEvent{SessionID: "ses_iso", Isolated: true, Reason: session.LifecycleReasonExpired})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != "DELETE /session/context ses_iso 1" {
+		t.Fatalf("instance requests = %q", got)
+	}
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// instanceRestrictedToken returns the request's API token when it is
+// limited to particular instances or profiles.
+func instanceRestrictedToken(r *http.Request) (*apitoken.Token, bool) {
+	tok, ok := apitoken.FromRequest(r)
+	if !ok || !tok.Scope.InstanceRestricted() {
+		return nil, false
+	}
+	return tok, true
+}
+
+// tokenAllowsInstance matches inst against the token's instances and
+// profiles. Replicas count as their base profile.
+func tokenAllowsInstance(tok *apitoken.Token, inst bridge.Instance) bool {
+	return tok.AllowsInstance(inst.ID, inst.ProfileID, inst.ProfileName, ProfileFamily(inst.ProfileName))
+}
+
+func writeTokenInstanceForbidden(w http.ResponseWriter, tok *apitoken.Token) {
+	httpx.ErrorCode(w, http.StatusForbidden, "token_instance_forbidden", "API token is not allowed to use this instance", false, map[string]any{
+		"tokenId": tok.ID,
+	})
+}
+
+// requestAllowsInstance writes 403 and reports false when the request's API
+// token may not use inst. A nil inst is allowed only for unrestricted
+// requests.
+func requestAllowsInstance(w http.ResponseWriter, r *http.Request, inst *InstanceInternal) bool {
+	tok, ok := instanceRestrictedToken(r)
+	if !ok || (inst != nil && tokenAllowsInstance(tok, inst.Instance)) {
+		return true
+	}
+	writeTokenInstanceForbidden(w, tok)
+	return false
+}
+
+// tokenInstanceScoped guards an /instances/{id}/... route.
+func (o *Orchestrator) tokenInstanceScoped(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := instanceRestrictedToken(r); ok {
+			o.mu.RLock()
+			inst := o.instances[r.PathValue("id")]
+			o.mu.RUnlock()
+			if !requestAllowsInstance(w, r, inst) {
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// tokenProfileScoped guards a /profiles/{id}/... route; {id} is a profile
+// id or name.
+func (o *Orchestrator) tokenProfileScoped(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if tok, ok := instanceRestrictedToken(r); ok && !o.tokenAllowsProfile(tok, r.PathValue("id")) {
+			writeTokenInstanceForbidden(w, tok)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (o *Orchestrator) tokenAllowsProfile(tok *apitoken.Token, id string) bool {
+	if tok.AllowsProfile(id) {
+		return true
+	}
+	name, err := o.resolveProfileName(id)
+	return err == nil && tok.AllowsProfile(name)
+}
+
+// tokenVisibleInstance reports whether the request may see instance id in
+// listings.
+func (o *Orchestrator) tokenVisibleInstance(r *http.Request, id string) bool {
+	tok, ok := instanceRestrictedToken(r)
+	if !ok {
+		return true
+	}
+	o.mu.RLock()
+	inst := o.instances[id]
+	o.mu.RUnlock()
+	return inst != nil && tokenAllowsInstance(tok, inst.Instance)
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/apitoken"
+	"github.com/pinchtab/pinchtab/internal/bridge"
+)
+
+func restrictedTokenRequest(t *testing.T, method, target string, scope apitoken.Scope) *http.Request {
+	t.Helper()
+	store := apitoken.NewStore("")
+	if len(scope.Families) == 0 {
+		scope.Families = []string{"*"}
+	}
+	tok, _, err := store.Create("agent", scope, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return apitoken.WithToken(httptest.NewRequest(method, target, nil), tok)
+}
+
+func TestTokenRestrictedInstanceRoutes(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	_, gotPath := newBackendInstance(t, o, "inst_work")
+	o.instances["inst_work"].ProfileName = "work"
+	addRunningInstance(o, bridge.Instance{ID: "inst_other", ProfileName: "other", URL: "http://other.local", Status: "running"})
+	addRunningInstance(o, bridge.Instance{ID: "inst_replica", ProfileName: ReplicaProfileName("work"), URL: "http://replica.local", Status: "running"})
+
+	mux := http.NewServeMux()
+	o.RegisterHandlers(mux)
+	scope := apitoken.Scope{Profiles: []string{"work"}}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, restrictedTokenRequest(t, http.MethodGet, "/instances", scope))
+	var list []bridge.Instance
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("listed %d instances, want the work instance and its replica: %+v", len(list), list)
+	}
+	for _, inst := range list {
+		if inst.ID == "inst_other" {
+			t.Fatalf("listed an instance outside the token's profiles")
+		}
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, restrictedTokenRequest(t, http.MethodPost, "/instances/inst_other/stop", scope))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("stop other: status = %d, want 403", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, restrictedTokenRequest(t, http.MethodPost, "/instances/inst_work/tab", scope))
+	if w.Code != http.StatusOK || *gotPath != "/tab" {
+		t.Fatalf("proxy to allowed instance: status = %d, path = %q", w.Code, *gotPath)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, restrictedTokenRequest(t, http.MethodPost, "/instances/start", scope))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("start without a profile: status = %d, want 403", w.Code)
+	}
+}
+
+func TestTokenRestrictedAllocation(t *testing.T) {
+	alwaysAlive(t)
+	o := NewOrchestrator(t.TempDir())
+	now := time.Now()
+	addRunningInstance(o, bridge.Instance{ID: "inst_a", URL: "http://a.local", Status: "running", StartTime: now})
+	addRunningInstance(o, bridge.Instance{ID: "inst_b", URL: "http://b.local", Status: "running", StartTime: now.Add(time.Second)})
+
+	r := restrictedTokenRequest(t, http.MethodGet, "/snapshot", apitoken.Scope{Instances: []string{"inst_b"}})
+	req, err := ExtractInstanceRequirements(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.allocateURL(req); got != "http://b.local" {
+		t.Fatalf("allocateURL = %q, want the token's instance", got)
+	}
+
+	r = restrictedTokenRequest(t, http.MethodGet, "/snapshot", apitoken.Scope{Instances: []string{"inst_gone"}})
+	if _, status, err := o.RouteForRequest(r); err == nil || status != http.StatusForbidden {
+		t.Fatalf("RouteForRequest = %d, %v; want 403 instead of an auto-launch", status, err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/activity"
 	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 // DefaultClient is the shared HTTP client for proxy requests.
@@ -73,6 +74,16 @@ func Forward(w http.ResponseWriter, r *http.Request, targetURL *url.URL, opts Op
 		opts.RewriteRequest(proxyReq)
 	}
 
+	// The upstream hop is a client span of the incoming request's span; its
+	// traceparent replaces whatever the caller sent so the child's server
+	// span nests under this hop.
+	ctx, span := tracing.StartClient(r.Context(), "proxy "+r.Method,
+		tracing.String("server.address", targetURL.Host),
+		tracing.String("url.path", targetURL.Path),
+	)
+	defer span.End()
+	tracing.Inject(ctx, proxyReq.Header)
+
 	if isWebSocketUpgrade(proxyReq) {
 		ProxyWebSocket(w, proxyReq, targetURL.String())
 		return
@@ -83,7 +94,7 @@ func Forward(w http.ResponseWriter, r *http.Request, targetURL *url.URL, opts Op
 		client = DefaultClient
 	}
 
-	outReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL.String(), r.Body)
+	outReq, err := http.NewRequestWithContext(ctx, r.Method, targetURL.String(), r.Body)
 	if err != nil {
 		httpx.Error(w, 502, fmt.Errorf("proxy error: %w", err))
 		return
@@ -92,10 +103,12 @@ func Forward(w http.ResponseWriter, r *http.Request, targetURL *url.URL, opts Op
 
 	resp, err := client.Do(outReq)
 	if err != nil {
+		span.RecordError(err)
 		httpx.Error(w, 502, fmt.Errorf("instance unreachable: %w", err))
 		return
 	}
 	defer func() { _ = resp.Body.Close() }()
+	span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
 
 	copyHeaders(w.Header(), resp.Header)
 
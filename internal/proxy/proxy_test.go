@@ -1,10 +1,13 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 func fakeBridge(t *testing.T) *httptest.Server {
@@ -162,3 +165,37 @@ func TestIsWebSocketUpgrade(t *testing.T) {
 		})
 	}
 }
+
+type discardSpans struct{}
+
+func (discardSpans) Export(context.Context, string, []tracing.SpanData) error { return nil }
+
+func TestHTTP_InjectsTraceparentForUpstreamHop(t *testing.T) {
+	tracer := tracing.New(tracing.Config{Exporter: discardSpans{}, SampleRatio: 1})
+	tracing.Install(tracer)
+	t.Cleanup(func() { _ = tracer.Shutdown(context.Background()) })
+
+	var upstream string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r.Header.Get(tracing.TraceparentHeader)
+	}))
+	defer srv.Close()
+
+	const incoming = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest("GET", "/snapshot", nil)
+	req.Header.Set(tracing.TraceparentHeader, incoming)
+	tracing.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HTTP(w, r, srv.URL+"/snapshot")
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	sc, err := tracing.ParseTraceparent(upstream)
+	if err != nil {
+		t.Fatalf("upstream traceparent %q: %v", upstream, err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("upstream trace id = %s", sc.TraceID)
+	}
+	if upstream == incoming {
+		t.Fatal("upstream should see the proxy hop's span, not the caller's")
+	}
+}
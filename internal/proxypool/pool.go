@@ -0,0 +1,382 @@
+// Package proxypool hands out proxies from the configured browser.proxyPools
+// and keeps track of which of them are usable.
+//
+// A proxy is taken out of rotation for the pool's cooldown when a tab reports
+// a proxy failure (MarkBad) or, with healthCheck.url set, when the periodic
+// check fails. Sticky keys pinned to a bad proxy are moved to another one on
+// their next Acquire, which is how sessions rotate.
+package proxypool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+var (
+	ErrUnknownPool    = errors.New("unknown proxy pool")
+	ErrNoHealthyProxy = errors.New("no healthy proxy available")
+)
+
+const (
+	defaultCooldown       = 5 * time.Minute
+	defaultHealthInterval = time.Minute
+	defaultHealthTimeout  = 10 * time.Second
+)
+
+// ProxyStatus is the redacted, point-in-time state of one pool member.
+type ProxyStatus struct {
+	Server      string     `json:"server"`
+	Username    string     `json:"username,omitempty"`
+	Healthy     bool       `json:"healthy"`
+	Failures    int        `json:"failures"`
+	LastError   string     `json:"lastError,omitempty"`
+	BadUntil    *time.Time `json:"badUntil,omitempty"`
+	LastChecked *time.Time `json:"lastChecked,omitempty"`
+	// Sticky is the number of sticky keys currently pinned to the proxy.
+	Sticky int `json:"sticky"`
+}
+
+// PoolStatus is the state of one pool.
+type PoolStatus struct {
+	Name        string        `json:"name"`
+	Strategy    string        `json:"strategy"`
+	HealthCheck bool          `json:"healthCheck"`
+	Proxies     []ProxyStatus `json:"proxies"`
+}
+
+type member struct {
+	proxy       config.BrowserProxyConfig
+	failures    int
+	badUntil    time.Time
+	lastError   string
+	lastChecked time.Time
+}
+
+// Pool selects proxies from one configured pool. It is safe for concurrent
+// use.
+type Pool struct {
+	name     string
+	strategy string
+	cooldown time.Duration
+	health   config.ProxyPoolHealthCheckConfig
+
+	mu      sync.Mutex
+	members []*member
+	next    int
+	sticky  map[string]int
+	rnd     *rand.Rand
+
+	now   func() time.Time
+	check func(ctx context.Context, p config.BrowserProxyConfig) error
+
+	checkOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+}
+
+// New builds a pool from its config. Health checks start with the first
+// Acquire, so pools nobody uses cost nothing.
+func New(name string, cfg config.ProxyPoolConfig) *Pool {
+	p := &Pool{
+		name:     name,
+		strategy: config.NormalizeProxyPoolStrategy(cfg.Strategy),
+		cooldown: defaultCooldown,
+		health:   cfg.HealthCheck,
+		sticky:   map[string]int{},
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+	if cfg.CooldownSec > 0 {
+		p.cooldown = time.Duration(cfg.CooldownSec) * time.Second
+	}
+	for _, px := range cfg.Proxies {
+		p.members = append(p.members, &member{proxy: px})
+	}
+	p.check = p.checkProxy
+	return p
+}
+
+// Name returns the pool name.
+func (p *Pool) Name() string { return p.name }
+
+// Acquire returns a usable proxy. key only matters for the sticky strategy:
+// the same key gets the same proxy until that proxy is marked bad. With every
+// proxy out of rotation it fails rather than letting traffic go direct.
+func (p *Pool) Acquire(key string) (config.BrowserProxyConfig, error) {
+	p.checkOnce.Do(p.startHealthChecks)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var healthy []int
+	for i, m := range p.members {
+		if !m.badUntil.After(now) {
+			healthy = append(healthy, i)
+		}
+	}
+	if len(healthy) == 0 {
+		return config.BrowserProxyConfig{}, fmt.Errorf("proxy pool %q: %w", p.name, ErrNoHealthyProxy)
+	}
+
+	if p.strategy == config.ProxyPoolSticky && key != "" {
+		if i, ok := p.sticky[key]; ok && !p.members[i].badUntil.After(now) {
+			return p.members[i].proxy, nil
+		}
+		i := p.pickRoundRobin(healthy)
+		p.sticky[key] = i
+		return p.members[i].proxy, nil
+	}
+	if p.strategy == config.ProxyPoolRandom {
+		return p.members[healthy[p.rnd.Intn(len(healthy))]].proxy, nil
+	}
+	return p.members[p.pickRoundRobin(healthy)].proxy, nil
+}
+
+// pickRoundRobin returns the first healthy index at or after p.next.
+func (p *Pool) pickRoundRobin(healthy []int) int {
+	pick := healthy[0]
+	for _, i := range healthy {
+		if i >= p.next {
+			pick = i
+			break
+		}
+	}
+	p.next = pick + 1
+	return pick
+}
+
+// MarkBad takes px out of rotation for the cooldown and unpins every sticky
+// key on it. Unknown proxies are ignored.
+func (p *Pool) MarkBad(px config.BrowserProxyConfig, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, m := range p.members {
+		if !sameProxy(m.proxy, px) {
+			continue
+		}
+		m.failures++
+		m.lastError = reason
+		m.badUntil = p.now().Add(p.cooldown)
+		for key, idx := range p.sticky {
+			if idx == i {
+				delete(p.sticky, key)
+			}
+		}
+		return
+	}
+}
+
+func (p *Pool) markGood(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := p.members[i]
+	m.badUntil = time.Time{}
+	m.lastError = ""
+}
+
+// Status reports every member, credentials left out.
+func (p *Pool) Status() PoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	st := PoolStatus{
+		Name:        p.name,
+		Strategy:    p.strategy,
+		HealthCheck: strings.TrimSpace(p.health.URL) != "",
+		Proxies:     make([]ProxyStatus, 0, len(p.members)),
+	}
+	pinned := map[int]int{}
+	for _, idx := range p.sticky {
+		pinned[idx]++
+	}
+	for i, m := range p.members {
+		ps := ProxyStatus{
+			Server:    m.proxy.Server,
+			Username:  m.proxy.Username,
+			Healthy:   !m.badUntil.After(now),
+			Failures:  m.failures,
+			LastError: m.lastError,
+			Sticky:    pinned[i],
+		}
+		if !ps.Healthy {
+			until := m.badUntil
+			ps.BadUntil = &until
+		}
+		if !m.lastChecked.IsZero() {
+			checked := m.lastChecked
+			ps.LastChecked = &checked
+		}
+		st.Proxies = append(st.Proxies, ps)
+	}
+	return st
+}
+
+// Close stops the health checks.
+func (p *Pool) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *Pool) startHealthChecks() {
+	if strings.TrimSpace(p.health.URL) == "" {
+		return
+	}
+	interval := defaultHealthInterval
+	if p.health.IntervalSec > 0 {
+		interval = time.Duration(p.health.IntervalSec) * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			p.runHealthChecks()
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runHealthChecks checks every member once.
+func (p *Pool) runHealthChecks() {
+	timeout := defaultHealthTimeout
+	if p.health.TimeoutSec > 0 {
+		timeout = time.Duration(p.health.TimeoutSec) * time.Second
+	}
+	p.mu.Lock()
+	proxies := make([]config.BrowserProxyConfig, len(p.members))
+	for i, m := range p.members {
+		proxies[i] = m.proxy
+	}
+	p.mu.Unlock()
+
+	for i, px := range proxies {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := p.check(ctx, px)
+		cancel()
+		p.mu.Lock()
+		p.members[i].lastChecked = p.now()
+		p.mu.Unlock()
+		if err != nil {
+			p.MarkBad(px, "health check: "+err.Error())
+		} else {
+			p.markGood(i)
+		}
+	}
+}
+
+// checkProxy fetches the health check URL through px. socks4 is not
+// supported by net/http and is treated as healthy.
+func (p *Pool) checkProxy(ctx context.Context, px config.BrowserProxyConfig) error {
+	u, err := url.Parse(strings.TrimSpace(px.Server))
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(u.Scheme, "socks4") {
+		return nil
+	}
+	if px.Username != "" {
+		u.User = url.UserPassword(px.Username, px.Password)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(u), DisableKeepAlives: true},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.health.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusProxyAuthRequired:
+		return errors.New("proxy authentication required (407)")
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sameProxy(a, b config.BrowserProxyConfig) bool {
+	return strings.EqualFold(strings.TrimSpace(a.Server), strings.TrimSpace(b.Server)) && a.Username == b.Username
+}
+
+// Manager holds one Pool per configured pool.
+type Manager struct {
+	pools map[string]*Pool
+}
+
+// NewManager builds the pools of cfg.
+func NewManager(cfg config.ProxyPoolsConfig) *Manager {
+	m := &Manager{pools: make(map[string]*Pool, len(cfg))}
+	for name, pc := range cfg {
+		m.pools[name] = New(name, pc)
+	}
+	return m
+}
+
+// Pool returns the named pool.
+func (m *Manager) Pool(name string) (*Pool, error) {
+	if m != nil {
+		if p, ok := m.pools[name]; ok {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownPool, name)
+}
+
+// Acquire picks a proxy from the named pool (see Pool.Acquire).
+func (m *Manager) Acquire(pool, key string) (config.BrowserProxyConfig, error) {
+	p, err := m.Pool(pool)
+	if err != nil {
+		return config.BrowserProxyConfig{}, err
+	}
+	return p.Acquire(key)
+}
+
+// MarkBad marks px bad in the named pool; unknown pools are ignored.
+func (m *Manager) MarkBad(pool string, px config.BrowserProxyConfig, reason string) {
+	if p, err := m.Pool(pool); err == nil {
+		p.MarkBad(px, reason)
+	}
+}
+
+// Status reports every pool, sorted by name.
+func (m *Manager) Status() []PoolStatus {
+	if m == nil {
+		return []PoolStatus{}
+	}
+	out := make([]PoolStatus, 0, len(m.pools))
+	for _, p := range m.pools {
+		out = append(out, p.Status())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Close stops the health checks of every pool.
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+	for _, p := range m.pools {
+		p.Close()
+	}
+}
@@ -0,0 +1,127 @@
+package proxypool
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+)
+
+func testPool(strategy string) *Pool {
+	return New("res", config.ProxyPoolConfig{
+		Strategy: strategy,
+		Proxies: []config.BrowserProxyConfig{
+			{Server: "http://a.example:8080"},
+			{Server: "http://b.example:8080"},
+			{Server: "http://c.example:8080"},
+		},
+	})
+}
+
+func acquire(t *testing.T, p *Pool, key string) string {
+	t.Helper()
+	px, err := p.Acquire(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return px.Server
+}
+
+func TestAcquireRoundRobinSkipsBad(t *testing.T) {
+	p := testPool("")
+	p.MarkBad(config.BrowserProxyConfig{Server: "http://b.example:8080"}, "407")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, acquire(t, p, ""))
+	}
+	want := []string{"http://a.example:8080", "http://c.example:8080", "http://a.example:8080", "http://c.example:8080"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("round robin = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAcquireStickyRotatesAfterMarkBad(t *testing.T) {
+	p := testPool(config.ProxyPoolSticky)
+	first := acquire(t, p, "session-1")
+	if again := acquire(t, p, "session-1"); again != first {
+		t.Fatalf("sticky key moved from %s to %s", first, again)
+	}
+	other := acquire(t, p, "session-2")
+	if other == first {
+		t.Fatalf("second key should spread to another proxy, got %s", other)
+	}
+
+	p.MarkBad(config.BrowserProxyConfig{Server: first}, "net::ERR_TUNNEL_CONNECTION_FAILED")
+	rotated := acquire(t, p, "session-1")
+	if rotated == first {
+		t.Fatalf("sticky key stayed on bad proxy %s", first)
+	}
+	if again := acquire(t, p, "session-2"); again != other {
+		t.Fatalf("unaffected key moved from %s to %s", other, again)
+	}
+
+	st := p.Status()
+	if st.Strategy != config.ProxyPoolSticky || st.Proxies[0].Failures+st.Proxies[1].Failures+st.Proxies[2].Failures != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestAcquireFailsClosedAndRecoversAfterCooldown(t *testing.T) {
+	p := testPool(config.ProxyPoolRandom)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	for _, m := range p.members {
+		p.MarkBad(m.proxy, "down")
+	}
+	if _, err := p.Acquire(""); !errors.Is(err, ErrNoHealthyProxy) {
+		t.Fatalf("err = %v, want ErrNoHealthyProxy", err)
+	}
+	now = now.Add(defaultCooldown + time.Second)
+	acquire(t, p, "")
+}
+
+func TestHealthCheckMarksProxyRequiringAuthBad(t *testing.T) {
+	// A forward proxy sees absolute-URI requests; answer 407 to anyone
+	// without credentials.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	p := New("res", config.ProxyPoolConfig{
+		HealthCheck: config.ProxyPoolHealthCheckConfig{URL: "http://health.example/generate_204"},
+		Proxies: []config.BrowserProxyConfig{
+			{Server: proxy.URL},
+			{Server: proxy.URL, Username: "u", Password: "p"},
+		},
+	})
+	p.runHealthChecks()
+
+	st := p.Status()
+	if st.Proxies[0].Healthy || st.Proxies[0].LastError == "" {
+		t.Fatalf("proxy without credentials should be bad: %+v", st.Proxies[0])
+	}
+	if !st.Proxies[1].Healthy || st.Proxies[1].LastChecked == nil {
+		t.Fatalf("proxy with credentials should be healthy: %+v", st.Proxies[1])
+	}
+}
+
+func TestManagerUnknownPool(t *testing.T) {
+	m := NewManager(config.ProxyPoolsConfig{"res": {Proxies: []config.BrowserProxyConfig{{Server: "http://a.example:8080"}}}})
+	if _, err := m.Acquire("dc", ""); !errors.Is(err, ErrUnknownPool) {
+		t.Fatalf("err = %v, want ErrUnknownPool", err)
+	}
+	if got := m.Status(); len(got) != 1 || got[0].Name != "res" {
+		t.Fatalf("status = %+v", got)
+	}
+}
@@ -4,7 +4,10 @@
 // the generated /openapi.json response.
 package routes
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Capability gates an endpoint behind a security config flag.
 type Capability string
@@ -19,6 +22,7 @@ const (
 	CapUpload           Capability = "upload"
 	CapStateExport      Capability = "stateExport"
 	CapNetworkIntercept Capability = "networkIntercept"
+	CapWebAuthn         Capability = "webauthn"
 )
 
 // CapabilityMeta is the single source of truth for a capability gate's
@@ -43,6 +47,7 @@ var capabilityMeta = map[Capability]CapabilityMeta{
 	CapUpload:           {CapUpload, "upload", "security.allowUpload", "upload_disabled"},
 	CapStateExport:      {CapStateExport, "stateExport", "security.allowStateExport", "state_export_disabled"},
 	CapNetworkIntercept: {CapNetworkIntercept, "networkIntercept", "security.allowNetworkIntercept", "network_intercept_disabled"},
+	CapWebAuthn:         {CapWebAuthn, "webauthn", "security.allowWebAuthn", "webauthn_disabled"},
 }
 
 // Meta returns the gate metadata for a capability. The second result is false
@@ -116,6 +121,9 @@ var coreEndpoints = []Endpoint{
 	{"POST", "/handoff", "Pause tab for human handoff", CapNone, true},
 	{"POST", "/resume", "Resume paused tab", CapNone, true},
 	{"GET", "/handoff", "Get handoff status", CapNone, true},
+	{"GET", "/takeover", "Describe a handoff via its takeover link", CapNone, true},
+	{"GET", "/takeover/stream", "Live takeover stream with input forwarding", CapScreencast, true},
+	{"POST", "/takeover/resume", "Resume a handoff via its takeover link", CapNone, true},
 
 	{"GET", "/cookies", "Get cookies", CapCookies, true},
 	{"POST", "/cookies", "Set cookies", CapCookies, true},
@@ -133,10 +141,14 @@ var coreEndpoints = []Endpoint{
 	{"GET", "/network/export", "Export HAR", CapNone, true},
 	{"GET", "/network/export/stream", "Export HAR stream", CapNone, true},
 	{"GET", "/network/{requestId}", "Single network request", CapNone, true},
+	{"GET", "/network/{requestId}/frames", "WebSocket frame SSE stream", CapNone, true},
 	{"POST", "/network/clear", "Clear network log", CapNone, false},
 	{"GET", "/network/route", "List interception rules for a tab", CapNetworkIntercept, true},
 	{"POST", "/network/route", "Install an interception rule", CapNetworkIntercept, true},
 	{"DELETE", "/network/route", "Remove interception rule(s)", CapNetworkIntercept, true},
+	{"GET", "/network/replay", "Show HAR replay status for a tab", CapNetworkIntercept, true},
+	{"POST", "/network/replay", "Serve a tab from a recorded HAR archive", CapNetworkIntercept, true},
+	{"DELETE", "/network/replay", "Stop HAR replay on a tab", CapNetworkIntercept, true},
 
 	{"GET", "/console", "Console logs", CapNone, false},
 	{"POST", "/console/clear", "Clear console logs", CapNone, false},
@@ -149,6 +161,9 @@ var coreEndpoints = []Endpoint{
 	{"GET", "/clipboard/paste", "Paste from clipboard", CapNone, false},
 
 	{"GET", "/stealth/status", "Stealth configuration status", CapNone, false},
+	{"GET", "/proxypools", "List proxy pools, their health and proxied tabs", CapNone, false},
+	{"GET", "/session/context", "Browser context of the caller's isolated session", CapNone, false},
+	{"DELETE", "/session/context", "Close the caller's session tabs and dispose its browser context", CapNone, false},
 	{"POST", "/fingerprint/rotate", "Rotate browser fingerprint", CapNone, false},
 
 	{"GET", "/solvers", "List available solvers", CapNone, false},
@@ -162,6 +177,17 @@ var coreEndpoints = []Endpoint{
 	{"POST", "/emulation/headers", "Set extra HTTP headers", CapNone, true},
 	{"POST", "/emulation/credentials", "Set HTTP auth credentials", CapNone, true},
 	{"POST", "/emulation/media", "Emulate CSS media features", CapNone, true},
+	{"GET", "/permissions", "Show permission overrides, states and prompts for a tab", CapNone, true},
+	{"POST", "/permissions", "Grant or deny browser permissions for an origin", CapNone, true},
+	{"DELETE", "/permissions", "Reset permission overrides for an origin", CapNone, true},
+	{"GET", "/webauthn", "List virtual WebAuthn authenticators", CapWebAuthn, true},
+	{"POST", "/webauthn/authenticators", "Add a virtual WebAuthn authenticator", CapWebAuthn, true},
+	{"DELETE", "/webauthn/authenticators/{authenticatorId}", "Remove a virtual WebAuthn authenticator", CapWebAuthn, true},
+	{"POST", "/webauthn/authenticators/{authenticatorId}/presence", "Toggle user presence and verification", CapWebAuthn, true},
+	{"GET", "/webauthn/authenticators/{authenticatorId}/credentials", "List authenticator credentials", CapWebAuthn, true},
+	{"POST", "/webauthn/authenticators/{authenticatorId}/credentials", "Import an authenticator credential", CapWebAuthn, true},
+	{"DELETE", "/webauthn/authenticators/{authenticatorId}/credentials", "Remove an authenticator credential", CapWebAuthn, true},
+	{"POST", "/webauthn/authenticators/{authenticatorId}/credentials/export", "Export authenticator credentials with private keys", CapWebAuthn, true},
 
 	{"POST", "/cache/clear", "Clear browser cache", CapNone, false},
 	{"GET", "/cache/status", "Cache status", CapNone, false},
@@ -169,12 +195,25 @@ var coreEndpoints = []Endpoint{
 	// Storage operations are gated under stateExport because they access/mutate sensitive client-side state.
 	{"POST", "/storage", "Set storage item", CapStateExport, true},
 	{"DELETE", "/storage", "Delete storage items", CapStateExport, true},
+	{"GET", "/serviceworkers", "List service workers", CapStateExport, true},
+	{"POST", "/serviceworkers/unregister", "Unregister a service worker", CapStateExport, true},
+	{"POST", "/serviceworkers/stop", "Stop running service workers", CapStateExport, true},
+	{"POST", "/serviceworkers/bypass", "Bypass service workers for network requests", CapStateExport, true},
+	{"GET", "/cachestorage", "List Cache Storage caches", CapStateExport, true},
+	{"GET", "/cachestorage/entries", "List Cache Storage entries", CapStateExport, true},
+	{"GET", "/cachestorage/entry", "Fetch a cached response", CapStateExport, true},
+	{"DELETE", "/cachestorage", "Delete a Cache Storage entry or cache", CapStateExport, true},
+	{"GET", "/indexeddb", "List IndexedDB databases (current origin)", CapStateExport, true},
+	{"GET", "/indexeddb/export", "Export IndexedDB records (current origin)", CapStateExport, true},
 
 	{"GET", "/state", "Read current browser state", CapStateExport, false},
 	{"GET", "/state/list", "List saved states", CapStateExport, false},
 
 	{"POST", "/evaluate", "Run JavaScript in page", CapEvaluate, true},
 	{"POST", "/macro", "Macro action pipeline", CapMacro, false},
+	{"POST", "/workflows/run", "Run a declarative workflow", CapMacro, false},
+	{"GET", "/workflows/runs/{id}", "Get workflow run status and trace", CapMacro, false},
+	{"POST", "/workflows/runs/{id}/cancel", "Cancel a running workflow", CapMacro, false},
 	{"GET", "/download", "Download URL via browser session", CapDownload, true},
 	{"POST", "/upload", "Upload file to file input", CapUpload, true},
 	{"GET", "/screencast", "Live tab frame stream", CapScreencast, false},
@@ -247,3 +286,61 @@ func TabScopedCapabilityRoutes() map[Capability][]Endpoint {
 	}
 	return m
 }
+
+// ParseCapability returns the capability named name ("evaluate",
+// "stateExport", ...), ignoring case.
+func ParseCapability(name string) (Capability, bool) {
+	name = strings.TrimSpace(name)
+	for cap := range capabilityMeta {
+		if strings.EqualFold(string(cap), name) {
+			return cap, true
+		}
+	}
+	return CapNone, false
+}
+
+// CapabilityFor returns the capability gating the catalogue endpoint that
+// serves method and path, or CapNone. Tab-scoped (/tabs/{id}/...) and
+// per-instance (/instances/{id}/...) forms resolve to their shorthand.
+func CapabilityFor(method, path string) Capability {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	tabScoped := false
+	switch {
+	case len(segs) > 2 && segs[0] == "tabs":
+		segs, tabScoped = segs[2:], true
+	case len(segs) > 2 && segs[0] == "instances":
+		segs = segs[2:]
+		// GET /instances/{id}/proxy/screencast is the orchestrator's
+		// WebSocket relay for /screencast.
+		if len(segs) > 1 && segs[0] == "proxy" {
+			segs = segs[1:]
+		}
+	}
+	for _, ep := range coreEndpoints {
+		if ep.Method != method || (tabScoped && !ep.TabScoped) {
+			continue
+		}
+		if pathMatches(strings.Split(strings.Trim(ep.Path, "/"), "/"), segs) {
+			return ep.Capability
+		}
+	}
+	return CapNone
+}
+
+func pathMatches(pattern, segs []string) bool {
+	if len(pattern) != len(segs) {
+		return false
+	}
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if segs[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segs[i] {
+			return false
+		}
+	}
+	return true
+}
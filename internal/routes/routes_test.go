@@ -246,3 +246,34 @@ func TestAllEndpointsHaveMethodAndPath(t *testing.T) {
 		}
 	}
 }
+
+func TestCapabilityFor(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         Capability
+	}{
+		{"POST", "/evaluate", CapEvaluate},
+		{"POST", "/tabs/T1/evaluate", CapEvaluate},
+		{"GET", "/cookies", CapCookies},
+		{"POST", "/instances/inst_1/cookies", CapCookies},
+		{"GET", "/instances/inst_1/proxy/screencast", CapScreencast},
+		{"GET", "/snapshot", CapNone},
+		{"GET", "/tabs/T1/text", CapNone},
+		{"GET", "/evaluate", CapNone},
+		{"GET", "/unknown", CapNone},
+	}
+	for _, tt := range tests {
+		if got := CapabilityFor(tt.method, tt.path); got != tt.want {
+			t.Errorf("CapabilityFor(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseCapability(t *testing.T) {
+	if got, ok := ParseCapability("StateExport"); !ok || got != CapStateExport {
+		t.Fatalf("ParseCapability(StateExport) = %q, %v", got, ok)
+	}
+	if _, ok := ParseCapability("shell"); ok {
+		t.Fatal("ParseCapability accepted an unknown capability")
+	}
+}
@@ -1,9 +1,16 @@
 package scheduler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pinchtab/pinchtab/internal/httpx"
 )
@@ -13,29 +20,130 @@ var (
 	errEmptyBatch     = errors.New("batch must contain at least one task")
 )
 
-// BatchRequest is the JSON body for POST /tasks/batch.
+// BatchRequest is the JSON body for POST /tasks/batch. Tasks may depend on
+// each other through batch-local keys, forming a DAG; OnFailure decides
+// whether a failure cancels the rest of the batch.
 type BatchRequest struct {
 	AgentID     string         `json:"agentId"`
 	CallbackURL string         `json:"callbackUrl,omitempty"`
+	OnFailure   FailurePolicy  `json:"onFailure,omitempty"`
 	Tasks       []BatchTaskDef `json:"tasks"`
 }
 
-// BatchTaskDef defines a single task inside a batch.
+// BatchTaskDef defines a single task inside a batch. DependsOn entries are
+// batch-local keys or IDs of tasks submitted earlier.
 type BatchTaskDef struct {
-	Action   string         `json:"action"`
-	TabID    string         `json:"tabId,omitempty"`
-	Ref      string         `json:"ref,omitempty"`
-	Params   map[string]any `json:"params,omitempty"`
-	Priority int            `json:"priority,omitempty"`
-	Deadline string         `json:"deadline,omitempty"`
+	Key       string         `json:"key,omitempty"`
+	DependsOn []string       `json:"dependsOn,omitempty"`
+	Action    string         `json:"action"`
+	TabID     string         `json:"tabId,omitempty"`
+	Ref       string         `json:"ref,omitempty"`
+	Params    map[string]any `json:"params,omitempty"`
+	Priority  int            `json:"priority,omitempty"`
+	Deadline  string         `json:"deadline,omitempty"`
+	OnRestart RestartPolicy  `json:"onRestart,omitempty"`
 }
 
 // BatchResponseItem is the result for each submitted task in the batch.
 type BatchResponseItem struct {
-	TaskID   string    `json:"taskId"`
-	State    TaskState `json:"state"`
-	Position int       `json:"position,omitempty"`
-	Error    string    `json:"error,omitempty"`
+	TaskID    string    `json:"taskId"`
+	Key       string    `json:"key,omitempty"`
+	State     TaskState `json:"state"`
+	Position  int       `json:"position,omitempty"`
+	DependsOn []string  `json:"dependsOn,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BatchDAG is the resolved dependency graph of a batch. Levels lists task
+// IDs by depth: every task in a level depends only on tasks in earlier
+// levels (or outside the batch), so each level can run in parallel.
+type BatchDAG struct {
+	Levels [][]string `json:"levels"`
+	Edges  []DAGEdge  `json:"edges"`
+}
+
+// DAGEdge says task To waits for task From.
+type DAGEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+var batchKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// planBatch validates keys and dependencies and returns the task indexes
+// grouped by level. External dependencies must name existing tasks.
+func (s *Scheduler) planBatch(tasks []BatchTaskDef) ([][]int, error) {
+	keys := make(map[string]int, len(tasks))
+	for i, td := range tasks {
+		if td.Key == "" {
+			continue
+		}
+		if !batchKeyPattern.MatchString(td.Key) {
+			return nil, fmt.Errorf("tasks[%d]: invalid key %q (letters, digits, '-' and '_' only)", i, td.Key)
+		}
+		if _, dup := keys[td.Key]; dup {
+			return nil, fmt.Errorf("tasks[%d]: duplicate key %q", i, td.Key)
+		}
+		keys[td.Key] = i
+	}
+
+	pending := make([]int, len(tasks))
+	children := make([][]int, len(tasks))
+	for i, td := range tasks {
+		seen := make(map[string]bool, len(td.DependsOn))
+		for _, dep := range td.DependsOn {
+			if seen[dep] {
+				return nil, fmt.Errorf("tasks[%d]: duplicate dependency %q", i, dep)
+			}
+			seen[dep] = true
+			if parent, ok := keys[dep]; ok {
+				pending[i]++
+				children[parent] = append(children[parent], i)
+				continue
+			}
+			if s.GetTask(dep) == nil {
+				return nil, fmt.Errorf("tasks[%d]: unknown dependency %q", i, dep)
+			}
+		}
+	}
+
+	var levels [][]int
+	var level []int
+	for i := range tasks {
+		if pending[i] == 0 {
+			level = append(level, i)
+		}
+	}
+	placed := 0
+	for len(level) > 0 {
+		levels = append(levels, level)
+		placed += len(level)
+		var next []int
+		for _, i := range level {
+			for _, child := range children[i] {
+				pending[child]--
+				if pending[child] == 0 {
+					next = append(next, child)
+				}
+			}
+		}
+		sort.Ints(next)
+		level = next
+	}
+	if placed != len(tasks) {
+		var cyclic []string
+		for i := range tasks {
+			if pending[i] > 0 {
+				name := tasks[i].Key
+				if name == "" {
+					name = fmt.Sprintf("tasks[%d]", i)
+				}
+				cyclic = append(cyclic, name)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle among tasks %s", strings.Join(cyclic, ", "))
+	}
+	return levels, nil
 }
 
 func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
@@ -63,40 +171,111 @@ func (s *Scheduler) handleBatch(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if !req.OnFailure.IsValid() {
+		httpx.Error(w, 400, fmt.Errorf("invalid onFailure %q (must be continue or fail-fast)", req.OnFailure))
+		return
+	}
+	levels, err := s.planBatch(req.Tasks)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
 
-	results := make([]BatchResponseItem, 0, len(req.Tasks))
+	batchID := generateBatchID()
+	opts := submitOptions{batchID: batchID, onFailure: req.OnFailure}
+	localKeys := make(map[string]bool, len(req.Tasks))
 	for _, td := range req.Tasks {
-		sr := SubmitRequest{
-			AgentID:     req.AgentID,
-			Action:      td.Action,
-			TabID:       td.TabID,
-			Ref:         td.Ref,
-			Params:      td.Params,
-			Priority:    td.Priority,
-			Deadline:    td.Deadline,
-			CallbackURL: req.CallbackURL,
+		if td.Key != "" {
+			localKeys[td.Key] = true
 		}
+	}
+	keyIDs := make(map[string]string, len(req.Tasks))
+	results := make([]BatchResponseItem, len(req.Tasks))
+	dag := BatchDAG{Levels: make([][]string, 0, len(levels)), Edges: []DAGEdge{}}
 
-		task, err := s.Submit(sr)
-		if err != nil {
-			item := BatchResponseItem{State: StateRejected, Error: err.Error()}
+	// Parents are submitted before their children, so keys resolve to IDs.
+	for _, level := range levels {
+		ids := make([]string, 0, len(level))
+		for _, i := range level {
+			td := req.Tasks[i]
+			item := BatchResponseItem{Key: td.Key}
+
+			var deps []string
+			var missing string
+			for _, dep := range td.DependsOn {
+				if localKeys[dep] {
+					id, ok := keyIDs[dep]
+					if !ok {
+						missing = dep
+						break
+					}
+					dep = id
+				}
+				deps = append(deps, dep)
+			}
+			if missing != "" {
+				item.State = StateRejected
+				item.Error = fmt.Sprintf("dependency %q was not submitted", missing)
+				results[i] = item
+				continue
+			}
+			item.DependsOn = deps
+
+			sr := SubmitRequest{
+				AgentID:     req.AgentID,
+				Action:      td.Action,
+				TabID:       td.TabID,
+				Ref:         td.Ref,
+				Params:      td.Params,
+				Priority:    td.Priority,
+				Deadline:    td.Deadline,
+				CallbackURL: req.CallbackURL,
+				OnRestart:   td.OnRestart,
+				DependsOn:   deps,
+			}
+			opts.key = td.Key
+			task, err := s.submit(sr, opts)
 			if task != nil {
 				item.TaskID = task.ID
+				if td.Key != "" {
+					keyIDs[td.Key] = task.ID
+				}
+				ids = append(ids, task.ID)
+				for _, dep := range deps {
+					dag.Edges = append(dag.Edges, DAGEdge{From: dep, To: task.ID})
+				}
+			}
+			if err != nil {
+				item.State = StateRejected
+				item.Error = err.Error()
+				results[i] = item
+				slog.Warn("batch: task rejected", "agent", req.AgentID, "action", td.Action, "err", err)
+				continue
 			}
-			results = append(results, item)
-			slog.Warn("batch: task rejected", "agent", req.AgentID, "action", td.Action, "err", err)
-			continue
-		}
 
-		results = append(results, BatchResponseItem{
-			TaskID:   task.ID,
-			State:    task.GetState(),
-			Position: task.Position,
-		})
+			snap := task.Snapshot()
+			item.State = snap.State
+			item.Position = snap.Position
+			results[i] = item
+		}
+		if len(ids) > 0 {
+			dag.Levels = append(dag.Levels, ids)
+		}
 	}
 
 	httpx.JSON(w, 202, map[string]any{
+		"batchId":   batchID,
 		"tasks":     results,
 		"submitted": len(results),
+		"dag":       dag,
 	})
 }
+
+// generateBatchID produces a random batch ID in the format bat_XXXXXXXX.
+func generateBatchID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("bat_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "bat_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // Saturday
+
+	tests := []struct {
+		expr string
+		loc  *time.Location
+		want time.Time
+	}{
+		{"*/15 * * * *", time.UTC, time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.UTC, time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.UTC, time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.UTC, time.Date(2026, 3, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.UTC, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.UTC, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"5,10 10 * * *", time.UTC, time.Date(2026, 3, 14, 10, 10, 0, 0, time.UTC)},
+		// Both day fields restricted: the 1st of the month OR any Monday.
+		{"0 0 1 * 1", time.UTC, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * *", ny, time.Date(2026, 3, 14, 13, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.UTC, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		spec, err := parseCron(tt.expr, tt.loc)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := spec.next(from); !got.Equal(tt.want) {
+			t.Errorf("%q next = %s, want %s", tt.expr, got.UTC(), tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every 5m"} {
+		if _, err := parseCron(expr, time.UTC); err == nil {
+			t.Errorf("parseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestCronNeverFires(t *testing.T) {
+	spec, err := parseCron("0 0 30 2 *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := spec.next(time.Now()); !got.IsZero() {
+		t.Errorf("Feb 30 should never fire, got %s", got)
+	}
+}
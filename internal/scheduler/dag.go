@@ -0,0 +1,330 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/workflow"
+)
+
+// FailurePolicy decides how a failed task in a batch affects the rest of
+// the batch. Dependents of a failed task always fail; the policy only
+// matters for tasks that do not depend on it.
+type FailurePolicy string
+
+const (
+	// FailContinue lets independent tasks keep running. The default.
+	FailContinue FailurePolicy = "continue"
+	// FailFast cancels every unfinished task in the batch.
+	FailFast FailurePolicy = "fail-fast"
+)
+
+// IsValid reports whether p is a known policy. Empty means continue.
+func (p FailurePolicy) IsValid() bool {
+	switch p {
+	case "", FailContinue, FailFast:
+		return true
+	}
+	return false
+}
+
+// waitingTask is a task blocked on parents that have not finished yet.
+type waitingTask struct {
+	task      *Task
+	remaining int
+}
+
+// dependencyRef matches ${...} references in params and tabId.
+var dependencyRef = regexp.MustCompile(`\$\{\s*([^{}]+?)\s*\}`)
+
+// waitFor registers a waiting task against its parents. Parents that are
+// already done count as satisfied; a parent that already failed fails the
+// task straight away.
+func (s *Scheduler) waitFor(t *Task) {
+	var failReason string
+	w := &waitingTask{task: t}
+
+	s.depsMu.Lock()
+	for _, parentID := range t.DependsOn {
+		parent := s.GetTask(parentID)
+		if parent == nil {
+			failReason = fmt.Sprintf("dependency %s not found", parentID)
+			break
+		}
+		state := parent.GetState()
+		if state == StateDone {
+			continue
+		}
+		if state.IsTerminal() {
+			failReason = fmt.Sprintf("dependency %s %s", parentID, state)
+			break
+		}
+		w.remaining++
+		s.dependents[parentID] = append(s.dependents[parentID], t.ID)
+	}
+	if failReason == "" && w.remaining > 0 {
+		s.waiting[t.ID] = w
+	}
+	s.depsMu.Unlock()
+
+	switch {
+	case failReason != "":
+		s.failWaiting(t, failReason)
+	case w.remaining == 0:
+		s.releaseWaiting(t)
+	}
+}
+
+// releaseDependents is called once a task is terminal. Dependents whose last
+// parent just finished with done are queued; if the parent did not finish
+// with done, its dependents fail, which in turn fails their own dependents.
+func (s *Scheduler) releaseDependents(parent *Task) {
+	state := parent.GetState()
+
+	var ready, failed []*Task
+	s.depsMu.Lock()
+	for _, childID := range s.dependents[parent.ID] {
+		w := s.waiting[childID]
+		if w == nil {
+			continue
+		}
+		if state != StateDone {
+			delete(s.waiting, childID)
+			failed = append(failed, w.task)
+			continue
+		}
+		w.remaining--
+		if w.remaining == 0 {
+			delete(s.waiting, childID)
+			ready = append(ready, w.task)
+		}
+	}
+	delete(s.dependents, parent.ID)
+	s.depsMu.Unlock()
+
+	for _, child := range failed {
+		s.failWaiting(child, fmt.Sprintf("dependency %s %s", parent.ID, state))
+	}
+	for _, child := range ready {
+		s.releaseWaiting(child)
+	}
+}
+
+// dropWaiting forgets a waiting task, e.g. when it is cancelled.
+func (s *Scheduler) dropWaiting(taskID string) {
+	s.depsMu.Lock()
+	delete(s.waiting, taskID)
+	s.depsMu.Unlock()
+}
+
+// releaseWaiting substitutes parent results into the task and queues it.
+func (s *Scheduler) releaseWaiting(t *Task) {
+	if !t.Deadline.IsZero() && t.Deadline.Before(timeNow()) {
+		s.metrics.recordExpire()
+		s.failWaiting(t, "deadline exceeded while waiting for dependencies")
+		return
+	}
+
+	scope := s.dependencyScope(t)
+	t.mu.RLock()
+	params, tabID := t.Params, t.TabID
+	t.mu.RUnlock()
+	resolved, err := substituteDependencies(params, scope)
+	if err == nil {
+		var tab any
+		if tab, err = substituteDependencies(tabID, scope); err == nil {
+			var ok bool
+			if tabID, ok = tab.(string); !ok {
+				err = fmt.Errorf("tabId must resolve to a string")
+			}
+		}
+	}
+	if err != nil {
+		s.failWaiting(t, "resolve dependency results: "+err.Error())
+		return
+	}
+
+	t.mu.Lock()
+	if params != nil {
+		t.Params, _ = resolved.(map[string]any)
+	}
+	t.TabID = tabID
+	t.mu.Unlock()
+
+	// Queued before Enqueue so a worker never sees a waiting task.
+	if err := t.SetState(StateQueued); err != nil {
+		return // cancelled in the meantime
+	}
+	pos, err := s.queue.Enqueue(t)
+	if err != nil {
+		t.Error = err.Error()
+		_ = t.SetState(StateRejected)
+		s.metrics.recordReject(t.AgentID)
+		slog.Warn("task rejected after dependencies", "task", t.ID, "agent", t.AgentID, "err", err)
+		s.results.Store(t)
+		s.retireTask(t)
+		return
+	}
+	t.Position = pos
+	s.results.Store(t)
+	slog.Info("task dependencies done, queued", "task", t.ID, "agent", t.AgentID, "position", pos)
+}
+
+// failWaiting fails a task that never made it into the queue.
+func (s *Scheduler) failWaiting(t *Task, reason string) {
+	if t.GetState() != StateWaiting {
+		return
+	}
+	t.Error = reason
+	if err := t.SetState(StateFailed); err != nil {
+		return
+	}
+	s.metrics.recordFail(t.AgentID)
+	slog.Info("task failed before queueing", "task", t.ID, "agent", t.AgentID, "reason", reason)
+	s.results.Store(t)
+	s.retireTask(t)
+}
+
+// expireWaiting fails waiting tasks whose deadline passed before their
+// parents finished.
+func (s *Scheduler) expireWaiting(now time.Time) {
+	var expired []*Task
+	s.depsMu.Lock()
+	for id, w := range s.waiting {
+		if !w.task.Deadline.IsZero() && w.task.Deadline.Before(now) {
+			delete(s.waiting, id)
+			expired = append(expired, w.task)
+		}
+	}
+	s.depsMu.Unlock()
+
+	for _, t := range expired {
+		s.metrics.recordExpire()
+		s.failWaiting(t, "deadline exceeded while waiting for dependencies")
+	}
+}
+
+// failFastBatch cancels the rest of a fail-fast batch once one of its tasks
+// fails.
+func (s *Scheduler) failFastBatch(t *Task) {
+	if t.BatchID == "" || t.OnFailure != FailFast {
+		return
+	}
+	if state := t.GetState(); state != StateFailed && state != StateRejected {
+		return
+	}
+
+	var siblings []string
+	s.liveMu.RLock()
+	for id, other := range s.live {
+		if other.BatchID == t.BatchID && !other.GetState().IsTerminal() {
+			siblings = append(siblings, id)
+		}
+	}
+	s.liveMu.RUnlock()
+	if len(siblings) == 0 {
+		return
+	}
+
+	slog.Info("batch failing fast", "batch", t.BatchID, "failedTask", t.ID, "cancelling", len(siblings))
+	for _, id := range siblings {
+		// A sibling may finish or fail on its own in the meantime.
+		_ = s.Cancel(id)
+	}
+}
+
+// dependencyScope exposes each parent to ${...} references by task ID and,
+// within a batch, by its batch-local key.
+func (s *Scheduler) dependencyScope(t *Task) map[string]any {
+	scope := make(map[string]any, 2*len(t.DependsOn))
+	for _, parentID := range t.DependsOn {
+		parent := s.GetTask(parentID)
+		if parent == nil {
+			continue
+		}
+		snap := parent.Snapshot()
+		entry := map[string]any{
+			"taskId": snap.ID,
+			"state":  string(snap.State),
+			"result": snap.Result,
+		}
+		scope[snap.ID] = entry
+		if snap.Key != "" && snap.BatchID == t.BatchID {
+			scope[snap.Key] = entry
+		}
+	}
+	return scope
+}
+
+// substituteDependencies replaces ${ref.path} references whose first
+// segment names a parent in scope. Anything else, such as a JavaScript
+// template literal in an evaluate expression, is left untouched. A string
+// that is exactly one reference takes the referenced value's type.
+func substituteDependencies(v any, scope map[string]any) (any, error) {
+	switch val := v.(type) {
+	case string:
+		return substituteString(val, scope)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			resolved, err := substituteDependencies(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			resolved, err := substituteDependencies(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func substituteString(s string, scope map[string]any) (any, error) {
+	matches := dependencyRef.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	inScope := func(path string) bool {
+		root, _, _ := strings.Cut(path, ".")
+		_, ok := scope[root]
+		return ok
+	}
+
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		path := s[matches[0][2]:matches[0][3]]
+		if !inScope(path) {
+			return s, nil
+		}
+		return workflow.Lookup(scope, path)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+		if !inScope(s[m[2]:m[3]]) {
+			b.WriteString(s[m[0]:m[1]])
+			continue
+		}
+		text, err := workflow.InterpolateString(s[m[0]:m[1]], scope)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(text)
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
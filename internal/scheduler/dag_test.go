@@ -0,0 +1,294 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type funcExecutor func(t *Task) (any, error)
+
+func (f funcExecutor) Execute(_ context.Context, t *Task) (any, error) { return f(t) }
+
+// newDAGScheduler returns a scheduler without workers whose tasks succeed
+// with {"tabId": "tab-<action>", "action": <action>} unless the action is
+// "boom".
+func newDAGScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	s, executor := newTestScheduler(t)
+	executor.Close()
+	s.executor = funcExecutor(func(task *Task) (any, error) {
+		if task.Action == "boom" {
+			return nil, fmt.Errorf("boom")
+		}
+		return map[string]any{"tabId": "tab-" + task.Action, "action": task.Action}, nil
+	})
+	return s
+}
+
+// runNext dispatches the next queued task synchronously.
+func runNext(t *testing.T, s *Scheduler) *Task {
+	t.Helper()
+	task := s.queue.Dequeue(100, 100)
+	if task == nil {
+		t.Fatal("no queued task to run")
+	}
+	s.dispatch(task)
+	return task
+}
+
+func TestDependentTaskWaitsForParent(t *testing.T) {
+	s := newDAGScheduler(t)
+
+	parent, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "login", TabID: "tab-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := s.Submit(SubmitRequest{
+		AgentID:   "a1",
+		Action:    "scrape",
+		TabID:     "${" + parent.ID + ".result.tabId}",
+		DependsOn: []string{parent.ID},
+		Params: map[string]any{
+			"note":       "after ${" + parent.ID + ".result.action}",
+			"result":     "${" + parent.ID + ".result}",
+			"expression": "`${window.location.href}`",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.GetState() != StateWaiting {
+		t.Fatalf("child should wait, got %s", child.GetState())
+	}
+	if stats := s.QueueStats(); stats.TotalQueued != 1 {
+		t.Fatalf("waiting tasks must not be queued, got %d queued", stats.TotalQueued)
+	}
+
+	runNext(t, s)
+
+	got := s.GetTask(child.ID).Snapshot()
+	if got.State != StateQueued {
+		t.Fatalf("child should be queued once the parent is done, got %s", got.State)
+	}
+	if got.TabID != "tab-login" {
+		t.Errorf("tabId = %q, want tab-login", got.TabID)
+	}
+	if got.Params["note"] != "after login" {
+		t.Errorf("note = %v", got.Params["note"])
+	}
+	if m, ok := got.Params["result"].(map[string]any); !ok || m["action"] != "login" {
+		t.Errorf("whole-reference param should keep its type, got %#v", got.Params["result"])
+	}
+	if got.Params["expression"] != "`${window.location.href}`" {
+		t.Errorf("unrelated ${...} must be left alone, got %v", got.Params["expression"])
+	}
+}
+
+func TestDependencyFailureCascades(t *testing.T) {
+	s := newDAGScheduler(t)
+
+	parent, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "boom", TabID: "tab-1"})
+	child, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "scrape", DependsOn: []string{parent.ID}})
+	grandchild, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "logout", DependsOn: []string{child.ID}})
+
+	runNext(t, s)
+
+	for _, task := range []*Task{child, grandchild} {
+		got := s.GetTask(task.ID).Snapshot()
+		if got.State != StateFailed || !strings.HasPrefix(got.Error, "dependency ") {
+			t.Errorf("%s: state=%s error=%q, want dependency failure", task.Action, got.State, got.Error)
+		}
+	}
+	if n := len(s.waiting); n != 0 {
+		t.Errorf("waiting set should be empty, has %d", n)
+	}
+
+	// Depending on a task that already failed fails immediately.
+	late, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "scrape", DependsOn: []string{parent.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if late.GetState() != StateFailed {
+		t.Errorf("late dependent state = %s, want failed", late.GetState())
+	}
+}
+
+func TestDependsOnUnknownTask(t *testing.T) {
+	s := newDAGScheduler(t)
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", DependsOn: []string{"tsk_missing"}}); err == nil {
+		t.Error("unknown dependency should be rejected")
+	}
+}
+
+func TestCancelWaitingTask(t *testing.T) {
+	s := newDAGScheduler(t)
+
+	parent, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "login", TabID: "tab-1"})
+	child, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "scrape", TabID: "tab-1", DependsOn: []string{parent.ID}})
+	if err := s.Cancel(child.ID); err != nil {
+		t.Fatal(err)
+	}
+	runNext(t, s)
+
+	if got := s.GetTask(child.ID).GetState(); got != StateCancelled {
+		t.Errorf("cancelled child state = %s", got)
+	}
+	if stats := s.QueueStats(); stats.TotalQueued != 0 {
+		t.Errorf("cancelled child must not be queued, got %d queued", stats.TotalQueued)
+	}
+}
+
+func TestPlanBatch(t *testing.T) {
+	s := newDAGScheduler(t)
+	existing, _ := s.Submit(SubmitRequest{AgentID: "a1", Action: "click"})
+
+	levels, err := s.planBatch([]BatchTaskDef{
+		{Key: "logout", DependsOn: []string{"p1", "p2"}},
+		{Key: "p1", DependsOn: []string{"login"}},
+		{Key: "p2", DependsOn: []string{"login", existing.ID}},
+		{Key: "login"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{3}, {1, 2}, {0}}
+	if fmt.Sprint(levels) != fmt.Sprint(want) {
+		t.Errorf("levels = %v, want %v", levels, want)
+	}
+
+	bad := map[string][]BatchTaskDef{
+		"cycle":         {{Key: "a", DependsOn: []string{"b"}}, {Key: "b", DependsOn: []string{"a"}}},
+		"self":          {{Key: "a", DependsOn: []string{"a"}}},
+		"unknown":       {{Key: "a", DependsOn: []string{"nope"}}},
+		"duplicate key": {{Key: "a"}, {Key: "a"}},
+		"bad key":       {{Key: "a.b"}},
+		"duplicate dep": {{Key: "a"}, {DependsOn: []string{"a", "a"}}},
+	}
+	for name, tasks := range bad {
+		if _, err := s.planBatch(tasks); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestHandleBatchDAG(t *testing.T) {
+	s := newDAGScheduler(t)
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+
+	body := `{
+		"agentId": "a1",
+		"tasks": [
+			{"key":"logout","action":"logout","tabId":"${login.result.tabId}","dependsOn":["p1","p2","p3"]},
+			{"key":"p1","action":"scrape","tabId":"${login.result.tabId}","dependsOn":["login"],"params":{"page":1}},
+			{"key":"p2","action":"scrape","tabId":"${login.result.tabId}","dependsOn":["login"],"params":{"page":2}},
+			{"key":"p3","action":"scrape","tabId":"${login.result.tabId}","dependsOn":["login"],"params":{"page":3}},
+			{"key":"login","action":"login","tabId":"tab-1"}
+		]
+	}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(body)))
+	if w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		BatchID string              `json:"batchId"`
+		Tasks   []BatchResponseItem `json:"tasks"`
+		DAG     BatchDAG            `json:"dag"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resp.BatchID, "bat_") {
+		t.Errorf("batchId = %q", resp.BatchID)
+	}
+	if len(resp.DAG.Levels) != 3 || len(resp.DAG.Levels[0]) != 1 || len(resp.DAG.Levels[1]) != 3 || len(resp.DAG.Levels[2]) != 1 {
+		t.Fatalf("levels = %v", resp.DAG.Levels)
+	}
+	if len(resp.DAG.Edges) != 6 {
+		t.Errorf("edges = %v", resp.DAG.Edges)
+	}
+	byKey := map[string]BatchResponseItem{}
+	for _, item := range resp.Tasks {
+		byKey[item.Key] = item
+	}
+	if byKey["login"].State != StateQueued || byKey["p1"].State != StateWaiting || byKey["logout"].State != StateWaiting {
+		t.Fatalf("unexpected states: %+v", resp.Tasks)
+	}
+	if got := byKey["p2"].DependsOn; len(got) != 1 || got[0] != byKey["login"].TaskID {
+		t.Errorf("p2 dependsOn = %v, want [%s]", got, byKey["login"].TaskID)
+	}
+
+	runNext(t, s) // login
+	for range 3 {
+		scrape := runNext(t, s)
+		if scrape.TabID != "tab-login" {
+			t.Errorf("scrape tabId = %q", scrape.TabID)
+		}
+	}
+	if got := s.GetTask(byKey["logout"].TaskID).GetState(); got != StateQueued {
+		t.Fatalf("logout should queue after every scrape, got %s", got)
+	}
+	runNext(t, s)
+	if got := s.GetTask(byKey["logout"].TaskID).GetState(); got != StateDone {
+		t.Errorf("logout state = %s", got)
+	}
+}
+
+func TestHandleBatchFailFast(t *testing.T) {
+	s := newDAGScheduler(t)
+	mux := http.NewServeMux()
+	s.RegisterHandlers(mux)
+
+	body := `{
+		"agentId": "a1",
+		"onFailure": "fail-fast",
+		"tasks": [
+			{"key":"bad","action":"boom","tabId":"tab-1","priority":0},
+			{"key":"other","action":"scrape","tabId":"tab-1","priority":5},
+			{"key":"after","action":"scrape","tabId":"tab-1","dependsOn":["other"]}
+		]
+	}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(body)))
+	if w.Code != 202 {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Tasks []BatchResponseItem `json:"tasks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if failed := runNext(t, s); failed.GetState() != StateFailed {
+		t.Fatalf("expected the boom task first, got %s", failed.Action)
+	}
+	if got := s.GetTask(resp.Tasks[1].TaskID).GetState(); got != StateCancelled {
+		t.Errorf("other: state = %s, want cancelled", got)
+	}
+	// "after" is either cancelled directly or failed because "other" was.
+	if got := s.GetTask(resp.Tasks[2].TaskID).GetState(); got != StateCancelled && got != StateFailed {
+		t.Errorf("after: state = %s, want cancelled or failed", got)
+	}
+	if stats := s.QueueStats(); stats.TotalQueued != 0 {
+		t.Errorf("nothing should be left queued, got %d", stats.TotalQueued)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(`{"agentId":"a1","onFailure":"sometimes","tasks":[{"action":"click"}]}`)))
+	if w.Code != 400 {
+		t.Errorf("invalid onFailure: expected 400, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/tasks/batch", strings.NewReader(`{"agentId":"a1","tasks":[{"key":"a","action":"click","dependsOn":["b"]},{"key":"b","action":"click","dependsOn":["a"]}]}`)))
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "cycle") {
+		t.Errorf("cycle: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+// MetricFamilies reports queue depth, in-flight tasks and task outcomes per
+// agent for the metrics exposition endpoint.
+func (s *Scheduler) MetricFamilies() []metrics.Family {
+	queue := s.QueueStats()
+	snap := s.GetMetrics()
+
+	depth := metrics.Family{Name: "pinchtab_scheduler_queue_depth", Help: "Tasks waiting in the queue per agent.", Type: metrics.Gauge}
+	inflight := metrics.Family{Name: "pinchtab_scheduler_inflight", Help: "Tasks being executed per agent.", Type: metrics.Gauge}
+	for _, agentID := range sortedAgentIDs(queue.Agents) {
+		stats := queue.Agents[agentID]
+		labels := []metrics.Label{{Name: "agent_id", Value: agentID}}
+		depth.Samples = append(depth.Samples, metrics.Sample{Labels: labels, Value: float64(stats.Queued)})
+		inflight.Samples = append(inflight.Samples, metrics.Sample{Labels: labels, Value: float64(stats.Inflight)})
+	}
+
+	tasks := metrics.Family{Name: "pinchtab_scheduler_tasks", Help: "Scheduler tasks per agent by outcome.", Type: metrics.Counter}
+	for _, agentID := range sortedAgentIDs(snap.Agents) {
+		a := snap.Agents[agentID]
+		for _, outcome := range []struct {
+			name  string
+			value uint64
+		}{
+			{"submitted", a.Submitted},
+			{"completed", a.Completed},
+			{"failed", a.Failed},
+			{"cancelled", a.Cancelled},
+			{"rejected", a.Rejected},
+		} {
+			tasks.Samples = append(tasks.Samples, metrics.Sample{
+				Suffix: "_total",
+				Labels: []metrics.Label{{Name: "agent_id", Value: agentID}, {Name: "outcome", Value: outcome.name}},
+				Value:  float64(outcome.value),
+			})
+		}
+	}
+
+	expired := metrics.Family{Name: "pinchtab_scheduler_tasks_expired", Help: "Tasks that missed their deadline.", Type: metrics.Counter,
+		Samples: []metrics.Sample{{Suffix: "_total", Value: float64(snap.TasksExpired)}}}
+
+	return []metrics.Family{depth, inflight, tasks, expired}
+}
+
+func sortedAgentIDs[V any](m map[string]V) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
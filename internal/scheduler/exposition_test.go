@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
+)
+
+func TestMetricFamiliesPerAgent(t *testing.T) {
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	for _, agentID := range []string{"agent-1", "agent-1", "agent-2"} {
+		if _, err := s.Submit(SubmitRequest{AgentID: agentID, Action: "click", TabID: "tab-1"}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.Write(&buf, metrics.Merge(s.MetricFamilies()), metrics.FormatOpenMetrics); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`pinchtab_scheduler_queue_depth{agent_id="agent-1"} 2`,
+		`pinchtab_scheduler_queue_depth{agent_id="agent-2"} 1`,
+		`pinchtab_scheduler_inflight{agent_id="agent-1"} 0`,
+		`pinchtab_scheduler_tasks_total{agent_id="agent-1",outcome="submitted"} 2`,
+		`pinchtab_scheduler_tasks_expired_total 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in:\n%s", want, out)
+		}
+	}
+}
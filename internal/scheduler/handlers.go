@@ -15,6 +15,10 @@ func (s *Scheduler) RegisterHandlers(mux *http.ServeMux) {
 	mux.HandleFunc("POST /tasks/{id}/cancel", s.handleCancel)
 	mux.HandleFunc("GET /scheduler/stats", s.handleStats)
 	mux.HandleFunc("POST /tasks/batch", s.handleBatch)
+	mux.HandleFunc("POST /schedules", s.handleScheduleCreate)
+	mux.HandleFunc("GET /schedules", s.handleScheduleList)
+	mux.HandleFunc("GET /schedules/{id}", s.handleScheduleGet)
+	mux.HandleFunc("DELETE /schedules/{id}", s.handleScheduleDelete)
 }
 
 func (s *Scheduler) handleSubmit(w http.ResponseWriter, r *http.Request) {
@@ -113,8 +117,9 @@ func (s *Scheduler) handleStats(w http.ResponseWriter, _ *http.Request) {
 	queue := s.QueueStats()
 	metrics := s.GetMetrics()
 	httpx.JSON(w, 200, map[string]any{
-		"queue":   queue,
-		"metrics": metrics,
+		"queue":     queue,
+		"metrics":   metrics,
+		"schedules": s.ListSchedules(),
 		"config": map[string]any{
 			"strategy":          s.cfg.Strategy,
 			"maxQueueSize":      s.cfg.MaxQueueSize,
@@ -126,3 +131,41 @@ func (s *Scheduler) handleStats(w http.ResponseWriter, _ *http.Request) {
 		},
 	})
 }
+
+func (s *Scheduler) handleScheduleCreate(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if err := httpx.DecodeJSONBody(w, r, 0, &req); err != nil {
+		httpx.Error(w, httpx.StatusForJSONDecodeError(err), err)
+		return
+	}
+
+	sc, err := s.CreateSchedule(req)
+	if err != nil {
+		httpx.Error(w, 400, err)
+		return
+	}
+	httpx.JSON(w, 201, sc)
+}
+
+func (s *Scheduler) handleScheduleList(w http.ResponseWriter, _ *http.Request) {
+	schedules := s.ListSchedules()
+	httpx.JSON(w, 200, map[string]any{"schedules": schedules, "count": len(schedules)})
+}
+
+func (s *Scheduler) handleScheduleGet(w http.ResponseWriter, r *http.Request) {
+	sc := s.GetSchedule(r.PathValue("id"))
+	if sc == nil {
+		httpx.ErrorCode(w, 404, "not_found", "schedule not found", false, nil)
+		return
+	}
+	httpx.JSON(w, 200, sc)
+}
+
+func (s *Scheduler) handleScheduleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.DeleteSchedule(id); err != nil {
+		httpx.ErrorCode(w, 404, "not_found", err.Error(), false, nil)
+		return
+	}
+	httpx.JSON(w, 200, map[string]string{"status": "deleted", "scheduleId": id})
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// journalCompactMin is the minimum number of appended records before a
+// compaction is considered. Below this the log is cheap to replay as-is.
+const journalCompactMin = 1024
+
+// journalRecord is one line of the on-disk task journal. A record carries
+// either a full task snapshot (last write for an ID wins) or a tombstone.
+type journalRecord struct {
+	Task    *Task  `json:"task,omitempty"`
+	Deleted string `json:"deleted,omitempty"`
+}
+
+// taskJournal is an append-only JSONL log of task snapshots with periodic
+// compaction. It is not safe for concurrent use; ResultStore serializes
+// access under its own lock so the log order matches the in-memory order.
+type taskJournal struct {
+	path     string
+	f        *os.File
+	appended int
+}
+
+// openTaskJournal opens (creating if needed) the journal at path and returns
+// the latest snapshot of every task it records, oldest first.
+func openTaskJournal(path string) (*taskJournal, []*Task, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	tasks, records, err := readTaskJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open journal: %w", err)
+	}
+	return &taskJournal{path: path, f: f, appended: records}, tasks, nil
+}
+
+// readTaskJournal replays the log. Malformed lines — including a torn final
+// line left behind by a crash mid-write — are skipped rather than failing the
+// whole replay. A missing file is an empty journal.
+func readTaskJournal(path string) ([]*Task, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("read journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	latest := make(map[string]*Task)
+	records := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		records++
+		switch {
+		case rec.Deleted != "":
+			delete(latest, rec.Deleted)
+		case rec.Task != nil && rec.Task.ID != "":
+			latest[rec.Task.ID] = rec.Task
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("scan journal: %w", err)
+	}
+
+	tasks := make([]*Task, 0, len(latest))
+	for _, t := range latest {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+	return tasks, records, nil
+}
+
+func (j *taskJournal) append(rec journalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+	// One write per record: a crash can tear at most the final line, which
+	// replay discards.
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write journal record: %w", err)
+	}
+	j.appended++
+	return nil
+}
+
+// needsCompaction reports whether the log has grown well past the number of
+// tasks it actually describes.
+func (j *taskJournal) needsCompaction(live int) bool {
+	return j.appended >= journalCompactMin && j.appended > 4*live
+}
+
+// compact atomically replaces the log with one record per task. The new file
+// is fsynced before the rename so a crash leaves either the old or the new
+// log intact, never a truncated one.
+func (j *taskJournal) compact(tasks []*Task) error {
+	tmpPath := j.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("create compacted journal: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for _, t := range tasks {
+		line, err := json.Marshal(journalRecord{Task: t})
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("encode journal record: %w", err)
+		}
+		_, _ = w.Write(line)
+		_ = w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write compacted journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("sync compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close compacted journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("replace journal: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("reopen journal: %w", err)
+	}
+	_ = j.f.Close()
+	j.f = f
+	j.appended = len(tasks)
+	return nil
+}
+
+func (j *taskJournal) close() error {
+	if j.f == nil {
+		return nil
+	}
+	_ = j.f.Sync()
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskJournalReplayLastWriteWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	j, tasks, err := openTaskJournal(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("expected empty journal, got %d tasks", len(tasks))
+	}
+
+	now := time.Now()
+	for _, rec := range []journalRecord{
+		{Task: &Task{ID: "t1", AgentID: "a1", State: StateQueued, CreatedAt: now}},
+		{Task: &Task{ID: "t2", AgentID: "a1", State: StateQueued, CreatedAt: now.Add(time.Second)}},
+		{Task: &Task{ID: "t1", AgentID: "a1", State: StateDone, CreatedAt: now}},
+		{Deleted: "t2"},
+	} {
+		if err := j.append(rec); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := j.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// Simulate a crash mid-write: a torn trailing line must be ignored.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"task":{"taskId":"t3","sta`)
+	_ = f.Close()
+
+	got, records, err := readTaskJournal(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if records != 4 {
+		t.Errorf("records = %d, want 4", records)
+	}
+	if len(got) != 1 || got[0].ID != "t1" || got[0].State != StateDone {
+		t.Fatalf("replay = %+v, want only t1 in state done", got)
+	}
+}
+
+func TestTaskJournalCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.jsonl")
+	j, _, err := openTaskJournal(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = j.close() }()
+
+	task := &Task{ID: "t1", AgentID: "a1", State: StateQueued}
+	for range 10 {
+		if err := j.append(journalRecord{Task: task}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := j.compact([]*Task{task}); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if err := j.append(journalRecord{Task: &Task{ID: "t2", AgentID: "a1", State: StateQueued}}); err != nil {
+		t.Fatalf("append after compact: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("journal has %d lines after compaction, want 2", lines)
+	}
+}
+
+func TestTaskJournalNeedsCompaction(t *testing.T) {
+	j := &taskJournal{appended: journalCompactMin - 1}
+	if j.needsCompaction(0) {
+		t.Error("should not compact below the minimum record count")
+	}
+	j.appended = journalCompactMin * 2
+	if !j.needsCompaction(10) {
+		t.Error("should compact when records far exceed live tasks")
+	}
+	if j.needsCompaction(journalCompactMin) {
+		t.Error("should not compact when most records are live")
+	}
+}
+
+func TestSchedulerJournalSurvivesRestart(t *testing.T) {
+	_, executor := newTestScheduler(t)
+	defer executor.Close()
+	port := executor.URL[strings.LastIndex(executor.URL, ":")+1:]
+
+	cfg := DefaultConfig()
+	cfg.JournalPath = filepath.Join(t.TempDir(), "scheduler", "tasks.jsonl")
+
+	s1 := New(cfg, &mockResolver{port: port})
+	s1.noAutoStart = true
+
+	queued, err := s1.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	now := timeNow()
+	s1.results.Store(&Task{
+		ID: "tsk_done", AgentID: "a1", Action: "click", State: StateDone,
+		CreatedAt: now, CompletedAt: now, Result: map[string]any{"success": true},
+	})
+	s1.results.Store(&Task{
+		ID: "tsk_running_fail", AgentID: "a1", Action: "click", TabID: "tab-1",
+		State: StateRunning, CreatedAt: now, StartedAt: now, Deadline: now.Add(time.Minute),
+	})
+	s1.results.Store(&Task{
+		ID: "tsk_running_requeue", AgentID: "a1", Action: "click", TabID: "tab-1",
+		State: StateRunning, CreatedAt: now, StartedAt: now, Deadline: now.Add(time.Minute),
+		OnRestart: RestartRequeue,
+	})
+	s1.Stop()
+
+	s2 := New(cfg, &mockResolver{port: port})
+	defer s2.Stop()
+
+	done := s2.GetTask("tsk_done")
+	if done == nil || done.State != StateDone {
+		t.Fatalf("terminal result not replayed: %+v", done)
+	}
+
+	interrupted := s2.GetTask("tsk_running_fail")
+	if interrupted == nil || interrupted.GetState() != StateFailed || interrupted.Error != errInterruptedByRestart {
+		t.Fatalf("running task with default policy should fail on restart, got %+v", interrupted)
+	}
+
+	for _, id := range []string{queued.ID, "tsk_running_requeue"} {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			task := s2.GetTask(id)
+			if task == nil {
+				t.Fatalf("task %s missing after restart", id)
+			}
+			if task.GetState() == StateDone {
+				break
+			}
+			if task.GetState().IsTerminal() || time.Now().After(deadline) {
+				t.Fatalf("task %s should run to completion after restart, got state %q (%s)", id, task.GetState(), task.Error)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestSchedulerJournalDropsExpiredResults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ResultTTL = time.Minute
+	cfg.JournalPath = filepath.Join(t.TempDir(), "tasks.jsonl")
+
+	s1 := New(cfg, &mockResolver{port: "1"})
+	s1.noAutoStart = true
+	old := timeNow().Add(-time.Hour)
+	s1.results.Store(&Task{ID: "tsk_old", AgentID: "a1", State: StateDone, CreatedAt: old, CompletedAt: old})
+	s1.Stop()
+
+	s2 := New(cfg, &mockResolver{port: "1"})
+	defer s2.Stop()
+	if got := s2.GetTask("tsk_old"); got != nil {
+		t.Fatalf("expired result should not be replayed, got %+v", got)
+	}
+}
+
+func TestSubmitRequestRejectsUnknownRestartPolicy(t *testing.T) {
+	req := SubmitRequest{AgentID: "a1", Action: "click", OnRestart: "retry"}
+	if err := req.Validate(); err == nil {
+		t.Fatal("expected validation error for unknown onRestart")
+	}
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// errInterruptedByRestart is recorded on tasks that were mid-flight when the
+// scheduler went down and whose restart policy is RestartFail.
+const errInterruptedByRestart = "interrupted by scheduler restart"
+
+// restore rebuilds queue and result state from journal snapshots. Terminal
+// results are kept until their TTL runs out; queued tasks go back into the
+// queue; assigned/running tasks are requeued or failed per their policy.
+// The journal is compacted afterwards so it reflects the restored state.
+func (s *Scheduler) restore(tasks []*Task) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	now := timeNow()
+	cutoff := now.Add(-s.cfg.ResultTTL)
+	var kept, requeued, failed int
+	var waiting []*Task
+	for _, t := range tasks {
+		state := t.State
+		switch {
+		case state == StateWaiting:
+			waiting = append(waiting, t)
+
+		case state.IsTerminal():
+			if !t.CompletedAt.IsZero() && t.CompletedAt.Before(cutoff) {
+				continue
+			}
+			s.results.Store(t)
+			kept++
+
+		case state == StateQueued:
+			if s.requeueRestored(t, now) {
+				requeued++
+			} else {
+				failed++
+			}
+
+		default:
+			policy := t.OnRestart
+			if policy == "" {
+				policy = s.cfg.RestartPolicy
+			}
+			if policy == RestartRequeue {
+				t.State = StateQueued
+				t.StartedAt = time.Time{}
+				if s.requeueRestored(t, now) {
+					requeued++
+				} else {
+					failed++
+				}
+				continue
+			}
+			s.failRestored(t, now, errInterruptedByRestart)
+			failed++
+		}
+	}
+
+	s.restoreWaiting(waiting)
+
+	s.results.compactJournal()
+	slog.Info("scheduler journal replayed", "results", kept, "requeued", requeued, "failed", failed, "waiting", len(waiting))
+
+	if requeued > 0 || len(waiting) > 0 {
+		s.ensureRunning()
+	}
+}
+
+// restoreWaiting re-registers tasks that were still waiting on dependencies.
+// All of them are made live first so that a waiting task whose parent is
+// also waiting finds it.
+func (s *Scheduler) restoreWaiting(tasks []*Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	s.liveMu.Lock()
+	for _, t := range tasks {
+		s.live[t.ID] = t
+	}
+	s.liveMu.Unlock()
+	for _, t := range tasks {
+		s.results.Store(t)
+		s.waitFor(t)
+	}
+}
+
+// requeueRestored puts a replayed task back in the queue. Tasks whose
+// deadline passed while the scheduler was down, or that no longer fit the
+// queue limits, are failed instead. Returns true when the task was queued.
+func (s *Scheduler) requeueRestored(t *Task, now time.Time) bool {
+	if !t.Deadline.IsZero() && t.Deadline.Before(now) {
+		s.metrics.recordExpire()
+		s.failRestored(t, now, "deadline exceeded while queued")
+		return false
+	}
+
+	pos, err := s.queue.Enqueue(t)
+	if err != nil {
+		s.failRestored(t, now, "requeue after restart: "+err.Error())
+		return false
+	}
+	t.Position = pos
+
+	s.liveMu.Lock()
+	s.live[t.ID] = t
+	s.liveMu.Unlock()
+
+	s.results.Store(t)
+	s.metrics.recordSubmit(t.AgentID)
+	return true
+}
+
+// failRestored terminates a replayed task without going through SetState:
+// assigned/queued → failed is not a transition a live task can make, but a
+// task replayed after a crash is no longer live.
+func (s *Scheduler) failRestored(t *Task, now time.Time, reason string) {
+	t.State = StateFailed
+	t.Error = reason
+	t.CompletedAt = now
+	if !t.StartedAt.IsZero() {
+		t.LatencyMs = now.Sub(t.StartedAt).Milliseconds()
+	}
+	s.results.Store(t)
+	s.metrics.recordFail(t.AgentID)
+	s.recordScheduleOutcome(t)
+	s.webhooks.fire(t)
+	slog.Info("task failed on restore", "task", t.ID, "agent", t.AgentID, "reason", reason)
+}
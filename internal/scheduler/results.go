@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"log/slog"
 	"sync"
 	"time"
 )
@@ -11,6 +12,10 @@ type ResultStore struct {
 	tasks   map[string]*Task
 	ttl     time.Duration
 	closeCh chan struct{}
+
+	// journal, when attached, receives every stored snapshot so results
+	// survive a restart. Guarded by mu.
+	journal *taskJournal
 }
 
 // NewResultStore creates a store that evicts terminal tasks after ttl.
@@ -60,6 +65,7 @@ func (rs *ResultStore) Store(t *Task) {
 	snap := t.Snapshot()
 	rs.mu.Lock()
 	rs.tasks[snap.ID] = snap
+	rs.appendLocked(journalRecord{Task: snap})
 	rs.mu.Unlock()
 }
 
@@ -102,9 +108,65 @@ func (rs *ResultStore) List(agentID string, states []TaskState) []*Task {
 func (rs *ResultStore) Delete(taskID string) {
 	rs.mu.Lock()
 	delete(rs.tasks, taskID)
+	rs.appendLocked(journalRecord{Deleted: taskID})
 	rs.mu.Unlock()
 }
 
+// attachJournal starts mirroring stores into j. Snapshots already in the
+// store are not re-written; callers compact afterwards if they need that.
+func (rs *ResultStore) attachJournal(j *taskJournal) {
+	rs.mu.Lock()
+	rs.journal = j
+	rs.mu.Unlock()
+}
+
+// compactJournal rewrites the journal to hold exactly the current contents
+// of the store, dropping evicted results and superseded snapshots.
+func (rs *ResultStore) compactJournal() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.compactLocked()
+}
+
+// closeJournal flushes and detaches the journal.
+func (rs *ResultStore) closeJournal() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.journal == nil {
+		return
+	}
+	if err := rs.journal.close(); err != nil {
+		slog.Warn("scheduler journal close failed", "err", err)
+	}
+	rs.journal = nil
+}
+
+func (rs *ResultStore) appendLocked(rec journalRecord) {
+	if rs.journal == nil {
+		return
+	}
+	if err := rs.journal.append(rec); err != nil {
+		slog.Warn("scheduler journal append failed", "err", err)
+		return
+	}
+	if rs.journal.needsCompaction(len(rs.tasks)) {
+		rs.compactLocked()
+	}
+}
+
+func (rs *ResultStore) compactLocked() {
+	if rs.journal == nil {
+		return
+	}
+	tasks := make([]*Task, 0, len(rs.tasks))
+	for _, t := range rs.tasks {
+		tasks = append(tasks, t)
+	}
+	if err := rs.journal.compact(tasks); err != nil {
+		slog.Warn("scheduler journal compaction failed", "err", err)
+	}
+}
+
 func (rs *ResultStore) evict() {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
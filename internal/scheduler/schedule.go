@@ -0,0 +1,552 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	mrand "math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// scheduleTick is how often the schedule loop checks for due schedules.
+	scheduleTick = time.Second
+	// scheduleRecentRuns is how many run outcomes each schedule keeps.
+	scheduleRecentRuns = 10
+	// minScheduleInterval is the shortest accepted interval.
+	minScheduleInterval = time.Second
+	// defaultScheduleTaskTimeout is the deadline given to each created task
+	// when the schedule does not set taskTimeoutSec, matching POST /tasks.
+	defaultScheduleTaskTimeout = 60 * time.Second
+)
+
+// OverlapPolicy decides what a schedule does when it fires while a task it
+// created earlier is still queued or running.
+type OverlapPolicy string
+
+const (
+	OverlapSkip    OverlapPolicy = "skip"    // don't create a task this time
+	OverlapQueue   OverlapPolicy = "queue"   // create it anyway; it queues behind the previous one
+	OverlapReplace OverlapPolicy = "replace" // cancel the previous task, then create a new one
+)
+
+// IsValid reports whether p is a known policy. Empty means skip.
+func (p OverlapPolicy) IsValid() bool {
+	switch p {
+	case "", OverlapSkip, OverlapQueue, OverlapReplace:
+		return true
+	}
+	return false
+}
+
+// ScheduleRunSkipped is the outcome state recorded when a fire was skipped by
+// the overlap policy.
+const ScheduleRunSkipped TaskState = "skipped"
+
+// ScheduleRun is the outcome of one schedule fire.
+type ScheduleRun struct {
+	TaskID      string    `json:"taskId,omitempty"`
+	FiredAt     time.Time `json:"firedAt"`
+	State       TaskState `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	CompletedAt time.Time `json:"completedAt,omitempty"`
+}
+
+// ScheduleRequest is the JSON body for POST /schedules. Exactly one of Cron
+// and Interval is required. Task is the task created on every fire; its
+// deadline is relative (TaskTimeoutSec) rather than absolute.
+type ScheduleRequest struct {
+	Name           string        `json:"name,omitempty"`
+	Cron           string        `json:"cron,omitempty"`
+	Interval       string        `json:"interval,omitempty"`
+	Timezone       string        `json:"timezone,omitempty"`
+	Jitter         string        `json:"jitter,omitempty"`
+	Overlap        OverlapPolicy `json:"overlap,omitempty"`
+	TaskTimeoutSec int           `json:"taskTimeoutSec,omitempty"`
+	Task           SubmitRequest `json:"task"`
+}
+
+// Schedule is a recurring task definition plus its run history.
+type Schedule struct {
+	ID             string        `json:"scheduleId"`
+	Name           string        `json:"name,omitempty"`
+	Cron           string        `json:"cron,omitempty"`
+	Interval       string        `json:"interval,omitempty"`
+	Timezone       string        `json:"timezone,omitempty"`
+	Jitter         string        `json:"jitter,omitempty"`
+	Overlap        OverlapPolicy `json:"overlap"`
+	TaskTimeoutSec int           `json:"taskTimeoutSec,omitempty"`
+	Task           SubmitRequest `json:"task"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	LastRunAt      time.Time     `json:"lastRunAt,omitempty"`
+	NextRunAt      time.Time     `json:"nextRunAt,omitempty"`
+	Runs           int           `json:"runs"`
+	Skipped        int           `json:"skipped"`
+	ActiveTaskIDs  []string      `json:"activeTaskIds,omitempty"`
+	RecentRuns     []ScheduleRun `json:"recentRuns,omitempty"`
+
+	spec   scheduleSpec
+	jitter time.Duration
+	// base is the un-jittered time of the upcoming fire; the one after it is
+	// computed from base so jitter never accumulates.
+	base time.Time
+}
+
+// scheduleSpec yields successive fire times.
+type scheduleSpec interface {
+	next(after time.Time) time.Time
+}
+
+type intervalSpec time.Duration
+
+func (d intervalSpec) next(after time.Time) time.Time {
+	return after.Add(time.Duration(d))
+}
+
+// compile validates the timing fields and prepares the schedule's spec.
+func (sc *Schedule) compile() error {
+	switch {
+	case sc.Cron != "" && sc.Interval != "":
+		return fmt.Errorf("set either cron or interval, not both")
+	case sc.Cron != "":
+		loc := time.UTC
+		if sc.Timezone != "" {
+			var err error
+			if loc, err = time.LoadLocation(sc.Timezone); err != nil {
+				return fmt.Errorf("invalid timezone %q: %w", sc.Timezone, err)
+			}
+		}
+		spec, err := parseCron(sc.Cron, loc)
+		if err != nil {
+			return err
+		}
+		if spec.next(timeNow()).IsZero() {
+			return fmt.Errorf("cron expression %q never fires", sc.Cron)
+		}
+		sc.spec = spec
+	case sc.Interval != "":
+		if sc.Timezone != "" {
+			return fmt.Errorf("timezone only applies to cron schedules")
+		}
+		d, err := time.ParseDuration(sc.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q: %w", sc.Interval, err)
+		}
+		if d < minScheduleInterval {
+			return fmt.Errorf("interval must be at least %s", minScheduleInterval)
+		}
+		sc.spec = intervalSpec(d)
+	default:
+		return fmt.Errorf("missing required field 'cron' or 'interval'")
+	}
+
+	sc.jitter = 0
+	if sc.Jitter != "" {
+		d, err := time.ParseDuration(sc.Jitter)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid jitter %q", sc.Jitter)
+		}
+		sc.jitter = d
+	}
+	if !sc.Overlap.IsValid() {
+		return fmt.Errorf("invalid overlap %q (must be skip, queue, or replace)", sc.Overlap)
+	}
+	if sc.Overlap == "" {
+		sc.Overlap = OverlapSkip
+	}
+	if sc.TaskTimeoutSec < 0 {
+		return fmt.Errorf("taskTimeoutSec must be >= 0")
+	}
+	if sc.Task.Deadline != "" {
+		return fmt.Errorf("task.deadline is not allowed on schedules; use taskTimeoutSec")
+	}
+	if err := sc.Task.Validate(); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+	return nil
+}
+
+// advance sets the next fire time after now. Fires follow on from the
+// previous base time so intervals keep their cadence; after a long gap (a
+// restart, a stalled loop) missed fires are dropped rather than replayed.
+func (sc *Schedule) advance(now time.Time) {
+	if sc.base.IsZero() {
+		sc.base = now
+	}
+	next := sc.spec.next(sc.base)
+	if !next.IsZero() && !next.After(now) {
+		next = sc.spec.next(now)
+	}
+	sc.base = next
+	sc.NextRunAt = sc.base
+	if sc.jitter > 0 && !sc.base.IsZero() {
+		sc.NextRunAt = sc.base.Add(mrand.N(sc.jitter))
+	}
+}
+
+func (sc *Schedule) recordRun(run ScheduleRun) {
+	sc.RecentRuns = append(sc.RecentRuns, run)
+	if n := len(sc.RecentRuns); n > scheduleRecentRuns {
+		sc.RecentRuns = append([]ScheduleRun(nil), sc.RecentRuns[n-scheduleRecentRuns:]...)
+	}
+}
+
+func (sc *Schedule) snapshot() *Schedule {
+	cp := *sc
+	cp.ActiveTaskIDs = append([]string(nil), sc.ActiveTaskIDs...)
+	cp.RecentRuns = append([]ScheduleRun(nil), sc.RecentRuns...)
+	return &cp
+}
+
+// scheduleStore holds schedules and, when path is set, mirrors them to a
+// JSON file (temp file + rename) after every change.
+type scheduleStore struct {
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+	path      string
+}
+
+type persistedSchedules struct {
+	SavedAt   time.Time   `json:"savedAt"`
+	Schedules []*Schedule `json:"schedules"`
+}
+
+func newScheduleStore(path string) *scheduleStore {
+	return &scheduleStore{schedules: make(map[string]*Schedule), path: path}
+}
+
+// load reads persisted schedules. Schedules that no longer compile are
+// dropped with a warning. Missed fires are not replayed: each schedule
+// resumes at its next occurrence after now.
+func (ss *scheduleStore) load(now time.Time) error {
+	if ss.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(ss.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read schedules: %w", err)
+	}
+	var persisted persistedSchedules
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return fmt.Errorf("decode schedules: %w", err)
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, sc := range persisted.Schedules {
+		if sc == nil || sc.ID == "" {
+			continue
+		}
+		if err := sc.compile(); err != nil {
+			slog.Warn("dropping persisted schedule", "schedule", sc.ID, "err", err)
+			continue
+		}
+		sc.advance(now)
+		ss.schedules[sc.ID] = sc
+	}
+	return nil
+}
+
+// saveLocked writes every schedule to disk. Caller must hold ss.mu.
+func (ss *scheduleStore) saveLocked() {
+	if ss.path == "" {
+		return
+	}
+	persisted := persistedSchedules{SavedAt: timeNow().UTC(), Schedules: ss.listLocked()}
+	data, err := json.MarshalIndent(persisted, "", "  ")
+	if err != nil {
+		slog.Warn("encode schedules failed", "err", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(ss.path), 0700); err != nil {
+		slog.Warn("create schedules dir failed", "path", ss.path, "err", err)
+		return
+	}
+	tmpPath := ss.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		slog.Warn("write schedules failed", "path", ss.path, "err", err)
+		return
+	}
+	if err := os.Rename(tmpPath, ss.path); err != nil {
+		slog.Warn("replace schedules file failed", "path", ss.path, "err", err)
+	}
+}
+
+func (ss *scheduleStore) listLocked() []*Schedule {
+	out := make([]*Schedule, 0, len(ss.schedules))
+	for _, sc := range ss.schedules {
+		out = append(out, sc.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out
+}
+
+func (ss *scheduleStore) list() []*Schedule {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.listLocked()
+}
+
+func (ss *scheduleStore) get(id string) *Schedule {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if sc := ss.schedules[id]; sc != nil {
+		return sc.snapshot()
+	}
+	return nil
+}
+
+func (ss *scheduleStore) len() int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return len(ss.schedules)
+}
+
+// CreateSchedule validates and registers a recurring task.
+func (s *Scheduler) CreateSchedule(req ScheduleRequest) (*Schedule, error) {
+	now := timeNow()
+	sc := &Schedule{
+		ID:             generateScheduleID(),
+		Name:           req.Name,
+		Cron:           req.Cron,
+		Interval:       req.Interval,
+		Timezone:       req.Timezone,
+		Jitter:         req.Jitter,
+		Overlap:        req.Overlap,
+		TaskTimeoutSec: req.TaskTimeoutSec,
+		Task:           req.Task,
+		CreatedAt:      now,
+	}
+	if err := sc.compile(); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+	sc.advance(now)
+
+	s.schedules.mu.Lock()
+	s.schedules.schedules[sc.ID] = sc
+	s.schedules.saveLocked()
+	snap := sc.snapshot()
+	s.schedules.mu.Unlock()
+
+	if !s.noAutoStart {
+		s.ensureRunning()
+	}
+	slog.Info("schedule created", "schedule", sc.ID, "cron", sc.Cron, "interval", sc.Interval, "overlap", sc.Overlap, "next", sc.NextRunAt)
+	return snap, nil
+}
+
+// GetSchedule returns a schedule snapshot, or nil if unknown.
+func (s *Scheduler) GetSchedule(id string) *Schedule {
+	return s.schedules.get(id)
+}
+
+// ListSchedules returns every schedule, oldest first.
+func (s *Scheduler) ListSchedules() []*Schedule {
+	return s.schedules.list()
+}
+
+// DeleteSchedule removes a schedule. Tasks it already created are left to
+// finish.
+func (s *Scheduler) DeleteSchedule(id string) error {
+	s.schedules.mu.Lock()
+	defer s.schedules.mu.Unlock()
+	if _, ok := s.schedules.schedules[id]; !ok {
+		return fmt.Errorf("schedule %q not found", id)
+	}
+	delete(s.schedules.schedules, id)
+	s.schedules.saveLocked()
+	slog.Info("schedule deleted", "schedule", id)
+	return nil
+}
+
+func (s *Scheduler) scheduleLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.runDueSchedules(timeNow())
+		}
+	}
+}
+
+// scheduleFire is one due schedule, captured under the store lock and acted
+// on outside it (Submit and Cancel take scheduler locks and may call back
+// into recordScheduleOutcome).
+type scheduleFire struct {
+	id      string
+	overlap OverlapPolicy
+	active  []string
+	req     SubmitRequest
+	timeout time.Duration
+}
+
+// runDueSchedules fires every schedule whose next run is at or before now.
+func (s *Scheduler) runDueSchedules(now time.Time) {
+	var due []scheduleFire
+	s.schedules.mu.Lock()
+	for _, sc := range s.schedules.schedules {
+		if sc.NextRunAt.IsZero() || sc.NextRunAt.After(now) {
+			continue
+		}
+		timeout := defaultScheduleTaskTimeout
+		if sc.TaskTimeoutSec > 0 {
+			timeout = time.Duration(sc.TaskTimeoutSec) * time.Second
+		}
+		due = append(due, scheduleFire{
+			id:      sc.ID,
+			overlap: sc.Overlap,
+			active:  append([]string(nil), sc.ActiveTaskIDs...),
+			req:     sc.Task,
+			timeout: timeout,
+		})
+		sc.LastRunAt = now
+		sc.advance(now)
+	}
+	s.schedules.mu.Unlock()
+
+	for _, f := range due {
+		s.fireSchedule(f, now)
+	}
+}
+
+func (s *Scheduler) fireSchedule(f scheduleFire, now time.Time) {
+	var running []string
+	for _, id := range f.active {
+		if t := s.GetTask(id); t != nil && !t.GetState().IsTerminal() {
+			running = append(running, id)
+		}
+	}
+
+	run := ScheduleRun{FiredAt: now}
+	if len(running) > 0 {
+		switch f.overlap {
+		case OverlapSkip, "":
+			run.State = ScheduleRunSkipped
+			run.Error = fmt.Sprintf("previous run %s still active", running[0])
+			s.finishScheduleFire(f.id, run, true)
+			slog.Info("schedule fire skipped", "schedule", f.id, "active", running[0])
+			return
+		case OverlapReplace:
+			for _, id := range running {
+				if err := s.Cancel(id); err != nil {
+					slog.Warn("schedule replace: cancel failed", "schedule", f.id, "task", id, "err", err)
+				}
+			}
+		}
+	}
+
+	req := f.req
+	req.Deadline = now.Add(f.timeout).Format(time.RFC3339)
+	task, err := s.submit(req, submitOptions{scheduleID: f.id})
+	if err != nil {
+		run.State = StateRejected
+		run.Error = err.Error()
+		if task != nil {
+			run.TaskID = task.ID
+		}
+		s.finishScheduleFire(f.id, run, false)
+		slog.Warn("schedule fire rejected", "schedule", f.id, "err", err)
+		return
+	}
+	run.TaskID = task.ID
+	run.State = task.GetState()
+	s.finishScheduleFire(f.id, run, false)
+	slog.Info("schedule fired", "schedule", f.id, "task", task.ID)
+}
+
+func (s *Scheduler) finishScheduleFire(id string, run ScheduleRun, skipped bool) {
+	s.schedules.mu.Lock()
+	defer s.schedules.mu.Unlock()
+	sc := s.schedules.schedules[id]
+	if sc == nil {
+		return
+	}
+	if skipped {
+		sc.Skipped++
+	} else {
+		sc.Runs++
+	}
+
+	// Drop tasks that finished or vanished (e.g. lost across a restart
+	// without the task journal) so they no longer count as overlapping.
+	active := sc.ActiveTaskIDs[:0]
+	for _, taskID := range sc.ActiveTaskIDs {
+		if t := s.GetTask(taskID); t != nil && !t.GetState().IsTerminal() {
+			active = append(active, taskID)
+		}
+	}
+	sc.ActiveTaskIDs = active
+
+	if run.TaskID != "" {
+		// A fast task may already have finished, in which case its outcome
+		// was reported before this run was recorded.
+		if t := s.GetTask(run.TaskID); t != nil {
+			snap := t.Snapshot()
+			run.State = snap.State
+			run.Error = snap.Error
+			run.CompletedAt = snap.CompletedAt
+		}
+		if !run.State.IsTerminal() {
+			sc.ActiveTaskIDs = append(sc.ActiveTaskIDs, run.TaskID)
+		}
+	}
+	sc.recordRun(run)
+	s.schedules.saveLocked()
+}
+
+// recordScheduleOutcome updates the owning schedule when a task it created
+// reaches a terminal state.
+func (s *Scheduler) recordScheduleOutcome(t *Task) {
+	if t.ScheduleID == "" {
+		return
+	}
+	snap := t.Snapshot()
+	s.schedules.mu.Lock()
+	defer s.schedules.mu.Unlock()
+	sc := s.schedules.schedules[snap.ScheduleID]
+	if sc == nil {
+		return
+	}
+	active := sc.ActiveTaskIDs[:0]
+	for _, id := range sc.ActiveTaskIDs {
+		if id != snap.ID {
+			active = append(active, id)
+		}
+	}
+	sc.ActiveTaskIDs = active
+	for i := range sc.RecentRuns {
+		if sc.RecentRuns[i].TaskID == snap.ID {
+			sc.RecentRuns[i].State = snap.State
+			sc.RecentRuns[i].Error = snap.Error
+			sc.RecentRuns[i].CompletedAt = snap.CompletedAt
+		}
+	}
+	s.schedules.saveLocked()
+}
+
+// generateScheduleID produces a random schedule ID in the format sch_XXXXXXXX.
+func generateScheduleID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("sch_%08x", time.Now().UnixNano()&0xFFFFFFFF)
+	}
+	return "sch_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,285 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useFakeClock pins timeNow for the duration of the test and returns a
+// function that moves it forward.
+func useFakeClock(t *testing.T, start time.Time) func(time.Duration) time.Time {
+	t.Helper()
+	old := timeNow
+	now := start
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = old })
+	return func(d time.Duration) time.Time {
+		now = now.Add(d)
+		return now
+	}
+}
+
+func scheduleTask() SubmitRequest {
+	return SubmitRequest{AgentID: "agent-1", Action: "click", TabID: "tab-1"}
+}
+
+func TestCreateScheduleValidation(t *testing.T) {
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	bad := []ScheduleRequest{
+		{Task: scheduleTask()},
+		{Cron: "* * * * *", Interval: "1m", Task: scheduleTask()},
+		{Cron: "bogus", Task: scheduleTask()},
+		{Cron: "0 0 30 2 *", Task: scheduleTask()},
+		{Cron: "* * * * *", Timezone: "Mars/Olympus", Task: scheduleTask()},
+		{Interval: "1m", Timezone: "UTC", Task: scheduleTask()},
+		{Interval: "10ms", Task: scheduleTask()},
+		{Interval: "1m", Jitter: "-1s", Task: scheduleTask()},
+		{Interval: "1m", Overlap: "later", Task: scheduleTask()},
+		{Interval: "1m", TaskTimeoutSec: -1, Task: scheduleTask()},
+		{Interval: "1m", Task: SubmitRequest{AgentID: "agent-1"}},
+		{Interval: "1m", Task: SubmitRequest{AgentID: "agent-1", Action: "click", Deadline: "2030-01-01T00:00:00Z"}},
+	}
+	for i, req := range bad {
+		if _, err := s.CreateSchedule(req); err == nil {
+			t.Errorf("case %d: expected validation error for %+v", i, req)
+		}
+	}
+	if n := len(s.ListSchedules()); n != 0 {
+		t.Errorf("invalid schedules should not be stored, got %d", n)
+	}
+}
+
+func TestCreateScheduleNextRun(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 7, 0, 0, time.UTC)
+	useFakeClock(t, start)
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	sc, err := s.CreateSchedule(ScheduleRequest{Cron: "*/15 * * * *", Task: scheduleTask()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sc.ID, "sch_") {
+		t.Errorf("unexpected id %q", sc.ID)
+	}
+	if sc.Overlap != OverlapSkip {
+		t.Errorf("overlap should default to skip, got %q", sc.Overlap)
+	}
+	if want := time.Date(2026, 5, 1, 10, 15, 0, 0, time.UTC); !sc.NextRunAt.Equal(want) {
+		t.Errorf("nextRunAt = %s, want %s", sc.NextRunAt, want)
+	}
+
+	jittered, err := s.CreateSchedule(ScheduleRequest{Interval: "1m", Jitter: "30s", Task: scheduleTask()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, hi := start.Add(time.Minute), start.Add(time.Minute+30*time.Second)
+	if jittered.NextRunAt.Before(lo) || !jittered.NextRunAt.Before(hi) {
+		t.Errorf("jittered nextRunAt %s outside [%s, %s)", jittered.NextRunAt, lo, hi)
+	}
+}
+
+func TestScheduleFiresAndRecordsOutcome(t *testing.T) {
+	advance := useFakeClock(t, time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	sc, err := s.CreateSchedule(ScheduleRequest{Interval: "1m", TaskTimeoutSec: 30, Task: scheduleTask()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.runDueSchedules(advance(30 * time.Second))
+	if got := s.GetSchedule(sc.ID); got.Runs != 0 {
+		t.Fatalf("schedule fired early: %+v", got)
+	}
+
+	firedAt := advance(30 * time.Second)
+	s.runDueSchedules(firedAt)
+	got := s.GetSchedule(sc.ID)
+	if got.Runs != 1 || len(got.RecentRuns) != 1 || len(got.ActiveTaskIDs) != 1 {
+		t.Fatalf("expected one active run, got %+v", got)
+	}
+	if !got.LastRunAt.Equal(firedAt) || !got.NextRunAt.Equal(firedAt.Add(time.Minute)) {
+		t.Errorf("lastRunAt=%s nextRunAt=%s", got.LastRunAt, got.NextRunAt)
+	}
+	taskID := got.RecentRuns[0].TaskID
+	task := s.GetTask(taskID)
+	if task == nil || task.ScheduleID != sc.ID {
+		t.Fatalf("task %q should carry scheduleId %q", taskID, sc.ID)
+	}
+	if want := firedAt.Add(30 * time.Second); !task.Deadline.Equal(want) {
+		t.Errorf("task deadline = %s, want %s", task.Deadline, want)
+	}
+
+	if err := s.Cancel(taskID); err != nil {
+		t.Fatal(err)
+	}
+	got = s.GetSchedule(sc.ID)
+	if len(got.ActiveTaskIDs) != 0 {
+		t.Errorf("finished task should leave the active set: %v", got.ActiveTaskIDs)
+	}
+	if got.RecentRuns[0].State != StateCancelled {
+		t.Errorf("run state = %q, want cancelled", got.RecentRuns[0].State)
+	}
+}
+
+func TestScheduleOverlapPolicies(t *testing.T) {
+	tests := []struct {
+		overlap    OverlapPolicy
+		wantRuns   int
+		wantSkip   int
+		wantActive int
+		wantFirst  TaskState
+	}{
+		{OverlapSkip, 1, 1, 1, StateQueued},
+		{OverlapQueue, 2, 0, 2, StateQueued},
+		{OverlapReplace, 2, 0, 1, StateCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.overlap), func(t *testing.T) {
+			advance := useFakeClock(t, time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+			s, executor := newTestScheduler(t)
+			defer executor.Close()
+
+			sc, err := s.CreateSchedule(ScheduleRequest{Interval: "1m", Overlap: tt.overlap, Task: scheduleTask()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Workers are not running, so the first task stays queued.
+			s.runDueSchedules(advance(time.Minute))
+			s.runDueSchedules(advance(time.Minute))
+
+			got := s.GetSchedule(sc.ID)
+			if got.Runs != tt.wantRuns || got.Skipped != tt.wantSkip || len(got.ActiveTaskIDs) != tt.wantActive {
+				t.Fatalf("runs=%d skipped=%d active=%v", got.Runs, got.Skipped, got.ActiveTaskIDs)
+			}
+			if len(got.RecentRuns) != 2 {
+				t.Fatalf("expected 2 recent runs, got %d", len(got.RecentRuns))
+			}
+			if first := got.RecentRuns[0].State; first != tt.wantFirst {
+				t.Errorf("first run state = %q, want %q", first, tt.wantFirst)
+			}
+			if tt.overlap == OverlapSkip && got.RecentRuns[1].State != ScheduleRunSkipped {
+				t.Errorf("second run should be skipped, got %q", got.RecentRuns[1].State)
+			}
+		})
+	}
+}
+
+func TestScheduleRecentRunsCapped(t *testing.T) {
+	advance := useFakeClock(t, time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	sc, err := s.CreateSchedule(ScheduleRequest{Interval: "1m", Task: scheduleTask()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range scheduleRecentRuns + 5 {
+		s.runDueSchedules(advance(time.Minute))
+	}
+	got := s.GetSchedule(sc.ID)
+	if len(got.RecentRuns) != scheduleRecentRuns {
+		t.Errorf("recent runs = %d, want %d", len(got.RecentRuns), scheduleRecentRuns)
+	}
+	if got.Runs+got.Skipped != scheduleRecentRuns+5 {
+		t.Errorf("runs=%d skipped=%d", got.Runs, got.Skipped)
+	}
+}
+
+func TestSchedulesPersistAcrossRestart(t *testing.T) {
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	advance := useFakeClock(t, start)
+	path := filepath.Join(t.TempDir(), "schedules.json")
+
+	cfg := DefaultConfig()
+	cfg.SchedulesPath = path
+	s1 := New(cfg, &mockResolver{port: "1"})
+	s1.noAutoStart = true
+	sc, err := s1.CreateSchedule(ScheduleRequest{Name: "hourly", Cron: "0 * * * *", Overlap: OverlapQueue, Task: scheduleTask()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1.Stop()
+
+	// Come back after the 11:00 fire was missed; it is not replayed.
+	advance(90 * time.Minute)
+	s2 := New(cfg, &mockResolver{port: "1"})
+	defer s2.Stop()
+
+	got := s2.GetSchedule(sc.ID)
+	if got == nil {
+		t.Fatal("schedule not restored")
+	}
+	if got.Name != "hourly" || got.Overlap != OverlapQueue || got.Task.Action != "click" {
+		t.Errorf("restored schedule mismatch: %+v", got)
+	}
+	if want := start.Add(2 * time.Hour); !got.NextRunAt.Equal(want) {
+		t.Errorf("nextRunAt = %s, want %s", got.NextRunAt, want)
+	}
+
+	if err := s2.DeleteSchedule(sc.ID); err != nil {
+		t.Fatal(err)
+	}
+	s3 := New(cfg, &mockResolver{port: "1"})
+	defer s3.Stop()
+	if n := len(s3.ListSchedules()); n != 0 {
+		t.Errorf("deleted schedule came back: %d schedules", n)
+	}
+}
+
+func TestHandlerSchedules(t *testing.T) {
+	_, mux, executor := setupHandlerTest(t)
+	defer executor.Close()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/schedules", strings.NewReader(`{"interval":"5m","task":{"agentId":"a","action":"click"}}`)))
+	if w.Code != 201 {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created Schedule
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.NextRunAt.IsZero() {
+		t.Fatalf("unexpected create response: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/schedules", strings.NewReader(`{"cron":"nope","task":{"agentId":"a","action":"click"}}`)))
+	if w.Code != 400 {
+		t.Errorf("invalid cron: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/schedules/"+created.ID, nil))
+	if w.Code != 200 {
+		t.Errorf("get: expected 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/scheduler/stats", nil))
+	if !strings.Contains(w.Body.String(), created.ID) {
+		t.Errorf("stats should list schedules: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/schedules/"+created.ID, nil))
+	if w.Code != 200 {
+		t.Errorf("delete: expected 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/schedules/"+created.ID, nil))
+	if w.Code != 404 {
+		t.Errorf("get deleted: expected 404, got %d", w.Code)
+	}
+}
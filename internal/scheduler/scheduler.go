@@ -26,6 +26,16 @@ type Config struct {
 	WorkerCount       int           `json:"workerCount"`
 	MaxBatchSize      int           `json:"maxBatchSize"`
 	WatcherInterval   time.Duration `json:"watcherInterval"`
+
+	// JournalPath enables the on-disk task journal when non-empty. Queued
+	// tasks and unexpired results are replayed from it on startup.
+	JournalPath string `json:"journalPath,omitempty"`
+	// RestartPolicy applies to tasks that were assigned or running when the
+	// scheduler stopped, unless the task sets its own OnRestart.
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	// SchedulesPath is where recurring schedules are persisted. Schedules are
+	// kept in memory only when empty.
+	SchedulesPath string `json:"schedulesPath,omitempty"`
 }
 
 // DefaultConfig returns safe defaults.
@@ -40,6 +50,7 @@ func DefaultConfig() Config {
 		WorkerCount:       4,
 		MaxBatchSize:      50,
 		WatcherInterval:   30 * time.Second,
+		RestartPolicy:     RestartFail,
 	}
 }
 
@@ -58,6 +69,14 @@ type Scheduler struct {
 
 	webhooks *webhookDispatcher
 
+	schedules *scheduleStore
+
+	// waiting holds tasks blocked on dependencies; dependents maps a parent
+	// task ID to the waiting tasks that need it.
+	waiting    map[string]*waitingTask
+	dependents map[string][]string
+	depsMu     sync.Mutex
+
 	cancels   map[string]context.CancelFunc
 	cancelsMu sync.Mutex
 
@@ -94,18 +113,44 @@ func New(cfg Config, resolver InstanceResolver) *Scheduler {
 	if cfg.WatcherInterval <= 0 {
 		cfg.WatcherInterval = 30 * time.Second
 	}
+	if cfg.RestartPolicy == "" || !cfg.RestartPolicy.IsValid() {
+		cfg.RestartPolicy = RestartFail
+	}
+
+	s := &Scheduler{
+		cfg:        cfg,
+		queue:      NewTaskQueue(cfg.MaxQueueSize, cfg.MaxPerAgent),
+		results:    NewResultStore(cfg.ResultTTL),
+		executor:   &actionEndpointExecutor{resolver: resolver, client: &http.Client{Timeout: 60 * time.Second}},
+		metrics:    newMetrics(),
+		live:       make(map[string]*Task),
+		cancels:    make(map[string]context.CancelFunc),
+		waiting:    make(map[string]*waitingTask),
+		dependents: make(map[string][]string),
+		stopCh:     make(chan struct{}),
+		webhooks:   newWebhookDispatcher(16),
+	}
+
+	// Schedules load before the journal replay so tasks failed during the
+	// replay are reported to the schedule that created them.
+	s.schedules = newScheduleStore(cfg.SchedulesPath)
+	if err := s.schedules.load(timeNow()); err != nil {
+		slog.Warn("scheduler schedules unavailable", "path", cfg.SchedulesPath, "err", err)
+	}
 
-	return &Scheduler{
-		cfg:      cfg,
-		queue:    NewTaskQueue(cfg.MaxQueueSize, cfg.MaxPerAgent),
-		results:  NewResultStore(cfg.ResultTTL),
-		executor: &actionEndpointExecutor{resolver: resolver, client: &http.Client{Timeout: 60 * time.Second}},
-		metrics:  newMetrics(),
-		live:     make(map[string]*Task),
-		cancels:  make(map[string]context.CancelFunc),
-		stopCh:   make(chan struct{}),
-		webhooks: newWebhookDispatcher(16),
+	if cfg.JournalPath != "" {
+		journal, tasks, err := openTaskJournal(cfg.JournalPath)
+		if err != nil {
+			slog.Warn("scheduler journal unavailable, running in-memory only", "path", cfg.JournalPath, "err", err)
+		} else {
+			s.results.attachJournal(journal)
+			s.restore(tasks)
+		}
 	}
+	if s.schedules.len() > 0 {
+		s.ensureRunning()
+	}
+	return s
 }
 
 // Start launches workers and the deadline reaper. If Start is not called,
@@ -126,11 +171,15 @@ func (s *Scheduler) ensureRunning() {
 		s.wg.Add(1)
 		go s.deadlineReaper()
 
+		s.wg.Add(1)
+		go s.scheduleLoop()
+
 		slog.Info("scheduler started", "workers", s.cfg.WorkerCount)
 	})
 }
 
-// Stop gracefully shuts down the scheduler. Queued tasks are cancelled.
+// Stop gracefully shuts down the scheduler. Queued tasks are cancelled,
+// unless the journal is enabled, in which case they are kept for replay.
 func (s *Scheduler) Stop() {
 	s.stopOnce.Do(func() {
 		slog.Info("scheduler stopping")
@@ -138,9 +187,12 @@ func (s *Scheduler) Stop() {
 		s.wg.Wait()
 		s.results.Stop()
 
+		// With a journal, leftover tasks stay queued on disk and are replayed
+		// on the next start instead of being cancelled.
+		persistent := s.cfg.JournalPath != ""
 		s.liveMu.Lock()
 		for id, t := range s.live {
-			if !t.GetState().IsTerminal() {
+			if !persistent && !t.GetState().IsTerminal() {
 				_ = t.SetState(StateCancelled)
 				t.Error = "scheduler shutdown"
 				s.results.Store(t)
@@ -148,13 +200,28 @@ func (s *Scheduler) Stop() {
 			delete(s.live, id)
 		}
 		s.liveMu.Unlock()
+		s.results.closeJournal()
 
 		slog.Info("scheduler stopped")
 	})
 }
 
-// Submit creates a new task from the request and enqueues it.
+// Submit creates a new task from the request and enqueues it. Tasks with
+// dependsOn are held in the waiting state until their parents finish.
 func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
+	return s.submit(req, submitOptions{})
+}
+
+// submitOptions carries the task fields that are not part of the public
+// request body: the schedule or batch that created the task.
+type submitOptions struct {
+	scheduleID string
+	batchID    string
+	key        string
+	onFailure  FailurePolicy
+}
+
+func (s *Scheduler) submit(req SubmitRequest, opts submitOptions) (*Task, error) {
 	if !s.noAutoStart {
 		s.ensureRunning()
 	}
@@ -162,6 +229,11 @@ func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid task: %w", err)
 	}
+	for _, id := range req.DependsOn {
+		if s.GetTask(id) == nil {
+			return nil, fmt.Errorf("invalid task: unknown dependency %q", id)
+		}
+	}
 
 	now := timeNow()
 	deadline := now.Add(60 * time.Second)
@@ -189,6 +261,25 @@ func (s *Scheduler) Submit(req SubmitRequest) (*Task, error) {
 		Deadline:    deadline,
 		CreatedAt:   now,
 		CallbackURL: req.CallbackURL,
+		OnRestart:   req.OnRestart,
+		ScheduleID:  opts.scheduleID,
+		DependsOn:   append([]string(nil), req.DependsOn...),
+		BatchID:     opts.batchID,
+		Key:         opts.key,
+		OnFailure:   opts.onFailure,
+	}
+
+	if len(t.DependsOn) > 0 {
+		t.State = StateWaiting
+		s.liveMu.Lock()
+		s.live[t.ID] = t
+		s.liveMu.Unlock()
+
+		s.results.Store(t)
+		s.metrics.recordSubmit(req.AgentID)
+		slog.Info("task waiting on dependencies", "task", t.ID, "agent", req.AgentID, "action", t.Action, "dependsOn", t.DependsOn)
+		s.waitFor(t)
+		return t, nil
 	}
 
 	pos, err := s.queue.Enqueue(t)
@@ -249,6 +340,19 @@ func (s *Scheduler) Cancel(taskID string) error {
 		return fmt.Errorf("task %q already in terminal state %q", taskID, state)
 	}
 
+	if state == StateWaiting {
+		// Never queued, so there is no queue slot to give back.
+		s.dropWaiting(t.ID)
+		if err := t.SetState(StateCancelled); err != nil {
+			return fmt.Errorf("cancel failed: %w", err)
+		}
+		s.metrics.recordCancel(t.AgentID)
+		slog.Info("task cancelled", "task", t.ID, "agent", t.AgentID, "previousState", state)
+		s.results.Store(t)
+		s.retireTask(t)
+		return nil
+	}
+
 	if state == StateQueued {
 		s.queue.Remove(t.ID, t.AgentID)
 	}
@@ -275,6 +379,25 @@ func (s *Scheduler) ListTasks(agentID string, states []TaskState) []*Task {
 	return s.results.List(agentID, states)
 }
 
+// ActiveTasksForTabs counts the unfinished tasks aimed at one of tabIDs.
+// Draining an instance waits for it to reach zero before moving the tabs.
+func (s *Scheduler) ActiveTasksForTabs(tabIDs []string) int {
+	if len(tabIDs) == 0 {
+		return 0
+	}
+	want := make(map[string]bool, len(tabIDs))
+	for _, id := range tabIDs {
+		want[id] = true
+	}
+	n := 0
+	for _, t := range s.results.List("", []TaskState{StateWaiting, StateQueued, StateAssigned, StateRunning}) {
+		if want[t.TabID] {
+			n++
+		}
+	}
+	return n
+}
+
 // QueueStats returns current queue metrics.
 func (s *Scheduler) QueueStats() QueueStats {
 	return s.queue.Stats()
@@ -369,11 +492,19 @@ func (s *Scheduler) dispatch(t *Task) {
 func (s *Scheduler) finishTask(t *Task) {
 	s.results.Store(t)
 	s.queue.Complete(t.AgentID)
+	s.retireTask(t)
+}
 
+// retireTask drops a terminal task from the live set and notifies everything
+// that waits on it: dependent tasks, its schedule, its batch and its webhook.
+func (s *Scheduler) retireTask(t *Task) {
 	s.liveMu.Lock()
 	delete(s.live, t.ID)
 	s.liveMu.Unlock()
 
+	s.releaseDependents(t)
+	s.recordScheduleOutcome(t)
+	s.failFastBatch(t)
 	s.webhooks.fire(t)
 }
 
@@ -387,6 +518,7 @@ func (s *Scheduler) deadlineReaper() {
 		case <-s.stopCh:
 			return
 		case <-ticker.C:
+			s.expireWaiting(timeNow())
 			expired := s.queue.ExpireDeadlined()
 			for _, t := range expired {
 				t.Error = "deadline exceeded while queued"
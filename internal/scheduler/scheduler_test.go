@@ -174,6 +174,35 @@ func TestSchedulerListTasks(t *testing.T) {
 	}
 }
 
+func TestSchedulerActiveTasksForTabs(t *testing.T) {
+	s, executor := newTestScheduler(t)
+	defer executor.Close()
+
+	if _, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"}); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	done, err := s.Submit(SubmitRequest{AgentID: "a1", Action: "click", TabID: "tab-1"})
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	if err := s.Cancel(done.ID); err != nil {
+		t.Fatalf("cancel failed: %v", err)
+	}
+	if _, err := s.Submit(SubmitRequest{AgentID: "a2", Action: "click", TabID: "tab-2"}); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+
+	if n := s.ActiveTasksForTabs([]string{"tab-1"}); n != 1 {
+		t.Errorf("tab-1 active = %d, want 1 (cancelled tasks are finished)", n)
+	}
+	if n := s.ActiveTasksForTabs([]string{"tab-1", "tab-2"}); n != 2 {
+		t.Errorf("both tabs active = %d, want 2", n)
+	}
+	if n := s.ActiveTasksForTabs(nil); n != 0 {
+		t.Errorf("no tabs active = %d, want 0", n)
+	}
+}
+
 func TestSchedulerDispatchAndComplete(t *testing.T) {
 	executor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
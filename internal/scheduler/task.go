@@ -13,6 +13,7 @@ import (
 type TaskState string
 
 const (
+	StateWaiting   TaskState = "waiting" // blocked on dependsOn parents; not yet queued
 	StateQueued    TaskState = "queued"
 	StateAssigned  TaskState = "assigned"
 	StateRunning   TaskState = "running"
@@ -31,6 +32,28 @@ func (s TaskState) IsTerminal() bool {
 	return false
 }
 
+// RestartPolicy decides what happens to a task that was assigned or running
+// when the scheduler went down, once its journal is replayed on startup.
+type RestartPolicy string
+
+const (
+	// RestartFail marks interrupted tasks failed. Safe for non-idempotent
+	// actions and the default.
+	RestartFail RestartPolicy = "fail"
+	// RestartRequeue puts interrupted tasks back in the queue to run again.
+	RestartRequeue RestartPolicy = "requeue"
+)
+
+// IsValid reports whether p is a known policy. The empty policy is valid
+// and means "use the scheduler default".
+func (p RestartPolicy) IsValid() bool {
+	switch p {
+	case "", RestartFail, RestartRequeue:
+		return true
+	}
+	return false
+}
+
 // Task represents a scheduled unit of work dispatched to the executor.
 type Task struct {
 	mu sync.RWMutex
@@ -57,6 +80,20 @@ type Task struct {
 	// CallbackURL receives a POST with the task snapshot on completion.
 	CallbackURL string `json:"callbackUrl,omitempty"`
 
+	// OnRestart overrides the scheduler's restart policy for this task.
+	OnRestart RestartPolicy `json:"onRestart,omitempty"`
+
+	// ScheduleID is set on tasks created by a recurring schedule.
+	ScheduleID string `json:"scheduleId,omitempty"`
+
+	// DependsOn lists the task IDs that must finish with done before this
+	// task is queued. BatchID, Key and OnFailure are set on tasks submitted
+	// through POST /tasks/batch.
+	DependsOn []string      `json:"dependsOn,omitempty"`
+	BatchID   string        `json:"batchId,omitempty"`
+	Key       string        `json:"key,omitempty"`
+	OnFailure FailurePolicy `json:"onFailure,omitempty"`
+
 	// position is the queue position at submission time.
 	Position int `json:"position,omitempty"`
 }
@@ -72,6 +109,7 @@ func (t *Task) SetState(next TaskState) error {
 	}
 
 	switch {
+	case t.State == StateWaiting && (next == StateQueued || next == StateCancelled || next == StateFailed || next == StateRejected):
 	case t.State == StateQueued && (next == StateAssigned || next == StateCancelled || next == StateFailed || next == StateRejected):
 	case t.State == StateAssigned && (next == StateRunning || next == StateCancelled):
 	case t.State == StateRunning && (next == StateDone || next == StateFailed || next == StateCancelled):
@@ -137,6 +175,12 @@ func (t *Task) Snapshot() *Task {
 		Result:      t.Result,
 		Error:       t.Error,
 		CallbackURL: t.CallbackURL,
+		OnRestart:   t.OnRestart,
+		ScheduleID:  t.ScheduleID,
+		DependsOn:   append([]string(nil), t.DependsOn...),
+		BatchID:     t.BatchID,
+		Key:         t.Key,
+		OnFailure:   t.OnFailure,
 		Position:    t.Position,
 	}
 }
@@ -152,6 +196,10 @@ type SubmitRequest struct {
 	Priority    int            `json:"priority,omitempty"`
 	Deadline    string         `json:"deadline,omitempty"`
 	CallbackURL string         `json:"callbackUrl,omitempty"`
+	OnRestart   RestartPolicy  `json:"onRestart,omitempty"`
+	// DependsOn holds task IDs that must finish with done first. Params and
+	// tabId may reference their results as ${<taskId>.result.<path>}.
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // Validate checks that the request has the minimum required fields.
@@ -167,6 +215,19 @@ func (r *SubmitRequest) Validate() error {
 			return fmt.Errorf("invalid callbackUrl: %w", err)
 		}
 	}
+	if !r.OnRestart.IsValid() {
+		return fmt.Errorf("invalid onRestart %q (must be fail or requeue)", r.OnRestart)
+	}
+	seen := make(map[string]bool, len(r.DependsOn))
+	for _, id := range r.DependsOn {
+		if id == "" {
+			return fmt.Errorf("dependsOn contains an empty task ID")
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate dependency %q", id)
+		}
+		seen[id] = true
+	}
 	return nil
 }
 
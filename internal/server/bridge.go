@@ -17,6 +17,7 @@ import (
 	"github.com/pinchtab/pinchtab/internal/cli"
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/handlers"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 func RunBridgeServer(cfg *config.RuntimeConfig, version string) {
@@ -68,15 +69,18 @@ func RunBridgeServer(cfg *config.RuntimeConfig, version string) {
 	activity.RegisterHandlers(mux, actStore)
 	cli.LogSecurityWarnings(cfg)
 
+	stopTracing := startTracing(cfg, "pinchtab-bridge")
 	server := &http.Server{
 		Addr: listenAddr,
 		Handler: handlers.TrustedInternalProxyStripMiddleware(os.Getenv("PINCHTAB_INTERNAL_TOKEN"))(
-			handlers.RequestIDMiddleware(
-				activity.Middleware(
-					actStore,
-					"bridge",
-					handlers.SecurityHeadersMiddleware(cfg,
-						handlers.LoggingMiddleware(handlers.RateLimitMiddleware(handlers.AuthMiddleware(cfg, mux))),
+			tracing.Middleware(
+				handlers.RequestIDMiddleware(
+					activity.Middleware(
+						actStore,
+						"bridge",
+						handlers.SecurityHeadersMiddleware(cfg,
+							handlers.LoggingMiddleware(handlers.RateLimitMiddleware(handlers.AuthMiddleware(cfg, handlers.RoutePatternMiddleware(mux)))),
+						),
 					),
 				),
 			),
@@ -106,6 +110,7 @@ func RunBridgeServer(cfg *config.RuntimeConfig, version string) {
 	if err := server.Shutdown(ctx); err != nil {
 		slog.Error("shutdown error", "err", err)
 	}
+	stopTracing()
 }
 
 func configureBridgeRouter(h *handlers.Handlers, cfg *config.RuntimeConfig) {
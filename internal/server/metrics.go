@@ -0,0 +1,25 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/metrics"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+	"github.com/pinchtab/pinchtab/internal/scheduler"
+)
+
+// prometheusMetricsHandler serves the server's own metrics, the scheduler's
+// per-agent gauges and every running instance's metrics relabelled by
+// instance and profile, so one scrape target covers the whole deployment.
+func prometheusMetricsHandler(orch *orchestrator.Orchestrator, sched *scheduler.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		families := metrics.Default.Gather()
+		if sched != nil {
+			families = append(families, sched.MetricFamilies()...)
+		}
+		if orch != nil {
+			families = append(families, orch.InstanceMetricFamilies(r.Context())...)
+		}
+		metrics.ServeHTTP(w, r, metrics.Merge(families))
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/pinchtab/pinchtab/internal/activity"
+	"github.com/pinchtab/pinchtab/internal/apitoken"
 	"github.com/pinchtab/pinchtab/internal/authn"
 	_ "github.com/pinchtab/pinchtab/internal/browsers/all"
 	"github.com/pinchtab/pinchtab/internal/browsers/providerhooks"
@@ -30,9 +31,11 @@ import (
 	"github.com/pinchtab/pinchtab/internal/strategy"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/alwayson"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/autorestart"
+	_ "github.com/pinchtab/pinchtab/internal/strategy/autoscale"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/explicit"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/noinstance"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/simple"
+	"github.com/pinchtab/pinchtab/internal/tracing"
 )
 
 func RunDashboard(cfg *config.RuntimeConfig, version string) {
@@ -82,11 +85,13 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 	if sessionStore.Enabled() {
 		sessionAPI = dashboard.NewSessionAPI(sessionStore, cfg.BrowsersAvailable)
 	}
+	apiTokens := apitoken.NewStore(filepath.Join(cfg.StateDir, "api-tokens.json"))
 
 	orch.OnEvent(func(evt orchestrator.InstanceEvent) {
 		dash.BroadcastSystemEvent(dashboard.SystemEvent{
 			Type:     evt.Type,
 			Instance: evt.Instance,
+			Reason:   evt.Reason,
 		})
 	})
 
@@ -138,6 +143,7 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		ConfigAPI:     configAPI,
 		AuthAPI:       authAPI,
 		SessionAPI:    sessionAPI,
+		TokenAPI:      dashboard.NewTokenAPI(apiTokens),
 		Activity:      liveActivity,
 		ServerMetrics: handlers.SnapshotMetrics,
 	})
@@ -276,13 +282,24 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		if cfg.Scheduler.WorkerCount > 0 {
 			schedCfg.WorkerCount = cfg.Scheduler.WorkerCount
 		}
+		if cfg.Scheduler.Persist {
+			schedCfg.JournalPath = filepath.Join(cfg.StateDir, "scheduler", "tasks.jsonl")
+		}
+		if cfg.Scheduler.OnRestart != "" {
+			schedCfg.RestartPolicy = scheduler.RestartPolicy(cfg.Scheduler.OnRestart)
+		}
+		if cfg.StateDir != "" {
+			schedCfg.SchedulesPath = filepath.Join(cfg.StateDir, "scheduler", "schedules.json")
+		}
 
 		resolver := &scheduler.ManagerResolver{Mgr: orch.InstanceManager()}
 		sched = scheduler.New(schedCfg, resolver)
 		sched.RegisterHandlers(mux)
-		slog.Info("scheduler enabled (on-demand)", "strategy", schedCfg.Strategy, "workers", schedCfg.WorkerCount)
+		orch.SetDrainTaskSource(sched)
+		slog.Info("scheduler enabled (on-demand)", "strategy", schedCfg.Strategy, "workers", schedCfg.WorkerCount, "persist", schedCfg.JournalPath != "")
 	}
 
+	mux.HandleFunc("GET /metrics/prometheus", prometheusMetricsHandler(orch, sched))
 	mux.HandleFunc("GET /health", configAPI.HandleHealth)
 	mux.HandleFunc("GET /health/background", func(w http.ResponseWriter, r *http.Request) {
 		httpx.JSON(w, http.StatusOK, map[string]string{
@@ -293,13 +310,16 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		})
 	})
 
+	stopTracing := startTracing(cfg, "pinchtab")
 	handler := handlers.StripInternalHeadersMiddleware(
-		handlers.RequestIDMiddleware(
-			activity.Middleware(
-				liveActivity,
-				"server",
-				handlers.SecurityHeadersMiddleware(cfg,
-					handlers.LoggingMiddleware(handlers.RateLimitMiddleware(handlers.CorsMiddleware(cfg, handlers.AuthMiddlewareWithSessions(cfg, sessions, sessionStore, mux)))),
+		tracing.Middleware(
+			handlers.RequestIDMiddleware(
+				activity.Middleware(
+					liveActivity,
+					"server",
+					handlers.SecurityHeadersMiddleware(cfg,
+						handlers.LoggingMiddleware(handlers.RateLimitMiddleware(handlers.CorsMiddleware(cfg, handlers.AuthMiddlewareWithTokens(cfg, sessions, sessionStore, apiTokens, handlers.RoutePatternMiddleware(mux))))),
+					),
 				),
 			),
 		),
@@ -318,6 +338,9 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 		IdleTimeout:       120 * time.Second,
 	}
 
+	if setter, ok := activeStrategy.(strategy.SchedulerAware); ok && sched != nil {
+		setter.SetScheduler(sched)
+	}
 	if err := activeStrategy.Start(context.Background()); err != nil {
 		slog.Error("strategy start failed", "strategy", activeStrategy.Name(), "err", err)
 	}
@@ -348,6 +371,7 @@ func RunDashboard(cfg *config.RuntimeConfig, version string) {
 			if err := srv.Shutdown(ctx); err != nil {
 				slog.Error("shutdown http", "err", err)
 			}
+			stopTracing()
 		})
 	}
 
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/tracing"
+)
+
+// startTracing installs the process-wide tracer when observability.tracing
+// is enabled. The returned stop function flushes pending spans; it is safe
+// to call when tracing is off.
+func startTracing(cfg *config.RuntimeConfig, service string) func() {
+	tc := cfg.Observability.Tracing
+	if !tc.Enabled {
+		return func() {}
+	}
+
+	var exporter tracing.Exporter
+	switch tc.Exporter {
+	case "otlp":
+		exporter = tracing.NewHTTPExporter(tc.Endpoint)
+		slog.Info("tracing enabled", "exporter", "otlp", "endpoint", tc.Endpoint, "sampleRatio", tc.SampleRatio)
+	default:
+		file, err := tracing.NewFileExporter(cfg.TracingFile())
+		if err != nil {
+			slog.Warn("tracing disabled", "err", err)
+			return func() {}
+		}
+		exporter = file
+		slog.Info("tracing enabled", "exporter", "file", "file", cfg.TracingFile(), "sampleRatio", tc.SampleRatio)
+	}
+
+	tracer := tracing.New(tracing.Config{
+		ServiceName: service,
+		Exporter:    exporter,
+		SampleRatio: tc.SampleRatio,
+	})
+	tracing.Install(tracer)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			slog.Warn("tracing shutdown", "err", err)
+		}
+	}
+}
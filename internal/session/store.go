@@ -31,6 +31,10 @@ type Session struct {
 	IdleTimeout time.Duration `json:"-"`
 	Status      string        `json:"status"`
 	Grants      []string      `json:"grants,omitempty"`
+	// Isolated sessions get a browser context of their own on every instance
+	// they open tabs on: separate cookies, storage and permissions, disposed
+	// when the session is revoked or expires.
+	Isolated bool `json:"isolated,omitempty"`
 }
 
 // Config controls store behavior.
@@ -49,6 +53,8 @@ type LifecycleEvent struct {
 	SessionID string
 	AgentID   string
 	Reason    string // "revoked" | "expired" | "pruned"
+	// Isolated is set for sessions that own browser contexts to dispose.
+	Isolated bool
 }
 
 // LifecycleHook receives events after a store mutation has committed.
@@ -254,7 +260,7 @@ func (s *Store) authenticate(token string, touch bool) (*Session, bool) {
 		if s.isExpired(sess, now) {
 			sess.Status = StatusExpired
 			job, persist = s.snapshotLocked()
-			expiredEvt = &LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonExpired}
+			expiredEvt = &LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonExpired, Isolated: sess.Isolated}
 			return
 		}
 		if touch {
@@ -364,6 +370,27 @@ func (s *Store) SetGrants(sessionID string, grants []string) bool {
 	return true
 }
 
+// SetIsolated sets whether the session's tabs get their own browser context
+// and persists the change. Returns false if no such session.
+func (s *Store) SetIsolated(sessionID string, isolated bool) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	sess, ok := s.sessions[strings.TrimSpace(sessionID)]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	sess.Isolated = isolated
+	job, persist := s.snapshotLocked()
+	s.mu.Unlock()
+	if persist {
+		s.writeSnapshot(job)
+	}
+	return true
+}
+
 // Revoke marks a session as revoked.
 func (s *Store) Revoke(sessionID string) bool {
 	if s == nil {
@@ -385,7 +412,7 @@ func (s *Store) Revoke(sessionID string) bool {
 		}
 		sess.Status = StatusRevoked
 		job, persist = s.snapshotLocked()
-		event = LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonRevoked}
+		event = LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonRevoked, Isolated: sess.Isolated}
 		return true
 	}()
 	if !revoked {
@@ -450,13 +477,13 @@ func (s *Store) pruneExpiredLocked() []LifecycleEvent {
 		if sess.Status == StatusRevoked {
 			delete(s.sessions, id)
 			delete(s.byTokenHash, sess.TokenHash)
-			events = append(events, LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonPruned})
+			events = append(events, LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonPruned, Isolated: sess.Isolated})
 			continue
 		}
 		if s.isExpired(sess, now) {
 			delete(s.sessions, id)
 			delete(s.byTokenHash, sess.TokenHash)
-			events = append(events, LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonPruned})
+			events = append(events, LifecycleEvent{SessionID: sess.ID, AgentID: sess.AgentID, Reason: LifecycleReasonPruned, Isolated: sess.Isolated})
 		}
 	}
 	return events
@@ -478,6 +505,7 @@ type persistedSession struct {
 	ExpiresAt  time.Time `json:"expiresAt,omitempty"`
 	Status     string    `json:"status"`
 	Grants     []string  `json:"grants,omitempty"`
+	Isolated   bool      `json:"isolated,omitempty"`
 }
 
 // toPersisted maps an in-memory Session to its on-disk record.
@@ -494,7 +522,8 @@ func (sess *Session) toPersisted() persistedSession {
 		Status:     sess.Status,
 		// Clone Grants: snapshots are marshalled outside s.mu, so the record must
 		// not alias store-owned slices that a concurrent SetGrants could mutate.
-		Grants: append([]string(nil), sess.Grants...),
+		Grants:   append([]string(nil), sess.Grants...),
+		Isolated: sess.Isolated,
 	}
 }
 
@@ -521,6 +550,7 @@ func (rec persistedSession) toSession(idleTimeout time.Duration) (*Session, bool
 		IdleTimeout: idleTimeout,
 		Status:      rec.Status,
 		Grants:      append([]string(nil), rec.Grants...),
+		Isolated:    rec.Isolated,
 	}, true
 }
 
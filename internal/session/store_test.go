@@ -320,6 +320,25 @@ func TestPersistAndLoad(t *testing.T) {
 	}
 }
 
+func TestSetIsolatedPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+
+	s1 := NewStore(Config{Enabled: true, PersistPath: path, IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour})
+	id, token, _ := s1.Create("agent-1", "", "")
+	if !s1.SetIsolated(id, true) {
+		t.Fatal("SetIsolated on existing session returned false")
+	}
+	if s1.SetIsolated("ses_missing", true) {
+		t.Fatal("SetIsolated on unknown session returned true")
+	}
+
+	s2 := NewStore(Config{Enabled: true, PersistPath: path, IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour})
+	sess, ok := s2.Authenticate(token)
+	if !ok || !sess.Isolated {
+		t.Fatalf("isolated flag lost across reload: %+v", sess)
+	}
+}
+
 func TestPrunedOnLoad(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "sessions.json")
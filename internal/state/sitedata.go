@@ -0,0 +1,69 @@
+package state
+
+import "encoding/json"
+
+// ServiceWorker records a service worker registration so it can be
+// re-registered when the state is loaded.
+type ServiceWorker struct {
+	ScopeURL  string `json:"scopeURL"`
+	ScriptURL string `json:"scriptURL"`
+}
+
+// Cache is a single named Cache Storage cache and its entries.
+type Cache struct {
+	Name    string       `json:"name"`
+	Entries []CacheEntry `json:"entries"`
+}
+
+// CacheEntry is a cached request/response pair. Body is base64 encoded.
+type CacheEntry struct {
+	RequestURL    string   `json:"requestURL"`
+	RequestMethod string   `json:"requestMethod,omitempty"`
+	Status        int      `json:"status"`
+	StatusText    string   `json:"statusText,omitempty"`
+	Headers       []Header `json:"headers,omitempty"`
+	Body          string   `json:"body,omitempty"`
+}
+
+// Header is a single HTTP header. Headers are kept as a list because a name
+// may repeat.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// IndexedDBDatabase describes an IndexedDB database, its schema and, when
+// exported, its records.
+type IndexedDBDatabase struct {
+	Name         string                 `json:"name"`
+	Version      int64                  `json:"version"`
+	ObjectStores []IndexedDBObjectStore `json:"objectStores"`
+}
+
+// IndexedDBObjectStore describes one object store. KeyPath is null, a string
+// or an array of strings, as in the IndexedDB API.
+type IndexedDBObjectStore struct {
+	Name          string            `json:"name"`
+	KeyPath       json.RawMessage   `json:"keyPath"`
+	AutoIncrement bool              `json:"autoIncrement"`
+	Indexes       []IndexedDBIndex  `json:"indexes,omitempty"`
+	Count         int               `json:"count,omitempty"`
+	Records       []IndexedDBRecord `json:"records,omitempty"`
+	Truncated     bool              `json:"truncated,omitempty"`
+}
+
+// IndexedDBIndex describes an index on an object store.
+type IndexedDBIndex struct {
+	Name       string          `json:"name"`
+	KeyPath    json.RawMessage `json:"keyPath"`
+	Unique     bool            `json:"unique"`
+	MultiEntry bool            `json:"multiEntry"`
+}
+
+// IndexedDBRecord is a key/value pair. Values that JSON cannot represent
+// directly (dates, binary data, maps, sets, bigints) are tagged objects such
+// as {"$date": "..."} or {"$bytes": "<base64>", "$type": "Uint8Array"}.
+type IndexedDBRecord struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
@@ -37,6 +37,11 @@ type StateFile struct {
 	Storage   map[string]OriginStorage `json:"storage"`
 	Metadata  map[string]interface{}   `json:"metadata"`
 	Encrypted bool                     `json:"encrypted"`
+
+	// Site data beyond web storage, keyed by origin like Storage.
+	ServiceWorkers map[string][]ServiceWorker     `json:"serviceWorkers,omitempty"`
+	CacheStorage   map[string][]Cache             `json:"cacheStorage,omitempty"`
+	IndexedDB      map[string][]IndexedDBDatabase `json:"indexedDB,omitempty"`
 }
 
 // OriginStorage holds localStorage and sessionStorage key-value pairs
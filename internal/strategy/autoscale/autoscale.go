@@ -0,0 +1,260 @@
+// Package autoscale implements the "autoscale" allocation strategy.
+//
+// Autoscale keeps every configured profile between a minimum and maximum
+// number of running instances. Each evaluation tick reads the scheduler queue
+// and every instance's tabs, CPU and memory; a profile whose load crosses a
+// threshold gets one more instance, and an instance that stays idle for the
+// cooldown is drained and stopped. Decisions are emitted as orchestrator
+// events ("autoscale.scale_up", "autoscale.draining", "autoscale.scale_down",
+// "autoscale.blocked") with the reason attached.
+//
+// A profile's first instance runs on the profile itself. Extra instances run
+// on temporary profiles that start empty and are deleted when they stop.
+package autoscale
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+	"github.com/pinchtab/pinchtab/internal/scheduler"
+	"github.com/pinchtab/pinchtab/internal/strategy"
+)
+
+const (
+	defaultProfileName   = "default"
+	defaultMinInstances  = 1
+	defaultMaxInstances  = 4
+	defaultQueueDepth    = 10
+	defaultTabsPerInst   = 20
+	defaultInterval      = 15 * time.Second
+	defaultCooldown      = 5 * time.Minute
+	statusPath           = "/autoscale/status"
+	maxRecentDecisions   = 50
+	instanceLoadsTimeout = 10 * time.Second
+)
+
+func init() {
+	strategy.MustRegister("autoscale", func() strategy.Strategy {
+		return New(Config{})
+	})
+}
+
+// Config configures the autoscale behavior. Zero values take the defaults
+// described on config.AutoscaleConfig.
+type Config struct {
+	Profiles     []string
+	MinInstances int
+	MaxInstances int
+
+	QueueDepthPerInstance int
+	TabsPerInstance       int
+	CPUPercent            int
+	MemoryMB              int
+
+	Interval time.Duration
+	Cooldown time.Duration
+	Headed   bool // launch with a visible window; instances are headless by default
+}
+
+func (c Config) withDefaults() Config {
+	if len(c.Profiles) == 0 {
+		c.Profiles = []string{defaultProfileName}
+	}
+	if c.MinInstances <= 0 {
+		c.MinInstances = defaultMinInstances
+	}
+	if c.MaxInstances <= 0 {
+		c.MaxInstances = max(defaultMaxInstances, c.MinInstances)
+	}
+	if c.QueueDepthPerInstance == 0 && c.TabsPerInstance == 0 && c.CPUPercent == 0 && c.MemoryMB == 0 {
+		c.QueueDepthPerInstance = defaultQueueDepth
+		c.TabsPerInstance = defaultTabsPerInst
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultInterval
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = defaultCooldown
+	}
+	return c
+}
+
+// queueStater is the part of the scheduler autoscale reads.
+type queueStater interface {
+	QueueStats() scheduler.QueueStats
+}
+
+// Strategy scales the instances of its profiles with load.
+type Strategy struct {
+	orch   *orchestrator.Orchestrator
+	queue  queueStater
+	config Config
+
+	mu        sync.Mutex
+	members   map[string]*member // by instance ID
+	launching map[string]bool    // profiles with a launch in flight
+	blocked   map[string]string  // profile → reason scale-up is blocked
+	recent    []Decision
+	lastTick  time.Time
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// member is what the strategy remembers about one of its instances between
+// ticks.
+type member struct {
+	profile   string
+	primary   bool
+	idleSince time.Time
+	cpuSec    float64
+	cpuAt     time.Time
+	draining  bool
+}
+
+// New creates a new autoscale strategy with the given config.
+func New(cfg Config) *Strategy {
+	return &Strategy{
+		config:    cfg.withDefaults(),
+		members:   make(map[string]*member),
+		launching: make(map[string]bool),
+		blocked:   make(map[string]string),
+	}
+}
+
+func (s *Strategy) Name() string { return "autoscale" }
+
+// SetRuntimeConfig applies multiInstance.autoscale and the launch mode.
+func (s *Strategy) SetRuntimeConfig(cfg *config.RuntimeConfig) {
+	if cfg == nil {
+		return
+	}
+	a := cfg.Autoscale
+	headed := s.config.Headed
+	if cfg.HeadlessSet {
+		headed = !cfg.Headless
+	}
+	s.config = Config{
+		Profiles:              append([]string(nil), a.Profiles...),
+		MinInstances:          a.MinInstances,
+		MaxInstances:          a.MaxInstances,
+		QueueDepthPerInstance: a.QueueDepthPerInstance,
+		TabsPerInstance:       a.TabsPerInstance,
+		CPUPercent:            a.CPUPercent,
+		MemoryMB:              a.MemoryMB,
+		Interval:              time.Duration(a.IntervalSec) * time.Second,
+		Cooldown:              time.Duration(a.CooldownSec) * time.Second,
+		Headed:                headed,
+	}.withDefaults()
+}
+
+// SetOrchestrator injects the orchestrator after construction.
+func (s *Strategy) SetOrchestrator(o *orchestrator.Orchestrator) {
+	s.orch = o
+}
+
+// SetScheduler lets queue depth drive scale-up. Without a scheduler the
+// queue signal is unused.
+func (s *Strategy) SetScheduler(sched *scheduler.Scheduler) {
+	s.queue = sched
+}
+
+// Start runs the first evaluation right away, then one per interval.
+func (s *Strategy) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	ctx = s.ctx
+	s.mu.Unlock()
+
+	s.orch.OnEvent(s.handleEvent)
+	go s.loop(ctx)
+	return nil
+}
+
+// Stop ends the evaluation loop. Running instances are left to the
+// orchestrator's shutdown.
+func (s *Strategy) Stop() error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Decision is one scaling decision, as reported by the status endpoint.
+type Decision struct {
+	At         time.Time `json:"at"`
+	Action     string    `json:"action"` // scale_up, draining, scale_down, blocked
+	Profile    string    `json:"profile"`
+	InstanceID string    `json:"instanceId,omitempty"`
+	Reason     string    `json:"reason"`
+}
+
+// ProfileState is the scaling state of one profile.
+type ProfileState struct {
+	Profile   string   `json:"profile"`
+	Instances []string `json:"instances"`
+	Draining  []string `json:"draining,omitempty"`
+	Launching bool     `json:"launching"`
+	Blocked   string   `json:"blocked,omitempty"`
+}
+
+// State is the status endpoint payload.
+type State struct {
+	MinInstances          int            `json:"minInstances"`
+	MaxInstances          int            `json:"maxInstances"`
+	QueueDepthPerInstance int            `json:"queueDepthPerInstance,omitempty"`
+	TabsPerInstance       int            `json:"tabsPerInstance,omitempty"`
+	CPUPercent            int            `json:"cpuPercent,omitempty"`
+	MemoryMB              int            `json:"memoryMB,omitempty"`
+	IntervalSec           int            `json:"intervalSec"`
+	CooldownSec           int            `json:"cooldownSec"`
+	LastEvaluation        time.Time      `json:"lastEvaluation,omitempty"`
+	Profiles              []ProfileState `json:"profiles"`
+	Decisions             []Decision     `json:"decisions"`
+}
+
+// State returns the current scaling state for observability.
+func (s *Strategy) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := State{
+		MinInstances:          s.config.MinInstances,
+		MaxInstances:          s.config.MaxInstances,
+		QueueDepthPerInstance: s.config.QueueDepthPerInstance,
+		TabsPerInstance:       s.config.TabsPerInstance,
+		CPUPercent:            s.config.CPUPercent,
+		MemoryMB:              s.config.MemoryMB,
+		IntervalSec:           int(s.config.Interval / time.Second),
+		CooldownSec:           int(s.config.Cooldown / time.Second),
+		LastEvaluation:        s.lastTick,
+		Decisions:             append([]Decision{}, s.recent...),
+	}
+	for _, profile := range s.config.Profiles {
+		ps := ProfileState{
+			Profile:   profile,
+			Instances: []string{},
+			Launching: s.launching[profile],
+			Blocked:   s.blocked[profile],
+		}
+		for id, m := range s.members {
+			if m.profile != profile {
+				continue
+			}
+			if m.draining {
+				ps.Draining = append(ps.Draining, id)
+			} else {
+				ps.Instances = append(ps.Instances, id)
+			}
+		}
+		sort.Strings(ps.Instances)
+		sort.Strings(ps.Draining)
+		st.Profiles = append(st.Profiles, ps)
+	}
+	return st
+}
@@ -0,0 +1,396 @@
+package autoscale
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/config"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+)
+
+func TestStrategy_DefaultConfig(t *testing.T) {
+	s := New(Config{})
+	c := s.config
+	if len(c.Profiles) != 1 || c.Profiles[0] != defaultProfileName {
+		t.Errorf("Profiles = %v", c.Profiles)
+	}
+	if c.MinInstances != 1 || c.MaxInstances != 4 {
+		t.Errorf("min/max = %d/%d, want 1/4", c.MinInstances, c.MaxInstances)
+	}
+	if c.QueueDepthPerInstance != defaultQueueDepth || c.TabsPerInstance != defaultTabsPerInst {
+		t.Errorf("thresholds = %d/%d, want defaults", c.QueueDepthPerInstance, c.TabsPerInstance)
+	}
+	if c.Interval != defaultInterval || c.Cooldown != defaultCooldown {
+		t.Errorf("interval/cooldown = %s/%s", c.Interval, c.Cooldown)
+	}
+	if c.Headed {
+		t.Error("expected headless launches by default")
+	}
+}
+
+func TestStrategy_SetRuntimeConfig(t *testing.T) {
+	s := New(Config{})
+	s.SetRuntimeConfig(&config.RuntimeConfig{
+		Headless:    false,
+		HeadlessSet: true,
+		Autoscale: config.AutoscaleConfig{
+			Profiles:     []string{"work", "research"},
+			MinInstances: 2,
+			MaxInstances: 6,
+			CPUPercent:   150,
+			IntervalSec:  5,
+			CooldownSec:  60,
+		},
+	})
+	c := s.config
+	if strings.Join(c.Profiles, ",") != "work,research" || c.MinInstances != 2 || c.MaxInstances != 6 {
+		t.Fatalf("config = %+v", c)
+	}
+	// An explicit threshold replaces the default signals.
+	if c.CPUPercent != 150 || c.QueueDepthPerInstance != 0 || c.TabsPerInstance != 0 {
+		t.Fatalf("thresholds = %+v", c)
+	}
+	if c.Interval != 5*time.Second || c.Cooldown != time.Minute || !c.Headed {
+		t.Fatalf("config = %+v", c)
+	}
+}
+
+func TestStrategy_MaxFollowsMin(t *testing.T) {
+	s := New(Config{MinInstances: 6})
+	if s.config.MaxInstances != 6 {
+		t.Fatalf("MaxInstances = %d, want 6", s.config.MaxInstances)
+	}
+}
+
+func TestProfileOf(t *testing.T) {
+	s := New(Config{Profiles: []string{"work"}})
+	tests := []struct {
+		name    string
+		profile string
+		primary bool
+		ok      bool
+	}{
+		{"work", "work", true, true},
+		{"instance-work-scale-18a2b", "work", false, true},
+		{"instance-123-abc", "", false, false},
+		{"default", "", false, false},
+	}
+	for _, tt := range tests {
+		profile, primary, ok := s.profileOf(tt.name)
+		if profile != tt.profile || primary != tt.primary || ok != tt.ok {
+			t.Errorf("profileOf(%q) = %q, %v, %v", tt.name, profile, primary, ok)
+		}
+	}
+}
+
+func running(id string, tabs int) memberLoad {
+	return memberLoad{id: id, running: true, tabs: tabs, cpuPercent: -1}
+}
+
+func TestDecide_LaunchesUpToMin(t *testing.T) {
+	cfg := Config{MinInstances: 2}.withDefaults()
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "default", members: []memberLoad{running("a", 0)}},
+	}})
+	if len(got) != 1 || got[0].Action != actionScaleUp || !strings.Contains(got[0].Reason, "below minInstances") {
+		t.Fatalf("decisions = %+v", got)
+	}
+}
+
+func TestDecide_SkipsProfileWhileLaunching(t *testing.T) {
+	cfg := Config{}.withDefaults()
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "default", launching: true},
+	}})
+	if len(got) != 0 {
+		t.Fatalf("decisions = %+v, want none", got)
+	}
+}
+
+func TestDecide_TabPressure(t *testing.T) {
+	cfg := Config{TabsPerInstance: 5}.withDefaults()
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "default", members: []memberLoad{running("a", 8), running("b", 4)}},
+	}})
+	if len(got) != 1 || got[0].Action != actionScaleUp || !strings.Contains(got[0].Reason, "6.0 tabs per instance > 5") {
+		t.Fatalf("decisions = %+v", got)
+	}
+}
+
+func TestDecide_CPUAndMemoryPressure(t *testing.T) {
+	cpu := Config{CPUPercent: 80}.withDefaults()
+	m := running("a", 1)
+	m.cpuPercent = 120
+	if got := decide(cpu, fleetLoad{capacity: 10, profiles: []profileLoad{{name: "p", members: []memberLoad{m}}}}); len(got) != 1 || got[0].Action != actionScaleUp {
+		t.Fatalf("cpu decisions = %+v", got)
+	}
+	// Without a previous reading there is no CPU rate yet.
+	m.cpuPercent = -1
+	if got := decide(cpu, fleetLoad{capacity: 10, profiles: []profileLoad{{name: "p", members: []memberLoad{m}}}}); len(got) != 0 {
+		t.Fatalf("cpu decisions without rate = %+v", got)
+	}
+
+	mem := Config{MemoryMB: 500}.withDefaults()
+	m.memoryMB = 900
+	if got := decide(mem, fleetLoad{capacity: 10, profiles: []profileLoad{{name: "p", members: []memberLoad{m}}}}); len(got) != 1 || !strings.Contains(got[0].Reason, "900 MB") {
+		t.Fatalf("memory decisions = %+v", got)
+	}
+}
+
+func TestDecide_QueueGrowsSmallestProfile(t *testing.T) {
+	cfg := Config{QueueDepthPerInstance: 5}.withDefaults()
+	got := decide(cfg, fleetLoad{queued: 20, capacity: 10, profiles: []profileLoad{
+		{name: "big", members: []memberLoad{running("a", 0), running("b", 0)}},
+		{name: "small", members: []memberLoad{running("c", 0)}},
+	}})
+	if len(got) != 1 || got[0].Profile != "small" || !strings.Contains(got[0].Reason, "queue depth 20 > 5 per instance × 3") {
+		t.Fatalf("decisions = %+v", got)
+	}
+}
+
+func TestDecide_BlockedAtMaxAndByPorts(t *testing.T) {
+	cfg := Config{TabsPerInstance: 1, MaxInstances: 2}.withDefaults()
+	full := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{running("a", 5), running("b", 5)}},
+	}})
+	if len(full) != 1 || full[0].Action != actionBlocked || !strings.Contains(full[0].Reason, "maxInstances") {
+		t.Fatalf("at max decisions = %+v", full)
+	}
+
+	noPorts := decide(cfg, fleetLoad{capacity: 0, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{running("a", 5)}},
+	}})
+	if len(noPorts) != 1 || noPorts[0].Action != actionBlocked || !strings.Contains(noPorts[0].Reason, "port range") {
+		t.Fatalf("no ports decisions = %+v", noPorts)
+	}
+
+	// Two profiles share the last free port pair.
+	shared := decide(cfg, fleetLoad{capacity: 1, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{running("a", 5)}},
+		{name: "q", members: []memberLoad{running("b", 5)}},
+	}})
+	if len(shared) != 2 || shared[0].Action != actionScaleUp || shared[1].Action != actionBlocked {
+		t.Fatalf("shared capacity decisions = %+v", shared)
+	}
+}
+
+func TestDecide_DrainsLongestIdleReplicaAfterCooldown(t *testing.T) {
+	cfg := Config{Cooldown: time.Minute}.withDefaults()
+	primary := running("primary", 0)
+	primary.primary = true
+	primary.idleFor = time.Hour
+	short := running("short", 0)
+	short.idleFor = 30 * time.Second
+	long := running("long", 0)
+	long.idleFor = 2 * time.Minute
+	longer := running("longer", 0)
+	longer.idleFor = 3 * time.Minute
+
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{primary, short, long, longer}},
+	}})
+	if len(got) != 1 || got[0].Action != actionDraining || got[0].InstanceID != "longer" {
+		t.Fatalf("decisions = %+v", got)
+	}
+}
+
+func TestDecide_StopsDrainedInstanceAndKeepsMin(t *testing.T) {
+	cfg := Config{MinInstances: 1, Cooldown: time.Minute}.withDefaults()
+	drained := running("b", 0)
+	drained.draining = true
+	drained.idleFor = 2 * time.Minute
+	primary := running("a", 0)
+	primary.primary = true
+
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{primary, drained}},
+	}})
+	if len(got) != 1 || got[0].Action != actionScaleDown || got[0].InstanceID != "b" {
+		t.Fatalf("decisions = %+v", got)
+	}
+}
+
+func TestDecide_BusyOrBoundInstancesAreNotDrained(t *testing.T) {
+	cfg := Config{Cooldown: time.Minute}.withDefaults()
+	primary := running("a", 1)
+	primary.primary = true
+	bound := running("b", 0)
+	bound.bindings = 1
+	bound.idleFor = time.Hour
+
+	got := decide(cfg, fleetLoad{capacity: 10, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{primary, bound}},
+	}})
+	if len(got) != 0 {
+		t.Fatalf("decisions = %+v, want none", got)
+	}
+}
+
+func TestDecide_QueuePressureSuppressesScaleDown(t *testing.T) {
+	cfg := Config{QueueDepthPerInstance: 1, MaxInstances: 2, Cooldown: time.Minute}.withDefaults()
+	primary := running("a", 0)
+	primary.primary = true
+	idle := running("b", 0)
+	idle.idleFor = time.Hour
+
+	got := decide(cfg, fleetLoad{queued: 10, capacity: 10, profiles: []profileLoad{
+		{name: "p", members: []memberLoad{primary, idle}},
+	}})
+	for _, d := range got {
+		if d.Action == actionDraining || d.Action == actionScaleDown {
+			t.Fatalf("decisions = %+v, want no scale-down under queue pressure", got)
+		}
+	}
+}
+
+func TestObserve_TracksIdleTimeAndCPURate(t *testing.T) {
+	s := New(Config{Profiles: []string{"work"}})
+	start := time.Now()
+	instances := []bridge.Instance{
+		{ID: "a", ProfileName: "work", Status: "running"},
+		{ID: "b", ProfileName: "instance-work-scale-1", Status: "running"},
+		{ID: "c", ProfileName: "other", Status: "running"},
+	}
+	loads := map[string]orchestrator.InstanceLoad{
+		"a": {InstanceID: "a", Scraped: true, Tabs: 2, CPUSeconds: 10, At: start},
+		"b": {InstanceID: "b", Scraped: true, CPUSeconds: 1, At: start},
+	}
+
+	s.mu.Lock()
+	first := s.observe(instances, loads, start)
+	loads["a"] = orchestrator.InstanceLoad{InstanceID: "a", Scraped: true, Tabs: 2, CPUSeconds: 15, At: start.Add(10 * time.Second)}
+	loads["b"] = orchestrator.InstanceLoad{InstanceID: "b", Scraped: true, CPUSeconds: 1, At: start.Add(10 * time.Second)}
+	second := s.observe(instances, loads, start.Add(10*time.Second))
+	s.mu.Unlock()
+
+	if len(first) != 1 || len(first[0].members) != 2 {
+		t.Fatalf("first = %+v", first)
+	}
+	if first[0].members[0].cpuPercent != -1 {
+		t.Fatalf("first reading should have no CPU rate: %+v", first[0].members[0])
+	}
+	a, b := second[0].members[0], second[0].members[1]
+	if !a.primary || a.cpuPercent != 50 || a.idleFor != 0 {
+		t.Fatalf("a = %+v", a)
+	}
+	if b.primary || b.idleFor != 10*time.Second {
+		t.Fatalf("b = %+v", b)
+	}
+}
+
+func TestObserve_ForgetsStoppedInstances(t *testing.T) {
+	s := New(Config{})
+	s.mu.Lock()
+	s.observe([]bridge.Instance{{ID: "a", ProfileName: "default", Status: "starting"}}, nil, time.Now())
+	s.observe(nil, nil, time.Now())
+	n := len(s.members)
+	s.mu.Unlock()
+	if n != 0 {
+		t.Fatalf("members = %d, want 0", n)
+	}
+}
+
+func TestTick_LaunchesPrimaryAndRecordsDecision(t *testing.T) {
+	orch := orchestrator.NewOrchestratorWithRunner(t.TempDir(), &mockRunner{})
+	orch.ApplyRuntimeConfig(&config.RuntimeConfig{})
+	orch.SetPortRange(19800, 19810)
+
+	events := make(chan orchestrator.InstanceEvent, 4)
+	orch.OnEvent(func(evt orchestrator.InstanceEvent) {
+		if strings.HasPrefix(evt.Type, "autoscale.") {
+			events <- evt
+		}
+	})
+
+	s := New(Config{Profiles: []string{"work"}})
+	s.SetOrchestrator(orch)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.tick(ctx, time.Now())
+
+	select {
+	case evt := <-events:
+		if evt.Type != "autoscale.scale_up" || evt.Instance == nil || evt.Instance.ProfileName != "work" {
+			t.Fatalf("event = %+v", evt)
+		}
+		if !strings.Contains(evt.Reason, "below minInstances") {
+			t.Fatalf("reason = %q", evt.Reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no autoscale event")
+	}
+
+	st := s.State()
+	if len(st.Decisions) != 1 || st.Decisions[0].Action != actionScaleUp || st.Decisions[0].InstanceID == "" {
+		t.Fatalf("decisions = %+v", st.Decisions)
+	}
+	if len(st.Profiles) != 1 || len(st.Profiles[0].Instances) != 1 || st.Profiles[0].Launching {
+		t.Fatalf("profiles = %+v", st.Profiles)
+	}
+}
+
+func TestRoute_NoRunningInstance(t *testing.T) {
+	orch := orchestrator.NewOrchestratorWithRunner(t.TempDir(), &mockRunner{})
+	orch.ApplyRuntimeConfig(&config.RuntimeConfig{})
+	s := New(Config{})
+	s.SetOrchestrator(orch)
+
+	req := httptest.NewRequest("GET", "/snapshot", nil).WithContext(canceledContext())
+	if _, status, err := s.route(req); err == nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("route = %d, %v; want 503", status, err)
+	}
+}
+
+func TestHandleStatus(t *testing.T) {
+	s := New(Config{Profiles: []string{"work"}, MaxInstances: 3})
+	s.members["inst_a"] = &member{profile: "work", primary: true}
+	s.members["inst_b"] = &member{profile: "work", draining: true}
+	s.blocked["work"] = "at maxInstances (3)"
+
+	w := httptest.NewRecorder()
+	s.handleStatus(w, httptest.NewRequest("GET", statusPath, nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var st State
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.MaxInstances != 3 || len(st.Profiles) != 1 {
+		t.Fatalf("state = %+v", st)
+	}
+	p := st.Profiles[0]
+	if len(p.Instances) != 1 || p.Instances[0] != "inst_a" || len(p.Draining) != 1 || p.Blocked == "" {
+		t.Fatalf("profile = %+v", p)
+	}
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+type mockRunner struct{}
+
+type mockCmd struct{}
+
+func (m *mockCmd) Wait() error { return nil }
+func (m *mockCmd) PID() int    { return 1234 }
+func (m *mockCmd) Cancel()     {}
+
+func (r *mockRunner) Run(_ context.Context, _ string, _ []string, _ []string, _, _ io.Writer) (orchestrator.Cmd, error) {
+	return &mockCmd{}, nil
+}
+
+func (r *mockRunner) InspectPort(_ string) orchestrator.PortInspection {
+	return orchestrator.PortInspection{Available: true}
+}
@@ -0,0 +1,205 @@
+package autoscale
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Scaling actions.
+const (
+	actionScaleUp   = "scale_up"
+	actionDraining  = "draining"
+	actionScaleDown = "scale_down"
+	actionBlocked   = "blocked"
+)
+
+// memberLoad is one instance of a profile as seen by a tick.
+type memberLoad struct {
+	id       string
+	primary  bool
+	running  bool // false while the instance is still starting
+	draining bool
+	tabs     int
+	bindings int
+	memoryMB float64
+	// cpuPercent is the process-tree CPU since the previous tick; negative
+	// until two readings exist.
+	cpuPercent float64
+	idleFor    time.Duration
+}
+
+// profileLoad is one profile as seen by a tick.
+type profileLoad struct {
+	name      string
+	launching bool
+	members   []memberLoad
+}
+
+// fleetLoad is the input of decide.
+type fleetLoad struct {
+	queued   int // scheduler tasks waiting for a worker
+	capacity int // launches the port range still has room for
+	profiles []profileLoad
+}
+
+// decide turns one tick's observations into scaling decisions. It grows a
+// profile by at most one instance per tick, and only drains an instance once
+// it has been idle for the cooldown. A drained instance is stopped on the
+// next tick if it is still idle.
+func decide(cfg Config, fleet fleetLoad) []Decision {
+	var out []Decision
+	capacity := fleet.capacity
+
+	scaleUp := func(p profileLoad, reason string) {
+		switch {
+		case p.launching:
+			// Re-evaluated once the launch lands.
+		case countActive(p) >= cfg.MaxInstances:
+			out = append(out, Decision{Action: actionBlocked, Profile: p.name,
+				Reason: fmt.Sprintf("at maxInstances (%d): %s", cfg.MaxInstances, reason)})
+		case capacity < 1:
+			out = append(out, Decision{Action: actionBlocked, Profile: p.name,
+				Reason: "no free ports in the instance port range: " + reason})
+		default:
+			capacity--
+			out = append(out, Decision{Action: actionScaleUp, Profile: p.name, Reason: reason})
+		}
+	}
+
+	grown := map[string]bool{}
+	for _, p := range fleet.profiles {
+		if n := countActive(p); n < cfg.MinInstances {
+			scaleUp(p, fmt.Sprintf("below minInstances (%d < %d)", n, cfg.MinInstances))
+			grown[p.name] = true
+			continue
+		}
+		if reason := profilePressure(cfg, p); reason != "" {
+			scaleUp(p, reason)
+			grown[p.name] = true
+		}
+	}
+
+	queuePressure := false
+	if cfg.QueueDepthPerInstance > 0 {
+		running := 0
+		for _, p := range fleet.profiles {
+			running += countRunning(p)
+		}
+		if running > 0 && fleet.queued > cfg.QueueDepthPerInstance*running {
+			queuePressure = true
+			// Grow the smallest profile that is not already growing.
+			candidates := make([]profileLoad, 0, len(fleet.profiles))
+			for _, p := range fleet.profiles {
+				if !grown[p.name] {
+					candidates = append(candidates, p)
+				}
+			}
+			sort.SliceStable(candidates, func(i, j int) bool {
+				return countActive(candidates[i]) < countActive(candidates[j])
+			})
+			if len(candidates) > 0 {
+				p := candidates[0]
+				scaleUp(p, fmt.Sprintf("queue depth %d > %d per instance × %d", fleet.queued, cfg.QueueDepthPerInstance, running))
+				grown[p.name] = true
+			}
+		}
+	}
+
+	if queuePressure {
+		return out
+	}
+	for _, p := range fleet.profiles {
+		if grown[p.name] {
+			continue
+		}
+		for _, m := range p.members {
+			if m.draining && isIdle(m) {
+				out = append(out, Decision{Action: actionScaleDown, Profile: p.name, InstanceID: m.id,
+					Reason: fmt.Sprintf("idle for %s", m.idleFor.Round(time.Second))})
+			}
+		}
+		if countActive(p) <= cfg.MinInstances {
+			continue
+		}
+		if m, ok := longestIdle(p, cfg.Cooldown); ok {
+			out = append(out, Decision{Action: actionDraining, Profile: p.name, InstanceID: m.id,
+				Reason: fmt.Sprintf("idle for %s (cooldown %s)", m.idleFor.Round(time.Second), cfg.Cooldown)})
+		}
+	}
+	return out
+}
+
+// profilePressure returns why p needs another instance, or "" when every
+// per-instance average is under its threshold.
+func profilePressure(cfg Config, p profileLoad) string {
+	var n, tabs, cpuN int
+	var memoryMB, cpu float64
+	for _, m := range p.members {
+		if !m.running || m.draining {
+			continue
+		}
+		n++
+		tabs += m.tabs
+		memoryMB += m.memoryMB
+		if m.cpuPercent >= 0 {
+			cpu += m.cpuPercent
+			cpuN++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	if cfg.TabsPerInstance > 0 && tabs > cfg.TabsPerInstance*n {
+		return fmt.Sprintf("%.1f tabs per instance > %d", float64(tabs)/float64(n), cfg.TabsPerInstance)
+	}
+	if cfg.CPUPercent > 0 && cpuN > 0 && cpu/float64(cpuN) > float64(cfg.CPUPercent) {
+		return fmt.Sprintf("%.0f%% CPU per instance > %d%%", cpu/float64(cpuN), cfg.CPUPercent)
+	}
+	if cfg.MemoryMB > 0 && memoryMB/float64(n) > float64(cfg.MemoryMB) {
+		return fmt.Sprintf("%.0f MB memory per instance > %d MB", memoryMB/float64(n), cfg.MemoryMB)
+	}
+	return ""
+}
+
+// longestIdle picks the replica that has been idle longest, if any has been
+// idle for the cooldown. The primary keeps the profile's own state and is
+// never drained.
+func longestIdle(p profileLoad, cooldown time.Duration) (memberLoad, bool) {
+	var best memberLoad
+	found := false
+	for _, m := range p.members {
+		if m.primary || m.draining || !isIdle(m) || m.idleFor < cooldown {
+			continue
+		}
+		if !found || m.idleFor > best.idleFor {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+func isIdle(m memberLoad) bool {
+	return m.running && m.tabs == 0 && m.bindings == 0
+}
+
+// countActive counts the instances that serve or will serve the profile.
+func countActive(p profileLoad) int {
+	n := 0
+	for _, m := range p.members {
+		if !m.draining {
+			n++
+		}
+	}
+	return n
+}
+
+func countRunning(p profileLoad) int {
+	n := 0
+	for _, m := range p.members {
+		if m.running && !m.draining {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package autoscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/httpx"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+	"github.com/pinchtab/pinchtab/internal/readiness"
+	"github.com/pinchtab/pinchtab/internal/strategy"
+)
+
+// instanceReadyWait is the longest a proxied request waits for the first
+// instance of a managed profile to come up.
+const instanceReadyWait = 30 * time.Second
+
+// RegisterRoutes adds shorthand endpoints that spread requests over the
+// managed instances, plus the status endpoint.
+func (s *Strategy) RegisterRoutes(mux *http.ServeMux) {
+	s.orch.RegisterHandlers(mux)
+	strategy.RegisterShorthandRoutes(mux, s.orch, s.proxyToScaled)
+	mux.HandleFunc("GET /tabs", s.handleTabs)
+	mux.HandleFunc("GET "+statusPath, s.handleStatus)
+}
+
+// proxyToScaled is the shorthand fallback, reached when no tab owner,
+// session or agent binding picked an instance.
+func (s *Strategy) proxyToScaled(w http.ResponseWriter, r *http.Request) {
+	target, status, err := s.route(r)
+	if err != nil {
+		if status == 0 {
+			status = 503
+		}
+		httpx.Error(w, status, err)
+		return
+	}
+	strategy.EnrichAndProxy(s.orch, w, r, target)
+}
+
+// route picks a running, non-draining instance with the configured
+// allocation policy. Requests that name a browser, mode or proxy region keep
+// the orchestrator's capability-aware routing.
+func (s *Strategy) route(r *http.Request) (string, int, error) {
+	if s.orch == nil {
+		return "", 503, fmt.Errorf("no orchestrator configured")
+	}
+	req, err := orchestrator.ExtractInstanceRequirements(r)
+	if err != nil {
+		return "", http.StatusBadRequest, err
+	}
+	if orchestrator.ExtractRequestedBrowser(r) != "" || !req.IsZero() {
+		return s.orch.RouteForRequest(r)
+	}
+	if target, err := s.allocate(); err == nil {
+		return target, 0, nil
+	}
+	if reason, unavailable := s.orch.BrowserUnavailableReason(r); unavailable {
+		return "", http.StatusServiceUnavailable, errors.New(reason)
+	}
+
+	target, err := readiness.WaitUntil(r.Context(), instanceReadyWait, 200*time.Millisecond,
+		func() (string, bool, error) {
+			t, err := s.allocate()
+			return t, err == nil, nil
+		})
+	if err != nil {
+		if errors.Is(err, readiness.ErrNotReady) || errors.Is(err, context.DeadlineExceeded) {
+			return "", 503, fmt.Errorf("no autoscaled instance ready after %s", instanceReadyWait)
+		}
+		return "", 503, err
+	}
+	return target, 0, nil
+}
+
+// allocate returns the URL of the instance the allocation policy selects.
+func (s *Strategy) allocate() (string, error) {
+	candidates := s.candidates()
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no running instances")
+	}
+	selected, err := s.orch.InstanceManager().Allocator.Policy().Select(candidates)
+	if err != nil {
+		return "", err
+	}
+	return selected.URL, nil
+}
+
+// candidates lists the running instances of the managed profiles that are
+// not being drained.
+func (s *Strategy) candidates() []bridge.Instance {
+	instances := s.orch.List()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]bridge.Instance, 0, len(instances))
+	for _, inst := range instances {
+		if inst.Status != "running" || inst.URL == "" {
+			continue
+		}
+		if _, _, ok := s.profileOf(inst.ProfileName); !ok {
+			continue
+		}
+		if m := s.members[inst.ID]; m != nil && m.draining {
+			continue
+		}
+		out = append(out, inst)
+	}
+	return out
+}
+
+func (s *Strategy) handleTabs(w http.ResponseWriter, r *http.Request) {
+	strategy.ProxyTabsToFirst(s.orch, w, r)
+}
+
+func (s *Strategy) handleStatus(w http.ResponseWriter, r *http.Request) {
+	httpx.JSON(w, 200, s.State())
+}
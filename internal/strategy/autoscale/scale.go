@@ -0,0 +1,261 @@
+package autoscale
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pinchtab/pinchtab/internal/bridge"
+	"github.com/pinchtab/pinchtab/internal/orchestrator"
+)
+
+// loop evaluates the fleet now and then every interval until ctx ends.
+func (s *Strategy) loop(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick observes every instance of the managed profiles, decides, and acts.
+func (s *Strategy) tick(ctx context.Context, now time.Time) {
+	if ctx.Err() != nil || s.orch == nil {
+		return
+	}
+	instances := s.orch.List()
+	loadsCtx, cancel := context.WithTimeout(ctx, instanceLoadsTimeout)
+	loads := make(map[string]orchestrator.InstanceLoad)
+	for _, l := range s.orch.InstanceLoads(loadsCtx) {
+		loads[l.InstanceID] = l
+	}
+	cancel()
+
+	fleet := fleetLoad{capacity: s.orch.LaunchCapacity()}
+	if s.queue != nil {
+		fleet.queued = s.queue.QueueStats().TotalQueued
+	}
+
+	s.mu.Lock()
+	fleet.profiles = s.observe(instances, loads, now)
+	decisions := decide(s.config, fleet)
+	s.lastTick = now
+	s.mu.Unlock()
+
+	s.apply(ctx, decisions, instances, now)
+}
+
+// observe refreshes the remembered members from the instance list and their
+// loads, and returns the per-profile view decide works on. Caller holds s.mu.
+func (s *Strategy) observe(instances []bridge.Instance, loads map[string]orchestrator.InstanceLoad, now time.Time) []profileLoad {
+	byProfile := make(map[string][]memberLoad, len(s.config.Profiles))
+	seen := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		if inst.Status != "starting" && inst.Status != "running" {
+			continue
+		}
+		profile, primary, ok := s.profileOf(inst.ProfileName)
+		if !ok {
+			continue
+		}
+		seen[inst.ID] = true
+		m := s.members[inst.ID]
+		if m == nil {
+			m = &member{profile: profile, primary: primary}
+			s.members[inst.ID] = m
+		}
+
+		ml := memberLoad{id: inst.ID, primary: primary, cpuPercent: -1}
+		if l, ok := loads[inst.ID]; ok && l.Scraped && inst.Status == "running" {
+			ml.running = true
+			ml.tabs = l.Tabs
+			ml.bindings = l.Bindings
+			ml.memoryMB = l.MemoryMB
+			if !m.cpuAt.IsZero() && l.At.After(m.cpuAt) && l.CPUSeconds >= m.cpuSec {
+				ml.cpuPercent = (l.CPUSeconds - m.cpuSec) / l.At.Sub(m.cpuAt).Seconds() * 100
+			}
+			m.cpuSec, m.cpuAt = l.CPUSeconds, l.At
+		}
+		if isIdle(ml) {
+			if m.idleSince.IsZero() {
+				m.idleSince = now
+			}
+			ml.idleFor = now.Sub(m.idleSince)
+		} else {
+			m.idleSince = time.Time{}
+			// Work arrived while draining: keep the instance.
+			m.draining = false
+		}
+		ml.draining = m.draining
+		byProfile[profile] = append(byProfile[profile], ml)
+	}
+	for id := range s.members {
+		if !seen[id] {
+			delete(s.members, id)
+		}
+	}
+
+	out := make([]profileLoad, 0, len(s.config.Profiles))
+	for _, name := range s.config.Profiles {
+		out = append(out, profileLoad{name: name, launching: s.launching[name], members: byProfile[name]})
+	}
+	return out
+}
+
+// profileOf maps an instance's profile to the managed profile it serves.
+func (s *Strategy) profileOf(profileName string) (profile string, primary, ok bool) {
+	for _, p := range s.config.Profiles {
+		if profileName == p {
+			return p, true, true
+		}
+		if orchestrator.IsReplicaOf(profileName, p) {
+			return p, false, true
+		}
+	}
+	return "", false, false
+}
+
+// scaleEvent is an orchestrator event queued while s.mu is held; handlers
+// run synchronously and may call back into the strategy.
+type scaleEvent struct {
+	inst   *bridge.Instance
+	action string
+	reason string
+}
+
+// apply carries out decisions. Launches and stops run in the background so a
+// slow browser does not hold up the next tick.
+func (s *Strategy) apply(ctx context.Context, decisions []Decision, instances []bridge.Instance, now time.Time) {
+	byID := make(map[string]*bridge.Instance, len(instances))
+	for i := range instances {
+		byID[instances[i].ID] = &instances[i]
+	}
+
+	var events []scaleEvent
+	var stops []string
+	blockedNow := map[string]bool{}
+
+	s.mu.Lock()
+	for _, d := range decisions {
+		d.At = now
+		switch d.Action {
+		case actionScaleUp:
+			s.launching[d.Profile] = true
+			go s.launch(ctx, d.Profile, d.Reason)
+		case actionBlocked:
+			blockedNow[d.Profile] = true
+			// Report the transition into the blocked state, not every tick.
+			if _, was := s.blocked[d.Profile]; was {
+				s.blocked[d.Profile] = d.Reason
+				continue
+			}
+			s.blocked[d.Profile] = d.Reason
+			s.record(d)
+			events = append(events, scaleEvent{inst: &bridge.Instance{ProfileName: d.Profile}, action: d.Action, reason: d.Reason})
+		case actionDraining:
+			if m := s.members[d.InstanceID]; m != nil {
+				m.draining = true
+			}
+			s.orch.Bindings().ClearInstance(d.InstanceID)
+			s.record(d)
+			events = append(events, scaleEvent{inst: byID[d.InstanceID], action: d.Action, reason: d.Reason})
+		case actionScaleDown:
+			delete(s.members, d.InstanceID)
+			stops = append(stops, d.InstanceID)
+			s.record(d)
+			events = append(events, scaleEvent{inst: byID[d.InstanceID], action: d.Action, reason: d.Reason})
+		}
+	}
+	for profile := range s.blocked {
+		if !blockedNow[profile] {
+			delete(s.blocked, profile)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range events {
+		slog.Info("autoscale: "+e.action, "profile", e.inst.ProfileName, "id", e.inst.ID, "reason", e.reason)
+		s.orch.EmitEventWithReason("autoscale."+e.action, e.inst, e.reason)
+	}
+	for _, id := range stops {
+		go func() {
+			if err := s.orch.Stop(id); err != nil {
+				slog.Warn("autoscale: stop failed", "id", id, "err", err)
+			}
+		}()
+	}
+}
+
+// launch starts one more instance for profile: on the profile itself when
+// its primary is not running, else on a fresh temporary profile.
+func (s *Strategy) launch(ctx context.Context, profile, reason string) {
+	s.mu.Lock()
+	name := profile
+	for _, m := range s.members {
+		if m.profile == profile && m.primary {
+			name = orchestrator.ReplicaProfileName(profile)
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	var inst *bridge.Instance
+	var err error
+	if ctx.Err() == nil {
+		inst, err = s.orch.Launch(name, "", !s.config.Headed, nil)
+	} else {
+		err = ctx.Err()
+	}
+
+	s.mu.Lock()
+	delete(s.launching, profile)
+	d := Decision{At: time.Now(), Action: actionScaleUp, Profile: profile, Reason: reason}
+	if err != nil {
+		d.Action = actionBlocked
+		d.Reason = fmt.Sprintf("launch failed: %v", err)
+		s.blocked[profile] = d.Reason
+		inst = &bridge.Instance{ProfileName: name}
+	} else {
+		d.InstanceID = inst.ID
+		if s.members[inst.ID] == nil {
+			s.members[inst.ID] = &member{profile: profile, primary: name == profile}
+		}
+	}
+	s.record(d)
+	s.mu.Unlock()
+
+	if err != nil {
+		slog.Error("autoscale: launch failed", "profile", profile, "name", name, "err", err)
+	} else {
+		slog.Info("autoscale: scale_up", "profile", profile, "id", inst.ID, "reason", reason)
+	}
+	s.orch.EmitEventWithReason("autoscale."+d.Action, inst, d.Reason)
+}
+
+// record keeps the latest decisions for the status endpoint. Caller holds
+// s.mu.
+func (s *Strategy) record(d Decision) {
+	s.recent = append(s.recent, d)
+	if over := len(s.recent) - maxRecentDecisions; over > 0 {
+		s.recent = append([]Decision(nil), s.recent[over:]...)
+	}
+}
+
+// handleEvent forgets instances that stop outside the strategy's control.
+func (s *Strategy) handleEvent(evt orchestrator.InstanceEvent) {
+	if evt.Instance == nil || evt.Instance.ID == "" {
+		return
+	}
+	switch evt.Type {
+	case "instance.stopped", "instance.error":
+		s.mu.Lock()
+		delete(s.members, evt.Instance.ID)
+		s.mu.Unlock()
+	}
+}
@@ -26,6 +26,8 @@ func capabilitySetting(cap routes.Capability) (feature, setting, code string) {
 		return "stateExport", "security.allowStateExport", "state_export_disabled"
 	case routes.CapNetworkIntercept:
 		return "networkIntercept", "security.allowNetworkIntercept", "network_intercept_disabled"
+	case routes.CapWebAuthn:
+		return "webauthn", "security.allowWebAuthn", "webauthn_disabled"
 	default:
 		return string(cap), "security.allow" + string(cap), string(cap) + "_disabled"
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/pinchtab/pinchtab/internal/config"
 	"github.com/pinchtab/pinchtab/internal/orchestrator"
+	"github.com/pinchtab/pinchtab/internal/scheduler"
 )
 
 // Orchestrator is the interface strategies need from the orchestrator.
@@ -32,6 +33,12 @@ type RuntimeConfigAware interface {
 	SetRuntimeConfig(cfg *config.RuntimeConfig)
 }
 
+// SchedulerAware is implemented by strategies that read the task scheduler.
+// The server injects the scheduler before Start() when it is enabled.
+type SchedulerAware interface {
+	SetScheduler(s *scheduler.Scheduler)
+}
+
 // Strategy defines a browser allocation approach.
 type Strategy interface {
 	// Name returns the strategy identifier.
@@ -14,6 +14,7 @@ import (
 	// Register strategies via init()
 	_ "github.com/pinchtab/pinchtab/internal/strategy/alwayson"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/autorestart"
+	_ "github.com/pinchtab/pinchtab/internal/strategy/autoscale"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/explicit"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/noinstance"
 	_ "github.com/pinchtab/pinchtab/internal/strategy/simple"
@@ -59,6 +60,19 @@ func TestRegistry_AlwaysOnRegistered(t *testing.T) {
 	}
 }
 
+func TestRegistry_AutoscaleRegistered(t *testing.T) {
+	s, err := strategy.New("autoscale")
+	if err != nil {
+		t.Fatalf("autoscale strategy not registered: %v", err)
+	}
+	if s.Name() != "autoscale" {
+		t.Errorf("expected name 'autoscale', got %q", s.Name())
+	}
+	if _, ok := s.(strategy.SchedulerAware); !ok {
+		t.Error("autoscale does not implement SchedulerAware")
+	}
+}
+
 func TestRegistry_UnknownStrategy(t *testing.T) {
 	_, err := strategy.New("nonexistent")
 	if err == nil {
@@ -96,7 +110,7 @@ func (m *mockRunner) InspectPort(_ string) orchestrator.PortInspection {
 }
 
 func TestCacheRoutes_RegisteredAcrossStrategies(t *testing.T) {
-	strategies := []string{"simple", "explicit", "no-instance", "simple-autorestart"}
+	strategies := []string{"simple", "explicit", "no-instance", "simple-autorestart", "autoscale"}
 	cacheRoutes := []struct {
 		method string
 		path   string
@@ -135,7 +149,7 @@ func TestCacheRoutes_RegisteredAcrossStrategies(t *testing.T) {
 }
 
 func TestOrchestratorAware_AllStrategies(t *testing.T) {
-	for _, name := range []string{"explicit", "simple", "simple-autorestart", "always-on"} {
+	for _, name := range []string{"explicit", "simple", "simple-autorestart", "always-on", "autoscale"} {
 		s, err := strategy.New(name)
 		if err != nil {
 			t.Fatalf("strategy %q not registered: %v", name, err)
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pinchtab/pinchtab/internal/httpx"
+)
+
+// Middleware starts a server span for each request, continuing the trace
+// from an incoming traceparent header. It must run before the request ID
+// middleware: a request without X-Request-Id gets the trace ID as its
+// request ID, so activity events, logs and traces share one key. A
+// caller-supplied request ID is kept and recorded on the span instead.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		remote, _ := Extract(r.Header)
+		ctx, span := start(r.Context(), r.Method, KindServer, remote, []Attr{
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+		})
+		defer span.End()
+
+		if rid := r.Header.Get("X-Request-Id"); rid != "" {
+			span.SetAttributes(String("pinchtab.request_id", rid))
+		} else if span != nil {
+			r.Header.Set("X-Request-Id", span.SpanContext().TraceID.String())
+		}
+
+		sw := &httpx.StatusWriter{ResponseWriter: w, Code: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(Int("http.response.status_code", sw.Code))
+		if sw.Code >= 500 {
+			span.RecordError(fmt.Errorf("HTTP %d", sw.Code))
+		}
+	})
+}
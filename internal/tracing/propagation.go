@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "traceparent"
+
+var errMalformedTraceparent = errors.New("malformed traceparent")
+
+// FormatTraceparent renders sc as a version-00 traceparent value.
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a traceparent header value. Versions newer than 00
+// are accepted as long as their leading fields follow the 00 layout, as the
+// spec asks of parsers.
+func ParseTraceparent(value string) (SpanContext, error) {
+	var sc SpanContext
+	value = strings.TrimSpace(value)
+	if len(value) < 55 {
+		return sc, errMalformedTraceparent
+	}
+	version := value[:2]
+	if !isLowerHex(version) || version == "ff" {
+		return sc, errMalformedTraceparent
+	}
+	if version == "00" && len(value) != 55 {
+		return sc, errMalformedTraceparent
+	}
+	if len(value) > 55 && value[55] != '-' {
+		return sc, errMalformedTraceparent
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, errMalformedTraceparent
+	}
+	traceHex, spanHex, flagsHex := value[3:35], value[36:52], value[53:55]
+	if !isLowerHex(traceHex) || !isLowerHex(spanHex) || !isLowerHex(flagsHex) {
+		return sc, errMalformedTraceparent
+	}
+	_, _ = hex.Decode(sc.TraceID[:], []byte(traceHex))
+	_, _ = hex.Decode(sc.SpanID[:], []byte(spanHex))
+	if !sc.IsValid() {
+		return SpanContext{}, errMalformedTraceparent
+	}
+	flags, _ := hex.DecodeString(flagsHex)
+	sc.Sampled = flags[0]&0x01 == 1
+	return sc, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Inject writes the current span of ctx into h as a traceparent header. It
+// leaves h untouched when ctx carries no span.
+func Inject(ctx context.Context, h http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	h.Set(TraceparentHeader, FormatTraceparent(span.SpanContext()))
+}
+
+// Extract reads the remote parent from h. It returns false when the header
+// is missing or malformed, in which case a new trace should be started.
+func Extract(h http.Header) (SpanContext, bool) {
+	value := h.Get(TraceparentHeader)
+	if value == "" {
+		return SpanContext{}, false
+	}
+	sc, err := ParseTraceparent(value)
+	if err != nil {
+		return SpanContext{}, false
+	}
+	return sc, true
+}
@@ -0,0 +1,380 @@
+// Package tracing records request spans across the server, the orchestrator
+// proxy, bridge handlers and CDP calls, and exports them as OTLP/JSON to a
+// file or a collector. It is deliberately small: W3C trace context
+// propagation, parent-based sampling and a batching exporter are all the
+// pieces PinchTab needs.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace across processes.
+type TraceID [16]byte
+
+// IsValid reports whether id is non-zero, as W3C trace context requires.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether id is non-zero.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// SpanKind mirrors the OTLP span kind enum.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Attr is a span attribute. Value is a string, int64, bool or float64.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attr        { return Attr{Key: key, Value: value} }
+func Int(key string, value int) Attr       { return Attr{Key: key, Value: int64(value)} }
+func Bool(key string, value bool) Attr     { return Attr{Key: key, Value: value} }
+func Float(key string, value float64) Attr { return Attr{Key: key, Value: value} }
+
+// Span is an in-flight operation. All methods are safe on a nil *Span so
+// call sites do not need to check whether tracing is enabled.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanData is an ended span as handed to an Exporter.
+type SpanData struct {
+	Name     string
+	Kind     SpanKind
+	Context  SpanContext
+	Parent   SpanID
+	Start    time.Time
+	End      time.Time
+	Attrs    []Attr
+	ErrorMsg string
+	Error    bool
+}
+
+// SpanContext returns the span's propagation context.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.Context
+}
+
+// SetName renames the span, e.g. once the HTTP route is known.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes adds or replaces attributes.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range attrs {
+		replaced := false
+		for i := range s.data.Attrs {
+			if s.data.Attrs[i].Key == a.Key {
+				s.data.Attrs[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.Attrs = append(s.data.Attrs, a)
+		}
+	}
+}
+
+// RecordError marks the span as failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Error = true
+	s.data.ErrorMsg = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export when sampled. Calling End
+// more than once has no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attrs = append([]Attr(nil), s.data.Attrs...)
+	s.mu.Unlock()
+
+	if data.Context.Sampled {
+		s.tracer.enqueue(data)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns ctx carrying span as the current span. It is used
+// to carry a request's span into contexts that do not derive from the
+// request, such as a tab's chromedp context.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// TraceIDFromContext returns the hex trace ID of the current span, or "".
+func TraceIDFromContext(ctx context.Context) string {
+	if s := SpanFromContext(ctx); s != nil {
+		return s.data.Context.TraceID.String()
+	}
+	return ""
+}
+
+// Start begins an internal child span of the span in ctx. It returns ctx
+// unchanged and a nil span when no tracer is installed.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, KindInternal, SpanContext{}, attrs)
+}
+
+// StartClient begins a span for an outgoing call; pair it with Inject.
+func StartClient(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return start(ctx, name, KindClient, SpanContext{}, attrs)
+}
+
+func start(ctx context.Context, name string, kind SpanKind, remote SpanContext, attrs []Attr) (context.Context, *Span) {
+	t := global.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	parent := remote
+	if p := SpanFromContext(ctx); p != nil {
+		parent = p.data.Context
+	}
+
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample()
+	}
+	span := &Span{tracer: t, data: SpanData{
+		Name:    name,
+		Kind:    kind,
+		Context: sc,
+		Parent:  parent.SpanID,
+		Start:   time.Now(),
+		Attrs:   attrs,
+	}}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+const (
+	maxQueuedSpans = 2048
+	maxBatchSize   = 512
+	flushInterval  = 2 * time.Second
+)
+
+// Config configures a Tracer.
+type Config struct {
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Exporter receives ended, sampled spans in batches.
+	Exporter Exporter
+	// SampleRatio is the fraction of root spans that are sampled. Spans with
+	// a remote or local parent follow the parent's decision.
+	SampleRatio float64
+}
+
+// Tracer batches ended spans and hands them to its exporter.
+type Tracer struct {
+	service  string
+	exporter Exporter
+	ratio    float64
+
+	queue    chan SpanData
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+	dropped  atomic.Uint64
+}
+
+// New starts a tracer. Call Shutdown to flush pending spans.
+func New(cfg Config) *Tracer {
+	t := &Tracer{
+		service:  cfg.ServiceName,
+		exporter: cfg.Exporter,
+		ratio:    cfg.SampleRatio,
+		queue:    make(chan SpanData, maxQueuedSpans),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.loop()
+	return t
+}
+
+var global atomic.Pointer[Tracer]
+
+// Install makes t the tracer used by Start and Middleware. Passing nil
+// disables tracing.
+func Install(t *Tracer) { global.Store(t) }
+
+// Enabled reports whether a tracer is installed.
+func Enabled() bool { return global.Load() != nil }
+
+func (t *Tracer) sample() bool {
+	if t.ratio >= 1 {
+		return true
+	}
+	if t.ratio <= 0 {
+		return false
+	}
+	return mathrand.Float64() < t.ratio
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case <-t.stop:
+		return
+	default:
+	}
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(context.Background(), t.service, batch); err != nil {
+			slog.Warn("trace export failed", "spans", len(batch), "err", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.stop:
+			for {
+				select {
+				case data := <-t.queue:
+					batch = append(batch, data)
+					if len(batch) >= maxBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Shutdown flushes queued spans and stops the export loop. It uninstalls t
+// if it is the global tracer.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	global.CompareAndSwap(t, nil)
+	t.stopOnce.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if n := t.dropped.Load(); n > 0 {
+		slog.Warn("trace spans dropped because the export queue was full", "spans", n)
+	}
+	return nil
+}
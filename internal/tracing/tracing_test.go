@@ -0,0 +1,258 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *memoryExporter) Export(_ context.Context, _ string, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func installTracer(t *testing.T, ratio float64) *memoryExporter {
+	t.Helper()
+	exp := &memoryExporter{}
+	tracer := New(Config{ServiceName: "test", Exporter: exp, SampleRatio: ratio})
+	Install(tracer)
+	t.Cleanup(func() { _ = tracer.Shutdown(context.Background()) })
+	return exp
+}
+
+func flush(t *testing.T, exp *memoryExporter) []SpanData {
+	t.Helper()
+	tracer := global.Load()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	exp.mu.Lock()
+	defer exp.mu.Unlock()
+	return append([]SpanData(nil), exp.spans...)
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Fatalf("parsed %+v", sc)
+	}
+	if got := FormatTraceparent(sc); got != header {
+		t.Fatalf("format = %q", got)
+	}
+}
+
+func TestParseTraceparentRejectsMalformed(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(v); err == nil {
+			t.Errorf("ParseTraceparent(%q) succeeded", v)
+		}
+	}
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); err != nil {
+		t.Errorf("future version with extra fields should parse: %v", err)
+	}
+}
+
+func TestNilSpanIsSafe(t *testing.T) {
+	Install(nil)
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("expected no span without a tracer")
+	}
+	span.SetName("x")
+	span.SetAttributes(String("k", "v"))
+	span.RecordError(errors.New("boom"))
+	span.End()
+	h := http.Header{}
+	Inject(ctx, h)
+	if h.Get(TraceparentHeader) != "" {
+		t.Fatal("inject without a span should not set traceparent")
+	}
+}
+
+func TestMiddlewareContinuesRemoteTraceAndSetsRequestID(t *testing.T) {
+	exp := installTracer(t, 1)
+
+	var requestID, childParent string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-Id")
+		ctx, child := Start(r.Context(), "selector.resolve")
+		out := http.Header{}
+		Inject(ctx, out)
+		childParent = out.Get(TraceparentHeader)
+		child.End()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/tabs/t1/action", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if requestID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("request id = %q, want the trace id", requestID)
+	}
+	if !strings.HasPrefix(childParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Fatalf("child traceparent = %q", childParent)
+	}
+
+	spans := flush(t, exp)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	child, server := spans[0], spans[1]
+	if server.Kind != KindServer || server.Parent.String() != "00f067aa0ba902b7" {
+		t.Fatalf("server span = %+v", server)
+	}
+	if child.Parent != server.Context.SpanID {
+		t.Fatalf("child parent %s, want %s", child.Parent, server.Context.SpanID)
+	}
+	if !server.Error {
+		t.Fatal("5xx response should mark the server span as failed")
+	}
+}
+
+func TestMiddlewareKeepsCallerRequestID(t *testing.T) {
+	exp := installTracer(t, 1)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Request-Id"); got != "caller-1" {
+			t.Errorf("request id = %q", got)
+		}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("X-Request-Id", "caller-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := flush(t, exp)
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	found := false
+	for _, a := range spans[0].Attrs {
+		if a.Key == "pinchtab.request_id" && a.Value == "caller-1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("caller request id not recorded: %+v", spans[0].Attrs)
+	}
+}
+
+func TestUnsampledParentIsPropagatedButNotExported(t *testing.T) {
+	exp := installTracer(t, 1)
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, child := Start(r.Context(), "tab.execute")
+		child.End()
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/snapshot", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if spans := flush(t, exp); len(spans) != 0 {
+		t.Fatalf("unsampled trace exported %d spans", len(spans))
+	}
+}
+
+func TestFileExporterWritesOTLPJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "traces.jsonl")
+	exp, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1700000000, 0)
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	err = exp.Export(context.Background(), "pinchtab", []SpanData{{
+		Name: "GET /tabs/{id}/snapshot", Kind: KindServer, Context: sc,
+		Start: start, End: start.Add(time.Millisecond),
+		Attrs:    []Attr{String("http.route", "/tabs/{id}/snapshot"), Int("http.response.status_code", 500)},
+		Error:    true,
+		ErrorMsg: "HTTP 500",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		t.Fatal("expected one JSON line per batch")
+	}
+	var req struct {
+		ResourceSpans []struct {
+			Resource struct {
+				Attributes []otlpKeyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	rs := req.ResourceSpans[0]
+	if *rs.Resource.Attributes[0].Value.StringValue != "pinchtab" {
+		t.Fatalf("service.name = %+v", rs.Resource.Attributes)
+	}
+	span := rs.ScopeSpans[0].Spans[0]
+	if span.TraceID != sc.TraceID.String() || span.Kind != 2 || span.StartTimeUnixNano != "1700000000000000000" {
+		t.Fatalf("span = %+v", span)
+	}
+	if span.Status == nil || span.Status.Code != 2 {
+		t.Fatalf("status = %+v", span.Status)
+	}
+	if *span.Attributes[1].Value.IntValue != "500" {
+		t.Fatalf("int attribute = %+v", span.Attributes[1])
+	}
+}
+
+func TestHTTPExporterPostsToCollector(t *testing.T) {
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(r.Body)
+		got = buf.Bytes()
+	}))
+	t.Cleanup(srv.Close)
+
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	err := NewHTTPExporter(srv.URL+"/v1/traces").Export(context.Background(), "pinchtab", []SpanData{{Name: "cdp.action", Kind: KindInternal, Context: sc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte(`"name":"cdp.action"`)) {
+		t.Fatalf("collector body = %s", got)
+	}
+}
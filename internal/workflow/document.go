@@ -0,0 +1,351 @@
+// Package workflow runs declarative multi-step browser flows. A workflow is a
+// YAML or JSON document of steps (actions, navigation, waits, extraction,
+// branches and loops) that is executed server-side against a single tab, with
+// variables, step outputs that later steps can reference, per-step retries and
+// a per-step trace.
+//
+// The package is browser-agnostic: the browser-facing primitives are supplied
+// by a Runner, which the handlers package implements on top of the action
+// registry and the /wait semantics.
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MaxRetryAttempts caps retry.attempts for a single step.
+	MaxRetryAttempts = 10
+	// MaxRetryDelayMs caps retry.delayMs for a single step.
+	MaxRetryDelayMs = 60_000
+	// DefaultLoopMax is the iteration cap applied when loop.max is omitted.
+	DefaultLoopMax = 100
+	// MaxLoopMax caps loop.max.
+	MaxLoopMax = 1000
+)
+
+// Step types, as reported by Step.Type and in the run trace.
+const (
+	StepAction   = "action"
+	StepNavigate = "navigate"
+	StepWait     = "wait"
+	StepExtract  = "extract"
+	StepSet      = "set"
+	StepIf       = "if"
+	StepLoop     = "loop"
+)
+
+var stepIDPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Document is a parsed workflow definition.
+type Document struct {
+	Name       string         `json:"name,omitempty"`
+	Vars       map[string]any `json:"vars,omitempty"`
+	TimeoutSec int            `json:"timeoutSec,omitempty"`
+	Steps      []Step         `json:"steps"`
+}
+
+// Step is one node of a workflow. Exactly one of the type fields (action,
+// navigate, wait, extract, set, if, loop) must be set; then/else only apply
+// to if steps.
+type Step struct {
+	ID string `json:"id,omitempty"`
+
+	// Action is an /action request body (kind, selector, text, ...).
+	Action map[string]any `json:"action,omitempty"`
+	// Navigate is a /navigate request body (url, newTab, waitFor, ...).
+	Navigate map[string]any `json:"navigate,omitempty"`
+	// Wait is a /wait request body (selector, text, url, load, fn, ms, ...).
+	Wait    map[string]any `json:"wait,omitempty"`
+	Extract *Extract       `json:"extract,omitempty"`
+	// Set assigns workflow variables; values are interpolated.
+	Set  map[string]any `json:"set,omitempty"`
+	If   *Condition     `json:"if,omitempty"`
+	Then []Step         `json:"then,omitempty"`
+	Else []Step         `json:"else,omitempty"`
+	Loop *Loop          `json:"loop,omitempty"`
+
+	Retry           *Retry `json:"retry,omitempty"`
+	TimeoutSec      int    `json:"timeoutSec,omitempty"`
+	ContinueOnError bool   `json:"continueOnError,omitempty"`
+}
+
+// Extract reads content from the elements matching Selector. Attr selects
+// what is read: "text" (default), "html", or any attribute name. With Fields,
+// each match yields an object whose keys are read from sub-selectors relative
+// to the match. All returns every match instead of only the first.
+type Extract struct {
+	Selector string            `json:"selector"`
+	Attr     string            `json:"attr,omitempty"`
+	All      bool              `json:"all,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// Condition is a predicate used by if steps and loops. Exactly one field must
+// be set. Exists, NotExists, Text, URL and Fn are evaluated in the page with
+// the same semantics as /wait; Value is interpolated and tested for
+// truthiness without touching the browser.
+type Condition struct {
+	Exists    string `json:"exists,omitempty"`
+	NotExists string `json:"notExists,omitempty"`
+	Text      string `json:"text,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Fn        string `json:"fn,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+// Loop repeats Steps. While is checked before every iteration, Until after
+// every iteration, and Items iterates over a list (literal or a ${...}
+// reference), binding each element to As (default "item"). Max caps the
+// number of iterations for every form.
+type Loop struct {
+	While *Condition `json:"while,omitempty"`
+	Until *Condition `json:"until,omitempty"`
+	Items any        `json:"items,omitempty"`
+	As    string     `json:"as,omitempty"`
+	Max   int        `json:"max,omitempty"`
+	Steps []Step     `json:"steps"`
+}
+
+// Retry re-runs a failed step. Attempts counts the first try.
+type Retry struct {
+	Attempts int `json:"attempts,omitempty"`
+	DelayMs  int `json:"delayMs,omitempty"`
+}
+
+// Parse decodes a workflow from YAML or JSON (JSON is accepted as YAML).
+// Unknown fields are rejected so typos surface instead of being ignored.
+func Parse(data []byte) (*Document, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("workflow document is empty")
+	}
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	if _, ok := raw.(map[string]any); !ok {
+		return nil, fmt.Errorf("workflow document must be an object")
+	}
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(normalized))
+	dec.DisallowUnknownFields()
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse workflow: %w", err)
+	}
+	return &doc, nil
+}
+
+// Type returns the step type, or "" when no type field is set.
+func (s *Step) Type() string {
+	if types := s.types(); len(types) > 0 {
+		return types[0]
+	}
+	return ""
+}
+
+func (s *Step) types() []string {
+	var out []string
+	if s.Action != nil {
+		out = append(out, StepAction)
+	}
+	if s.Navigate != nil {
+		out = append(out, StepNavigate)
+	}
+	if s.Wait != nil {
+		out = append(out, StepWait)
+	}
+	if s.Extract != nil {
+		out = append(out, StepExtract)
+	}
+	if s.Set != nil {
+		out = append(out, StepSet)
+	}
+	if s.If != nil {
+		out = append(out, StepIf)
+	}
+	if s.Loop != nil {
+		out = append(out, StepLoop)
+	}
+	return out
+}
+
+// Validate checks the document structure. actionKinds, when non-empty, is the
+// set of action kinds the runner supports; literal action kinds outside it
+// are rejected up front rather than failing mid-run.
+func (d *Document) Validate(actionKinds []string) error {
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+	if d.TimeoutSec < 0 {
+		return fmt.Errorf("timeoutSec must be >= 0")
+	}
+	for name := range d.Vars {
+		if !stepIDPattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+	v := validator{kinds: make(map[string]bool, len(actionKinds)), ids: map[string]bool{}}
+	for _, k := range actionKinds {
+		v.kinds[k] = true
+	}
+	return v.steps(d.Steps, "steps")
+}
+
+type validator struct {
+	kinds map[string]bool
+	ids   map[string]bool
+}
+
+func (v *validator) steps(steps []Step, path string) error {
+	for i := range steps {
+		if err := v.step(&steps[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) step(s *Step, path string) error {
+	types := s.types()
+	switch len(types) {
+	case 0:
+		return fmt.Errorf("%s: step needs one of action, navigate, wait, extract, set, if, loop", path)
+	case 1:
+	default:
+		return fmt.Errorf("%s: step sets more than one of %s", path, strings.Join(types, ", "))
+	}
+	if s.ID != "" {
+		if !stepIDPattern.MatchString(s.ID) {
+			return fmt.Errorf("%s: invalid id %q", path, s.ID)
+		}
+		if v.ids[s.ID] {
+			return fmt.Errorf("%s: duplicate id %q", path, s.ID)
+		}
+		v.ids[s.ID] = true
+	}
+	if s.TimeoutSec < 0 {
+		return fmt.Errorf("%s: timeoutSec must be >= 0", path)
+	}
+	if s.Retry != nil {
+		if s.Retry.Attempts < 0 || s.Retry.Attempts > MaxRetryAttempts {
+			return fmt.Errorf("%s: retry.attempts must be between 0 and %d", path, MaxRetryAttempts)
+		}
+		if s.Retry.DelayMs < 0 || s.Retry.DelayMs > MaxRetryDelayMs {
+			return fmt.Errorf("%s: retry.delayMs must be between 0 and %d", path, MaxRetryDelayMs)
+		}
+	}
+	if types[0] != StepIf && (len(s.Then) > 0 || len(s.Else) > 0) {
+		return fmt.Errorf("%s: then/else are only valid on if steps", path)
+	}
+
+	switch types[0] {
+	case StepAction:
+		kind, _ := s.Action["kind"].(string)
+		if kind == "" {
+			return fmt.Errorf("%s: action.kind is required", path)
+		}
+		if len(v.kinds) > 0 && !hasReference(kind) && !v.kinds[kind] {
+			return fmt.Errorf("%s: unknown action kind %q", path, kind)
+		}
+	case StepNavigate:
+		if url, _ := s.Navigate["url"].(string); url == "" {
+			return fmt.Errorf("%s: navigate.url is required", path)
+		}
+	case StepWait:
+		if len(s.Wait) == 0 {
+			return fmt.Errorf("%s: wait needs one of selector, text, notText, url, load, fn, ms", path)
+		}
+	case StepExtract:
+		if s.Extract.Selector == "" {
+			return fmt.Errorf("%s: extract.selector is required", path)
+		}
+	case StepSet:
+		for name := range s.Set {
+			if !stepIDPattern.MatchString(name) {
+				return fmt.Errorf("%s: invalid variable name %q", path, name)
+			}
+		}
+	case StepIf:
+		if err := s.If.validate(); err != nil {
+			return fmt.Errorf("%s.if: %w", path, err)
+		}
+		if len(s.Then) == 0 && len(s.Else) == 0 {
+			return fmt.Errorf("%s: if step needs then or else steps", path)
+		}
+		if err := v.steps(s.Then, path+".then"); err != nil {
+			return err
+		}
+		if err := v.steps(s.Else, path+".else"); err != nil {
+			return err
+		}
+	case StepLoop:
+		if err := v.loop(s.Loop, path+".loop"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) loop(l *Loop, path string) error {
+	forms := 0
+	if l.While != nil {
+		forms++
+		if err := l.While.validate(); err != nil {
+			return fmt.Errorf("%s.while: %w", path, err)
+		}
+	}
+	if l.Until != nil {
+		forms++
+		if err := l.Until.validate(); err != nil {
+			return fmt.Errorf("%s.until: %w", path, err)
+		}
+	}
+	if l.Items != nil {
+		forms++
+		switch items := l.Items.(type) {
+		case []any:
+		case string:
+			if !hasReference(items) {
+				return fmt.Errorf("%s: items must be a list or a ${...} reference", path)
+			}
+		default:
+			return fmt.Errorf("%s: items must be a list or a ${...} reference", path)
+		}
+	}
+	if forms != 1 {
+		return fmt.Errorf("%s: loop needs exactly one of while, until, items", path)
+	}
+	if l.As != "" && !stepIDPattern.MatchString(l.As) {
+		return fmt.Errorf("%s: invalid as %q", path, l.As)
+	}
+	if l.Max < 0 || l.Max > MaxLoopMax {
+		return fmt.Errorf("%s: max must be between 0 and %d", path, MaxLoopMax)
+	}
+	if len(l.Steps) == 0 {
+		return fmt.Errorf("%s: loop has no steps", path)
+	}
+	return v.steps(l.Steps, path+".steps")
+}
+
+func (c *Condition) validate() error {
+	set := 0
+	for _, f := range []string{c.Exists, c.NotExists, c.Text, c.URL, c.Fn, c.Value} {
+		if f != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("condition needs exactly one of exists, notExists, text, url, fn, value")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLAndJSON(t *testing.T) {
+	yamlDoc := `
+name: search
+vars:
+  query: pinchtab
+steps:
+  - id: open
+    navigate: {url: "https://example.com/?q=${vars.query}"}
+  - wait: {selector: ".results"}
+  - if: {exists: ".cookie-banner"}
+    then:
+      - action: {kind: click, selector: ".cookie-banner button"}
+  - loop:
+      until: {notExists: "a.next"}
+      max: 5
+      steps:
+        - id: rows
+          extract: {selector: ".result h3", all: true}
+        - action: {kind: click, selector: "a.next"}
+          retry: {attempts: 3, delayMs: 100}
+`
+	doc, err := Parse([]byte(yamlDoc))
+	if err != nil {
+		t.Fatalf("parse yaml: %v", err)
+	}
+	if doc.Name != "search" || len(doc.Steps) != 4 || doc.Vars["query"] != "pinchtab" {
+		t.Fatalf("unexpected doc: %+v", doc)
+	}
+	if got := doc.Steps[3].Loop.Steps[1].Retry.Attempts; got != 3 {
+		t.Errorf("retry attempts = %d, want 3", got)
+	}
+	if err := doc.Validate([]string{"click"}); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	jsonDoc := `{"steps":[{"action":{"kind":"click","selector":"#go"}}]}`
+	doc, err = Parse([]byte(jsonDoc))
+	if err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if doc.Steps[0].Type() != StepAction {
+		t.Errorf("type = %q, want action", doc.Steps[0].Type())
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	if _, err := Parse([]byte(`{"steps":[{"clik":{}}]}`)); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+	if _, err := Parse([]byte(`- just a list`)); err == nil {
+		t.Fatal("expected error for non-object document")
+	}
+	if _, err := Parse([]byte("  ")); err == nil {
+		t.Fatal("expected error for empty document")
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"no steps", `{"steps":[]}`, "no steps"},
+		{"no type", `{"steps":[{"id":"a"}]}`, "step needs one of"},
+		{"two types", `{"steps":[{"wait":{"ms":1},"set":{"a":1}}]}`, "more than one"},
+		{"duplicate id", `{"steps":[{"id":"a","wait":{"ms":1}},{"id":"a","wait":{"ms":1}}]}`, "duplicate id"},
+		{"unknown kind", `{"steps":[{"action":{"kind":"teleport"}}]}`, "unknown action kind"},
+		{"missing kind", `{"steps":[{"action":{"selector":"#a"}}]}`, "action.kind is required"},
+		{"navigate url", `{"steps":[{"navigate":{}}]}`, "navigate.url is required"},
+		{"then without if", `{"steps":[{"wait":{"ms":1},"then":[{"wait":{"ms":1}}]}]}`, "only valid on if"},
+		{"if empty", `{"steps":[{"if":{"exists":"#a"}}]}`, "needs then or else"},
+		{"condition ambiguous", `{"steps":[{"if":{"exists":"#a","text":"x"},"then":[{"wait":{"ms":1}}]}]}`, "exactly one of"},
+		{"loop forms", `{"steps":[{"loop":{"steps":[{"wait":{"ms":1}}]}}]}`, "exactly one of while, until, items"},
+		{"loop items literal", `{"steps":[{"loop":{"items":"abc","steps":[{"wait":{"ms":1}}]}}]}`, "items must be a list"},
+		{"loop max", `{"steps":[{"loop":{"items":[1],"max":5000,"steps":[{"wait":{"ms":1}}]}}]}`, "max must be between"},
+		{"retry attempts", `{"steps":[{"wait":{"ms":1},"retry":{"attempts":50}}]}`, "retry.attempts"},
+		{"nested path", `{"steps":[{"if":{"value":"x"},"then":[{"navigate":{}}]}]}`, "steps[0].then[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			err = doc.Validate([]string{"click"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAllowsInterpolatedKind(t *testing.T) {
+	doc, err := Parse([]byte(`{"steps":[{"action":{"kind":"${vars.kind}"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate([]string{"click"}); err != nil {
+		t.Fatalf("interpolated kind should defer to run time: %v", err)
+	}
+}
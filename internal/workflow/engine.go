@@ -0,0 +1,533 @@
+package workflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds a run whose document sets no timeoutSec.
+	DefaultTimeout = 5 * time.Minute
+	// MaxTimeout caps a document's timeoutSec.
+	MaxTimeout = time.Hour
+	// MaxExecutedSteps bounds the total number of step executions (including
+	// loop iterations and retries) in one run.
+	MaxExecutedSteps = 5000
+)
+
+// Status is the lifecycle state of a run.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// IsTerminal reports whether the run has finished.
+func (s Status) IsTerminal() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusCancelled
+}
+
+// Step trace statuses.
+const (
+	TraceRunning = "running"
+	TraceOK      = "ok"
+	TraceFailed  = "failed"
+)
+
+// Runner performs the browser-facing work of a workflow. Outputs must be
+// plain JSON values (maps, slices, strings, float64, bool, nil) so later steps
+// can reference into them. A map output carrying a non-empty "tabId" moves
+// the run to that tab (e.g. navigate with newTab).
+type Runner interface {
+	Action(ctx context.Context, tabID string, params map[string]any) (any, error)
+	Navigate(ctx context.Context, tabID string, params map[string]any) (any, error)
+	Wait(ctx context.Context, tabID string, params map[string]any) (any, error)
+	Extract(ctx context.Context, tabID string, ex Extract) (any, error)
+	Check(ctx context.Context, tabID string, cond Condition) (bool, error)
+}
+
+// StepTrace records one executed step. Path locates the step in the document,
+// e.g. "steps[2].then[0]" or "steps[3].loop[1].steps[0]" for the second
+// iteration of a loop.
+type StepTrace struct {
+	Path       string    `json:"path"`
+	ID         string    `json:"id,omitempty"`
+	Type       string    `json:"type"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Output     any       `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Run is one execution of a workflow document. Fields are guarded by mu while
+// the run is in flight; callers read them through Snapshot.
+type Run struct {
+	mu sync.Mutex
+
+	ID          string         `json:"runId"`
+	Name        string         `json:"name,omitempty"`
+	Status      Status         `json:"status"`
+	TabID       string         `json:"tabId,omitempty"`
+	Owner       string         `json:"owner,omitempty"`
+	Vars        map[string]any `json:"vars,omitempty"`
+	Outputs     map[string]any `json:"outputs,omitempty"`
+	Trace       []StepTrace    `json:"trace"`
+	Error       string         `json:"error,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	CompletedAt time.Time      `json:"completedAt,omitempty"`
+	DurationMs  int64          `json:"durationMs,omitempty"`
+	// Caller identifies who started the run; only they may read or cancel
+	// it. Empty means an unscoped caller.
+	Caller string `json:"-"`
+
+	cancel          context.CancelFunc
+	cancelRequested bool
+}
+
+// NewRun prepares a run of doc. Caller vars override the document defaults.
+func NewRun(doc *Document, tabID, owner string, vars map[string]any) *Run {
+	merged := make(map[string]any, len(doc.Vars)+len(vars))
+	maps.Copy(merged, doc.Vars)
+	maps.Copy(merged, vars)
+	return &Run{
+		ID:        generateRunID(),
+		Name:      doc.Name,
+		Status:    StatusRunning,
+		TabID:     tabID,
+		Owner:     owner,
+		Vars:      merged,
+		Outputs:   map[string]any{},
+		Trace:     []StepTrace{},
+		CreatedAt: time.Now(),
+	}
+}
+
+// Snapshot returns a copy safe to read and serialize while the run proceeds.
+func (r *Run) Snapshot() *Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Run{
+		ID:          r.ID,
+		Name:        r.Name,
+		Status:      r.Status,
+		TabID:       r.TabID,
+		Owner:       r.Owner,
+		Vars:        maps.Clone(r.Vars),
+		Outputs:     maps.Clone(r.Outputs),
+		Trace:       append([]StepTrace(nil), r.Trace...),
+		Error:       r.Error,
+		CreatedAt:   r.CreatedAt,
+		CompletedAt: r.CompletedAt,
+		DurationMs:  r.DurationMs,
+		Caller:      r.Caller,
+	}
+}
+
+// Cancel stops the run at its next step boundary, or before its first step
+// when Execute has not started yet. It reports false for a finished run.
+func (r *Run) Cancel() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Status.IsTerminal() {
+		return false
+	}
+	r.cancelRequested = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return true
+}
+
+// Timeout returns the run deadline for doc, defaulted and capped.
+func (d *Document) Timeout() time.Duration {
+	if d.TimeoutSec <= 0 {
+		return DefaultTimeout
+	}
+	if t := time.Duration(d.TimeoutSec) * time.Second; t < MaxTimeout {
+		return t
+	}
+	return MaxTimeout
+}
+
+// Execute runs doc to completion, recording progress on run. It returns when
+// the run reaches a terminal state; ctx cancellation marks it cancelled.
+func Execute(ctx context.Context, run *Run, doc *Document, runner Runner) {
+	ctx, cancel := context.WithTimeout(ctx, doc.Timeout())
+	defer cancel()
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	run.mu.Lock()
+	run.cancel = stop
+	if run.cancelRequested {
+		stop()
+	}
+	run.mu.Unlock()
+
+	e := &executor{run: run, runner: runner, steps: map[string]any{}}
+	err := e.runSteps(ctx, doc.Steps, "steps", nil)
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.cancel = nil
+	run.CompletedAt = time.Now()
+	run.DurationMs = run.CompletedAt.Sub(run.CreatedAt).Milliseconds()
+	switch {
+	case err == nil:
+		run.Status = StatusDone
+	case errors.Is(err, context.Canceled):
+		run.Status = StatusCancelled
+		run.Error = err.Error()
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		run.Status = StatusFailed
+		run.Error = fmt.Sprintf("workflow timed out after %s: %v", doc.Timeout(), err)
+	default:
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+}
+
+type executor struct {
+	run      *Run
+	runner   Runner
+	executed int
+	// steps is the ${steps.<id>} scope: id -> {output, status, error}.
+	// Only touched by the executing goroutine.
+	steps map[string]any
+}
+
+func (e *executor) scope(locals map[string]any) map[string]any {
+	e.run.mu.Lock()
+	vars := maps.Clone(e.run.Vars)
+	e.run.mu.Unlock()
+	scope := map[string]any{"vars": vars, "steps": e.steps}
+	maps.Copy(scope, locals)
+	return scope
+}
+
+func (e *executor) tabID() string {
+	e.run.mu.Lock()
+	defer e.run.mu.Unlock()
+	return e.run.TabID
+}
+
+func (e *executor) runSteps(ctx context.Context, steps []Step, path string, locals map[string]any) error {
+	for i := range steps {
+		if err := e.runStep(ctx, &steps[i], fmt.Sprintf("%s[%d]", path, i), locals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *executor) runStep(ctx context.Context, s *Step, path string, locals map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.executed++
+	if e.executed > MaxExecutedSteps {
+		return fmt.Errorf("workflow exceeded %d executed steps", MaxExecutedSteps)
+	}
+
+	start := time.Now()
+	e.run.mu.Lock()
+	idx := len(e.run.Trace)
+	e.run.Trace = append(e.run.Trace, StepTrace{
+		Path: path, ID: s.ID, Type: s.Type(), Status: TraceRunning, StartedAt: start,
+	})
+	e.run.mu.Unlock()
+
+	attempts := 1
+	var delay time.Duration
+	if s.Retry != nil {
+		attempts = max(s.Retry.Attempts, 1)
+		delay = time.Duration(s.Retry.DelayMs) * time.Millisecond
+	}
+
+	var (
+		out any
+		err error
+		n   int
+	)
+	for n = 1; n <= attempts; n++ {
+		out, err = e.exec(ctx, s, path, locals)
+		if err == nil || ctx.Err() != nil || n == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+
+	status := TraceOK
+	errMsg := ""
+	if err != nil {
+		status = TraceFailed
+		errMsg = err.Error()
+	}
+
+	e.run.mu.Lock()
+	tr := &e.run.Trace[idx]
+	tr.Status = status
+	tr.Attempts = n
+	tr.DurationMs = time.Since(start).Milliseconds()
+	tr.Output = out
+	tr.Error = errMsg
+	if s.ID != "" {
+		e.run.Outputs[s.ID] = out
+	}
+	if m, ok := out.(map[string]any); ok && s.Type() != StepSet {
+		if tab, _ := m["tabId"].(string); tab != "" {
+			e.run.TabID = tab
+		}
+	}
+	e.run.mu.Unlock()
+
+	if s.ID != "" {
+		entry := map[string]any{"output": out, "status": status}
+		if errMsg != "" {
+			entry["error"] = errMsg
+		}
+		e.steps[s.ID] = entry
+	}
+
+	if err != nil && !(s.ContinueOnError && ctx.Err() == nil) {
+		label := path
+		if s.ID != "" {
+			label = fmt.Sprintf("%s (%s)", path, s.ID)
+		}
+		if errors.Is(err, errStepAborted) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", label, abort(err))
+	}
+	return nil
+}
+
+// errStepAborted marks an error that already carries the failing step's
+// location, so enclosing if/loop steps don't prefix it again.
+var errStepAborted = errors.New("workflow step failed")
+
+type abortError struct{ err error }
+
+func (a abortError) Error() string { return a.err.Error() }
+func (a abortError) Unwrap() []error {
+	return []error{errStepAborted, a.err}
+}
+
+func abort(err error) error {
+	if errors.Is(err, errStepAborted) {
+		return err
+	}
+	return abortError{err: err}
+}
+
+func (e *executor) exec(ctx context.Context, s *Step, path string, locals map[string]any) (any, error) {
+	switch s.Type() {
+	case StepIf:
+		return e.execIf(ctx, s, path, locals)
+	case StepLoop:
+		return e.execLoop(ctx, s.Loop, path, locals)
+	case StepSet:
+		return e.execSet(s.Set, locals)
+	}
+
+	if s.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.TimeoutSec)*time.Second)
+		defer cancel()
+	}
+	scope := e.scope(locals)
+	tabID := e.tabID()
+
+	switch s.Type() {
+	case StepAction, StepNavigate, StepWait:
+		raw := s.Action
+		call := e.runner.Action
+		if s.Navigate != nil {
+			raw, call = s.Navigate, e.runner.Navigate
+		} else if s.Wait != nil {
+			raw, call = s.Wait, e.runner.Wait
+		}
+		params, err := Interpolate(raw, scope)
+		if err != nil {
+			return nil, err
+		}
+		return call(ctx, tabID, params.(map[string]any))
+	case StepExtract:
+		ex := *s.Extract
+		var err error
+		if ex.Selector, err = InterpolateString(ex.Selector, scope); err != nil {
+			return nil, err
+		}
+		if ex.Attr, err = InterpolateString(ex.Attr, scope); err != nil {
+			return nil, err
+		}
+		return e.runner.Extract(ctx, tabID, ex)
+	}
+	return nil, fmt.Errorf("unsupported step type %q", s.Type())
+}
+
+func (e *executor) execSet(set map[string]any, locals map[string]any) (any, error) {
+	resolved, err := Interpolate(set, e.scope(locals))
+	if err != nil {
+		return nil, err
+	}
+	values := resolved.(map[string]any)
+	e.run.mu.Lock()
+	maps.Copy(e.run.Vars, values)
+	e.run.mu.Unlock()
+	return values, nil
+}
+
+func (e *executor) execIf(ctx context.Context, s *Step, path string, locals map[string]any) (any, error) {
+	matched, err := e.check(ctx, s.If, locals)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{"matched": matched}
+	if matched {
+		return out, e.runSteps(ctx, s.Then, path+".then", locals)
+	}
+	return out, e.runSteps(ctx, s.Else, path+".else", locals)
+}
+
+func (e *executor) execLoop(ctx context.Context, l *Loop, path string, locals map[string]any) (any, error) {
+	limit := l.Max
+	if limit == 0 {
+		limit = DefaultLoopMax
+	}
+	as := l.As
+	if as == "" {
+		as = "item"
+	}
+
+	var items []any
+	if l.Items != nil {
+		resolved, err := Interpolate(l.Items, e.scope(locals))
+		if err != nil {
+			return nil, err
+		}
+		list, ok := resolved.([]any)
+		if !ok {
+			return nil, fmt.Errorf("loop items resolved to %T, want a list", resolved)
+		}
+		items = list
+	}
+
+	ids := collectIDs(l.Steps)
+	results := []any{}
+	iterations := 0
+	for i := 0; i < limit; i++ {
+		if l.Items != nil && i >= len(items) {
+			break
+		}
+		iterLocals := maps.Clone(locals)
+		if iterLocals == nil {
+			iterLocals = map[string]any{}
+		}
+		iterLocals["loop"] = map[string]any{"index": i}
+		if l.Items != nil {
+			iterLocals[as] = items[i]
+		}
+		if l.While != nil {
+			ok, err := e.check(ctx, l.While, iterLocals)
+			if err != nil {
+				return loopOutput(iterations, results), err
+			}
+			if !ok {
+				break
+			}
+		}
+
+		for _, id := range ids {
+			delete(e.steps, id)
+		}
+		err := e.runSteps(ctx, l.Steps, fmt.Sprintf("%s.loop[%d].steps", path, i), iterLocals)
+		iterations++
+		iter := map[string]any{}
+		for _, id := range ids {
+			if entry, ok := e.steps[id].(map[string]any); ok {
+				iter[id] = entry["output"]
+			}
+		}
+		results = append(results, iter)
+		if err != nil {
+			return loopOutput(iterations, results), err
+		}
+
+		if l.Until != nil {
+			done, err := e.check(ctx, l.Until, iterLocals)
+			if err != nil {
+				return loopOutput(iterations, results), err
+			}
+			if done {
+				break
+			}
+		}
+	}
+	return loopOutput(iterations, results), nil
+}
+
+func loopOutput(iterations int, results []any) map[string]any {
+	return map[string]any{"iterations": iterations, "results": results}
+}
+
+func (e *executor) check(ctx context.Context, c *Condition, locals map[string]any) (bool, error) {
+	scope := e.scope(locals)
+	if c.Value != "" {
+		v, err := interpolateString(c.Value, scope)
+		if err != nil {
+			return false, err
+		}
+		return Truthy(v), nil
+	}
+	resolved := *c
+	for _, field := range []*string{&resolved.Exists, &resolved.NotExists, &resolved.Text, &resolved.URL, &resolved.Fn} {
+		if *field == "" {
+			continue
+		}
+		v, err := InterpolateString(*field, scope)
+		if err != nil {
+			return false, err
+		}
+		*field = v
+	}
+	return e.runner.Check(ctx, e.tabID(), resolved)
+}
+
+func collectIDs(steps []Step) []string {
+	var ids []string
+	for i := range steps {
+		s := &steps[i]
+		if s.ID != "" {
+			ids = append(ids, s.ID)
+		}
+		ids = append(ids, collectIDs(s.Then)...)
+		ids = append(ids, collectIDs(s.Else)...)
+		if s.Loop != nil {
+			ids = append(ids, collectIDs(s.Loop.Steps)...)
+		}
+	}
+	return ids
+}
+
+// generateRunID produces a random run ID in the format wfr_XXXXXXXXXXXX.
+func generateRunID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("wfr_%012x", time.Now().UnixNano()&0xFFFFFFFFFFFF)
+	}
+	return "wfr_" + hex.EncodeToString(b)
+}
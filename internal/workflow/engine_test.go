@@ -0,0 +1,275 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRunner simulates a paginated listing: "a.next" exists until the last
+// page, clicking it advances the page, and extract returns the page label.
+type fakeRunner struct {
+	page     int
+	pages    int
+	failures map[string]int // action selector -> remaining failures
+	actions  []map[string]any
+	navTab   string
+}
+
+func (f *fakeRunner) Action(_ context.Context, tabID string, params map[string]any) (any, error) {
+	f.actions = append(f.actions, params)
+	sel, _ := params["selector"].(string)
+	if f.failures[sel] > 0 {
+		f.failures[sel]--
+		return nil, errors.New("element not found")
+	}
+	if sel == "a.next" {
+		f.page++
+	}
+	return map[string]any{"success": true, "tab": tabID}, nil
+}
+
+func (f *fakeRunner) Navigate(_ context.Context, _ string, params map[string]any) (any, error) {
+	f.page = 1
+	return map[string]any{"tabId": f.navTab, "url": params["url"]}, nil
+}
+
+func (f *fakeRunner) Wait(_ context.Context, _ string, _ map[string]any) (any, error) {
+	return map[string]any{"waited": true}, nil
+}
+
+func (f *fakeRunner) Extract(_ context.Context, _ string, ex Extract) (any, error) {
+	return []any{fmt.Sprintf("%s@p%d", ex.Selector, f.page)}, nil
+}
+
+func (f *fakeRunner) Check(_ context.Context, _ string, c Condition) (bool, error) {
+	switch {
+	case c.Exists == "a.next":
+		return f.page < f.pages, nil
+	case c.NotExists == "a.next":
+		return f.page >= f.pages, nil
+	case c.Exists != "":
+		return false, nil
+	}
+	return false, fmt.Errorf("unsupported condition %+v", c)
+}
+
+func runDoc(t *testing.T, src string, r Runner, vars map[string]any) *Run {
+	t.Helper()
+	doc, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := doc.Validate(nil); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	run := NewRun(doc, "tab-1", "agent", vars)
+	Execute(context.Background(), run, doc, r)
+	return run.Snapshot()
+}
+
+func TestExecutePaginationLoop(t *testing.T) {
+	r := &fakeRunner{pages: 3, navTab: "tab-2"}
+	run := runDoc(t, `
+vars: {site: "https://example.com"}
+steps:
+  - id: open
+    navigate: {url: "${vars.site}/list"}
+  - if: {exists: ".cookie-banner"}
+    then:
+      - action: {kind: click, selector: ".cookie-banner button"}
+    else:
+      - set: {banner: false}
+  - id: pages
+    loop:
+      until: {notExists: "a.next"}
+      steps:
+        - if: {value: "${loop.index}"}
+          then:
+            - action: {kind: click, selector: "a.next"}
+        - id: rows
+          extract: {selector: ".row", all: true}
+  - set: {first: "${steps.pages.output.results.0.rows.0}", count: "${steps.pages.output.iterations}"}
+`, r, nil)
+
+	if run.Status != StatusDone {
+		t.Fatalf("status = %s (%s), want done", run.Status, run.Error)
+	}
+	if run.TabID != "tab-2" {
+		t.Errorf("run should follow navigate tabId, got %q", run.TabID)
+	}
+	if run.Vars["count"] != 3 || run.Vars["first"] != ".row@p1" || run.Vars["banner"] != false {
+		t.Errorf("vars = %+v", run.Vars)
+	}
+	if got := run.Outputs["open"].(map[string]any)["url"]; got != "https://example.com/list" {
+		t.Errorf("navigate url = %v", got)
+	}
+	results := run.Outputs["pages"].(map[string]any)["results"].([]any)
+	if len(results) != 3 {
+		t.Fatalf("loop results = %d, want 3", len(results))
+	}
+	if rows := results[2].(map[string]any)["rows"].([]any); rows[0] != ".row@p3" {
+		t.Errorf("last page rows = %v", rows)
+	}
+	var loopPaths int
+	for _, tr := range run.Trace {
+		if tr.Status != TraceOK {
+			t.Errorf("trace %s status = %s", tr.Path, tr.Status)
+		}
+		if strings.HasPrefix(tr.Path, "steps[2].loop[2].steps[") {
+			loopPaths++
+		}
+	}
+	if loopPaths == 0 {
+		t.Errorf("trace lacks third-iteration entries: %+v", run.Trace)
+	}
+}
+
+func TestExecuteRetryAndContinueOnError(t *testing.T) {
+	r := &fakeRunner{pages: 1, failures: map[string]int{"#flaky": 2, "#broken": 99}}
+	run := runDoc(t, `
+steps:
+  - id: flaky
+    action: {kind: click, selector: "#flaky"}
+    retry: {attempts: 3}
+  - id: broken
+    action: {kind: click, selector: "#broken"}
+    continueOnError: true
+  - if: {value: "${steps.broken.error}"}
+    then:
+      - set: {recovered: true}
+`, r, nil)
+
+	if run.Status != StatusDone {
+		t.Fatalf("status = %s (%s), want done", run.Status, run.Error)
+	}
+	if run.Trace[0].Attempts != 3 || run.Trace[0].Status != TraceOK {
+		t.Errorf("flaky trace = %+v, want ok after 3 attempts", run.Trace[0])
+	}
+	if run.Trace[1].Status != TraceFailed || run.Trace[1].Error == "" {
+		t.Errorf("broken trace = %+v, want failed with error", run.Trace[1])
+	}
+	if run.Vars["recovered"] != true {
+		t.Errorf("branch on step error did not run: %+v", run.Vars)
+	}
+}
+
+func TestExecuteFailureStopsRun(t *testing.T) {
+	r := &fakeRunner{pages: 1, failures: map[string]int{"#missing": 99}}
+	run := runDoc(t, `
+steps:
+  - if: {value: "yes"}
+    then:
+      - id: click
+        action: {kind: click, selector: "#missing"}
+  - set: {unreachable: true}
+`, r, nil)
+
+	if run.Status != StatusFailed {
+		t.Fatalf("status = %s, want failed", run.Status)
+	}
+	if !strings.Contains(run.Error, "steps[0].then[0] (click)") || strings.Count(run.Error, "steps[") != 1 {
+		t.Errorf("error should name the failing step once, got %q", run.Error)
+	}
+	if _, ok := run.Vars["unreachable"]; ok {
+		t.Error("steps after a failure must not run")
+	}
+}
+
+func TestExecuteItemsLoopAndVars(t *testing.T) {
+	r := &fakeRunner{pages: 1}
+	run := runDoc(t, `
+vars: {terms: [a, b]}
+steps:
+  - loop:
+      items: "${vars.terms}"
+      as: term
+      steps:
+        - action: {kind: type, selector: "#q", text: "${term}-${loop.index}"}
+`, r, map[string]any{"terms": []any{"x", "y", "z"}})
+
+	if run.Status != StatusDone {
+		t.Fatalf("status = %s (%s)", run.Status, run.Error)
+	}
+	if len(r.actions) != 3 || r.actions[2]["text"] != "z-2" {
+		t.Errorf("actions = %+v, want caller vars to override document vars", r.actions)
+	}
+}
+
+func TestExecuteUnresolvedReferenceFails(t *testing.T) {
+	run := runDoc(t, `{"steps":[{"action":{"kind":"click","selector":"${steps.nope.output}"}}]}`, &fakeRunner{}, nil)
+	if run.Status != StatusFailed || !strings.Contains(run.Error, "unresolved reference") {
+		t.Fatalf("run = %s %q, want unresolved reference failure", run.Status, run.Error)
+	}
+}
+
+func TestExecuteCancelled(t *testing.T) {
+	doc, err := Parse([]byte(`{"steps":[{"wait":{"ms":1}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	run := NewRun(doc, "", "", nil)
+	Execute(ctx, run, doc, &fakeRunner{})
+	if got := run.Snapshot().Status; got != StatusCancelled {
+		t.Fatalf("status = %s, want cancelled", got)
+	}
+}
+
+func TestStoreEvictsFinishedRuns(t *testing.T) {
+	s := NewStore(time.Minute)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	old := &Run{ID: "wfr_old", Status: StatusDone, CompletedAt: now.Add(-2 * time.Minute)}
+	live := &Run{ID: "wfr_live", Status: StatusRunning}
+	s.Put(old)
+	s.Put(live)
+	s.Put(&Run{ID: "wfr_new", Status: StatusRunning})
+
+	if s.Get("wfr_old") != nil {
+		t.Error("expired run should be evicted")
+	}
+	if s.Get("wfr_live") == nil || s.Len() != 2 {
+		t.Errorf("running runs must be kept, len=%d", s.Len())
+	}
+}
+
+func TestRunCancelBeforeExecute(t *testing.T) {
+	doc, err := Parse([]byte(`{"steps":[{"wait":{"ms":1}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := NewRun(doc, "", "", nil)
+	if !run.Cancel() {
+		t.Fatal("Cancel on a running run should succeed")
+	}
+	Execute(context.Background(), run, doc, &fakeRunner{})
+	if got := run.Snapshot().Status; got != StatusCancelled {
+		t.Fatalf("status = %s, want cancelled", got)
+	}
+	if run.Cancel() {
+		t.Fatal("Cancel on a finished run should report false")
+	}
+}
+
+func TestStoreTryPutCapsRunningRuns(t *testing.T) {
+	s := NewStore(time.Minute)
+	if !s.TryPut(&Run{ID: "wfr_1", Status: StatusRunning}, 2) || !s.TryPut(&Run{ID: "wfr_2", Status: StatusRunning}, 2) {
+		t.Fatal("runs under the limit should be accepted")
+	}
+	if s.TryPut(&Run{ID: "wfr_3", Status: StatusRunning}, 2) {
+		t.Fatal("a third running run should be refused")
+	}
+	if !s.Cancel("wfr_1") {
+		t.Fatal("Cancel should find wfr_1")
+	}
+	s.runs["wfr_1"].Status = StatusCancelled
+	if !s.TryPut(&Run{ID: "wfr_3", Status: StatusRunning}, 2) {
+		t.Fatal("a finished run should free its slot")
+	}
+}
@@ -0,0 +1,168 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// refPattern matches ${path} references, e.g. ${vars.query} or
+// ${steps.rows.output.0.title}.
+var refPattern = regexp.MustCompile(`\$\{\s*([^{}]+?)\s*\}`)
+
+func hasReference(s string) bool {
+	return refPattern.MatchString(s)
+}
+
+// Interpolate resolves ${...} references in v against scope. Maps and slices
+// are copied, never mutated. A string that is exactly one reference takes the
+// referenced value with its type intact (a list stays a list); references
+// embedded in longer strings are formatted as text.
+func Interpolate(v any, scope map[string]any) (any, error) {
+	switch val := v.(type) {
+	case string:
+		return interpolateString(val, scope)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			resolved, err := Interpolate(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			resolved, err := Interpolate(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// InterpolateString is Interpolate for string fields; non-string results are
+// formatted as text.
+func InterpolateString(s string, scope map[string]any) (string, error) {
+	v, err := interpolateString(s, scope)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(v), nil
+}
+
+func interpolateString(s string, scope map[string]any) (any, error) {
+	matches := refPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return Lookup(scope, s[matches[0][2]:matches[0][3]])
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		v, err := Lookup(scope, s[m[2]:m[3]])
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(formatValue(v))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// Lookup resolves a dotted path (vars.query, steps.rows.output.0) against
+// scope. Numeric segments index lists; "length" on a list, map or string
+// yields its size when no such key exists.
+func Lookup(scope map[string]any, path string) (any, error) {
+	parts := strings.Split(strings.TrimSpace(path), ".")
+	var cur any = scope
+	for _, part := range parts {
+		next, ok := lookupSegment(cur, part)
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference ${%s}", path)
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+func lookupSegment(cur any, part string) (any, bool) {
+	switch val := cur.(type) {
+	case map[string]any:
+		if v, ok := val[part]; ok {
+			return v, true
+		}
+		if part == "length" {
+			return len(val), true
+		}
+	case []any:
+		if part == "length" {
+			return len(val), true
+		}
+		idx, err := strconv.Atoi(part)
+		if err != nil || idx < 0 || idx >= len(val) {
+			return nil, false
+		}
+		return val[idx], true
+	case string:
+		if part == "length" {
+			return len(val), true
+		}
+	}
+	return nil, false
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool, int, int64, float64:
+		return fmt.Sprint(val)
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	}
+}
+
+// Truthy reports whether v counts as true in a condition: nil, false, zero,
+// "", "false", "0" and empty lists or maps are false.
+func Truthy(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case int:
+		return val != 0
+	case int64:
+		return val != 0
+	case float64:
+		return val != 0
+	case string:
+		s := strings.TrimSpace(val)
+		return s != "" && s != "false" && s != "0"
+	case []any:
+		return len(val) > 0
+	case map[string]any:
+		return len(val) > 0
+	default:
+		return true
+	}
+}
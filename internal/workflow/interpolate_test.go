@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	scope := map[string]any{
+		"vars": map[string]any{"query": "go", "page": 2},
+		"steps": map[string]any{
+			"rows": map[string]any{"output": []any{"a", "b", "c"}},
+		},
+	}
+
+	tests := []struct {
+		in   any
+		want any
+	}{
+		{"plain", "plain"},
+		{"${vars.query}", "go"},
+		{"${ vars.page }", 2},
+		{"q=${vars.query}&p=${vars.page}", "q=go&p=2"},
+		{"${steps.rows.output}", []any{"a", "b", "c"}},
+		{"${steps.rows.output.1}", "b"},
+		{"${steps.rows.output.length}", 3},
+		{"rows: ${steps.rows.output}", `rows: ["a","b","c"]`},
+		{map[string]any{"text": "${vars.query}", "n": 1}, map[string]any{"text": "go", "n": 1}},
+		{[]any{"${vars.page}", true}, []any{2, true}},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.in, scope)
+		if err != nil {
+			t.Fatalf("Interpolate(%v): %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Interpolate(%v) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"${vars.missing}", "${steps.rows.output.9}", "${nope}"} {
+		if _, err := Interpolate(bad, scope); err == nil {
+			t.Errorf("Interpolate(%q) should fail", bad)
+		}
+	}
+}
+
+func TestTruthy(t *testing.T) {
+	for _, v := range []any{true, 1, 2.5, "yes", []any{1}, map[string]any{"a": 1}} {
+		if !Truthy(v) {
+			t.Errorf("Truthy(%#v) = false", v)
+		}
+	}
+	for _, v := range []any{nil, false, 0, 0.0, "", "false", "0", []any{}, map[string]any{}} {
+		if Truthy(v) {
+			t.Errorf("Truthy(%#v) = true", v)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRunTTL is how long finished runs stay queryable.
+const DefaultRunTTL = 30 * time.Minute
+
+// Store holds runs in memory so GET /workflows/runs/{id} can report progress
+// and results. Finished runs are evicted ttl after completion; eviction is
+// lazy, on Put.
+type Store struct {
+	mu   sync.Mutex
+	runs map[string]*Run
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewStore creates a store that evicts finished runs after ttl.
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultRunTTL
+	}
+	return &Store{runs: make(map[string]*Run), ttl: ttl, now: time.Now}
+}
+
+// Put registers a run (typically still in flight).
+func (s *Store) Put(run *Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictLocked()
+	s.runs[run.ID] = run
+}
+
+// TryPut registers run unless limit runs are already in flight. A limit
+// of zero or less means no limit.
+func (s *Store) TryPut(run *Run, limit int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictLocked()
+	if limit > 0 {
+		running := 0
+		for _, r := range s.runs {
+			r.mu.Lock()
+			if !r.Status.IsTerminal() {
+				running++
+			}
+			r.mu.Unlock()
+		}
+		if running >= limit {
+			return false
+		}
+	}
+	s.runs[run.ID] = run
+	return true
+}
+
+// Cancel cancels the run with id. It reports false when the run is
+// unknown or already finished.
+func (s *Store) Cancel(id string) bool {
+	s.mu.Lock()
+	run := s.runs[id]
+	s.mu.Unlock()
+	return run != nil && run.Cancel()
+}
+
+// Get returns a snapshot of the run, or nil if unknown or evicted.
+func (s *Store) Get(id string) *Run {
+	s.mu.Lock()
+	run := s.runs[id]
+	s.mu.Unlock()
+	if run == nil {
+		return nil
+	}
+	return run.Snapshot()
+}
+
+// Len returns the number of retained runs.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.runs)
+}
+
+func (s *Store) evictLocked() {
+	cutoff := s.now().Add(-s.ttl)
+	for id, run := range s.runs {
+		run.mu.Lock()
+		expired := run.Status.IsTerminal() && run.CompletedAt.Before(cutoff)
+		run.mu.Unlock()
+		if expired {
+			delete(s.runs, id)
+		}
+	}
+}
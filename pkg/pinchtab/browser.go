@@ -0,0 +1,316 @@
+package pinchtab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Navigate loads a URL in the current tab, the tab named by req.TabID, or
+// a new tab when req.NewTab is set.
+func (c *Client) Navigate(ctx context.Context, req NavigateRequest) (*NavigateResult, error) {
+	var out NavigateResult
+	if err := c.Do(ctx, http.MethodPost, "/navigate", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Back goes back in the tab's history. An empty tabID uses the current tab.
+func (c *Client) Back(ctx context.Context, tabID string) (*HistoryResult, error) {
+	return c.history(ctx, "/back", tabID)
+}
+
+// Forward goes forward in the tab's history.
+func (c *Client) Forward(ctx context.Context, tabID string) (*HistoryResult, error) {
+	return c.history(ctx, "/forward", tabID)
+}
+
+// Reload reloads the tab.
+func (c *Client) Reload(ctx context.Context, tabID string) (*HistoryResult, error) {
+	return c.history(ctx, "/reload", tabID)
+}
+
+func (c *Client) history(ctx context.Context, path, tabID string) (*HistoryResult, error) {
+	var out HistoryResult
+	if err := c.Do(ctx, http.MethodPost, path, tabQuery(tabID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Snapshot returns the accessibility tree of a tab as JSON.
+func (c *Client) Snapshot(ctx context.Context, opts *SnapshotOptions) (*Snapshot, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "tabId", opts.TabID)
+		setIf(q, "filter", opts.Filter)
+		setIf(q, "selector", opts.Selector)
+		if opts.Depth > 0 {
+			q.Set("depth", strconv.Itoa(opts.Depth))
+		}
+		if opts.MaxTokens > 0 {
+			q.Set("maxTokens", strconv.Itoa(opts.MaxTokens))
+		}
+		if opts.Diff {
+			q.Set("diff", "true")
+		}
+	}
+	var out Snapshot
+	if err := c.Do(ctx, http.MethodGet, "/snapshot", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Text extracts the readable text of a tab.
+func (c *Client) Text(ctx context.Context, opts *TextOptions) (*TextResult, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "tabId", opts.TabID)
+		setIf(q, "mode", opts.Mode)
+		setIf(q, "selector", opts.Selector)
+		setIf(q, "frameId", opts.FrameID)
+		if opts.MaxChars > 0 {
+			q.Set("maxChars", strconv.Itoa(opts.MaxChars))
+		}
+	}
+	var out TextResult
+	if err := c.Do(ctx, http.MethodGet, "/text", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Screenshot captures a tab and returns the encoded image bytes.
+func (c *Client) Screenshot(ctx context.Context, opts *ScreenshotOptions) ([]byte, error) {
+	q := url.Values{"raw": {"true"}}
+	if opts != nil {
+		setIf(q, "tabId", opts.TabID)
+		setIf(q, "format", opts.Format)
+		setIf(q, "selector", opts.Selector)
+		if opts.Quality > 0 {
+			q.Set("quality", strconv.Itoa(opts.Quality))
+		}
+		if opts.BeyondViewport {
+			q.Set("beyondViewport", "true")
+		}
+		if opts.Scale > 0 {
+			q.Set("scale", strconv.FormatFloat(opts.Scale, 'f', -1, 64))
+		}
+	}
+	var img []byte
+	if err := c.Do(ctx, http.MethodGet, "/screenshot", q, nil, &img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// Action performs one interaction.
+func (c *Client) Action(ctx context.Context, req ActionRequest) (*ActionResult, error) {
+	var out ActionResult
+	if err := c.Do(ctx, http.MethodPost, "/action", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Actions performs a batch of interactions in order.
+func (c *Client) Actions(ctx context.Context, req ActionsRequest) (*ActionsResult, error) {
+	var out ActionsResult
+	if err := c.Do(ctx, http.MethodPost, "/actions", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Wait blocks until a condition holds or its timeout passes.
+func (c *Client) Wait(ctx context.Context, req WaitRequest) (*WaitResult, error) {
+	var out WaitResult
+	if err := c.Do(ctx, http.MethodPost, "/wait", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Find matches elements against a natural-language query.
+func (c *Client) Find(ctx context.Context, req FindRequest) (*FindResult, error) {
+	var out FindResult
+	if err := c.Do(ctx, http.MethodPost, "/find", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Evaluate runs a JavaScript expression in a tab and decodes its result
+// into out. It requires security.allowEvaluate on the server.
+func (c *Client) Evaluate(ctx context.Context, tabID, expression string, out any) error {
+	body := map[string]any{"expression": expression, "awaitPromise": true}
+	if tabID != "" {
+		body["tabId"] = tabID
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := c.Do(ctx, http.MethodPost, "/evaluate", nil, body, &resp); err != nil {
+		return err
+	}
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("pinchtab: decode evaluate result: %w", err)
+	}
+	return nil
+}
+
+// Tabs lists open tabs, current tab first.
+func (c *Client) Tabs(ctx context.Context) ([]Tab, error) {
+	var out struct {
+		Tabs []Tab `json:"tabs"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/tabs", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tabs, nil
+}
+
+// NewTab opens a tab, navigated to rawURL when it is not empty.
+func (c *Client) NewTab(ctx context.Context, rawURL string) (*NavigateResult, error) {
+	var out NavigateResult
+	body := map[string]string{"action": "new", "url": rawURL}
+	if err := c.Do(ctx, http.MethodPost, "/tab", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FocusTab makes tabID the current tab.
+func (c *Client) FocusTab(ctx context.Context, tabID string) error {
+	body := map[string]string{"action": "focus", "tabId": tabID}
+	return c.Do(ctx, http.MethodPost, "/tab", nil, body, nil)
+}
+
+// CloseTab closes a tab. An empty tabID closes the current tab.
+func (c *Client) CloseTab(ctx context.Context, tabID string) error {
+	return c.Do(ctx, http.MethodPost, "/close", nil, map[string]string{"tabId": tabID}, nil)
+}
+
+// Network lists captured requests of a tab.
+func (c *Client) Network(ctx context.Context, opts *NetworkOptions) (*NetworkList, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "tabId", opts.TabID)
+		setIf(q, "filter", opts.Filter)
+		setIf(q, "method", opts.Method)
+		setIf(q, "status", opts.Status)
+		setIf(q, "type", opts.Type)
+		if opts.Limit > 0 {
+			q.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+	var out NetworkList
+	if err := c.Do(ctx, http.MethodGet, "/network", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// NetworkRequest returns one captured request, with its response body when
+// withBody is set.
+func (c *Client) NetworkRequest(ctx context.Context, tabID, requestID string, withBody bool) (*NetworkDetail, error) {
+	q := tabQuery(tabID)
+	if withBody {
+		q.Set("body", "true")
+	}
+	var out NetworkDetail
+	if err := c.Do(ctx, http.MethodGet, "/network/"+url.PathEscape(requestID), q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClearNetwork drops captured requests of a tab, or of every tab when tabID
+// is empty.
+func (c *Client) ClearNetwork(ctx context.Context, tabID string) error {
+	return c.Do(ctx, http.MethodPost, "/network/clear", tabQuery(tabID), nil, nil)
+}
+
+// ExportHAR returns the tab's captured requests as a HAR 1.2 document.
+func (c *Client) ExportHAR(ctx context.Context, tabID string) ([]byte, error) {
+	var har []byte
+	if err := c.Do(ctx, http.MethodGet, "/network/export", tabQuery(tabID), nil, &har); err != nil {
+		return nil, err
+	}
+	return har, nil
+}
+
+// Console returns the tab's console messages, newest last.
+func (c *Client) Console(ctx context.Context, tabID string, limit int) ([]LogEntry, error) {
+	q := tabQuery(tabID)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out struct {
+		Console []LogEntry `json:"console"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/console", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Console, nil
+}
+
+// Cookies returns the cookies visible to the tab's page. It requires
+// security.allowCookies on the server.
+func (c *Client) Cookies(ctx context.Context, tabID string) ([]Cookie, error) {
+	var out struct {
+		Cookies []Cookie `json:"cookies"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/cookies", tabQuery(tabID), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Cookies, nil
+}
+
+// SetCookies sets cookies for pageURL in the tab.
+func (c *Client) SetCookies(ctx context.Context, tabID, pageURL string, cookies []Cookie) error {
+	body := map[string]any{"tabId": tabID, "url": pageURL, "cookies": cookies}
+	return c.Do(ctx, http.MethodPost, "/cookies", nil, body, nil)
+}
+
+// Health reports whether the browser is up.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var out Health
+	if err := c.Do(ctx, http.MethodGet, "/health", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunWorkflow runs a declarative workflow. With req.Async it returns once
+// the run has started, with only RunID and Status set.
+func (c *Client) RunWorkflow(ctx context.Context, req WorkflowRequest) (*WorkflowRun, error) {
+	var out WorkflowRun
+	if err := c.Do(ctx, http.MethodPost, "/workflows/run", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// WorkflowRun returns the status and trace of a workflow run.
+func (c *Client) WorkflowRun(ctx context.Context, runID string) (*WorkflowRun, error) {
+	var out WorkflowRun
+	if err := c.Do(ctx, http.MethodGet, "/workflows/runs/"+url.PathEscape(runID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelWorkflowRun stops a running workflow at its next step. Cancelling
+// a finished run fails with code "workflow_run_finished".
+func (c *Client) CancelWorkflowRun(ctx context.Context, runID string) error {
+	return c.Do(ctx, http.MethodPost, "/workflows/runs/"+url.PathEscape(runID)+"/cancel", nil, nil, nil)
+}
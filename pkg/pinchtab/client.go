@@ -0,0 +1,169 @@
+// Package pinchtab is the public Go client for the pinchtab HTTP API. It
+// covers the browser surface a bridge serves (navigation, snapshots,
+// actions, tabs, network capture, ...) and the server-only surface the
+// dashboard adds on top (tasks, instances, sessions), with typed request and
+// response structs, session and agent-id authentication, Server-Sent Event
+// helpers for /network/stream and /api/events, and typed errors that decode
+// both the legacy {"error","code"} body and RFC 9457 Problem Details.
+//
+// The request paths and methods are checked against the server's
+// /openapi.json in this package's tests. The exported types mirror the
+// server's JSON contract and depend on no pinchtab internal packages; Do is
+// the escape hatch for endpoints without a typed wrapper.
+package pinchtab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a call when the caller's context carries no
+// deadline. Streams are exempt: they last until their context is cancelled
+// or the stream is closed.
+const DefaultTimeout = 2 * time.Minute
+
+// Client is a typed client for a running pinchtab server or bridge. A Client
+// is safe for concurrent use; WithSession and WithAgentID return copies.
+type Client struct {
+	baseURL      string
+	token        string
+	sessionToken string
+	agentID      string
+	// HTTPClient may be replaced before first use. It must not set a
+	// Timeout if the streaming helpers are used; per-call deadlines come
+	// from the context or DefaultTimeout instead.
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the pinchtab server at baseURL. token is the
+// server's bearer token and may be empty when authentication is off.
+func New(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// WithSession returns a copy of c that authenticates with an agent session
+// token (Authorization: Session ...) instead of the bearer token. Session
+// tokens come from CreateSession.
+func (c *Client) WithSession(sessionToken string) *Client {
+	cp := *c
+	cp.sessionToken = sessionToken
+	return &cp
+}
+
+// WithAgentID returns a copy of c that tags every request with X-Agent-Id,
+// attributing activity, tab locks and scheduler quotas to that agent.
+func (c *Client) WithAgentID(agentID string) *Client {
+	cp := *c
+	cp.agentID = agentID
+	return &cp
+}
+
+// Do sends a request to path and decodes a JSON response into out, which
+// may be nil. query and body may be nil; a non-nil body is sent as JSON. A
+// non-2xx response is returned as *APIError.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	resp, err := c.send(ctx, method, path, query, body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("pinchtab: read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return newAPIError(resp, data)
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw = data
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("pinchtab: decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send builds and issues a request. The caller owns the response body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body any, accept string) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("pinchtab: encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, fmt.Errorf("pinchtab: build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", accept)
+	switch {
+	case c.sessionToken != "":
+		req.Header.Set("Authorization", "Session "+c.sessionToken)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.agentID != "" {
+		req.Header.Set("X-Agent-Id", c.agentID)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("pinchtab: %s %s: %w", method, path, err)
+	}
+	return resp, nil
+}
+
+// Bool returns a pointer to v, for optional boolean request fields.
+func Bool(v bool) *bool { return &v }
+
+// Int returns a pointer to v, for optional integer request fields.
+func Int(v int) *int { return &v }
+
+// tabQuery returns query values carrying tabId when it is set.
+func tabQuery(tabID string) url.Values {
+	q := url.Values{}
+	if tabID != "" {
+		q.Set("tabId", tabID)
+	}
+	return q
+}
+
+func setIf(q url.Values, key, value string) {
+	if value != "" {
+		q.Set(key, value)
+	}
+}
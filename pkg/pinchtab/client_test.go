@@ -0,0 +1,201 @@
+package pinchtab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNavigateSendsBodyAndAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/navigate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Agent-Id"); got != "agent-1" {
+			t.Errorf("X-Agent-Id = %q", got)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["url"] != "https://example.com" || body["newTab"] != true || body["blockImages"] != false {
+			t.Errorf("body = %v", body)
+		}
+		if _, ok := body["waitFor"]; ok {
+			t.Errorf("empty waitFor should be omitted: %v", body)
+		}
+		_, _ = w.Write([]byte(`{"tabId":"t1","url":"https://example.com/","title":"Example","route":{"requestedProvider":"auto","usedProvider":"chrome","escalated":false}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok").WithAgentID("agent-1")
+	res, err := c.Navigate(context.Background(), NavigateRequest{URL: "https://example.com", NewTab: true, BlockImages: Bool(false)})
+	if err != nil {
+		t.Fatalf("Navigate: %v", err)
+	}
+	if res.TabID != "t1" || res.Title != "Example" || res.Route == nil || res.Route.UsedProvider != "chrome" {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestWithSessionUsesSessionScheme(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Session ses_secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		_, _ = w.Write([]byte(`{"id":"ses_1","agentId":"agent-1","status":"active","createdAt":"2026-01-02T03:04:05Z"}`))
+	}))
+	defer srv.Close()
+
+	base := New(srv.URL, "tok")
+	sess, err := base.WithSession("ses_secret").CurrentSession(context.Background())
+	if err != nil {
+		t.Fatalf("CurrentSession: %v", err)
+	}
+	if sess.ID != "ses_1" || sess.AgentID != "agent-1" {
+		t.Errorf("session = %+v", sess)
+	}
+	if base.sessionToken != "" {
+		t.Error("WithSession mutated the receiver")
+	}
+}
+
+func TestLegacyErrorShape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":"queue is full","code":"queue_full","retryable":true,"details":{"maxQueue":10}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "").SubmitTask(context.Background(), TaskRequest{AgentID: "a", Action: "click"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != 429 || apiErr.Code != "queue_full" || apiErr.Message != "queue is full" || !apiErr.Retryable {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if apiErr.Problem || apiErr.RetryAfter != 3*time.Second || apiErr.Details["maxQueue"] != float64(10) {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if !IsCode(err, "queue_full") || !IsRetryable(err) {
+		t.Errorf("helpers disagree with %v", err)
+	}
+}
+
+func TestProblemDetailsErrorShape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"type":"about:blank","title":"Forbidden","status":403,"detail":"evaluate is disabled","instance":"/evaluate","code":"evaluate_disabled","details":{"setting":"security.allowEvaluate"}}`))
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL, "").Evaluate(context.Background(), "", "1+1", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if !apiErr.Problem || apiErr.Title != "Forbidden" || apiErr.Instance != "/evaluate" || apiErr.Type != "about:blank" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if apiErr.Code != "evaluate_disabled" || apiErr.Message != "evaluate is disabled" || apiErr.Details["setting"] != "security.allowEvaluate" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if IsRetryable(err) {
+		t.Error("403 should not be retryable")
+	}
+}
+
+func TestNonJSONErrorKeepsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "").Tabs(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 502 || apiErr.Message != "upstream unavailable" || apiErr.Code != "" {
+		t.Fatalf("err = %#v", err)
+	}
+}
+
+func TestScreenshotReturnsRawImage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("raw") != "true" || q.Get("format") != "png" || q.Get("tabId") != "t1" {
+			t.Errorf("query = %v", q)
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("\x89PNG"))
+	}))
+	defer srv.Close()
+
+	img, err := New(srv.URL, "").Screenshot(context.Background(), &ScreenshotOptions{TabID: "t1", Format: "png"})
+	if err != nil {
+		t.Fatalf("Screenshot: %v", err)
+	}
+	if string(img) != "\x89PNG" {
+		t.Errorf("img = %q", img)
+	}
+}
+
+func TestEvaluateDecodesResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"title":"Example","links":3}}`))
+	}))
+	defer srv.Close()
+
+	var out struct {
+		Title string `json:"title"`
+		Links int    `json:"links"`
+	}
+	if err := New(srv.URL, "").Evaluate(context.Background(), "t1", "({title: document.title})", &out); err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+	if out.Title != "Example" || out.Links != 3 {
+		t.Errorf("out = %+v", out)
+	}
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /tasks":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"taskId":"tsk_1","state":"queued","position":1,"createdAt":"2026-01-02T03:04:05Z"}`))
+		case "GET /tasks/tsk_1":
+			_, _ = w.Write([]byte(`{"taskId":"tsk_1","agentId":"a","action":"click","state":"done","priority":0,"result":{"ok":true},"createdAt":"2026-01-02T03:04:05Z"}`))
+		case "GET /tasks":
+			if r.URL.Query().Get("state") != "queued,running" {
+				t.Errorf("state filter = %q", r.URL.Query().Get("state"))
+			}
+			_, _ = w.Write([]byte(`{"tasks":[{"taskId":"tsk_1","state":"running"}],"count":1}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := New(srv.URL, "")
+	sub, err := c.SubmitTask(ctx, TaskRequest{AgentID: "a", Action: "click"})
+	if err != nil || sub.TaskID != "tsk_1" || sub.State != TaskQueued {
+		t.Fatalf("SubmitTask = %+v, %v", sub, err)
+	}
+	task, err := c.Task(ctx, sub.TaskID)
+	if err != nil || !task.Terminal() || string(task.Result) != `{"ok":true}` {
+		t.Fatalf("Task = %+v, %v", task, err)
+	}
+	tasks, err := c.Tasks(ctx, &TaskListOptions{States: []string{TaskQueued, TaskRunning}})
+	if err != nil || len(tasks) != 1 || tasks[0].Terminal() {
+		t.Fatalf("Tasks = %+v, %v", tasks, err)
+	}
+}
@@ -0,0 +1,122 @@
+package pinchtab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is a non-2xx response from the server. It is decoded from either
+// of the two error shapes pinchtab emits: the legacy JSON body
+// {"error", "code", "retryable", "details"} or an application/problem+json
+// Problem Details document, which carries the same code, retryable and
+// details members next to type, title, detail and instance.
+type APIError struct {
+	// StatusCode is the HTTP status.
+	StatusCode int
+	// Code is the stable machine-readable error code, e.g. "tab_locked" or
+	// "evaluate_disabled". It is "" when the body was not JSON.
+	Code string
+	// Message is the human-readable error: "error" in the legacy shape,
+	// "detail" (or "title") in Problem Details, or the raw body otherwise.
+	Message string
+	// Retryable reports whether the server flagged the failure as transient.
+	Retryable bool
+	// Details carries code-specific context such as a suggestion or limits.
+	Details map[string]any
+	// RetryAfter is the Retry-After header, when the server sent one.
+	RetryAfter time.Duration
+
+	// Problem is true when the body was application/problem+json; Type,
+	// Title and Instance are only set then.
+	Problem  bool
+	Type     string
+	Title    string
+	Instance string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Code != "" && e.Code != "error" {
+		return fmt.Sprintf("pinchtab: HTTP %d %s: %s", e.StatusCode, e.Code, msg)
+	}
+	return fmt.Sprintf("pinchtab: HTTP %d: %s", e.StatusCode, msg)
+}
+
+// errorBody is the union of the legacy and Problem Details members.
+type errorBody struct {
+	Error     string         `json:"error"`
+	Code      string         `json:"code"`
+	Retryable bool           `json:"retryable"`
+	Details   map[string]any `json:"details"`
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Detail    string         `json:"detail"`
+	Instance  string         `json:"instance"`
+	// Health endpoints answer 503 with {"status": "error", "reason": ...}.
+	Reason string `json:"reason"`
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && secs >= 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	e.Problem = mediaType == "application/problem+json"
+
+	var b errorBody
+	if err := json.Unmarshal(body, &b); err != nil {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+	e.Code = b.Code
+	e.Retryable = b.Retryable
+	e.Details = b.Details
+	if e.Problem {
+		e.Type, e.Title, e.Instance = b.Type, b.Title, b.Instance
+	}
+	switch {
+	case b.Error != "":
+		e.Message = b.Error
+	case b.Detail != "":
+		e.Message = b.Detail
+	case b.Reason != "":
+		e.Message = b.Reason
+	default:
+		e.Message = b.Title
+	}
+	return e
+}
+
+// IsCode reports whether err is an *APIError with the given code.
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsRetryable reports whether err is an *APIError the server marked as
+// retryable, or a 429/503 response.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Retryable || apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable
+}
+
+// IsNotFound reports whether err is a 404 *APIError.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
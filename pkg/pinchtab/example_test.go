@@ -0,0 +1,88 @@
+package pinchtab_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/pinchtab/pinchtab/pkg/pinchtab"
+)
+
+// Open a page, read its interactive elements and click the first link.
+func ExampleClient_Navigate() {
+	ctx := context.Background()
+	client := pinchtab.New("http://localhost:9867", "my-token").WithAgentID("crawler")
+
+	page, err := client.Navigate(ctx, pinchtab.NavigateRequest{URL: "https://example.com", NewTab: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	snap, err := client.Snapshot(ctx, &pinchtab.SnapshotOptions{TabID: page.TabID, Filter: "interactive"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, node := range snap.Nodes {
+		if node.Role == "link" {
+			_, err := client.Action(ctx, pinchtab.ActionRequest{TabID: page.TabID, Kind: "click", Ref: node.Ref, WaitNav: true})
+			if pinchtab.IsCode(err, "tab_locked") {
+				log.Fatal("another agent holds this tab")
+			}
+			break
+		}
+	}
+}
+
+// Watch failed requests of a tab as they happen.
+func ExampleClient_StreamNetwork() {
+	client := pinchtab.New("http://localhost:9867", "my-token")
+
+	stream, err := client.StreamNetwork(context.Background(), &pinchtab.NetworkStreamOptions{Status: "4xx"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = stream.Close() }()
+	for {
+		entry, err := stream.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(entry.Status, entry.URL)
+	}
+}
+
+// Authenticate as an agent session and follow its activity feed.
+func ExampleClient_StreamEvents() {
+	ctx := context.Background()
+	admin := pinchtab.New("http://localhost:9867", "my-token")
+
+	sess, err := admin.CreateSession(ctx, pinchtab.CreateSessionRequest{AgentID: "crawler"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	agent := admin.WithSession(sess.SessionToken)
+
+	stream, err := agent.StreamEvents(ctx, &pinchtab.EventsOptions{Mode: "both", AgentID: "crawler"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() { _ = stream.Close() }()
+	for {
+		ev, err := stream.Next()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if ev.Name != pinchtab.EventAction && ev.Name != pinchtab.EventProgress {
+			continue
+		}
+		var act pinchtab.ActivityEvent
+		if err := ev.Decode(&act); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(act.Method, act.Path, act.Message)
+	}
+}
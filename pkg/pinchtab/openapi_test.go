@@ -0,0 +1,183 @@
+package pinchtab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pinchtab/pinchtab/internal/handlers"
+)
+
+// serverOnlyRoutes are endpoints the pinchtab server registers next to the
+// bridge surface. The bridge's /openapi.json does not document them, so the
+// client's use of them is checked against this list instead.
+var serverOnlyRoutes = []string{
+	"POST /tasks",
+	"GET /tasks",
+	"GET /tasks/{id}",
+	"POST /tasks/{id}/cancel",
+	"POST /tasks/batch",
+	"GET /instances",
+	"GET /instances/{id}",
+	"POST /instances/start",
+	"POST /instances/{id}/stop",
+	"POST /sessions",
+	"GET /sessions",
+	"GET /sessions/me",
+	"POST /sessions/{id}/revoke",
+	"GET /api/events",
+}
+
+// TestClientRoutesMatchOpenAPI calls every typed method against a recording
+// server and checks each request's method and path against the document
+// served by handlers.HandleOpenAPI, so a renamed or removed endpoint breaks
+// this test rather than SDK users.
+func TestClientRoutesMatchOpenAPI(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&handlers.Handlers{}).HandleOpenAPI(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode openapi: %v", err)
+	}
+	var documented []string
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			return
+		}
+		_, _ = w.Write([]byte("null"))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := New(srv.URL, "")
+	calls := []func() error{
+		func() error { _, err := c.Navigate(ctx, NavigateRequest{URL: "https://example.com"}); return err },
+		func() error { _, err := c.Back(ctx, "t1"); return err },
+		func() error { _, err := c.Forward(ctx, "t1"); return err },
+		func() error { _, err := c.Reload(ctx, "t1"); return err },
+		func() error { _, err := c.Snapshot(ctx, &SnapshotOptions{Filter: "interactive"}); return err },
+		func() error { _, err := c.Text(ctx, nil); return err },
+		func() error { _, err := c.Screenshot(ctx, nil); return err },
+		func() error { _, err := c.Action(ctx, ActionRequest{Kind: "click", Ref: "e1"}); return err },
+		func() error { _, err := c.Actions(ctx, ActionsRequest{}); return err },
+		func() error { _, err := c.Wait(ctx, WaitRequest{Ms: Int(1)}); return err },
+		func() error { _, err := c.Find(ctx, FindRequest{Query: "login"}); return err },
+		func() error { return c.Evaluate(ctx, "", "1", nil) },
+		func() error { _, err := c.Tabs(ctx); return err },
+		func() error { _, err := c.NewTab(ctx, ""); return err },
+		func() error { return c.FocusTab(ctx, "t1") },
+		func() error { return c.CloseTab(ctx, "t1") },
+		func() error { _, err := c.Network(ctx, nil); return err },
+		func() error { _, err := c.NetworkRequest(ctx, "", "r1", true); return err },
+		func() error { return c.ClearNetwork(ctx, "") },
+		func() error { _, err := c.ExportHAR(ctx, ""); return err },
+		func() error { _, err := c.Console(ctx, "", 10); return err },
+		func() error { _, err := c.Cookies(ctx, ""); return err },
+		func() error { return c.SetCookies(ctx, "", "https://example.com", nil) },
+		func() error { _, err := c.Health(ctx); return err },
+		func() error { _, err := c.RunWorkflow(ctx, WorkflowRequest{Workflow: "steps: []"}); return err },
+		func() error { _, err := c.WorkflowRun(ctx, "run1"); return err },
+		func() error { return c.CancelWorkflowRun(ctx, "run1") },
+		func() error {
+			s, err := c.StreamNetwork(ctx, nil)
+			if err == nil {
+				_ = s.Close()
+			}
+			return err
+		},
+		func() error {
+			s, err := c.StreamFrames(ctx, "", "ws1", true)
+			if err == nil {
+				_ = s.Close()
+			}
+			return err
+		},
+		func() error {
+			s, err := c.StreamEvents(ctx, nil)
+			if err == nil {
+				_ = s.Close()
+			}
+			return err
+		},
+		func() error { _, err := c.SubmitTask(ctx, TaskRequest{}); return err },
+		func() error { _, err := c.SubmitBatch(ctx, BatchRequest{}); return err },
+		func() error { _, err := c.Task(ctx, "tsk1"); return err },
+		func() error { _, err := c.Tasks(ctx, nil); return err },
+		func() error { return c.CancelTask(ctx, "tsk1") },
+		func() error { _, err := c.Instances(ctx); return err },
+		func() error { _, err := c.Instance(ctx, "inst1"); return err },
+		func() error { _, err := c.StartInstance(ctx, StartInstanceRequest{}); return err },
+		func() error { return c.StopInstance(ctx, "inst1") },
+		func() error { _, err := c.CreateSession(ctx, CreateSessionRequest{}); return err },
+		func() error { _, err := c.Sessions(ctx); return err },
+		func() error { _, err := c.CurrentSession(ctx); return err },
+		func() error { return c.RevokeSession(ctx, "ses1") },
+	}
+	for i, call := range calls {
+		if err := call(); err != nil {
+			t.Errorf("call %d: %v", i, err)
+		}
+	}
+	if len(seen) != len(calls) {
+		t.Fatalf("recorded %d requests for %d calls", len(seen), len(calls))
+	}
+
+	sort.Strings(seen)
+	for _, got := range seen {
+		if !matchesAny(got, documented) && !matchesAny(got, serverOnlyRoutes) {
+			t.Errorf("%s is neither in /openapi.json nor a known server route", got)
+		}
+	}
+}
+
+// matchesAny reports whether "METHOD /concrete/path" fits one of the route
+// templates, where a {param} segment matches any single segment.
+func matchesAny(got string, templates []string) bool {
+	method, path, _ := strings.Cut(got, " ")
+	for _, tmpl := range templates {
+		tm, tp, _ := strings.Cut(tmpl, " ")
+		if tm != method {
+			continue
+		}
+		if matchPath(tp, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(tmpl, path string) bool {
+	ts := strings.Split(strings.Trim(tmpl, "/"), "/")
+	ps := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ts) != len(ps) {
+		return false
+	}
+	for i := range ts {
+		if strings.HasPrefix(ts[i], "{") && strings.HasSuffix(ts[i], "}") {
+			continue
+		}
+		if ts[i] != ps[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package pinchtab
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The methods in this file use endpoints the pinchtab server adds on top of
+// a bridge; they return a 404 *APIError when called against a bare bridge.
+
+// SubmitTask queues a task with the scheduler.
+func (c *Client) SubmitTask(ctx context.Context, req TaskRequest) (*TaskSubmitted, error) {
+	var out TaskSubmitted
+	if err := c.Do(ctx, http.MethodPost, "/tasks", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SubmitBatch queues a batch of tasks that may depend on each other.
+func (c *Client) SubmitBatch(ctx context.Context, req BatchRequest) (*BatchResult, error) {
+	var out BatchResult
+	if err := c.Do(ctx, http.MethodPost, "/tasks/batch", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Task returns a task by ID.
+func (c *Client) Task(ctx context.Context, taskID string) (*Task, error) {
+	var out Task
+	if err := c.Do(ctx, http.MethodGet, "/tasks/"+url.PathEscape(taskID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Tasks lists tasks, optionally filtered by agent and state.
+func (c *Client) Tasks(ctx context.Context, opts *TaskListOptions) ([]Task, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "agentId", opts.AgentID)
+		setIf(q, "state", strings.Join(opts.States, ","))
+	}
+	var out struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/tasks", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tasks, nil
+}
+
+// CancelTask cancels a task that has not finished. Cancelling a finished
+// task fails with code "conflict".
+func (c *Client) CancelTask(ctx context.Context, taskID string) error {
+	return c.Do(ctx, http.MethodPost, "/tasks/"+url.PathEscape(taskID)+"/cancel", nil, nil, nil)
+}
+
+// Instances lists managed browser instances.
+func (c *Client) Instances(ctx context.Context) ([]Instance, error) {
+	var out []Instance
+	if err := c.Do(ctx, http.MethodGet, "/instances", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Instance returns one instance.
+func (c *Client) Instance(ctx context.Context, id string) (*Instance, error) {
+	var out Instance
+	if err := c.Do(ctx, http.MethodGet, "/instances/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StartInstance launches a browser instance.
+func (c *Client) StartInstance(ctx context.Context, req StartInstanceRequest) (*Instance, error) {
+	var out Instance
+	if err := c.Do(ctx, http.MethodPost, "/instances/start", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StopInstance stops a browser instance.
+func (c *Client) StopInstance(ctx context.Context, id string) error {
+	return c.Do(ctx, http.MethodPost, "/instances/"+url.PathEscape(id)+"/stop", nil, nil, nil)
+}
+
+// CreateSession creates an agent session. Use the returned SessionToken
+// with WithSession.
+func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (*Session, error) {
+	var out Session
+	if err := c.Do(ctx, http.MethodPost, "/sessions", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Sessions lists agent sessions.
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var out []Session
+	if err := c.Do(ctx, http.MethodGet, "/sessions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CurrentSession returns the session the client authenticates with. It
+// fails with code "session_auth_required" unless WithSession was used.
+func (c *Client) CurrentSession(ctx context.Context) (*Session, error) {
+	var out Session
+	if err := c.Do(ctx, http.MethodGet, "/sessions/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeSession ends a session.
+func (c *Client) RevokeSession(ctx context.Context, id string) error {
+	return c.Do(ctx, http.MethodPost, "/sessions/"+url.PathEscape(id)+"/revoke", nil, nil, nil)
+}
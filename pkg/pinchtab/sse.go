@@ -0,0 +1,234 @@
+package pinchtab
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Event is one Server-Sent Event.
+type Event struct {
+	// Name is the event field; "message" when the server sent none.
+	Name string
+	ID   string
+	Data []byte
+}
+
+// Decode unmarshals the event data into v.
+func (e Event) Decode(v any) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("pinchtab: decode %s event: %w", e.Name, err)
+	}
+	return nil
+}
+
+// Stream reads Server-Sent Events from a long-lived response. Keepalive
+// comments are skipped. Close it, or cancel its context, when done.
+type Stream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// Next blocks for the next event. It returns io.EOF when the server ends the
+// stream, or the context error once the stream's context is cancelled.
+func (s *Stream) Next() (Event, error) {
+	var ev Event
+	var data bytes.Buffer
+	hasData := false
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && hasData {
+				return finishEvent(ev, data.Bytes()), nil
+			}
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if hasData {
+				return finishEvent(ev, data.Bytes()), nil
+			}
+			ev = Event{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Name = value
+		case "id":
+			ev.ID = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		}
+	}
+}
+
+func finishEvent(ev Event, data []byte) Event {
+	if ev.Name == "" {
+		ev.Name = "message"
+	}
+	ev.Data = data
+	return ev
+}
+
+// Close ends the stream.
+func (s *Stream) Close() error { return s.body.Close() }
+
+// Stream opens an SSE endpoint. Use it for streams without a typed helper,
+// such as /network/export/stream or /instances/{id}/logs/stream.
+func (c *Client) Stream(ctx context.Context, path string, query url.Values) (*Stream, error) {
+	resp, err := c.send(ctx, http.MethodGet, path, query, nil, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return nil, newAPIError(resp, data)
+	}
+	return &Stream{body: resp.Body, reader: bufio.NewReader(resp.Body)}, nil
+}
+
+// NetworkStreamOptions filters GET /network/stream.
+type NetworkStreamOptions struct {
+	TabID  string
+	Filter string // URL substring
+	Method string
+	Status string // e.g. "4xx" or "200-299"
+	Type   string // CDP resource type, e.g. "XHR"
+}
+
+// NetworkStream yields captured requests as they complete.
+type NetworkStream struct {
+	*Stream
+}
+
+// Next blocks for the next network entry.
+func (s *NetworkStream) Next() (*NetworkEntry, error) {
+	for {
+		ev, err := s.Stream.Next()
+		if err != nil {
+			return nil, err
+		}
+		if ev.Name != "network" {
+			continue
+		}
+		var entry NetworkEntry
+		if err := ev.Decode(&entry); err != nil {
+			return nil, err
+		}
+		return &entry, nil
+	}
+}
+
+// StreamNetwork subscribes to GET /network/stream.
+func (c *Client) StreamNetwork(ctx context.Context, opts *NetworkStreamOptions) (*NetworkStream, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "tabId", opts.TabID)
+		setIf(q, "filter", opts.Filter)
+		setIf(q, "method", opts.Method)
+		setIf(q, "status", opts.Status)
+		setIf(q, "type", opts.Type)
+	}
+	s, err := c.Stream(ctx, "/network/stream", q)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkStream{Stream: s}, nil
+}
+
+// FrameStream yields the frames of one WebSocket connection.
+type FrameStream struct {
+	*Stream
+	// Closed is the final connection entry, set once the socket has closed.
+	Closed *NetworkEntry
+}
+
+// Next blocks for the next frame. It returns io.EOF after the connection
+// closes.
+func (s *FrameStream) Next() (*WebSocketFrame, error) {
+	for {
+		ev, err := s.Stream.Next()
+		if err != nil {
+			return nil, err
+		}
+		switch ev.Name {
+		case "frame":
+			var frame WebSocketFrame
+			if err := ev.Decode(&frame); err != nil {
+				return nil, err
+			}
+			return &frame, nil
+		case "close":
+			var entry NetworkEntry
+			if err := ev.Decode(&entry); err != nil {
+				return nil, err
+			}
+			s.Closed = &entry
+			return nil, io.EOF
+		}
+	}
+}
+
+// StreamFrames subscribes to GET /network/{requestId}/frames. Retained
+// frames are replayed first unless replay is false.
+func (c *Client) StreamFrames(ctx context.Context, tabID, requestID string, replay bool) (*FrameStream, error) {
+	q := tabQuery(tabID)
+	if !replay {
+		q.Set("replay", "false")
+	}
+	s, err := c.Stream(ctx, "/network/"+url.PathEscape(requestID)+"/frames", q)
+	if err != nil {
+		return nil, err
+	}
+	return &FrameStream{Stream: s}, nil
+}
+
+// Event names sent on /api/events.
+const (
+	EventInit       = "init"       // data: the known agents
+	EventAction     = "action"     // data: ActivityEvent on the tool_call channel
+	EventProgress   = "progress"   // data: ActivityEvent on the progress channel
+	EventSystem     = "system"     // data: instance lifecycle event
+	EventMonitoring = "monitoring" // data: instance and tab monitoring snapshot
+)
+
+// EventsOptions filters GET /api/events.
+type EventsOptions struct {
+	// Mode selects the activity channel: "tool_calls" (default),
+	// "progress" or "both".
+	Mode string
+	// AgentID limits activity events to one agent.
+	AgentID string
+	// Memory includes per-instance memory in monitoring events.
+	Memory bool
+}
+
+// StreamEvents subscribes to the server's GET /api/events dashboard feed.
+// Decode action and progress events into ActivityEvent.
+func (c *Client) StreamEvents(ctx context.Context, opts *EventsOptions) (*Stream, error) {
+	q := url.Values{}
+	if opts != nil {
+		setIf(q, "mode", opts.Mode)
+		setIf(q, "agentId", opts.AgentID)
+		if opts.Memory {
+			q.Set("memory", "1")
+		}
+	}
+	return c.Stream(ctx, "/api/events", q)
+}
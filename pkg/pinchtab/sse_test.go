@@ -0,0 +1,126 @@
+package pinchtab
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamNetworkYieldsEntries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/network/stream" || r.URL.Query().Get("status") != "4xx" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept = %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, ": keepalive\n\n")
+		_, _ = io.WriteString(w, "event: network\ndata: {\"requestId\":\"r1\",\"url\":\"https://example.com/a\",\"method\":\"GET\",\"status\":404,\"finished\":true}\n\n")
+		_, _ = io.WriteString(w, "event: network\r\ndata: {\"requestId\":\"r2\",\"status\":410}\r\n\r\n")
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL, "").StreamNetwork(context.Background(), &NetworkStreamOptions{Status: "4xx"})
+	if err != nil {
+		t.Fatalf("StreamNetwork: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	first, err := s.Next()
+	if err != nil || first.RequestID != "r1" || first.Status != 404 || !first.Finished {
+		t.Fatalf("first = %+v, %v", first, err)
+	}
+	second, err := s.Next()
+	if err != nil || second.RequestID != "r2" {
+		t.Fatalf("second = %+v, %v", second, err)
+	}
+	if _, err := s.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("end err = %v, want io.EOF", err)
+	}
+}
+
+func TestStreamFramesEndsOnClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/network/ws1/frames" || r.URL.Query().Get("replay") != "false" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: frame\nid: 4\ndata: {\"seq\":4,\"type\":\"receive\",\"opcode\":1,\"data\":\"tick\"}\n\n")
+		_, _ = io.WriteString(w, "event: close\ndata: {\"requestId\":\"ws1\",\"resourceType\":\"WebSocket\",\"finished\":true,\"framesReceived\":4}\n\n")
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL, "").StreamFrames(context.Background(), "", "ws1", false)
+	if err != nil {
+		t.Fatalf("StreamFrames: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	frame, err := s.Next()
+	if err != nil || frame.Seq != 4 || frame.Type != "receive" || frame.Data != "tick" {
+		t.Fatalf("frame = %+v, %v", frame, err)
+	}
+	if _, err := s.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("end err = %v, want io.EOF", err)
+	}
+	if s.Closed == nil || !s.Closed.Finished || s.Closed.FramesReceived != 4 {
+		t.Fatalf("Closed = %+v", s.Closed)
+	}
+}
+
+func TestStreamEventsDecodesActivity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/events" || r.URL.Query().Get("mode") != "both" || r.URL.Query().Get("agentId") != "a1" {
+			t.Errorf("unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: init\ndata: []\n\n")
+		_, _ = io.WriteString(w, "event: progress\ndata: {\"agentId\":\"a1\",\"channel\":\"progress\",\"message\":\"step 1\",\"progress\":50}\n\n")
+		_, _ = io.WriteString(w, "data: line one\ndata: line two\n\n")
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL, "").StreamEvents(context.Background(), &EventsOptions{Mode: "both", AgentID: "a1"})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ev, err := s.Next()
+	if err != nil || ev.Name != EventInit {
+		t.Fatalf("init = %+v, %v", ev, err)
+	}
+	ev, err = s.Next()
+	if err != nil || ev.Name != EventProgress {
+		t.Fatalf("progress = %+v, %v", ev, err)
+	}
+	var act ActivityEvent
+	if err := ev.Decode(&act); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if act.Message != "step 1" || act.Progress == nil || *act.Progress != 50 {
+		t.Errorf("activity = %+v", act)
+	}
+	ev, err = s.Next()
+	if err != nil || ev.Name != "message" || string(ev.Data) != "line one\nline two" {
+		t.Fatalf("multi-line = %+v, %v", ev, err)
+	}
+}
+
+func TestStreamErrorIsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"error":"unauthorized","code":"bad_token"}`)
+	}))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "wrong").StreamEvents(context.Background(), nil)
+	if !IsCode(err, "bad_token") {
+		t.Fatalf("err = %v, want bad_token APIError", err)
+	}
+}
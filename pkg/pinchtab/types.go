@@ -0,0 +1,596 @@
+package pinchtab
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RouteMetadata reports which browser provider served a request and why.
+type RouteMetadata struct {
+	RequestedProvider string         `json:"requestedProvider"`
+	UsedProvider      string         `json:"usedProvider"`
+	Escalated         bool           `json:"escalated"`
+	Reason            string         `json:"reason,omitempty"`
+	Quality           int            `json:"quality,omitempty"`
+	FallbackAttempts  int            `json:"fallbackAttempts,omitempty"`
+	Attempts          []RouteAttempt `json:"attempts,omitempty"`
+}
+
+// RouteAttempt is one provider tried while routing a request.
+type RouteAttempt struct {
+	Provider string `json:"provider"`
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// NavigateRequest is the body of POST /navigate.
+type NavigateRequest struct {
+	URL string `json:"url"`
+	// TabID navigates an existing tab; empty uses the current tab.
+	TabID  string `json:"tabId,omitempty"`
+	NewTab bool   `json:"newTab,omitempty"`
+	// Timeout is the navigation timeout in seconds.
+	Timeout   float64 `json:"timeout,omitempty"`
+	WaitTitle float64 `json:"waitTitle,omitempty"`
+	// WaitFor is "none", "dom", "networkidle" or "selector".
+	WaitFor        string `json:"waitFor,omitempty"`
+	WaitSelector   string `json:"waitSelector,omitempty"`
+	BlockImages    *bool  `json:"blockImages,omitempty"`
+	BlockMedia     *bool  `json:"blockMedia,omitempty"`
+	BlockAds       *bool  `json:"blockAds,omitempty"`
+	DismissBanners bool   `json:"dismissBanners,omitempty"`
+	Browser        string `json:"browser,omitempty"`
+}
+
+// NavigateResult is the response of POST /navigate and POST /tab.
+type NavigateResult struct {
+	TabID string         `json:"tabId"`
+	URL   string         `json:"url"`
+	Title string         `json:"title"`
+	Route *RouteMetadata `json:"route,omitempty"`
+}
+
+// HistoryResult is the response of /back, /forward and /reload.
+type HistoryResult struct {
+	TabID string `json:"tabId"`
+	URL   string `json:"url"`
+}
+
+// SnapshotOptions are the query parameters of GET /snapshot.
+type SnapshotOptions struct {
+	TabID string
+	// Filter is "interactive" or "all" (default).
+	Filter   string
+	Selector string
+	// Depth limits tree depth; 0 means the full tree.
+	Depth     int
+	MaxTokens int
+	// Diff returns the changes since the previous snapshot of the tab.
+	Diff bool
+}
+
+// Node is an accessibility tree node. Ref is the handle actions accept.
+type Node struct {
+	Ref         string `json:"ref"`
+	Role        string `json:"role"`
+	Name        string `json:"name"`
+	Depth       int    `json:"depth"`
+	Value       string `json:"value,omitempty"`
+	Label       string `json:"label,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+	Alt         string `json:"alt,omitempty"`
+	Title       string `json:"title,omitempty"`
+	TestID      string `json:"testid,omitempty"`
+	Text        string `json:"text,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+	Focused     bool   `json:"focused,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	NodeID      int64  `json:"nodeId,omitempty"`
+	FrameID     string `json:"frameId,omitempty"`
+	FrameURL    string `json:"frameUrl,omitempty"`
+}
+
+// Snapshot is the JSON response of GET /snapshot. A diff snapshot fills
+// Added, Changed and Removed instead of Nodes.
+type Snapshot struct {
+	URL       string         `json:"url"`
+	Title     string         `json:"title"`
+	Nodes     []Node         `json:"nodes,omitempty"`
+	Count     int            `json:"count,omitempty"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Hint      string         `json:"hint,omitempty"`
+	Route     *RouteMetadata `json:"route,omitempty"`
+
+	Diff    bool   `json:"diff,omitempty"`
+	Added   []Node `json:"added,omitempty"`
+	Changed []Node `json:"changed,omitempty"`
+	Removed []Node `json:"removed,omitempty"`
+
+	IDPIWarning      string `json:"idpiWarning,omitempty"`
+	UntrustedContent bool   `json:"untrustedContent,omitempty"`
+}
+
+// TextOptions are the query parameters of GET /text.
+type TextOptions struct {
+	TabID string
+	// Mode is "raw" to skip readability extraction.
+	Mode     string
+	Selector string
+	MaxChars int
+	FrameID  string
+}
+
+// TextResult is the response of GET /text.
+type TextResult struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// ScreenshotOptions are the query parameters of GET /screenshot.
+type ScreenshotOptions struct {
+	TabID string
+	// Format is "jpeg" (default) or "png".
+	Format         string
+	Quality        int
+	Selector       string
+	BeyondViewport bool
+	Scale          float64
+}
+
+// ActionRequest is one interaction for POST /action and POST /actions.
+// Kind is e.g. "click", "type", "fill", "press", "hover", "scroll" or
+// "select"; target it with Ref from a snapshot or with Selector.
+type ActionRequest struct {
+	Kind     string `json:"kind"`
+	TabID    string `json:"tabId,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+	NodeID   int64  `json:"nodeId,omitempty"`
+	// X and Y are viewport coordinates; set HasXY to click at them.
+	X         float64 `json:"x,omitempty"`
+	Y         float64 `json:"y,omitempty"`
+	HasXY     bool    `json:"hasXY,omitempty"`
+	Button    string  `json:"button,omitempty"`
+	Mode      string  `json:"mode,omitempty"`
+	Modifiers int     `json:"modifiers,omitempty"`
+	ScrollX   int     `json:"scrollX,omitempty"`
+	ScrollY   int     `json:"scrollY,omitempty"`
+	DeltaX    int     `json:"deltaX,omitempty"`
+	DeltaY    int     `json:"deltaY,omitempty"`
+	DragX     int     `json:"dragX,omitempty"`
+	DragY     int     `json:"dragY,omitempty"`
+	WaitNav   bool    `json:"waitNav,omitempty"`
+	Fast      bool    `json:"fast,omitempty"`
+	// Owner must match the holder of a tab lock.
+	Owner          string `json:"owner,omitempty"`
+	DismissBanners bool   `json:"dismissBanners,omitempty"`
+	Humanize       *bool  `json:"humanize,omitempty"`
+	// DialogAction is "accept" or "dismiss" for a dialog the action opens.
+	DialogAction string `json:"dialogAction,omitempty"`
+	DialogText   string `json:"dialogText,omitempty"`
+	Browser      string `json:"browser,omitempty"`
+}
+
+// ActionResult is the response of POST /action.
+type ActionResult struct {
+	Success  bool           `json:"success"`
+	Result   map[string]any `json:"result,omitempty"`
+	Route    *RouteMetadata `json:"route,omitempty"`
+	Recovery map[string]any `json:"recovery,omitempty"`
+}
+
+// ActionsRequest is the body of POST /actions.
+type ActionsRequest struct {
+	TabID       string          `json:"tabId,omitempty"`
+	Owner       string          `json:"owner,omitempty"`
+	Actions     []ActionRequest `json:"actions"`
+	StopOnError bool            `json:"stopOnError,omitempty"`
+}
+
+// ActionsResult is the response of POST /actions.
+type ActionsResult struct {
+	Results    []ActionStepResult `json:"results"`
+	Total      int                `json:"total"`
+	Successful int                `json:"successful"`
+	Failed     int                `json:"failed"`
+	Route      *RouteMetadata     `json:"route,omitempty"`
+}
+
+// ActionStepResult is the outcome of one step of a batch.
+type ActionStepResult struct {
+	Index   int            `json:"index"`
+	Success bool           `json:"success"`
+	Result  map[string]any `json:"result,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// WaitRequest is the body of POST /wait. Set exactly one condition.
+type WaitRequest struct {
+	TabID    string `json:"tabId,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	// State is "visible" (default) or "hidden" for Selector waits.
+	State   string `json:"state,omitempty"`
+	Text    string `json:"text,omitempty"`
+	NotText string `json:"notText,omitempty"`
+	URL     string `json:"url,omitempty"`
+	// Load is "ready-state", "content-loaded" or "network-idle".
+	Load string `json:"load,omitempty"`
+	Fn   string `json:"fn,omitempty"`
+	Ms   *int   `json:"ms,omitempty"`
+	// Timeout and IdleFor are milliseconds.
+	Timeout *int `json:"timeout,omitempty"`
+	IdleFor *int `json:"idleFor,omitempty"`
+}
+
+// WaitResult is the response of POST /wait. A timeout is not an HTTP error:
+// Waited is false and Error says why.
+type WaitResult struct {
+	Waited  bool   `json:"waited"`
+	Elapsed int64  `json:"elapsed"`
+	Match   string `json:"match,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// FindRequest is the body of POST /find.
+type FindRequest struct {
+	Query     string  `json:"query"`
+	TabID     string  `json:"tabId,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+	TopK      int     `json:"topK,omitempty"`
+	Explain   bool    `json:"explain,omitempty"`
+}
+
+// FindResult is the response of POST /find.
+type FindResult struct {
+	BestRef      string         `json:"best_ref"`
+	Confidence   string         `json:"confidence"`
+	Score        float64        `json:"score"`
+	Matches      []ElementMatch `json:"matches"`
+	Strategy     string         `json:"strategy"`
+	Threshold    float64        `json:"threshold"`
+	LatencyMs    int64          `json:"latency_ms"`
+	ElementCount int            `json:"element_count"`
+	IDPIWarning  string         `json:"idpiWarning,omitempty"`
+}
+
+// ElementMatch is one candidate returned by /find.
+type ElementMatch struct {
+	Ref   string  `json:"ref"`
+	Role  string  `json:"role,omitempty"`
+	Name  string  `json:"name,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// Tab is an entry of GET /tabs.
+type Tab struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	Type          string `json:"type"`
+	Status        string `json:"status,omitempty"`
+	HandoffReason string `json:"handoffReason,omitempty"`
+	Owner         string `json:"owner,omitempty"`
+	LockedUntil   string `json:"lockedUntil,omitempty"`
+}
+
+// NetworkOptions are the query parameters of GET /network.
+type NetworkOptions struct {
+	TabID  string
+	Filter string
+	Method string
+	Status string
+	Type   string
+	Limit  int
+}
+
+// NetworkEntry is a captured request.
+type NetworkEntry struct {
+	RequestID       string            `json:"requestId"`
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	Status          int               `json:"status,omitempty"`
+	StatusText      string            `json:"statusText,omitempty"`
+	ResourceType    string            `json:"resourceType"`
+	RequestHeaders  map[string]string `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	PostData        string            `json:"postData,omitempty"`
+	MimeType        string            `json:"mimeType,omitempty"`
+	StartTime       time.Time         `json:"startTime"`
+	EndTime         time.Time         `json:"endTime,omitempty"`
+	Duration        float64           `json:"duration,omitempty"`
+	Size            int64             `json:"size,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	Finished        bool              `json:"finished"`
+	Failed          bool              `json:"failed"`
+	ResponseBody    string            `json:"responseBody,omitempty"`
+	Base64Encoded   bool              `json:"base64Encoded,omitempty"`
+	// Frame counters are set on WebSocket entries (ResourceType "WebSocket").
+	FramesSent     int `json:"framesSent,omitempty"`
+	FramesReceived int `json:"framesReceived,omitempty"`
+	FramesDropped  int `json:"framesDropped,omitempty"`
+}
+
+// WebSocketFrame is one message on a captured WebSocket connection. Type is
+// "send" or "receive"; binary payloads (Opcode 2) are base64-encoded.
+type WebSocketFrame struct {
+	Seq       int64     `json:"seq"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Opcode    int       `json:"opcode"`
+	Data      string    `json:"data"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// NetworkList is the response of GET /network.
+type NetworkList struct {
+	Entries []NetworkEntry `json:"entries"`
+	Count   int            `json:"count"`
+	TabID   string         `json:"tabId"`
+}
+
+// NetworkDetail is the response of GET /network/{requestId}. The body
+// fields are only set when the body was requested.
+type NetworkDetail struct {
+	Entry         NetworkEntry `json:"entry"`
+	TabID         string       `json:"tabId"`
+	ResponseBody  string       `json:"responseBody,omitempty"`
+	Base64Encoded bool         `json:"base64Encoded,omitempty"`
+	// BodySource is "retained" or "live".
+	BodySource string `json:"bodySource,omitempty"`
+	BodyError  string `json:"bodyError,omitempty"`
+	// Frames holds the retained frames of a WebSocket entry, oldest first.
+	Frames []WebSocketFrame `json:"frames,omitempty"`
+}
+
+// LogEntry is a console message.
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// Cookie is a browser cookie.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	HTTPOnly bool    `json:"httpOnly,omitempty"`
+	SameSite string  `json:"sameSite,omitempty"`
+	Expires  float64 `json:"expires,omitempty"`
+}
+
+// Health is the response of GET /health.
+type Health struct {
+	Status string `json:"status"`
+	Tabs   int    `json:"tabs,omitempty"`
+}
+
+// TaskRequest is the body of POST /tasks.
+type TaskRequest struct {
+	AgentID     string         `json:"agentId"`
+	Action      string         `json:"action"`
+	TabID       string         `json:"tabId,omitempty"`
+	Selector    string         `json:"selector,omitempty"`
+	Params      map[string]any `json:"params,omitempty"`
+	Priority    int            `json:"priority,omitempty"`
+	Deadline    string         `json:"deadline,omitempty"`
+	CallbackURL string         `json:"callbackUrl,omitempty"`
+	// OnRestart is "fail" (default) or "requeue".
+	OnRestart string   `json:"onRestart,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// TaskSubmitted is the 202 response of POST /tasks.
+type TaskSubmitted struct {
+	TaskID    string    `json:"taskId"`
+	State     string    `json:"state"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Task states.
+const (
+	TaskWaiting   = "waiting"
+	TaskQueued    = "queued"
+	TaskAssigned  = "assigned"
+	TaskRunning   = "running"
+	TaskDone      = "done"
+	TaskFailed    = "failed"
+	TaskCancelled = "cancelled"
+	TaskRejected  = "rejected"
+)
+
+// Task is a scheduler task as returned by GET /tasks/{id}.
+type Task struct {
+	TaskID      string          `json:"taskId"`
+	AgentID     string          `json:"agentId"`
+	Action      string          `json:"action"`
+	TabID       string          `json:"tabId,omitempty"`
+	Selector    string          `json:"selector,omitempty"`
+	Params      map[string]any  `json:"params,omitempty"`
+	Priority    int             `json:"priority"`
+	State       string          `json:"state"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   time.Time       `json:"startedAt,omitempty"`
+	CompletedAt time.Time       `json:"completedAt,omitempty"`
+	LatencyMs   int64           `json:"latencyMs,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	ScheduleID  string          `json:"scheduleId,omitempty"`
+	DependsOn   []string        `json:"dependsOn,omitempty"`
+	BatchID     string          `json:"batchId,omitempty"`
+	Key         string          `json:"key,omitempty"`
+	Position    int             `json:"position,omitempty"`
+}
+
+// Terminal reports whether the task's state will no longer change.
+func (t *Task) Terminal() bool {
+	switch t.State {
+	case TaskDone, TaskFailed, TaskCancelled, TaskRejected:
+		return true
+	}
+	return false
+}
+
+// TaskListOptions filters GET /tasks.
+type TaskListOptions struct {
+	AgentID string
+	States  []string
+}
+
+// BatchRequest is the body of POST /tasks/batch.
+type BatchRequest struct {
+	AgentID     string `json:"agentId"`
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	// OnFailure is "continue" (default) or "fail-fast".
+	OnFailure string         `json:"onFailure,omitempty"`
+	Tasks     []BatchTaskDef `json:"tasks"`
+}
+
+// BatchTaskDef is one task of a batch. DependsOn names batch-local keys or
+// IDs of earlier tasks.
+type BatchTaskDef struct {
+	Key       string         `json:"key,omitempty"`
+	DependsOn []string       `json:"dependsOn,omitempty"`
+	Action    string         `json:"action"`
+	TabID     string         `json:"tabId,omitempty"`
+	Params    map[string]any `json:"params,omitempty"`
+	Priority  int            `json:"priority,omitempty"`
+	Deadline  string         `json:"deadline,omitempty"`
+	OnRestart string         `json:"onRestart,omitempty"`
+}
+
+// BatchResult is the response of POST /tasks/batch.
+type BatchResult struct {
+	BatchID   string           `json:"batchId"`
+	Tasks     []BatchTaskState `json:"tasks"`
+	Submitted int              `json:"submitted"`
+	DAG       *BatchDAG        `json:"dag,omitempty"`
+}
+
+// BatchTaskState is the submission outcome of one batch task.
+type BatchTaskState struct {
+	TaskID    string   `json:"taskId"`
+	Key       string   `json:"key,omitempty"`
+	State     string   `json:"state"`
+	Position  int      `json:"position,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// BatchDAG lists batch task IDs by dependency level.
+type BatchDAG struct {
+	Levels [][]string `json:"levels"`
+	Edges  []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"edges"`
+}
+
+// Instance is a managed browser instance.
+type Instance struct {
+	ID          string    `json:"id"`
+	ProfileID   string    `json:"profileId"`
+	ProfileName string    `json:"profileName"`
+	Port        string    `json:"port"`
+	URL         string    `json:"url,omitempty"`
+	Mode        string    `json:"mode"`
+	Headless    bool      `json:"headless"`
+	Status      string    `json:"status"`
+	StartTime   time.Time `json:"startTime"`
+	Error       string    `json:"error,omitempty"`
+	Attached    bool      `json:"attached"`
+	Browser     string    `json:"browser,omitempty"`
+}
+
+// StartInstanceRequest is the body of POST /instances/start.
+type StartInstanceRequest struct {
+	ProfileID string `json:"profileId,omitempty"`
+	// Mode is "headless" or "headed".
+	Mode    string `json:"mode,omitempty"`
+	Port    string `json:"port,omitempty"`
+	Browser string `json:"browser,omitempty"`
+}
+
+// CreateSessionRequest is the body of POST /sessions.
+type CreateSessionRequest struct {
+	AgentID string `json:"agentId"`
+	Label   string `json:"label,omitempty"`
+	Browser string `json:"browser,omitempty"`
+}
+
+// Session is an agent session. SessionToken is only returned once, by
+// CreateSession; pass it to Client.WithSession.
+type Session struct {
+	ID           string    `json:"id"`
+	AgentID      string    `json:"agentId"`
+	Label        string    `json:"label,omitempty"`
+	Browser      string    `json:"browser,omitempty"`
+	SessionToken string    `json:"sessionToken,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	LastSeenAt   time.Time `json:"lastSeenAt,omitempty"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"`
+	Status       string    `json:"status"`
+	Grants       []string  `json:"grants,omitempty"`
+}
+
+// ActivityEvent is the data of action and progress events on /api/events.
+type ActivityEvent struct {
+	ID        string         `json:"id"`
+	AgentID   string         `json:"agentId"`
+	Channel   string         `json:"channel"`
+	Type      string         `json:"type"`
+	Method    string         `json:"method"`
+	Path      string         `json:"path"`
+	Message   string         `json:"message,omitempty"`
+	Progress  *int           `json:"progress,omitempty"`
+	Total     *int           `json:"total,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// WorkflowRequest is the body of POST /workflows/run. Workflow is a
+// document value (marshalled as JSON) or a string of YAML/JSON source.
+type WorkflowRequest struct {
+	TabID    string         `json:"tabId,omitempty"`
+	Owner    string         `json:"owner,omitempty"`
+	Workflow any            `json:"workflow"`
+	Vars     map[string]any `json:"vars,omitempty"`
+	// Async returns as soon as the run starts; poll it with WorkflowRun.
+	Async bool `json:"async,omitempty"`
+}
+
+// WorkflowRun is a workflow execution with its outputs and step trace.
+type WorkflowRun struct {
+	RunID       string         `json:"runId"`
+	Name        string         `json:"name,omitempty"`
+	Status      string         `json:"status"`
+	TabID       string         `json:"tabId,omitempty"`
+	Outputs     map[string]any `json:"outputs,omitempty"`
+	Trace       []StepTrace    `json:"trace,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt,omitempty"`
+	CompletedAt time.Time      `json:"completedAt,omitempty"`
+	DurationMs  int64          `json:"durationMs,omitempty"`
+}
+
+// StepTrace records one executed workflow step.
+type StepTrace struct {
+	Path       string    `json:"path"`
+	ID         string    `json:"id,omitempty"`
+	Type       string    `json:"type"`
+	Status     string    `json:"status"`
+	Attempts   int       `json:"attempts"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Output     any       `json:"output,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -175,3 +175,30 @@ else
 fi
 
 end_test
+
+# ─────────────────────────────────────────────────────────────────
+start_test "capture: live page churn keeps the epoch valid"
+
+pt_post /navigate -d "{\"url\":\"${FIXTURES_URL}/dom-epoch-churn.html\"}"
+CHURN_TAB=$(echo "$RESULT" | jq -r '.tabId')
+sleep 0.5
+
+pt_get "/capture?tabId=${CHURN_TAB}"
+EPOCH=$(echo "$RESULT" | jq -r '.epoch.domEpoch')
+KEEP_REF=$(echo "$RESULT" | jq -r '[.snapshot.nodes[] | select(.name == "Keep") | .ref] | first // empty')
+REBUILD_REF=$(echo "$RESULT" | jq -r '[.snapshot.nodes[] | select(.name == "Rebuild") | .ref] | first // empty')
+
+# The clock, spinner and feed mutate every 100ms without touching the buttons.
+sleep 1
+pt_post /action "{\"tabId\":\"${CHURN_TAB}\",\"kind\":\"click\",\"ref\":\"${KEEP_REF}\",\"expectedEpoch\":\"${EPOCH}\"}"
+assert_ok "action after unrelated churn"
+
+# Rebuilding the buttons removes the referenced nodes.
+pt_post /action "{\"tabId\":\"${CHURN_TAB}\",\"kind\":\"click\",\"ref\":\"${REBUILD_REF}\",\"expectedEpoch\":\"${EPOCH}\"}"
+assert_ok "rebuild click"
+sleep 0.3
+pt_post /action "{\"tabId\":\"${CHURN_TAB}\",\"kind\":\"click\",\"ref\":\"${KEEP_REF}\",\"expectedEpoch\":\"${EPOCH}\"}"
+assert_http_status 409 "action after referenced nodes were replaced"
+assert_json_eq "$RESULT" '.details.reason' 'dom_mutated' "stale reason"
+
+end_test